@@ -9,9 +9,11 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/mr-tron/base58/base58"
 	"github.com/spf13/cobra"
 
 	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/macaroon"
 	"storj.io/storj/pkg/process"
 	"storj.io/storj/pkg/provider"
 )
@@ -27,8 +29,8 @@ type Config struct {
 	BasePath            string `help:"base path for captain planet storage" default:"$CONFDIR"`
 	ListenHost          string `help:"the host for providers to listen on" default:"127.0.0.1"`
 	StartingPort        int    `help:"all providers will listen on ports consecutively starting with this one" default:"7777"`
-	APIKey              string `default:"abc123" help:"the api key to use for the satellite"`
-	EncKey              string `default:"highlydistributedridiculouslyresilient" help:"your root encryption key"`
+	APIKey              string `default:"abc123" help:"the static api key of the internal satellite services"`
+	EncKey              string `default:"highlydistributedridiculouslyresilient" help:"the passphrase your root encryption key is derived from"`
 	Overwrite           bool   `help:"whether to overwrite pre-existing configuration files" default:"false"`
 	GenerateMinioCerts  bool   `default:"false" help:"generate sample TLS certs for Minio GW"`
 }
@@ -131,6 +133,19 @@ func cmdSetup(cmd *cobra.Command, args []string) (err error) {
 
 	startingPort := setupCfg.StartingPort
 
+	apiKeySecret, err := macaroon.NewSecret()
+	if err != nil {
+		return err
+	}
+	apiKey, err := macaroon.NewAPIKey(apiKeySecret)
+	if err != nil {
+		return err
+	}
+	uplinkAPIKey, err := apiKey.Serialize()
+	if err != nil {
+		return err
+	}
+
 	overrides := map[string]interface{}{
 		"satellite.repairer.queue-address": "redis://127.0.0.1:6378?db=1&password=abc123",
 		"satellite.identity.cert-path":     setupCfg.HCIdentity.CertPath,
@@ -155,9 +170,11 @@ func cmdSetup(cmd *cobra.Command, args []string) (err error) {
 			setupCfg.ListenHost, startingPort+1),
 		"uplink.minio-dir": filepath.Join(
 			setupCfg.BasePath, "uplink", "minio"),
-		"uplink.enc-key":          setupCfg.EncKey,
-		"uplink.api-key":          setupCfg.APIKey,
-		"pointer-db.auth.api-key": setupCfg.APIKey,
+		"uplink.enc-key":                      setupCfg.EncKey,
+		"uplink.enc-key-derivation":           "argon2id",
+		"uplink.api-key":                      uplinkAPIKey,
+		"satellite.pointer-db.api-key-secret": base58.Encode(apiKeySecret),
+		"pointer-db.auth.api-key":             setupCfg.APIKey,
 	}
 
 	for i := 0; i < len(runCfg.StorageNodes); i++ {
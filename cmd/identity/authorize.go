@@ -0,0 +1,72 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"bytes"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/certificates"
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/process"
+	"storj.io/storj/pkg/provider"
+	"storj.io/storj/pkg/utils"
+)
+
+var (
+	authorizeCmd = &cobra.Command{
+		Use:   "authorize <signer address> <auth token>",
+		Short: "Have the certificate authority signed by a satellite in exchange for an authorization token",
+		Args:  cobra.ExactArgs(2),
+		RunE:  cmdAuthorize,
+	}
+
+	authorizeCfg struct {
+		CA       provider.FullCAConfig
+		Identity provider.IdentityConfig
+	}
+)
+
+func init() {
+	caCmd.AddCommand(authorizeCmd)
+	cfgstruct.Bind(authorizeCmd.Flags(), &authorizeCfg, cfgstruct.ConfDir(defaultConfDir))
+}
+
+func cmdAuthorize(cmd *cobra.Command, args []string) (err error) {
+	ctx := process.Ctx(cmd)
+	address, token := args[0], args[1]
+
+	ca, err := authorizeCfg.CA.Load()
+	if err != nil {
+		return err
+	}
+	identity, err := authorizeCfg.Identity.Load()
+	if err != nil {
+		return err
+	}
+
+	client, err := certificates.NewClient(ctx, identity, address)
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, client.Close()) }()
+
+	chain, err := client.Sign(ctx, token)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(chain[0].RawSubjectPublicKeyInfo, ca.Cert.RawSubjectPublicKeyInfo) {
+		return errs.New("signer returned a certificate for a different key")
+	}
+
+	// the key, and therefore the node id and the identity's leaf, stay valid
+	ca.Cert, ca.RestChain = chain[0], chain[1:]
+	if err := authorizeCfg.CA.Save(ca); err != nil {
+		return err
+	}
+	identity.CA, identity.RestChain = chain[0], chain[1:]
+	return authorizeCfg.Identity.Save(identity)
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/process"
+	"storj.io/storj/pkg/provider"
+)
+
+var (
+	inspectIDCmd = &cobra.Command{
+		Use:   "inspect",
+		Short: "Print the node id, difficulty and certificate chain of an identity",
+		RunE:  cmdInspectID,
+	}
+	verifyIDCmd = &cobra.Command{
+		Use:   "verify <address> <node id>",
+		Short: "Check that the node serving at an address has the given node id",
+		Args:  cobra.ExactArgs(2),
+		RunE:  cmdVerifyID,
+	}
+
+	inspectIDCfg struct {
+		CertPath string `help:"path to the certificate chain of the identity" default:"$CONFDIR/identity.cert"`
+	}
+	verifyIDCfg struct {
+		Identity provider.IdentityConfig
+	}
+)
+
+func init() {
+	idCmd.AddCommand(inspectIDCmd)
+	idCmd.AddCommand(verifyIDCmd)
+	cfgstruct.Bind(inspectIDCmd.Flags(), &inspectIDCfg, cfgstruct.ConfDir(defaultConfDir))
+	cfgstruct.Bind(verifyIDCmd.Flags(), &verifyIDCfg, cfgstruct.ConfDir(defaultConfDir))
+}
+
+func cmdInspectID(cmd *cobra.Command, args []string) (err error) {
+	chainPEM, err := ioutil.ReadFile(inspectIDCfg.CertPath)
+	if err != nil {
+		return err
+	}
+	pi, err := provider.PeerIdentityFromPEM(chainPEM)
+	if err != nil {
+		return err
+	}
+
+	printIdentityInfo(pi.Inspect())
+	return nil
+}
+
+func cmdVerifyID(cmd *cobra.Command, args []string) (err error) {
+	identity, err := verifyIDCfg.Identity.Load()
+	if err != nil {
+		return err
+	}
+
+	pi, err := identity.VerifyPeer(process.Ctx(cmd), args[0], args[1])
+	if pi != nil {
+		printIdentityInfo(pi.Inspect())
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s serves node %s\n", args[0], args[1])
+	return nil
+}
+
+func printIdentityInfo(info *provider.IdentityInfo) {
+	now := time.Now()
+
+	fmt.Printf("node id:    %s\n", info.ID)
+	fmt.Printf("difficulty: %d\n", info.Difficulty)
+	if info.ChainError != nil {
+		fmt.Printf("chain:      invalid: %v\n", info.ChainError)
+	} else {
+		fmt.Printf("chain:      valid\n")
+	}
+	for _, cert := range info.Certs {
+		status := fmt.Sprintf("expires in %s", cert.NotAfter.Sub(now).Round(time.Second))
+		if cert.ExpiredAt(now) {
+			status = "expired"
+		}
+		fmt.Printf("%-11s %s to %s (%s)\n", cert.Role+":",
+			cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339), status)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/process"
+	"storj.io/storj/pkg/provider"
+)
+
+var (
+	mineCmd = &cobra.Command{
+		Use:   "mine",
+		Short: "Pre-generate a pool of certificate authorities",
+		RunE:  cmdMine,
+	}
+
+	mineCfg struct {
+		PoolDir     string `help:"directory of the certificate authority pool" default:"$CONFDIR/pool"`
+		Count       int    `help:"number of certificate authorities to generate" default:"1"`
+		Difficulty  uint64 `help:"minimum difficulty for identity generation" default:"12"`
+		Concurrency uint   `help:"number of concurrent workers for certificate authority generation, 0 uses all cores" default:"0"`
+		KeyType     string `help:"type of the keys of the identities, ecdsa or ed25519" default:"ecdsa"`
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(mineCmd)
+	cfgstruct.Bind(mineCmd.Flags(), &mineCfg, cfgstruct.ConfDir(defaultConfDir))
+}
+
+func cmdMine(cmd *cobra.Command, args []string) error {
+	pool := provider.NewCAPool(mineCfg.PoolDir)
+	err := pool.Mine(process.Ctx(cmd), provider.NewCAOptions{
+		Difficulty:  uint16(mineCfg.Difficulty),
+		Concurrency: mineCfg.Concurrency,
+		KeyType:     mineCfg.KeyType,
+	}, mineCfg.Count)
+	if err != nil {
+		return err
+	}
+
+	available, err := pool.Available(uint16(mineCfg.Difficulty))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d certificate authorities with difficulty %d or more available in %s\n", available, mineCfg.Difficulty, mineCfg.PoolDir)
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/provider"
+	"storj.io/storj/pkg/trust"
+)
+
+var (
+	signListCmd = &cobra.Command{
+		Use:   "sign-list <list path>",
+		Short: "Sign a list of trusted satellites, writing the signature next to it with .sig appended",
+		Args:  cobra.ExactArgs(1),
+		RunE:  cmdSignList,
+	}
+
+	signListCfg struct {
+		Identity provider.IdentityConfig
+	}
+)
+
+func init() {
+	idCmd.AddCommand(signListCmd)
+	cfgstruct.Bind(signListCmd.Flags(), &signListCfg, cfgstruct.ConfDir(defaultConfDir))
+}
+
+func cmdSignList(cmd *cobra.Command, args []string) (err error) {
+	identity, err := signListCfg.Identity.Load()
+	if err != nil {
+		return err
+	}
+	list, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	signature, err := trust.SignList(list, identity)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(args[0]+".sig", signature, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("signed %s as %s; storage nodes need --storage.trust.list-signer %s\n", args[0], identity.ID, identity.ID)
+	return nil
+}
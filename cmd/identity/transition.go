@@ -0,0 +1,77 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+	"google.golang.org/grpc"
+
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/process"
+	"storj.io/storj/pkg/provider"
+	"storj.io/storj/pkg/utils"
+)
+
+var (
+	transitionCmd = &cobra.Command{
+		Use:   "transition <satellite address>...",
+		Short: "Move the history of an old identity to a new, harder one on satellites",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  cmdTransition,
+	}
+
+	transitionCfg struct {
+		OldCertPath string `help:"path to the certificate chain of the old identity" default:"$CONFDIR/old/identity.cert"`
+		OldKeyPath  string `help:"path to the private key of the old identity" default:"$CONFDIR/old/identity.key"`
+		Identity    provider.IdentityConfig
+	}
+)
+
+func init() {
+	idCmd.AddCommand(transitionCmd)
+	cfgstruct.Bind(transitionCmd.Flags(), &transitionCfg, cfgstruct.ConfDir(defaultConfDir))
+}
+
+func cmdTransition(cmd *cobra.Command, args []string) (err error) {
+	ctx := process.Ctx(cmd)
+
+	oldIdentity, err := provider.IdentityConfig{
+		CertPath: transitionCfg.OldCertPath,
+		KeyPath:  transitionCfg.OldKeyPath,
+	}.Load()
+	if err != nil {
+		return err
+	}
+	newIdentity, err := transitionCfg.Identity.Load()
+	if err != nil {
+		return err
+	}
+
+	transition, err := node.NewIdentityTransition(oldIdentity, newIdentity)
+	if err != nil {
+		return err
+	}
+
+	dialOpt, err := newIdentity.DialOption()
+	if err != nil {
+		return err
+	}
+	for _, address := range args {
+		conn, err := grpc.DialContext(ctx, address, dialOpt)
+		if err != nil {
+			return err
+		}
+		_, err = pb.NewIdentityTransitionsClient(conn).Transition(ctx, &pb.TransitionRequest{Transition: transition})
+		if err = utils.CombineErrors(err, conn.Close()); err != nil {
+			return errs.New("transition on %s failed: %v", address, err)
+		}
+		fmt.Printf("moved the history of %s to %s on %s\n", oldIdentity.ID, newIdentity.ID, address)
+	}
+	return nil
+}
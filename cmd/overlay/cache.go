@@ -5,7 +5,6 @@ package main
 
 import (
 	"net/url"
-	"strconv"
 
 	"go.uber.org/zap"
 
@@ -32,11 +31,7 @@ func (c cacheConfig) open() (*overlay.Cache, error) {
 		}
 		zap.S().Info("Starting overlay cache with BoltDB")
 	case "redis":
-		db, err := strconv.Atoi(dburl.Query().Get("db"))
-		if err != nil {
-			return nil, Error.New("invalid db: %s", err)
-		}
-		cache, err = overlay.NewRedisOverlayCache(dburl.Host, overlay.GetUserPassword(dburl), db, nil)
+		cache, err = overlay.NewRedisOverlayCacheFrom(c.DatabaseURL, nil)
 		if err != nil {
 			return nil, err
 		}
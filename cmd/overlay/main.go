@@ -14,6 +14,7 @@ import (
 	"storj.io/storj/pkg/cfgstruct"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/process"
+	"storj.io/storj/storage"
 )
 
 var (
@@ -52,24 +53,22 @@ func cmdList(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
-	keys, err := c.DB.List(nil, 0)
-	if err != nil {
-		return err
-	}
-
-	for _, k := range keys {
-		n, err := c.Get(process.Ctx(cmd), string(k))
-		if err != nil {
-			zap.S().Infof("ID: %s; error getting value\n", k)
+	return c.DB.Iterate(storage.IterateOptions{Recurse: true}, func(it storage.Iterator) error {
+		var item storage.ListItem
+		for it.Next(&item) {
+			k := item.Key
+			n, err := c.Get(process.Ctx(cmd), string(k))
+			if err != nil {
+				zap.S().Infof("ID: %s; error getting value\n", k)
+			}
+			if n != nil {
+				zap.S().Infof("ID: %s; Address: %s\n", k, n.Address.Address)
+				continue
+			}
+			zap.S().Infof("ID: %s: nil\n", k)
 		}
-		if n != nil {
-			zap.S().Infof("ID: %s; Address: %s\n", k, n.Address.Address)
-			continue
-		}
-		zap.S().Infof("ID: %s: nil\n", k)
-	}
-
-	return nil
+		return nil
+	})
 }
 
 func cmdAdd(cmd *cobra.Command, args []string) (err error) {
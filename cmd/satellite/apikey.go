@@ -0,0 +1,58 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/macaroon"
+	"storj.io/storj/pkg/pointerdb"
+)
+
+var (
+	apiKeyCmd = &cobra.Command{
+		Use:   "api-key",
+		Short: "Manage the api keys of uplinks",
+	}
+	apiKeyCreateCmd = &cobra.Command{
+		Use:   "create",
+		Short: "Create an unrestricted api key from the api key secret",
+		RunE:  cmdAPIKeyCreate,
+	}
+
+	apiKeyCfg struct {
+		PointerDB pointerdb.Config
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(apiKeyCmd)
+	apiKeyCmd.AddCommand(apiKeyCreateCmd)
+	cfgstruct.Bind(apiKeyCreateCmd.Flags(), &apiKeyCfg, cfgstruct.ConfDir(defaultConfDir))
+}
+
+func cmdAPIKeyCreate(cmd *cobra.Command, args []string) (err error) {
+	secret, err := apiKeyCfg.PointerDB.LoadAPIKeySecret()
+	if err != nil {
+		return err
+	}
+	if secret == nil {
+		return errs.New("no api key secret configured")
+	}
+
+	key, err := macaroon.NewAPIKey(secret)
+	if err != nil {
+		return err
+	}
+	serialized, err := key.Serialize()
+	if err != nil {
+		return err
+	}
+	fmt.Println(serialized)
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/certificates"
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/utils"
+)
+
+var (
+	authCmd = &cobra.Command{
+		Use:   "auth",
+		Short: "Manage certificate signing authorizations",
+	}
+	authCreateCmd = &cobra.Command{
+		Use:   "create <count> <user id>...",
+		Short: "Create authorization tokens for the given users",
+		Args:  cobra.MinimumNArgs(2),
+		RunE:  cmdAuthCreate,
+	}
+	authInfoCmd = &cobra.Command{
+		Use:   "info [<user id>...]",
+		Short: "Show the authorizations of the given users, or of all users",
+		RunE:  cmdAuthInfo,
+	}
+
+	authCfg struct {
+		Signer certificates.CertSigningConfig
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authCreateCmd)
+	authCmd.AddCommand(authInfoCmd)
+	cfgstruct.Bind(authCreateCmd.Flags(), &authCfg, cfgstruct.ConfDir(defaultConfDir))
+	cfgstruct.Bind(authInfoCmd.Flags(), &authCfg, cfgstruct.ConfDir(defaultConfDir))
+}
+
+func cmdAuthCreate(cmd *cobra.Command, args []string) (err error) {
+	count, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errs.New("invalid count %q: %v", args[0], err)
+	}
+
+	authDB, err := authCfg.Signer.NewAuthDB()
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, authDB.Close()) }()
+
+	for _, userID := range args[1:] {
+		auths, err := authDB.Create(userID, count)
+		if err != nil {
+			return err
+		}
+		for _, auth := range auths {
+			fmt.Println(auth.Token.String())
+		}
+	}
+	return nil
+}
+
+func cmdAuthInfo(cmd *cobra.Command, args []string) (err error) {
+	authDB, err := authCfg.Signer.NewAuthDB()
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, authDB.Close()) }()
+
+	userIDs := args
+	if len(userIDs) == 0 {
+		userIDs, err = authDB.UserIDs()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, userID := range userIDs {
+		auths, err := authDB.Get(userID)
+		if err != nil {
+			return err
+		}
+		claimed, open := auths.Group()
+		fmt.Printf("%s: %d open, %d claimed\n", userID, len(open), len(claimed))
+		for _, auth := range claimed {
+			fmt.Printf("\tclaimed by %s (%s) at %s\n", auth.Claim.NodeID, auth.Claim.Addr,
+				time.Unix(auth.Claim.Timestamp, 0).Format(time.RFC3339))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/overlay"
+	"storj.io/storj/pkg/pointerdb"
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage/boltdb"
+)
+
+var (
+	compactCmd = &cobra.Command{
+		Use:   "compact",
+		Short: "Compact the bolt pointer and overlay databases, while the satellite is stopped",
+		RunE:  cmdCompact,
+	}
+	backupCmd = &cobra.Command{
+		Use:   "backup <dir>",
+		Short: "Copy the bolt pointer and overlay databases to dir, while the satellite is stopped",
+		Args:  cobra.ExactArgs(1),
+		RunE:  cmdBackup,
+	}
+
+	boltCfg struct {
+		PointerDB pointerdb.Config
+		Overlay   overlay.Config
+	}
+)
+
+func init() {
+	metainfoCmd.AddCommand(compactCmd)
+	metainfoCmd.AddCommand(backupCmd)
+	cfgstruct.Bind(compactCmd.Flags(), &boltCfg, cfgstruct.ConfDir(defaultConfDir))
+	cfgstruct.Bind(backupCmd.Flags(), &boltCfg, cfgstruct.ConfDir(defaultConfDir))
+}
+
+// boltDatabase is a bolt database configured for the satellite
+type boltDatabase struct {
+	name   string
+	path   string
+	bucket string
+}
+
+// boltDatabases returns the configured databases stored in bolt
+func boltDatabases() ([]boltDatabase, error) {
+	var dbs []boltDatabase
+	for _, db := range []struct {
+		name, url, bucket string
+	}{
+		{"pointerdb", boltCfg.PointerDB.DatabaseURL, pointerdb.BoltPointerBucket},
+		{"overlay", boltCfg.Overlay.DatabaseURL, overlay.OverlayBucket},
+	} {
+		dburl, err := utils.ParseURL(db.url)
+		if err != nil {
+			return nil, err
+		}
+		if dburl.Scheme != "bolt" {
+			fmt.Printf("skipping %s, it's not stored in bolt\n", db.name)
+			continue
+		}
+		dbs = append(dbs, boltDatabase{name: db.name, path: dburl.Path, bucket: db.bucket})
+	}
+	return dbs, nil
+}
+
+func cmdCompact(cmd *cobra.Command, args []string) (err error) {
+	dbs, err := boltDatabases()
+	if err != nil {
+		return err
+	}
+	for _, db := range dbs {
+		if err := compactDatabase(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compactDatabase(db boltDatabase) (err error) {
+	before, err := os.Stat(db.path)
+	if err != nil {
+		return err
+	}
+
+	client, err := boltdb.New(db.path, db.bucket)
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, client.Close()) }()
+
+	if err := client.Compact(); err != nil {
+		return err
+	}
+
+	after, err := os.Stat(db.path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("compacted %s from %d to %d bytes\n", db.name, before.Size(), after.Size())
+	return nil
+}
+
+func cmdBackup(cmd *cobra.Command, args []string) (err error) {
+	dbs, err := boltDatabases()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(args[0], 0700); err != nil {
+		return err
+	}
+	for _, db := range dbs {
+		if err := backupDatabase(db, filepath.Join(args[0], filepath.Base(db.path))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func backupDatabase(db boltDatabase, path string) (err error) {
+	client, err := boltdb.New(db.path, db.bucket)
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, client.Close()) }()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, file.Close()) }()
+
+	if err := client.Backup(file); err != nil {
+		return err
+	}
+	fmt.Printf("backed up %s to %s\n", db.name, path)
+	return nil
+}
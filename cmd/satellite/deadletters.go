@@ -0,0 +1,86 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/datarepair/repairer"
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
+)
+
+var (
+	deadLettersCmd = &cobra.Command{
+		Use:   "dead-letters",
+		Short: "Manage the segments which were moved out of the repair queue, because they failed to be repaired too often",
+	}
+	deadLettersListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the segments in the dead-letter database of the repair queue",
+		RunE:  cmdDeadLettersList,
+	}
+	deadLettersRequeueCmd = &cobra.Command{
+		Use:   "requeue <key>...",
+		Short: "Move segments from the dead-letter database back to the repair queue",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  cmdDeadLettersRequeue,
+	}
+
+	deadLettersCfg struct {
+		Repairer repairer.Config
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(deadLettersCmd)
+	for _, cmd := range []*cobra.Command{deadLettersListCmd, deadLettersRequeueCmd} {
+		deadLettersCmd.AddCommand(cmd)
+		cfgstruct.Bind(cmd.Flags(), &deadLettersCfg, cfgstruct.ConfDir(defaultConfDir))
+	}
+}
+
+func cmdDeadLettersList(cmd *cobra.Command, args []string) (err error) {
+	repairQueue, err := deadLettersCfg.Repairer.OpenQueue()
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, repairQueue.Close()) }()
+
+	var first storage.Key
+	for {
+		letters, err := repairQueue.DeadLetters(first, storage.LookupLimit)
+		if err != nil {
+			return err
+		}
+		for _, letter := range letters {
+			fmt.Printf("%s\t%s\tclaims %d\tmoved %s\t%s\n", letter.Key, letter.Segment.GetPath(),
+				letter.Claims, letter.Moved.Format(time.RFC3339), letter.Reason)
+		}
+		if len(letters) < storage.LookupLimit {
+			return nil
+		}
+		// the next page starts after the last letter
+		first = storage.NextKey(letters[len(letters)-1].Key)
+	}
+}
+
+func cmdDeadLettersRequeue(cmd *cobra.Command, args []string) (err error) {
+	repairQueue, err := deadLettersCfg.Repairer.OpenQueue()
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, repairQueue.Close()) }()
+
+	for _, key := range args {
+		if err := repairQueue.Requeue(storage.Key(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/overlay"
+	"storj.io/storj/pkg/pointerdb"
+	"storj.io/storj/pkg/process"
+	"storj.io/storj/pkg/utils"
+)
+
+var (
+	metainfoCmd = &cobra.Command{
+		Use:   "metainfo",
+		Short: "Metainfo maintenance commands",
+	}
+	fsckCmd = &cobra.Command{
+		Use:   "fsck",
+		Short: "Check pointers against the overlay without modifying anything",
+		RunE:  cmdFsck,
+	}
+
+	fsckCfg struct {
+		PointerDB pointerdb.Config
+		Overlay   overlay.Config
+		Verbose   bool `default:"false" help:"print every problem found for each segment"`
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(metainfoCmd)
+	metainfoCmd.AddCommand(fsckCmd)
+	cfgstruct.Bind(fsckCmd.Flags(), &fsckCfg, cfgstruct.ConfDir(defaultConfDir))
+}
+
+func cmdFsck(cmd *cobra.Command, args []string) (err error) {
+	ctx := process.Ctx(cmd)
+
+	db, err := pointerdb.NewKeyValueStore(fsckCfg.PointerDB.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, db.Close()) }()
+
+	store, err := fsckCfg.PointerDB.EncryptAtRest.Wrap(db)
+	if err != nil {
+		return err
+	}
+
+	cache, err := fsckCfg.Overlay.NewCache(nil)
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, cache.DB.Close()) }()
+
+	report, err := pointerdb.Fsck(ctx, store, cache)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range report.Segments {
+		fmt.Printf("%s\t%s\tonline %d/%d\n", segment.Health, segment.Path, segment.Online, segment.Total)
+		if !fsckCfg.Verbose {
+			continue
+		}
+		for _, problem := range segment.Problems {
+			fmt.Printf("\tproblem: %s\n", problem)
+		}
+		if len(segment.MissingNodes) > 0 {
+			fmt.Printf("\tmissing nodes: %s\n", strings.Join(segment.MissingNodes, ", "))
+		}
+	}
+
+	fmt.Printf("checked: %d, inline: %d, healthy: %d, repairable: %d, irreparable: %d, invalid: %d\n",
+		report.Checked, report.Inline, report.Healthy, report.Repairable, report.Irreparable, report.Invalid)
+	return nil
+}
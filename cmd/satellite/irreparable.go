@@ -0,0 +1,132 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/datarepair/checker"
+	"storj.io/storj/pkg/pointerdb"
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
+)
+
+var (
+	irreparableCmd = &cobra.Command{
+		Use:   "irreparable",
+		Short: "Manage the segments the checker found to be irreparable",
+	}
+	irreparableListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the irreparable segments",
+		RunE:  cmdIrreparableList,
+	}
+	irreparableAcceptCmd = &cobra.Command{
+		Use:   "accept <path>",
+		Short: "Accept the loss of an irreparable segment, so it isn't retried anymore",
+		Args:  cobra.ExactArgs(1),
+		RunE:  cmdIrreparableAccept,
+	}
+	irreparableDeleteCmd = &cobra.Command{
+		Use:   "delete <path>",
+		Short: "Delete the pointer of an irreparable segment",
+		Args:  cobra.ExactArgs(1),
+		RunE:  cmdIrreparableDelete,
+	}
+
+	irreparableCfg struct {
+		Checker   checker.Config
+		PointerDB pointerdb.Config
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(irreparableCmd)
+	for _, cmd := range []*cobra.Command{irreparableListCmd, irreparableAcceptCmd, irreparableDeleteCmd} {
+		irreparableCmd.AddCommand(cmd)
+		cfgstruct.Bind(cmd.Flags(), &irreparableCfg, cfgstruct.ConfDir(defaultConfDir))
+	}
+}
+
+func cmdIrreparableList(cmd *cobra.Command, args []string) (err error) {
+	irreparable, err := irreparableCfg.Checker.OpenIrreparableDB()
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, irreparable.Close()) }()
+
+	segments, err := irreparable.List()
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		var status string
+		switch {
+		case segment.GetLossAccepted():
+			status = "accepted"
+		case segment.GetEscalated():
+			status = "escalated"
+		default:
+			status = "retrying"
+		}
+		lastDetected, err := ptypes.Timestamp(segment.GetLastDetected())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\t%s\tonline %d/%d\tattempts %d\tlast detected %s\n", status, segment.GetPath(),
+			segment.GetAvailablePieces(), segment.GetMinReq(), segment.GetRepairAttempts(),
+			lastDetected.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func cmdIrreparableAccept(cmd *cobra.Command, args []string) (err error) {
+	irreparable, err := irreparableCfg.Checker.OpenIrreparableDB()
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, irreparable.Close()) }()
+
+	segment, err := irreparable.Get(args[0])
+	if err != nil {
+		return err
+	}
+	segment.LossAccepted = true
+	return irreparable.Put(segment)
+}
+
+func cmdIrreparableDelete(cmd *cobra.Command, args []string) (err error) {
+	irreparable, err := irreparableCfg.Checker.OpenIrreparableDB()
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, irreparable.Close()) }()
+
+	// only segments known to be irreparable are deleted
+	if _, err := irreparable.Get(args[0]); err != nil {
+		return err
+	}
+
+	db, err := pointerdb.NewKeyValueStore(irreparableCfg.PointerDB.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, db.Close()) }()
+
+	store, err := irreparableCfg.PointerDB.EncryptAtRest.Wrap(db)
+	if err != nil {
+		return err
+	}
+
+	err = store.Delete(storage.Key(args[0]))
+	if err != nil && !storage.ErrKeyNotFound.Has(err) {
+		return err
+	}
+	return irreparable.Delete(args[0])
+}
@@ -8,11 +8,14 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/mr-tron/base58/base58"
 	"github.com/spf13/cobra"
 
 	"storj.io/storj/pkg/auth/grpcauth"
+	"storj.io/storj/pkg/certificates"
 	"storj.io/storj/pkg/cfgstruct"
 	"storj.io/storj/pkg/kademlia"
+	"storj.io/storj/pkg/macaroon"
 	"storj.io/storj/pkg/overlay"
 	mockOverlay "storj.io/storj/pkg/overlay/mocks"
 	"storj.io/storj/pkg/pointerdb"
@@ -46,6 +49,7 @@ var (
 		Overlay     overlay.Config
 		MockOverlay mockOverlay.Config
 		StatDB      statdb.Config
+		Signer      certificates.CertSigningConfig
 		// RepairQueue   queue.Config
 		// RepairChecker checker.Config
 		// Repairer      repairer.Config
@@ -79,6 +83,7 @@ func cmdRun(cmd *cobra.Command, args []string) (err error) {
 		runCfg.PointerDB,
 		o,
 		runCfg.StatDB,
+		runCfg.Signer,
 	)
 }
 
@@ -118,9 +123,15 @@ func cmdSetup(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
+	apiKeySecret, err := macaroon.NewSecret()
+	if err != nil {
+		return err
+	}
+
 	o := map[string]interface{}{
-		"identity.cert-path": setupCfg.Identity.CertPath,
-		"identity.key-path":  setupCfg.Identity.KeyPath,
+		"identity.cert-path":        setupCfg.Identity.CertPath,
+		"identity.key-path":         setupCfg.Identity.KeyPath,
+		"pointer-db.api-key-secret": base58.Encode(apiKeySecret),
 	}
 
 	return process.SaveConfig(runCmd.Flags(),
@@ -130,5 +141,13 @@ func cmdSetup(cmd *cobra.Command, args []string) (err error) {
 func main() {
 	runCmd.Flags().String("config",
 		filepath.Join(defaultConfDir, "config.yaml"), "path to configuration")
+	fsckCmd.Flags().String("config",
+		filepath.Join(defaultConfDir, "config.yaml"), "path to configuration")
+	apiKeyCreateCmd.Flags().String("config",
+		filepath.Join(defaultConfDir, "config.yaml"), "path to configuration")
+	for _, cmd := range []*cobra.Command{irreparableListCmd, irreparableAcceptCmd, irreparableDeleteCmd, repairCmd} {
+		cmd.Flags().String("config",
+			filepath.Join(defaultConfDir, "config.yaml"), "path to configuration")
+	}
 	process.Exec(rootCmd)
 }
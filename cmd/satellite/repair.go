@@ -0,0 +1,69 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/process"
+	"storj.io/storj/pkg/provider"
+	"storj.io/storj/pkg/utils"
+)
+
+var (
+	repairCmd = &cobra.Command{
+		Use:   "repair <path> [<lost piece number>...]",
+		Short: "Repair a segment right away, rebuilding the given pieces and the ones on nodes which aren't in the overlay",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  cmdRepair,
+	}
+
+	repairCfg struct {
+		Identity provider.IdentityConfig
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(repairCmd)
+	cfgstruct.Bind(repairCmd.Flags(), &repairCfg, cfgstruct.ConfDir(defaultConfDir))
+}
+
+func cmdRepair(cmd *cobra.Command, args []string) (err error) {
+	req := &pb.RepairSegmentRequest{Path: args[0]}
+	for _, arg := range args[1:] {
+		piece, err := strconv.ParseInt(arg, 10, 32)
+		if err != nil {
+			return err
+		}
+		req.LostPieces = append(req.LostPieces, int32(piece))
+	}
+
+	identity, err := repairCfg.Identity.Load()
+	if err != nil {
+		return err
+	}
+	dialOpt, err := identity.DialOption()
+	if err != nil {
+		return err
+	}
+
+	// the repairs are requested from the private services of the satellite
+	address := repairCfg.Identity.PrivateAddress
+	if address == "" {
+		address = repairCfg.Identity.Address
+	}
+	conn, err := grpc.Dial(address, dialOpt)
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, conn.Close()) }()
+
+	_, err = pb.NewDataRepairClient(conn).RepairSegment(process.Ctx(cmd), req)
+	return err
+}
@@ -0,0 +1,21 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/pkg/process"
+)
+
+var (
+	rootCmd = &cobra.Command{
+		Use:   "storage",
+		Short: "Key/value store maintenance",
+	}
+)
+
+func main() {
+	process.Exec(rootCmd)
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/boltdb"
+	"storj.io/storj/storage/postgreskv"
+	"storj.io/storj/storage/redis"
+)
+
+var (
+	migrateCmd = &cobra.Command{
+		Use:   "migrate",
+		Short: "Copy all keys and values from one key/value store to another",
+		RunE:  cmdMigrate,
+	}
+
+	migrateCfg struct {
+		From       string `help:"the database connection string to copy from" default:""`
+		FromBucket string `help:"the bucket to copy from, if copying from bolt" default:""`
+		To         string `help:"the database connection string to copy to" default:""`
+		ToBucket   string `help:"the bucket to copy to, if copying to bolt" default:""`
+		Resume     string `help:"the hex encoded key an interrupted migration reported last, to copy the keys after it" default:""`
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	cfgstruct.Bind(migrateCmd.Flags(), &migrateCfg)
+}
+
+// openStore opens the key/value store at dbURL, using bucket for bolt
+func openStore(dbURL, bucket string) (storage.KeyValueStore, error) {
+	dburl, err := utils.ParseURL(dbURL)
+	if err != nil {
+		return nil, err
+	}
+	switch dburl.Scheme {
+	case "bolt":
+		if bucket == "" {
+			return nil, errs.New("a bucket is required for %s", dbURL)
+		}
+		return boltdb.New(dburl.Path, bucket)
+	case "redis":
+		client, err := redis.NewClientFrom(dbURL)
+		if err != nil {
+			return nil, err
+		}
+		// the expiration times of values aren't copied, so they mustn't
+		// expire after the default ttl of the client
+		client.TTL = 0
+		return client, nil
+	case "postgres", "postgresql":
+		return postgreskv.New(dbURL)
+	default:
+		return nil, errs.New("unsupported db scheme: %s", dburl.Scheme)
+	}
+}
+
+func cmdMigrate(cmd *cobra.Command, args []string) (err error) {
+	after, err := hex.DecodeString(migrateCfg.Resume)
+	if err != nil {
+		return errs.New("invalid key to resume after: %v", err)
+	}
+	if len(after) == 0 {
+		after = nil
+	}
+
+	from, err := openStore(migrateCfg.From, migrateCfg.FromBucket)
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, from.Close()) }()
+
+	to, err := openStore(migrateCfg.To, migrateCfg.ToBucket)
+	if err != nil {
+		return err
+	}
+	defer func() { err = utils.CombineErrors(err, to.Close()) }()
+
+	var last storage.Key
+	err = storage.Migrate(from, to, after, func(copied int64, key storage.Key) {
+		last = storage.CloneKey(key)
+		fmt.Printf("copied %d values, up to %q\n", copied, key)
+	})
+	if err != nil && last != nil {
+		fmt.Printf("migration interrupted, resume it with --resume %s\n", hex.EncodeToString(last))
+	}
+	return err
+}
@@ -9,34 +9,69 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/cheggaaa/pb"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 
 	"storj.io/storj/internal/fpath"
 	"storj.io/storj/pkg/process"
 	"storj.io/storj/pkg/storage/buckets"
 	"storj.io/storj/pkg/storage/objects"
+	"storj.io/storj/pkg/storage/streams"
 	"storj.io/storj/pkg/utils"
 )
 
 var (
-	progress *bool
+	progress    *bool
+	progressLog *string
+	cpRecursive *bool
+
+	// contentType and metadata are the Content-Type and user-defined
+	// metadata of the objects uploaded by cp and put
+	contentType string
+	metadata    []string
 )
 
 func init() {
 	cpCmd := addCmd(&cobra.Command{
 		Use:   "cp",
-		Short: "Copies a local file or Storj object to another location locally or in Storj",
+		Short: "Copies a local file or Storj object to another location locally or in Storj, where - is standard in or out",
 		RunE:  copyMain,
 	}, CLICmd)
 	progress = cpCmd.Flags().Bool("progress", true, "if true, show progress")
+	progressLog = cpCmd.Flags().String("progress-log", "", "file the progress of the transfers is appended to as JSON lines, if set")
+	cpRecursive = cpCmd.Flags().Bool("recursive", false, "if true, copy the files or objects under the source recursively")
+	addMetaFlags(cpCmd)
 }
 
-// upload transfers src from local machine to s3 compatible object dst
-func upload(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FPath) error {
+// addMetaFlags adds the flags setting the metadata of uploaded objects to cmd
+func addMetaFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&contentType, "content-type", "", "the Content-Type of the objects. detected from the file extension if empty")
+	cmd.Flags().StringArrayVar(&metadata, "metadata", nil, "user-defined metadata of the objects as key=value. may be repeated")
+}
+
+// objectMeta returns the metadata of the objects to upload from the flags
+func objectMeta() (objects.SerializableMeta, error) {
+	meta := objects.SerializableMeta{ContentType: contentType}
+	for _, kv := range metadata {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return objects.SerializableMeta{}, fmt.Errorf("Invalid metadata %q, use format key=value", kv)
+		}
+		if meta.UserDefined == nil {
+			meta.UserDefined = make(map[string]string)
+		}
+		meta.UserDefined[parts[0]] = parts[1]
+	}
+	return meta, nil
+}
+
+// upload transfers src from local machine to s3 compatible object dst with
+// the given metadata
+func upload(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FPath, meta objects.SerializableMeta) error {
 	if !src.IsLocal() {
 		return fmt.Errorf("source must be local path: %s", src)
 	}
@@ -47,12 +82,15 @@ func upload(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FP
 
 	// if object name not specified, default to filename
 	if strings.HasSuffix(dst.Path(), "/") || dst.Path() == "" {
+		if src.IsStdio() {
+			return fmt.Errorf("destination must be an object when copying from standard in: %s", dst)
+		}
 		dst = dst.Join(src.Base())
 	}
 
 	var f *os.File
 	var err error
-	if src.Base() == "-" {
+	if src.IsStdio() {
 		f = os.Stdin
 	} else {
 		f, err = os.Open(src.Path())
@@ -76,24 +114,47 @@ func upload(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FP
 		return err
 	}
 
-	r := io.Reader(f)
-	var bar *pb.ProgressBar
-	if *progress {
-		bar = pb.New(int(fi.Size())).SetUnits(pb.U_BYTES)
-		bar.Start()
-		r = bar.NewProxyReader(r)
+	// the uploads of files are resumed after the segments committed before
+	var offset int64
+	var statePath string
+	if f != os.Stdin {
+		bucket, err := bs.Get(ctx, dst.Bucket())
+		if err != nil {
+			return err
+		}
+		segmentSize := bucket.SegmentSize
+		if segmentSize == 0 {
+			segmentSize = cfg.SegmentSize
+		}
+		statePath, offset, ctx, err = resumeUpload(ctx, src, fi, dst, segmentSize)
+		if err != nil {
+			return err
+		}
+		if offset > 0 {
+			if _, err := f.Seek(offset, io.SeekStart); err != nil {
+				return err
+			}
+			fmt.Printf("Resuming upload of %s at %d bytes\n", src.String(), offset)
+		}
+	}
+
+	t, err := startTransfer(src, dst, fi.Size(), offset)
+	if err != nil {
+		return err
 	}
 
-	meta := objects.SerializableMeta{}
 	expTime := time.Time{}
 
-	_, err = o.Put(ctx, dst.Path(), r, meta, expTime)
+	_, err = o.Put(t.context(ctx), dst.Path(), t.reader(f), meta, expTime)
+	t.finish(err)
 	if err != nil {
 		return err
 	}
 
-	if bar != nil {
-		bar.Finish()
+	if statePath != "" {
+		if err := os.Remove(statePath); err != nil && !os.IsNotExist(err) {
+			zap.S().Warnf("Failed to remove the state of the upload %s: %v", statePath, err)
+		}
 	}
 
 	fmt.Printf("Created %s\n", dst.String())
@@ -101,6 +162,43 @@ func upload(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FP
 	return nil
 }
 
+// resumeUpload returns a context the upload of the file src with info fi to
+// dst in segments of segmentSize bytes is resumed with, which keeps the state
+// of the upload at statePath, and the offset in the file the upload continues
+// at
+func resumeUpload(ctx context.Context, src fpath.FPath, fi os.FileInfo, dst fpath.FPath, segmentSize int64) (statePath string, offset int64, _ context.Context, err error) {
+	source, err := filepath.Abs(src.Path())
+	if err != nil {
+		return "", 0, nil, err
+	}
+	state := &uploadState{
+		Source:      source,
+		Size:        fi.Size(),
+		Modified:    fi.ModTime(),
+		SegmentSize: segmentSize,
+	}
+
+	statePath = uploadStatePath(cfg.UploadState, dst)
+	saved, err := loadUploadState(statePath)
+	if err != nil {
+		zap.S().Warnf("Failed to load the state of the upload %s, starting it over: %v", statePath, err)
+	}
+	if state.resumes(saved) {
+		state.Segments = saved.Segments
+	}
+	if err := state.save(statePath); err != nil {
+		return "", 0, nil, err
+	}
+
+	resume := streams.NewResume(state.Segments, func(committed int64) {
+		state.Segments = committed
+		if err := state.save(statePath); err != nil {
+			zap.S().Warnf("Failed to save the state of the upload %s: %v", statePath, err)
+		}
+	})
+	return statePath, state.Segments * state.SegmentSize, streams.WithResume(ctx, resume), nil
+}
+
 // download transfers s3 compatible object src to dst on local machine
 func download(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FPath) error {
 	if src.IsLocal() {
@@ -121,25 +219,12 @@ func download(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.
 		return err
 	}
 
-	r, err := rr.Range(ctx, 0, rr.Size())
-	if err != nil {
-		return err
-	}
-	defer utils.LogClose(r)
-
-	var bar *pb.ProgressBar
-	if *progress {
-		bar = pb.New(int(rr.Size())).SetUnits(pb.U_BYTES)
-		bar.Start()
-		r = bar.NewProxyReader(r)
-	}
-
 	if fi, err := os.Stat(dst.Path()); err == nil && fi.IsDir() {
 		dst = dst.Join((src.Base()))
 	}
 
 	var f *os.File
-	if dst.Base() == "-" {
+	if dst.IsStdio() {
 		f = os.Stdout
 	} else {
 		f, err = os.Create(dst.Path())
@@ -149,24 +234,32 @@ func download(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.
 		defer utils.LogClose(f)
 	}
 
-	_, err = io.Copy(f, r)
+	t, err := startTransfer(src, dst, rr.Size(), 0)
 	if err != nil {
 		return err
 	}
 
-	if bar != nil {
-		bar.Finish()
+	r, err := rr.Range(t.context(ctx), 0, rr.Size())
+	if err == nil {
+		_, err = io.Copy(f, t.reader(r))
+		utils.LogClose(r)
+	}
+	t.finish(err)
+	if err != nil {
+		return err
 	}
 
-	if dst.Base() != "-" {
+	if !dst.IsStdio() {
 		fmt.Printf("Downloaded %s to %s\n", src.String(), dst.String())
 	}
 
 	return nil
 }
 
-// copy copies s3 compatible object src to s3 compatible object dst
-func copy(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FPath) error {
+// copy copies s3 compatible object src to s3 compatible object dst, with the
+// metadata of src, where the Content-Type and user-defined metadata of meta
+// take precedence
+func copy(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FPath, meta objects.SerializableMeta) error {
 	if src.IsLocal() {
 		return fmt.Errorf("source must be Storj URL: %s", src)
 	}
@@ -180,24 +273,11 @@ func copy(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FPat
 		return err
 	}
 
-	rr, _, err := o.Get(ctx, src.Path())
+	rr, srcMeta, err := o.Get(ctx, src.Path())
 	if err != nil {
 		return err
 	}
 
-	r, err := rr.Range(ctx, 0, rr.Size())
-	if err != nil {
-		return err
-	}
-	defer utils.LogClose(r)
-
-	var bar *pb.ProgressBar
-	if *progress {
-		bar = pb.New(int(rr.Size())).SetUnits(pb.U_BYTES)
-		bar.Start()
-		r = bar.NewProxyReader(r)
-	}
-
 	if dst.Bucket() != src.Bucket() {
 		o, err = bs.GetObjectStore(ctx, dst.Bucket())
 		if err != nil {
@@ -205,7 +285,17 @@ func copy(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FPat
 		}
 	}
 
-	meta := objects.SerializableMeta{}
+	if meta.ContentType == "" {
+		meta.ContentType = srcMeta.ContentType
+	}
+	userDefined := make(map[string]string)
+	for k, v := range srcMeta.UserDefined {
+		userDefined[k] = v
+	}
+	for k, v := range meta.UserDefined {
+		userDefined[k] = v
+	}
+	meta.UserDefined = userDefined
 	expTime := time.Time{}
 
 	// if destination object name not specified, default to source object name
@@ -213,13 +303,19 @@ func copy(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FPat
 		dst = dst.Join(src.Base())
 	}
 
-	_, err = o.Put(ctx, dst.Path(), r, meta, expTime)
+	t, err := startTransfer(src, dst, rr.Size(), 0)
 	if err != nil {
 		return err
 	}
 
-	if bar != nil {
-		bar.Finish()
+	r, err := rr.Range(t.context(ctx), 0, rr.Size())
+	if err == nil {
+		_, err = o.Put(t.context(ctx), dst.Path(), t.reader(r), meta, expTime)
+		utils.LogClose(r)
+	}
+	t.finish(err)
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("%s copied to %s\n", src.String(), dst.String())
@@ -257,9 +353,18 @@ func copyMain(cmd *cobra.Command, args []string) (err error) {
 		return errors.New("At least one of the source or the desination must be a Storj URL")
 	}
 
+	meta, err := objectMeta()
+	if err != nil {
+		return err
+	}
+
+	if *cpRecursive {
+		return copyRecursive(ctx, bs, src, dst, meta)
+	}
+
 	// if uploading
 	if src.IsLocal() {
-		return upload(ctx, bs, src, dst)
+		return upload(ctx, bs, src, dst, meta)
 	}
 
 	// if downloading
@@ -268,5 +373,57 @@ func copyMain(cmd *cobra.Command, args []string) (err error) {
 	}
 
 	// if copying from one remote location to another
-	return copy(ctx, bs, src, dst)
+	return copy(ctx, bs, src, dst, meta)
+}
+
+// copyRecursive copies the files in the local directory or the objects with
+// the prefix src to the same paths relative to dst, uploading them with meta
+func copyRecursive(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FPath, meta objects.SerializableMeta) error {
+	if src.IsStdio() || dst.IsStdio() {
+		return fmt.Errorf("standard in and out can't be copied recursively")
+	}
+
+	// if uploading
+	if src.IsLocal() {
+		files, err := localFiles(src.Path())
+		if err != nil {
+			return err
+		}
+		for _, rel := range sortedFiles(files) {
+			err := upload(ctx, bs, src.Join(filepath.FromSlash(rel)), dst.Join(rel), meta)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	o, err := bs.GetObjectStore(ctx, src.Bucket())
+	if err != nil {
+		return err
+	}
+	objs, err := remoteObjects(ctx, o, src.Path())
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range sortedObjects(objs) {
+		// if downloading
+		if dst.IsLocal() {
+			file := dst.Join(filepath.FromSlash(rel))
+			if err := os.MkdirAll(filepath.Dir(file.Path()), 0755); err != nil {
+				return err
+			}
+			if err := download(ctx, bs, src.Join(rel), file); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// if copying from one remote location to another
+		if err := copy(ctx, bs, src.Join(rel), dst.Join(rel), meta); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -7,18 +7,64 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"storj.io/storj/internal/fpath"
 	"storj.io/storj/pkg/process"
+	"storj.io/storj/pkg/storj"
 	"storj.io/storj/storage"
 )
 
+var (
+	mbRedundancy  *string
+	mbShareSize   *int
+	mbSegmentSize *int64
+)
+
 func init() {
-	addCmd(&cobra.Command{
+	mbCmd := addCmd(&cobra.Command{
 		Use:   "mb",
 		Short: "Create a new bucket",
 		RunE:  makeBucket,
 	}, CLICmd)
+	mbRedundancy = mbCmd.Flags().String("redundancy", "", "required/repair/optimal/total shares of the objects of the bucket, like 29/35/80/95. the configured ones if empty")
+	mbShareSize = mbCmd.Flags().Int("share-size", 0, "the size of the erasure shares of the objects of the bucket in bytes. the configured one if 0")
+	mbSegmentSize = mbCmd.Flags().Int64("segment-size", 0, "the size of the segments of the objects of the bucket in bytes. the configured one if 0")
+	mbCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		// --rs is short for --redundancy
+		if name == "rs" {
+			name = "redundancy"
+		}
+		return pflag.NormalizedName(name)
+	})
+}
+
+// bucketRedundancy returns the redundancy scheme of the bucket to create from
+// the flags, which is zero if the bucket uses the configured one
+func bucketRedundancy() (storj.RedundancyScheme, error) {
+	if *mbRedundancy == "" {
+		if *mbShareSize != 0 {
+			return storj.RedundancyScheme{}, fmt.Errorf("--share-size requires --redundancy")
+		}
+		return storj.RedundancyScheme{}, nil
+	}
+
+	scheme := storj.RedundancyScheme{
+		Algorithm: storj.ReedSolomon,
+		ShareSize: int64(*mbShareSize),
+	}
+	if scheme.ShareSize == 0 {
+		scheme.ShareSize = int64(cfg.ErasureShareSize)
+	}
+	_, err := fmt.Sscanf(*mbRedundancy, "%d/%d/%d/%d",
+		&scheme.RequiredShares, &scheme.RepairShares, &scheme.OptimalShares, &scheme.TotalShares)
+	if err != nil {
+		return storj.RedundancyScheme{}, fmt.Errorf("Invalid redundancy %q, use format required/repair/optimal/total", *mbRedundancy)
+	}
+	if scheme.ShareSize*int64(scheme.RequiredShares)%int64(cfg.EncBlockSize) != 0 {
+		return storj.RedundancyScheme{}, fmt.Errorf("Encryption block size must be a multiple of share size * required shares")
+	}
+	return scheme, nil
 }
 
 func makeBucket(cmd *cobra.Command, args []string) error {
@@ -41,6 +87,17 @@ func makeBucket(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("Nested buckets not supported, use format sj://bucket/")
 	}
 
+	redundancy, err := bucketRedundancy()
+	if err != nil {
+		return err
+	}
+	if *mbSegmentSize < 0 {
+		return fmt.Errorf("Invalid segment size %d", *mbSegmentSize)
+	}
+	if redundancy.ShareSize != 0 && *mbSegmentSize%(redundancy.ShareSize*int64(redundancy.RequiredShares)) != 0 {
+		return fmt.Errorf("Segment size must be a multiple of share size * required shares")
+	}
+
 	bs, err := cfg.BucketStore(ctx)
 	if err != nil {
 		return err
@@ -53,7 +110,7 @@ func makeBucket(cmd *cobra.Command, args []string) error {
 	if !storage.ErrKeyNotFound.Has(err) {
 		return err
 	}
-	_, err = bs.Put(ctx, dst.Bucket())
+	_, err = bs.Put(ctx, dst.Bucket(), redundancy, *mbSegmentSize)
 	if err != nil {
 		return err
 	}
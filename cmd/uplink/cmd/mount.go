@@ -21,6 +21,7 @@ import (
 
 	"storj.io/storj/internal/fpath"
 	"storj.io/storj/pkg/process"
+	"storj.io/storj/pkg/ranger"
 	"storj.io/storj/pkg/storage/meta"
 	"storj.io/storj/pkg/storage/objects"
 	"storj.io/storj/pkg/utils"
@@ -197,6 +198,7 @@ type storjFile struct {
 	name            string
 	ctx             context.Context
 	store           objects.Store
+	ranger          ranger.Ranger // kept when seeking, so the object isn't got again
 	reader          io.ReadCloser
 	predictedOffset int64
 	nodefs.File
@@ -228,12 +230,17 @@ func (f *storjFile) Read(buf []byte, off int64) (res fuse.ReadResult, code fuse.
 
 func (f *storjFile) getReader(off int64) (io.ReadCloser, error) {
 	if f.reader == nil {
-		ranger, _, err := f.store.Get(f.ctx, f.name)
-		if err != nil {
-			return nil, err
+		if f.ranger == nil {
+			rr, _, err := f.store.Get(f.ctx, f.name)
+			if err != nil {
+				return nil, err
+			}
+			f.ranger = rr
 		}
 
-		f.reader, err = ranger.Range(f.ctx, off, ranger.Size()-off)
+		// just the stripes and erasure shares from the offset are read
+		var err error
+		f.reader, err = f.ranger.Range(f.ctx, off, f.ranger.Size()-off)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,71 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/internal/fpath"
+)
+
+var (
+	presignMethod   *string
+	presignExpires  *time.Duration
+	presignEndpoint *string
+	presignSecure   *bool
+)
+
+func init() {
+	presignCmd := addCmd(&cobra.Command{
+		Use:   "presign",
+		Short: "Print a presigned URL of an object, with which it can be downloaded or uploaded through the gateway without its keys",
+		RunE:  presignMain,
+	}, GWCmd)
+	presignMethod = presignCmd.Flags().String("method", "GET", "the HTTP method the URL is presigned for: GET, HEAD or PUT")
+	presignExpires = presignCmd.Flags().Duration("expires", time.Hour, "how long the URL is valid for, up to 7 days")
+	presignEndpoint = presignCmd.Flags().String("endpoint", "", "the address the gateway is reached at, the address it listens on if empty")
+	presignSecure = presignCmd.Flags().Bool("secure", false, "if true, the gateway is reached with https")
+}
+
+// presignMain is the function executed when presignCmd is called
+func presignMain(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("No object specified for presign")
+	}
+
+	src, err := fpath.New(args[0])
+	if err != nil {
+		return err
+	}
+	if src.IsLocal() {
+		return fmt.Errorf("No bucket specified, use format sj://bucket/object")
+	}
+	if src.Path() == "" {
+		return fmt.Errorf("No object specified, use format sj://bucket/object")
+	}
+
+	endpoint := *presignEndpoint
+	if endpoint == "" {
+		host, port, err := net.SplitHostPort(cfg.Address)
+		if err != nil {
+			return err
+		}
+		if host == "" {
+			host = "localhost"
+		}
+		endpoint = net.JoinHostPort(host, port)
+	}
+
+	u, err := cfg.Presign(endpoint, *presignSecure, strings.ToUpper(*presignMethod), src.Bucket(), src.Path(), *presignExpires)
+	if err != nil {
+		return err
+	}
+	fmt.Println(u.String())
+	return nil
+}
@@ -0,0 +1,242 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh/terminal"
+
+	"storj.io/storj/internal/fpath"
+	ecclient "storj.io/storj/pkg/storage/ec"
+)
+
+const (
+	// barInterval is how often the progress bars of transfers are updated
+	barInterval = 200 * time.Millisecond
+	// logInterval is how often the progress of transfers is logged
+	logInterval = time.Second
+)
+
+// transfer tracks the progress of the transfer of a file or an object: the
+// bytes transferred, the pieces uploaded or downloaded, and the transfers of
+// pieces which failed. It's shown on a progress bar, if progress is on, and
+// logged as JSON lines to the progress log, if there is one.
+type transfer struct {
+	src, dst fpath.FPath
+	size     int64
+	offset   int64
+	start    time.Time
+	bar      *pb.ProgressBar
+	log      *os.File
+	done     chan struct{}
+	stopped  chan struct{}
+
+	mu      sync.Mutex
+	bytes   int64
+	pieces  int
+	failed  int
+	offline int
+}
+
+// transferEvent is a line of the progress log
+type transferEvent struct {
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event"`
+	Source  string    `json:"source"`
+	Dest    string    `json:"destination"`
+	Bytes   int64     `json:"bytes"`
+	Size    int64     `json:"size"`
+	Speed   float64   `json:"bytes_per_second"`
+	ETA     float64   `json:"eta_seconds,omitempty"`
+	Pieces  int       `json:"pieces"`
+	Failed  int       `json:"failed_pieces"`
+	Offline int       `json:"offline_nodes"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// startTransfer starts tracking the transfer of size bytes from src to dst,
+// of which offset bytes were transferred before
+func startTransfer(src, dst fpath.FPath, size, offset int64) (*transfer, error) {
+	t := &transfer{
+		src:     src,
+		dst:     dst,
+		size:    size,
+		offset:  offset,
+		start:   time.Now(),
+		bytes:   offset,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	if *progressLog != "" {
+		f, err := os.OpenFile(*progressLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		t.log = f
+		t.logEvent("start", nil)
+	}
+
+	// the transfers from standard in or to standard out are usually part of
+	// pipelines, where the bar is only shown on a terminal
+	stdio := src.IsStdio() || dst.IsStdio()
+	if *progress && (!stdio || terminal.IsTerminal(int(os.Stderr.Fd()))) {
+		// the bar is updated by the transfer, so its postfix with the
+		// pieces isn't changed while it's printed
+		t.bar = pb.New64(size).SetUnits(pb.U_BYTES)
+		t.bar.ShowSpeed = true
+		t.bar.ManualUpdate = true
+		// the bar is printed to standard error, so it isn't mixed with the
+		// objects copied to standard out
+		t.bar.Output = os.Stderr
+		t.bar.NotPrint = true
+		t.bar.Set64(offset)
+		t.bar.Start()
+		t.updateBar()
+	}
+
+	go t.run()
+	return t, nil
+}
+
+// context returns ctx, with which the transfers of pieces are reported to t
+func (t *transfer) context(ctx context.Context) context.Context {
+	return ecclient.WithPieceReports(ctx, t.report)
+}
+
+// reader returns r, whose reads are counted as transferred
+func (t *transfer) reader(r io.Reader) io.Reader {
+	return &transferReader{Reader: r, transfer: t}
+}
+
+// report counts the transfer of a piece
+func (t *transfer) report(report ecclient.PieceReport) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case report.Offline:
+		t.offline++
+	case report.Err != nil:
+		t.failed++
+	default:
+		t.pieces++
+	}
+}
+
+// finish stops tracking the transfer, which failed with err unless it's nil
+func (t *transfer) finish(err error) {
+	close(t.done)
+	<-t.stopped
+
+	if t.bar != nil {
+		t.updateBar()
+		t.bar.Finish()
+		fmt.Fprintln(os.Stderr)
+	}
+	if t.log != nil {
+		event := "done"
+		if err != nil {
+			event = "failed"
+		}
+		t.logEvent(event, err)
+		if err := t.log.Close(); err != nil {
+			zap.S().Warnf("Failed to close the progress log %s: %v", *progressLog, err)
+		}
+	}
+}
+
+// run updates the progress bar and logs the progress of the transfer until
+// it's finished
+func (t *transfer) run() {
+	defer close(t.stopped)
+
+	barTicker := time.NewTicker(barInterval)
+	defer barTicker.Stop()
+	logTicker := time.NewTicker(logInterval)
+	defer logTicker.Stop()
+	for {
+		select {
+		case <-barTicker.C:
+			if t.bar != nil {
+				t.updateBar()
+			}
+		case <-logTicker.C:
+			if t.log != nil {
+				t.logEvent("progress", nil)
+			}
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// updateBar prints the progress bar with the pieces transferred
+func (t *transfer) updateBar() {
+	t.mu.Lock()
+	t.bar.Postfix(fmt.Sprintf(" %d pieces, %d failed", t.pieces, t.failed+t.offline))
+	t.mu.Unlock()
+	t.bar.Update()
+}
+
+// logEvent logs the current progress of the transfer as event
+func (t *transfer) logEvent(event string, err error) {
+	t.mu.Lock()
+	e := transferEvent{
+		Time:    time.Now(),
+		Event:   event,
+		Source:  t.src.String(),
+		Dest:    t.dst.String(),
+		Bytes:   t.bytes,
+		Size:    t.size,
+		Pieces:  t.pieces,
+		Failed:  t.failed,
+		Offline: t.offline,
+	}
+	t.mu.Unlock()
+
+	if elapsed := e.Time.Sub(t.start).Seconds(); elapsed > 0 {
+		e.Speed = float64(e.Bytes-t.offset) / elapsed
+	}
+	if e.Speed > 0 && e.Bytes < e.Size {
+		e.ETA = float64(e.Size-e.Bytes) / e.Speed
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	data, marshalErr := json.Marshal(e)
+	if marshalErr == nil {
+		_, marshalErr = t.log.Write(append(data, '\n'))
+	}
+	if marshalErr != nil {
+		zap.S().Warnf("Failed to write to the progress log %s: %v", *progressLog, marshalErr)
+	}
+}
+
+// transferReader counts the bytes read from it as transferred
+type transferReader struct {
+	io.Reader
+	transfer *transfer
+}
+
+// Read implements io.Reader
+func (r *transferReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	r.transfer.mu.Lock()
+	r.transfer.bytes += int64(n)
+	r.transfer.mu.Unlock()
+	if r.transfer.bar != nil {
+		r.transfer.bar.Add(n)
+	}
+	return n, err
+}
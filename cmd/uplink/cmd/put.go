@@ -13,11 +13,12 @@ import (
 )
 
 func init() {
-	addCmd(&cobra.Command{
+	putCmd := addCmd(&cobra.Command{
 		Use:   "put",
 		Short: "Copies data from standard in to a Storj object",
 		RunE:  putMain,
 	}, CLICmd)
+	addMetaFlags(putCmd)
 }
 
 // putMain is the function executed when putCmd is called
@@ -47,5 +48,10 @@ func putMain(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
-	return upload(ctx, bs, src, dst)
+	meta, err := objectMeta()
+	if err != nil {
+		return err
+	}
+
+	return upload(ctx, bs, src, dst, meta)
 }
@@ -0,0 +1,63 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/pkg/macaroon"
+	"storj.io/storj/pkg/pb"
+)
+
+var (
+	restrictBuckets  *string
+	restrictExpires  *time.Duration
+	restrictReadOnly *bool
+)
+
+func init() {
+	restrictCmd := addCmd(&cobra.Command{
+		Use:   "restrict",
+		Short: "Print a restricted copy of the api key, to be shared with others",
+		RunE:  restrictKey,
+	}, CLICmd)
+	restrictBuckets = restrictCmd.Flags().String("buckets", "", "comma separated list of the only buckets the key can be used for")
+	restrictExpires = restrictCmd.Flags().Duration("expires", 0, "how long the key is valid for, forever if 0")
+	restrictReadOnly = restrictCmd.Flags().Bool("read-only", false, "if true, the key can't be used to upload or delete")
+}
+
+func restrictKey(cmd *cobra.Command, args []string) error {
+	key, err := macaroon.ParseAPIKey(cfg.APIKey)
+	if err != nil {
+		return err
+	}
+
+	caveat := &pb.Caveat{
+		DisallowWrites:  *restrictReadOnly,
+		DisallowDeletes: *restrictReadOnly,
+	}
+	for _, bucket := range strings.Split(*restrictBuckets, ",") {
+		if bucket = strings.TrimSpace(bucket); bucket != "" {
+			caveat.AllowedBuckets = append(caveat.AllowedBuckets, []byte(bucket))
+		}
+	}
+	if *restrictExpires > 0 {
+		caveat.NotAfter = time.Now().Add(*restrictExpires).Unix()
+	}
+
+	key, err = key.Restrict(caveat)
+	if err != nil {
+		return err
+	}
+	restricted, err := key.Serialize()
+	if err != nil {
+		return err
+	}
+	fmt.Println(restricted)
+	return nil
+}
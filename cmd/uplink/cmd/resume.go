@@ -0,0 +1,77 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"storj.io/storj/internal/fpath"
+)
+
+// uploadState is the state of an upload of a local file, which is kept while
+// the upload runs, so it's resumed if it's interrupted and started again
+type uploadState struct {
+	Source      string    `json:"source"`
+	Size        int64     `json:"size"`
+	Modified    time.Time `json:"modified"`
+	SegmentSize int64     `json:"segment_size"`
+	// Segments is how many segments of the file are committed
+	Segments int64 `json:"segments"`
+}
+
+// uploadStatePath returns the path the state of the upload to dst is kept at
+func uploadStatePath(dir string, dst fpath.FPath) string {
+	hash := sha256.Sum256([]byte(dst.String()))
+	return filepath.Join(dir, hex.EncodeToString(hash[:])+".json")
+}
+
+// loadUploadState loads the upload state at path, returning nil if there is
+// none
+func loadUploadState(path string) (*uploadState, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := &uploadState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// resumes returns whether the upload with state continues the one with
+// saved, which is when the same file, unchanged, is uploaded in segments of
+// the same size
+func (state *uploadState) resumes(saved *uploadState) bool {
+	return saved != nil &&
+		saved.Source == state.Source &&
+		saved.Size == state.Size &&
+		saved.Modified.Equal(state.Modified) &&
+		saved.SegmentSize == state.SegmentSize
+}
+
+// save saves state at path, replacing the state saved before at once
+func (state *uploadState) save(path string) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -4,20 +4,34 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"storj.io/storj/internal/fpath"
 	"storj.io/storj/pkg/process"
+	"storj.io/storj/pkg/storage/objects"
+	"storj.io/storj/pkg/storj"
+)
+
+var (
+	rmRecursive   *bool
+	rmYes         *bool
+	rmParallelism *int
 )
 
 func init() {
-	addCmd(&cobra.Command{
+	rmCmd := addCmd(&cobra.Command{
 		Use:   "rm",
-		Short: "Delete an object",
+		Short: "Delete an object, or all the objects under a prefix",
 		RunE:  delete,
 	}, CLICmd)
+	rmRecursive = rmCmd.Flags().Bool("recursive", false, "if true, delete all the objects under the prefix")
+	rmYes = rmCmd.Flags().Bool("yes", false, "if true, don't ask for confirmation before deleting recursively")
+	rmParallelism = rmCmd.Flags().Int("parallelism", 8, "how many objects are deleted at once when deleting recursively")
 }
 
 func delete(cmd *cobra.Command, args []string) error {
@@ -46,6 +60,28 @@ func delete(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if *rmRecursive {
+		if !*rmYes {
+			confirmed, err := confirm(fmt.Sprintf("Delete all the objects under %s?", dst))
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				return fmt.Errorf("Deletion canceled")
+			}
+		}
+
+		count, err := objects.DeletePrefix(ctx, o, dst.Path(), *rmParallelism, func(path storj.Path, err error) {
+			if err != nil {
+				fmt.Printf("Failed to delete %s: %v\n", dst.Join(path), err)
+				return
+			}
+			fmt.Printf("Deleted %s\n", dst.Join(path))
+		})
+		fmt.Printf("Deleted %d objects under %s\n", count, dst)
+		return err
+	}
+
 	err = o.Delete(ctx, dst.Path())
 	if err != nil {
 		return err
@@ -55,3 +91,18 @@ func delete(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// confirm asks the question on standard out and returns whether it was
+// answered with yes on standard in
+func confirm(question string) (bool, error) {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
@@ -19,6 +19,7 @@ const defaultConfDir = "$HOME/.storj/uplink"
 // Config is miniogw.Config configuration
 type Config struct {
 	miniogw.Config
+	UploadState string `help:"directory the state of running uploads is kept in, so they're resumed if they're interrupted" default:"$CONFDIR/uploads"`
 }
 
 var cfg Config
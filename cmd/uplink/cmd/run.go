@@ -36,6 +36,11 @@ func cmdRun(cmd *cobra.Command, args []string) (err error) {
 
 	fmt.Printf("Starting Storj S3-compatible gateway!\n\n")
 	fmt.Printf("Endpoint: %s\n", address)
+	if cfg.Tenants != "" {
+		// every tenant's gateway checks the satellite when it starts
+		fmt.Printf("Tenants: %s\n", cfg.Tenants)
+		return cfg.Run(process.Ctx(cmd))
+	}
 	fmt.Printf("Access key: %s\n", cfg.AccessKey)
 	fmt.Printf("Secret key: %s\n", cfg.SecretKey)
 
@@ -30,7 +30,7 @@ var (
 		Overwrite          bool   `default:"false" help:"whether to overwrite pre-existing configuration files"`
 		SatelliteAddr      string `default:"localhost:7778" help:"the address to use for the satellite"`
 		APIKey             string `default:"" help:"the api key to use for the satellite"`
-		EncKey             string `default:"" help:"your root encryption key"`
+		EncKey             string `default:"" help:"the passphrase your root encryption key is derived from"`
 		GenerateMinioCerts bool   `default:"false" help:"generate sample TLS certs for Minio GW"`
 	}
 )
@@ -51,6 +51,10 @@ func cmdSetup(cmd *cobra.Command, args []string) (err error) {
 		return fmt.Errorf("%s - Invalid flag. Pleas see --help", flagname)
 	}
 
+	if setupCfg.EncKey == "" {
+		return fmt.Errorf("No encryption passphrase specified, use --enc-key")
+	}
+
 	_, err = os.Stat(setupCfg.BasePath)
 	if !setupCfg.Overwrite && err == nil {
 		return fmt.Errorf("An uplink configuration already exists. Rerun with --overwrite")
@@ -98,14 +102,15 @@ func cmdSetup(cmd *cobra.Command, args []string) (err error) {
 	}
 
 	o := map[string]interface{}{
-		"cert-path":       setupCfg.Identity.CertPath,
-		"key-path":        setupCfg.Identity.KeyPath,
-		"api-key":         setupCfg.APIKey,
-		"pointer-db-addr": setupCfg.SatelliteAddr,
-		"overlay-addr":    setupCfg.SatelliteAddr,
-		"access-key":      accessKey,
-		"secret-key":      secretKey,
-		"enc-key":         setupCfg.EncKey,
+		"cert-path":          setupCfg.Identity.CertPath,
+		"key-path":           setupCfg.Identity.KeyPath,
+		"api-key":            setupCfg.APIKey,
+		"pointer-db-addr":    setupCfg.SatelliteAddr,
+		"overlay-addr":       setupCfg.SatelliteAddr,
+		"access-key":         accessKey,
+		"secret-key":         secretKey,
+		"enc-key":            setupCfg.EncKey,
+		"enc-key-derivation": "argon2id",
 	}
 
 	return process.SaveConfig(runCmd.Flags(),
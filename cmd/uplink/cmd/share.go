@@ -0,0 +1,90 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/internal/fpath"
+	"storj.io/storj/pkg/macaroon"
+	"storj.io/storj/pkg/miniogw"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/storage/streams"
+	"storj.io/storj/pkg/storj"
+)
+
+var (
+	shareExpires  *time.Duration
+	shareReadOnly *bool
+)
+
+func init() {
+	shareCmd := addCmd(&cobra.Command{
+		Use:   "share",
+		Short: "Print the access to a bucket or a prefix only, to be shared with others, who configure it as their access",
+		RunE:  shareMain,
+	}, CLICmd)
+	shareExpires = shareCmd.Flags().Duration("expires", 0, "how long the access is valid for, forever if 0")
+	shareReadOnly = shareCmd.Flags().Bool("read-only", false, "if true, the access can't be used to upload or delete")
+}
+
+// shareMain is the function executed when shareCmd is called
+func shareMain(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("No bucket or prefix specified for share")
+	}
+
+	src, err := fpath.New(args[0])
+	if err != nil {
+		return err
+	}
+	if src.IsLocal() {
+		return fmt.Errorf("No bucket specified, use format sj://bucket/")
+	}
+	if cfg.Access != "" {
+		return errors.New("Only the api key and the encryption passphrase can be shared, not an access")
+	}
+
+	rootKey, err := cfg.RootKey()
+	if err != nil {
+		return err
+	}
+	shared, err := streams.NewSharedPrefix(storj.JoinPaths(src.Bucket(), src.Path()), rootKey)
+	if err != nil {
+		return err
+	}
+
+	key, err := macaroon.ParseAPIKey(cfg.APIKey)
+	if err != nil {
+		return err
+	}
+	caveat := &pb.Caveat{
+		DisallowWrites:  *shareReadOnly,
+		DisallowDeletes: *shareReadOnly,
+		AllowedBuckets:  [][]byte{[]byte(src.Bucket())},
+		AllowedPaths:    [][]byte{[]byte(shared.EncryptedPrefix)},
+	}
+	if *shareExpires > 0 {
+		caveat.NotAfter = time.Now().Add(*shareExpires).Unix()
+	}
+	key, err = key.Restrict(caveat)
+	if err != nil {
+		return err
+	}
+	apiKey, err := key.Serialize()
+	if err != nil {
+		return err
+	}
+
+	access, err := (&miniogw.Access{APIKey: apiKey, Shared: shared}).Serialize()
+	if err != nil {
+		return err
+	}
+	fmt.Println(access)
+	return nil
+}
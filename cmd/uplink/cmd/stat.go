@@ -0,0 +1,74 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/internal/fpath"
+	"storj.io/storj/pkg/process"
+)
+
+func init() {
+	addCmd(&cobra.Command{
+		Use:   "stat",
+		Short: "Prints the size, Content-Type and metadata of a Storj object",
+		RunE:  statMain,
+	}, CLICmd)
+}
+
+// statMain is the function executed when statCmd is called
+func statMain(cmd *cobra.Command, args []string) (err error) {
+	if len(args) == 0 {
+		return fmt.Errorf("No object specified")
+	}
+
+	ctx := process.Ctx(cmd)
+
+	src, err := fpath.New(args[0])
+	if err != nil {
+		return err
+	}
+
+	if src.IsLocal() || src.Path() == "" {
+		return fmt.Errorf("No object specified, use format sj://bucket/object")
+	}
+
+	bs, err := cfg.BucketStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	o, err := bs.GetObjectStore(ctx, src.Bucket())
+	if err != nil {
+		return err
+	}
+
+	m, err := o.Meta(ctx, src.Path())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Path:         %s\n", src.String())
+	fmt.Printf("Size:         %d\n", m.Size)
+	fmt.Printf("Modified:     %s\n", formatTime(m.Modified))
+	if !m.Expiration.IsZero() {
+		fmt.Printf("Expires:      %s\n", formatTime(m.Expiration))
+	}
+	fmt.Printf("Content-Type: %s\n", m.ContentType)
+
+	keys := make([]string, 0, len(m.UserDefined))
+	for k := range m.UserDefined {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("Metadata:     %s=%s\n", k, m.UserDefined[k])
+	}
+
+	return nil
+}
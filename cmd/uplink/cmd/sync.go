@@ -0,0 +1,325 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"storj.io/storj/internal/fpath"
+	"storj.io/storj/pkg/process"
+	"storj.io/storj/pkg/storage/buckets"
+	"storj.io/storj/pkg/storage/meta"
+	"storj.io/storj/pkg/storage/objects"
+	"storj.io/storj/pkg/utils"
+)
+
+var (
+	syncDelete *bool
+)
+
+// the user-defined metadata of the objects uploaded by sync keep the
+// modification time and the hash of the files they were uploaded from, so
+// they're compared with the files without downloading them
+const (
+	syncModifiedKey = "sync-mtime"
+	syncHashKey     = "sync-sha256"
+)
+
+func init() {
+	syncCmd := addCmd(&cobra.Command{
+		Use:   "sync",
+		Short: "Copies the files of a local directory or Storj prefix which differ from the ones at another location locally or in Storj",
+		RunE:  syncMain,
+	}, CLICmd)
+	syncDelete = syncCmd.Flags().Bool("delete", false, "if true, delete the files in the destination which aren't in the source")
+}
+
+// syncMain is the function executed when syncCmd is called
+func syncMain(cmd *cobra.Command, args []string) (err error) {
+	if len(args) == 0 {
+		return fmt.Errorf("No source specified for sync")
+	}
+	if len(args) == 1 {
+		return fmt.Errorf("No destination specified")
+	}
+
+	ctx := process.Ctx(cmd)
+
+	src, err := fpath.New(args[0])
+	if err != nil {
+		return err
+	}
+	dst, err := fpath.New(args[1])
+	if err != nil {
+		return err
+	}
+
+	if src.IsLocal() == dst.IsLocal() {
+		return errors.New("Exactly one of the source or the destination must be a Storj URL")
+	}
+
+	bs, err := cfg.BucketStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if src.IsLocal() {
+		return syncUp(ctx, bs, src, dst)
+	}
+	return syncDown(ctx, bs, src, dst)
+}
+
+// syncUp uploads the files in the local directory src which differ from the
+// objects with dst as prefix
+func syncUp(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FPath) error {
+	files, err := localFiles(src.Path())
+	if err != nil {
+		return err
+	}
+
+	o, err := bs.GetObjectStore(ctx, dst.Bucket())
+	if err != nil {
+		return err
+	}
+	objs, err := remoteObjects(ctx, o, dst.Path())
+	if err != nil {
+		return err
+	}
+
+	var copied, unchanged, deleted int
+	for _, rel := range sortedFiles(files) {
+		file := src.Join(filepath.FromSlash(rel))
+		if obj, ok := objs[rel]; ok {
+			same, err := sameContent(file.Path(), files[rel], obj)
+			if err != nil {
+				return err
+			}
+			if same {
+				unchanged++
+				continue
+			}
+		}
+
+		metadata, err := syncMetadata(file.Path(), files[rel])
+		if err != nil {
+			return err
+		}
+		if err := upload(ctx, bs, file, dst.Join(rel), metadata); err != nil {
+			return err
+		}
+		copied++
+	}
+
+	if *syncDelete {
+		for _, rel := range sortedObjects(objs) {
+			if _, ok := files[rel]; ok {
+				continue
+			}
+			if err := o.Delete(ctx, path.Join(dst.Path(), rel)); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted %s\n", dst.Join(rel))
+			deleted++
+		}
+	}
+
+	fmt.Printf("Synced %s to %s: %d copied, %d unchanged, %d deleted\n", src, dst, copied, unchanged, deleted)
+	return nil
+}
+
+// syncDown downloads the objects with src as prefix which differ from the
+// files in the local directory dst
+func syncDown(ctx context.Context, bs buckets.Store, src fpath.FPath, dst fpath.FPath) error {
+	o, err := bs.GetObjectStore(ctx, src.Bucket())
+	if err != nil {
+		return err
+	}
+	objs, err := remoteObjects(ctx, o, src.Path())
+	if err != nil {
+		return err
+	}
+
+	files, err := localFiles(dst.Path())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var copied, unchanged, deleted int
+	for _, rel := range sortedObjects(objs) {
+		file := dst.Join(filepath.FromSlash(rel))
+		if fi, ok := files[rel]; ok {
+			same, err := sameContent(file.Path(), fi, objs[rel])
+			if err != nil {
+				return err
+			}
+			if same {
+				unchanged++
+				continue
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(file.Path()), 0755); err != nil {
+			return err
+		}
+		if err := download(ctx, bs, src.Join(rel), file); err != nil {
+			return err
+		}
+		// the file gets the modification time of the file the object was
+		// uploaded from, so it's known to be the same
+		if modified, err := time.Parse(time.RFC3339Nano, objs[rel].UserDefined[syncModifiedKey]); err == nil {
+			if err := os.Chtimes(file.Path(), modified, modified); err != nil {
+				return err
+			}
+		}
+		copied++
+	}
+
+	if *syncDelete {
+		for _, rel := range sortedFiles(files) {
+			if _, ok := objs[rel]; ok {
+				continue
+			}
+			file := dst.Join(filepath.FromSlash(rel))
+			if err := os.Remove(file.Path()); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted %s\n", file)
+			deleted++
+		}
+	}
+
+	fmt.Printf("Synced %s to %s: %d copied, %d unchanged, %d deleted\n", src, dst, copied, unchanged, deleted)
+	return nil
+}
+
+// localFiles returns the info of the regular files in the directory root and
+// its subdirectories, by their slash separated path relative to root
+func localFiles(root string) (map[string]os.FileInfo, error) {
+	files := map[string]os.FileInfo{}
+	err := filepath.Walk(root, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = fi
+		return nil
+	})
+	return files, err
+}
+
+// remoteObjects returns the meta of the objects of o with prefix, by their
+// path relative to prefix
+func remoteObjects(ctx context.Context, o objects.Store, prefix string) (map[string]objects.Meta, error) {
+	objs := map[string]objects.Meta{}
+	startAfter := ""
+	for {
+		items, more, err := o.List(ctx, prefix, startAfter, "", true, 0, meta.All)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if !item.IsPrefix {
+				objs[item.Path] = item.Meta
+			}
+		}
+		if !more || len(items) == 0 {
+			return objs, nil
+		}
+		startAfter = items[len(items)-1].Path
+	}
+}
+
+// sameContent returns whether the local file with info fi has the content of
+// the object with obj meta. They're the same if they have the same size, and
+// the object was uploaded from a file with the same modification time or
+// hash.
+func sameContent(file string, fi os.FileInfo, obj objects.Meta) (bool, error) {
+	if fi.Size() != obj.Size {
+		return false, nil
+	}
+	if obj.UserDefined[syncModifiedKey] == formatModified(fi.ModTime()) {
+		return true, nil
+	}
+	hash, ok := obj.UserDefined[syncHashKey]
+	if !ok {
+		return false, nil
+	}
+	fileHash, err := hashFile(file)
+	if err != nil {
+		return false, err
+	}
+	return fileHash == hash, nil
+}
+
+// syncMetadata returns the metadata of the object uploaded from the local
+// file with info fi
+func syncMetadata(file string, fi os.FileInfo) (objects.SerializableMeta, error) {
+	hash, err := hashFile(file)
+	if err != nil {
+		return objects.SerializableMeta{}, err
+	}
+	return objects.SerializableMeta{
+		UserDefined: map[string]string{
+			syncModifiedKey: formatModified(fi.ModTime()),
+			syncHashKey:     hash,
+		},
+	}, nil
+}
+
+// hashFile returns the hex encoded SHA-256 hash of the content of file
+func hashFile(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer utils.LogClose(f)
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func formatModified(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// sortedFiles returns the paths of files in order
+func sortedFiles(files map[string]os.FileInfo) []string {
+	paths := make([]string, 0, len(files))
+	for rel := range files {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// sortedObjects returns the paths of objs in order
+func sortedObjects(objs map[string]objects.Meta) []string {
+	paths := make([]string, 0, len(objs))
+	for rel := range objs {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	return paths
+}
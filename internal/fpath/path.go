@@ -116,6 +116,12 @@ func (p FPath) IsLocal() bool {
 	return p.local
 }
 
+// IsStdio returns whether the path is "-", which refers to standard in when
+// copied from and to standard out when copied to
+func (p FPath) IsStdio() bool {
+	return p.local && p.original == "-"
+}
+
 // String returns the entire URL (untouched)
 func (p FPath) String() string {
 	return p.original
@@ -164,3 +164,20 @@ func testLocalPath(t *testing.T, url, base string, i int) {
 	assert.Equal(t, filepath.Join(url, "suffix"), fp.Join("suffix").String(), errTag)
 	assert.Equal(t, url, fp.String(), errTag)
 }
+
+func TestStdio(t *testing.T) {
+	for _, tt := range []struct {
+		path  string
+		stdio bool
+	}{
+		{"-", true},
+		{"./-", false},
+		{"dir/-", false},
+		{"sj://mybucket/-", false},
+	} {
+		p, err := New(tt.path)
+		if assert.NoError(t, err, tt.path) {
+			assert.Equal(t, tt.stdio, p.IsStdio(), tt.path)
+		}
+	}
+}
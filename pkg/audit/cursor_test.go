@@ -59,6 +59,10 @@ func (pbd *pointerDBWrapper) Delete(ctx context.Context, in *pb.DeleteRequest, o
 	return pbd.s.Delete(ctx, in)
 }
 
+func (pbd *pointerDBWrapper) Copy(ctx context.Context, in *pb.CopyRequest, opts ...grpc.CallOption) (*pb.CopyResponse, error) {
+	return pbd.s.Copy(ctx, in)
+}
+
 func TestAuditSegment(t *testing.T) {
 	type pathCount struct {
 		path  storj.Path
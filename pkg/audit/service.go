@@ -36,18 +36,21 @@ type Config struct {
 
 // Run runs the repairer with the configured values
 func (c Config) Run(ctx context.Context, server *provider.Provider) (err error) {
-	service, err := NewService(ctx, c.StatDBPort, c.Interval, c.MaxRetriesStatDB, c.Pointers, c.Transport, c.Overlay, c.ID)
+	// the node dossiers are set if the overlay runs in the same process
+	dossiers := overlay.LoadDossiersFromContext(ctx)
+	service, err := NewService(ctx, c.StatDBPort, c.Interval, c.MaxRetriesStatDB, c.Pointers, c.Transport, c.Overlay, dossiers, c.ID)
 	if err != nil {
 		return err
 	}
 	return service.Run(ctx)
 }
 
-// NewService instantiates a Service with access to a Cursor and Verifier
+// NewService instantiates a Service with access to a Cursor and Verifier,
+// which looks the nodes up in dossiers, if it isn't nil, or through overlay
 func NewService(ctx context.Context, statDBPort string, interval time.Duration, maxRetries int, pointers pdbclient.Client, transport transport.Client, overlay overlay.Client,
-	id provider.FullIdentity) (service *Service, err error) {
+	dossiers *overlay.DossierService, id provider.FullIdentity) (service *Service, err error) {
 	cursor := NewCursor(pointers)
-	verifier := NewVerifier(transport, overlay, id)
+	verifier := NewVerifier(transport, overlay, dossiers, id)
 	reporter, err := NewReporter(ctx, statDBPort, maxRetries)
 	if err != nil {
 		return nil, err
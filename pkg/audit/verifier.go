@@ -8,18 +8,21 @@ import (
 	"context"
 	"io"
 
-	"github.com/gogo/protobuf/proto"
 	"github.com/vivint/infectious"
 	monkit "gopkg.in/spacemonkeygo/monkit.v2"
 
+	"storj.io/storj/pkg/auth"
 	"storj.io/storj/pkg/dht"
+	"storj.io/storj/pkg/eestream"
 	"storj.io/storj/pkg/node"
 	"storj.io/storj/pkg/overlay"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/piecestore/rpc/client"
 	"storj.io/storj/pkg/provider"
 	sdbproto "storj.io/storj/pkg/statdb/proto"
+	"storj.io/storj/pkg/storage/segments"
 	"storj.io/storj/pkg/transport"
+	"storj.io/storj/pkg/utils"
 )
 
 var mon = monkit.Package()
@@ -43,61 +46,103 @@ type downloader interface {
 type defaultDownloader struct {
 	transport transport.Client
 	overlay   overlay.Client
-	identity  provider.FullIdentity
+	// dossiers are used to look the nodes up instead of the overlay when
+	// the audit runs in the same process as the overlay
+	dossiers *overlay.DossierService
+	identity provider.FullIdentity
 	reporter
 }
 
 // newDefaultDownloader creates a defaultDownloader
-func newDefaultDownloader(transport transport.Client, overlay overlay.Client, id provider.FullIdentity) *defaultDownloader {
-	return &defaultDownloader{transport: transport, overlay: overlay, identity: id}
+func newDefaultDownloader(transport transport.Client, overlay overlay.Client, dossiers *overlay.DossierService, id provider.FullIdentity) *defaultDownloader {
+	return &defaultDownloader{transport: transport, overlay: overlay, dossiers: dossiers, identity: id}
 }
 
-// NewVerifier creates a Verifier
-func NewVerifier(transport transport.Client, overlay overlay.Client, id provider.FullIdentity) *Verifier {
-	return &Verifier{downloader: newDefaultDownloader(transport, overlay, id)}
+// NewVerifier creates a Verifier, which looks the nodes up in dossiers, if
+// it isn't nil, or through overlay
+func NewVerifier(transport transport.Client, overlay overlay.Client, dossiers *overlay.DossierService, id provider.FullIdentity) *Verifier {
+	return &Verifier{downloader: newDefaultDownloader(transport, overlay, dossiers, id)}
+}
+
+// lookupNodes returns the nodes with the given ids, in the same order. The
+// nodes missing from the dossiers only have their id, so that their audit
+// fails as if they were offline.
+func (d *defaultDownloader) lookupNodes(ctx context.Context, nodeIDs []dht.NodeID) (nodes []*pb.Node, err error) {
+	defer mon.Task()(&ctx)(&err)
+	if d.dossiers == nil {
+		return d.overlay.BulkLookup(ctx, nodeIDs)
+	}
+
+	var ids []string
+	for _, id := range nodeIDs {
+		ids = append(ids, id.String())
+	}
+	dossiers, err := d.dossiers.GetAll(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i, dossier := range dossiers {
+		if dossier == nil {
+			nodes = append(nodes, &pb.Node{Id: ids[i]})
+			continue
+		}
+		nodes = append(nodes, dossier.Node)
+	}
+	return nodes, nil
 }
 
 func (d *defaultDownloader) dial(ctx context.Context, storageNode *pb.Node) (ps client.PSClient, err error) {
 	defer mon.Task()(&ctx)(&err)
-	c, err := d.transport.DialNode(ctx, storageNode)
+	c, release, err := transport.Acquire(ctx, d.transport, storageNode)
 	if err != nil {
 		return nil, err
 	}
-	return client.NewPSClient(c, node.IDFromString(storageNode.GetId()), 0, d.identity.Key)
+	ps, err = client.NewPooledPSClient(c, release, node.IDFromString(storageNode.GetId()), 0, d.identity.Key)
+	if err != nil {
+		return nil, utils.CombineErrors(err, release())
+	}
+	return ps, nil
 }
 
-// getShare use piece store clients to download shares from a given node
+// getShare use piece store clients to download shares from a given node. If
+// the piece has a hash, the share is verified against its hash, failing with
+// eestream.ErrShareCorrupted if it doesn't match.
 func (d *defaultDownloader) getShare(ctx context.Context, stripeIndex, shareSize, pieceNumber int,
-	id client.PieceID, pieceSize int64, node *pb.Node, authorization *pb.SignedMessage) (s share, err error) {
+	id client.PieceID, pieceSize int64, hash []byte, node *pb.Node, authorization *pb.SignedMessage) (s share, err error) {
 	defer mon.Task()(&ctx)(&err)
 
 	ps, err := d.dial(ctx, node)
 	if err != nil {
 		return s, err
 	}
+	defer utils.LogClose(ps)
 
 	derivedPieceID, err := id.Derive([]byte(node.GetId()))
 	if err != nil {
 		return s, err
 	}
 
-	allocationData := &pb.PayerBandwidthAllocation_Data{
-		Action: pb.PayerBandwidthAllocation_GET,
-	}
-
-	serializedAllocation, err := proto.Marshal(allocationData)
+	pba, err := auth.NewPayerAllocation(&pb.PayerBandwidthAllocation_Data{
+		UplinkId: d.identity.ID.Bytes(),
+		Action:   pb.PayerBandwidthAllocation_GET,
+	}, &d.identity)
 	if err != nil {
 		return s, err
 	}
 
-	pba := &pb.PayerBandwidthAllocation{
-		Data: serializedAllocation,
+	if hash != nil {
+		pieceSize = eestream.HashedPieceSize(pieceSize, shareSize)
 	}
-
 	rr, err := ps.Get(ctx, derivedPieceID, pieceSize, pba, authorization)
 	if err != nil {
 		return s, err
 	}
+	if hash != nil {
+		rr, err = eestream.VerifyShares(rr, shareSize, hash)
+		if err != nil {
+			return s, err
+		}
+	}
 
 	offset := shareSize * stripeIndex
 
@@ -130,7 +175,7 @@ func (d *defaultDownloader) DownloadShares(ctx context.Context, pointer *pb.Poin
 	for _, p := range pieces {
 		nodeIds = append(nodeIds, node.IDFromString(p.GetNodeId()))
 	}
-	nodes, err = d.overlay.BulkLookup(ctx, nodeIds)
+	nodes, err = d.lookupNodes(ctx, nodeIds)
 	if err != nil {
 		return nil, nodes, err
 	}
@@ -143,7 +188,7 @@ func (d *defaultDownloader) DownloadShares(ctx context.Context, pointer *pb.Poin
 		paddedSize := calcPadded(pointer.GetSize(), shareSize)
 		pieceSize := paddedSize / int64(pointer.Remote.Redundancy.GetMinReq())
 
-		s, err := d.getShare(ctx, stripeIndex, shareSize, i, pieceID, pieceSize, node, authorization)
+		s, err := d.getShare(ctx, stripeIndex, shareSize, i, pieceID, pieceSize, pieces[i].GetHash(), node, authorization)
 		if err != nil {
 			s = share{
 				Error:       err,
@@ -214,28 +259,70 @@ func calcPadded(size int64, blockSize int) int64 {
 func (verifier *Verifier) verify(ctx context.Context, stripeIndex int, pointer *pb.Pointer, authorization *pb.SignedMessage) (verifiedNodes []*sdbproto.Node, err error) {
 	defer mon.Task()(&ctx)(&err)
 
+	// the shares are checked with the error correction of infectious, which
+	// only knows the code of the RS scheme, so the shares of segments of the
+	// other schemes are only checked against the hashes of their pieces
+	scheme := pointer.GetRemote().GetRedundancy().GetType()
+	hashesOnly := scheme != pb.RedundancyScheme_RS
+	if hashesOnly {
+		for _, piece := range pointer.GetRemote().GetRemotePieces() {
+			if len(piece.GetHash()) == 0 {
+				return nil, Error.New("can't audit pieces without hashes of segments of redundancy scheme %v", scheme)
+			}
+		}
+	}
+
+	// nodes are only audited for the pieces they committed to store, as
+	// proven by their receipts, which the pointer must hold unaltered
+	remote := pointer.GetRemote()
+	if len(remote.GetMerkleRoot()) > 0 {
+		if err := segments.VerifyReceiptsRoot(remote); err != nil {
+			return nil, Error.Wrap(err)
+		}
+	}
+
 	shares, nodes, err := verifier.downloader.DownloadShares(ctx, pointer, stripeIndex, authorization)
 	if err != nil {
 		return nil, err
 	}
 
-	var offlineNodes []string
-	for i, share := range shares {
-		if shares[i].Error != nil {
-			offlineNodes = append(offlineNodes, nodes[share.PieceNumber].GetId())
+	// the receipts of the nodes are checked once they're looked up, which
+	// the nodes that couldn't be reached may not have been
+	pieces := remote.GetRemotePieces()
+	for _, share := range shares {
+		if share.Error != nil && !eestream.ErrShareCorrupted.Has(share.Error) {
+			continue
+		}
+		piece := pieces[share.PieceNumber]
+		if piece.GetReceipt() == nil {
+			continue
+		}
+		if err := segments.VerifyReceipt(remote, piece, nodes[share.PieceNumber]); err != nil {
+			return nil, Error.Wrap(err)
 		}
 	}
 
-	required := int(pointer.Remote.Redundancy.GetMinReq())
-	total := int(pointer.Remote.Redundancy.GetTotal())
-	pieceNums, err := auditShares(ctx, required, total, shares)
-	if err != nil {
-		return nil, err
+	var offlineNodes, failedNodes []string
+	for i, share := range shares {
+		switch {
+		case shares[i].Error == nil:
+		case eestream.ErrShareCorrupted.Has(share.Error):
+			failedNodes = append(failedNodes, nodes[share.PieceNumber].GetId())
+		default:
+			offlineNodes = append(offlineNodes, nodes[share.PieceNumber].GetId())
+		}
 	}
 
-	var failedNodes []string
-	for _, pieceNum := range pieceNums {
-		failedNodes = append(failedNodes, nodes[pieceNum].GetId())
+	if !hashesOnly {
+		required := int(pointer.Remote.Redundancy.GetMinReq())
+		total := int(pointer.Remote.Redundancy.GetTotal())
+		pieceNums, err := auditShares(ctx, required, total, shares)
+		if err != nil {
+			return nil, err
+		}
+		for _, pieceNum := range pieceNums {
+			failedNodes = append(failedNodes, nodes[pieceNum].GetId())
+		}
 	}
 
 	successNodes := getSuccessNodes(ctx, nodes, failedNodes, offlineNodes)
@@ -9,14 +9,26 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 	"github.com/vivint/infectious"
 
+	"storj.io/storj/pkg/dht"
+	"storj.io/storj/pkg/eestream"
+	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/overlay"
 	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/peertls"
+	"storj.io/storj/pkg/piecestore/rpc/client"
+	"storj.io/storj/pkg/provider"
+	"storj.io/storj/pkg/storage/segments"
+	"storj.io/storj/storage/teststore"
 )
 
 type mockDownloader struct {
 	shares map[int]share
+	// nodes are the nodes of the pieces, 30 made up ones if empty
+	nodes []*pb.Node
 }
 
 func TestPassingAudit(t *testing.T) {
@@ -100,6 +112,117 @@ func TestSomeNodesPassAudit(t *testing.T) {
 	}
 }
 
+func TestHashedAudit(t *testing.T) {
+	ctx := context.Background()
+	someData := randData(32 * 1024)
+	mockShares := make(map[int]share)
+	for i := 0; i < 30; i++ {
+		mockShares[i] = share{PieceNumber: i, Data: someData}
+	}
+	for i := 0; i < 5; i++ {
+		mockShares[i] = share{PieceNumber: i, Error: eestream.ErrShareCorrupted.New("corrupted")}
+	}
+	for i := 5; i < 10; i++ {
+		mockShares[i] = share{PieceNumber: i, Error: Error.New("unable to get node")}
+	}
+	verifier := &Verifier{downloader: &mockDownloader{shares: mockShares}}
+
+	// segments of other schemes than RS are only audited by the hashes of
+	// their pieces
+	pointer := makePointer(30)
+	pointer.Remote.Redundancy.Type = pb.RedundancyScheme_RS_SIMD
+	_, err := verifier.verify(ctx, 6, pointer, nil)
+	assert.Error(t, err)
+
+	for _, piece := range pointer.Remote.RemotePieces {
+		piece.Hash = []byte("hash")
+	}
+	verifiedNodes, err := verifier.verify(ctx, 6, pointer, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var failed, offline, passed int
+	for _, node := range verifiedNodes {
+		switch {
+		case !node.IsUp:
+			offline++
+		case !node.AuditSuccess:
+			failed++
+		default:
+			passed++
+		}
+	}
+	assert.Equal(t, 5, failed)
+	assert.Equal(t, 5, offline)
+	assert.Equal(t, 20, passed)
+}
+
+func TestAuditReceipts(t *testing.T) {
+	ctx := context.Background()
+	pieceID := client.NewPieceID()
+	pointer := makePointer(3)
+	pointer.Remote.PieceId = string(pieceID)
+	pointer.Remote.Redundancy.Type = pb.RedundancyScheme_RS_SIMD
+
+	var nodes []*pb.Node
+	mockShares := make(map[int]share)
+	for i, piece := range pointer.Remote.RemotePieces {
+		ca, err := provider.NewTestCA(ctx)
+		if !assert.NoError(t, err) {
+			return
+		}
+		identity, err := ca.NewIdentity()
+		if !assert.NoError(t, err) {
+			return
+		}
+		n := &pb.Node{Id: identity.ID.String()}
+		if !assert.NoError(t, node.SignNode(n, identity)) {
+			return
+		}
+		nodes = append(nodes, n)
+
+		derivedPieceID, err := pieceID.Derive([]byte(n.GetId()))
+		if !assert.NoError(t, err) {
+			return
+		}
+		data, err := proto.Marshal(&pb.PieceHash_Data{PieceId: derivedPieceID.String(), Hash: []byte("hash"), Size: 10})
+		if !assert.NoError(t, err) {
+			return
+		}
+		signature, err := peertls.SignMessage(identity.Key, data)
+		if !assert.NoError(t, err) {
+			return
+		}
+		piece.NodeId = n.GetId()
+		piece.Hash = []byte("hash")
+		piece.Receipt = &pb.PieceHash{Data: data, Signature: signature}
+		mockShares[i] = share{PieceNumber: i, Data: []byte("share")}
+	}
+	root, err := segments.ReceiptsRoot(pointer.Remote.RemotePieces)
+	if !assert.NoError(t, err) {
+		return
+	}
+	pointer.Remote.MerkleRoot = root
+
+	verifier := &Verifier{downloader: &mockDownloader{shares: mockShares, nodes: nodes}}
+	verifiedNodes, err := verifier.verify(ctx, 0, pointer, nil)
+	assert.NoError(t, err)
+	assert.Len(t, verifiedNodes, 3)
+
+	// nodes aren't audited with pieces they didn't commit to
+	tampered := proto.Clone(pointer).(*pb.Pointer)
+	tampered.Remote.RemotePieces[1].Hash = []byte("forged")
+	_, err = verifier.verify(ctx, 0, tampered, nil)
+	assert.Error(t, err)
+
+	tampered = proto.Clone(pointer).(*pb.Pointer)
+	tampered.Remote.RemotePieces[1].Receipt = tampered.Remote.RemotePieces[0].Receipt
+	tampered.Remote.MerkleRoot, err = segments.ReceiptsRoot(tampered.Remote.RemotePieces)
+	assert.NoError(t, err)
+	_, err = verifier.verify(ctx, 0, tampered, nil)
+	assert.Error(t, err)
+}
+
 func TestFailingAudit(t *testing.T) {
 	const (
 		required = 8
@@ -206,6 +329,9 @@ func (m *mockDownloader) DownloadShares(ctx context.Context, pointer *pb.Pointer
 	for _, share := range m.shares {
 		shares = append(shares, share)
 	}
+	if len(m.nodes) > 0 {
+		return shares, m.nodes, nil
+	}
 	for i := 0; i < 30; i++ {
 		node := &pb.Node{
 			Id: strconv.Itoa(i),
@@ -253,3 +379,19 @@ func randData(amount int) []byte {
 	}
 	return buf
 }
+
+func TestLookupNodesFromDossiers(t *testing.T) {
+	ctx := context.Background()
+	cache := overlay.NewOverlayCache(teststore.New(), nil)
+	known := pb.Node{Id: "known", Address: &pb.NodeAddress{Address: "127.0.0.1:7777"}}
+	assert.NoError(t, cache.Put(known.Id, known))
+
+	d := newDefaultDownloader(nil, nil, overlay.NewDossierService(cache, nil), provider.FullIdentity{})
+	nodes, err := d.lookupNodes(ctx, []dht.NodeID{node.IDFromString("known"), node.IDFromString("unknown")})
+	if assert.NoError(t, err) && assert.Len(t, nodes, 2) {
+		assert.Equal(t, known.Address.Address, nodes[0].GetAddress().GetAddress())
+		// the unknown node fails its audit as if it were offline
+		assert.Equal(t, "unknown", nodes[1].GetId())
+		assert.Nil(t, nodes[1].GetAddress())
+	}
+}
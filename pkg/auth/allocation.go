@@ -0,0 +1,69 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package auth
+
+import (
+	"crypto/x509"
+
+	"github.com/golang/protobuf/proto"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/peertls"
+	"storj.io/storj/pkg/provider"
+)
+
+// NewPayerAllocation returns the payer bandwidth allocation of data signed
+// by the satellite identity, with the identity's certificate chain, so that
+// storage nodes can verify which satellite pays for the bandwidth.
+func NewPayerAllocation(data *pb.PayerBandwidthAllocation_Data, identity *provider.FullIdentity) (*pb.PayerBandwidthAllocation, error) {
+	data.SatelliteId = identity.ID.Bytes()
+	serialized, err := proto.Marshal(data)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	signature, err := GenerateSignature(serialized, identity)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	chain := [][]byte{identity.Leaf.Raw, identity.CA.Raw}
+	return &pb.PayerBandwidthAllocation{
+		Signature:     signature,
+		Data:          serialized,
+		IdentityChain: append(chain, identity.RestChainRaw()...),
+	}, nil
+}
+
+// VerifyPayerAllocation checks that pba is signed by the satellite its data
+// names, with the identity its id is derived from, and returns the data.
+func VerifyPayerAllocation(pba *pb.PayerBandwidthAllocation) (*pb.PayerBandwidthAllocation_Data, error) {
+	data := &pb.PayerBandwidthAllocation_Data{}
+	if err := proto.Unmarshal(pba.GetData(), data); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if len(pba.GetSignature()) == 0 {
+		return nil, Error.New("payer allocation is not signed")
+	}
+	if len(pba.GetIdentityChain()) < 2 {
+		return nil, Error.New("payer allocation has an incomplete certificate chain")
+	}
+
+	chain, err := provider.ParseCertChain(pba.GetIdentityChain())
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if err := peertls.VerifyPeerCertChains(nil, [][]*x509.Certificate{chain}); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	identity, err := provider.PeerIdentityFromCerts(chain[0], chain[1], chain[2:])
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if identity.ID.String() != string(data.GetSatelliteId()) {
+		return nil, Error.New("satellite id %s doesn't match its identity %s", data.GetSatelliteId(), identity.ID)
+	}
+	if err := peertls.VerifyMessage(identity.Leaf.PublicKey, pba.GetData(), pba.GetSignature()); err != nil {
+		return nil, Error.New("invalid payer allocation signature of satellite %s: %v", identity.ID, err)
+	}
+	return data, nil
+}
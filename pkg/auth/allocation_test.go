@@ -0,0 +1,48 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/provider"
+)
+
+func TestPayerAllocation(t *testing.T) {
+	ctx := context.Background()
+	ca, err := provider.NewTestCA(ctx)
+	assert.NoError(t, err)
+	identity, err := ca.NewIdentity()
+	assert.NoError(t, err)
+
+	pba, err := NewPayerAllocation(&pb.PayerBandwidthAllocation_Data{
+		Action: pb.PayerBandwidthAllocation_GET,
+	}, identity)
+	assert.NoError(t, err)
+
+	data, err := VerifyPayerAllocation(pba)
+	assert.NoError(t, err)
+	assert.Equal(t, identity.ID.Bytes(), data.GetSatelliteId())
+	assert.Equal(t, pb.PayerBandwidthAllocation_GET, data.GetAction())
+
+	tampered := *pba
+	tampered.Signature = append([]byte{}, pba.Signature...)
+	tampered.Signature[0]++
+	_, err = VerifyPayerAllocation(&tampered)
+	assert.Error(t, err)
+
+	unsigned := *pba
+	unsigned.Signature = nil
+	_, err = VerifyPayerAllocation(&unsigned)
+	assert.Error(t, err)
+
+	chainless := *pba
+	chainless.IdentityChain = nil
+	_, err = VerifyPayerAllocation(&chainless)
+	assert.Error(t, err)
+}
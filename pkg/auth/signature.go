@@ -4,9 +4,9 @@
 package auth
 
 import (
-	"crypto/ecdsa"
-
-	"github.com/gtank/cryptopasta"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
 
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/peertls"
@@ -19,25 +19,12 @@ func GenerateSignature(data []byte, identity *provider.FullIdentity) ([]byte, er
 		return nil, nil
 	}
 
-	k, ok := identity.Key.(*ecdsa.PrivateKey)
-	if !ok {
-		return nil, peertls.ErrUnsupportedKey.New("%T", identity.Key)
-	}
-	signature, err := cryptopasta.Sign(data, k)
-	if err != nil {
-		return nil, err
-	}
-	return signature, nil
+	return peertls.SignMessage(identity.Key, data)
 }
 
 // NewSignedMessage creates instance of signed message
 func NewSignedMessage(signature []byte, identity *provider.PeerIdentity) (*pb.SignedMessage, error) {
-	k, ok := identity.Leaf.PublicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, peertls.ErrUnsupportedKey.New("%T", identity.Leaf.PublicKey)
-	}
-
-	encodedKey, err := cryptopasta.EncodePublicKey(k)
+	encodedKey, err := encodePublicKey(identity.Leaf.PublicKey)
 	if err != nil {
 		return nil, err
 	}
@@ -67,13 +54,31 @@ func NewSignedMessageVerifier() SignedMessageVerifier {
 			return Error.New("missing public key for verification")
 		}
 
-		k, err := cryptopasta.DecodePublicKey(signedMessage.GetPublicKey())
+		k, err := decodePublicKey(signedMessage.GetPublicKey())
 		if err != nil {
 			return Error.Wrap(err)
 		}
-		if ok := cryptopasta.Verify(signedMessage.GetData(), signedMessage.GetSignature(), k); !ok {
+		if err := peertls.VerifyMessage(k, signedMessage.GetData(), signedMessage.GetSignature()); err != nil {
 			return Error.New("failed to verify message")
 		}
 		return nil
 	}
 }
+
+// encodePublicKey encodes a public key of any supported type as PEM
+func encodePublicKey(key crypto.PublicKey) ([]byte, error) {
+	kb, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, peertls.ErrUnsupportedKey.Wrap(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: kb}), nil
+}
+
+// decodePublicKey decodes a public key encoded with encodePublicKey
+func decodePublicKey(encodedKey []byte) (crypto.PublicKey, error) {
+	b, _ := pem.Decode(encodedKey)
+	if b == nil {
+		return nil, Error.New("no public key found")
+	}
+	return x509.ParsePKIXPublicKey(b.Bytes)
+}
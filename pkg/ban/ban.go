@@ -0,0 +1,184 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ban
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/pb"
+)
+
+// Config is the configuration of the ban list of a node
+type Config struct {
+	Strikes int           `help:"number of protocol errors after which a peer is banned" default:"3"`
+	TTL     time.Duration `help:"how long misbehaving peers stay banned" default:"1h"`
+	Hosts   bool          `help:"also ban the hosts misbehaving peers connect from, which bans every peer behind the same address" default:"false"`
+}
+
+// NewList returns the ban list configured by c
+func (c Config) NewList() *List {
+	return NewList(c.Strikes, c.TTL, c.Hosts)
+}
+
+// List keeps track of misbehaving peers by node id, and by host if enabled.
+// Peers are banned for ttl once they have made strikes protocol errors;
+// strikes older than ttl are forgotten. A nil List bans nobody.
+type List struct {
+	strikes int
+	ttl     time.Duration
+	hosts   bool
+	now     func() time.Time
+
+	mu       sync.Mutex
+	ids      map[string]*record
+	hostBans map[string]*record
+	// swept is when the expired records were deleted the last time
+	swept time.Time
+}
+
+type record struct {
+	strikes int
+	since   time.Time // first strike still counted
+	until   time.Time // end of the ban, zero when not banned
+}
+
+// NewList returns a ban list that bans peers for ttl after strikes protocol
+// errors. The hosts of the peers are only banned if hosts is set, since all
+// the peers behind a NAT share theirs.
+func NewList(strikes int, ttl time.Duration, hosts bool) *List {
+	return &List{
+		strikes:  strikes,
+		ttl:      ttl,
+		hosts:    hosts,
+		now:      time.Now,
+		ids:      make(map[string]*record),
+		hostBans: make(map[string]*record),
+		swept:    time.Now(),
+	}
+}
+
+// Strike records a protocol error made by the peer with the given node id
+// and host, banning it once it has made too many. Empty values are ignored.
+// It returns whether the peer is banned.
+func (l *List) Strike(id, host string) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.swept) > l.ttl {
+		l.sweep(now)
+	}
+
+	banned := false
+	if id != "" {
+		banned = l.strike(l.ids, id, now) || banned
+	}
+	if host != "" && l.hosts {
+		banned = l.strike(l.hostBans, host, now) || banned
+	}
+	if banned {
+		zap.L().Warn("banning peer", zap.String("NodeID", id), zap.String("Host", host), zap.Duration("TTL", l.ttl))
+	}
+	return banned
+}
+
+func (l *List) strike(records map[string]*record, key string, now time.Time) bool {
+	r, ok := records[key]
+	if !ok || now.Sub(r.since) > l.ttl {
+		r = &record{since: now}
+		records[key] = r
+	}
+	r.strikes++
+	if r.strikes >= l.strikes {
+		r.until = now.Add(l.ttl)
+		return true
+	}
+	return false
+}
+
+// sweep deletes the records of the peers which aren't banned and whose
+// strikes are all older than the ttl, so peers striking with ever new node
+// ids don't grow the list forever
+func (l *List) sweep(now time.Time) {
+	for _, records := range []map[string]*record{l.ids, l.hostBans} {
+		for key, r := range records {
+			if now.Sub(r.since) > l.ttl && now.After(r.until) {
+				delete(records, key)
+			}
+		}
+	}
+	l.swept = now
+}
+
+// Ban bans the peer with the given node id and host right away
+func (l *List) Ban(id, host string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	until := now.Add(l.ttl)
+	if id != "" {
+		l.ids[id] = &record{strikes: l.strikes, since: now, until: until}
+	}
+	if host != "" && l.hosts {
+		l.hostBans[host] = &record{strikes: l.strikes, since: now, until: until}
+	}
+}
+
+// Banned returns whether the node id or the host is banned
+func (l *List) Banned(id, host string) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	return l.banned(l.ids, id, now) || (l.hosts && l.banned(l.hostBans, host, now))
+}
+
+func (l *List) banned(records map[string]*record, key string, now time.Time) bool {
+	r, ok := records[key]
+	if !ok {
+		return false
+	}
+	if r.until.IsZero() {
+		return false
+	}
+	if now.After(r.until) {
+		delete(records, key)
+		return false
+	}
+	return true
+}
+
+// BannedNode returns whether the node is banned by its id or by the host of
+// its address
+func (l *List) BannedNode(n *pb.Node) bool {
+	return l.Banned(n.GetId(), Host(n.GetAddress().GetAddress()))
+}
+
+// StrikeNode records a protocol error made by the node
+func (l *List) StrikeNode(n *pb.Node) bool {
+	return l.Strike(n.GetId(), Host(n.GetAddress().GetAddress()))
+}
+
+// Host returns the host part of address, or address itself if it has no port
+func Host(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
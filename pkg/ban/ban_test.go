@@ -0,0 +1,105 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ban
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/pb"
+)
+
+func TestStrike(t *testing.T) {
+	now := time.Now()
+	l := NewList(2, time.Hour, true)
+	l.now = func() time.Time { return now }
+
+	assert.False(t, l.Strike("id1", "10.0.0.1"))
+	assert.False(t, l.Banned("id1", "10.0.0.1"))
+
+	assert.True(t, l.Strike("id1", ""))
+	assert.True(t, l.Banned("id1", ""))
+	assert.False(t, l.Banned("", "10.0.0.1"))
+
+	assert.True(t, l.Strike("id2", "10.0.0.1"))
+	assert.True(t, l.Banned("id3", "10.0.0.1"))
+
+	// bans expire after the ttl
+	now = now.Add(2 * time.Hour)
+	assert.False(t, l.Banned("id1", "10.0.0.1"))
+
+	// strikes older than the ttl are forgotten
+	assert.False(t, l.Strike("id4", ""))
+	now = now.Add(2 * time.Hour)
+	assert.False(t, l.Strike("id4", ""))
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Now()
+	l := NewList(3, time.Hour, true)
+	l.now = func() time.Time { return now }
+	l.swept = now
+
+	assert.False(t, l.Strike("id1", "10.0.0.1"))
+	l.Ban("id2", "10.0.0.2")
+	now = now.Add(30 * time.Minute)
+	assert.False(t, l.Strike("id3", "10.0.0.3"))
+
+	// the records whose strikes and bans are older than the ttl are deleted
+	now = now.Add(45 * time.Minute)
+	assert.False(t, l.Strike("id4", ""))
+	assert.Len(t, l.ids, 2)
+	assert.Contains(t, l.ids, "id3")
+	assert.Contains(t, l.ids, "id4")
+	assert.Len(t, l.hostBans, 1)
+	assert.Contains(t, l.hostBans, "10.0.0.3")
+}
+
+func TestBanNode(t *testing.T) {
+	l := NewList(3, time.Hour, true)
+	n := &pb.Node{Id: "id1", Address: &pb.NodeAddress{Address: "10.0.0.1:7777"}}
+	assert.False(t, l.BannedNode(n))
+
+	l.Ban("", "10.0.0.1")
+	assert.True(t, l.BannedNode(n))
+	assert.False(t, l.BannedNode(&pb.Node{Id: "id1"}))
+}
+
+func TestBanIDsOnly(t *testing.T) {
+	l := NewList(1, time.Hour, false)
+	n := &pb.Node{Id: "id1", Address: &pb.NodeAddress{Address: "10.0.0.1:7777"}}
+
+	// the other peers behind the host of a banned peer aren't banned
+	assert.True(t, l.StrikeNode(n))
+	assert.True(t, l.BannedNode(n))
+	assert.False(t, l.Banned("id2", "10.0.0.1"))
+
+	l.Ban("", "10.0.0.2")
+	assert.False(t, l.Banned("id3", "10.0.0.2"))
+}
+
+func TestNilList(t *testing.T) {
+	var l *List
+	n := &pb.Node{Id: "id1", Address: &pb.NodeAddress{Address: "10.0.0.1:7777"}}
+	assert.False(t, l.StrikeNode(n))
+	l.Ban("id1", "10.0.0.1")
+	assert.False(t, l.BannedNode(n))
+}
+
+func TestHost(t *testing.T) {
+	for _, tt := range []struct {
+		address string
+		host    string
+	}{
+		{"10.0.0.1:7777", "10.0.0.1"},
+		{"[::1]:7777", "::1"},
+		{"bootstrap.storj.io:8080", "bootstrap.storj.io"},
+		{"10.0.0.1", "10.0.0.1"},
+		{"", ""},
+	} {
+		assert.Equal(t, tt.host, Host(tt.address), tt.address)
+	}
+}
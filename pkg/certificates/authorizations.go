@@ -0,0 +1,274 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package certificates
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/gob"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/boltdb"
+	"storj.io/storj/storage/redis"
+)
+
+const (
+	// AuthorizationsBucket is the bucket used with a bolt-backed authorizations DB
+	AuthorizationsBucket = "authorizations"
+	// tokenDataLength is the number of random bytes in a token
+	tokenDataLength = 64
+	// tokenDelimiter separates the user id from the token data
+	tokenDelimiter = ":"
+)
+
+var (
+	// ErrAuthorization is used when an authorization can't be claimed
+	ErrAuthorization = errs.Class("authorization error")
+	// ErrAuthorizationDB is used when an error occurs involving the authorization database
+	ErrAuthorizationDB = errs.Class("authorization db error")
+	// ErrInvalidToken is used when a token is malformed
+	ErrInvalidToken = errs.Class("invalid authorization token")
+)
+
+// Token is a single use authorization for a node to have its certificate
+// authority signed. It is handed out as "<user id>:<base58 data>".
+type Token struct {
+	UserID string
+	Data   [tokenDataLength]byte
+}
+
+// Claim records which node used an authorization and when
+type Claim struct {
+	Timestamp int64
+	Addr      string
+	NodeID    string
+}
+
+// Authorization is a token along with its claim, which is nil until the
+// token is used
+type Authorization struct {
+	Token Token
+	Claim *Claim
+}
+
+// Authorizations is a list of authorizations of a single user
+type Authorizations []*Authorization
+
+// AuthorizationDB stores the authorizations of users, keyed by user id
+type AuthorizationDB struct {
+	mu sync.Mutex // serializes claims
+	DB storage.KeyValueStore
+}
+
+// NewAuthorizationDB opens the authorization database described by dbURL
+func NewAuthorizationDB(dbURL string) (*AuthorizationDB, error) {
+	u, err := utils.ParseURL(dbURL)
+	if err != nil {
+		return nil, ErrAuthorizationDB.Wrap(err)
+	}
+
+	var db storage.KeyValueStore
+	switch u.Scheme {
+	case "bolt":
+		db, err = boltdb.New(u.Path, AuthorizationsBucket)
+	case "redis":
+		db, err = redis.NewClientFrom(dbURL)
+	default:
+		return nil, ErrAuthorizationDB.New("unsupported db scheme: %s", u.Scheme)
+	}
+	if err != nil {
+		return nil, ErrAuthorizationDB.Wrap(err)
+	}
+	return &AuthorizationDB{DB: db}, nil
+}
+
+// NewAuthorization returns a new unclaimed authorization for the user
+func NewAuthorization(userID string) (*Authorization, error) {
+	if userID == "" || strings.Contains(userID, tokenDelimiter) {
+		return nil, ErrInvalidToken.New("invalid user id %q", userID)
+	}
+
+	token := Token{UserID: userID}
+	if _, err := rand.Read(token.Data[:]); err != nil {
+		return nil, ErrAuthorization.Wrap(err)
+	}
+	return &Authorization{Token: token}, nil
+}
+
+// ParseToken parses a token in the form returned by Token.String
+func ParseToken(s string) (*Token, error) {
+	i := strings.LastIndex(s, tokenDelimiter)
+	if i <= 0 {
+		return nil, ErrInvalidToken.New("missing user id")
+	}
+
+	data, err := base58.Decode(s[i+1:])
+	if err != nil {
+		return nil, ErrInvalidToken.Wrap(err)
+	}
+	if len(data) != tokenDataLength {
+		return nil, ErrInvalidToken.New("token data has %d bytes, expected %d", len(data), tokenDataLength)
+	}
+
+	token := &Token{UserID: s[:i]}
+	copy(token.Data[:], data)
+	return token, nil
+}
+
+// String returns the token in the form it is handed out
+func (t Token) String() string {
+	return t.UserID + tokenDelimiter + base58.Encode(t.Data[:])
+}
+
+// Equal returns whether t and other are the same token, comparing the data
+// in constant time
+func (t Token) Equal(other Token) bool {
+	return t.UserID == other.UserID &&
+		subtle.ConstantTimeCompare(t.Data[:], other.Data[:]) == 1
+}
+
+// Marshal serializes the authorizations
+func (a Authorizations) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(a); err != nil {
+		return nil, ErrAuthorizationDB.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal deserializes data into a
+func (a *Authorizations) Unmarshal(data []byte) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(a); err != nil {
+		return ErrAuthorizationDB.Wrap(err)
+	}
+	return nil
+}
+
+// Group splits the authorizations into claimed and open ones
+func (a Authorizations) Group() (claimed, open Authorizations) {
+	for _, auth := range a {
+		if auth.Claim != nil {
+			claimed = append(claimed, auth)
+		} else {
+			open = append(open, auth)
+		}
+	}
+	return claimed, open
+}
+
+// Create issues count new authorizations for the user, in addition to the
+// ones the user already has
+func (db *AuthorizationDB) Create(userID string, count int) (Authorizations, error) {
+	if count < 1 {
+		return nil, ErrAuthorization.New("count must be positive, got %d", count)
+	}
+
+	var created Authorizations
+	for i := 0; i < count; i++ {
+		auth, err := NewAuthorization(userID)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, auth)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	existing, err := db.get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.put(userID, append(existing, created...)); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Get returns the authorizations of the user
+func (db *AuthorizationDB) Get(userID string) (Authorizations, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.get(userID)
+}
+
+// UserIDs returns the ids of all users with authorizations
+func (db *AuthorizationDB) UserIDs() (ids []string, err error) {
+	err = db.DB.Iterate(storage.IterateOptions{Recurse: true}, func(it storage.Iterator) error {
+		var item storage.ListItem
+		for it.Next(&item) {
+			ids = append(ids, string(item.Key))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, ErrAuthorizationDB.Wrap(err)
+	}
+	return ids, nil
+}
+
+// Claim marks the authorization of token as used by the node with the given
+// id and address. Each authorization can only be claimed once.
+func (db *AuthorizationDB) Claim(token Token, nodeID, addr string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	auths, err := db.get(token.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, auth := range auths {
+		if !auth.Token.Equal(token) {
+			continue
+		}
+		if auth.Claim != nil {
+			return ErrAuthorization.New("token already claimed by node %s", auth.Claim.NodeID)
+		}
+		auth.Claim = &Claim{
+			Timestamp: time.Now().Unix(),
+			Addr:      addr,
+			NodeID:    nodeID,
+		}
+		return db.put(token.UserID, auths)
+	}
+	return ErrAuthorization.New("unknown token for user %s", token.UserID)
+}
+
+// Close closes the underlying store
+func (db *AuthorizationDB) Close() error {
+	return ErrAuthorizationDB.Wrap(db.DB.Close())
+}
+
+func (db *AuthorizationDB) get(userID string) (Authorizations, error) {
+	data, err := db.DB.Get(storage.Key(userID))
+	if storage.ErrKeyNotFound.Has(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, ErrAuthorizationDB.Wrap(err)
+	}
+
+	var auths Authorizations
+	if err := auths.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return auths, nil
+}
+
+func (db *AuthorizationDB) put(userID string, auths Authorizations) error {
+	data, err := auths.Marshal()
+	if err != nil {
+		return err
+	}
+	return ErrAuthorizationDB.Wrap(db.DB.Put(storage.Key(userID), data))
+}
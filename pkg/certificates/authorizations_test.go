@@ -0,0 +1,72 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package certificates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/storage/teststore"
+)
+
+func TestParseToken(t *testing.T) {
+	auth, err := NewAuthorization("user@example.com")
+	assert.NoError(t, err)
+
+	token, err := ParseToken(auth.Token.String())
+	assert.NoError(t, err)
+	assert.True(t, auth.Token.Equal(*token))
+
+	for _, invalid := range []string{
+		"",
+		"user@example.com",
+		":" + auth.Token.String()[len("user@example.com:"):],
+		"user@example.com:notbase58!",
+		"user@example.com:abc",
+	} {
+		_, err := ParseToken(invalid)
+		assert.True(t, ErrInvalidToken.Has(err), invalid)
+	}
+}
+
+func TestAuthorizationDB(t *testing.T) {
+	db := &AuthorizationDB{DB: teststore.New()}
+	defer func() { assert.NoError(t, db.Close()) }()
+
+	created, err := db.Create("user", 2)
+	assert.NoError(t, err)
+	assert.Len(t, created, 2)
+	_, err = db.Create("user", 1)
+	assert.NoError(t, err)
+
+	auths, err := db.Get("user")
+	assert.NoError(t, err)
+	assert.Len(t, auths, 3)
+
+	ids, err := db.UserIDs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user"}, ids)
+
+	// tokens can be claimed once
+	assert.NoError(t, db.Claim(created[0].Token, "node", "127.0.0.1:7777"))
+	assert.True(t, ErrAuthorization.Has(db.Claim(created[0].Token, "other", "127.0.0.1:7778")))
+
+	auths, err = db.Get("user")
+	assert.NoError(t, err)
+	claimed, open := auths.Group()
+	assert.Len(t, open, 2)
+	if assert.Len(t, claimed, 1) {
+		assert.Equal(t, "node", claimed[0].Claim.NodeID)
+		assert.Equal(t, "127.0.0.1:7777", claimed[0].Claim.Addr)
+	}
+
+	// unknown tokens can't be claimed
+	unknown, err := NewAuthorization("user")
+	assert.NoError(t, err)
+	assert.True(t, ErrAuthorization.Has(db.Claim(unknown.Token, "node", "")))
+	other, err := NewAuthorization("nobody")
+	assert.NoError(t, err)
+	assert.True(t, ErrAuthorization.Has(db.Claim(other.Token, "node", "")))
+}
@@ -0,0 +1,155 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package certificates
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	monkit "gopkg.in/spacemonkeygo/monkit.v2"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/peertls"
+	"storj.io/storj/pkg/provider"
+)
+
+var (
+	mon = monkit.Package()
+	// Error is the default error class for the certificates package
+	Error = errs.Class("certificates error")
+)
+
+// CertificateSigner signs the certificate authorities of nodes presenting a
+// valid authorization token
+type CertificateSigner struct {
+	logger        *zap.Logger
+	signer        *provider.FullCertificateAuthority
+	authDB        *AuthorizationDB
+	minDifficulty uint16
+}
+
+// NewServer returns a CertificateSigner signing with signer and claiming
+// tokens from authDB
+func NewServer(logger *zap.Logger, signer *provider.FullCertificateAuthority, authDB *AuthorizationDB, minDifficulty uint16) *CertificateSigner {
+	return &CertificateSigner{
+		logger:        logger,
+		signer:        signer,
+		authDB:        authDB,
+		minDifficulty: minDifficulty,
+	}
+}
+
+// Sign claims the authorization token of the request and signs the
+// certificate authority the requesting node used for the TLS handshake
+func (c *CertificateSigner) Sign(ctx context.Context, req *pb.SigningRequest) (_ *pb.SigningResponse, err error) {
+	defer mon.Task()(&ctx)(&err)
+	if c.logger == nil {
+		c.logger = zap.L()
+	}
+
+	identity, err := provider.PeerIdentityFromContext(ctx)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if d := identity.ID.Difficulty(); d < c.minDifficulty {
+		return nil, provider.ErrDifficulty.New("node id %s has difficulty %d, at least %d is required", identity.ID, d, c.minDifficulty)
+	}
+
+	token, err := ParseToken(req.GetAuthToken())
+	if err != nil {
+		return nil, err
+	}
+
+	// the certificate authority is signed before the token is claimed, so
+	// that a failed signature doesn't use it up
+	signed, err := SignCA(identity.CA, c.signer)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if err := c.authDB.Claim(*token, identity.ID.String(), peerAddr(ctx)); err != nil {
+		return nil, err
+	}
+
+	c.logger.Info("signed certificate authority",
+		zap.String("NodeID", identity.ID.String()),
+		zap.String("UserID", token.UserID))
+
+	chain := [][]byte{signed.Raw, c.signer.Cert.Raw}
+	for _, cert := range c.signer.RestChain {
+		chain = append(chain, cert.Raw)
+	}
+	return &pb.SigningResponse{Chain: chain}, nil
+}
+
+// SignCA returns a copy of the certificate authority cert signed by signer.
+// The public key, and therefore the node id, stays the same; the copy also
+// gets the signer's signature of it in a signed node id extension.
+func SignCA(cert *x509.Certificate, signer *provider.FullCertificateAuthority) (*x509.Certificate, error) {
+	ext, err := peertls.NewSignedNodeIDExt(signer.Key, cert)
+	if err != nil {
+		return nil, err
+	}
+	template := *cert
+	template.ExtraExtensions = append(append([]pkix.Extension{}, cert.ExtraExtensions...), ext)
+	return peertls.NewCert(&template, signer.Cert, cert.PublicKey, signer.Key)
+}
+
+// Client requests certificate authority signatures from a CertificateSigning
+// server
+type Client struct {
+	conn   *grpc.ClientConn
+	client pb.CertificateSigningClient
+}
+
+// NewClient dials the CertificateSigning server at address with identity,
+// whose certificate authority is the one that gets signed
+func NewClient(ctx context.Context, identity *provider.FullIdentity, address string) (*Client, error) {
+	dialOpt, err := identity.DialOption()
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	conn, err := grpc.DialContext(ctx, address, dialOpt)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return &Client{conn: conn, client: pb.NewCertificateSigningClient(conn)}, nil
+}
+
+// Sign exchanges the authorization token for a signed certificate authority
+// chain, starting with the signed certificate authority
+func (c *Client) Sign(ctx context.Context, token string) (_ []*x509.Certificate, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	resp, err := c.client.Sign(ctx, &pb.SigningRequest{AuthToken: token})
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	chain, err := provider.ParseCertChain(resp.GetChain())
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if len(chain) < 2 {
+		return nil, Error.New("signed chain is incomplete")
+	}
+	return chain, nil
+}
+
+// Close closes the connection to the server
+func (c *Client) Close() error {
+	return Error.Wrap(c.conn.Close())
+}
+
+// peerAddr returns the address the request was received from
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
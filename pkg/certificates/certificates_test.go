@@ -0,0 +1,63 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package certificates
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/peertls"
+	"storj.io/storj/pkg/provider"
+	"storj.io/storj/storage/teststore"
+)
+
+func TestSign(t *testing.T) {
+	ctx := context.Background()
+	signer, err := provider.NewTestCA(ctx)
+	assert.NoError(t, err)
+	ca, err := provider.NewTestCA(ctx)
+	assert.NoError(t, err)
+	identity, err := ca.NewIdentity()
+	assert.NoError(t, err)
+
+	authDB := &AuthorizationDB{DB: teststore.New()}
+	auths, err := authDB.Create("user", 1)
+	assert.NoError(t, err)
+
+	peerCtx := peer.NewContext(ctx, &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{identity.Leaf, identity.CA},
+		}},
+	})
+	srv := NewServer(nil, signer, authDB, 0)
+
+	_, err = srv.Sign(ctx, &pb.SigningRequest{AuthToken: auths[0].Token.String()})
+	assert.Error(t, err, "requests without a peer identity are rejected")
+
+	resp, err := srv.Sign(peerCtx, &pb.SigningRequest{AuthToken: auths[0].Token.String()})
+	if !assert.NoError(t, err) {
+		return
+	}
+	chain, err := provider.ParseCertChain(resp.GetChain())
+	assert.NoError(t, err)
+	if assert.Len(t, chain, 2) {
+		assert.Equal(t, identity.CA.RawSubjectPublicKeyInfo, chain[0].RawSubjectPublicKeyInfo)
+		assert.Equal(t, signer.Cert.Raw, chain[1].Raw)
+		assert.NoError(t, peertls.VerifyPeerCertChains(nil, [][]*x509.Certificate{append([]*x509.Certificate{identity.Leaf}, chain...)}))
+	}
+
+	_, err = srv.Sign(peerCtx, &pb.SigningRequest{AuthToken: auths[0].Token.String()})
+	assert.True(t, ErrAuthorization.Has(err), "tokens can't be reused")
+
+	srv = NewServer(nil, signer, authDB, identity.ID.Difficulty()+1)
+	_, err = srv.Sign(peerCtx, &pb.SigningRequest{AuthToken: auths[0].Token.String()})
+	assert.True(t, provider.ErrDifficulty.Has(err))
+}
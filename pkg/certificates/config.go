@@ -0,0 +1,53 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package certificates
+
+import (
+	"context"
+	"math"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/provider"
+)
+
+// CertSigningConfig is a configuration struct that is everything you need
+// to start a certificate signing responsibility
+type CertSigningConfig struct {
+	AuthorizationDBURL string `help:"url to the certificate signing authorization database" default:"bolt://$CONFDIR/authorizations.db"`
+	MinDifficulty      uint   `help:"minimum difficulty of the requester's node id required to claim an authorization" default:"12"`
+	CA                 provider.FullCAConfig
+}
+
+// NewAuthDB opens the authorization database of the config
+func (c CertSigningConfig) NewAuthDB() (*AuthorizationDB, error) {
+	return NewAuthorizationDB(c.AuthorizationDBURL)
+}
+
+// Run implements the provider.Responsibility interface
+func (c CertSigningConfig) Run(ctx context.Context, server *provider.Provider) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if c.MinDifficulty > math.MaxUint16 {
+		return errs.New("minimum difficulty %d is higher than the maximum of %d", c.MinDifficulty, math.MaxUint16)
+	}
+
+	signer, err := c.CA.Load()
+	if err != nil {
+		return err
+	}
+
+	authDB, err := c.NewAuthDB()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = authDB.Close() }()
+
+	srv := NewServer(zap.L(), signer, authDB, uint16(c.MinDifficulty))
+	pb.RegisterCertificateSigningServer(server.GRPC(), srv)
+
+	return server.Run(ctx)
+}
@@ -8,11 +8,13 @@ import (
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"go.uber.org/zap"
 
 	"storj.io/storj/pkg/datarepair/queue"
 	"storj.io/storj/pkg/dht"
 	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/overlay"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/pointerdb"
 	"storj.io/storj/storage"
@@ -21,28 +23,61 @@ import (
 // Checker is the interface for the data repair queue
 type Checker interface {
 	IdentifyInjuredSegments(ctx context.Context) (err error)
+	RetryIrreparable(ctx context.Context) (err error)
 	Run(ctx context.Context) error
 }
 
+// the checkpoint of a scan is kept in the checkpoints store, so the scan
+// continues where it stopped when the checker restarts
+var (
+	// positionKey is the path of the last pointer checked by the scan, which
+	// is empty between scans
+	positionKey = storage.Key("position")
+	// scanStartedKey is when the current or last scan started
+	scanStartedKey = storage.Key("scan-started")
+)
+
 // Checker contains the information needed to do checks for missing pieces
 type checker struct {
-	pointerdb   *pointerdb.Server
-	repairQueue *queue.Queue
-	overlay     pb.OverlayServer
-	limit       int
-	logger      *zap.Logger
-	ticker      *time.Ticker
+	pointerdb        *pointerdb.Server
+	repairQueue      *queue.Queue
+	overlay          pb.OverlayServer
+	checkpoints      storage.KeyValueStore
+	irreparable      *IrreparableDB
+	limit            int
+	logger           *zap.Logger
+	ticker           *time.Ticker
+	fullScanInterval time.Duration
+	// dossiers are used for the reputation of the nodes if they're set, so
+	// only the pieces on nodes with at least minReputation are good
+	dossiers      *overlay.DossierService
+	minReputation *pb.NodeRep
+	// maxOffline is how long the nodes may have been failing to be contacted
+	// before their pieces count as lost, which isn't limited if it's 0
+	maxOffline time.Duration
+	// dryRun checkers estimate what repairing the injured segments would
+	// take, instead of queueing them for repair
+	dryRun   bool
+	estimate repairEstimate
+	// retryTicker is when the irreparable segments are checked again, if
+	// it's set, and escalateAfter is how many times they're retried before
+	// they're reported to the operator, which they aren't if it's 0
+	retryTicker   *time.Ticker
+	escalateAfter int
 }
 
 // NewChecker creates a new instance of checker
-func newChecker(pointerdb *pointerdb.Server, repairQueue *queue.Queue, overlay pb.OverlayServer, limit int, logger *zap.Logger, interval time.Duration) *checker {
+func newChecker(pointerdb *pointerdb.Server, repairQueue *queue.Queue, overlay pb.OverlayServer, checkpoints, irreparable storage.KeyValueStore, limit int, logger *zap.Logger, interval, fullScanInterval time.Duration) *checker {
 	return &checker{
-		pointerdb:   pointerdb,
-		repairQueue: repairQueue,
-		overlay:     overlay,
-		limit:       limit,
-		logger:      logger,
-		ticker:      time.NewTicker(interval),
+		pointerdb:        pointerdb,
+		repairQueue:      repairQueue,
+		overlay:          overlay,
+		checkpoints:      checkpoints,
+		irreparable:      NewIrreparableDB(irreparable),
+		limit:            limit,
+		logger:           logger,
+		ticker:           time.NewTicker(interval),
+		fullScanInterval: fullScanInterval,
 	}
 }
 
@@ -50,77 +85,225 @@ func newChecker(pointerdb *pointerdb.Server, repairQueue *queue.Queue, overlay p
 func (c *checker) Run(ctx context.Context) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	for {
-		err = c.IdentifyInjuredSegments(ctx)
-		if err != nil {
-			zap.L().Error("Checker failed", zap.Error(err))
-		}
+	var retry <-chan time.Time
+	if c.retryTicker != nil {
+		retry = c.retryTicker.C
+	}
 
+	c.identifyInjuredSegments(ctx)
+	for {
 		select {
 		case <-c.ticker.C: // wait for the next interval to happen
+			c.identifyInjuredSegments(ctx)
+		case <-retry: // or for the irreparable segments to be retried
+			err = c.RetryIrreparable(ctx)
+			if err != nil {
+				zap.L().Error("Retrying irreparable segments failed", zap.Error(err))
+			}
 		case <-ctx.Done(): // or the checker is canceled via context
 			return ctx.Err()
 		}
 	}
 }
 
-// IdentifyInjuredSegments checks for missing pieces off of the pointerdb and overlay cache
+// identifyInjuredSegments identifies injured segments, logging its error
+func (c *checker) identifyInjuredSegments(ctx context.Context) {
+	err := c.IdentifyInjuredSegments(ctx)
+	if err != nil {
+		zap.L().Error("Checker failed", zap.Error(err))
+	}
+}
+
+// IdentifyInjuredSegments checks the next batch of pointers for missing
+// pieces off of the pointerdb and overlay cache. Each batch continues the
+// scan of the pointerdb after the last pointer checked, and a new scan starts
+// once the full scan interval passed since the last one started.
 func (c *checker) IdentifyInjuredSegments(ctx context.Context) (err error) {
 	defer mon.Task()(&ctx)(&err)
-	c.logger.Debug("entering pointerdb iterate")
 
-	err = c.pointerdb.Iterate(ctx, &pb.IterateRequest{Recurse: true},
+	position, started, err := c.loadCheckpoint()
+	if err != nil {
+		return err
+	}
+	if position == "" {
+		if !started.IsZero() && time.Since(started) < c.fullScanInterval {
+			return nil
+		}
+		started = time.Now()
+		c.logger.Debug("starting a scan of the pointerdb")
+	}
+
+	last, done, err := c.checkBatch(ctx, position)
+	if err != nil {
+		return err
+	}
+	if done {
+		c.logger.Debug("finished the scan of the pointerdb")
+		if c.dryRun {
+			c.reportEstimate()
+		}
+		last = ""
+	}
+	return c.saveCheckpoint(last, started)
+}
+
+// checkBatch checks up to the limit of the checker of the pointers after
+// position, returning the path of the last one checked, and whether there
+// are no more pointers after it
+func (c *checker) checkBatch(ctx context.Context, position string) (last string, done bool, err error) {
+	last = position
+	err = c.pointerdb.Iterate(ctx, &pb.IterateRequest{Recurse: true, First: position},
 		func(it storage.Iterator) error {
 			var item storage.ListItem
 			lim := c.limit
 			if lim <= 0 || lim > storage.LookupLimit {
 				lim = storage.LookupLimit
 			}
-			for ; lim > 0 && it.Next(&item); lim-- {
+			for lim > 0 {
+				if !it.Next(&item) {
+					done = true
+					return nil
+				}
+				// the iteration starts at the last pointer checked before
+				if string(item.Key) == position {
+					continue
+				}
+				lim--
+				last = string(item.Key)
+				mon.Meter("checked_segments").Mark(1)
+
 				pointer := &pb.Pointer{}
 				err = proto.Unmarshal(item.Value, pointer)
 				if err != nil {
 					return Error.New("error unmarshalling pointer %s", err)
 				}
-				pieces := pointer.Remote.RemotePieces
-				var nodeIDs []dht.NodeID
-				for _, p := range pieces {
-					nodeIDs = append(nodeIDs, node.IDFromString(p.NodeId))
-				}
-				missingPieces, err := c.offlineNodes(ctx, nodeIDs)
-				if err != nil {
-					return Error.New("error getting missing offline nodes %s", err)
+				if pointer.Remote == nil {
+					continue
 				}
-				numHealthy := len(nodeIDs) - len(missingPieces)
-				if int32(numHealthy) < pointer.Remote.Redundancy.RepairThreshold {
-					err = c.repairQueue.Enqueue(&pb.InjuredSegment{
-						Path:       string(item.Key),
-						LostPieces: missingPieces,
-					})
-					if err != nil {
-						return Error.New("error adding injured segment to queue %s", err)
-					}
+				if err := c.checkSegment(ctx, string(item.Key), pointer); err != nil {
+					return err
 				}
 			}
 			return nil
 		},
 	)
-	return err
+	return last, done, err
 }
 
-// returns the indices of offline and online nodes
-func (c *checker) offlineNodes(ctx context.Context, nodeIDs []dht.NodeID) (offline []int32, err error) {
-	responses, err := c.overlay.BulkLookup(ctx, nodeIDsToLookupRequests(nodeIDs))
+// checkSegment classifies the health of the remote segment of pointer at
+// path, queueing it for repair with its repair priority if it's injured, and
+// recording it if it's irreparable
+func (c *checker) checkSegment(ctx context.Context, path string, pointer *pb.Pointer) error {
+	remote := pointer.GetRemote()
+	health, err := c.segmentHealth(ctx, remote)
 	if err != nil {
-		return []int32{}, err
+		return err
 	}
-	nodes := lookupResponsesToNodes(responses)
-	for i, n := range nodes {
-		if n == nil {
-			offline = append(offline, int32(i))
+	mon.Event("segment_" + health.Health.String())
+
+	switch health.Health {
+	case NeedsRepair, Critical:
+		return c.queueRepair(path, pointer, health)
+	case Irreparable:
+		return c.recordIrreparable(path, remote.GetRedundancy(), health)
+	}
+	return nil
+}
+
+// segmentHealth returns the health of remote, by the nodes its pieces are on
+func (c *checker) segmentHealth(ctx context.Context, remote *pb.RemoteSegment) (segmentHealth, error) {
+	var nodeIDs []dht.NodeID
+	for _, p := range remote.GetRemotePieces() {
+		nodeIDs = append(nodeIDs, node.IDFromString(p.NodeId))
+	}
+	nodes, err := c.lookupNodes(ctx, nodeIDs)
+	if err != nil {
+		return segmentHealth{}, Error.New("error getting missing offline nodes %s", err)
+	}
+	return c.classify(ctx, remote.GetRedundancy(), nodes), nil
+}
+
+// queueRepair queues the injured segment of pointer at path for repair with
+// its repair priority
+func (c *checker) queueRepair(path string, pointer *pb.Pointer, health segmentHealth) error {
+	priority := repairPriority(pointer.GetRemote().GetRedundancy(), health, creationDate(pointer), time.Now())
+	if c.dryRun {
+		c.simulateRepair(path, pointer, health, priority)
+		return nil
+	}
+	injured := &pb.InjuredSegment{
+		Path:       path,
+		LostPieces: health.Lost,
+	}
+	if err := c.repairQueue.EnqueueWithPriority(injured, priority); err != nil {
+		return Error.New("error adding injured segment to queue %s", err)
+	}
+	return nil
+}
+
+// creationDate returns when pointer was created, or the zero time if that's
+// unknown
+func creationDate(pointer *pb.Pointer) time.Time {
+	created, err := ptypes.Timestamp(pointer.GetCreationDate())
+	if err != nil {
+		return time.Time{}
+	}
+	return created
+}
+
+// loadCheckpoint returns the position of the scan in progress, which is
+// empty if there is none, and when the current or last scan started
+func (c *checker) loadCheckpoint() (position string, started time.Time, err error) {
+	value, err := c.checkpoints.Get(positionKey)
+	if err != nil && !storage.ErrKeyNotFound.Has(err) {
+		return "", time.Time{}, Error.Wrap(err)
+	}
+	position = string(value)
+
+	value, err = c.checkpoints.Get(scanStartedKey)
+	if storage.ErrKeyNotFound.Has(err) {
+		return position, time.Time{}, nil
+	}
+	if err != nil {
+		return "", time.Time{}, Error.Wrap(err)
+	}
+	if err := started.UnmarshalBinary(value); err != nil {
+		return "", time.Time{}, Error.Wrap(err)
+	}
+	return position, started, nil
+}
+
+// saveCheckpoint keeps the position of the scan and when it started. The
+// position is saved first, so a scan isn't skipped if saving the start of
+// it fails.
+func (c *checker) saveCheckpoint(position string, started time.Time) (err error) {
+	if position == "" {
+		err = c.checkpoints.Delete(positionKey)
+		if storage.ErrKeyNotFound.Has(err) {
+			err = nil
 		}
+	} else {
+		err = c.checkpoints.Put(positionKey, storage.Value(position))
+	}
+	if err != nil {
+		return Error.Wrap(err)
+	}
+
+	value, err := started.MarshalBinary()
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	return Error.Wrap(c.checkpoints.Put(scanStartedKey, value))
+}
+
+// lookupNodes returns the nodes with the given ids, where the nodes which
+// are offline are nil
+func (c *checker) lookupNodes(ctx context.Context, nodeIDs []dht.NodeID) ([]*pb.Node, error) {
+	responses, err := c.overlay.BulkLookup(ctx, nodeIDsToLookupRequests(nodeIDs))
+	if err != nil {
+		return nil, err
 	}
-	return offline, nil
+	return lookupResponsesToNodes(responses), nil
 }
 
 func nodeIDsToLookupRequests(nodeIDs []dht.NodeID) *pb.LookupRequests {
@@ -34,7 +34,7 @@ func TestIdentifyInjuredSegments(t *testing.T) {
 	logger := zap.NewNop()
 	pointerdb := pointerdb.NewServer(teststore.New(), &overlay.Cache{}, logger, pointerdb.Config{}, nil)
 
-	repairQueue := queue.NewQueue(teststore.New())
+	repairQueue := queue.NewQueue(teststore.NewQueue())
 
 	const N = 25
 	nodes := []*pb.Node{}
@@ -87,7 +87,7 @@ func TestIdentifyInjuredSegments(t *testing.T) {
 	overlayServer := mocks.NewOverlay(nodes)
 	limit := 0
 	interval := time.Second
-	checker := newChecker(pointerdb, repairQueue, overlayServer, limit, logger, interval)
+	checker := newChecker(pointerdb, repairQueue, overlayServer, teststore.New(), teststore.New(), limit, logger, interval, time.Hour)
 	err := checker.IdentifyInjuredSegments(ctx)
 	assert.NoError(t, err)
 
@@ -102,15 +102,67 @@ func TestIdentifyInjuredSegments(t *testing.T) {
 	sort.Slice(dequeued, func(i, k int) bool { return dequeued[i].Path < dequeued[k].Path })
 
 	for i := 0; i < len(segs); i++ {
+		// the segments are set to be queued by the queue
+		assert.NotNil(t, dequeued[i].Queued)
+		dequeued[i].Queued = nil
 		assert.True(t, proto.Equal(segs[i], dequeued[i]))
 	}
 }
 
+func TestIncrementalScan(t *testing.T) {
+	logger := zap.NewNop()
+	pointerdb := pointerdb.NewServer(teststore.New(), &overlay.Cache{}, logger, pointerdb.Config{}, nil)
+	repairQueue := queue.NewQueue(teststore.NewQueue())
+	checkpoints := teststore.New()
+
+	// none of the pieces are on nodes which are online, so every segment is
+	// injured
+	const N = 10
+	ctx := auth.WithAPIKey(ctx, nil)
+	for i := 0; i < N; i++ {
+		p := &pb.Pointer{
+			Remote: &pb.RemoteSegment{
+				Redundancy:   &pb.RedundancyScheme{RepairThreshold: int32(1)},
+				PieceId:      strconv.Itoa(i),
+				RemotePieces: []*pb.RemotePiece{{PieceNum: 0, NodeId: strconv.Itoa(i)}},
+			},
+		}
+		_, err := pointerdb.Put(ctx, &pb.PutRequest{Path: strconv.Itoa(i), Pointer: p})
+		assert.NoError(t, err)
+	}
+	overlayServer := mocks.NewOverlay(nil)
+
+	scanned := func(checker *checker) (paths []string) {
+		assert.NoError(t, checker.IdentifyInjuredSegments(ctx))
+		for {
+			seg, err := repairQueue.Dequeue()
+			if err != nil {
+				return paths
+			}
+			paths = append(paths, seg.Path)
+		}
+	}
+
+	checker := newChecker(pointerdb, repairQueue, overlayServer, checkpoints, teststore.New(), 4, logger, time.Hour, time.Hour)
+	assert.Equal(t, []string{"0", "1", "2", "3"}, scanned(checker))
+
+	// a restarted checker continues the scan
+	checker = newChecker(pointerdb, repairQueue, overlayServer, checkpoints, teststore.New(), 4, logger, time.Hour, time.Hour)
+	assert.Equal(t, []string{"4", "5", "6", "7"}, scanned(checker))
+	assert.Equal(t, []string{"8", "9"}, scanned(checker))
+
+	// the next scan waits for the full scan interval
+	assert.Empty(t, scanned(checker))
+
+	checker = newChecker(pointerdb, repairQueue, overlayServer, checkpoints, teststore.New(), 4, logger, time.Hour, 0)
+	assert.Equal(t, []string{"0", "1", "2", "3"}, scanned(checker))
+}
+
 func TestOfflineAndOnlineNodes(t *testing.T) {
 	logger := zap.NewNop()
 	pointerdb := pointerdb.NewServer(teststore.New(), &overlay.Cache{}, logger, pointerdb.Config{}, nil)
 
-	repairQueue := queue.NewQueue(teststore.New())
+	repairQueue := queue.NewQueue(teststore.NewQueue())
 	const N = 50
 	nodes := []*pb.Node{}
 	nodeIDs := []dht.NodeID{}
@@ -131,9 +183,15 @@ func TestOfflineAndOnlineNodes(t *testing.T) {
 	overlayServer := mocks.NewOverlay(nodes)
 	limit := 0
 	interval := time.Second
-	checker := newChecker(pointerdb, repairQueue, overlayServer, limit, logger, interval)
-	offline, err := checker.offlineNodes(ctx, nodeIDs)
+	checker := newChecker(pointerdb, repairQueue, overlayServer, teststore.New(), teststore.New(), limit, logger, interval, time.Hour)
+	nodes, err := checker.lookupNodes(ctx, nodeIDs)
 	assert.NoError(t, err)
+	var offline []int32
+	for i, n := range nodes {
+		if n == nil {
+			offline = append(offline, int32(i))
+		}
+	}
 	assert.Equal(t, expectedOffline, offline)
 }
 
@@ -144,9 +202,9 @@ func BenchmarkIdentifyInjuredSegments(b *testing.B) {
 	addr, cleanup, err := redisserver.Start()
 	defer cleanup()
 	assert.NoError(b, err)
-	client, err := redis.NewClient(addr, "", 1)
+	db, err := redis.NewQueue("redis://"+addr, "repair")
 	assert.NoError(b, err)
-	repairQueue := queue.NewQueue(client)
+	repairQueue := queue.NewQueue(db)
 
 	const N = 25
 	nodes := []*pb.Node{}
@@ -201,7 +259,7 @@ func BenchmarkIdentifyInjuredSegments(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		interval := time.Second
-		checker := newChecker(pointerdb, repairQueue, overlayServer, limit, logger, interval)
+		checker := newChecker(pointerdb, repairQueue, overlayServer, teststore.New(), teststore.New(), limit, logger, interval, time.Hour)
 		err = checker.IdentifyInjuredSegments(ctx)
 		assert.NoError(b, err)
 
@@ -11,35 +11,99 @@ import (
 
 	"storj.io/storj/pkg/datarepair/queue"
 	"storj.io/storj/pkg/overlay"
+	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/pointerdb"
 	"storj.io/storj/pkg/provider"
-	"storj.io/storj/storage/redis"
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/boltdb"
+	"storj.io/storj/storage/postgreskv"
 )
 
 // Config contains configurable values for repairer
 type Config struct {
-	QueueAddress string        `help:"data checker queue address" default:"redis://127.0.0.1:6378?db=1&password=abc123"`
-	Interval     time.Duration `help:"how frequently checker should audit segments" default:"30s"`
+	QueueAddress     string        `help:"data checker queue address" default:"redis://127.0.0.1:6378?db=1&password=abc123"`
+	Interval         time.Duration `help:"how frequently checker should audit segments" default:"30s"`
+	BatchSize        int           `help:"how many segments the checker checks every interval" default:"1000"`
+	FullScanInterval time.Duration `help:"how frequently the checker starts checking all segments again" default:"24h"`
+	DatabaseURL      string        `help:"the database the checker keeps the position of its scan and the irreparable segments in, which can't be the pointer database" default:"bolt://$CONFDIR/checker.db"`
+	MinUptime        float64       `help:"the minimum uptime ratio of the nodes whose pieces are counted as good, if the overlay uses statdb" default:"0"`
+	MinAuditSuccess  float64       `help:"the minimum audit success ratio of the nodes whose pieces are counted as good, if the overlay uses statdb" default:"0"`
+	MinAuditCount    int64         `help:"the minimum number of audits of the nodes whose pieces are counted as good, if the overlay uses statdb" default:"0"`
+	MaxOffline       time.Duration `help:"how long contacting a node in the overlay may have been failing before its pieces are counted as lost, whatever its reputation, 0 disables the limit" default:"24h"`
+	DryRun           bool          `help:"log which segments would be repaired, with the pieces and bandwidth their repair would take, instead of queueing them for repair" default:"false"`
+	RetryInterval    time.Duration `help:"how frequently the irreparable segments are checked again, since their nodes may be back online, 0 disables retrying them" default:"1h"`
+	EscalateAfter    int           `help:"how many times an irreparable segment is checked again before it's reported to the operator, 0 disables reporting them" default:"24"`
 }
 
+// irreparablePrefix is the prefix of the irreparable segments in the
+// database of the checker
+var irreparablePrefix = storage.Key("irreparable/")
+
 // Initialize a Checker struct
-func (c Config) initialize(ctx context.Context) (Checker, error) {
+func (c Config) initialize(ctx context.Context, repairQueue *queue.Queue, db storage.KeyValueStore) Checker {
 	pointerdb := pointerdb.LoadFromContext(ctx)
-	overlay := overlay.LoadServerFromContext(ctx)
-	client, err := redis.NewClientFrom(c.QueueAddress)
+	overlayServer := overlay.LoadServerFromContext(ctx)
+	checkpoints := storage.WithPrefix(db, storage.Key("checkpoint/"))
+	irreparable := storage.WithPrefix(db, irreparablePrefix)
+
+	check := newChecker(pointerdb, repairQueue, overlayServer, checkpoints, irreparable, c.BatchSize, zap.L(), c.Interval, c.FullScanInterval)
+	check.dossiers = overlay.LoadDossiersFromContext(ctx)
+	check.maxOffline = c.MaxOffline
+	check.dryRun = c.DryRun
+	check.escalateAfter = c.EscalateAfter
+	if c.RetryInterval > 0 {
+		check.retryTicker = time.NewTicker(c.RetryInterval)
+	}
+	check.minReputation = &pb.NodeRep{
+		MinUptime:       float32(c.MinUptime),
+		MinAuditSuccess: float32(c.MinAuditSuccess),
+		MinAuditCount:   c.MinAuditCount,
+	}
+	return check
+}
+
+// openDB opens the database of the checker
+func (c Config) openDB() (storage.KeyValueStore, error) {
+	dburl, err := utils.ParseURL(c.DatabaseURL)
 	if err != nil {
 		return nil, Error.Wrap(err)
 	}
-	repairQueue := queue.NewQueue(client)
-	return newChecker(pointerdb, repairQueue, overlay, 0, zap.L(), c.Interval), nil
+	switch dburl.Scheme {
+	case "bolt":
+		return boltdb.New(dburl.Path, "checker")
+	case "postgres", "postgresql":
+		return postgreskv.New(c.DatabaseURL)
+	default:
+		return nil, Error.New("unsupported db scheme: %s", dburl.Scheme)
+	}
+}
+
+// OpenIrreparableDB opens the irreparable segments in the database of the
+// checker, which is closed when they're closed
+func (c Config) OpenIrreparableDB() (*IrreparableDB, error) {
+	db, err := c.openDB()
+	if err != nil {
+		return nil, err
+	}
+	return &IrreparableDB{db: storage.WithPrefix(db, irreparablePrefix), closer: db}, nil
 }
 
 // Run runs the checker with configured values
 func (c Config) Run(ctx context.Context, server *provider.Provider) (err error) {
-	check, err := c.initialize(ctx)
+	repairQueue, err := queue.Open(c.QueueAddress)
 	if err != nil {
-		return err
+		return Error.Wrap(err)
 	}
+	defer func() { _ = repairQueue.Close() }()
+
+	db, err := c.openDB()
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	check := c.initialize(ctx, repairQueue, db)
 
 	// TODO(coyle): we need to figure out how to propagate the error up to cancel the service
 	go func() {
@@ -0,0 +1,150 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"time"
+
+	"storj.io/storj/pkg/overlay"
+	"storj.io/storj/pkg/pb"
+)
+
+// Health is the health of a segment, by how many of its pieces are on nodes
+// which are online, and how many of those have a good reputation. Nodes which
+// are in the overlay, but which couldn't be contacted for longer than the
+// checker allows, aren't online.
+type Health int
+
+const (
+	// Healthy segments have at least as many good pieces as their repair
+	// threshold
+	Healthy Health = iota
+	// NeedsRepair segments have fewer good pieces than their repair threshold
+	NeedsRepair
+	// Critical segments have no more good pieces than are needed to
+	// reconstruct them, so losing another one could make them irreparable
+	Critical
+	// Irreparable segments are on fewer online nodes than are needed to
+	// reconstruct them
+	Irreparable
+)
+
+// String returns the name of h, which is used for its metrics
+func (h Health) String() string {
+	switch h {
+	case Healthy:
+		return "healthy"
+	case NeedsRepair:
+		return "needs_repair"
+	case Critical:
+		return "critical"
+	case Irreparable:
+		return "irreparable"
+	default:
+		return "unknown"
+	}
+}
+
+// segmentHealth is what the checker found out about a segment
+type segmentHealth struct {
+	Health Health
+	// Available is the number of pieces on online nodes
+	Available int
+	// Good is the number of pieces on online nodes with a good reputation
+	Good int
+	// Lost are the indices of the pieces which aren't good, because their
+	// nodes are offline or have a bad reputation. The pieces on online nodes
+	// with a bad reputation aren't lost if there are fewer good pieces than
+	// are needed to reconstruct the segment, so that the repair downloads
+	// them too.
+	Lost []int32
+}
+
+// classify returns the health of a segment with the given redundancy, whose
+// pieces are on nodes, where the nodes which aren't in the overlay are nil
+func (c *checker) classify(ctx context.Context, redundancy *pb.RedundancyScheme, nodes []*pb.Node) segmentHealth {
+	now := time.Now()
+	var health segmentHealth
+	var offline []int32
+	for i, n := range nodes {
+		var dossier *overlay.NodeDossier
+		if n != nil && c.dossiers != nil {
+			dossier = c.dossiers.Compose(ctx, n)
+		}
+		if n == nil || c.offline(dossier, now) {
+			health.Lost = append(health.Lost, int32(i))
+			offline = append(offline, int32(i))
+			continue
+		}
+		health.Available++
+		if !c.meetsReputation(dossier) {
+			health.Lost = append(health.Lost, int32(i))
+			continue
+		}
+		health.Good++
+	}
+	if health.Good < int(redundancy.GetMinReq()) {
+		health.Lost = offline
+	}
+
+	switch {
+	case health.Available < int(redundancy.GetMinReq()):
+		health.Health = Irreparable
+	case health.Good <= int(redundancy.GetMinReq()):
+		health.Health = Critical
+	case health.Good < int(redundancy.GetRepairThreshold()):
+		health.Health = NeedsRepair
+	default:
+		health.Health = Healthy
+	}
+	return health
+}
+
+// offline returns whether the node of dossier couldn't be contacted for
+// longer than the checker allows at now. Nodes are never offline without
+// dossiers, or if the checker doesn't limit how long they may be.
+func (c *checker) offline(dossier *overlay.NodeDossier, now time.Time) bool {
+	if dossier == nil || c.maxOffline <= 0 {
+		return false
+	}
+	return dossier.Liveness.OfflineFor(now) > c.maxOffline
+}
+
+// meetsReputation returns whether the online node of dossier has the minimum
+// reputation of the checker, which every node has without dossiers
+func (c *checker) meetsReputation(dossier *overlay.NodeDossier) bool {
+	if dossier == nil {
+		return true
+	}
+	return dossier.MeetsReputation(c.minReputation)
+}
+
+// lossRanks is the number of ranks of the loss rates of the segments with the
+// same margin of good pieces, which order them for repair
+const lossRanks = 100
+
+// repairPriority returns the priority an injured segment is queued for repair
+// with. The fewer good pieces a segment has left above the minimum required,
+// the higher its priority, so the segments closest to being lost are
+// repaired first, starting with the critical ones. The segments with the same
+// margin are ordered by how many pieces they lost per day since they were
+// created, which ranks segments created at an unknown time last.
+func repairPriority(redundancy *pb.RedundancyScheme, health segmentHealth, created, now time.Time) int {
+	margin := health.Good - int(redundancy.GetMinReq())
+
+	var rank int
+	if !created.IsZero() {
+		days := now.Sub(created).Hours() / 24
+		if days < 1 {
+			days = 1
+		}
+		rank = int(float64(len(health.Lost)) / days)
+		if rank >= lossRanks {
+			rank = lossRanks - 1
+		}
+	}
+
+	return -margin*lossRanks + rank
+}
@@ -0,0 +1,187 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/datarepair/queue"
+	"storj.io/storj/pkg/overlay"
+	"storj.io/storj/pkg/overlay/mocks"
+	"storj.io/storj/pkg/pb"
+	sdbproto "storj.io/storj/pkg/statdb/proto"
+	"storj.io/storj/pkg/statdb/sdbclient"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+)
+
+type mockStatDB struct {
+	sdbclient.Client
+	stats map[string]*sdbproto.NodeStats
+}
+
+func (m *mockStatDB) Get(ctx context.Context, nodeID []byte) (*sdbproto.NodeStats, error) {
+	stats, ok := m.stats[string(nodeID)]
+	if !ok {
+		return nil, errs.New("node not found")
+	}
+	return stats, nil
+}
+
+func TestClassify(t *testing.T) {
+	ctx := context.Background()
+	good := &pb.Node{Id: "good"}
+	bad := &pb.Node{Id: "bad"}
+	statdb := &mockStatDB{stats: map[string]*sdbproto.NodeStats{
+		"good": {UptimeRatio: 0.99, AuditSuccessRatio: 0.99, AuditCount: 100},
+		"bad":  {UptimeRatio: 0.5, AuditSuccessRatio: 0.99, AuditCount: 100},
+	}}
+
+	c := &checker{
+		dossiers:      overlay.NewDossierService(nil, statdb),
+		minReputation: &pb.NodeRep{MinUptime: 0.9},
+	}
+	redundancy := &pb.RedundancyScheme{MinReq: 2, RepairThreshold: 4}
+
+	for i, tt := range []struct {
+		nodes  []*pb.Node
+		health Health
+		lost   []int32
+	}{
+		{[]*pb.Node{good, good, good, good, good}, Healthy, nil},
+		{[]*pb.Node{good, good, good, nil, bad}, NeedsRepair, []int32{3, 4}},
+		{[]*pb.Node{good, good, bad, nil, nil}, Critical, []int32{2, 3, 4}},
+		// pieces on nodes with a bad reputation can still be downloaded, and
+		// they are when there aren't enough good ones
+		{[]*pb.Node{good, bad, bad, nil, nil}, Critical, []int32{3, 4}},
+		{[]*pb.Node{bad, bad, nil, nil, nil}, Critical, []int32{2, 3, 4}},
+		{[]*pb.Node{good, nil, nil, nil, nil}, Irreparable, []int32{1, 2, 3, 4}},
+	} {
+		health := c.classify(ctx, redundancy, tt.nodes)
+		assert.Equal(t, tt.health, health.Health, "case %d", i)
+		assert.Equal(t, tt.lost, health.Lost, "case %d", i)
+	}
+
+	// without dossiers every online node is good
+	c.dossiers = nil
+	health := c.classify(ctx, redundancy, []*pb.Node{bad, bad, bad, bad, nil})
+	assert.Equal(t, Healthy, health.Health)
+
+	// nodes which couldn't be contacted for too long aren't online
+	c.dossiers = overlay.NewDossierService(nil, nil)
+	c.minReputation = &pb.NodeRep{}
+	c.maxOffline = time.Millisecond
+	failing := &pb.Node{Id: "failing"}
+	c.dossiers.ConnFailure(ctx, failing, errs.New("dial failed"))
+	health = c.classify(ctx, redundancy, []*pb.Node{failing, good, good, good, good})
+	assert.Equal(t, 5, health.Available)
+	time.Sleep(2 * time.Millisecond)
+	health = c.classify(ctx, redundancy, []*pb.Node{failing, good, good, good, good})
+	assert.Equal(t, 4, health.Available)
+	assert.Equal(t, []int32{0}, health.Lost)
+}
+
+func TestIrreparableSegments(t *testing.T) {
+	ctx := context.Background()
+	irreparable := teststore.New()
+	c := &checker{
+		irreparable: NewIrreparableDB(irreparable),
+		overlay:     mocks.NewOverlay([]*pb.Node{{Id: "online"}}),
+		logger:      zap.NewNop(),
+	}
+
+	remote := &pb.RemoteSegment{
+		Redundancy: &pb.RedundancyScheme{MinReq: 2, RepairThreshold: 3},
+		RemotePieces: []*pb.RemotePiece{
+			{PieceNum: 0, NodeId: "online"},
+			{PieceNum: 1, NodeId: "offline"},
+			{PieceNum: 2, NodeId: "offline2"},
+		},
+	}
+	assert.NoError(t, c.checkSegment(ctx, "a/b", &pb.Pointer{Type: pb.Pointer_REMOTE, Remote: remote}))
+
+	value, err := irreparable.Get(storage.Key("a/b"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	segment := &pb.IrreparableSegment{}
+	assert.NoError(t, proto.Unmarshal(value, segment))
+	assert.Equal(t, "a/b", segment.GetPath())
+	assert.Equal(t, int32(1), segment.GetAvailablePieces())
+	assert.Equal(t, int32(2), segment.GetMinReq())
+	assert.NotEmpty(t, segment.GetReason())
+	assert.NotNil(t, segment.GetLastDetected())
+}
+
+func TestRepairPriority(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	redundancy := &pb.RedundancyScheme{MinReq: 2, RepairThreshold: 6}
+	priority := func(good, lost int, age time.Duration) int {
+		created := time.Time{}
+		if age > 0 {
+			created = now.Add(-age)
+		}
+		return repairPriority(redundancy, segmentHealth{Good: good, Lost: make([]int32, lost)}, created, now)
+	}
+
+	// the segments with fewer good pieces left go first
+	assert.True(t, priority(2, 4, 0) > priority(3, 3, 0))
+	assert.True(t, priority(3, 3, 0) > priority(5, 1, 0))
+	// whatever their loss rate
+	assert.True(t, priority(3, 3, 0) > priority(4, 1000, time.Hour))
+
+	// then the ones losing their pieces faster
+	assert.True(t, priority(3, 3, day) > priority(3, 3, 3*day))
+	assert.True(t, priority(3, 3, 3*day) > priority(3, 3, 0))
+	// younger segments than a day are ranked like they're a day old
+	assert.Equal(t, priority(3, 3, day), priority(3, 3, time.Minute))
+}
+
+func TestQueueByPriority(t *testing.T) {
+	ctx := context.Background()
+	repairQueue := queue.NewQueue(teststore.NewQueue())
+	c := &checker{
+		repairQueue: repairQueue,
+		overlay:     mocks.NewOverlay([]*pb.Node{{Id: "a"}, {Id: "b"}, {Id: "c"}, {Id: "d"}, {Id: "e"}}),
+		logger:      zap.NewNop(),
+	}
+	created, err := ptypes.TimestampProto(time.Now().Add(-10 * 24 * time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pointer := func(nodeIDs ...string) *pb.Pointer {
+		remote := &pb.RemoteSegment{
+			Redundancy: &pb.RedundancyScheme{MinReq: 2, RepairThreshold: 5, Total: int32(len(nodeIDs))},
+		}
+		for i, id := range nodeIDs {
+			remote.RemotePieces = append(remote.RemotePieces, &pb.RemotePiece{PieceNum: int32(i), NodeId: id})
+		}
+		return &pb.Pointer{Type: pb.Pointer_REMOTE, Remote: remote, CreationDate: created}
+	}
+
+	assert.NoError(t, c.checkSegment(ctx, "injured", pointer("a", "b", "c", "d", "x")))
+	assert.NoError(t, c.checkSegment(ctx, "critical", pointer("a", "b", "x", "y", "z")))
+	assert.NoError(t, c.checkSegment(ctx, "healthy", pointer("a", "b", "c", "d", "e")))
+
+	// the critical segment is repaired first, although it was queued after
+	// the injured one
+	for _, path := range []string{"critical", "injured"} {
+		claim, err := repairQueue.Claim(time.Hour)
+		if assert.NoError(t, err) {
+			assert.Equal(t, path, claim.Segment.GetPath())
+		}
+	}
+	_, err = repairQueue.Claim(time.Hour)
+	assert.True(t, storage.ErrEmptyQueue.Has(err))
+}
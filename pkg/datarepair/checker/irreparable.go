@@ -0,0 +1,189 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/storage"
+)
+
+// IrreparableDB keeps the irreparable segments found by the checker by their
+// path
+type IrreparableDB struct {
+	db storage.KeyValueStore
+	// closer is the database db is kept in, if it was opened for the
+	// IrreparableDB
+	closer io.Closer
+}
+
+// NewIrreparableDB returns an IrreparableDB keeping the segments in db
+func NewIrreparableDB(db storage.KeyValueStore) *IrreparableDB {
+	return &IrreparableDB{db: db}
+}
+
+// Get returns the irreparable segment at path, or an error of class
+// storage.ErrKeyNotFound if there is none
+func (idb *IrreparableDB) Get(path string) (*pb.IrreparableSegment, error) {
+	value, err := idb.db.Get(storage.Key(path))
+	if err != nil {
+		return nil, err
+	}
+	segment := &pb.IrreparableSegment{}
+	if err := proto.Unmarshal(value, segment); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return segment, nil
+}
+
+// Put keeps segment, replacing the one at its path
+func (idb *IrreparableDB) Put(segment *pb.IrreparableSegment) error {
+	value, err := proto.Marshal(segment)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	return Error.Wrap(idb.db.Put(storage.Key(segment.GetPath()), value))
+}
+
+// Delete removes the irreparable segment at path
+func (idb *IrreparableDB) Delete(path string) error {
+	return idb.db.Delete(storage.Key(path))
+}
+
+// List returns all the irreparable segments, ordered by their path
+func (idb *IrreparableDB) List() (segments []*pb.IrreparableSegment, err error) {
+	err = idb.db.Iterate(storage.IterateOptions{Recurse: true},
+		func(it storage.Iterator) error {
+			var item storage.ListItem
+			for it.Next(&item) {
+				segment := &pb.IrreparableSegment{}
+				if err := proto.Unmarshal(item.Value, segment); err != nil {
+					return Error.Wrap(err)
+				}
+				segments = append(segments, segment)
+			}
+			return nil
+		},
+	)
+	return segments, err
+}
+
+// Close closes the database of idb, if it was opened for it
+func (idb *IrreparableDB) Close() error {
+	if idb.closer == nil {
+		return nil
+	}
+	return idb.closer.Close()
+}
+
+// recordIrreparable keeps the irreparable segment at path with the reason it
+// can't be repaired, and when that was detected last. How often it was
+// retried, and what the operator decided about it, are kept.
+func (c *checker) recordIrreparable(path string, redundancy *pb.RedundancyScheme, health segmentHealth) error {
+	segment, err := c.irreparable.Get(path)
+	if storage.ErrKeyNotFound.Has(err) {
+		segment, err = &pb.IrreparableSegment{Path: path}, nil
+	}
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	if !segment.GetLossAccepted() {
+		c.logger.Warn("irreparable segment", zap.String("path", path), zap.Int("available", health.Available))
+	}
+	if err := updateIrreparable(segment, redundancy, health); err != nil {
+		return err
+	}
+	return c.irreparable.Put(segment)
+}
+
+// updateIrreparable updates segment with what the checker found out about it
+func updateIrreparable(segment *pb.IrreparableSegment, redundancy *pb.RedundancyScheme, health segmentHealth) error {
+	lastDetected, err := ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	segment.Reason = fmt.Sprintf("only %d pieces are on online nodes, but %d are needed", health.Available, redundancy.GetMinReq())
+	segment.AvailablePieces = int32(health.Available)
+	segment.MinReq = redundancy.GetMinReq()
+	segment.LastDetected = lastDetected
+	return nil
+}
+
+// RetryIrreparable checks the irreparable segments again, except the ones
+// whose loss was accepted, since the nodes of their pieces may be back
+// online. The segments which can be repaired again are queued for repair,
+// and the ones which were retried as often as the checker escalates after
+// are reported to the operator.
+func (c *checker) RetryIrreparable(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	segments, err := c.irreparable.List()
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	for _, segment := range segments {
+		if segment.GetLossAccepted() {
+			continue
+		}
+		if err := c.retrySegment(ctx, segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retrySegment checks the irreparable segment again
+func (c *checker) retrySegment(ctx context.Context, segment *pb.IrreparableSegment) error {
+	path := segment.GetPath()
+	value, err := c.pointerdb.DB.Get(storage.Key(path))
+	if storage.ErrKeyNotFound.Has(err) {
+		// the segment was deleted
+		return Error.Wrap(c.irreparable.Delete(path))
+	}
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	pointer := &pb.Pointer{}
+	if err := proto.Unmarshal(value, pointer); err != nil {
+		return Error.New("error unmarshalling pointer %s", err)
+	}
+	remote := pointer.GetRemote()
+	if remote == nil {
+		return Error.Wrap(c.irreparable.Delete(path))
+	}
+
+	health, err := c.segmentHealth(ctx, remote)
+	if err != nil {
+		return err
+	}
+	if health.Health != Irreparable {
+		c.logger.Info("irreparable segment can be repaired again", zap.String("path", path), zap.Int("available", health.Available))
+		mon.Event("irreparable_recovered")
+		if err := c.queueRepair(path, pointer, health); err != nil {
+			return err
+		}
+		return Error.Wrap(c.irreparable.Delete(path))
+	}
+
+	if err := updateIrreparable(segment, remote.GetRedundancy(), health); err != nil {
+		return err
+	}
+	segment.RepairAttempts++
+	if !segment.GetEscalated() && c.escalateAfter > 0 && int(segment.GetRepairAttempts()) >= c.escalateAfter {
+		c.logger.Error("irreparable segment needs the attention of the operator",
+			zap.String("path", path), zap.Int32("attempts", segment.GetRepairAttempts()),
+			zap.Int("available", health.Available), zap.Int32("required", remote.GetRedundancy().GetMinReq()))
+		mon.Event("irreparable_escalated")
+		segment.Escalated = true
+	}
+	return c.irreparable.Put(segment)
+}
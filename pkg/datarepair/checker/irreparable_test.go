@@ -0,0 +1,88 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/datarepair/queue"
+	"storj.io/storj/pkg/overlay"
+	"storj.io/storj/pkg/overlay/mocks"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/pointerdb"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+)
+
+func TestRetryIrreparable(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	repairQueue := queue.NewQueue(teststore.NewQueue())
+	c := &checker{
+		pointerdb:     pointerdb.NewServer(teststore.New(), &overlay.Cache{}, logger, pointerdb.Config{}, nil),
+		repairQueue:   repairQueue,
+		irreparable:   NewIrreparableDB(teststore.New()),
+		overlay:       mocks.NewOverlay([]*pb.Node{{Id: "a"}}),
+		logger:        logger,
+		escalateAfter: 2,
+	}
+
+	putPointer := func(path string, nodeIDs ...string) {
+		remote := &pb.RemoteSegment{Redundancy: &pb.RedundancyScheme{MinReq: 2, RepairThreshold: 3}}
+		for i, id := range nodeIDs {
+			remote.RemotePieces = append(remote.RemotePieces, &pb.RemotePiece{PieceNum: int32(i), NodeId: id})
+		}
+		value, err := proto.Marshal(&pb.Pointer{Type: pb.Pointer_REMOTE, Remote: remote})
+		if assert.NoError(t, err) {
+			assert.NoError(t, c.pointerdb.DB.Put(storage.Key(path), value))
+		}
+	}
+	putPointer("lost", "a", "b", "c")
+	putPointer("accepted", "a", "b", "c")
+	putPointer("back", "a", "b", "c")
+	for _, path := range []string{"lost", "accepted", "back", "deleted"} {
+		assert.NoError(t, c.irreparable.Put(&pb.IrreparableSegment{Path: path, LossAccepted: path == "accepted"}))
+	}
+
+	// the segments are retried until they're escalated
+	assert.NoError(t, c.RetryIrreparable(ctx))
+	segment, err := c.irreparable.Get("lost")
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(1), segment.GetRepairAttempts())
+		assert.False(t, segment.GetEscalated())
+		assert.Equal(t, int32(1), segment.GetAvailablePieces())
+	}
+
+	// once the nodes of a segment are back, it's queued for repair
+	c.overlay = mocks.NewOverlay([]*pb.Node{{Id: "a"}, {Id: "b"}})
+	putPointer("lost", "a", "c", "d")
+	assert.NoError(t, c.RetryIrreparable(ctx))
+
+	segment, err = c.irreparable.Get("lost")
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(2), segment.GetRepairAttempts())
+		assert.True(t, segment.GetEscalated())
+	}
+	injured, err := repairQueue.Dequeue()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "back", injured.GetPath())
+		assert.Equal(t, []int32{2}, injured.GetLostPieces())
+	}
+	_, err = repairQueue.Dequeue()
+	assert.True(t, storage.ErrEmptyQueue.Has(err))
+
+	// the segments which are back or deleted aren't kept, and the accepted
+	// ones aren't retried
+	segments, err := c.irreparable.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []*pb.IrreparableSegment{
+		{Path: "accepted", LossAccepted: true},
+		segment,
+	}, segments)
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/pb"
+)
+
+// repairEstimate is what repairing injured segments would take, which a
+// checker in dry-run mode computes instead of queueing them for repair
+type repairEstimate struct {
+	Segments int64
+	// Pieces are the pieces which would be rebuilt and moved to new nodes
+	Pieces int64
+	// DownloadBytes are the bytes of the pieces which would be downloaded to
+	// rebuild the lost ones
+	DownloadBytes int64
+	// UploadBytes are the bytes of the rebuilt pieces
+	UploadBytes int64
+}
+
+// estimateRepair returns what repairing the remote segment of pointer would
+// take: its lost pieces are rebuilt from as many pieces as are required to
+// reconstruct it, and uploaded to new nodes
+func estimateRepair(pointer *pb.Pointer, health segmentHealth) repairEstimate {
+	redundancy := pointer.GetRemote().GetRedundancy()
+	pieceSize := estimatePieceSize(pointer.GetSize(), redundancy)
+	return repairEstimate{
+		Segments:      1,
+		Pieces:        int64(len(health.Lost)),
+		DownloadBytes: int64(redundancy.GetMinReq()) * pieceSize,
+		UploadBytes:   int64(len(health.Lost)) * pieceSize,
+	}
+}
+
+// estimatePieceSize returns the size of the pieces of a segment of size
+// bytes, which is padded to full stripes of erasure shares, without the
+// hashes of the shares
+func estimatePieceSize(size int64, redundancy *pb.RedundancyScheme) int64 {
+	required := int64(redundancy.GetMinReq())
+	shareSize := int64(redundancy.GetErasureShareSize())
+	if required <= 0 {
+		return 0
+	}
+	if shareSize <= 0 {
+		return (size + required - 1) / required
+	}
+	stripeSize := required * shareSize
+	stripes := (size + stripeSize - 1) / stripeSize
+	return stripes * shareSize
+}
+
+// add adds other to estimate
+func (estimate *repairEstimate) add(other repairEstimate) {
+	estimate.Segments += other.Segments
+	estimate.Pieces += other.Pieces
+	estimate.DownloadBytes += other.DownloadBytes
+	estimate.UploadBytes += other.UploadBytes
+}
+
+// simulateRepair adds what repairing the segment of pointer at path would
+// take to the estimate of the scan, instead of queueing it for repair
+func (c *checker) simulateRepair(path string, pointer *pb.Pointer, health segmentHealth, priority int) {
+	estimate := estimateRepair(pointer, health)
+	c.estimate.add(estimate)
+
+	mon.IntVal("dry_run_repair_pieces").Observe(estimate.Pieces)
+	mon.IntVal("dry_run_repair_download_bytes").Observe(estimate.DownloadBytes)
+	mon.IntVal("dry_run_repair_upload_bytes").Observe(estimate.UploadBytes)
+	c.logger.Debug("segment would be repaired",
+		zap.String("path", path),
+		zap.Stringer("health", health.Health),
+		zap.Int("priority", priority),
+		zap.Int64("pieces", estimate.Pieces),
+		zap.Int64("download bytes", estimate.DownloadBytes),
+		zap.Int64("upload bytes", estimate.UploadBytes))
+}
+
+// reportEstimate logs what repairing the injured segments found by the scan
+// which just finished would take, and starts the estimate of the next scan.
+// A scan which continued after the checker restarted only reports the
+// segments checked since then.
+func (c *checker) reportEstimate() {
+	c.logger.Info("repairs needed by the segments of the scan",
+		zap.Int64("segments", c.estimate.Segments),
+		zap.Int64("pieces", c.estimate.Pieces),
+		zap.Int64("download bytes", c.estimate.DownloadBytes),
+		zap.Int64("upload bytes", c.estimate.UploadBytes))
+	c.estimate = repairEstimate{}
+}
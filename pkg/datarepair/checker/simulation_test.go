@@ -0,0 +1,74 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/datarepair/queue"
+	"storj.io/storj/pkg/overlay/mocks"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+)
+
+func TestEstimatePieceSize(t *testing.T) {
+	for i, tt := range []struct {
+		size      int64
+		minReq    int32
+		shareSize int32
+		pieceSize int64
+	}{
+		{0, 2, 1024, 0},
+		{1, 2, 1024, 1024},
+		{2048, 2, 1024, 1024},
+		{2049, 2, 1024, 2048},
+		{10, 4, 0, 3},
+		{10, 0, 1024, 0},
+	} {
+		redundancy := &pb.RedundancyScheme{MinReq: tt.minReq, ErasureShareSize: tt.shareSize}
+		assert.Equal(t, tt.pieceSize, estimatePieceSize(tt.size, redundancy), "case %d", i)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	repairQueue := queue.NewQueue(teststore.NewQueue())
+	c := &checker{
+		repairQueue: repairQueue,
+		overlay:     mocks.NewOverlay([]*pb.Node{{Id: "a"}, {Id: "b"}, {Id: "c"}}),
+		logger:      zap.NewNop(),
+		dryRun:      true,
+	}
+
+	remote := &pb.RemoteSegment{
+		Redundancy: &pb.RedundancyScheme{MinReq: 2, RepairThreshold: 4, Total: 5, ErasureShareSize: 1024},
+	}
+	for i, id := range []string{"a", "b", "c", "x", "y"} {
+		remote.RemotePieces = append(remote.RemotePieces, &pb.RemotePiece{PieceNum: int32(i), NodeId: id})
+	}
+	pointer := &pb.Pointer{Type: pb.Pointer_REMOTE, Remote: remote, Size: 4096}
+	assert.NoError(t, c.checkSegment(ctx, "a/b", pointer))
+	assert.NoError(t, c.checkSegment(ctx, "a/c", pointer))
+
+	// the segments aren't queued for repair
+	_, err := repairQueue.Claim(time.Hour)
+	assert.True(t, storage.ErrEmptyQueue.Has(err))
+
+	// but their repairs are estimated
+	assert.Equal(t, repairEstimate{
+		Segments:      2,
+		Pieces:        4,
+		DownloadBytes: 2 * 2 * 2048,
+		UploadBytes:   2 * 2 * 2048,
+	}, c.estimate)
+
+	c.reportEstimate()
+	assert.Equal(t, repairEstimate{}, c.estimate)
+}
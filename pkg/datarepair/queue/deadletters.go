@@ -0,0 +1,71 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package queue
+
+import (
+	"time"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/deadletter"
+)
+
+// DeadLetter is a segment which was moved out of the repair queue, because
+// it failed to be repaired too often
+type DeadLetter struct {
+	// Key identifies the segment in the dead-letter store
+	Key     storage.Key
+	Segment pb.InjuredSegment
+	// Claims is how often the segment was claimed before it was moved
+	Claims int
+	// Reason is why the segment was moved
+	Reason string
+	Moved  time.Time
+}
+
+// WithDeadLetters returns the queue of the segments of q, which moves the
+// segments claimed more than maxClaims times without being repaired to dead,
+// rather than returning them again. q shouldn't be used or closed anymore;
+// closing the returned queue closes q and dead.
+func (q *Queue) WithDeadLetters(dead storage.KeyValueStore, maxClaims int) *Queue {
+	letters := deadletter.New(q.db, dead, maxClaims)
+	return &Queue{db: letters, address: q.address, letters: letters, dead: dead}
+}
+
+// DeadLetters lists up to limit of the segments moved out of the queue,
+// starting from the one with key first, in the order they were moved
+func (q *Queue) DeadLetters(first storage.Key, limit int) ([]DeadLetter, error) {
+	if q.letters == nil {
+		return nil, Error.New("the queue has no dead-letter store")
+	}
+	letters, err := q.letters.Letters(first, limit)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	deadLetters := make([]DeadLetter, 0, len(letters))
+	for _, letter := range letters {
+		seg, err := unmarshalSegment(letter.Value)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, DeadLetter{
+			Key:     letter.Key,
+			Segment: *seg,
+			Claims:  letter.Claims,
+			Reason:  letter.Reason,
+			Moved:   letter.Moved,
+		})
+	}
+	return deadLetters, nil
+}
+
+// Requeue moves the segment with key from the dead-letter store back to the
+// queue, so it's repaired again
+func (q *Queue) Requeue(key storage.Key) error {
+	if q.letters == nil {
+		return Error.New("the queue has no dead-letter store")
+	}
+	return Error.Wrap(q.letters.Requeue(key))
+}
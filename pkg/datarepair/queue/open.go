@@ -0,0 +1,93 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package queue
+
+import (
+	"sync"
+
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/boltdb"
+	"storj.io/storj/storage/postgreskv"
+	"storj.io/storj/storage/redis"
+)
+
+// queueName is the name of the repair queue in the storage it's kept in
+const queueName = "repair"
+
+// the queues opened by a process are shared by their address, so the checker
+// and the repairer of a satellite can both use a queue in a bolt database,
+// which can't be opened twice
+var shared = struct {
+	sync.Mutex
+	queues map[string]*sharedQueue
+}{queues: map[string]*sharedQueue{}}
+
+type sharedQueue struct {
+	db   storage.Queue
+	refs int
+}
+
+// Open opens the repair queue at address, which is either a redis address
+// like for redis.NewClientFrom, bolt://path/to/file.db or a postgres url. A
+// bolt database can't be shared by the processes of the checker and the
+// repairer, unlike redis and postgres.
+func Open(address string) (*Queue, error) {
+	shared.Lock()
+	defer shared.Unlock()
+
+	queue, ok := shared.queues[address]
+	if !ok {
+		db, err := openStorage(address)
+		if err != nil {
+			return nil, err
+		}
+		queue = &sharedQueue{db: db}
+		shared.queues[address] = queue
+	}
+	queue.refs++
+
+	return &Queue{db: queue.db, address: address}, nil
+}
+
+// openStorage opens the storage.Queue at address
+func openStorage(address string) (storage.Queue, error) {
+	url, err := utils.ParseURL(address)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	var db storage.Queue
+	switch url.Scheme {
+	case "redis":
+		db, err = redis.NewQueue(address, queueName)
+	case "bolt":
+		db, err = boltdb.NewQueue(url.Path, queueName)
+	case "postgres", "postgresql":
+		db, err = postgreskv.NewQueue(address, queueName)
+	default:
+		err = Error.New("unsupported queue scheme: %s", url.Scheme)
+	}
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return db, nil
+}
+
+// release closes the shared queue at address when it isn't used anymore
+func release(address string) error {
+	shared.Lock()
+	defer shared.Unlock()
+
+	queue, ok := shared.queues[address]
+	if !ok {
+		return nil
+	}
+	queue.refs--
+	if queue.refs > 0 {
+		return nil
+	}
+	delete(shared.queues, address)
+	return Error.Wrap(queue.db.Close())
+}
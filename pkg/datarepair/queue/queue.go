@@ -4,58 +4,73 @@
 package queue
 
 import (
-	"encoding/binary"
-	"math/rand"
-	"sync"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 
 	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/utils"
 	"storj.io/storj/storage"
+	"storj.io/storj/storage/deadletter"
 )
 
-// RepairQueue is the interface for the data repair queue
+// RepairQueue is the interface for the data repair queue. Segments claimed
+// from it stay in it until their repair is acknowledged, so they're repaired
+// again when a repairer crashes or restarts while repairing them.
 type RepairQueue interface {
 	Enqueue(qi *pb.InjuredSegment) error
+	EnqueueWithPriority(qi *pb.InjuredSegment, priority int) error
 	Dequeue() (pb.InjuredSegment, error)
+	Claim(timeout time.Duration) (Claim, error)
+	Ack(claim Claim) error
+	Nack(claim Claim) error
+	Len() (int, error)
+}
+
+// Claim is an injured segment claimed from the queue
+type Claim struct {
+	Segment pb.InjuredSegment
+	claim   storage.Claim
 }
 
 // Queue implements the RepairQueue interface
 type Queue struct {
-	mu sync.Mutex
-	db storage.KeyValueStore
+	db storage.Queue
+	// address is set for the queues opened with Open, which are shared
+	address string
+	// letters is set for the queues moving the segments which fail to be
+	// repaired to a dead-letter store
+	letters *deadletter.Queue
+	dead    storage.KeyValueStore
 }
 
-// NewQueue returns a pointer to a new Queue instance with an initialized connection to Redis
-func NewQueue(client storage.KeyValueStore) *Queue {
-	return &Queue{
-		mu: sync.Mutex{},
-		db: client,
-	}
+// NewQueue returns a Queue of the injured segments in db
+func NewQueue(db storage.Queue) *Queue {
+	return &Queue{db: db}
 }
 
 // Enqueue adds a repair segment to the queue
 func (q *Queue) Enqueue(qi *pb.InjuredSegment) error {
-	q.mu.Lock()
-	defer q.mu.Unlock()
-	const dateSize = 8
-	dateTime := make([]byte, dateSize)
-	// TODO: this can cause conflicts when time is unstable or running on multiple computers
-	// Append random 4 byte token to account for time conflicts
-	binary.BigEndian.PutUint64(dateTime, uint64(time.Now().UnixNano()))
-	const tokenSize = 4
-	token := make([]byte, tokenSize)
-	_, err := rand.Read(token)
-	if err != nil {
-		return Error.New("error creating random token %s", err)
+	return q.EnqueueWithPriority(qi, 0)
+}
+
+// EnqueueWithPriority adds a repair segment to the queue, ahead of the
+// segments with a lower priority. The segment is set to be queued now, unless
+// it's set to be queued already.
+func (q *Queue) EnqueueWithPriority(qi *pb.InjuredSegment, priority int) error {
+	if qi.Queued == nil {
+		queued, err := ptypes.TimestampProto(time.Now())
+		if err != nil {
+			return Error.Wrap(err)
+		}
+		qi.Queued = queued
 	}
-	dateTime = append(dateTime, token...)
 	val, err := proto.Marshal(qi)
 	if err != nil {
 		return Error.New("error marshalling injured seg %s", err)
 	}
-	err = q.db.Put(dateTime, val)
+	err = q.db.EnqueueWithPriority(val, priority)
 	if err != nil {
 		return Error.New("error adding injured seg to queue %s", err)
 	}
@@ -64,27 +79,81 @@ func (q *Queue) Enqueue(qi *pb.InjuredSegment) error {
 
 // Dequeue returns the next repair segement and removes it from the queue
 func (q *Queue) Dequeue() (pb.InjuredSegment, error) {
-	q.mu.Lock()
-	defer q.mu.Unlock()
-
-	items, _, err := storage.ListV2(q.db, storage.ListOptions{IncludeValue: true, Limit: 1, Recursive: true})
+	val, err := q.db.Dequeue()
 	if err != nil {
-		return pb.InjuredSegment{}, Error.New("error getting first key %s", err)
+		return pb.InjuredSegment{}, wrapQueueError(err)
 	}
-	if len(items) == 0 {
-		return pb.InjuredSegment{}, Error.New("empty database")
+	seg, err := unmarshalSegment(val)
+	if err != nil {
+		return pb.InjuredSegment{}, err
 	}
-	key := items[0].Key
-	val := items[0].Value
+	return *seg, nil
+}
 
-	seg := &pb.InjuredSegment{}
-	err = proto.Unmarshal(val, seg)
+// Claim returns the next repair segment, which isn't returned again until
+// its claim is acknowledged or released, or it times out
+func (q *Queue) Claim(timeout time.Duration) (Claim, error) {
+	claim, err := q.db.Claim(timeout)
 	if err != nil {
-		return pb.InjuredSegment{}, Error.New("error unmarshalling segment %s", err)
+		return Claim{}, wrapQueueError(err)
 	}
-	err = q.db.Delete(key)
+	seg, err := unmarshalSegment(claim.Value)
 	if err != nil {
-		return *seg, Error.New("error removing injured seg %s", err)
+		// the segment can't ever be repaired, so it's dropped
+		return Claim{}, utils.CombineErrors(err, q.db.Ack(claim))
 	}
-	return *seg, nil
+	return Claim{Segment: *seg, claim: claim}, nil
+}
+
+// Ack removes the repaired segment of claim from the queue
+func (q *Queue) Ack(claim Claim) error {
+	return wrapQueueError(q.db.Ack(claim.claim))
+}
+
+// Nack releases claim, so its segment is returned again right away
+func (q *Queue) Nack(claim Claim) error {
+	return wrapQueueError(q.db.Nack(claim.claim))
+}
+
+// Len returns the number of segments in the queue, including the claimed ones
+func (q *Queue) Len() (int, error) {
+	n, err := q.db.Len()
+	return n, Error.Wrap(err)
+}
+
+// Ping checks whether the storage of the queue can be reached, if it can be
+// checked
+func (q *Queue) Ping() error {
+	if pinger, ok := q.db.(interface{ Ping() error }); ok {
+		return pinger.Ping()
+	}
+	return nil
+}
+
+// Close closes the queue, and its dead-letter store if it has one
+func (q *Queue) Close() error {
+	if q.address != "" {
+		if q.dead != nil {
+			return utils.CombineErrors(release(q.address), q.dead.Close())
+		}
+		return release(q.address)
+	}
+	return q.db.Close()
+}
+
+func unmarshalSegment(val storage.Value) (*pb.InjuredSegment, error) {
+	seg := &pb.InjuredSegment{}
+	if err := proto.Unmarshal(val, seg); err != nil {
+		return nil, Error.New("error unmarshalling segment %s", err)
+	}
+	return seg, nil
+}
+
+// wrapQueueError wraps err, but leaves the errors telling that the queue is
+// empty or that a claim expired as they are
+func wrapQueueError(err error) error {
+	if storage.ErrEmptyQueue.Has(err) || storage.ErrClaimExpired.Has(err) {
+		return err
+	}
+	return Error.Wrap(err)
 }
@@ -4,22 +4,27 @@
 package queue
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 
 	"storj.io/storj/pkg/pb"
+	"storj.io/storj/storage"
 	"storj.io/storj/storage/redis"
 	"storj.io/storj/storage/redis/redisserver"
 	"storj.io/storj/storage/teststore"
 )
 
 func TestEnqueueDequeue(t *testing.T) {
-	db := teststore.New()
+	db := teststore.NewQueue()
 	q := NewQueue(db)
 	seg := &pb.InjuredSegment{
 		Path:       "abc",
@@ -34,7 +39,7 @@ func TestEnqueueDequeue(t *testing.T) {
 }
 
 func TestDequeueEmptyQueue(t *testing.T) {
-	db := teststore.New()
+	db := teststore.NewQueue()
 	q := NewQueue(db)
 	s, err := q.Dequeue()
 	assert.Error(t, err)
@@ -42,7 +47,7 @@ func TestDequeueEmptyQueue(t *testing.T) {
 }
 
 func TestForceError(t *testing.T) {
-	db := teststore.New()
+	db := teststore.NewQueue()
 	q := NewQueue(db)
 	err := q.Enqueue(&pb.InjuredSegment{Path: "abc", LostPieces: []int32{int32(0)}})
 	assert.NoError(t, err)
@@ -52,8 +57,85 @@ func TestForceError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestClaim(t *testing.T) {
+	q := NewQueue(teststore.NewQueue())
+	seg := &pb.InjuredSegment{Path: "abc", LostPieces: []int32{int32(1)}}
+	assert.NoError(t, q.Enqueue(seg))
+
+	claim, err := q.Claim(time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&claim.Segment, seg))
+
+	// the claimed segment is skipped until it's released
+	_, err = q.Claim(time.Hour)
+	assert.True(t, storage.ErrEmptyQueue.Has(err))
+	assert.NoError(t, q.Nack(claim))
+
+	claim, err = q.Claim(time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&claim.Segment, seg))
+
+	assert.NoError(t, q.Ack(claim))
+	assert.True(t, storage.ErrClaimExpired.Has(q.Ack(claim)))
+	_, err = q.Dequeue()
+	assert.True(t, storage.ErrEmptyQueue.Has(err))
+}
+
+func TestClaimTimeout(t *testing.T) {
+	q := NewQueue(teststore.NewQueue())
+	seg := &pb.InjuredSegment{Path: "abc", LostPieces: []int32{int32(1)}}
+	assert.NoError(t, q.Enqueue(seg))
+
+	// a segment whose repairer crashed is claimed again after the timeout
+	_, err := q.Claim(10 * time.Millisecond)
+	assert.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+
+	claim, err := q.Claim(time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&claim.Segment, seg))
+}
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repair-queue")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	address := "bolt://" + filepath.Join(dir, "queue.db")
+
+	// the queue in a bolt database is shared while it's open
+	checker, err := Open(address)
+	if !assert.NoError(t, err) {
+		return
+	}
+	repairer, err := Open(address)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	seg := &pb.InjuredSegment{Path: "abc", LostPieces: []int32{int32(1)}}
+	assert.NoError(t, checker.Enqueue(seg))
+	assert.NoError(t, checker.Close())
+	claim, err := repairer.Claim(time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&claim.Segment, seg))
+	assert.NoError(t, repairer.Close())
+
+	// the claimed segment is kept in the database
+	reopened, err := Open(address)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, reopened.Ack(claim))
+	assert.NoError(t, reopened.Close())
+
+	_, err = Open("memory://queue")
+	assert.Error(t, err)
+}
+
 func TestSequential(t *testing.T) {
-	db := teststore.New()
+	db := teststore.NewQueue()
 	q := NewQueue(db)
 	const N = 100
 	var addSegs []*pb.InjuredSegment
@@ -74,7 +156,7 @@ func TestSequential(t *testing.T) {
 }
 
 func TestParallel(t *testing.T) {
-	queue := NewQueue(teststore.New())
+	queue := NewQueue(teststore.NewQueue())
 	const N = 100
 	errs := make(chan error, N*2)
 	entries := make(chan *pb.InjuredSegment, N*2)
@@ -132,14 +214,14 @@ func BenchmarkRedisSequential(b *testing.B) {
 	addr, cleanup, err := redisserver.Start()
 	defer cleanup()
 	assert.NoError(b, err)
-	client, err := redis.NewClient(addr, "", 1)
+	db, err := redis.NewQueue("redis://"+addr, "repair")
 	assert.NoError(b, err)
-	q := NewQueue(client)
+	q := NewQueue(db)
 	benchmarkSequential(b, q)
 }
 
 func BenchmarkTeststoreSequential(b *testing.B) {
-	q := NewQueue(teststore.New())
+	q := NewQueue(teststore.NewQueue())
 	benchmarkSequential(b, q)
 }
 
@@ -169,14 +251,14 @@ func BenchmarkRedisParallel(b *testing.B) {
 	addr, cleanup, err := redisserver.Start()
 	defer cleanup()
 	assert.NoError(b, err)
-	client, err := redis.NewClient(addr, "", 1)
+	db, err := redis.NewQueue("redis://"+addr, "repair")
 	assert.NoError(b, err)
-	q := NewQueue(client)
+	q := NewQueue(db)
 	benchmarkParallel(b, q)
 }
 
 func BenchmarkTeststoreParallel(b *testing.B) {
-	q := NewQueue(teststore.New())
+	q := NewQueue(teststore.NewQueue())
 	benchmarkParallel(b, q)
 }
 
@@ -236,3 +318,37 @@ func benchmarkParallel(b *testing.B, q RepairQueue) {
 		}
 	}
 }
+
+func TestDeadLetters(t *testing.T) {
+	q := NewQueue(teststore.NewQueue()).WithDeadLetters(teststore.New(), 2)
+	defer func() { assert.NoError(t, q.Close()) }()
+
+	seg := &pb.InjuredSegment{Path: "abc", LostPieces: []int32{int32(1)}}
+	assert.NoError(t, q.Enqueue(seg))
+
+	// a segment whose repairs keep failing is moved out of the queue
+	for i := 0; i < 2; i++ {
+		_, err := q.Claim(time.Millisecond)
+		assert.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+	}
+	_, err := q.Claim(time.Hour)
+	assert.True(t, storage.ErrEmptyQueue.Has(err))
+
+	letters, err := q.DeadLetters(nil, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, letters, 1) {
+		assert.Equal(t, "abc", letters[0].Segment.GetPath())
+		assert.Equal(t, 3, letters[0].Claims)
+	}
+
+	// and repaired again once it's requeued
+	assert.NoError(t, q.Requeue(letters[0].Key))
+	letters, err = q.DeadLetters(nil, 10)
+	assert.NoError(t, err)
+	assert.Len(t, letters, 0)
+
+	claim, err := q.Claim(time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", claim.Segment.GetPath())
+}
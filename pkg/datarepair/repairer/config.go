@@ -10,8 +10,20 @@ import (
 	"go.uber.org/zap"
 
 	"storj.io/storj/pkg/datarepair/queue"
+	"storj.io/storj/pkg/eestream"
+	"storj.io/storj/pkg/overlay"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/pointerdb/pdbclient"
+	"storj.io/storj/pkg/process"
 	"storj.io/storj/pkg/provider"
-	"storj.io/storj/storage/redis"
+	"storj.io/storj/pkg/statdb/sdbclient"
+	ecclient "storj.io/storj/pkg/storage/ec"
+	"storj.io/storj/pkg/storage/segments"
+	"storj.io/storj/pkg/transport"
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/boltdb"
+	"storj.io/storj/storage/postgreskv"
 )
 
 // Config contains configurable values for repairer
@@ -19,17 +31,118 @@ type Config struct {
 	QueueAddress string        `help:"data repair queue address" default:"redis://127.0.0.1:6378?db=1&password=abc123"`
 	MaxRepair    int           `help:"maximum segments that can be repaired concurrently" default:"100"`
 	Interval     time.Duration `help:"how frequently checker should audit segments" default:"3600s"`
+	ClaimTimeout time.Duration `help:"how long a segment is claimed by its repair, before it's repaired again" default:"1h"`
+	MaxClaims    int           `help:"how many times a segment is claimed without being repaired before it's moved to the dead-letter database, 0 retries it forever" default:"10"`
+	DeadLetters  string        `help:"the database the segments which fail to be repaired are moved to, which can't be the pointer database" default:"bolt://$CONFDIR/repair-dead-letters.db"`
+
+	OverlayAddr   string `help:"address to contact the overlay server through"`
+	PointerDBAddr string `help:"address to contact the pointerdb server through"`
+	APIKey        string `help:"API key the pointers of the repaired segments are read and updated with"`
+	StatDBAddr    string `help:"address of the statdb the outcomes of the piece downloads of repairs are recorded in, they aren't recorded if empty" default:""`
+
+	MaxBufferMem   int           `help:"maximum buffer memory (in bytes) to be allocated for the read buffers of a repair" default:"0x400000"`
+	PieceTimeout   time.Duration `help:"how long a read of a piece may take before the piece is canceled and the segment is rebuilt from the other pieces, 0 disables the timeout" default:"10s"`
+	MaxUploadRate  float64       `help:"maximum number of repaired pieces whose upload is started per second, over all the nodes, 0 disables the limit" default:"0"`
+	MaxNodeUploads int           `help:"maximum number of repaired pieces uploaded to a single node at once, 0 disables the limit" default:"4"`
+
+	DailyBandwidth int64  `help:"maximum bytes downloaded and uploaded by the repairs started in a UTC day, 0 disables the budget" default:"0"`
+	Windows        string `help:"comma separated UTC times of day repairs are started in, like 22:00-06:00, repairs are started at any time if empty" default:""`
+}
+
+// OpenQueue opens the repair queue, which moves the segments claimed more
+// than MaxClaims times to the dead-letter database, unless MaxClaims is 0
+func (c Config) OpenQueue() (*queue.Queue, error) {
+	repairQueue, err := queue.Open(c.QueueAddress)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if c.MaxClaims <= 0 {
+		return repairQueue, nil
+	}
+
+	dead, err := c.openDeadLetters()
+	if err != nil {
+		return nil, utils.CombineErrors(Error.Wrap(err), repairQueue.Close())
+	}
+	return repairQueue.WithDeadLetters(dead, c.MaxClaims), nil
+}
+
+// openDeadLetters opens the dead-letter database of the repair queue
+func (c Config) openDeadLetters() (storage.KeyValueStore, error) {
+	dburl, err := utils.ParseURL(c.DeadLetters)
+	if err != nil {
+		return nil, err
+	}
+	switch dburl.Scheme {
+	case "bolt":
+		return boltdb.New(dburl.Path, "deadletters")
+	case "postgres", "postgresql":
+		return postgreskv.New(c.DeadLetters)
+	default:
+		return nil, Error.New("unsupported dead-letter db scheme: %s", dburl.Scheme)
+	}
+}
+
+// segmentStore returns the segments.Store the segments are repaired with.
+// The outcomes of the dials of the repairs are recorded in dossiers, unless
+// it's nil.
+func (c Config) segmentStore(identity *provider.FullIdentity, dossiers *overlay.DossierService) (segments.Store, error) {
+	oc, err := overlay.NewOverlayClient(identity, c.OverlayAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	pdb, err := pdbclient.NewClient(identity, c.PointerDBAddr, c.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	uploads := ecclient.NewUploadLimiter(c.MaxUploadRate, c.MaxNodeUploads)
+	var observers []transport.Observer
+	if dossiers != nil {
+		observers = append(observers, dossiers)
+	}
+	ec := ecclient.NewClient(identity, transport.NewClient(identity, observers...), c.MaxBufferMem, c.PieceTimeout, nil, 0, uploads, nil, nil)
+
+	// the segments are repaired with the redundancy they were stored with
+	return segments.NewSegmentStore(oc, ec, pdb, eestream.RedundancyStrategy{}, 0, 0), nil
 }
 
 // Run runs the repairer with configured values
 func (c Config) Run(ctx context.Context, server *provider.Provider) (err error) {
-	client, err := redis.NewClientFrom(c.QueueAddress)
+	windows, err := parseWindows(c.Windows)
+	if err != nil {
+		return err
+	}
+
+	queue, err := c.OpenQueue()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = queue.Close() }()
+	defer process.RegisterHealthCheck("repair queue", queue.Ping)()
+
+	// the node dossiers are set if the overlay runs in the same process
+	store, err := c.segmentStore(server.Identity(), overlay.LoadDossiersFromContext(ctx))
 	if err != nil {
 		return Error.Wrap(err)
 	}
 
-	queue := queue.NewQueue(client)
-	repairer := newRepairer(queue, c.Interval, c.MaxRepair)
+	var statdb sdbclient.Client
+	if c.StatDBAddr != "" {
+		statdb, err = sdbclient.NewClient(server.Identity(), c.StatDBAddr, []byte(c.APIKey))
+		if err != nil {
+			return Error.Wrap(err)
+		}
+	}
+
+	repairer := newRepairer(queue, store, statdb, c.Interval, c.MaxRepair, c.ClaimTimeout)
+	if len(windows) > 0 || c.DailyBandwidth > 0 {
+		repairer.schedule = newSchedule(windows, c.DailyBandwidth)
+	}
+
+	// segments are repaired on request through the private server
+	pb.RegisterDataRepairServer(server.PrivateGRPC(), &repairServer{repairer: repairer})
 
 	// TODO(coyle): we need to figure out how to propagate the error up to cancel the service
 	go func() {
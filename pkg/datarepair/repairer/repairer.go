@@ -7,11 +7,17 @@ import (
 	"context"
 	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	"go.uber.org/zap"
 
 	"storj.io/storj/internal/sync2"
 	"storj.io/storj/pkg/datarepair/queue"
 	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/statdb/sdbclient"
+	ecclient "storj.io/storj/pkg/storage/ec"
+	"storj.io/storj/pkg/storage/segments"
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
 )
 
 // Repairer is the interface for the data repair queue
@@ -22,16 +28,23 @@ type Repairer interface {
 
 // repairer holds important values for data repair
 type repairer struct {
-	queue   queue.RepairQueue
-	limiter *sync2.Limiter
-	ticker  *time.Ticker
+	queue        queue.RepairQueue
+	segments     segments.Store
+	statdb       sdbclient.Client
+	schedule     *schedule
+	limiter      *sync2.Limiter
+	ticker       *time.Ticker
+	claimTimeout time.Duration
 }
 
-func newRepairer(queue queue.RepairQueue, interval time.Duration, concurrency int) *repairer {
+func newRepairer(queue queue.RepairQueue, segments segments.Store, statdb sdbclient.Client, interval time.Duration, concurrency int, claimTimeout time.Duration) *repairer {
 	return &repairer{
-		queue:   queue,
-		limiter: sync2.NewLimiter(concurrency),
-		ticker:  time.NewTicker(interval),
+		queue:        queue,
+		segments:     segments,
+		statdb:       statdb,
+		limiter:      sync2.NewLimiter(concurrency),
+		ticker:       time.NewTicker(interval),
+		claimTimeout: claimTimeout,
 	}
 }
 
@@ -56,28 +69,99 @@ func (r *repairer) Run(ctx context.Context) (err error) {
 	}
 }
 
-// process picks an item from repair queue and spawns a repairer
+// process claims the items of the repair queue and repairs them, with as
+// many repairs running at once as the concurrency of the repairer, until the
+// queue is empty. An item is only removed from the queue once it's repaired,
+// so it's claimed again after the claim timeout if its repair fails or the
+// repairer stops before that. The pass ends when an item claimed in it is
+// claimed again, so a segment which fails to be repaired is retried in a
+// later pass rather than over and over in this one.
 func (r *repairer) process(ctx context.Context) error {
-	seg, err := r.queue.Dequeue()
+	depth, err := r.queue.Len()
 	if err != nil {
-		// TODO: only log when err != ErrQueueEmpty
-		return err
+		zap.L().Warn("Failed to get the length of the repair queue", zap.Error(err))
+	} else {
+		mon.IntVal("repair_queue_depth").Observe(int64(depth))
 	}
 
-	r.limiter.Go(ctx, func() {
-		err := r.Repair(ctx, &seg)
+	claimed := map[string]bool{}
+	for {
+		if !r.schedule.allows(time.Now()) {
+			// the segments are repaired once the schedule allows it again
+			return nil
+		}
+
+		claim, err := r.queue.Claim(r.claimTimeout)
 		if err != nil {
-			zap.L().Error("Repair failed", zap.Error(err))
+			if storage.ErrEmptyQueue.Has(err) {
+				return nil
+			}
+			return err
+		}
+		if claimed[claim.Segment.GetPath()] {
+			// the claim of the segment expired in this pass, so the claim is
+			// left to expire again before the segment is retried
+			return nil
+		}
+		claimed[claim.Segment.GetPath()] = true
+
+		if queued, err := ptypes.Timestamp(claim.Segment.GetQueued()); err == nil {
+			mon.IntVal("repair_time_in_queue_seconds").Observe(int64(time.Since(queued) / time.Second))
 		}
-	})
 
-	return nil
+		started := r.limiter.Go(ctx, func() {
+			r.repair(ctx, claim)
+		})
+		if !started {
+			// the repairer is canceled, so the segment is released for the
+			// next one
+			return utils.CombineErrors(ctx.Err(), r.queue.Nack(claim))
+		}
+	}
 }
 
-// Repair starts repair of the segment
+// repair repairs the segment of claim, and acks the claim if it's repaired.
+// The claim of a segment which fails to be repaired is left to expire, so
+// the segment is repaired again after the claim timeout.
+func (r *repairer) repair(ctx context.Context, claim queue.Claim) {
+	err := r.Repair(ctx, &claim.Segment)
+	if err != nil {
+		zap.L().Error("Repair failed", zap.String("path", claim.Segment.GetPath()), zap.Error(err))
+		mon.Event("repair_failed")
+		return
+	}
+
+	mon.Event("repair_succeeded")
+	if err := r.queue.Ack(claim); err != nil {
+		zap.L().Error("Failed to release claim of segment", zap.Error(err))
+	}
+}
+
+// Repair rebuilds the lost pieces of the segment and stores them on new nodes.
+// If the repairer has a statdb, the outcomes of the downloads of the pieces
+// the segment is rebuilt from are recorded in it, whether the repair succeeds
+// or not. The bytes transferred by the repair count against the budget of
+// the schedule of the repairer.
 func (r *repairer) Repair(ctx context.Context, seg *pb.InjuredSegment) (err error) {
 	defer mon.Task()(&ctx)(&err)
-	// TODO:
-	zap.L().Debug("Repairing", zap.Any("segment", seg))
+
+	lostPieces := make([]int, len(seg.GetLostPieces()))
+	for i, piece := range seg.GetLostPieces() {
+		lostPieces[i] = int(piece)
+	}
+
+	traffic := &ecclient.Traffic{}
+	ctx = ecclient.WithTraffic(ctx, traffic)
+	defer func() { r.schedule.use(time.Now(), traffic.Downloaded()+traffic.Uploaded()) }()
+
+	if r.statdb == nil {
+		return r.segments.Repair(ctx, seg.GetPath(), lostPieces)
+	}
+
+	reports := newDownloadReports()
+	err = r.segments.Repair(ecclient.WithPieceReports(ctx, reports.add), seg.GetPath(), lostPieces)
+	if recordErr := r.recordDownloads(ctx, reports); recordErr != nil {
+		zap.L().Error("Failed to record repair downloads", zap.String("path", seg.GetPath()), zap.Error(recordErr))
+	}
 	return err
 }
@@ -2,3 +2,168 @@
 // See LICENSE for copying information.
 
 package repairer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/datarepair/queue"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/storage/segments"
+	"storj.io/storj/pkg/storj"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+)
+
+// mockSegments records the repairs of segments, which block until unblocked
+// if block is set
+type mockSegments struct {
+	segments.Store
+
+	mu       sync.Mutex
+	repaired map[storj.Path][]int
+	repairs  map[storj.Path]int
+	running  int
+	max      int
+	fail     bool
+	block    chan struct{}
+}
+
+func (m *mockSegments) Repair(ctx context.Context, path storj.Path, lostPieces []int) error {
+	m.mu.Lock()
+	m.running++
+	if m.running > m.max {
+		m.max = m.running
+	}
+	m.mu.Unlock()
+
+	if m.block != nil {
+		<-m.block
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running--
+	if m.repairs != nil {
+		m.repairs[path]++
+	}
+	if m.fail {
+		return errs.New("repair failed")
+	}
+	m.repaired[path] = lostPieces
+	return nil
+}
+
+// waitRunning waits until n repairs are running
+func (m *mockSegments) waitRunning(n int) {
+	for {
+		m.mu.Lock()
+		running := m.running
+		m.mu.Unlock()
+		if running == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	ctx := context.Background()
+	q := queue.NewQueue(teststore.NewQueue())
+	store := &mockSegments{repaired: map[storj.Path][]int{}}
+	r := newRepairer(q, store, nil, time.Hour, 1, time.Hour)
+	defer r.ticker.Stop()
+
+	// an empty queue is nothing to report
+	assert.NoError(t, r.process(ctx))
+
+	assert.NoError(t, q.Enqueue(&pb.InjuredSegment{Path: "abc", LostPieces: []int32{int32(1)}}))
+	assert.NoError(t, r.process(ctx))
+	r.limiter.Wait()
+	assert.Equal(t, map[storj.Path][]int{"abc": {1}}, store.repaired)
+
+	// the repaired segment is removed from the queue
+	_, err := q.Claim(time.Hour)
+	assert.True(t, storage.ErrEmptyQueue.Has(err))
+}
+
+func TestProcessFailed(t *testing.T) {
+	ctx := context.Background()
+	q := queue.NewQueue(teststore.NewQueue())
+	store := &mockSegments{repaired: map[storj.Path][]int{}, repairs: map[storj.Path]int{}, fail: true}
+	r := newRepairer(q, store, nil, time.Hour, 2, time.Millisecond)
+	defer r.ticker.Stop()
+
+	assert.NoError(t, q.Enqueue(&pb.InjuredSegment{Path: "abc"}))
+	assert.NoError(t, q.Enqueue(&pb.InjuredSegment{Path: "def"}))
+
+	// the failed segments aren't retried in the same pass, even though their
+	// claims expire during it
+	assert.NoError(t, r.process(ctx))
+	r.limiter.Wait()
+	assert.Equal(t, map[storj.Path]int{"abc": 1, "def": 1}, store.repairs)
+
+	// a failed repair is repaired again once its claim expired
+	time.Sleep(10 * time.Millisecond)
+	claim, err := q.Claim(time.Hour)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "abc", claim.Segment.GetPath())
+	}
+}
+
+func TestProcessConcurrently(t *testing.T) {
+	ctx := context.Background()
+	q := queue.NewQueue(teststore.NewQueue())
+	store := &mockSegments{repaired: map[storj.Path][]int{}, block: make(chan struct{})}
+	r := newRepairer(q, store, nil, time.Hour, 3, time.Hour)
+	defer r.ticker.Stop()
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, q.Enqueue(&pb.InjuredSegment{Path: fmt.Sprintf("path/%d", i)}))
+	}
+
+	processed := make(chan error)
+	go func() { processed <- r.process(ctx) }()
+	store.waitRunning(3)
+	close(store.block)
+	assert.NoError(t, <-processed)
+	r.limiter.Wait()
+
+	// all the segments were repaired, with at most 3 repairs at once
+	assert.Len(t, store.repaired, 10)
+	assert.Equal(t, 3, store.max)
+	_, err := q.Claim(time.Hour)
+	assert.True(t, storage.ErrEmptyQueue.Has(err))
+}
+
+func TestProcessCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := queue.NewQueue(teststore.NewQueue())
+	store := &mockSegments{repaired: map[storj.Path][]int{}, block: make(chan struct{})}
+	r := newRepairer(q, store, nil, time.Hour, 1, time.Hour)
+	defer r.ticker.Stop()
+
+	assert.NoError(t, q.Enqueue(&pb.InjuredSegment{Path: "running"}))
+	assert.NoError(t, q.Enqueue(&pb.InjuredSegment{Path: "waiting"}))
+
+	processed := make(chan error)
+	go func() { processed <- r.process(ctx) }()
+	// the second segment waits for the first repair, until it's canceled
+	store.waitRunning(1)
+	cancel()
+	assert.Equal(t, context.Canceled, <-processed)
+	close(store.block)
+	r.limiter.Wait()
+
+	// the waiting segment is released, so it's repaired later
+	claim, err := q.Claim(time.Hour)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "waiting", claim.Segment.GetPath())
+	}
+}
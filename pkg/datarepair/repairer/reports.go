@@ -0,0 +1,92 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package repairer
+
+import (
+	"context"
+	"sync"
+
+	sdbproto "storj.io/storj/pkg/statdb/proto"
+	ecclient "storj.io/storj/pkg/storage/ec"
+)
+
+// maxReportRetries is how many times the nodes whose update failed are
+// reported to statdb again
+const maxReportRetries = 3
+
+// downloadReports collects the outcomes of the piece downloads of a repair,
+// as the updates of the stats of their nodes: nodes which served their piece
+// passed an audit, the ones which failed to, like by serving corrupted
+// erasure shares, failed it, and the ones which couldn't be dialed are down
+type downloadReports struct {
+	mu    sync.Mutex
+	nodes map[string]*sdbproto.Node
+	order []string
+}
+
+func newDownloadReports() *downloadReports {
+	return &downloadReports{nodes: map[string]*sdbproto.Node{}}
+}
+
+// add adds the outcome of a piece download. A failure of a node overrides
+// its success, and both override it being down. The uploads of the repaired
+// pieces aren't added.
+func (reports *downloadReports) add(report ecclient.PieceReport) {
+	if report.Upload {
+		return
+	}
+	reports.mu.Lock()
+	defer reports.mu.Unlock()
+
+	node, ok := reports.nodes[report.NodeID]
+	if !ok {
+		node = &sdbproto.Node{NodeId: []byte(report.NodeID)}
+		reports.nodes[report.NodeID] = node
+		reports.order = append(reports.order, report.NodeID)
+	}
+
+	if report.Offline {
+		if !node.UpdateAuditSuccess {
+			node.IsUp = false
+			node.UpdateUptime = true
+		}
+		return
+	}
+	node.IsUp = true
+	node.UpdateUptime = true
+	if !node.UpdateAuditSuccess || report.Err != nil {
+		node.AuditSuccess = report.Err == nil
+	}
+	node.UpdateAuditSuccess = true
+}
+
+// statNodes returns the updates of the stats of the nodes, in the order the
+// nodes were first reported
+func (reports *downloadReports) statNodes() []*sdbproto.Node {
+	reports.mu.Lock()
+	defer reports.mu.Unlock()
+
+	nodes := make([]*sdbproto.Node, 0, len(reports.order))
+	for _, id := range reports.order {
+		nodes = append(nodes, reports.nodes[id])
+	}
+	return nodes
+}
+
+// recordDownloads updates the stats of the nodes of reports in statdb
+func (r *repairer) recordDownloads(ctx context.Context, reports *downloadReports) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	nodes := reports.statNodes()
+	for retries := 0; len(nodes) > 0; retries++ {
+		if retries == maxReportRetries {
+			return Error.New("failed to update %d nodes in statdb", len(nodes))
+		}
+		_, nodes, err = r.statdb.UpdateBatch(ctx, nodes)
+		if err != nil {
+			return Error.Wrap(err)
+		}
+	}
+	return nil
+}
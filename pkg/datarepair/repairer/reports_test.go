@@ -0,0 +1,79 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package repairer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/datarepair/queue"
+	sdbproto "storj.io/storj/pkg/statdb/proto"
+	"storj.io/storj/pkg/statdb/sdbclient"
+	ecclient "storj.io/storj/pkg/storage/ec"
+	"storj.io/storj/storage/teststore"
+)
+
+// mockStatDB records the nodes updated in a batch, failing the updates of
+// the first fail nodes
+type mockStatDB struct {
+	sdbclient.Client
+	updated []*sdbproto.Node
+	fail    int
+}
+
+func (m *mockStatDB) UpdateBatch(ctx context.Context, nodes []*sdbproto.Node) ([]*sdbproto.NodeStats, []*sdbproto.Node, error) {
+	var failed []*sdbproto.Node
+	for _, node := range nodes {
+		if m.fail > 0 {
+			m.fail--
+			failed = append(failed, node)
+			continue
+		}
+		m.updated = append(m.updated, node)
+	}
+	return nil, failed, nil
+}
+
+func TestDownloadReports(t *testing.T) {
+	reports := newDownloadReports()
+	reports.add(ecclient.PieceReport{NodeID: "down", Offline: true})
+	reports.add(ecclient.PieceReport{NodeID: "failed"})
+	reports.add(ecclient.PieceReport{NodeID: "failed", Err: errs.New("corrupted")})
+	reports.add(ecclient.PieceReport{NodeID: "failed"})
+	reports.add(ecclient.PieceReport{NodeID: "served", Offline: true})
+	reports.add(ecclient.PieceReport{NodeID: "served"})
+	reports.add(ecclient.PieceReport{NodeID: "served", Offline: true})
+	reports.add(ecclient.PieceReport{NodeID: "uploaded", Upload: true})
+
+	assert.Equal(t, []*sdbproto.Node{
+		{NodeId: []byte("down"), IsUp: false, UpdateUptime: true},
+		{NodeId: []byte("failed"), IsUp: true, UpdateUptime: true, AuditSuccess: false, UpdateAuditSuccess: true},
+		{NodeId: []byte("served"), IsUp: true, UpdateUptime: true, AuditSuccess: true, UpdateAuditSuccess: true},
+	}, reports.statNodes())
+}
+
+func TestRecordDownloads(t *testing.T) {
+	ctx := context.Background()
+	reports := newDownloadReports()
+	for _, id := range []string{"a", "b", "c"} {
+		reports.add(ecclient.PieceReport{NodeID: id})
+	}
+
+	// the nodes whose update failed are updated again
+	statdb := &mockStatDB{fail: 2}
+	r := newRepairer(queue.NewQueue(teststore.NewQueue()), nil, statdb, time.Hour, 1, time.Hour)
+	defer r.ticker.Stop()
+	assert.NoError(t, r.recordDownloads(ctx, reports))
+	assert.Len(t, statdb.updated, 3)
+
+	// until they're retried too many times
+	statdb = &mockStatDB{fail: 3 * maxReportRetries}
+	r.statdb = statdb
+	assert.Error(t, r.recordDownloads(ctx, reports))
+	assert.Empty(t, statdb.updated)
+}
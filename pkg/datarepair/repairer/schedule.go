@@ -0,0 +1,128 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package repairer
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// window is a time of day repairs are started in, as the offsets from
+// midnight it starts and ends at. Windows which end before they start span
+// midnight.
+type window struct {
+	start, end time.Duration
+}
+
+// parseWindows parses comma separated windows like 22:00-06:00
+func parseWindows(s string) (windows []window, err error) {
+	for _, w := range strings.Split(s, ",") {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+		bounds := strings.Split(w, "-")
+		if len(bounds) != 2 {
+			return nil, Error.New("invalid repair window %q", w)
+		}
+		start, err := parseTimeOfDay(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseTimeOfDay(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window{start: start, end: end})
+	}
+	return windows, nil
+}
+
+// parseTimeOfDay parses a time of day like 06:30 as the offset from midnight
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, Error.New("invalid time of day %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// contains returns whether the time of day of t is in w
+func (w window) contains(t time.Time) bool {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+	if w.start <= w.end {
+		return w.start <= offset && offset < w.end
+	}
+	return offset >= w.start || offset < w.end
+}
+
+// schedule bounds when repairs are started: only in its windows, if it has
+// any, and only while the bytes downloaded and uploaded by the repairs of the
+// day are below its daily budget, if it has one. The repairs running when
+// the budget is used up are finished, so the budget may be exceeded by them.
+// The days are UTC days, and the bytes of a day are only kept by the
+// process.
+type schedule struct {
+	windows []window
+	budget  int64
+
+	mu   sync.Mutex
+	day  time.Time
+	used int64
+}
+
+// newSchedule returns a schedule with the given windows and daily budget,
+// where an empty windows or a 0 budget doesn't bound the repairs
+func newSchedule(windows []window, budget int64) *schedule {
+	return &schedule{windows: windows, budget: budget}
+}
+
+// allows returns whether repairs may be started at now
+func (s *schedule) allows(now time.Time) bool {
+	if s == nil {
+		return true
+	}
+	now = now.UTC()
+
+	inWindow := len(s.windows) == 0
+	for _, w := range s.windows {
+		if w.contains(now) {
+			inWindow = true
+			break
+		}
+	}
+	if !inWindow {
+		return false
+	}
+
+	if s.budget <= 0 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollover(now)
+	return s.used < s.budget
+}
+
+// use adds the bytes transferred by a repair at now to the ones of the day
+func (s *schedule) use(now time.Time, bytes int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollover(now.UTC())
+	s.used += bytes
+}
+
+// rollover starts a new day if now is after the day of s
+func (s *schedule) rollover(now time.Time) {
+	day := now.Truncate(24 * time.Hour)
+	if !day.Equal(s.day) {
+		s.day = day
+		s.used = 0
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package repairer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWindows(t *testing.T) {
+	windows, err := parseWindows("01:00-06:30, 22:00-02:00")
+	assert.NoError(t, err)
+	assert.Equal(t, []window{
+		{start: time.Hour, end: 6*time.Hour + 30*time.Minute},
+		{start: 22 * time.Hour, end: 2 * time.Hour},
+	}, windows)
+
+	windows, err = parseWindows("")
+	assert.NoError(t, err)
+	assert.Empty(t, windows)
+
+	for _, invalid := range []string{"01:00", "01:00-", "1-2", "25:00-26:00"} {
+		_, err = parseWindows(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestScheduleWindows(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2018, 11, 1, hour, min, 0, 0, time.UTC)
+	}
+	s := newSchedule([]window{
+		{start: time.Hour, end: 3 * time.Hour},
+		{start: 22 * time.Hour, end: 30 * time.Minute},
+	}, 0)
+
+	for _, tt := range []struct {
+		at      time.Time
+		allowed bool
+	}{
+		{at(0, 0), true},
+		{at(0, 30), false},
+		{at(1, 0), true},
+		{at(2, 59), true},
+		{at(3, 0), false},
+		{at(21, 59), false},
+		{at(23, 0), true},
+	} {
+		assert.Equal(t, tt.allowed, s.allows(tt.at), tt.at.String())
+	}
+
+	// the windows are in UTC
+	assert.True(t, s.allows(at(2, 0).In(time.FixedZone("", 5*60*60))))
+
+	// repairs are always allowed without a schedule
+	assert.True(t, (*schedule)(nil).allows(at(12, 0)))
+}
+
+func TestScheduleBudget(t *testing.T) {
+	day := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
+	s := newSchedule(nil, 100)
+
+	assert.True(t, s.allows(day.Add(time.Hour)))
+	s.use(day.Add(time.Hour), 60)
+	assert.True(t, s.allows(day.Add(2*time.Hour)))
+	s.use(day.Add(2*time.Hour), 60)
+	assert.False(t, s.allows(day.Add(3*time.Hour)))
+
+	// the budget is renewed every day
+	assert.True(t, s.allows(day.Add(25*time.Hour)))
+}
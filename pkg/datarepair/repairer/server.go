@@ -0,0 +1,44 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package repairer
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"storj.io/storj/pkg/pb"
+)
+
+// repairServer repairs segments on request, so the operator, or the audit
+// service when it finds pieces to be damaged, doesn't have to wait for the
+// checker to find them. The requested repairs are neither claimed from the queue nor
+// bound by the schedule of the repairer, but they count against its budget.
+type repairServer struct {
+	repairer *repairer
+}
+
+// RepairSegment repairs the segment at the path of req, rebuilding the lost
+// pieces of req and the ones on nodes which aren't in the overlay, and
+// returns once it's repaired
+func (s *repairServer) RepairSegment(ctx context.Context, req *pb.RepairSegmentRequest) (resp *pb.RepairSegmentResponse, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if req.GetPath() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "missing path")
+	}
+
+	zap.L().Info("Repair requested", zap.String("path", req.GetPath()), zap.Int32s("lost pieces", req.GetLostPieces()))
+	mon.Event("repair_requested")
+
+	err = s.repairer.Repair(ctx, &pb.InjuredSegment{Path: req.GetPath(), LostPieces: req.GetLostPieces()})
+	if err != nil {
+		mon.Event("repair_failed")
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	mon.Event("repair_succeeded")
+	return &pb.RepairSegmentResponse{}, nil
+}
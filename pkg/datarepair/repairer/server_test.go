@@ -0,0 +1,41 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package repairer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"storj.io/storj/pkg/datarepair/queue"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/storj"
+	"storj.io/storj/storage/teststore"
+)
+
+func TestRepairSegment(t *testing.T) {
+	ctx := context.Background()
+	store := &mockSegments{repaired: map[storj.Path][]int{}}
+	r := newRepairer(queue.NewQueue(teststore.NewQueue()), store, nil, time.Hour, 1, time.Hour)
+	defer r.ticker.Stop()
+	s := &repairServer{repairer: r}
+
+	_, err := s.RepairSegment(ctx, &pb.RepairSegmentRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the segment is repaired right away, whatever the schedule
+	r.schedule = newSchedule(nil, 1)
+	r.schedule.use(time.Now(), 1)
+	_, err = s.RepairSegment(ctx, &pb.RepairSegmentRequest{Path: "a", LostPieces: []int32{1, 3}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[storj.Path][]int{"a": {1, 3}}, store.repaired)
+
+	store.fail = true
+	_, err = s.RepairSegment(ctx, &pb.RepairSegmentRequest{Path: "b"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
@@ -40,6 +40,10 @@ type RoutingTable interface {
 
 	ConnectionSuccess(node *pb.Node) error
 	ConnectionFailed(node *pb.Node) error
+	// ConnectionAway records that node announced it is offline until the
+	// given time, IsAway reports whether it still is
+	ConnectionAway(node *pb.Node, until time.Time) error
+	IsAway(id string) bool
 
 	// these are for refreshing
 	SetBucketTimestamp(id string, now time.Time) error
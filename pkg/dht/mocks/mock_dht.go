@@ -152,6 +152,18 @@ func (mr *MockRoutingTableMockRecorder) CacheSize() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheSize", reflect.TypeOf((*MockRoutingTable)(nil).CacheSize))
 }
 
+// ConnectionAway mocks base method
+func (m *MockRoutingTable) ConnectionAway(arg0 *pb.Node, arg1 time.Time) error {
+	ret := m.ctrl.Call(m, "ConnectionAway", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConnectionAway indicates an expected call of ConnectionAway
+func (mr *MockRoutingTableMockRecorder) ConnectionAway(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionAway", reflect.TypeOf((*MockRoutingTable)(nil).ConnectionAway), arg0, arg1)
+}
+
 // ConnectionFailed mocks base method
 func (m *MockRoutingTable) ConnectionFailed(arg0 *pb.Node) error {
 	ret := m.ctrl.Call(m, "ConnectionFailed", arg0)
@@ -228,6 +240,18 @@ func (mr *MockRoutingTableMockRecorder) GetBuckets() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBuckets", reflect.TypeOf((*MockRoutingTable)(nil).GetBuckets))
 }
 
+// IsAway mocks base method
+func (m *MockRoutingTable) IsAway(arg0 string) bool {
+	ret := m.ctrl.Call(m, "IsAway", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsAway indicates an expected call of IsAway
+func (mr *MockRoutingTableMockRecorder) IsAway(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAway", reflect.TypeOf((*MockRoutingTable)(nil).IsAway), arg0)
+}
+
 // K mocks base method
 func (m *MockRoutingTable) K() int {
 	ret := m.ctrl.Call(m, "K")
@@ -0,0 +1,129 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package eestream
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBudget limits the memory of the buffers of all the encoders and
+// decoders sharing it. Each encoder and decoder reserves the memory of its
+// buffers when it starts, and waits while the budget is exhausted, so
+// concurrent uploads and downloads are held back instead of allocating more.
+type MemoryBudget struct {
+	mu       sync.Mutex
+	size     int64
+	reserved int64
+	released chan struct{} // closed when memory is released
+}
+
+// NewMemoryBudget returns a MemoryBudget of size bytes
+func NewMemoryBudget(size int64) (*MemoryBudget, error) {
+	if size <= 0 {
+		return nil, Error.New("invalid memory budget size %d", size)
+	}
+	return &MemoryBudget{size: size, released: make(chan struct{})}, nil
+}
+
+// Reserved returns the number of bytes reserved from the budget
+func (b *MemoryBudget) Reserved() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.reserved
+}
+
+// reserve waits until n bytes of the budget are available and reserves them.
+// A reservation larger than the whole budget waits until nothing else is
+// reserved, so it isn't held back forever.
+func (b *MemoryBudget) reserve(ctx context.Context, n int64) error {
+	if b == nil {
+		return nil
+	}
+	for {
+		b.mu.Lock()
+		if b.reserved == 0 || b.reserved+n <= b.size {
+			b.reserved += n
+			b.mu.Unlock()
+			return nil
+		}
+		released := b.released
+		b.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release releases n reserved bytes of the budget
+func (b *MemoryBudget) release(n int64) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reserved -= n
+	close(b.released)
+	b.released = make(chan struct{})
+}
+
+// TryReserve reserves n bytes of the budget if they're available, without
+// waiting, and returns whether it did. Unlike the reservations of encoders
+// and decoders, it fails for more bytes than are left even if nothing is
+// reserved.
+func (b *MemoryBudget) TryReserve(n int64) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.reserved+n > b.size {
+		return false
+	}
+	b.reserved += n
+	return true
+}
+
+// Release releases n bytes reserved with TryReserve
+func (b *MemoryBudget) Release(n int64) {
+	b.release(n)
+}
+
+type memoryBudgetCtxKey struct{}
+
+// WithMemoryBudget returns a context whose encoders and decoders reserve the
+// memory of their buffers from budget
+func WithMemoryBudget(ctx context.Context, budget *MemoryBudget) context.Context {
+	return context.WithValue(ctx, memoryBudgetCtxKey{}, budget)
+}
+
+// memoryBudget returns the MemoryBudget of ctx, if any
+func memoryBudget(ctx context.Context) *MemoryBudget {
+	budget, _ := ctx.Value(memoryBudgetCtxKey{}).(*MemoryBudget)
+	return budget
+}
+
+// bufferPools are the pools of the buffers of erasure shares, by their size
+var bufferPools sync.Map
+
+// getBuffer returns a buffer of size bytes from the pool of its size
+func getBuffer(size int) []byte {
+	if pool, ok := bufferPools.Load(size); ok {
+		if buf, ok := pool.(*sync.Pool).Get().([]byte); ok {
+			return buf
+		}
+	}
+	return make([]byte, size)
+}
+
+// putBuffer returns buf to the pool of its size to be reused
+func putBuffer(buf []byte) {
+	buf = buf[:cap(buf)]
+	pool, _ := bufferPools.LoadOrStore(len(buf), &sync.Pool{})
+	// the slice header is allocated, but the buffer is reused
+	pool.(*sync.Pool).Put(buf) //nolint
+}
@@ -0,0 +1,123 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package eestream
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vivint/infectious"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewMemoryBudget(0)
+	assert.EqualError(t, err, "eestream error: invalid memory budget size 0")
+
+	budget, err := NewMemoryBudget(100)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, budget.reserve(ctx, 60))
+	assert.NoError(t, budget.reserve(ctx, 40))
+	assert.Equal(t, int64(100), budget.Reserved())
+
+	// an exhausted budget holds reservations back until memory is released
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, budget.reserve(timeoutCtx, 10))
+
+	reserved := make(chan error)
+	go func() { reserved <- budget.reserve(ctx, 50) }()
+	select {
+	case err := <-reserved:
+		t.Fatalf("reserved from an exhausted budget: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	budget.release(60)
+	assert.NoError(t, <-reserved)
+	assert.Equal(t, int64(90), budget.Reserved())
+
+	// a reservation larger than the budget waits until nothing is reserved
+	go func() { reserved <- budget.reserve(ctx, 200) }()
+	budget.release(40)
+	budget.release(50)
+	assert.NoError(t, <-reserved)
+	assert.Equal(t, int64(200), budget.Reserved())
+	budget.release(200)
+
+	// reservations which don't wait fail unless the bytes are left
+	assert.True(t, budget.TryReserve(70))
+	assert.False(t, budget.TryReserve(40))
+	assert.True(t, budget.TryReserve(30))
+	budget.Release(100)
+	assert.False(t, budget.TryReserve(200))
+	assert.Equal(t, int64(0), budget.Reserved())
+
+	// without a budget, nothing is reserved
+	var none *MemoryBudget
+	assert.NoError(t, none.reserve(ctx, 10))
+	none.release(10)
+	assert.True(t, none.TryReserve(10))
+	none.Release(10)
+}
+
+func TestMemoryBudgetEncodeDecode(t *testing.T) {
+	budget, err := NewMemoryBudget(1 << 20)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx, cancel := context.WithCancel(WithMemoryBudget(context.Background(), budget))
+
+	data := randData(32 * 1024)
+	fc, err := infectious.NewFEC(2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := NewRedundancyStrategy(NewRSScheme(fc, 1024), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readers, err := EncodeReader(ctx, bytes.NewReader(data), rs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(rs.StripeSize()+4*1024), budget.Reserved())
+	pieces, err := readAll(readers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readerMap := make(map[int]io.ReadCloser, len(pieces))
+	for i, piece := range pieces {
+		readerMap[i] = ioutil.NopCloser(bytes.NewReader(piece))
+	}
+	decoder := DecodeReaders(ctx, readerMap, rs, int64(len(data)), 0)
+	data2, err := ioutil.ReadAll(decoder)
+	assert.NoError(t, err)
+	assert.Equal(t, data, data2)
+	assert.NoError(t, decoder.Close())
+
+	// the encoder releases its memory once its context is canceled
+	cancel()
+	for start := time.Now(); budget.Reserved() != 0; time.Sleep(time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatalf("memory not released: %d bytes reserved", budget.Reserved())
+		}
+	}
+}
+
+func TestBufferPool(t *testing.T) {
+	buf := getBuffer(123)
+	assert.Len(t, buf, 123)
+	putBuffer(buf[:10])
+	assert.Len(t, getBuffer(123), 123)
+}
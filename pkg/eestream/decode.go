@@ -8,6 +8,8 @@ import (
 	"io"
 	"io/ioutil"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"storj.io/storj/internal/readcloser"
 	"storj.io/storj/pkg/encryption"
@@ -27,6 +29,8 @@ type decodedReader struct {
 	expectedStripes int64
 	close           sync.Once
 	closeErr        error
+	budget          *MemoryBudget
+	reserved        int64
 }
 
 // DecodeReaders takes a map of readers and an ErasureScheme returning a
@@ -36,6 +40,9 @@ type decodedReader struct {
 // expectedSize is the number of bytes expected to be returned by the Reader.
 // mbm is the maximum memory (in bytes) to be allocated for read buffers. If
 // set to 0, the minimum possible memory will be used.
+//
+// If ctx has a MemoryBudget, the memory of the buffers is reserved from it,
+// waiting while it's exhausted, and released when the Reader is closed.
 func DecodeReaders(ctx context.Context, rs map[int]io.ReadCloser,
 	es ErasureScheme, expectedSize int64, mbm int) io.ReadCloser {
 	if expectedSize < 0 {
@@ -49,12 +56,22 @@ func DecodeReaders(ctx context.Context, rs map[int]io.ReadCloser,
 	if err := checkMBM(mbm); err != nil {
 		return readcloser.FatalReadCloser(err)
 	}
+	budget := memoryBudget(ctx)
+	reserved := int64(es.StripeSize() + len(rs)*(es.ErasureShareSize()+pieceBufferSize(len(rs), es, mbm)))
+	if err := budget.reserve(ctx, reserved); err != nil {
+		for _, r := range rs {
+			_ = r.Close()
+		}
+		return readcloser.FatalReadCloser(Error.Wrap(err))
+	}
 	dr := &decodedReader{
 		readers:         rs,
 		scheme:          es,
 		stripeReader:    NewStripeReader(rs, es, mbm),
 		outbuf:          make([]byte, 0, es.StripeSize()),
 		expectedStripes: expectedSize / int64(es.StripeSize()),
+		budget:          budget,
+		reserved:        reserved,
 	}
 	dr.ctx, dr.cancel = context.WithCancel(ctx)
 	// Kick off a goroutine to watch for context cancelation.
@@ -113,15 +130,17 @@ func (dr *decodedReader) Close() error {
 			errs = append(errs, err)
 		}
 		dr.closeErr = utils.CombineErrors(errs...)
+		dr.budget.release(dr.reserved)
 	})
 	return dr.closeErr
 }
 
 type decodedRanger struct {
-	es     ErasureScheme
-	rrs    map[int]ranger.Ranger
-	inSize int64
-	mbm    int // max buffer memory
+	es           ErasureScheme
+	rrs          map[int]ranger.Ranger
+	inSize       int64
+	mbm          int // max buffer memory
+	pieceTimeout time.Duration
 }
 
 // Decode takes a map of Rangers and an ErasureScheme and returns a combined
@@ -130,7 +149,13 @@ type decodedRanger struct {
 // rrs is a map of erasure piece numbers to erasure piece rangers.
 // mbm is the maximum memory (in bytes) to be allocated for read buffers. If
 // set to 0, the minimum possible memory will be used.
-func Decode(rrs map[int]ranger.Ranger, es ErasureScheme, mbm int) (ranger.Ranger, error) {
+// pieceTimeout is how long a read of a piece may take before the piece is
+// canceled and the data is decoded from the other pieces. If set to 0, the
+// pieces never time out.
+//
+// All pieces are requested in parallel, and each stripe is decoded from the
+// pieces which deliver its erasure shares first.
+func Decode(rrs map[int]ranger.Ranger, es ErasureScheme, mbm int, pieceTimeout time.Duration) (ranger.Ranger, error) {
 	if err := checkMBM(mbm); err != nil {
 		return nil, err
 	}
@@ -156,11 +181,15 @@ func Decode(rrs map[int]ranger.Ranger, es ErasureScheme, mbm int) (ranger.Ranger
 			"range reader size (%d) must be a multiple of erasure encoder block size (%d)",
 			size, es.ErasureShareSize())
 	}
+	if pieceTimeout < 0 {
+		return nil, Error.New("negative piece timeout")
+	}
 	return &decodedRanger{
-		es:     es,
-		rrs:    rrs,
-		inSize: size,
-		mbm:    mbm,
+		es:           es,
+		rrs:          rrs,
+		inSize:       size,
+		mbm:          mbm,
+		pieceTimeout: pieceTimeout,
 	}, nil
 }
 
@@ -174,30 +203,14 @@ func (dr *decodedRanger) Range(ctx context.Context, offset, length int64) (io.Re
 	// blocks contain this request
 	firstBlock, blockCount := encryption.CalcEncompassingBlocks(offset, length, dr.es.StripeSize())
 	// go ask for ranges for all those block boundaries
-	// do it parallel to save from network latency
+	// do it parallel to save from network latency, and without waiting for
+	// the slowest pieces
 	readers := make(map[int]io.ReadCloser, len(dr.rrs))
-	type indexReadCloser struct {
-		i   int
-		r   io.ReadCloser
-		err error
-	}
-	result := make(chan indexReadCloser, len(dr.rrs))
 	for i, rr := range dr.rrs {
-		go func(i int, rr ranger.Ranger) {
-			r, err := rr.Range(ctx,
-				firstBlock*int64(dr.es.ErasureShareSize()),
-				blockCount*int64(dr.es.ErasureShareSize()))
-			result <- indexReadCloser{i: i, r: r, err: err}
-		}(i, rr)
-	}
-	// wait for all goroutines to finish and save result in readers map
-	for range dr.rrs {
-		res := <-result
-		if res.err != nil {
-			readers[res.i] = readcloser.FatalReadCloser(res.err)
-		} else {
-			readers[res.i] = res.r
-		}
+		readers[i] = newPieceReader(ctx, rr,
+			firstBlock*int64(dr.es.ErasureShareSize()),
+			blockCount*int64(dr.es.ErasureShareSize()),
+			dr.pieceTimeout)
 	}
 	// decode from all those ranges
 	r := DecodeReaders(ctx, readers, dr.es, blockCount*int64(dr.es.StripeSize()), dr.mbm)
@@ -210,3 +223,74 @@ func (dr *decodedRanger) Range(ctx context.Context, offset, length int64) (io.Re
 	// length might not have included all of the blocks, limit what we return
 	return readcloser.LimitReadCloser(r, length), nil
 }
+
+// pieceReader reads a range of an erasure piece, which is requested in the
+// background, so the pieces which respond first are read without waiting
+// for the others.
+type pieceReader struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	timeout  time.Duration
+	timedOut int32
+	ready    chan struct{}
+	r        io.ReadCloser
+	err      error
+}
+
+// newPieceReader requests the range of rr at offset and length. The piece is
+// canceled if a read doesn't return within timeout, unless it's 0.
+func newPieceReader(ctx context.Context, rr ranger.Ranger, offset, length int64, timeout time.Duration) *pieceReader {
+	pr := &pieceReader{
+		timeout: timeout,
+		ready:   make(chan struct{}),
+	}
+	pr.ctx, pr.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(pr.ready)
+		pr.r, pr.err = rr.Range(pr.ctx, offset, length)
+	}()
+	return pr
+}
+
+func (pr *pieceReader) Read(p []byte) (n int, err error) {
+	if pr.timeout > 0 {
+		timer := time.AfterFunc(pr.timeout, func() {
+			atomic.StoreInt32(&pr.timedOut, 1)
+			pr.cancel()
+		})
+		defer timer.Stop()
+	}
+
+	select {
+	case <-pr.ready:
+	case <-pr.ctx.Done():
+		return 0, pr.ctxErr()
+	}
+	if pr.err != nil {
+		return 0, pr.err
+	}
+
+	n, err = pr.r.Read(p)
+	if err != nil && err != io.EOF && pr.ctx.Err() != nil {
+		return n, pr.ctxErr()
+	}
+	return n, err
+}
+
+// ctxErr returns the error of reading a piece which was canceled
+func (pr *pieceReader) ctxErr() error {
+	if atomic.LoadInt32(&pr.timedOut) != 0 {
+		return Error.New("piece timed out after %v", pr.timeout)
+	}
+	return pr.ctx.Err()
+}
+
+// Close cancels the piece, if it's still read, and closes it
+func (pr *pieceReader) Close() error {
+	pr.cancel()
+	<-pr.ready
+	if pr.err != nil {
+		return nil
+	}
+	return pr.r.Close()
+}
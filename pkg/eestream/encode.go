@@ -21,6 +21,10 @@ type ErasureScheme interface {
 	// Encode will take 'in' and call 'out' with erasure coded pieces.
 	Encode(in []byte, out func(num int, data []byte)) error
 
+	// EncodeSingle will take 'in' and write only the erasure coded piece num
+	// to 'out'.
+	EncodeSingle(in, out []byte, num int) error
+
 	// Decode will take a mapping of available erasure coded piece num -> data,
 	// 'in', and append the combined data to 'out', returning it.
 	Decode(out []byte, in map[int][]byte) ([]byte, error)
@@ -125,10 +129,23 @@ type block struct {
 // When the repair threshold is reached a timer will be started with another
 // 1.5x the amount of time that took so far. The Readers will be aborted as
 // soon as the timer expires or the optimal threshold is reached.
+//
+// If ctx has a MemoryBudget, the memory of the buffers is reserved from it,
+// waiting while it's exhausted, and released once the Readers are aborted or
+// ctx is canceled.
 func EncodeReader(ctx context.Context, r io.Reader, rs RedundancyStrategy, mbm int) ([]io.Reader, error) {
 	if err := checkMBM(mbm); err != nil {
 		return nil, err
 	}
+	chanSize := mbm / (rs.TotalCount() * rs.ErasureShareSize())
+	if chanSize < 1 {
+		chanSize = 1
+	}
+	budget := memoryBudget(ctx)
+	reserved := int64(rs.StripeSize() + chanSize*rs.TotalCount()*rs.ErasureShareSize())
+	if err := budget.reserve(ctx, reserved); err != nil {
+		return nil, Error.Wrap(err)
+	}
 	er := &encodedReader{
 		r:     r,
 		rs:    rs,
@@ -145,13 +162,13 @@ func EncodeReader(ctx context.Context, r io.Reader, rs RedundancyStrategy, mbm i
 		er.eps[i].ctx, er.eps[i].cancel = context.WithCancel(er.ctx)
 		readers = append(readers, er.eps[i])
 	}
-	chanSize := mbm / (rs.TotalCount() * rs.ErasureShareSize())
-	if chanSize < 1 {
-		chanSize = 1
-	}
 	for i := 0; i < rs.TotalCount(); i++ {
 		er.eps[i].ch = make(chan block, chanSize)
 	}
+	go func() {
+		<-er.ctx.Done()
+		budget.release(reserved)
+	}()
 	go er.fillBuffer()
 	return readers, nil
 }
@@ -181,7 +198,7 @@ func (er *encodedReader) fillBuffer() {
 			b := block{
 				i:    num,
 				num:  blockNum,
-				data: make([]byte, len(data)),
+				data: getBuffer(len(data)),
 			}
 			// data is reused by infecious, so add a copy to the channel
 			copy(b.data, data)
@@ -286,6 +303,7 @@ type encodedPiece struct {
 	er     *encodedReader
 	ch     chan block
 	closed bool
+	block  []byte // pooled buffer of outbuf
 	outbuf []byte
 	err    error
 }
@@ -309,6 +327,7 @@ func (ep *encodedPiece) Read(p []byte) (n int, err error) {
 				}
 				return 0, ep.err
 			}
+			ep.block = b.data
 			ep.outbuf = b.data
 		case <-ep.ctx.Done():
 			// context was canceled due to:
@@ -325,6 +344,11 @@ func (ep *encodedPiece) Read(p []byte) (n int, err error) {
 	copy(ep.outbuf, ep.outbuf[n:])
 	// and shrink the buffer
 	ep.outbuf = ep.outbuf[:len(ep.outbuf)-n]
+	if len(ep.outbuf) == 0 {
+		// the block is read, so its buffer can be reused
+		putBuffer(ep.block)
+		ep.block = nil
+	}
 	return n, nil
 }
 
@@ -9,6 +9,7 @@ import (
 	"encoding/binary"
 	"io"
 	"io/ioutil"
+	"math/bits"
 
 	"storj.io/storj/internal/readcloser"
 	"storj.io/storj/pkg/ranger"
@@ -25,11 +26,45 @@ func makePadding(dataLen int64, blockSize int) []byte {
 	if r > 0 {
 		padding += blockSize - int(r)
 	}
+	return paddingBytes(padding)
+}
+
+// paddingBytes returns padding bytes of the given amount, which end with the
+// amount
+func paddingBytes(padding int) []byte {
 	paddingBytes := bytes.Repeat([]byte{0}, padding)
 	binary.BigEndian.PutUint32(paddingBytes[padding-uint32Size:], uint32(padding))
 	return paddingBytes
 }
 
+// roundUp rounds size up to a multiple of blockSize
+func roundUp(size int64, blockSize int) int64 {
+	if r := size % int64(blockSize); r > 0 {
+		size += int64(blockSize) - r
+	}
+	return size
+}
+
+// padmeSize rounds size up with the Padmé scheme, so only O(log log size)
+// bits of it are left, for an overhead of at most 12%
+func padmeSize(size int64) int64 {
+	e := bits.Len64(uint64(size)) - 1
+	if e < 1 {
+		return size
+	}
+	s := bits.Len64(uint64(e))
+	mask := int64(1)<<uint(e-s) - 1
+	return (size + mask) &^ mask
+}
+
+func makeSizeHidingPadding(dataLen int64, blockSize int, maxDataLen int64) []byte {
+	size := roundUp(padmeSize(dataLen+uint32Size), blockSize)
+	if maxSize := roundUp(maxDataLen+uint32Size, blockSize); size > maxSize && dataLen <= maxDataLen {
+		size = maxSize
+	}
+	return paddingBytes(int(size - dataLen))
+}
+
 // Pad takes a Ranger and returns another Ranger that is a multiple of
 // blockSize in length. The return value padding is a convenience to report how
 // much padding was added.
@@ -39,6 +74,12 @@ func Pad(data ranger.Ranger, blockSize int) (
 	return ranger.Concat(data, ranger.ByteRanger(paddingBytes)), len(paddingBytes)
 }
 
+// PaddedSize returns the size of dataLen bytes once they're padded by Pad or
+// PadReader to a multiple of blockSize
+func PaddedSize(dataLen int64, blockSize int) int64 {
+	return roundUp(dataLen+uint32Size, blockSize)
+}
+
 // Unpad takes a previously padded Ranger data source and returns an unpadded
 // ranger, given the amount of padding. This is preferable to UnpadSlow if you
 // can swing it.
@@ -70,6 +111,21 @@ func PadReader(data io.ReadCloser, blockSize int) io.ReadCloser {
 		}))
 }
 
+// PadReaderHidingSize is like PadReader, but the data is padded to a size
+// which hides its own size: the data size is rounded up with the Padmé
+// scheme, which leaves only O(log log size) bits of it, and then to a
+// multiple of blockSize. The data isn't padded beyond the padded size of
+// maxDataLen bytes, so data of the maximum size, like a full segment, is
+// padded just like by PadReader. The padding is removed as the one of
+// PadReader.
+func PadReaderHidingSize(data io.ReadCloser, blockSize int, maxDataLen int64) io.ReadCloser {
+	cr := newCountingReader(data)
+	return readcloser.MultiReadCloser(cr,
+		readcloser.LazyReadCloser(func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(makeSizeHidingPadding(cr.N, blockSize, maxDataLen))), nil
+		}))
+}
+
 type countingReader struct {
 	R io.ReadCloser
 	N int64
@@ -44,6 +44,9 @@ func TestPad(t *testing.T) {
 		if int64(padding+len(example.data)) != padded.Size() {
 			t.Fatalf("invalid padding")
 		}
+		if PaddedSize(int64(len(example.data)), example.blockSize) != padded.Size() {
+			t.Fatalf("invalid padded size: %d", examplenum)
+		}
 		unpadded, err := Unpad(padded, padding)
 		if err != nil {
 			t.Fatalf("unexpected error")
@@ -76,3 +79,74 @@ func TestPad(t *testing.T) {
 		}
 	}
 }
+
+func TestPadmeSize(t *testing.T) {
+	for _, example := range []struct {
+		size, padded int64
+	}{
+		{0, 0},
+		{1, 1},
+		{10, 10},
+		{100, 104},
+		{1000, 1024},
+		{1025, 1088},
+		{1 << 20, 1 << 20},
+		{1<<20 + 1, 1<<20 + 1<<15},
+	} {
+		if padded := padmeSize(example.size); padded != example.padded {
+			t.Fatalf("invalid padded size of %d: %d != %d", example.size,
+				padded, example.padded)
+		}
+	}
+}
+
+func TestPadReaderHidingSize(t *testing.T) {
+	for examplenum, example := range []struct {
+		dataLen    int
+		blockSize  int
+		maxDataLen int64
+		padding    int
+	}{
+		{0, 16, 1024, 16},
+		{6, 16, 1024, 10},
+		{96, 16, 1024, 16},
+		{1000, 16, 2048, 24},
+		{1000, 16, 1000, 8},
+		{1020, 16, 1000, 4},
+		{3000, 512, 1 << 20, 72},
+		{1 << 20, 512, 1 << 20, 512},
+		{1<<20 - 100, 512, 1 << 20, 100},
+		{1<<20 - 2, 512, 1 << 20, 514},
+	} {
+		ctx := context.Background()
+		data := bytes.Repeat([]byte{1}, example.dataLen)
+		padded, err := ioutil.ReadAll(PadReaderHidingSize(
+			ioutil.NopCloser(bytes.NewReader(data)), example.blockSize,
+			example.maxDataLen))
+		if err != nil {
+			t.Fatalf("unexpected error")
+		}
+		if len(padded) != example.dataLen+example.padding {
+			t.Fatalf("invalid padding: %d, %v != %v", examplenum,
+				len(padded)-example.dataLen, example.padding)
+		}
+		if len(padded)%example.blockSize != 0 {
+			t.Fatalf("padded size %d isn't aligned", len(padded))
+		}
+		unpadded, err := UnpadSlow(ctx, ranger.ByteRanger(padded))
+		if err != nil {
+			t.Fatalf("unexpected error")
+		}
+		r, err := unpadded.Range(ctx, 0, unpadded.Size())
+		if err != nil {
+			t.Fatalf("unexpected error")
+		}
+		unpaddedData, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatalf("unexpected error")
+		}
+		if !bytes.Equal(unpaddedData, data) {
+			t.Fatalf("mismatch")
+		}
+	}
+}
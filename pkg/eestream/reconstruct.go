@@ -0,0 +1,98 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package eestream
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"storj.io/storj/pkg/ranger"
+)
+
+// Reconstruct takes a map of Rangers of erasure pieces and an ErasureScheme
+// and returns Readers of the erasure pieces with the numbers in nums, which
+// are rebuilt from the given pieces. Only the erasure shares of the pieces in
+// nums are encoded, so a segment can be repaired without encoding and
+// uploading again the pieces which are still healthy.
+//
+// rrs is a map of erasure piece numbers to erasure piece rangers. At least
+// the required count of pieces is needed to rebuild the others. See Decode
+// for mbm and pieceTimeout.
+//
+// The Readers must be read in parallel, as the erasure shares are written
+// to all of them stripe by stripe. A Reader which isn't read anymore must be
+// closed.
+func Reconstruct(ctx context.Context, rrs map[int]ranger.Ranger, es ErasureScheme,
+	nums []int, mbm int, pieceTimeout time.Duration) (map[int]io.ReadCloser, error) {
+	seen := make(map[int]bool, len(nums))
+	for _, num := range nums {
+		if num < 0 || num >= es.TotalCount() {
+			return nil, Error.New("invalid piece number %d", num)
+		}
+		if _, ok := rrs[num]; ok {
+			return nil, Error.New("piece %d can't be rebuilt from itself", num)
+		}
+		if seen[num] {
+			return nil, Error.New("duplicated piece number %d", num)
+		}
+		seen[num] = true
+	}
+
+	rr, err := Decode(rrs, es, mbm, pieceTimeout)
+	if err != nil {
+		return nil, err
+	}
+	r, err := rr.Range(ctx, 0, rr.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	readers := make(map[int]io.ReadCloser, len(nums))
+	writers := make(map[int]*io.PipeWriter, len(nums))
+	for _, num := range nums {
+		pr, pw := io.Pipe()
+		readers[num] = pr
+		writers[num] = pw
+	}
+	go reconstruct(r, es, writers)
+	return readers, nil
+}
+
+// reconstruct reads the stripes of the decoded data from r and writes the
+// erasure shares of the pieces to writers, until r is read or all the pieces
+// are closed.
+func reconstruct(r io.ReadCloser, es ErasureScheme, writers map[int]*io.PipeWriter) {
+	stripe := make([]byte, es.StripeSize())
+	share := make([]byte, es.ErasureShareSize())
+	var err error
+	for len(writers) > 0 {
+		_, err = io.ReadFull(r, stripe)
+		if err != nil {
+			break
+		}
+		for num, w := range writers {
+			if err = es.EncodeSingle(stripe, share, num); err != nil {
+				break
+			}
+			if _, werr := w.Write(share); werr != nil {
+				// the piece was closed by its reader
+				delete(writers, num)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	if closeErr := r.Close(); err == nil {
+		err = closeErr
+	}
+	for _, w := range writers {
+		// with a nil error the pieces are read until io.EOF
+		_ = w.CloseWithError(err)
+	}
+}
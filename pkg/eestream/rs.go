@@ -5,6 +5,8 @@ package eestream
 
 import (
 	"github.com/vivint/infectious"
+
+	"storj.io/storj/pkg/storj"
 )
 
 type rsScheme struct {
@@ -17,6 +19,47 @@ func NewRSScheme(fc *infectious.FEC, erasureShareSize int) ErasureScheme {
 	return &rsScheme{fc: fc, erasureShareSize: erasureShareSize}
 }
 
+// NewErasureScheme returns the ErasureScheme of algorithm, which encodes
+// stripes into total pieces, required of which are needed to decode them.
+func NewErasureScheme(algorithm storj.RedundancyAlgorithm, required, total, erasureShareSize int) (ErasureScheme, error) {
+	switch algorithm {
+	case storj.ReedSolomon:
+		fc, err := infectious.NewFEC(required, total)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		return NewRSScheme(fc, erasureShareSize), nil
+	case storj.ReedSolomonSIMD:
+		return NewSIMDScheme(required, total, erasureShareSize)
+	default:
+		return nil, Error.New("invalid redundancy algorithm %d", algorithm)
+	}
+}
+
+// Algorithm returns the redundancy algorithm of es
+func Algorithm(es ErasureScheme) storj.RedundancyAlgorithm {
+	if rs, ok := es.(RedundancyStrategy); ok {
+		es = rs.ErasureScheme
+	}
+	if _, ok := es.(*simdScheme); ok {
+		return storj.ReedSolomonSIMD
+	}
+	return storj.ReedSolomon
+}
+
+// NewRedundancyStrategyFromStorj returns the RedundancyStrategy of a
+// redundancy scheme
+func NewRedundancyStrategyFromStorj(scheme storj.RedundancyScheme) (RedundancyStrategy, error) {
+	if scheme.ShareSize <= 0 {
+		return RedundancyStrategy{}, Error.New("invalid erasure share size %d", scheme.ShareSize)
+	}
+	es, err := NewErasureScheme(scheme.Algorithm, int(scheme.RequiredShares), int(scheme.TotalShares), int(scheme.ShareSize))
+	if err != nil {
+		return RedundancyStrategy{}, err
+	}
+	return NewRedundancyStrategy(es, int(scheme.RepairShares), int(scheme.OptimalShares))
+}
+
 func (s *rsScheme) Encode(input []byte, output func(num int, data []byte)) (
 	err error) {
 	return s.fc.Encode(input, func(s infectious.Share) {
@@ -24,6 +67,10 @@ func (s *rsScheme) Encode(input []byte, output func(num int, data []byte)) (
 	})
 }
 
+func (s *rsScheme) EncodeSingle(input, output []byte, num int) (err error) {
+	return s.fc.EncodeSingle(input, output, num)
+}
+
 func (s *rsScheme) Decode(out []byte, in map[int][]byte) ([]byte, error) {
 	shares := make([]infectious.Share, 0, len(in))
 	for num, data := range in {
@@ -12,6 +12,7 @@ import (
 	"io"
 	"io/ioutil"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -127,7 +128,7 @@ func TestRSRanger(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	rc, err := Decode(rrs, rs, 0)
+	rc, err := Decode(rrs, rs, 0, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -189,6 +190,37 @@ func TestNewRedundancyStrategy(t *testing.T) {
 	}
 }
 
+func TestNewRedundancyStrategyFromStorj(t *testing.T) {
+	for i, tt := range []struct {
+		scheme    storj.RedundancyScheme
+		errString string
+	}{
+		{storj.RedundancyScheme{Algorithm: storj.ReedSolomon, ShareSize: 1024, RequiredShares: 2, RepairShares: 3, OptimalShares: 4, TotalShares: 5}, ""},
+		{storj.RedundancyScheme{Algorithm: storj.ReedSolomon, ShareSize: 1024, RequiredShares: 2, TotalShares: 5}, ""},
+		{storj.RedundancyScheme{Algorithm: storj.ReedSolomonSIMD, ShareSize: 1024, RequiredShares: 2, RepairShares: 3, OptimalShares: 4, TotalShares: 5}, ""},
+		{storj.RedundancyScheme{ShareSize: 1024, RequiredShares: 2, TotalShares: 5}, "eestream error: invalid redundancy algorithm 0"},
+		{storj.RedundancyScheme{Algorithm: storj.ReedSolomon, RequiredShares: 2, TotalShares: 5}, "eestream error: invalid erasure share size 0"},
+		{storj.RedundancyScheme{Algorithm: storj.ReedSolomon, ShareSize: 1024, RequiredShares: 2, RepairShares: 6, TotalShares: 5}, "eestream error: repair threshold greater than total count"},
+	} {
+		errTag := fmt.Sprintf("Test case #%d", i)
+		rs, err := NewRedundancyStrategyFromStorj(tt.scheme)
+		if tt.errString != "" {
+			assert.EqualError(t, err, tt.errString, errTag)
+			continue
+		}
+		if !assert.NoError(t, err, errTag) {
+			continue
+		}
+		assert.Equal(t, int(tt.scheme.ShareSize), rs.ErasureShareSize(), errTag)
+		assert.Equal(t, int(tt.scheme.RequiredShares), rs.RequiredCount(), errTag)
+		assert.Equal(t, int(tt.scheme.TotalShares), rs.TotalCount(), errTag)
+		if tt.scheme.RepairShares != 0 {
+			assert.Equal(t, int(tt.scheme.RepairShares), rs.RepairThreshold(), errTag)
+			assert.Equal(t, int(tt.scheme.OptimalShares), rs.OptimalThreshold(), errTag)
+		}
+	}
+}
+
 func TestRSEncoderInputParams(t *testing.T) {
 	for i, tt := range []struct {
 		mbm       int
@@ -555,6 +587,217 @@ func readAllStalled(readers []io.Reader, stalled int) ([][]byte, error) {
 	return pieces, nil
 }
 
+// stalledRanger is a ranger of a piece which never delivers any data, either
+// because its range never opens or because its reader never returns
+type stalledRanger struct {
+	size      int64
+	openRange bool
+}
+
+func (rr *stalledRanger) Size() int64 { return rr.size }
+
+func (rr *stalledRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	if !rr.openRange {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &stalledReader{ctx: ctx}, nil
+}
+
+type stalledReader struct {
+	ctx context.Context
+}
+
+func (r *stalledReader) Read(p []byte) (n int, err error) {
+	<-r.ctx.Done()
+	return 0, r.ctx.Err()
+}
+
+func (r *stalledReader) Close() error { return nil }
+
+func TestDecodeStalledPieces(t *testing.T) {
+	ctx := context.Background()
+	data := randData(32 * 1024)
+	fc, err := infectious.NewFEC(2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := NewRedundancyStrategy(NewRSScheme(fc, 1024), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readers, err := EncodeReader(ctx, bytes.NewReader(data), rs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces, err := readAll(readers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only the required pieces deliver their data
+	size := int64(len(pieces[0]))
+	rrs := map[int]ranger.Ranger{
+		0: ranger.ByteRanger(pieces[0]),
+		1: &stalledRanger{size: size},
+		2: &stalledRanger{size: size, openRange: true},
+		3: ranger.ByteRanger(pieces[3]),
+	}
+	rr, err := Decode(rrs, rs, 0, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	r, err := rr.Range(ctx, 0, rr.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data2, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, r.Close())
+	if !bytes.Equal(data, data2) {
+		t.Fatalf("rs decode with stalled pieces failed")
+	}
+	if time.Since(start) > 1*time.Second {
+		t.Fatalf("waited for stalled pieces")
+	}
+
+	_, err = Decode(rrs, rs, 0, -1)
+	assert.EqualError(t, err, "eestream error: negative piece timeout")
+}
+
+// recordingRanger records the ranges requested from it
+type recordingRanger struct {
+	ranger.Ranger
+	mu     sync.Mutex
+	ranges [][2]int64
+}
+
+func (rr *recordingRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	rr.mu.Lock()
+	rr.ranges = append(rr.ranges, [2]int64{offset, length})
+	rr.mu.Unlock()
+	return rr.Ranger.Range(ctx, offset, length)
+}
+
+func TestDecodeSparseRange(t *testing.T) {
+	ctx := context.Background()
+	data := randData(32 * 1024)
+	fc, err := infectious.NewFEC(2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := NewRedundancyStrategy(NewRSScheme(fc, 1024), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readers, err := EncodeReader(ctx, bytes.NewReader(data), rs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces, err := readAll(readers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorders := make([]*recordingRanger, len(pieces))
+	rrs := make(map[int]ranger.Ranger, len(pieces))
+	for i, piece := range pieces {
+		recorders[i] = &recordingRanger{Ranger: ranger.ByteRanger(piece)}
+		rrs[i] = recorders[i]
+	}
+	rr, err := Decode(rrs, rs, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a range within the stripes 5 and 6 reads just their erasure shares
+	stripeSize := int64(rs.StripeSize())
+	offset, length := 5*stripeSize+10, stripeSize
+	r, err := rr.Range(ctx, offset, length)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, data[offset:offset+length], got)
+	for _, recorder := range recorders {
+		assert.Equal(t, [][2]int64{{5 * 1024, 2 * 1024}}, recorder.ranges)
+	}
+}
+
+func TestReconstruct(t *testing.T) {
+	ctx := context.Background()
+	data := randData(32 * 1024)
+	fc, err := infectious.NewFEC(2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := NewRedundancyStrategy(NewRSScheme(fc, 1024), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readers, err := EncodeReader(ctx, bytes.NewReader(data), rs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces, err := readAll(readers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the pieces 1 and 3 are rebuilt from the required pieces 0 and 2
+	rrs := map[int]ranger.Ranger{
+		0: ranger.ByteRanger(pieces[0]),
+		2: ranger.ByteRanger(pieces[2]),
+	}
+	rebuilt, err := Reconstruct(ctx, rrs, rs, []int{1, 3}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, rebuilt, 2)
+	rebuiltReaders := []io.Reader{rebuilt[1], rebuilt[3]}
+	rebuiltPieces, err := readAll(rebuiltReaders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, pieces[1], rebuiltPieces[0])
+	assert.Equal(t, pieces[3], rebuiltPieces[1])
+	for _, r := range rebuilt {
+		assert.NoError(t, r.Close())
+	}
+
+	// a closed piece doesn't hold up the others
+	rebuilt, err = Reconstruct(ctx, rrs, rs, []int{1, 3}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, rebuilt[1].Close())
+	piece, err := ioutil.ReadAll(rebuilt[3])
+	assert.NoError(t, err)
+	assert.Equal(t, pieces[3], piece)
+
+	for _, tt := range []struct {
+		nums      []int
+		errString string
+	}{
+		{[]int{4}, "eestream error: invalid piece number 4"},
+		{[]int{-1}, "eestream error: invalid piece number -1"},
+		{[]int{0}, "eestream error: piece 0 can't be rebuilt from itself"},
+		{[]int{1, 1}, "eestream error: duplicated piece number 1"},
+	} {
+		_, err := Reconstruct(ctx, rrs, rs, tt.nums, 0, 0)
+		assert.EqualError(t, err, tt.errString)
+	}
+
+	_, err = Reconstruct(ctx, map[int]ranger.Ranger{0: ranger.ByteRanger(pieces[0])}, rs, []int{1}, 0, 0)
+	assert.EqualError(t, err, "eestream error: not enough readers to reconstruct data!")
+}
+
 func BenchmarkReedSolomonErasureScheme(b *testing.B) {
 	data := randData(8 << 20)
 	output := make([]byte, 8<<20)
@@ -0,0 +1,235 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package eestream
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"hash"
+	"io"
+	"sync"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/ranger"
+	"storj.io/storj/pkg/utils"
+)
+
+// ShareHashSize is the size of the hashes of the erasure shares, which are
+// stored after the erasure shares of a piece
+const ShareHashSize = sha256.Size
+
+// ErrShareCorrupted is the errs class of erasure shares which don't match
+// their hashes
+var ErrShareCorrupted = errs.Class("corrupted erasure share")
+
+// HashedPieceSize returns the size of a piece with size bytes of erasure
+// shares of shareSize bytes, followed by their hashes
+func HashedPieceSize(size int64, shareSize int) int64 {
+	return size + size/int64(shareSize)*ShareHashSize
+}
+
+// HashingReader reads the erasure shares of a piece, followed by their
+// hashes once all shares are read
+type HashingReader struct {
+	r         io.Reader
+	shareSize int
+	share     hash.Hash // hash of the current share
+	hashed    int       // bytes of the current share which are hashed
+	hashes    []byte
+	trailer   []byte // unread part of hashes
+	eof       bool
+}
+
+// NewHashingReader returns a HashingReader of the erasure shares of shareSize
+// bytes read from r
+func NewHashingReader(r io.Reader, shareSize int) *HashingReader {
+	return &HashingReader{r: r, shareSize: shareSize, share: sha256.New()}
+}
+
+// Read reads the erasure shares, and then their hashes
+func (hr *HashingReader) Read(p []byte) (n int, err error) {
+	if !hr.eof {
+		n, err = hr.r.Read(p)
+		hr.add(p[:n])
+		if err != io.EOF {
+			return n, err
+		}
+		if hr.hashed > 0 {
+			hr.hashes = hr.share.Sum(hr.hashes)
+		}
+		hr.eof = true
+		hr.trailer = hr.hashes
+		if n > 0 {
+			return n, nil
+		}
+	}
+	if len(hr.trailer) == 0 {
+		return 0, io.EOF
+	}
+	n = copy(p, hr.trailer)
+	hr.trailer = hr.trailer[n:]
+	return n, nil
+}
+
+// add hashes data
+func (hr *HashingReader) add(data []byte) {
+	for len(data) > 0 {
+		n := hr.shareSize - hr.hashed
+		if n > len(data) {
+			n = len(data)
+		}
+		_, _ = hr.share.Write(data[:n])
+		hr.hashed += n
+		data = data[n:]
+		if hr.hashed == hr.shareSize {
+			hr.hashes = hr.share.Sum(hr.hashes)
+			hr.share.Reset()
+			hr.hashed = 0
+		}
+	}
+}
+
+// Hash returns the hash of the hashes of the erasure shares, which is stored
+// with the piece to verify them. It's nil until all erasure shares are read.
+func (hr *HashingReader) Hash() []byte {
+	if !hr.eof {
+		return nil
+	}
+	sum := sha256.Sum256(hr.hashes)
+	return sum[:]
+}
+
+// VerifyShares returns a Ranger of the erasure shares of shareSize bytes of
+// the piece rr, which are followed by their hashes. The hashes are checked
+// against hash, the hash of the hashes, and each erasure share is verified
+// against its hash as it's read, so reads fail with ErrShareCorrupted at the
+// first corrupted share. Ranges must be aligned to the erasure shares.
+func VerifyShares(rr ranger.Ranger, shareSize int, hash []byte) (ranger.Ranger, error) {
+	hashedShareSize := int64(shareSize + ShareHashSize)
+	if rr.Size()%hashedShareSize != 0 {
+		return nil, Error.New("invalid size (%d) of piece with hashed erasure shares of %d bytes",
+			rr.Size(), shareSize)
+	}
+	return &verifiedRanger{
+		rr:        rr,
+		shareSize: shareSize,
+		shares:    rr.Size() / hashedShareSize,
+		hash:      hash,
+	}, nil
+}
+
+type verifiedRanger struct {
+	rr        ranger.Ranger
+	shareSize int
+	shares    int64
+	hash      []byte
+
+	mu     sync.Mutex
+	hashes []byte
+}
+
+func (vr *verifiedRanger) Size() int64 {
+	return vr.shares * int64(vr.shareSize)
+}
+
+// shareHashes returns the verified hashes of the erasure shares, which are
+// read from the piece once
+func (vr *verifiedRanger) shareHashes(ctx context.Context) (hashes []byte, err error) {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	if vr.hashes != nil {
+		return vr.hashes, nil
+	}
+
+	r, err := vr.rr.Range(ctx, vr.Size(), vr.shares*ShareHashSize)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = utils.CombineErrors(err, r.Close()) }()
+
+	hashes = make([]byte, vr.shares*ShareHashSize)
+	if _, err := io.ReadFull(r, hashes); err != nil {
+		return nil, err
+	}
+	if sum := sha256.Sum256(hashes); !bytes.Equal(sum[:], vr.hash) {
+		return nil, ErrShareCorrupted.New("hashes of the erasure shares don't match the hash of the piece")
+	}
+	vr.hashes = hashes
+	return hashes, nil
+}
+
+func (vr *verifiedRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 {
+		return nil, Error.New("negative offset")
+	}
+	if length < 0 {
+		return nil, Error.New("negative length")
+	}
+	if offset+length > vr.Size() {
+		return nil, Error.New("range beyond end")
+	}
+	if offset%int64(vr.shareSize) != 0 || length%int64(vr.shareSize) != 0 {
+		return nil, Error.New("range not aligned to the erasure share size (%d)", vr.shareSize)
+	}
+
+	hashes, err := vr.shareHashes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := vr.rr.Range(ctx, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	first := offset / int64(vr.shareSize)
+	last := (offset + length) / int64(vr.shareSize)
+	return &verifiedReader{
+		r:      r,
+		share:  first,
+		hashes: hashes[first*ShareHashSize : last*ShareHashSize],
+		buf:    make([]byte, vr.shareSize),
+	}, nil
+}
+
+// verifiedReader reads erasure shares, returning only the ones which match
+// their hashes
+type verifiedReader struct {
+	r      io.ReadCloser
+	share  int64 // number of the next share
+	hashes []byte
+	buf    []byte
+	out    []byte
+	err    error
+}
+
+func (r *verifiedReader) Read(p []byte) (n int, err error) {
+	if len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if len(r.hashes) == 0 {
+			return 0, io.EOF
+		}
+		if _, err := io.ReadFull(r.r, r.buf); err != nil {
+			r.err = err
+			return 0, err
+		}
+		if sum := sha256.Sum256(r.buf); !bytes.Equal(sum[:], r.hashes[:ShareHashSize]) {
+			r.err = ErrShareCorrupted.New("erasure share %d doesn't match its hash", r.share)
+			return 0, r.err
+		}
+		r.hashes = r.hashes[ShareHashSize:]
+		r.share++
+		r.out = r.buf
+	}
+
+	n = copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *verifiedReader) Close() error {
+	return r.r.Close()
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package eestream
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/ranger"
+)
+
+func hashPiece(t *testing.T, piece []byte, shareSize int) (hashed []byte, hash []byte) {
+	hr := NewHashingReader(bytes.NewReader(piece), shareSize)
+	assert.Nil(t, hr.Hash())
+	hashed, err := ioutil.ReadAll(hr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return hashed, hr.Hash()
+}
+
+func TestHashingReader(t *testing.T) {
+	piece := randData(4 * 64)
+	hashed, hash := hashPiece(t, piece, 64)
+
+	assert.Equal(t, HashedPieceSize(int64(len(piece)), 64), int64(len(hashed)))
+	assert.Equal(t, piece, hashed[:len(piece)])
+
+	var hashes []byte
+	for i := 0; i < len(piece); i += 64 {
+		sum := sha256.Sum256(piece[i : i+64])
+		hashes = append(hashes, sum[:]...)
+	}
+	assert.Equal(t, hashes, hashed[len(piece):])
+
+	sum := sha256.Sum256(hashes)
+	assert.Equal(t, sum[:], hash)
+}
+
+func TestVerifyShares(t *testing.T) {
+	ctx := context.Background()
+	piece := randData(4 * 64)
+	hashed, hash := hashPiece(t, piece, 64)
+
+	rr, err := VerifyShares(ranger.ByteRanger(hashed), 64, hash)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(len(piece)), rr.Size())
+
+	for _, tt := range []struct {
+		offset, length int64
+		errString      string
+	}{
+		{0, 4 * 64, ""},
+		{64, 2 * 64, ""},
+		{3 * 64, 0, ""},
+		{1, 64, "eestream error: range not aligned to the erasure share size (64)"},
+		{0, 5 * 64, "eestream error: range beyond end"},
+	} {
+		r, err := rr.Range(ctx, tt.offset, tt.length)
+		if tt.errString != "" {
+			assert.EqualError(t, err, tt.errString)
+			continue
+		}
+		if !assert.NoError(t, err) {
+			continue
+		}
+		data, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, piece[tt.offset:tt.offset+tt.length], data)
+		assert.NoError(t, r.Close())
+	}
+
+	_, err = VerifyShares(ranger.ByteRanger(hashed[1:]), 64, hash)
+	assert.Error(t, err)
+}
+
+func TestVerifySharesCorrupted(t *testing.T) {
+	ctx := context.Background()
+	piece := randData(4 * 64)
+	hashed, hash := hashPiece(t, piece, 64)
+
+	// a corrupted share fails reads once it's reached
+	corrupted := append([]byte{}, hashed...)
+	corrupted[2*64] ^= 1
+	rr, err := VerifyShares(ranger.ByteRanger(corrupted), 64, hash)
+	if !assert.NoError(t, err) {
+		return
+	}
+	r, err := rr.Range(ctx, 0, rr.Size())
+	if !assert.NoError(t, err) {
+		return
+	}
+	data, err := ioutil.ReadAll(r)
+	assert.True(t, ErrShareCorrupted.Has(err), "%v", err)
+	assert.Equal(t, piece[:2*64], data)
+	assert.NoError(t, r.Close())
+
+	// corrupted hashes fail all reads
+	corrupted = append([]byte{}, hashed...)
+	corrupted[len(piece)] ^= 1
+	rr, err = VerifyShares(ranger.ByteRanger(corrupted), 64, hash)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = rr.Range(ctx, 0, 64)
+	assert.True(t, ErrShareCorrupted.Has(err), "%v", err)
+}
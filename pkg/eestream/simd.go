@@ -0,0 +1,162 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package eestream
+
+import (
+	"github.com/klauspost/reedsolomon"
+	"github.com/vivint/infectious"
+)
+
+// simdScheme is a Reed-Solomon ErasureScheme which encodes and decodes with
+// the SIMD instructions of the CPU, when it has them. Its pieces can only be
+// decoded by a simdScheme, as its code differs from the one of rsScheme.
+type simdScheme struct {
+	enc              reedsolomon.Encoder
+	required, total  int
+	erasureShareSize int
+}
+
+// NewSIMDScheme returns a Reed-Solomon-based ErasureScheme accelerated with
+// SIMD instructions, which encodes stripes into total pieces, required of
+// which are needed to decode them. Unlike the scheme of NewRSScheme, it
+// detects corrupted erasure shares but can't correct them, so it relies on
+// the hashes of the erasure shares to exclude corrupted pieces.
+func NewSIMDScheme(required, total, erasureShareSize int) (ErasureScheme, error) {
+	enc, err := reedsolomon.New(required, total-required)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return &simdScheme{
+		enc:              enc,
+		required:         required,
+		total:            total,
+		erasureShareSize: erasureShareSize,
+	}, nil
+}
+
+// shards splits the stripe in into the data shards and appends buffers for
+// the parity shards
+func (s *simdScheme) shards(in []byte) ([][]byte, error) {
+	if len(in) != s.StripeSize() {
+		return nil, Error.New("invalid stripe size %d", len(in))
+	}
+	shards := make([][]byte, s.total)
+	for i := 0; i < s.required; i++ {
+		shards[i] = in[i*s.erasureShareSize : (i+1)*s.erasureShareSize]
+	}
+	for i := s.required; i < s.total; i++ {
+		shards[i] = getBuffer(s.erasureShareSize)
+	}
+	return shards, nil
+}
+
+// putParity returns the buffers of the parity shards to the pool
+func (s *simdScheme) putParity(shards [][]byte) {
+	for _, shard := range shards[s.required:] {
+		putBuffer(shard)
+	}
+}
+
+func (s *simdScheme) Encode(input []byte, output func(num int, data []byte)) (
+	err error) {
+	shards, err := s.shards(input)
+	if err != nil {
+		return err
+	}
+	defer s.putParity(shards)
+
+	if err = s.enc.Encode(shards); err != nil {
+		return Error.Wrap(err)
+	}
+	for num, shard := range shards {
+		output(num, shard)
+	}
+	return nil
+}
+
+func (s *simdScheme) EncodeSingle(input, output []byte, num int) (err error) {
+	if num < 0 || num >= s.total {
+		return Error.New("invalid piece number %d", num)
+	}
+	if num < s.required {
+		copy(output, input[num*s.erasureShareSize:(num+1)*s.erasureShareSize])
+		return nil
+	}
+	return s.Encode(input, func(n int, data []byte) {
+		if n == num {
+			copy(output, data)
+		}
+	})
+}
+
+func (s *simdScheme) Decode(out []byte, in map[int][]byte) (_ []byte, err error) {
+	if len(in) < s.required {
+		return nil, infectious.NotEnoughShares.New("")
+	}
+
+	shards := make([][]byte, s.total)
+	missing := make([]bool, s.total)
+	for num := range shards {
+		data, ok := in[num]
+		if !ok {
+			// buffers without length are filled by Reconstruct
+			shards[num] = getBuffer(s.erasureShareSize)[:0]
+			missing[num] = true
+			continue
+		}
+		if len(data) != s.erasureShareSize {
+			return nil, Error.New("invalid erasure share size %d of piece %d", len(data), num)
+		}
+		shards[num] = data
+	}
+	defer func() {
+		for num, shard := range shards {
+			if missing[num] {
+				putBuffer(shard)
+			}
+		}
+	}()
+
+	if len(in) == s.required {
+		err = s.enc.ReconstructData(shards)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+	} else {
+		// the surplus erasure shares are checked against the ones rebuilt
+		// from the others, like a decode with error correction would
+		err = s.enc.Reconstruct(shards)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		ok, err := s.enc.Verify(shards)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		if !ok {
+			return nil, infectious.TooManyErrors.New("erasure shares don't match")
+		}
+	}
+
+	for _, shard := range shards[:s.required] {
+		out = append(out, shard...)
+	}
+	return out, nil
+}
+
+func (s *simdScheme) ErasureShareSize() int {
+	return s.erasureShareSize
+}
+
+func (s *simdScheme) StripeSize() int {
+	return s.erasureShareSize * s.required
+}
+
+func (s *simdScheme) TotalCount() int {
+	return s.total
+}
+
+func (s *simdScheme) RequiredCount() int {
+	return s.required
+}
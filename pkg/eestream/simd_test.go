@@ -0,0 +1,150 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package eestream
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vivint/infectious"
+
+	"storj.io/storj/pkg/storj"
+)
+
+func TestSIMDScheme(t *testing.T) {
+	es, err := NewSIMDScheme(3, 6, 64)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, storj.ReedSolomonSIMD, Algorithm(es))
+	assert.Equal(t, 3*64, es.StripeSize())
+
+	stripe := randData(es.StripeSize())
+	shares := make(map[int][]byte, es.TotalCount())
+	err = es.Encode(stripe, func(num int, data []byte) {
+		shares[num] = append([]byte{}, data...)
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, shares, 6)
+	assert.Equal(t, stripe[:64], shares[0])
+
+	share := make([]byte, 64)
+	for num := 0; num < es.TotalCount(); num++ {
+		assert.NoError(t, es.EncodeSingle(stripe, share, num))
+		assert.Equal(t, shares[num], share, "piece %d", num)
+	}
+
+	for i, nums := range [][]int{
+		{0, 1, 2},
+		{3, 4, 5},
+		{1, 3, 5},
+		{0, 2, 3, 4},
+		{0, 1, 2, 3, 4, 5},
+	} {
+		in := make(map[int][]byte, len(nums))
+		for _, num := range nums {
+			in[num] = shares[num]
+		}
+		out, err := es.Decode(nil, in)
+		if assert.NoError(t, err, "Test case #%d", i) {
+			assert.Equal(t, stripe, out, "Test case #%d", i)
+		}
+	}
+
+	_, err = es.Decode(nil, map[int][]byte{0: shares[0], 4: shares[4]})
+	assert.True(t, infectious.NotEnoughShares.Contains(err), "%v", err)
+
+	// a corrupted share is detected with a surplus share, but not corrected
+	corrupted := map[int][]byte{
+		0: shares[0],
+		2: append([]byte{}, shares[2]...),
+		3: shares[3],
+		5: shares[5],
+	}
+	corrupted[2][10] ^= 1
+	_, err = es.Decode(nil, corrupted)
+	assert.True(t, infectious.TooManyErrors.Contains(err), "%v", err)
+}
+
+func TestSIMDEncodeDecodeReaders(t *testing.T) {
+	ctx := context.Background()
+	data := randData(32 * 1024)
+	es, err := NewErasureScheme(storj.ReedSolomonSIMD, 2, 4, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := NewRedundancyStrategy(es, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, storj.ReedSolomonSIMD, Algorithm(rs))
+
+	readers, err := EncodeReader(ctx, bytes.NewReader(data), rs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces, err := readAll(readers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// decode without the first piece
+	readerMap := make(map[int]io.ReadCloser, len(pieces))
+	for i, piece := range pieces[1:] {
+		readerMap[i+1] = ioutil.NopCloser(bytes.NewReader(piece))
+	}
+	decoder := DecodeReaders(ctx, readerMap, rs, int64(len(data)), 0)
+	data2, err := ioutil.ReadAll(decoder)
+	assert.NoError(t, err)
+	assert.Equal(t, data, data2)
+	assert.NoError(t, decoder.Close())
+}
+
+func TestNewErasureScheme(t *testing.T) {
+	for i, tt := range []struct {
+		algorithm storj.RedundancyAlgorithm
+		errString string
+	}{
+		{storj.ReedSolomon, ""},
+		{storj.ReedSolomonSIMD, ""},
+		{storj.InvalidRedundancyAlgorithm, "eestream error: invalid redundancy algorithm 0"},
+	} {
+		errTag := fmt.Sprintf("Test case #%d", i)
+		es, err := NewErasureScheme(tt.algorithm, 2, 4, 1024)
+		if tt.errString != "" {
+			assert.EqualError(t, err, tt.errString, errTag)
+			continue
+		}
+		if !assert.NoError(t, err, errTag) {
+			continue
+		}
+		assert.Equal(t, tt.algorithm, Algorithm(es), errTag)
+		assert.Equal(t, 2, es.RequiredCount(), errTag)
+		assert.Equal(t, 4, es.TotalCount(), errTag)
+		assert.Equal(t, 1024, es.ErasureShareSize(), errTag)
+	}
+}
+
+func BenchmarkErasureSchemeEncode(b *testing.B) {
+	for _, algorithm := range []storj.RedundancyAlgorithm{storj.ReedSolomon, storj.ReedSolomonSIMD} {
+		es, err := NewErasureScheme(algorithm, 29, 95, 1024)
+		if err != nil {
+			b.Fatal(err)
+		}
+		stripe := randData(es.StripeSize())
+		b.Run(fmt.Sprintf("Algorithm%d", algorithm), func(b *testing.B) {
+			b.SetBytes(int64(len(stripe)))
+			for i := 0; i < b.N; i++ {
+				_ = es.Encode(stripe, func(num int, data []byte) {})
+			}
+		})
+	}
+}
@@ -39,11 +39,7 @@ func NewStripeReader(rs map[int]io.ReadCloser, es ErasureScheme, mbm int) *Strip
 		errmap:      make(map[int]error, readerCount),
 	}
 
-	bufSize := mbm / readerCount
-	bufSize -= bufSize % es.ErasureShareSize()
-	if bufSize < es.ErasureShareSize() {
-		bufSize = es.ErasureShareSize()
-	}
+	bufSize := pieceBufferSize(readerCount, es, mbm)
 
 	for i := range rs {
 		r.inbufs[i] = make([]byte, es.ErasureShareSize())
@@ -62,6 +58,20 @@ func NewStripeReader(rs map[int]io.ReadCloser, es ErasureScheme, mbm int) *Strip
 	return r
 }
 
+// pieceBufferSize returns the size of the buffers of each of readerCount
+// pieces, sharing mbm bytes
+func pieceBufferSize(readerCount int, es ErasureScheme, mbm int) int {
+	bufSize := 0
+	if readerCount > 0 {
+		bufSize = mbm / readerCount
+	}
+	bufSize -= bufSize % es.ErasureShareSize()
+	if bufSize < es.ErasureShareSize() {
+		bufSize = es.ErasureShareSize()
+	}
+	return bufSize
+}
+
 // Close closes the StripeReader and all PieceBuffers.
 func (r *StripeReader) Close() error {
 	errs := make(chan error, len(r.bufs))
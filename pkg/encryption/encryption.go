@@ -7,6 +7,8 @@ import (
 	"crypto/hmac"
 	"crypto/sha512"
 
+	"golang.org/x/crypto/argon2"
+
 	"storj.io/storj/pkg/storj"
 )
 
@@ -102,6 +104,23 @@ func DecryptKey(keyToDecrypt storj.EncryptedPrivateKey, cipher storj.Cipher, key
 	return &decryptedKey, nil
 }
 
+// rootKeySalt is the salt the root keys are derived from passphrases with.
+// The salt is fixed, so the same passphrase gives the same root key on every
+// machine.
+var rootKeySalt = []byte("storj.io/storj root key")
+
+// DeriveRootKey derives the root key of the key hierarchy from passphrase
+// with Argon2id. The keys of the buckets are derived from the root key, and
+// the keys of the paths in a bucket from the key of the bucket.
+func DeriveRootKey(passphrase []byte) (*storj.Key, error) {
+	if len(passphrase) == 0 {
+		return nil, Error.New("empty passphrase")
+	}
+	key := new(storj.Key)
+	copy(key[:], argon2.IDKey(passphrase, rootKeySalt, 1, 64*1024, 4, storj.KeySize))
+	return key, nil
+}
+
 // DeriveKey derives new key from the given key and message using HMAC-SHA512
 func DeriveKey(key *storj.Key, message string) (*storj.Key, error) {
 	mac := hmac.New(sha512.New, key[:])
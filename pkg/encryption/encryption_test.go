@@ -0,0 +1,28 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package encryption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeriveRootKey(t *testing.T) {
+	key, err := DeriveRootKey([]byte("passphrase"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	same, err := DeriveRootKey([]byte("passphrase"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, key, same)
+	}
+	other, err := DeriveRootKey([]byte("other passphrase"))
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, key, other)
+	}
+
+	_, err = DeriveRootKey(nil)
+	assert.Error(t, err)
+}
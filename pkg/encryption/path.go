@@ -48,6 +48,12 @@ func DecryptPath(path storj.Path, key *storj.Key) (decrypted storj.Path, err err
 	return storj.JoinPaths(comps...), nil
 }
 
+// DeriveBucketKey derives the key of bucket from the given root key. The
+// paths in the bucket are encrypted with it.
+func DeriveBucketKey(bucket string, key *storj.Key) (*storj.Key, error) {
+	return DeriveKey(key, "path:"+bucket)
+}
+
 // DerivePathKey derives the key for the given depth from the given root key.
 // This method must be called on an unencrypted path.
 func DerivePathKey(path storj.Path, key *storj.Key, depth int) (derivedKey *storj.Key, err error) {
@@ -85,3 +85,36 @@ func TestDeriveKey(t *testing.T) {
 		assert.Equal(t, expected, decrypted, errTag)
 	}
 }
+
+func TestDeriveBucketKey(t *testing.T) {
+	key := new(storj.Key)
+	copy(key[:], randData(storj.KeySize))
+
+	bucketKey, err := DeriveBucketKey("bucket", key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	otherKey, err := DeriveBucketKey("other", key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEqual(t, bucketKey, otherKey)
+
+	// the paths in a bucket are encrypted with the key of the bucket
+	encrypted, err := EncryptPath("bucket/fold1/file.txt", key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	encryptedInBucket, err := EncryptPath("fold1/file.txt", bucketKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, storj.SplitPath(encrypted)[1:], storj.SplitPath(encryptedInBucket))
+
+	decrypted, err := DecryptPath(encryptedInBucket, bucketKey)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "fold1/file.txt", decrypted)
+	}
+	_, err = DecryptPath(encryptedInBucket, otherKey)
+	assert.Error(t, err)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"storj.io/storj/pkg/ranger"
+	"storj.io/storj/pkg/storj"
 )
 
 func TestCalcEncompassingBlocks(t *testing.T) {
@@ -204,3 +205,55 @@ func TestTransformerSize(t *testing.T) {
 		}
 	}
 }
+
+// recordingRanger records the ranges requested from it
+type recordingRanger struct {
+	ranger.Ranger
+	ranges [][2]int64
+}
+
+func (rr *recordingRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	rr.ranges = append(rr.ranges, [2]int64{offset, length})
+	return rr.Ranger.Range(ctx, offset, length)
+}
+
+func TestDecryptSparseRange(t *testing.T) {
+	ctx := context.Background()
+	key := new(storj.Key)
+	copy(key[:], randData(storj.KeySize))
+	nonce := new(storj.Nonce)
+
+	for _, cipher := range []storj.Cipher{storj.AESGCM, storj.SecretBox} {
+		encrypter, err := NewEncrypter(cipher, key, nonce, 4*1024)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		data := randData(encrypter.InBlockSize() * 10)
+		encrypted, err := ioutil.ReadAll(TransformReader(ioutil.NopCloser(bytes.NewReader(data)), encrypter, 0))
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		decrypter, err := NewDecrypter(cipher, key, nonce, 4*1024)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		rr := &recordingRanger{Ranger: ranger.ByteRanger(encrypted)}
+		decrypted, err := Transform(rr, decrypter)
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		// a range within the blocks 5 and 6 decrypts just those blocks
+		blockSize := int64(decrypter.OutBlockSize())
+		offset, length := 5*blockSize+10, blockSize
+		r, err := decrypted.Range(ctx, offset, length)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		got, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, data[offset:offset+length], got)
+		assert.Equal(t, [][2]int64{{5 * 4 * 1024, 2 * 4 * 1024}}, rr.ranges)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package kademlia
+
+import (
+	"context"
+	"net"
+
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/pb"
+)
+
+// resolveExternalAddress asks the bootstrap nodes, in order, which host this
+// node's requests come from and returns advertised with its host replaced by
+// the first answer. The port of advertised is kept, as the port requests are
+// sent from says nothing about the port forwarded to the node.
+func resolveExternalAddress(ctx context.Context, client node.Client, bootstrapNodes []pb.Node, advertised string) (string, error) {
+	_, port, err := net.SplitHostPort(advertised)
+	if err != nil {
+		return "", Error.Wrap(err)
+	}
+
+	for _, n := range bootstrapNodes {
+		observed, err := client.ExternalAddress(ctx, n)
+		if err != nil {
+			zap.L().Debug("could not resolve external address",
+				zap.String("Address", n.GetAddress().GetAddress()),
+				zap.Error(err))
+			continue
+		}
+		host, _, err := net.SplitHostPort(observed)
+		if err != nil {
+			zap.L().Debug("invalid external address", zap.String("Observed", observed), zap.Error(err))
+			continue
+		}
+		return net.JoinHostPort(host, port), nil
+	}
+	return "", Error.New("none of the %d bootstrap nodes reported an external address", len(bootstrapNodes))
+}
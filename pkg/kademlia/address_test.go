@@ -0,0 +1,51 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package kademlia
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/pb"
+)
+
+func TestResolveExternalAddress(t *testing.T) {
+	down := pb.Node{Id: "down", Address: &pb.NodeAddress{Address: "10.0.0.1:8080"}}
+	up := pb.Node{Id: "up", Address: &pb.NodeAddress{Address: "10.0.0.2:8080"}}
+	client := &fakeNodeClient{external: map[string]string{up.Id: "203.0.113.7:54321"}}
+
+	for _, tt := range []struct {
+		name       string
+		nodes      []pb.Node
+		advertised string
+		address    string
+		err        bool
+	}{
+		{name: "first answer wins, port is kept",
+			nodes:      []pb.Node{down, up},
+			advertised: "127.0.0.1:7777",
+			address:    "203.0.113.7:7777",
+		},
+		{name: "no answer",
+			nodes:      []pb.Node{down},
+			advertised: "127.0.0.1:7777",
+			err:        true,
+		},
+		{name: "advertised address without port",
+			nodes:      []pb.Node{up},
+			advertised: "127.0.0.1",
+			err:        true,
+		},
+	} {
+		address, err := resolveExternalAddress(context.Background(), client, tt.nodes, tt.advertised)
+		if tt.err {
+			assert.Error(t, err, tt.name)
+			continue
+		}
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.address, address, tt.name)
+	}
+}
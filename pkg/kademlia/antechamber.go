@@ -0,0 +1,111 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package kademlia
+
+import (
+	"context"
+	"crypto/x509"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/provider"
+	"storj.io/storj/storage"
+)
+
+// antechamber holds nodes that were discovered during lookups but haven't
+// been vetted yet. Nodes only move on to the routing table once they respond
+// to a ping and their node id meets the required difficulty, so that
+// transient nodes and cheaply generated ids don't pollute the routing table.
+type antechamber struct {
+	mu    sync.Mutex
+	size  int
+	nodes []*pb.Node // oldest first
+}
+
+func newAntechamber(size int) *antechamber {
+	return &antechamber{size: size}
+}
+
+// add adds the nodes to the antechamber, replacing entries that are already
+// waiting and dropping the oldest entries when it is full
+func (a *antechamber) add(nodes ...*pb.Node) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, node := range nodes {
+		for i, n := range a.nodes {
+			if n.GetId() == node.GetId() {
+				a.nodes = append(a.nodes[:i], a.nodes[i+1:]...)
+				break
+			}
+		}
+		a.nodes = append(a.nodes, node)
+	}
+	if len(a.nodes) > a.size {
+		a.nodes = a.nodes[len(a.nodes)-a.size:]
+	}
+}
+
+// drain removes and returns all waiting nodes
+func (a *antechamber) drain() []*pb.Node {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	nodes := a.nodes
+	a.nodes = nil
+	return nodes
+}
+
+// vet empties the antechamber and adds the nodes that pass vetting to the
+// routing table. Nodes that are already in the routing table are skipped.
+func (k *Kademlia) vet(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	rt := k.routingTable
+	var candidates []*pb.Node
+	for _, n := range k.antechamber.drain() {
+		if n.GetId() == rt.self.GetId() {
+			continue
+		}
+		_, err := rt.nodeBucketDB.Get(storage.Key(n.GetId()))
+		if err == nil {
+			continue
+		}
+		if !storage.ErrKeyNotFound.Has(err) {
+			return RoutingErr.New("could not get node %s", err)
+		}
+		if err := checkDifficulty(n, k.identity.PeerIDDifficulty); err != nil {
+			zap.L().Debug("rejecting node", zap.String("NodeID", n.GetId()), zap.Error(err))
+			continue
+		}
+		candidates = append(candidates, n)
+	}
+
+	live, _ := k.pingAll(ctx, candidates)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, n := range live {
+		if err := rt.ConnectionSuccess(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDifficulty verifies that the node id the identity chain of n is
+// derived from has at least the given difficulty
+func checkDifficulty(n *pb.Node, difficulty uint16) error {
+	verify := provider.VerifyPeerDifficulty(difficulty)
+	if verify == nil {
+		return nil
+	}
+	chain, err := provider.ParseCertChain(n.GetIdentityChain())
+	if err != nil {
+		return provider.ErrDifficulty.Wrap(err)
+	}
+	return verify(nil, [][]*x509.Certificate{chain})
+}
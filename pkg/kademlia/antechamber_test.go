@@ -0,0 +1,70 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package kademlia
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/dht"
+	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/storage"
+)
+
+func TestAntechamberAdd(t *testing.T) {
+	a := newAntechamber(2)
+	node1, node2, node3 := mockNode("AB"), mockNode("AC"), mockNode("AD")
+
+	a.add(node1, node2)
+	a.add(node1)
+	assert.Equal(t, []*pb.Node{node2, node1}, a.nodes)
+
+	a.add(node3)
+	assert.Equal(t, []*pb.Node{node1, node3}, a.drain())
+	assert.Empty(t, a.drain())
+}
+
+func TestCheckDifficulty(t *testing.T) {
+	fid, err := newTestIdentity()
+	assert.NoError(t, err)
+	n := &pb.Node{Id: fid.ID.String(), Address: &pb.NodeAddress{Address: "127.0.0.1:1"}}
+	assert.NoError(t, node.SignNode(n, fid))
+
+	assert.NoError(t, checkDifficulty(n, 0))
+	assert.NoError(t, checkDifficulty(n, 12))
+	assert.Error(t, checkDifficulty(n, 256))
+	assert.Error(t, checkDifficulty(mockNode("AB"), 12))
+}
+
+func TestVet(t *testing.T) {
+	live, s, clean := testNode(t, []pb.Node{})
+	defer clean()
+	defer s.Stop()
+
+	fid, err := newTestIdentity()
+	assert.NoError(t, err)
+	deadFid, err := newTestIdentity()
+	assert.NoError(t, err)
+	dead := pb.Node{Id: deadFid.ID.String(), Address: &pb.NodeAddress{Address: "127.0.0.1:1"}}
+	assert.NoError(t, node.SignNode(&dead, deadFid))
+
+	dir, cleanup := mktempdir(t, "kademlia")
+	defer cleanup()
+
+	k, err := NewKademlia(dht.NodeID(fid.ID), []pb.Node{}, "127.0.0.1:0", defaultTransport, fid, dir, defaultAlpha, nil)
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, k.Disconnect()) }()
+
+	k.antechamber.add(&live.routingTable.self, &dead, &k.routingTable.self)
+	assert.NoError(t, k.vet(context.Background()))
+
+	_, err = k.routingTable.nodeBucketDB.Get(storage.Key(live.routingTable.self.Id))
+	assert.NoError(t, err)
+	_, err = k.routingTable.nodeBucketDB.Get(storage.Key(dead.Id))
+	assert.True(t, storage.ErrKeyNotFound.Has(err))
+	assert.Empty(t, k.antechamber.drain())
+}
@@ -0,0 +1,103 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package kademlia
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/dht"
+	"storj.io/storj/pkg/pb"
+)
+
+// ParseBootstrapNodes parses a comma separated list of bootstrap nodes.
+// Every entry is either an address or a node id and an address joined by
+// '@', e.g. "id@bootstrap.storj.io:8080". Entries without a node id use
+// their address as a placeholder id so they can still be told apart.
+func ParseBootstrapNodes(list string) ([]pb.Node, error) {
+	var nodes []pb.Node
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, addr := "", entry
+		if i := strings.LastIndex(entry, "@"); i >= 0 {
+			id, addr = entry[:i], entry[i+1:]
+		}
+		if addr == "" {
+			return nil, BootstrapErr.New("missing address in %q", entry)
+		}
+		if id == "" {
+			id = addr
+		}
+		if seen[id] {
+			return nil, BootstrapErr.New("duplicate bootstrap node %q", id)
+		}
+		seen[id] = true
+
+		intro, err := GetIntroNode(addr)
+		if err != nil {
+			return nil, err
+		}
+		intro.Id = id
+		nodes = append(nodes, *intro)
+	}
+	return nodes, nil
+}
+
+// reachableBootstrapNode tries the bootstrap nodes in random order, so that
+// joining nodes are spread across them, and returns the first one that
+// responds, with the ones which didn't respond before it. It returns false
+// if none of them could be reached.
+func (k *Kademlia) reachableBootstrapNode(ctx context.Context) (reachable pb.Node, unreachable []pb.Node, ok bool) {
+	for _, i := range rand.Perm(len(k.bootstrapNodes)) {
+		if ctx.Err() != nil {
+			break
+		}
+
+		bn := k.bootstrapNodes[i]
+		if _, err := k.Ping(ctx, bn); err != nil {
+			zap.L().Warn("bootstrap node unreachable, trying next one",
+				zap.String("NodeID", bn.GetId()),
+				zap.String("Address", bn.GetAddress().GetAddress()),
+				zap.Error(err))
+			unreachable = append(unreachable, bn)
+			continue
+		}
+		return bn, unreachable, true
+	}
+	return pb.Node{}, unreachable, false
+}
+
+// bootstrapStart returns the nodes the bootstrap lookup for target starts
+// from: the reachable bootstrap node, if there is one, and the contacts
+// restored from the routing table, without the other bootstrap nodes
+func (k *Kademlia) bootstrapStart(target dht.NodeID, reachable *pb.Node) ([]*pb.Node, error) {
+	near, err := k.routingTable.FindNear(target, k.routingTable.K())
+	if err != nil {
+		return nil, err
+	}
+
+	skip := map[string]bool{}
+	for _, bn := range k.bootstrapNodes {
+		skip[bn.GetId()] = true
+	}
+
+	var start []*pb.Node
+	if reachable != nil {
+		start = append(start, reachable)
+	}
+	for _, n := range near {
+		if !skip[n.GetId()] {
+			start = append(start, n)
+		}
+	}
+	return start, nil
+}
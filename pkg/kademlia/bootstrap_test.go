@@ -0,0 +1,95 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package kademlia
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/pb"
+)
+
+func TestParseBootstrapNodes(t *testing.T) {
+	for _, tt := range []struct {
+		list      string
+		ids       []string
+		addresses []string
+		err       bool
+	}{
+		{list: "", ids: nil, addresses: nil},
+		{list: "127.0.0.1:8080", ids: []string{"127.0.0.1:8080"}, addresses: []string{"127.0.0.1:8080"}},
+		{
+			list:      "a@127.0.0.1:8080, b@127.0.0.1:8081,127.0.0.1:8082",
+			ids:       []string{"a", "b", "127.0.0.1:8082"},
+			addresses: []string{"127.0.0.1:8080", "127.0.0.1:8081", "127.0.0.1:8082"},
+		},
+		{list: "a@", err: true},
+		{list: "a@127.0.0.1:8080,a@127.0.0.1:8081", err: true},
+	} {
+		nodes, err := ParseBootstrapNodes(tt.list)
+		if tt.err {
+			assert.Error(t, err, tt.list)
+			continue
+		}
+		assert.NoError(t, err, tt.list)
+
+		var ids, addresses []string
+		for _, n := range nodes {
+			ids = append(ids, n.Id)
+			addresses = append(addresses, n.GetAddress().GetAddress())
+			assert.Equal(t, defaultTransport, n.GetAddress().GetTransport())
+		}
+		assert.Equal(t, tt.ids, ids, tt.list)
+		assert.Equal(t, tt.addresses, addresses, tt.list)
+	}
+}
+
+func TestBootstrapFallback(t *testing.T) {
+	bn, s, clean := testNode(t, []pb.Node{})
+	defer clean()
+	defer s.Stop()
+
+	unreachable := pb.Node{Id: "unreachable", Address: &pb.NodeAddress{Address: "127.0.0.1:1"}}
+
+	n, s1, clean1 := testNode(t, []pb.Node{unreachable, bn.routingTable.self})
+	defer clean1()
+	defer s1.Stop()
+
+	// the nodes are tried in random order, so the unreachable one is only
+	// reported when it's tried before the reachable one
+	reachable, failed, ok := n.reachableBootstrapNode(context.Background())
+	assert.True(t, ok)
+	assert.Equal(t, bn.routingTable.self.Id, reachable.Id)
+	for _, f := range failed {
+		assert.Equal(t, unreachable.Id, f.Id)
+	}
+
+	assert.NoError(t, n.Bootstrap(context.Background()))
+
+	// the lookup went through the reachable bootstrap node, which learned
+	// about n
+	ids, err := n.routingTable.nodeBucketDB.List(nil, 0)
+	assert.NoError(t, err)
+	assert.Contains(t, ids.Strings(), bn.routingTable.self.Id)
+
+	ids, err = bn.routingTable.nodeBucketDB.List(nil, 0)
+	assert.NoError(t, err)
+	assert.Contains(t, ids.Strings(), n.routingTable.self.Id)
+}
+
+func TestBootstrapUnreachable(t *testing.T) {
+	unreachable := []pb.Node{
+		{Id: "unreachable1", Address: &pb.NodeAddress{Address: "127.0.0.1:1"}},
+		{Id: "unreachable2", Address: &pb.NodeAddress{Address: "127.0.0.1:2"}},
+	}
+
+	n, s, clean := testNode(t, unreachable)
+	defer clean()
+	defer s.Stop()
+
+	err := n.Bootstrap(context.Background())
+	assert.True(t, BootstrapErr.Has(err))
+}
@@ -6,10 +6,14 @@ package kademlia
 import (
 	"context"
 	"flag"
+	"net"
+	"time"
 
 	"github.com/zeebo/errs"
+	"go.uber.org/zap"
 	monkit "gopkg.in/spacemonkeygo/monkit.v2"
 
+	"storj.io/storj/pkg/ban"
 	"storj.io/storj/pkg/node"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/provider"
@@ -30,6 +34,8 @@ var (
 	// TODO: replace these with constants after tuning
 	flagBucketSize           = flag.Int("kademlia-bucket-size", 20, "Size of each Kademlia bucket")
 	flagReplacementCacheSize = flag.Int("kademlia-replacement-cache-size", 5, "Size of Kademlia replacement cache")
+	flagAntechamberSize      = flag.Int("kademlia-antechamber-size", 20, "Max number of discovered nodes waiting to be vetted")
+	flagQueryTimeout         = flag.Duration("kademlia-query-timeout", 5*time.Second, "How long to wait for a node to answer a lookup query")
 )
 
 //CtxKey Used as kademlia key
@@ -42,11 +48,21 @@ const (
 // Config defines all of the things that are needed to start up Kademlia
 // server endpoints (and not necessarily client code).
 type Config struct {
-	BootstrapAddr string `help:"the kademlia node to bootstrap against" default:"bootstrap-dev.storj.io:8080"`
+	BootstrapAddr string `help:"comma separated list of kademlia nodes to bootstrap against, each optionally prefixed with its node id as id@address" default:"bootstrap-dev.storj.io:8080"`
 	DBPath        string `help:"the path for our db services to be created on" default:"$CONFDIR/kademlia"`
 	// TODO(jt): remove this! kademlia should just use the grpc server
 	TODOListenAddr string `help:"the host/port for kademlia to listen on. TODO(jt): this should be removed!" default:"127.0.0.1:7776"`
 	Alpha          int    `help:"alpha is a system wide concurrency parameter." default:"5"`
+	// RefreshInterval is also the age after which a bucket is considered stale
+	RefreshInterval time.Duration `help:"how often stale buckets are refreshed" default:"1h"`
+	// ExpectedDowntime is announced to the routing table contacts on shutdown
+	ExpectedDowntime time.Duration `help:"how long the node expects to be offline after shutting down, contacts are told on shutdown if set" default:"0s"`
+	// ExternalAddress is advertised instead of the listen address, e.g. when
+	// the node is behind port forwarding or dynamic DNS
+	ExternalAddress        string `help:"the host/port other nodes reach this node at, if different from the listen address" default:""`
+	ResolveExternalAddress bool   `help:"ask the bootstrap nodes for the public host of this node and advertise it with the port of the external or listen address" default:"false"`
+	// Ban is the ban list of the misbehaving peers, shared with the overlay
+	Ban ban.Config
 }
 
 // Run implements provider.Responsibility
@@ -55,22 +71,26 @@ func (c Config) Run(ctx context.Context, server *provider.Provider) (
 
 	defer mon.Task()(&ctx)(&err)
 
-	// TODO(coyle): I'm thinking we just remove  this function and grab from the config.
-	in, err := GetIntroNode(c.BootstrapAddr)
+	bootstrapNodes, err := ParseBootstrapNodes(c.BootstrapAddr)
+	if err != nil {
+		return err
+	}
+
+	advertised, err := c.advertisedAddress(ctx, server.Identity(), bootstrapNodes)
 	if err != nil {
 		return err
 	}
 
 	// TODO(jt): kademlia should register on server.GRPC() instead of listening
 	// itself
-	in.Id = "foo"
-	kad, err := NewKademlia(server.Identity().ID, []pb.Node{*in}, c.TODOListenAddr, server.Identity(), c.DBPath, c.Alpha)
+	bans := c.Ban.NewList()
+	kad, err := NewKademlia(server.Identity().ID, bootstrapNodes, advertised, server.Transport(), server.Identity(), c.DBPath, c.Alpha, bans)
 	if err != nil {
 		return err
 	}
 	defer func() { err = utils.CombineErrors(err, kad.Disconnect()) }()
 
-	mn := node.NewServer(kad)
+	mn := node.NewServer(kad, bans)
 	pb.RegisterNodesServer(server.GRPC(), mn)
 
 	// TODO(jt): Bootstrap should probably be blocking and we should kick it off
@@ -79,7 +99,53 @@ func (c Config) Run(ctx context.Context, server *provider.Provider) (
 		return err
 	}
 
-	return server.Run(context.WithValue(ctx, ctxKeyKad, kad))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		if err := kad.RunRefresh(ctx, c.RefreshInterval); err != nil && err != context.Canceled {
+			zap.L().Error("bucket refresh stopped", zap.Error(err))
+		}
+	}()
+
+	err = server.Run(context.WithValue(ctx, ctxKeyKad, kad))
+
+	if c.ExpectedDowntime > 0 {
+		// ctx is done by now, the announcement gets a fresh deadline
+		leaveCtx, leaveCancel := context.WithTimeout(context.Background(), *flagQueryTimeout)
+		defer leaveCancel()
+		if err := kad.Leave(leaveCtx, c.ExpectedDowntime); err != nil {
+			zap.L().Warn("could not announce downtime", zap.Error(err))
+		}
+	}
+
+	return err
+}
+
+// advertisedAddress returns the address the node announces to the network
+func (c Config) advertisedAddress(ctx context.Context, identity *provider.FullIdentity, bootstrapNodes []pb.Node) (string, error) {
+	advertised := c.TODOListenAddr
+	if c.ExternalAddress != "" {
+		if _, _, err := net.SplitHostPort(c.ExternalAddress); err != nil {
+			return "", Error.New("invalid external address %q: %v", c.ExternalAddress, err)
+		}
+		advertised = c.ExternalAddress
+	}
+	if !c.ResolveExternalAddress {
+		return advertised, nil
+	}
+
+	client, err := node.NewNodeClient(identity, pb.Node{}, nil, nil)
+	if err != nil {
+		return "", Error.Wrap(err)
+	}
+	defer func() { _ = client.Disconnect() }()
+
+	resolved, err := resolveExternalAddress(ctx, client, bootstrapNodes, advertised)
+	if err != nil {
+		return "", err
+	}
+	zap.L().Info("resolved external address", zap.String("Address", resolved))
+	return resolved, nil
 }
 
 // LoadFromContext loads an existing Kademlia from the Provider context
@@ -9,16 +9,20 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 
+	"storj.io/storj/internal/sync2"
+	"storj.io/storj/pkg/ban"
 	"storj.io/storj/pkg/dht"
 	"storj.io/storj/pkg/node"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/provider"
+	"storj.io/storj/pkg/transport"
 	"storj.io/storj/pkg/utils"
 	"storj.io/storj/storage"
 	"storj.io/storj/storage/boltdb"
@@ -40,6 +44,8 @@ type discoveryOptions struct {
 	concurrency int
 	retries     int
 	bootstrap   bool
+	k           int           // number of closest nodes the lookup converges on
+	timeout     time.Duration // per query, zero for none
 }
 
 // Kademlia is an implementation of kademlia adhering to the DHT interface.
@@ -50,11 +56,19 @@ type Kademlia struct {
 	address        string
 	nodeClient     node.Client
 	identity       *provider.FullIdentity
+	antechamber    *antechamber
+	observers      transport.Observers
+	bans           *ban.List
 }
 
-// NewKademlia returns a newly configured Kademlia instance
-func NewKademlia(id dht.NodeID, bootstrapNodes []pb.Node, address string, identity *provider.FullIdentity, path string, alpha int) (*Kademlia, error) {
-	self := pb.Node{Id: id.String(), Address: &pb.NodeAddress{Address: address}}
+// NewKademlia returns a newly configured Kademlia instance, which announces
+// that it's reachable at address with transport, and doesn't add or dial
+// the nodes banned by bans
+func NewKademlia(id dht.NodeID, bootstrapNodes []pb.Node, address string, transport pb.NodeTransport, identity *provider.FullIdentity, path string, alpha int, bans *ban.List) (*Kademlia, error) {
+	self := pb.Node{Id: id.String(), Address: &pb.NodeAddress{Transport: transport, Address: address}, Version: node.Version}
+	if err := node.SignNode(&self, identity); err != nil {
+		return nil, BootstrapErr.Wrap(err)
+	}
 
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		if err := os.MkdirAll(path, 0777); err != nil {
@@ -75,6 +89,7 @@ func NewKademlia(id dht.NodeID, bootstrapNodes []pb.Node, address string, identi
 	if err != nil {
 		return nil, BootstrapErr.Wrap(err)
 	}
+	rt.bans = bans
 
 	return NewKademliaWithRoutingTable(self, bootstrapNodes, identity, alpha, rt)
 }
@@ -97,9 +112,11 @@ func NewKademliaWithRoutingTable(self pb.Node, bootstrapNodes []pb.Node, identit
 		bootstrapNodes: bootstrapNodes,
 		address:        self.Address.Address,
 		identity:       identity,
+		antechamber:    newAntechamber(*flagAntechamberSize),
+		bans:           rt.bans,
 	}
 
-	nc, err := node.NewNodeClient(identity, self, k)
+	nc, err := node.NewNodeClient(identity, self, k, k.bans, &k.observers)
 	if err != nil {
 		return nil, BootstrapErr.Wrap(err)
 	}
@@ -117,6 +134,17 @@ func (k *Kademlia) Disconnect() error {
 	)
 }
 
+// Bans returns the ban list of the nodes kademlia doesn't add or dial
+func (k *Kademlia) Bans() *ban.List {
+	return k.bans
+}
+
+// AddObserver adds obs to the observers notified of the nodes kademlia could
+// or couldn't connect to
+func (k *Kademlia) AddObserver(obs transport.Observer) {
+	k.observers.Add(obs)
+}
+
 // GetNodes returns all nodes from a starting node up to a maximum limit
 // stored in the local routing table limiting the result by the specified restrictions
 func (k *Kademlia) GetNodes(ctx context.Context, start string, limit int, restrictions ...pb.Restriction) ([]*pb.Node, error) {
@@ -160,34 +188,108 @@ func (k *Kademlia) GetRoutingTable(ctx context.Context) (dht.RoutingTable, error
 }
 
 // Bootstrap contacts one of a set of pre defined trusted nodes on the network and
-// begins populating the local Kademlia node
+// begins populating the local Kademlia node. Contacts persisted by a previous
+// run are re-checked first and used alongside the bootstrap nodes. The
+// bootstrap nodes are tried in random order, and the lookup starts from the
+// first one which responds, so it fails if none of them do and there are no
+// contacts to start from instead.
 func (k *Kademlia) Bootstrap(ctx context.Context) error {
+	restored, err := k.restore(ctx)
+	if err != nil {
+		return BootstrapErr.Wrap(err)
+	}
+
 	// What I want to do here is do a normal lookup for myself
 	// so call lookup(ctx, nodeImLookingFor)
-	if len(k.bootstrapNodes) == 0 {
+	if len(k.bootstrapNodes) == 0 && restored == 0 {
 		return BootstrapErr.New("no bootstrap nodes provided")
 	}
 
-	return k.lookup(ctx, node.IDFromString(k.routingTable.self.GetId()), discoveryOptions{
-		concurrency: k.alpha, retries: defaultRetries, bootstrap: true,
-	})
+	target := node.IDFromString(k.routingTable.self.GetId())
+	if len(k.bootstrapNodes) == 0 {
+		if _, err := k.discover(ctx, target, k.discoveryOptions(true)); err != nil {
+			return BootstrapErr.Wrap(err)
+		}
+		return nil
+	}
+
+	reachable, unreachable, ok := k.reachableBootstrapNode(ctx)
+	if err := ctx.Err(); err != nil {
+		return BootstrapErr.Wrap(err)
+	}
+	for i := range unreachable {
+		if err := k.routingTable.ConnectionFailed(&unreachable[i]); err != nil {
+			return BootstrapErr.Wrap(err)
+		}
+	}
+
+	var first *pb.Node
+	if ok {
+		first = &reachable
+	} else {
+		if restored == 0 {
+			return BootstrapErr.New("none of the %d bootstrap nodes could be reached", len(k.bootstrapNodes))
+		}
+		zap.L().Warn("none of the bootstrap nodes could be reached, bootstrapping from the restored contacts",
+			zap.Int("BootstrapNodes", len(k.bootstrapNodes)),
+			zap.Int("RestoredContacts", restored))
+	}
+
+	start, err := k.bootstrapStart(target, first)
+	if err != nil {
+		return BootstrapErr.Wrap(err)
+	}
+	if _, err := k.discoverFrom(ctx, target, start, k.discoveryOptions(true)); err != nil {
+		return BootstrapErr.Wrap(err)
+	}
+	return nil
+}
+
+// discoveryOptions returns the options lookups are run with
+func (k *Kademlia) discoveryOptions(bootstrap bool) discoveryOptions {
+	return discoveryOptions{
+		concurrency: k.alpha,
+		retries:     defaultRetries,
+		bootstrap:   bootstrap,
+		k:           k.routingTable.K(),
+		timeout:     *flagQueryTimeout,
+	}
 }
 
 func (k *Kademlia) lookup(ctx context.Context, target dht.NodeID, opts discoveryOptions) error {
+	_, err := k.discover(ctx, target, opts)
+	if err != nil {
+		zap.L().Warn("lookup failed", zap.Error(err))
+	}
+	return nil
+}
+
+// discover searches the network for target, starting from the closest nodes
+// in the routing table, and returns the target node if it was found
+func (k *Kademlia) discover(ctx context.Context, target dht.NodeID, opts discoveryOptions) (*pb.Node, error) {
 	kb := k.routingTable.K()
 	// look in routing table for targetID
 	nodes, err := k.routingTable.FindNear(target, kb)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return k.discoverFrom(ctx, target, nodes, opts)
+}
 
+// discoverFrom searches the network for target like discover, starting from
+// nodes
+func (k *Kademlia) discoverFrom(ctx context.Context, target dht.NodeID, nodes []*pb.Node, opts discoveryOptions) (*pb.Node, error) {
 	lookup := newPeerDiscovery(nodes, k.nodeClient, target, opts)
-	err = lookup.Run(ctx)
-	if err != nil {
-		zap.L().Warn("lookup failed", zap.Error(err))
+	lookup.discovered = k.antechamber.add
+	found, err := lookup.Run(ctx)
+
+	// nodes discovered during the lookup only join the routing table once
+	// they have been vetted
+	if err := k.vet(ctx); err != nil {
+		zap.L().Warn("vetting discovered nodes failed", zap.Error(err))
 	}
 
-	return nil
+	return found, err
 }
 
 // Ping checks that the provided node is still accessible on the network
@@ -204,11 +306,52 @@ func (k *Kademlia) Ping(ctx context.Context, node pb.Node) (pb.Node, error) {
 	return node, nil
 }
 
+// Leave tells the contacts in the routing table that this node is going
+// offline for about downtime, so that they don't evict it in the meantime.
+// Contacts that can't be reached are skipped.
+func (k *Kademlia) Leave(ctx context.Context, downtime time.Duration) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	nodes, err := k.GetNodes(ctx, "", storage.LookupLimit)
+	if err != nil {
+		return err
+	}
+
+	limiter := sync2.NewLimiter(k.alpha)
+	for _, n := range nodes {
+		if n.GetId() == k.routingTable.self.GetId() {
+			continue
+		}
+		n := n
+		limiter.Go(ctx, func() {
+			if err := k.nodeClient.Leave(ctx, *n, downtime); err != nil {
+				zap.L().Debug("could not announce downtime", zap.String("NodeID", n.GetId()), zap.Error(err))
+			}
+		})
+	}
+	limiter.Wait()
+	return nil
+}
+
 // FindNode looks up the provided NodeID first in the local Node, and if it is not found
 // begins searching the network for the NodeID. Returns and error if node was not found
 func (k *Kademlia) FindNode(ctx context.Context, ID dht.NodeID) (pb.Node, error) {
-	// TODO(coyle)
-	return pb.Node{}, NodeErr.New("TODO FindNode")
+	nodes, err := k.routingTable.FindNear(ID, 1)
+	if err != nil {
+		return pb.Node{}, NodeErr.Wrap(err)
+	}
+	if len(nodes) > 0 && nodes[0].GetId() == ID.String() {
+		return *nodes[0], nil
+	}
+
+	found, err := k.discover(ctx, ID, k.discoveryOptions(false))
+	if err != nil {
+		return pb.Node{}, NodeErr.Wrap(err)
+	}
+	if found == nil {
+		return pb.Node{}, NodeNotFound
+	}
+	return *found, nil
 }
 
 // ListenAndServe connects the kademlia node to the network and listens for incoming requests
@@ -219,7 +362,7 @@ func (k *Kademlia) ListenAndServe() error {
 	}
 
 	grpcServer := grpc.NewServer(identOpt)
-	mn := node.NewServer(k)
+	mn := node.NewServer(k, k.bans)
 
 	pb.RegisterNodesServer(grpcServer, mn)
 	lis, err := net.Listen("tcp", k.address)
@@ -12,11 +12,14 @@ import (
 	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 
+	"storj.io/storj/pkg/ban"
 	"storj.io/storj/pkg/dht"
 	"storj.io/storj/pkg/node"
 	"storj.io/storj/pkg/pb"
@@ -69,7 +72,7 @@ func TestNewKademlia(t *testing.T) {
 		identity, err := ca.NewIdentity()
 		assert.NoError(t, err)
 
-		kad, err := NewKademlia(v.id, v.bn, v.addr, identity, dir, defaultAlpha)
+		kad, err := NewKademlia(v.id, v.bn, v.addr, defaultTransport, identity, dir, defaultAlpha, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, v.expectedErr, err)
 		assert.Equal(t, kad.bootstrapNodes, v.bn)
@@ -105,7 +108,7 @@ func TestPeerDiscovery(t *testing.T) {
 		assert.NotEqual(t, id, id2)
 
 		kid := dht.NodeID(fid.ID)
-		k, err := NewKademlia(kid, []pb.Node{pb.Node{Id: id2.String(), Address: &pb.NodeAddress{Address: lis.Addr().String()}}}, lis.Addr().String(), fid, dir, defaultAlpha)
+		k, err := NewKademlia(kid, []pb.Node{pb.Node{Id: id2.String(), Address: &pb.NodeAddress{Address: lis.Addr().String()}}}, lis.Addr().String(), defaultTransport, fid, dir, defaultAlpha, nil)
 		assert.NoError(t, err)
 		return k
 	}()
@@ -174,6 +177,46 @@ func TestBootstrap(t *testing.T) {
 	assert.Len(t, nodeIDs, 3)
 }
 
+func TestRestore(t *testing.T) {
+	live, s, clean := testNode(t, []pb.Node{})
+	defer clean()
+	defer s.Stop()
+
+	fid, err := newTestIdentity()
+	assert.NoError(t, err)
+	deadFid, err := newTestIdentity()
+	assert.NoError(t, err)
+	dead := pb.Node{Id: deadFid.ID.String(), Address: &pb.NodeAddress{Address: "127.0.0.1:1"}}
+
+	dir, cleanup := mktempdir(t, "kademlia")
+	defer cleanup()
+
+	id := dht.NodeID(fid.ID)
+	k, err := NewKademlia(id, []pb.Node{}, "127.0.0.1:0", defaultTransport, fid, dir, defaultAlpha, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, k.routingTable.ConnectionSuccess(&live.routingTable.self))
+	assert.NoError(t, k.routingTable.ConnectionSuccess(&dead))
+	assert.NoError(t, k.Disconnect())
+
+	// restart using the same database
+	k, err = NewKademlia(id, []pb.Node{}, "127.0.0.1:0", defaultTransport, fid, dir, defaultAlpha, nil)
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, k.Disconnect()) }()
+
+	nodeIDs, err := k.routingTable.nodeBucketDB.List(nil, 0)
+	assert.NoError(t, err)
+	assert.Len(t, nodeIDs, 3)
+
+	alive, err := k.restore(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, alive)
+
+	// only self and the live node remain
+	nodeIDs, err = k.routingTable.nodeBucketDB.List(nil, 0)
+	assert.NoError(t, err)
+	assert.Len(t, nodeIDs, 2)
+}
+
 func testNode(t *testing.T, bn []pb.Node) (*Kademlia, *grpc.Server, func()) {
 	// new address
 	lis, err := net.Listen("tcp", "127.0.0.1:0")
@@ -186,9 +229,9 @@ func testNode(t *testing.T, bn []pb.Node) (*Kademlia, *grpc.Server, func()) {
 	// new kademlia
 	dir, cleanup := mktempdir(t, "kademlia")
 
-	k, err := NewKademlia(id, bn, lis.Addr().String(), fid, dir, defaultAlpha)
+	k, err := NewKademlia(id, bn, lis.Addr().String(), defaultTransport, fid, dir, defaultAlpha, ban.NewList(3, time.Hour, false))
 	assert.NoError(t, err)
-	s := node.NewServer(k)
+	s := node.NewServer(k, k.Bans())
 	// new ident opts
 	identOpt, err := fid.ServerOption()
 	assert.NoError(t, err)
@@ -229,7 +272,7 @@ func TestGetNodes(t *testing.T) {
 
 	dir, cleanup := mktempdir(t, "kademlia")
 	defer cleanup()
-	k, err := NewKademlia(kid, []pb.Node{pb.Node{Id: id2.String(), Address: &pb.NodeAddress{Address: lis.Addr().String()}}}, lis.Addr().String(), fid, dir, defaultAlpha)
+	k, err := NewKademlia(kid, []pb.Node{pb.Node{Id: id2.String(), Address: &pb.NodeAddress{Address: lis.Addr().String()}}}, lis.Addr().String(), defaultTransport, fid, dir, defaultAlpha, nil)
 	assert.NoError(t, err)
 	defer func() {
 		assert.NoError(t, k.Disconnect())
@@ -242,7 +285,8 @@ func TestGetNodes(t *testing.T) {
 	nodes := []*pb.Node{}
 	for i, v := range ids {
 		n := &pb.Node{
-			Id: v,
+			Id:       v,
+			SignedAt: ptypes.TimestampNow(),
 			Restrictions: &pb.NodeRestrictions{
 				FreeBandwidth: bw[i],
 				FreeDisk:      disk[i],
@@ -5,6 +5,7 @@ package kademlia
 
 import (
 	"context"
+	"math/big"
 	"sync"
 
 	"github.com/zeebo/errs"
@@ -21,13 +22,25 @@ type peerDiscovery struct {
 
 	cond  sync.Cond
 	queue *XorQueue
+
+	// protected by `cond.L`
+	responded []*item // nodes that answered, closest to the target first
+	found     *pb.Node
+
+	// discovered, if set, is called with the neighbors returned by every
+	// contacted node
+	discovered func(nodes ...*pb.Node)
 }
 
 // ErrMaxRetries is used when a lookup has been retried the max number of times
 var ErrMaxRetries = errs.Class("max retries exceeded for id:")
 
 func newPeerDiscovery(nodes []*pb.Node, client node.Client, target dht.NodeID, opts discoveryOptions) *peerDiscovery {
-	queue := NewXorQueue(opts.concurrency)
+	if opts.k <= 0 {
+		opts.k = opts.concurrency
+	}
+
+	queue := NewXorQueue(opts.k)
 	queue.Insert(target, nodes)
 
 	return &peerDiscovery{
@@ -40,7 +53,11 @@ func newPeerDiscovery(nodes []*pb.Node, client node.Client, target dht.NodeID, o
 	}
 }
 
-func (lookup *peerDiscovery) Run(ctx context.Context) error {
+// Run queries up to opts.concurrency nodes at a time for the target until
+// the lookup converges, i.e. the k closest nodes that were seen have all
+// answered, or the target is found when not bootstrapping. It returns the
+// target node if it was found.
+func (lookup *peerDiscovery) Run(ctx context.Context) (*pb.Node, error) {
 	wg := sync.WaitGroup{}
 
 	// protected by `lookup.cond.L`
@@ -53,7 +70,8 @@ func (lookup *peerDiscovery) Run(ctx context.Context) error {
 			defer wg.Done()
 			for {
 				var (
-					next *pb.Node
+					next     *pb.Node
+					distance big.Int
 				)
 
 				lookup.cond.L.Lock()
@@ -64,23 +82,40 @@ func (lookup *peerDiscovery) Run(ctx context.Context) error {
 						return
 					}
 
-					next, _ = lookup.queue.Closest()
-					if !lookup.opts.bootstrap && next.GetId() == lookup.target.String() {
-						allDone = true
-						break // closest node is the target and is already in routing table (i.e. no lookup required)
+					next, distance = lookup.queue.Peek()
+					if next != nil && lookup.converged(&distance) {
+						next = nil
 					}
 
 					if next != nil {
+						lookup.queue.Closest()
+						if !lookup.opts.bootstrap && next.GetId() == lookup.target.String() {
+							// closest node is the target and is already in routing table (i.e. no lookup required)
+							lookup.found = next
+							allDone = true
+							lookup.cond.Broadcast()
+							lookup.cond.L.Unlock()
+							return
+						}
 						working++
 						break
 					}
 
+					// nothing left to query and nobody can add more work
+					if working == 0 {
+						allDone = true
+						lookup.cond.Broadcast()
+						continue
+					}
+
 					// no work, wait until some other routine inserts into the queue
 					lookup.cond.Wait()
 				}
 				lookup.cond.L.Unlock()
 
-				neighbors, err := lookup.client.Lookup(ctx, *next, pb.Node{Id: lookup.target.String()})
+				neighbors, err := lookup.query(ctx, next)
+
+				lookup.cond.L.Lock()
 				if err != nil {
 					ok := lookup.queue.Reinsert(lookup.target, next, lookup.opts.retries)
 					if !ok {
@@ -91,13 +126,22 @@ func (lookup *peerDiscovery) Run(ctx context.Context) error {
 							err.Error(),
 						)
 					}
+				} else {
+					lookup.respond(next, &distance)
+					if lookup.discovered != nil && len(neighbors) > 0 {
+						lookup.discovered(neighbors...)
+					}
+					for _, n := range neighbors {
+						if n.GetId() == lookup.target.String() {
+							lookup.found = n
+							allDone = allDone || !lookup.opts.bootstrap
+						}
+					}
+					lookup.queue.Insert(lookup.target, neighbors)
 				}
 
-				lookup.queue.Insert(lookup.target, neighbors)
-
-				lookup.cond.L.Lock()
 				working--
-				allDone = allDone || isDone(ctx) || working == 0 && lookup.queue.Len() == 0
+				allDone = allDone || isDone(ctx)
 				lookup.cond.L.Unlock()
 				lookup.cond.Broadcast()
 			}
@@ -105,7 +149,50 @@ func (lookup *peerDiscovery) Run(ctx context.Context) error {
 	}
 
 	wg.Wait()
-	return ctx.Err()
+
+	lookup.cond.L.Lock()
+	defer lookup.cond.L.Unlock()
+	return lookup.found, ctx.Err()
+}
+
+// query asks next for the neighbors of the target, giving up after
+// opts.timeout so that a single slow node can't stall the lookup
+func (lookup *peerDiscovery) query(ctx context.Context, next *pb.Node) ([]*pb.Node, error) {
+	if lookup.opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, lookup.opts.timeout)
+		defer cancel()
+	}
+	return lookup.client.Lookup(ctx, *next, pb.Node{Id: lookup.target.String()})
+}
+
+// respond records that n, at distance from the target, answered a query.
+// Only the k closest nodes are kept. Must hold `cond.L`.
+func (lookup *peerDiscovery) respond(n *pb.Node, distance *big.Int) {
+	i := 0
+	for i < len(lookup.responded) && lookup.responded[i].priority.Cmp(distance) < 0 {
+		i++
+	}
+	if i >= lookup.opts.k {
+		return
+	}
+
+	lookup.responded = append(lookup.responded, nil)
+	copy(lookup.responded[i+1:], lookup.responded[i:])
+	lookup.responded[i] = &item{value: n, priority: new(big.Int).Set(distance)}
+	if len(lookup.responded) > lookup.opts.k {
+		lookup.responded = lookup.responded[:lookup.opts.k]
+	}
+}
+
+// converged returns whether k nodes closer than distance have already
+// answered, in which case querying nodes at distance can't improve the
+// result. Must hold `cond.L`.
+func (lookup *peerDiscovery) converged(distance *big.Int) bool {
+	if len(lookup.responded) < lookup.opts.k {
+		return false
+	}
+	return lookup.responded[lookup.opts.k-1].priority.Cmp(distance) < 0
 }
 
 func isDone(ctx context.Context) bool {
@@ -0,0 +1,116 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package kademlia
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/pb"
+)
+
+type fakeNodeClient struct {
+	mu        sync.Mutex
+	neighbors map[string][]*pb.Node
+	slow      map[string]bool
+	queried   map[string]int
+	external  map[string]string
+}
+
+func (c *fakeNodeClient) Lookup(ctx context.Context, to pb.Node, find pb.Node) ([]*pb.Node, error) {
+	c.mu.Lock()
+	c.queried[to.Id]++
+	slow := c.slow[to.Id]
+	c.mu.Unlock()
+
+	if slow {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return c.neighbors[to.Id], nil
+}
+
+func (c *fakeNodeClient) Ping(ctx context.Context, to pb.Node) (bool, error) { return true, nil }
+
+func (c *fakeNodeClient) Leave(ctx context.Context, to pb.Node, downtime time.Duration) error {
+	return nil
+}
+
+func (c *fakeNodeClient) ExternalAddress(ctx context.Context, to pb.Node) (string, error) {
+	address, ok := c.external[to.Id]
+	if !ok {
+		return "", errs.New("node %s is unreachable", to.Id)
+	}
+	return address, nil
+}
+
+func (c *fakeNodeClient) Disconnect() error { return nil }
+
+func TestPeerDiscoveryRun(t *testing.T) {
+	target := node.ID(BinStr("00000000"))
+	a := &pb.Node{Id: BinStr("00000001")}
+	b := &pb.Node{Id: BinStr("00000010")}
+	far1 := &pb.Node{Id: BinStr("10000000")}
+	far2 := &pb.Node{Id: BinStr("10000001")}
+	slow := &pb.Node{Id: BinStr("00000011")}
+
+	for _, tt := range []struct {
+		name      string
+		start     []*pb.Node
+		neighbors map[string][]*pb.Node
+		opts      discoveryOptions
+		found     *pb.Node
+		queried   map[string]int
+	}{
+		{name: "converges on the k closest nodes",
+			start: []*pb.Node{a, b},
+			neighbors: map[string][]*pb.Node{
+				a.Id: {far1, far2},
+				b.Id: {far1},
+			},
+			opts:    discoveryOptions{concurrency: 1, retries: 1, bootstrap: true, k: 2},
+			queried: map[string]int{a.Id: 1, b.Id: 1},
+		},
+		{name: "queries further nodes until k have answered",
+			start: []*pb.Node{a, b},
+			neighbors: map[string][]*pb.Node{
+				a.Id: {far1},
+			},
+			opts:    discoveryOptions{concurrency: 2, retries: 1, bootstrap: true, k: 3},
+			queried: map[string]int{a.Id: 1, b.Id: 1, far1.Id: 1},
+		},
+		{name: "slow nodes time out",
+			start:   []*pb.Node{slow, far1},
+			opts:    discoveryOptions{concurrency: 2, retries: 1, bootstrap: true, k: 2, timeout: 10 * time.Millisecond},
+			queried: map[string]int{slow.Id: 1, far1.Id: 1},
+		},
+		{name: "stops once the target is found",
+			start: []*pb.Node{far1},
+			neighbors: map[string][]*pb.Node{
+				far1.Id: {{Id: target.String(), Address: &pb.NodeAddress{Address: "127.0.0.1:1"}}, far2},
+			},
+			opts:    discoveryOptions{concurrency: 1, retries: 1, k: 2},
+			found:   &pb.Node{Id: target.String(), Address: &pb.NodeAddress{Address: "127.0.0.1:1"}},
+			queried: map[string]int{far1.Id: 1},
+		},
+	} {
+		client := &fakeNodeClient{
+			neighbors: tt.neighbors,
+			slow:      map[string]bool{slow.Id: true},
+			queried:   map[string]int{},
+		}
+
+		lookup := newPeerDiscovery(tt.start, client, &target, tt.opts)
+		found, err := lookup.Run(context.Background())
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.found, found, tt.name)
+		assert.Equal(t, tt.queried, client.queried, tt.name)
+	}
+}
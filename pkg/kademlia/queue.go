@@ -96,6 +96,18 @@ func (x *XorQueue) Closest() (*pb.Node, big.Int) {
 	return item.value, *item.priority
 }
 
+// Peek returns the closest priority node without removing it from the queue
+func (x *XorQueue) Peek() (*pb.Node, big.Int) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.Len() == 0 {
+		return nil, big.Int{}
+	}
+	item := x.items[0]
+	return item.value, *item.priority
+}
+
 // Len returns the number of items in the queue
 func (x *XorQueue) Len() int {
 	return x.items.Len()
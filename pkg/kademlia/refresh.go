@@ -0,0 +1,185 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package kademlia
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"storj.io/storj/internal/sync2"
+	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/storage"
+)
+
+// RunRefresh refreshes stale buckets every interval until ctx is canceled.
+// A bucket is stale when it hasn't been updated for longer than interval.
+func (k *Kademlia) RunRefresh(ctx context.Context, interval time.Duration) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := k.refresh(ctx, interval); err != nil {
+				zap.L().Error("bucket refresh failed", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// refresh pings the contacts of every bucket that hasn't been updated within
+// threshold, evicting the ones that are dead (which promotes contacts from the
+// replacement cache), and then looks up a random id within the bucket to
+// discover new contacts in that part of the key space. Full buckets with nodes waiting
+// in their replacement cache have their least recently seen contact
+// challenged regardless of the threshold.
+func (k *Kademlia) refresh(ctx context.Context, threshold time.Duration) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	rt := k.routingTable
+	bucketIDs, err := allKeys(rt.kadBucketDB)
+	if err != nil {
+		return RoutingErr.New("could not list k bucket ids: %s", err)
+	}
+
+	now := time.Now()
+	for _, bucketID := range bucketIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if rt.hasReplacements(bucketID) {
+			if err := k.challengeLeastRecentlySeen(ctx, bucketID); err != nil {
+				return err
+			}
+		}
+
+		updated, err := rt.GetBucketTimestamp(string(bucketID), nil)
+		if err != nil {
+			return err
+		}
+		if now.Sub(updated) < threshold {
+			continue
+		}
+
+		nodes, err := rt.getUnmarshaledNodesFromBucket(bucketID)
+		if err != nil {
+			return err
+		}
+
+		var contacts []*pb.Node
+		for _, n := range nodes {
+			if n.GetId() != rt.self.GetId() {
+				contacts = append(contacts, n)
+			}
+		}
+
+		live, dead := k.pingAll(ctx, contacts)
+		for _, n := range dead {
+			if err := rt.ConnectionFailed(n); err != nil {
+				return err
+			}
+		}
+		for _, n := range live {
+			if err := rt.ConnectionSuccess(n); err != nil {
+				return err
+			}
+		}
+
+		// the lookup is made even if no contact of the bucket is left, as
+		// finding new ones is what the bucket needs most then
+		target, err := rt.randomIDInBucket(bucketID)
+		if err != nil {
+			return err
+		}
+		// lookup logs its failures, which don't stop the other buckets
+		_ = k.lookup(ctx, node.IDFromString(string(target)), k.discoveryOptions(true))
+
+		if err := rt.SetBucketTimestamp(string(bucketID), time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// randomIDInBucket returns a random id within the range of node ids of the
+// bucket, which is the range after the id of the previous bucket up to and
+// including bucketID
+func (rt *RoutingTable) randomIDInBucket(bucketID storage.Key) (storage.Key, error) {
+	bucketRange, err := rt.getKBucketRange(bucketID)
+	if err != nil {
+		return nil, err
+	}
+	low := new(big.Int).SetBytes(bucketRange[0])
+	high := new(big.Int).SetBytes(bucketRange[1])
+
+	// the range excludes low, so a random offset in [0, high-low) is added
+	// to low+1
+	width := new(big.Int).Sub(high, low)
+	if width.Sign() <= 0 {
+		return storage.CloneKey(bucketID), nil
+	}
+	offset, err := rand.Int(rand.Reader, width)
+	if err != nil {
+		return nil, RoutingErr.Wrap(err)
+	}
+	id := offset.Add(offset, low).Add(offset, big.NewInt(1))
+	return id.FillBytes(make([]byte, len(bucketID))), nil
+}
+
+// challengeLeastRecentlySeen pings the least recently seen contact of the
+// bucket. A contact that doesn't respond is evicted in favor of the most
+// recently seen node of the replacement cache; one that responds is kept, so
+// that long lived contacts can't be pushed out by a burst of new node ids.
+func (k *Kademlia) challengeLeastRecentlySeen(ctx context.Context, bucketID storage.Key) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	rt := k.routingTable
+	oldest, err := rt.leastRecentlySeen(bucketID)
+	if err != nil || oldest == nil {
+		return err
+	}
+
+	ok, err := k.nodeClient.Ping(ctx, *oldest)
+	if err != nil || !ok {
+		zap.L().Debug("evicting unresponsive contact", zap.String("NodeID", oldest.GetId()))
+		return rt.ConnectionFailed(oldest)
+	}
+	return rt.ConnectionSuccess(oldest)
+}
+
+// pingAll concurrently pings nodes, at most alpha at a time, and splits them
+// by whether they responded.
+func (k *Kademlia) pingAll(ctx context.Context, nodes []*pb.Node) (live, dead []*pb.Node) {
+	var mu sync.Mutex
+
+	limiter := sync2.NewLimiter(k.alpha)
+	for _, n := range nodes {
+		n := n
+		limiter.Go(ctx, func() {
+			ok, err := k.nodeClient.Ping(ctx, *n)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil || !ok {
+				dead = append(dead, n)
+				return
+			}
+			live = append(live, n)
+		})
+	}
+	limiter.Wait()
+
+	return live, dead
+}
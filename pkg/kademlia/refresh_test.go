@@ -0,0 +1,93 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package kademlia
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/dht"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/storage"
+)
+
+func TestRefresh(t *testing.T) {
+	live, s, clean := testNode(t, []pb.Node{})
+	defer clean()
+	defer s.Stop()
+
+	fid, err := newTestIdentity()
+	assert.NoError(t, err)
+	deadFid, err := newTestIdentity()
+	assert.NoError(t, err)
+	dead := pb.Node{Id: deadFid.ID.String(), Address: &pb.NodeAddress{Address: "127.0.0.1:1"}}
+
+	dir, cleanup := mktempdir(t, "kademlia")
+	defer cleanup()
+
+	k, err := NewKademlia(dht.NodeID(fid.ID), []pb.Node{}, "127.0.0.1:0", defaultTransport, fid, dir, defaultAlpha, nil)
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, k.Disconnect()) }()
+
+	assert.NoError(t, k.routingTable.ConnectionSuccess(&live.routingTable.self))
+	assert.NoError(t, k.routingTable.ConnectionSuccess(&dead))
+
+	// buckets that were updated recently are left alone
+	assert.NoError(t, k.refresh(context.Background(), time.Hour))
+	nodeIDs, err := k.routingTable.nodeBucketDB.List(nil, 0)
+	assert.NoError(t, err)
+	assert.Len(t, nodeIDs, 3)
+
+	bucketIDs, err := k.routingTable.kadBucketDB.List(nil, 0)
+	assert.NoError(t, err)
+	stale := time.Now().Add(-2 * time.Hour)
+	for _, id := range bucketIDs {
+		assert.NoError(t, k.routingTable.SetBucketTimestamp(string(id), stale))
+	}
+
+	assert.NoError(t, k.refresh(context.Background(), time.Hour))
+	nodeIDs, err = k.routingTable.nodeBucketDB.List(nil, 0)
+	assert.NoError(t, err)
+	assert.Len(t, nodeIDs, 2)
+	assert.NotContains(t, nodeIDs.Strings(), dead.Id)
+
+	for _, id := range bucketIDs {
+		updated, err := k.routingTable.GetBucketTimestamp(string(id), nil)
+		assert.NoError(t, err)
+		assert.True(t, updated.After(stale))
+	}
+}
+
+func TestRandomIDInBucket(t *testing.T) {
+	rt, cleanup := createRoutingTable(t, nil)
+	defer cleanup()
+	idA := []byte{255, 255}
+	idB := []byte{127, 255}
+	idC := []byte{127, 254}
+	assert.NoError(t, rt.kadBucketDB.Put(idA, []byte("")))
+	assert.NoError(t, rt.kadBucketDB.Put(idB, []byte("")))
+	assert.NoError(t, rt.kadBucketDB.Put(idC, []byte("")))
+
+	for i := 0; i < 100; i++ {
+		id, err := rt.randomIDInBucket(idA)
+		assert.NoError(t, err)
+		assert.Len(t, id, 2)
+		assert.True(t, bytes.Compare(id, idB) > 0)
+		assert.True(t, bytes.Compare(id, idA) <= 0)
+
+		id, err = rt.randomIDInBucket(idC)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Compare(id, rt.createZeroAsStorageKey()) > 0)
+		assert.True(t, bytes.Compare(id, idC) <= 0)
+	}
+
+	// a bucket holding a single id can only be looked up by it
+	id, err := rt.randomIDInBucket(idB)
+	assert.NoError(t, err)
+	assert.Equal(t, storage.Key(idB), id)
+}
@@ -4,13 +4,24 @@
 package kademlia
 
 import (
+	"time"
+
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/storage"
 )
 
+// addToReplacementCache adds node to the replacement cache of the bucket, or
+// moves it to the end if it is already cached. The end of the cache holds the
+// most recently seen node, which is the first one promoted into the bucket.
 func (rt *RoutingTable) addToReplacementCache(kadBucketID storage.Key, node *pb.Node) {
 	bucketID := string(kadBucketID)
 	nodes := rt.replacementCache[bucketID]
+	for i, n := range nodes {
+		if n.Id == node.Id {
+			nodes = append(nodes[:i], nodes[i+1:]...)
+			break
+		}
+	}
 	nodes = append(nodes, node)
 	if len(nodes) > rt.rcBucketSize {
 		copy(nodes, nodes[1:])
@@ -18,3 +29,44 @@ func (rt *RoutingTable) addToReplacementCache(kadBucketID storage.Key, node *pb.
 	}
 	rt.replacementCache[bucketID] = nodes
 }
+
+// hasReplacements returns whether the replacement cache of the bucket holds
+// nodes waiting for a slot in the bucket
+func (rt *RoutingTable) hasReplacements(kadBucketID storage.Key) bool {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	return len(rt.replacementCache[string(kadBucketID)]) > 0
+}
+
+// markSeen records a successful contact with the node
+func (rt *RoutingTable) markSeen(nodeID string) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.lastSeen[nodeID] = time.Now()
+}
+
+// leastRecentlySeen returns the contact of the bucket, other than the local
+// node, that was successfully contacted the longest time ago. Contacts that
+// haven't been seen since startup are considered the oldest.
+func (rt *RoutingTable) leastRecentlySeen(kadBucketID storage.Key) (*pb.Node, error) {
+	nodes, err := rt.getUnmarshaledNodesFromBucket(kadBucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	var oldest *pb.Node
+	var oldestSeen time.Time
+	for _, n := range nodes {
+		if n.GetId() == rt.self.GetId() {
+			continue
+		}
+		seen := rt.lastSeen[n.GetId()]
+		if oldest == nil || seen.Before(oldestSeen) {
+			oldest, oldestSeen = n, seen
+		}
+	}
+	return oldest, nil
+}
@@ -5,6 +5,7 @@ package kademlia
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -30,3 +31,44 @@ func TestAddToReplacementCache(t *testing.T) {
 	rt.addToReplacementCache(kadBucketID2, node4)
 	assert.Equal(t, []*pb.Node{node3, node4}, rt.replacementCache[string(kadBucketID2)])
 }
+
+func TestAddToReplacementCacheMovesExisting(t *testing.T) {
+	rt, cleanup := createRoutingTable(t, []byte{244, 255})
+	defer cleanup()
+	kadBucketID := []byte{127, 255}
+	node1 := mockNode(string([]byte{100, 255}))
+	node2 := mockNode(string([]byte{90, 255}))
+	rt.addToReplacementCache(kadBucketID, node1)
+	rt.addToReplacementCache(kadBucketID, node2)
+	rt.addToReplacementCache(kadBucketID, node1)
+	assert.Equal(t, []*pb.Node{node2, node1}, rt.replacementCache[string(kadBucketID)])
+}
+
+func TestLeastRecentlySeen(t *testing.T) {
+	rt, cleanup := createRoutingTable(t, []byte("AA"))
+	defer cleanup()
+	bucketID := rt.createFirstBucketID()
+
+	oldest, err := rt.leastRecentlySeen(bucketID)
+	assert.NoError(t, err)
+	assert.Nil(t, oldest)
+
+	for _, id := range []string{"AB", "AC", "AD"} {
+		assert.NoError(t, rt.ConnectionSuccess(mockNode(id)))
+	}
+	rt.lastSeen["AB"] = time.Now()
+	rt.lastSeen["AC"] = time.Now().Add(-time.Hour)
+	rt.lastSeen["AD"] = time.Now().Add(-time.Minute)
+
+	oldest, err = rt.leastRecentlySeen(bucketID)
+	assert.NoError(t, err)
+	assert.Equal(t, "AC", oldest.Id)
+
+	assert.NoError(t, rt.ConnectionSuccess(mockNode("AC")))
+	oldest, err = rt.leastRecentlySeen(bucketID)
+	assert.NoError(t, err)
+	assert.Equal(t, "AD", oldest.Id)
+
+	assert.NoError(t, rt.ConnectionFailed(mockNode("AD")))
+	assert.NotContains(t, rt.lastSeen, "AD")
+}
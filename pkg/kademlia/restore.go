@@ -0,0 +1,59 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package kademlia
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/storage"
+)
+
+// restore re-checks the liveness of the contacts persisted in the routing
+// table by a previous run. Contacts that don't answer a ping are evicted, the
+// rest are kept so that a restarted node can rejoin the network without
+// relying on the bootstrap nodes alone. It returns the number of live contacts.
+func (k *Kademlia) restore(ctx context.Context) (alive int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	nodes, err := k.GetNodes(ctx, "", storage.LookupLimit)
+	if err != nil {
+		return 0, err
+	}
+
+	skip := map[string]bool{k.routingTable.self.GetId(): true}
+	for _, n := range k.bootstrapNodes {
+		skip[n.GetId()] = true
+	}
+
+	var contacts []*pb.Node
+	for _, n := range nodes {
+		if !skip[n.GetId()] {
+			contacts = append(contacts, n)
+		}
+	}
+
+	live, dead := k.pingAll(ctx, contacts)
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	// routing table updates are applied sequentially since the
+	// replacement cache is not safe for concurrent use
+	for _, n := range dead {
+		if err := k.routingTable.ConnectionFailed(n); err != nil {
+			return len(live), err
+		}
+	}
+	for _, n := range live {
+		if err := k.routingTable.ConnectionSuccess(n); err != nil {
+			return len(live), err
+		}
+	}
+
+	zap.S().Infof("restored %d of %d persisted routing table contacts", len(live), len(contacts))
+	return len(live), nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
 
+	"storj.io/storj/pkg/ban"
 	"storj.io/storj/pkg/dht"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/storj"
@@ -38,6 +39,9 @@ type RoutingTable struct {
 	transport        *pb.NodeTransport
 	mutex            *sync.Mutex
 	replacementCache map[string][]*pb.Node
+	lastSeen         map[string]time.Time
+	away             map[string]time.Time
+	bans             *ban.List
 	idLength         int // kbucket and node id bit length (SHA256) = 256
 	bucketSize       int // max number of nodes stored in a kbucket = 20 (k)
 	rcBucketSize     int // replacementCache bucket max length
@@ -52,6 +56,8 @@ func NewRoutingTable(localNode pb.Node, kdb, ndb storage.KeyValueStore) (*Routin
 		transport:        &defaultTransport,
 		mutex:            &sync.Mutex{},
 		replacementCache: make(map[string][]*pb.Node),
+		lastSeen:         make(map[string]time.Time),
+		away:             make(map[string]time.Time),
 		idLength:         len(storj.NodeID{}) * 8, // NodeID length in bits
 		bucketSize:       *flagBucketSize,
 		rcBucketSize:     *flagReplacementCacheSize,
@@ -110,7 +116,7 @@ func (rt *RoutingTable) GetBucket(id string) (bucket dht.Bucket, ok bool) {
 // GetBuckets retrieves all buckets from the local node
 func (rt *RoutingTable) GetBuckets() (k []dht.Bucket, err error) {
 	bs := []dht.Bucket{}
-	kbuckets, err := rt.kadBucketDB.List(nil, 0)
+	kbuckets, err := allKeys(rt.kadBucketDB)
 	if err != nil {
 		return bs, RoutingErr.New("could not get bucket ids %s", err)
 	}
@@ -127,12 +133,19 @@ func (rt *RoutingTable) GetBuckets() (k []dht.Bucket, err error) {
 // FindNear returns the node corresponding to the provided nodeID
 // returns all Nodes closest via XOR to the provided nodeID up to the provided limit
 // always returns limit + self
+// Nodes that announced they are offline are left out.
 func (rt *RoutingTable) FindNear(id dht.NodeID, limit int) ([]*pb.Node, error) {
 	// if id is not in the routing table
-	nodeIDs, err := rt.nodeBucketDB.List(nil, 0)
+	allIDs, err := allKeys(rt.nodeBucketDB)
 	if err != nil {
 		return []*pb.Node{}, RoutingErr.New("could not get node ids %s", err)
 	}
+	var nodeIDs storage.Keys
+	for _, nodeID := range allIDs {
+		if !rt.IsAway(string(nodeID)) {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+	}
 
 	sortedIDs := sortByXOR(nodeIDs, id.Bytes())
 	if len(sortedIDs) >= limit {
@@ -152,36 +165,65 @@ func (rt *RoutingTable) FindNear(id dht.NodeID, limit int) ([]*pb.Node, error) {
 }
 
 // ConnectionSuccess updates or adds a node to the routing table when
-// a successful connection is made to the node on the network
+// a successful connection is made to the node on the network.
+// Banned nodes are removed instead, and records signed before the one in
+// the routing table are ignored.
 func (rt *RoutingTable) ConnectionSuccess(node *pb.Node) error {
+	if rt.bans.BannedNode(node) {
+		return rt.ConnectionFailed(node)
+	}
+
 	v, err := rt.nodeBucketDB.Get(storage.Key(node.Id))
 	if err != nil && !storage.ErrKeyNotFound.Has(err) {
 		return RoutingErr.New("could not get node %s", err)
 	}
 
 	if v != nil {
+		stale, err := staleNode(node, v)
+		if err != nil {
+			return err
+		}
+		if stale {
+			zap.L().Debug("ignoring record older than the one in the routing table", zap.String("node", node.Id))
+			return nil
+		}
 		err = rt.updateNode(node)
 		if err != nil {
 			return RoutingErr.New("could not update node %s", err)
 		}
+		rt.markSeen(node.Id)
+		rt.clearAway(node.Id)
 		return nil
 	}
 
-	_, err = rt.addNode(node)
+	added, err := rt.addNode(node)
 	if err != nil {
 		return RoutingErr.New("could not add node %s", err)
 	}
+	if added {
+		rt.markSeen(node.Id)
+	}
+	rt.clearAway(node.Id)
 	return nil
 }
 
 // ConnectionFailed removes a node from the routing table when
-// a connection fails for the node on the network
+// a connection fails for the node on the network. Nodes that announced
+// they'd be offline are kept until they were expected back, unless they're
+// banned.
 func (rt *RoutingTable) ConnectionFailed(node *pb.Node) error {
+	if rt.bans.BannedNode(node) {
+		rt.clearAway(node.Id)
+	} else if rt.IsAway(node.Id) {
+		return nil
+	}
 	nodeID := storage.Key(node.Id)
 	bucketID, err := rt.getKBucketID(nodeID)
 	if err != nil {
 		return RoutingErr.New("could not get k bucket %s", err)
 	}
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
 	err = rt.removeNode(bucketID, nodeID)
 	if err != nil {
 		return RoutingErr.New("could not remove node %s", err)
@@ -189,6 +231,57 @@ func (rt *RoutingTable) ConnectionFailed(node *pb.Node) error {
 	return nil
 }
 
+// ConnectionAway records that a node announced it is going offline until
+// the given time, so that it isn't evicted as a failed node in the meantime.
+// Only nodes in the routing table which aren't banned are recorded, so that
+// the announcements don't pile up.
+func (rt *RoutingTable) ConnectionAway(node *pb.Node, until time.Time) error {
+	if rt.bans.BannedNode(node) {
+		return nil
+	}
+	_, err := rt.nodeBucketDB.Get(storage.Key(node.Id))
+	if storage.ErrKeyNotFound.Has(err) {
+		return nil
+	}
+	if err != nil {
+		return RoutingErr.New("could not get node %s", err)
+	}
+
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	now := time.Now()
+	for id, until := range rt.away {
+		if now.After(until) {
+			delete(rt.away, id)
+		}
+	}
+	rt.away[node.Id] = until
+	return nil
+}
+
+// IsAway returns whether the node with the given id announced it is
+// offline and isn't expected back yet
+func (rt *RoutingTable) IsAway(id string) bool {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	until, ok := rt.away[id]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(rt.away, id)
+		return false
+	}
+	return true
+}
+
+// clearAway forgets a node's announced downtime once it is back
+func (rt *RoutingTable) clearAway(id string) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	delete(rt.away, id)
+}
+
 // SetBucketTimestamp updates the last updated time for a bucket
 func (rt *RoutingTable) SetBucketTimestamp(id string, now time.Time) error {
 	rt.mutex.Lock()
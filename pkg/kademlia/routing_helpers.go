@@ -11,6 +11,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 
+	"storj.io/storj/pkg/node"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/storage"
 )
@@ -124,6 +125,7 @@ func (rt *RoutingTable) removeNode(kadBucketID storage.Key, nodeID storage.Key)
 	if err != nil {
 		return RoutingErr.New("could not delete node %s", err)
 	}
+	delete(rt.lastSeen, string(nodeID))
 	nodes := rt.replacementCache[string(kadBucketID)]
 	if len(nodes) == 0 {
 		return nil
@@ -151,6 +153,16 @@ func marshalNode(node pb.Node) ([]byte, error) {
 	return nodeVal, nil
 }
 
+// staleNode: helper, returns whether n was signed before the node stored as
+// value
+func staleNode(n *pb.Node, value storage.Value) (bool, error) {
+	stored := &pb.Node{}
+	if err := proto.Unmarshal(value, stored); err != nil {
+		return false, RoutingErr.New("could not unmarshal node %s", err)
+	}
+	return node.NewerNode(stored, n), nil
+}
+
 // putNode: helper, adds or updates Node and ID to nodeBucketDB
 func (rt *RoutingTable) putNode(nodeKey storage.Key, nodeValue storage.Value) error {
 	err := rt.nodeBucketDB.Put(nodeKey, nodeValue)
@@ -174,22 +186,36 @@ func (rt *RoutingTable) createOrUpdateKBucket(bucketID storage.Key, now time.Tim
 // getKBucketID: helper, returns the id of the corresponding k bucket given a node id.
 // The node doesn't have to be in the routing table at time of search
 func (rt *RoutingTable) getKBucketID(nodeID storage.Key) (storage.Key, error) {
-	kadBucketIDs, err := rt.kadBucketDB.List(nil, 0)
+	// the bucket of the node is the one with the smallest id from the node id
+	var bucketID storage.Key
+	err := rt.kadBucketDB.Iterate(storage.IterateOptions{First: nodeID, Recurse: true, Limit: 1}, func(it storage.Iterator) error {
+		var item storage.ListItem
+		if it.Next(&item) {
+			bucketID = storage.CloneKey(item.Key)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, RoutingErr.New("could not list all k bucket ids: %s", err)
+		return nil, RoutingErr.New("could not list k bucket ids: %s", err)
 	}
-	smallestKey := rt.createZeroAsStorageKey()
-	var keys storage.Keys
-	keys = append(keys, smallestKey)
-	keys = append(keys, kadBucketIDs...)
+	if bucketID == nil || bytes.Compare(nodeID, rt.createZeroAsStorageKey()) <= 0 {
+		//shouldn't happen BUT return error if no matching kbucket...
+		return nil, RoutingErr.New("could not find k bucket")
+	}
+	return bucketID, nil
+}
 
-	for i := 0; i < len(keys)-1; i++ {
-		if bytes.Compare(nodeID, keys[i]) > 0 && bytes.Compare(nodeID, keys[i+1]) <= 0 {
-			return keys[i+1], nil
+// allKeys: helper, returns all the keys of db, which unlike List aren't
+// capped at storage.LookupLimit
+func allKeys(db storage.KeyValueStore) (keys storage.Keys, err error) {
+	err = db.Iterate(storage.IterateOptions{Recurse: true}, func(it storage.Iterator) error {
+		var item storage.ListItem
+		for it.Next(&item) {
+			keys = append(keys, storage.CloneKey(item.Key))
 		}
-	}
-	//shouldn't happen BUT return error if no matching kbucket...
-	return nil, RoutingErr.New("could not find k bucket")
+		return nil
+	})
+	return keys, err
 }
 
 // sortByXOR: helper, quick sorts node IDs by xor from the reference Node, smallest xor to largest
@@ -240,7 +266,7 @@ func xorTwoIds(id []byte, comparisonID []byte) []byte {
 
 // nodeIsWithinNearestK: helper, returns true if the node in question is within the nearest k from local node
 func (rt *RoutingTable) nodeIsWithinNearestK(nodeID storage.Key) (bool, error) {
-	nodes, err := rt.nodeBucketDB.List(nil, 0)
+	nodes, err := allKeys(rt.nodeBucketDB)
 	if err != nil {
 		return false, RoutingErr.New("could not get nodes: %s", err)
 	}
@@ -292,17 +318,20 @@ func (rt *RoutingTable) getNodeIDsWithinKBucket(bucketID storage.Key) (storage.K
 	left := endpoints[0]
 	right := endpoints[1]
 	var nodeIDs storage.Keys
-	allNodeIDs, err := rt.nodeBucketDB.List(nil, 0)
-	if err != nil {
-		return nil, RoutingErr.New("could not list nodes %s", err)
-	}
-	for _, v := range allNodeIDs {
-		if (bytes.Compare(v, left) > 0) && (bytes.Compare(v, right) <= 0) {
-			nodeIDs = append(nodeIDs, v)
-			if len(nodeIDs) == rt.bucketSize {
+	err = rt.nodeBucketDB.Iterate(storage.IterateOptions{First: left, Recurse: true}, func(it storage.Iterator) error {
+		var item storage.ListItem
+		for len(nodeIDs) < rt.bucketSize && it.Next(&item) {
+			if bytes.Compare(item.Key, right) > 0 {
 				break
 			}
+			if bytes.Compare(item.Key, left) > 0 {
+				nodeIDs = append(nodeIDs, storage.CloneKey(item.Key))
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, RoutingErr.New("could not list nodes %s", err)
 	}
 	if len(nodeIDs) > 0 {
 		return nodeIDs, nil
@@ -361,8 +390,14 @@ func (rt *RoutingTable) getUnmarshaledNodesFromBucket(bucketID storage.Key) ([]*
 
 // getKBucketRange: helper, returns the left and right endpoints of the range of node ids contained within the bucket
 func (rt *RoutingTable) getKBucketRange(bucketID storage.Key) (storage.Keys, error) {
-	key := bucketID
-	kadIDs, err := rt.kadBucketDB.ReverseList(key, 2)
+	var kadIDs storage.Keys
+	err := rt.kadBucketDB.Iterate(storage.IterateOptions{First: bucketID, Recurse: true, Reverse: true, Limit: 2}, func(it storage.Iterator) error {
+		var item storage.ListItem
+		for it.Next(&item) {
+			kadIDs = append(kadIDs, storage.CloneKey(item.Key))
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, RoutingErr.New("could not reverse list k bucket ids %s", err)
 	}
@@ -28,6 +28,8 @@ func newTestRoutingTable(localNode pb.Node) (*RoutingTable, error) {
 		transport:        &defaultTransport,
 		mutex:            &sync.Mutex{},
 		replacementCache: make(map[string][]*pb.Node),
+		lastSeen:         make(map[string]time.Time),
+		away:             make(map[string]time.Time),
 		idLength:         16,
 		bucketSize:       6,
 		rcBucketSize:     2,
@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/stretchr/testify/assert"
 
+	"storj.io/storj/pkg/ban"
 	"storj.io/storj/pkg/node"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/storage"
@@ -176,6 +178,43 @@ func TestConnectionSuccess(t *testing.T) {
 	}
 }
 
+func TestConnectionSuccessBanned(t *testing.T) {
+	rt, cleanup := createRoutingTable(t, []byte("AA"))
+	defer cleanup()
+	rt.bans = ban.NewList(1, time.Hour, false)
+
+	banned := &pb.Node{Id: "BB", Address: &pb.NodeAddress{Address: "b"}}
+	assert.NoError(t, rt.ConnectionSuccess(banned))
+
+	// banned nodes are removed instead of updated
+	rt.bans.StrikeNode(banned)
+	assert.NoError(t, rt.ConnectionSuccess(banned))
+	_, err := rt.nodeBucketDB.Get(storage.Key("BB"))
+	assert.True(t, storage.ErrKeyNotFound.Has(err))
+
+	// the other nodes at the same host aren't
+	assert.NoError(t, rt.ConnectionSuccess(&pb.Node{Id: "CC", Address: &pb.NodeAddress{Address: "b"}}))
+	_, err = rt.nodeBucketDB.Get(storage.Key("CC"))
+	assert.NoError(t, err)
+}
+
+func TestConnectionSuccessStale(t *testing.T) {
+	rt, cleanup := createRoutingTable(t, []byte("AA"))
+	defer cleanup()
+
+	newer := &pb.Node{Id: "BB", Address: &pb.NodeAddress{Address: "b"}, SignedAt: &timestamp.Timestamp{Seconds: 2}}
+	older := &pb.Node{Id: "BB", Address: &pb.NodeAddress{Address: "a"}, SignedAt: &timestamp.Timestamp{Seconds: 1}}
+	assert.NoError(t, rt.ConnectionSuccess(newer))
+
+	// the older record doesn't replace the newer one
+	assert.NoError(t, rt.ConnectionSuccess(older))
+	v, err := rt.nodeBucketDB.Get(storage.Key("BB"))
+	assert.NoError(t, err)
+	n, err := unmarshalNodes(storage.Keys{storage.Key("BB")}, []storage.Value{v})
+	assert.NoError(t, err)
+	assert.Equal(t, "b", n[0].Address.Address)
+}
+
 func TestConnectionFailed(t *testing.T) {
 	id := "AA"
 	node := mockNode(id)
@@ -188,6 +227,55 @@ func TestConnectionFailed(t *testing.T) {
 	assert.Nil(t, v)
 }
 
+func TestConnectionAway(t *testing.T) {
+	rt, cleanup := createRoutingTable(t, []byte("AA"))
+	defer cleanup()
+	n := mockNode("AB")
+	assert.NoError(t, rt.ConnectionSuccess(n))
+	assert.False(t, rt.IsAway(n.Id))
+
+	assert.NoError(t, rt.ConnectionAway(n, time.Now().Add(time.Hour)))
+	assert.True(t, rt.IsAway(n.Id))
+
+	// away nodes aren't evicted nor handed out
+	assert.NoError(t, rt.ConnectionFailed(n))
+	_, err := rt.nodeBucketDB.Get(storage.Key(n.Id))
+	assert.NoError(t, err)
+	nodes, err := rt.FindNear(node.IDFromString("AB"), 2)
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 1)
+	assert.Equal(t, "AA", nodes[0].Id)
+
+	// nodes are back once they respond
+	assert.NoError(t, rt.ConnectionSuccess(n))
+	assert.False(t, rt.IsAway(n.Id))
+
+	// or once their announced downtime is over
+	assert.NoError(t, rt.ConnectionAway(n, time.Now().Add(-time.Second)))
+	assert.False(t, rt.IsAway(n.Id))
+	assert.NoError(t, rt.ConnectionFailed(n))
+	_, err = rt.nodeBucketDB.Get(storage.Key(n.Id))
+	assert.True(t, storage.ErrKeyNotFound.Has(err))
+
+	// nodes which aren't in the routing table aren't recorded
+	assert.NoError(t, rt.ConnectionAway(n, time.Now().Add(time.Hour)))
+	assert.False(t, rt.IsAway(n.Id))
+
+	// banned nodes are evicted even if they announced they're away, and
+	// their announcements aren't recorded anymore
+	rt.bans = ban.NewList(1, time.Hour, false)
+	assert.NoError(t, rt.ConnectionSuccess(n))
+	assert.NoError(t, rt.ConnectionAway(n, time.Now().Add(time.Hour)))
+	assert.True(t, rt.IsAway(n.Id))
+	rt.bans.StrikeNode(n)
+	assert.NoError(t, rt.ConnectionFailed(n))
+	_, err = rt.nodeBucketDB.Get(storage.Key(n.Id))
+	assert.True(t, storage.ErrKeyNotFound.Has(err))
+	assert.False(t, rt.IsAway(n.Id))
+	assert.NoError(t, rt.ConnectionAway(n, time.Now().Add(time.Hour)))
+	assert.False(t, rt.IsAway(n.Id))
+}
+
 func TestSetBucketTimestamp(t *testing.T) {
 	id := []byte("AA")
 	idStr := string(id)
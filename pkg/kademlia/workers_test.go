@@ -83,6 +83,12 @@ func TestWorkerLookup(t *testing.T) {
 	srv, mock := newTestServer(nil)
 	go func() { _ = srv.Serve(lis) }()
 	defer srv.Stop()
+
+	fid, err := newTestIdentity()
+	assert.NoError(t, err)
+	found := &pb.Node{Id: fid.ID.String()}
+	assert.NoError(t, node.SignNode(found, fid))
+
 	cases := []struct {
 		name     string
 		worker   *worker
@@ -96,13 +102,14 @@ func TestWorkerLookup(t *testing.T) {
 				assert.NoError(t, err)
 				identity, err := ca.NewIdentity()
 				assert.NoError(t, err)
-				nc, err := node.NewNodeClient(identity, pb.Node{Id: "foo", Address: &pb.NodeAddress{Address: "127.0.0.1:0"}}, mockDHT)
+				nc, err := node.NewNodeClient(identity, pb.Node{Id: "foo", Address: &pb.NodeAddress{Address: "127.0.0.1:0"}}, mockDHT, nil)
 				assert.NoError(t, err)
-				mock.returnValue = []*pb.Node{&pb.Node{Id: "foo"}}
+				mock.returnValue = []*pb.Node{found, &pb.Node{Id: "foo"}}
 				return newWorker(context.Background(), nil, []*pb.Node{&pb.Node{Id: "foo"}}, nc, node.IDFromString("foo"), 5)
 			}(),
-			work:     &pb.Node{Id: "foo", Address: &pb.NodeAddress{Address: lis.Addr().String()}},
-			expected: []*pb.Node{&pb.Node{Id: "foo"}},
+			work: &pb.Node{Id: "foo", Address: &pb.NodeAddress{Address: lis.Addr().String()}},
+			// the unsigned node is dropped
+			expected: []*pb.Node{&pb.Node{Id: found.Id, Signature: found.Signature, IdentityChain: found.IdentityChain, SignedAt: found.SignedAt}},
 		},
 	}
 
@@ -142,7 +149,7 @@ func TestUpdate(t *testing.T) {
 				assert.NoError(t, err)
 				identity, err := ca.NewIdentity()
 				assert.NoError(t, err)
-				nc, err := node.NewNodeClient(identity, pb.Node{Id: "foo", Address: &pb.NodeAddress{Address: ":7070"}}, mockDHT)
+				nc, err := node.NewNodeClient(identity, pb.Node{Id: "foo", Address: &pb.NodeAddress{Address: ":7070"}}, mockDHT, nil)
 				assert.NoError(t, err)
 				return newWorker(context.Background(), nil, []*pb.Node{&pb.Node{Id: "0000"}}, nc, node.IDFromString("foo"), 2)
 			}(),
@@ -158,7 +165,7 @@ func TestUpdate(t *testing.T) {
 				assert.NoError(t, err)
 				identity, err := ca.NewIdentity()
 				assert.NoError(t, err)
-				nc, err := node.NewNodeClient(identity, pb.Node{Id: "a", Address: &pb.NodeAddress{Address: ":7070"}}, mockDHT)
+				nc, err := node.NewNodeClient(identity, pb.Node{Id: "a", Address: &pb.NodeAddress{Address: ":7070"}}, mockDHT, nil)
 				assert.NoError(t, err)
 				return newWorker(context.Background(), nil, []*pb.Node{&pb.Node{Id: "h"}}, nc, node.IDFromString("a"), 2)
 			}(),
@@ -208,6 +215,14 @@ type mockNodeServer struct {
 	returnValue []*pb.Node
 }
 
+func (mn *mockNodeServer) ExternalAddress(ctx context.Context, req *pb.ExternalAddressRequest) (*pb.ExternalAddressResponse, error) {
+	return &pb.ExternalAddressResponse{}, nil
+}
+
+func (mn *mockNodeServer) Leave(ctx context.Context, req *pb.LeaveRequest) (*pb.LeaveResponse, error) {
+	return &pb.LeaveResponse{}, nil
+}
+
 func (mn *mockNodeServer) Query(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
 	atomic.AddInt32(&mn.queryCalled, 1)
 	return &pb.QueryResponse{Response: mn.returnValue}, nil
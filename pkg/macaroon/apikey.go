@@ -0,0 +1,182 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package macaroon
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/mr-tron/base58/base58"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/pb"
+)
+
+var (
+	// Error is a general api key error
+	Error = errs.Class("api key error")
+	// ErrUnauthorized is used when an api key doesn't allow an action
+	ErrUnauthorized = errs.Class("api key unauthorized error")
+)
+
+// ActionType is the type of operation an api key is used for
+type ActionType int
+
+const (
+	// ActionRead is reading a pointer
+	ActionRead ActionType = iota + 1
+	// ActionWrite is putting a pointer
+	ActionWrite
+	// ActionList is listing pointers
+	ActionList
+	// ActionDelete is deleting a pointer
+	ActionDelete
+)
+
+// Action is an operation an api key is checked against
+type Action struct {
+	Op     ActionType
+	Bucket []byte
+	// Path is the path of the pointer, starting with the bucket, without the
+	// segment
+	Path []byte
+	Time time.Time
+}
+
+// APIKey is an api key: a macaroon whose caveats are serialized pb.Caveats.
+// It's verified against the secret it was created with, so checking it
+// doesn't take any lookup, and it can be restricted by anyone holding it.
+type APIKey struct {
+	mac *Macaroon
+}
+
+// NewAPIKey returns a new unrestricted api key for secret
+func NewAPIKey(secret []byte) (*APIKey, error) {
+	mac, err := NewUnrestricted(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &APIKey{mac: mac}, nil
+}
+
+// ParseAPIKey parses an api key serialized with Serialize
+func ParseAPIKey(key string) (*APIKey, error) {
+	data, err := base58.Decode(key)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	mac, err := ParseMacaroon(data)
+	if err != nil {
+		return nil, err
+	}
+	return &APIKey{mac: mac}, nil
+}
+
+// Restrict returns a copy of a restricted with caveat
+func (a *APIKey) Restrict(caveat *pb.Caveat) (*APIKey, error) {
+	data, err := proto.Marshal(caveat)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return &APIKey{mac: a.mac.AddFirstPartyCaveat(data)}, nil
+}
+
+// Check returns an ErrUnauthorized error unless a was created with secret
+// and every one of its caveats allows action
+func (a *APIKey) Check(secret []byte, action Action) error {
+	if !a.mac.Validate(secret) {
+		return ErrUnauthorized.New("macaroon unauthorized")
+	}
+
+	for _, data := range a.mac.Caveats() {
+		caveat := &pb.Caveat{}
+		if err := proto.Unmarshal(data, caveat); err != nil {
+			return ErrUnauthorized.New("invalid caveat: %v", err)
+		}
+		if !allows(caveat, action) {
+			return ErrUnauthorized.New("action disallowed")
+		}
+	}
+	return nil
+}
+
+// allows returns whether caveat allows action
+func allows(caveat *pb.Caveat, action Action) bool {
+	switch action.Op {
+	case ActionRead:
+		if caveat.GetDisallowReads() {
+			return false
+		}
+	case ActionWrite:
+		if caveat.GetDisallowWrites() {
+			return false
+		}
+	case ActionList:
+		if caveat.GetDisallowLists() {
+			return false
+		}
+	case ActionDelete:
+		if caveat.GetDisallowDeletes() {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if notAfter := caveat.GetNotAfter(); notAfter != 0 && action.Time.Unix() > notAfter {
+		return false
+	}
+
+	return allowsBucket(caveat, action) && allowsPath(caveat, action)
+}
+
+// allowsBucket returns whether the bucket of action is one of the allowed
+// buckets of caveat
+func allowsBucket(caveat *pb.Caveat, action Action) bool {
+	if len(caveat.GetAllowedBuckets()) == 0 {
+		return true
+	}
+	for _, bucket := range caveat.GetAllowedBuckets() {
+		if bytes.Equal(bucket, action.Bucket) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsPath returns whether the path of action is one of the allowed paths
+// of caveat or under one. The buckets of the allowed paths can be read too,
+// since they're looked up before their paths.
+func allowsPath(caveat *pb.Caveat, action Action) bool {
+	if len(caveat.GetAllowedPaths()) == 0 {
+		return true
+	}
+	for _, allowed := range caveat.GetAllowedPaths() {
+		if bytes.Equal(allowed, action.Path) || bytes.HasPrefix(action.Path, append(allowed[:len(allowed):len(allowed)], '/')) {
+			return true
+		}
+		if action.Op == ActionRead && bytes.Equal(bucketOf(allowed), action.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// bucketOf returns the first component of path
+func bucketOf(path []byte) []byte {
+	if i := bytes.IndexByte(path, '/'); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+// Serialize returns the string form of a, to be given to uplinks
+func (a *APIKey) Serialize() (string, error) {
+	data, err := a.mac.Serialize()
+	if err != nil {
+		return "", err
+	}
+	return base58.Encode(data), nil
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package macaroon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/pb"
+)
+
+func TestAPIKey(t *testing.T) {
+	secret, err := NewSecret()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	now := time.Now()
+
+	key, err := NewAPIKey(secret)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, key.Check(secret, Action{Op: ActionWrite, Bucket: []byte("a"), Time: now}))
+
+	otherSecret, err := NewSecret()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, ErrUnauthorized.Has(key.Check(otherSecret, Action{Op: ActionRead, Time: now})))
+
+	restricted, err := key.Restrict(&pb.Caveat{
+		DisallowWrites: true,
+		AllowedBuckets: [][]byte{[]byte("a"), []byte("b")},
+		NotAfter:       now.Add(time.Hour).Unix(),
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// keys survive serialization
+	serialized, err := restricted.Serialize()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	restricted, err = ParseAPIKey(serialized)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	for _, tt := range []struct {
+		action  Action
+		allowed bool
+	}{
+		{Action{Op: ActionRead, Bucket: []byte("a"), Time: now}, true},
+		{Action{Op: ActionList, Bucket: []byte("b"), Time: now}, true},
+		{Action{Op: ActionDelete, Bucket: []byte("b"), Time: now}, true},
+		{Action{Op: ActionWrite, Bucket: []byte("a"), Time: now}, false},
+		{Action{Op: ActionRead, Bucket: []byte("c"), Time: now}, false},
+		{Action{Op: ActionList, Time: now}, false},
+		{Action{Op: ActionRead, Bucket: []byte("a"), Time: now.Add(2 * time.Hour)}, false},
+	} {
+		err := restricted.Check(secret, tt.action)
+		if tt.allowed {
+			assert.NoError(t, err, "%+v", tt.action)
+		} else {
+			assert.True(t, ErrUnauthorized.Has(err), "%+v", tt.action)
+		}
+	}
+
+	// keys can be restricted to paths, whose buckets can be read
+	restricted, err = key.Restrict(&pb.Caveat{
+		DisallowWrites: true,
+		AllowedPaths:   [][]byte{[]byte("a/shared")},
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for _, tt := range []struct {
+		action  Action
+		allowed bool
+	}{
+		{Action{Op: ActionRead, Path: []byte("a/shared"), Time: now}, true},
+		{Action{Op: ActionRead, Path: []byte("a/shared/file"), Time: now}, true},
+		{Action{Op: ActionList, Path: []byte("a/shared/dir"), Time: now}, true},
+		{Action{Op: ActionRead, Path: []byte("a"), Time: now}, true},
+		{Action{Op: ActionList, Path: []byte("a"), Time: now}, false},
+		{Action{Op: ActionRead, Path: []byte("a/sharedfile"), Time: now}, false},
+		{Action{Op: ActionRead, Path: []byte("a/other"), Time: now}, false},
+		{Action{Op: ActionRead, Path: []byte("b/shared"), Time: now}, false},
+		{Action{Op: ActionWrite, Path: []byte("a/shared/file"), Time: now}, false},
+	} {
+		err := restricted.Check(secret, tt.action)
+		if tt.allowed {
+			assert.NoError(t, err, "%+v", tt.action)
+		} else {
+			assert.True(t, ErrUnauthorized.Has(err), "%+v", tt.action)
+		}
+	}
+
+	// caveats can't be removed
+	stripped := &APIKey{mac: &Macaroon{head: restricted.mac.head, tail: restricted.mac.tail}}
+	assert.True(t, ErrUnauthorized.Has(stripped.Check(secret, Action{Op: ActionWrite, Time: now})))
+
+	_, err = ParseAPIKey("not a key")
+	assert.Error(t, err)
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package macaroon
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+
+	"github.com/golang/protobuf/proto"
+
+	"storj.io/storj/pkg/pb"
+)
+
+// Macaroon is a chain of HMACs over a random head and a list of caveats. Its
+// tail is the HMAC of its last caveat keyed with the tail before that
+// caveat was added, the first one being the HMAC of the head keyed with a
+// secret. Anyone holding a macaroon can add caveats to it, but only the
+// holder of the secret can verify it or remove caveats.
+type Macaroon struct {
+	head    []byte
+	caveats [][]byte
+	tail    []byte
+}
+
+// NewSecret returns a new random secret to create macaroons with
+func NewSecret() ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return secret, nil
+}
+
+// NewUnrestricted returns a macaroon without caveats for secret
+func NewUnrestricted(secret []byte) (*Macaroon, error) {
+	head, err := NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	return &Macaroon{head: head, tail: sign(secret, head)}, nil
+}
+
+func sign(secret, data []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// AddFirstPartyCaveat returns a copy of m with the caveat c added
+func (m *Macaroon) AddFirstPartyCaveat(c []byte) *Macaroon {
+	caveats := make([][]byte, 0, len(m.caveats)+1)
+	caveats = append(caveats, m.caveats...)
+	caveats = append(caveats, c)
+	return &Macaroon{head: m.head, caveats: caveats, tail: sign(m.tail, c)}
+}
+
+// Validate returns whether m was made with secret and has kept all of its
+// caveats
+func (m *Macaroon) Validate(secret []byte) bool {
+	tail := sign(secret, m.head)
+	for _, c := range m.caveats {
+		tail = sign(tail, c)
+	}
+	return hmac.Equal(tail, m.tail)
+}
+
+// Caveats returns the caveats of m
+func (m *Macaroon) Caveats() [][]byte {
+	return m.caveats
+}
+
+// Serialize returns the serialized form of m
+func (m *Macaroon) Serialize() ([]byte, error) {
+	data, err := proto.Marshal(&pb.Macaroon{Head: m.head, Caveats: m.caveats, Tail: m.tail})
+	return data, Error.Wrap(err)
+}
+
+// ParseMacaroon parses a macaroon serialized with Serialize
+func ParseMacaroon(data []byte) (*Macaroon, error) {
+	m := &pb.Macaroon{}
+	if err := proto.Unmarshal(data, m); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if len(m.GetHead()) == 0 || len(m.GetTail()) == 0 {
+		return nil, Error.New("incomplete macaroon")
+	}
+	return &Macaroon{head: m.GetHead(), caveats: m.GetCaveats(), tail: m.GetTail()}, nil
+}
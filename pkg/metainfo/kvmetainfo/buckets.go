@@ -28,7 +28,14 @@ func (db *Buckets) CreateBucket(ctx context.Context, bucket string, info *storj.
 		return storj.Bucket{}, buckets.NoBucketError.New("")
 	}
 
-	meta, err := db.store.Put(ctx, bucket)
+	var redundancy storj.RedundancyScheme
+	var segmentSize int64
+	if info != nil {
+		redundancy = info.RedundancyScheme
+		segmentSize = info.SegmentSize
+	}
+
+	meta, err := db.store.Put(ctx, bucket, redundancy, segmentSize)
 	if err != nil {
 		return storj.Bucket{}, err
 	}
@@ -98,7 +105,9 @@ func (db *Buckets) ListBuckets(ctx context.Context, options storj.BucketListOpti
 
 func bucketFromMeta(bucket string, meta buckets.Meta) storj.Bucket {
 	return storj.Bucket{
-		Name:    bucket,
-		Created: meta.Created,
+		Name:             bucket,
+		Created:          meta.Created,
+		RedundancyScheme: meta.RedundancyScheme,
+		SegmentSize:      meta.SegmentSize,
 	}
 }
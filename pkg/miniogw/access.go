@@ -0,0 +1,70 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"encoding/json"
+
+	"github.com/mr-tron/base58/base58"
+
+	"storj.io/storj/pkg/storage/streams"
+	"storj.io/storj/pkg/storj"
+)
+
+// Access is the access to the paths under a prefix only, to be shared with
+// others: an api key restricted to the prefix, and the part of the key
+// hierarchy the paths under it are encrypted with
+type Access struct {
+	APIKey string
+	Shared *streams.SharedPrefix
+}
+
+// serializedAccess is the form an Access is serialized in
+type serializedAccess struct {
+	APIKey          string `json:"api_key"`
+	Prefix          string `json:"prefix"`
+	EncryptedPrefix string `json:"encrypted_prefix"`
+	Key             []byte `json:"key"`
+	BucketKey       []byte `json:"bucket_key"`
+}
+
+// Serialize returns the string form of a, to be given to uplinks
+func (a *Access) Serialize() (string, error) {
+	data, err := json.Marshal(serializedAccess{
+		APIKey:          a.APIKey,
+		Prefix:          a.Shared.Prefix,
+		EncryptedPrefix: a.Shared.EncryptedPrefix,
+		Key:             a.Shared.Key[:],
+		BucketKey:       a.Shared.BucketKey[:],
+	})
+	if err != nil {
+		return "", Error.Wrap(err)
+	}
+	return base58.Encode(data), nil
+}
+
+// ParseAccess parses an access serialized with Serialize
+func ParseAccess(access string) (*Access, error) {
+	data, err := base58.Decode(access)
+	if err != nil {
+		return nil, Error.New("invalid access: %v", err)
+	}
+	var s serializedAccess
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, Error.New("invalid access: %v", err)
+	}
+	if s.Prefix == "" || len(s.Key) != storj.KeySize || len(s.BucketKey) != storj.KeySize {
+		return nil, Error.New("invalid access")
+	}
+
+	shared := &streams.SharedPrefix{
+		Prefix:          s.Prefix,
+		EncryptedPrefix: s.EncryptedPrefix,
+		Key:             new(storj.Key),
+		BucketKey:       new(storj.Key),
+	}
+	copy(shared.Key[:], s.Key)
+	copy(shared.BucketKey[:], s.BucketKey)
+	return &Access{APIKey: s.APIKey, Shared: shared}, nil
+}
@@ -0,0 +1,37 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/storage/streams"
+	"storj.io/storj/pkg/storj"
+)
+
+func TestAccess(t *testing.T) {
+	rootKey := new(storj.Key)
+	copy(rootKey[:], "root key")
+	shared, err := streams.NewSharedPrefix("bucket/prefix", rootKey)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	access := &Access{APIKey: "api key", Shared: shared}
+	serialized, err := access.Serialize()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	parsed, err := ParseAccess(serialized)
+	if assert.NoError(t, err) {
+		assert.Equal(t, access, parsed)
+	}
+
+	for _, invalid := range []string{"", "not base58 0OIl", "3mJr7AoUXx2Wqd"} {
+		_, err := ParseAccess(invalid)
+		assert.True(t, Error.Has(err), invalid)
+	}
+}
@@ -6,12 +6,13 @@ package miniogw
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/minio/cli"
 	minio "github.com/minio/minio/cmd"
-	"github.com/vivint/infectious"
 
 	"storj.io/storj/pkg/eestream"
+	"storj.io/storj/pkg/encryption"
 	"storj.io/storj/pkg/miniogw/logging"
 	"storj.io/storj/pkg/overlay"
 	"storj.io/storj/pkg/pointerdb/pdbclient"
@@ -23,25 +24,48 @@ import (
 	streams "storj.io/storj/pkg/storage/streams"
 	"storj.io/storj/pkg/storj"
 	"storj.io/storj/pkg/transport"
+	"storj.io/storj/pkg/utils"
 )
 
 // RSConfig is a configuration struct that keeps details about default
 // redundancy strategy information
 type RSConfig struct {
-	MaxBufferMem     int `help:"maximum buffer memory (in bytes) to be allocated for read buffers" default:"0x400000"`
-	ErasureShareSize int `help:"the size of each new erasure sure in bytes" default:"1024"`
-	MinThreshold     int `help:"the minimum pieces required to recover a segment. k." default:"29"`
-	RepairThreshold  int `help:"the minimum safe pieces before a repair is triggered. m." default:"35"`
-	SuccessThreshold int `help:"the desired total pieces for a segment. o." default:"80"`
-	MaxThreshold     int `help:"the largest amount of pieces to encode to. n." default:"95"`
+	MaxBufferMem      int           `help:"maximum buffer memory (in bytes) to be allocated for read buffers" default:"0x400000"`
+	MaxBufferMemTotal int64         `help:"maximum buffer memory (in bytes) to be allocated for the buffers of all uploads and downloads together, which wait while it's used up, 0 disables the limit" default:"0x10000000"`
+	PieceTimeout      time.Duration `help:"how long a read of a piece may take before the piece is canceled and the segment is read from the other pieces, 0 disables the timeout" default:"10s"`
+	ExtraPieces       int           `help:"how many pieces more than the minimum required are downloaded, from the nodes which served pieces the fastest, -1 downloads all the pieces" default:"8"`
+	ErasureShareSize  int           `help:"the size of each new erasure sure in bytes" default:"1024"`
+	MinThreshold      int           `help:"the minimum pieces required to recover a segment. k." default:"29"`
+	RepairThreshold   int           `help:"the minimum safe pieces before a repair is triggered. m." default:"35"`
+	SuccessThreshold  int           `help:"the desired total pieces for a segment. o." default:"80"`
+	MaxThreshold      int           `help:"the largest amount of pieces to encode to. n." default:"95"`
+	SIMD              bool          `help:"encode new segments with the Reed-Solomon code accelerated with SIMD instructions, whose pieces older uplinks can't decode" default:"false"`
 }
 
 // EncryptionConfig is a configuration struct that keeps details about
 // encrypting segments
 type EncryptionConfig struct {
-	EncKey       string `help:"root key for encrypting the data"`
-	EncBlockSize int    `help:"size (in bytes) of encrypted blocks" default:"1024"`
-	EncType      int    `help:"Type of encryption to use (1=AES-GCM, 2=SecretBox)" default:"1"`
+	EncKey           string `help:"root key for encrypting the data, or the passphrase it's derived from"`
+	EncKeyDerivation string `help:"how the root key is derived from the enc key: raw uses its bytes, as uplinks set up before passphrases did, and argon2id derives it from a passphrase" default:"raw"`
+	EncBlockSize     int    `help:"size (in bytes) of encrypted blocks" default:"1024"`
+	EncType          int    `help:"Type of encryption to use (1=AES-GCM, 2=SecretBox)" default:"1"`
+	PadSizes         bool   `help:"pad the last segments of objects to sizes which hide their exact size from the storage nodes" default:"false"`
+}
+
+// RootKey returns the root key the enc key gives with the configured
+// derivation. Data encrypted with raw keys stays readable with the raw
+// derivation.
+func (c EncryptionConfig) RootKey() (*storj.Key, error) {
+	switch c.EncKeyDerivation {
+	case "", "raw":
+		key := new(storj.Key)
+		copy(key[:], c.EncKey)
+		return key, nil
+	case "argon2id":
+		return encryption.DeriveRootKey([]byte(c.EncKey))
+	default:
+		return nil, Error.New("unknown enc key derivation %q", c.EncKeyDerivation)
+	}
 }
 
 // MinioConfig is a configuration struct that keeps details about starting
@@ -50,6 +74,7 @@ type MinioConfig struct {
 	AccessKey string `help:"Minio Access Key to use" default:"insecure-dev-access-key"`
 	SecretKey string `help:"Minio Secret Key to use" default:"insecure-dev-secret-key"`
 	MinioDir  string `help:"Minio generic server config path" default:"$CONFDIR/minio"`
+	Tenants   string `help:"path to a JSON file of S3 access keys with their own secret key, satellite api key and encryption passphrase or access, to serve several tenants with one gateway, reloaded when it changes" default:""`
 }
 
 // ClientConfig is a configuration struct for the miniogw that controls how
@@ -59,9 +84,20 @@ type ClientConfig struct {
 	OverlayAddr   string `help:"Address to contact overlay server through"`
 	PointerDBAddr string `help:"Address to contact pointerdb server through"`
 
-	APIKey        string `help:"API Key (TODO: this needs to change to macaroons somehow)"`
+	APIKey        string `help:"API Key, created by the satellite and possibly restricted with uplink restrict"`
+	Access        string `help:"access to the paths under a prefix only, printed by uplink share, which is used instead of the api key and the encryption passphrase"`
 	MaxInlineSize int    `help:"max inline segment size in bytes" default:"4096"`
 	SegmentSize   int64  `help:"the size of a segment in bytes" default:"64000000"`
+
+	DownloadRetries   int   `help:"how many times a failed read of a segment is retried from the pieces on other nodes" default:"3"`
+	MaxUploadRate     int64 `help:"maximum bytes per second uploaded by all the piece uploads together, 0 disables the limit" default:"0"`
+	MaxDownloadRate   int64 `help:"maximum bytes per second downloaded by all the piece downloads together, 0 disables the limit" default:"0"`
+	ParallelUploads   int   `help:"how many segments of an object are uploaded at once" default:"4"`
+	MaxUploadMem      int64 `help:"maximum memory (in bytes) the segments of an object uploaded at once are buffered in, fewer are uploaded at once if they don't fit, 0 disables the limit" default:"0x10000000"`
+	ParallelDownloads int   `help:"how many segments of an object are downloaded at once, counting the one read; the ones ahead of it are buffered in memory reserved from max-buffer-mem-total until they're read" default:"4"`
+
+	Transport transport.Config
+	Pool      transport.PoolConfig
 }
 
 // Config is a general miniogw configuration struct. This should be everything
@@ -78,6 +114,10 @@ type Config struct {
 func (c Config) Run(ctx context.Context) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
+	if c.Tenants != "" {
+		return NewTenantProxy(c, c.Tenants).Run(ctx)
+	}
+
 	identity, err := c.Load()
 	if err != nil {
 		return err
@@ -105,6 +145,11 @@ func (c Config) Run(ctx context.Context) (err error) {
 		return err
 	}
 
+	err = releaseTenantListener()
+	if err != nil {
+		return err
+	}
+
 	minio.Main([]string{"storj", "gateway", "storj",
 		"--address", c.Address, "--config-dir", c.MinioDir, "--quiet"})
 	return Error.New("unexpected minio exit")
@@ -125,48 +170,101 @@ func (c Config) action(ctx context.Context, cliCtx *cli.Context, identity *provi
 // GetBucketStore returns an implementation of buckets.Store
 func (c Config) GetBucketStore(ctx context.Context, identity *provider.FullIdentity) (bs buckets.Store, err error) {
 	defer mon.Task()(&ctx)(&err)
+	bs, _, err = c.OpenBucketStore(ctx, identity)
+	return bs, err
+}
 
-	t := transport.NewClient(identity)
+// OpenBucketStore returns an implementation of buckets.Store, and a function
+// closing the connections of its clients once it isn't used anymore
+func (c Config) OpenBucketStore(ctx context.Context, identity *provider.FullIdentity) (bs buckets.Store, close func() error, err error) {
+	defer mon.Task()(&ctx)(&err)
 
-	var oc overlay.Client
-	oc, err = overlay.NewOverlayClient(identity, c.OverlayAddr)
-	if err != nil {
-		return nil, err
+	t := transport.NewPool(c.Transport.NewClient(identity), c.Pool)
+	closers := []func() error{t.Close}
+	closeAll := func() error {
+		var errs []error
+		for _, closer := range closers {
+			errs = append(errs, closer())
+		}
+		return utils.CombineErrors(errs...)
 	}
+	defer func() {
+		if err != nil {
+			err = utils.CombineErrors(err, closeAll())
+		}
+	}()
 
-	pdb, err := pdbclient.NewClient(identity, c.PointerDBAddr, c.APIKey)
+	oc, err := overlay.NewOverlayClient(identity, c.OverlayAddr)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	closers = append(closers, oc.Close)
+
+	var access *Access
+	apiKey := c.APIKey
+	if c.Access != "" {
+		access, err = ParseAccess(c.Access)
+		if err != nil {
+			return nil, nil, err
+		}
+		apiKey = access.APIKey
 	}
 
-	ec := ecclient.NewClient(identity, t, c.MaxBufferMem)
-	fc, err := infectious.NewFEC(c.MinThreshold, c.MaxThreshold)
+	pdb, err := pdbclient.NewClient(identity, c.PointerDBAddr, apiKey)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	rs, err := eestream.NewRedundancyStrategy(eestream.NewRSScheme(fc, c.ErasureShareSize), c.RepairThreshold, c.SuccessThreshold)
+	closers = append(closers, pdb.Close)
+
+	var budget *eestream.MemoryBudget
+	if c.MaxBufferMemTotal > 0 {
+		budget, err = eestream.NewMemoryBudget(c.MaxBufferMemTotal)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	ec := ecclient.NewClient(identity, t, c.MaxBufferMem, c.PieceTimeout, budget, c.ExtraPieces, nil,
+		ecclient.NewRateLimiter(c.MaxUploadRate), ecclient.NewRateLimiter(c.MaxDownloadRate))
+	algorithm := storj.ReedSolomon
+	if c.SIMD {
+		algorithm = storj.ReedSolomonSIMD
+	}
+	es, err := eestream.NewErasureScheme(algorithm, c.MinThreshold, c.MaxThreshold, c.ErasureShareSize)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	rs, err := eestream.NewRedundancyStrategy(es, c.RepairThreshold, c.SuccessThreshold)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	segments := segment.NewSegmentStore(oc, ec, pdb, rs, c.MaxInlineSize)
+	segments := segment.NewSegmentStore(oc, ec, pdb, rs, c.MaxInlineSize, c.DownloadRetries)
 
 	if c.ErasureShareSize*c.MinThreshold%c.EncBlockSize != 0 {
 		err = Error.New("EncryptionBlockSize must be a multiple of ErasureShareSize * RS MinThreshold")
-		return nil, err
+		return nil, nil, err
 	}
 
-	key := new(storj.Key)
-	copy(key[:], c.EncKey)
-
-	stream, err := streams.NewStreamStore(segments, c.SegmentSize, key, c.EncBlockSize, storj.Cipher(c.EncType))
-	if err != nil {
-		return nil, err
+	var stream streams.Store
+	if access != nil {
+		stream, err = streams.NewSharedStreamStore(segments, c.SegmentSize, access.Shared, c.EncBlockSize, storj.Cipher(c.EncType), c.PadSizes, c.ParallelUploads, c.MaxUploadMem, c.ParallelDownloads, budget)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		key, err := c.RootKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		stream, err = streams.NewStreamStore(segments, c.SegmentSize, key, c.EncBlockSize, storj.Cipher(c.EncType), c.PadSizes, c.ParallelUploads, c.MaxUploadMem, c.ParallelDownloads, budget)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	obj := objects.NewStore(stream)
 
-	return buckets.NewStore(obj), nil
+	return buckets.NewStore(obj), closeAll, nil
 }
 
 // NewGateway creates a new minio Gateway
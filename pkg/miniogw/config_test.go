@@ -0,0 +1,36 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/encryption"
+	"storj.io/storj/pkg/storj"
+)
+
+func TestRootKey(t *testing.T) {
+	// raw keys are used as they are, so data encrypted before passphrases
+	// stays readable
+	for _, derivation := range []string{"", "raw"} {
+		key, err := EncryptionConfig{EncKey: "key", EncKeyDerivation: derivation}.RootKey()
+		assert.NoError(t, err)
+		expected := storj.Key{}
+		copy(expected[:], "key")
+		assert.Equal(t, &expected, key)
+	}
+
+	key, err := EncryptionConfig{EncKey: "passphrase", EncKeyDerivation: "argon2id"}.RootKey()
+	assert.NoError(t, err)
+	expected, err := encryption.DeriveRootKey([]byte("passphrase"))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, key)
+
+	_, err = EncryptionConfig{EncKey: "", EncKeyDerivation: "argon2id"}.RootKey()
+	assert.Error(t, err)
+	_, err = EncryptionConfig{EncKey: "key", EncKeyDerivation: "scrypt"}.RootKey()
+	assert.Error(t, err)
+}
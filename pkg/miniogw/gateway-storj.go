@@ -7,9 +7,11 @@ import (
 	"context"
 	"io"
 	"strings"
+	"sync"
 	"time"
 
 	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/cmd/logger"
 	"github.com/minio/minio/pkg/auth"
 	"github.com/minio/minio/pkg/hash"
 	"github.com/zeebo/errs"
@@ -30,15 +32,83 @@ var (
 	Error = errs.Class("Storj Gateway error")
 )
 
+// parallelDeletes is how many objects each multi-object delete deletes at
+// once. The multi-object deletes of S3 delete up to 1000 objects at once
+// otherwise.
+const parallelDeletes = 16
+
 // NewStorjGateway creates a *Storj object from an existing ObjectStore
 func NewStorjGateway(bs buckets.Store) *Storj {
-	return &Storj{bs: bs, multipart: NewMultipartUploads()}
+	return &Storj{bs: bs, multipart: NewMultipartUploads(), deletes: newDeleteLimiter(parallelDeletes)}
 }
 
 //Storj is the implementation of a minio cmd.Gateway
 type Storj struct {
 	bs        buckets.Store
 	multipart *MultipartUploads
+	// deletes limits the objects deleted at once, unless it's nil
+	deletes *deleteLimiter
+}
+
+// deleteLimiter limits how many objects each multi-object delete deletes at
+// once, so the deletes of a request don't hold up those of the others. The
+// objects of a multi-object delete are deleted with the context of the
+// request, which has the info of the request.
+type deleteLimiter struct {
+	parallelism int
+
+	mu       sync.Mutex
+	requests map[*logger.ReqInfo]*deleteSlots
+}
+
+// deleteSlots are the slots of the objects deleted at once by a request,
+// which are kept as long as the request has deletes
+type deleteSlots struct {
+	slots   chan struct{}
+	deletes int
+}
+
+func newDeleteLimiter(parallelism int) *deleteLimiter {
+	return &deleteLimiter{parallelism: parallelism, requests: make(map[*logger.ReqInfo]*deleteSlots)}
+}
+
+// acquire waits until the request of ctx can delete another object, and
+// returns the function to call once it's deleted. The deletes without the
+// info of a request aren't limited.
+func (l *deleteLimiter) acquire(ctx context.Context) (release func(), err error) {
+	request := logger.GetReqInfo(ctx)
+	if l == nil || request == nil {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	slots, ok := l.requests[request]
+	if !ok {
+		slots = &deleteSlots{slots: make(chan struct{}, l.parallelism)}
+		l.requests[request] = slots
+	}
+	slots.deletes++
+	l.mu.Unlock()
+
+	done := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		slots.deletes--
+		if slots.deletes == 0 {
+			delete(l.requests, request)
+		}
+	}
+
+	select {
+	case slots.slots <- struct{}{}:
+		return func() {
+			<-slots.slots
+			done()
+		}, nil
+	case <-ctx.Done():
+		done()
+		return nil, ctx.Err()
+	}
 }
 
 // Name implements cmd.Gateway
@@ -87,6 +157,11 @@ func (s *storjObjects) DeleteBucket(ctx context.Context, bucket string) (err err
 
 func (s *storjObjects) DeleteObject(ctx context.Context, bucket, object string) (err error) {
 	defer mon.Task()(&ctx)(&err)
+	release, err := s.storj.deletes.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
 	o, err := s.storj.bs.GetObjectStore(ctx, bucket)
 	if err != nil {
 		return err
@@ -205,46 +280,10 @@ func (s *storjObjects) ListBuckets(ctx context.Context) (
 func (s *storjObjects) ListObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (result minio.ListObjectsInfo, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	if delimiter != "" && delimiter != "/" {
-		return minio.ListObjectsInfo{}, Error.New("delimiter %s not supported", delimiter)
-	}
-
-	startAfter := marker
-	recursive := delimiter == ""
-
-	var objects []minio.ObjectInfo
-	var prefixes []string
-	o, err := s.storj.bs.GetObjectStore(ctx, bucket)
+	objects, prefixes, next, more, err := s.listObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
 	if err != nil {
 		return minio.ListObjectsInfo{}, err
 	}
-	items, more, err := o.List(ctx, prefix, startAfter, "", recursive, maxKeys, meta.All)
-	if err != nil {
-		return result, err
-	}
-	if len(items) > 0 {
-		for _, item := range items {
-			path := item.Path
-			if recursive && prefix != "" {
-				path = storj.JoinPaths(strings.TrimSuffix(prefix, "/"), path)
-			}
-			if item.IsPrefix {
-				prefixes = append(prefixes, path)
-				continue
-			}
-			objects = append(objects, minio.ObjectInfo{
-				Bucket:      bucket,
-				IsDir:       false,
-				Name:        path,
-				ModTime:     item.Meta.Modified,
-				Size:        item.Meta.Size,
-				ContentType: item.Meta.ContentType,
-				UserDefined: item.Meta.UserDefined,
-				ETag:        item.Meta.Checksum,
-			})
-		}
-		startAfter = items[len(items)-1].Path
-	}
 
 	result = minio.ListObjectsInfo{
 		IsTruncated: more,
@@ -252,78 +291,97 @@ func (s *storjObjects) ListObjects(ctx context.Context, bucket, prefix, marker,
 		Prefixes:    prefixes,
 	}
 	if more {
-		result.NextMarker = startAfter
+		result.NextMarker = next
 	}
 
-	return result, err
+	return result, nil
 }
 
-// ListObjectsV2 - Not implemented stub
 func (s *storjObjects) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (result minio.ListObjectsV2Info, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	if delimiter != "" && delimiter != "/" {
-		return minio.ListObjectsV2Info{ContinuationToken: continuationToken}, Error.New("delimiter %s not supported", delimiter)
+	// the continuation token is the key the previous page ended at
+	marker := continuationToken
+	if marker == "" {
+		marker = startAfter
 	}
 
+	objects, prefixes, next, more, err := s.listObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return minio.ListObjectsV2Info{ContinuationToken: continuationToken}, err
+	}
+
+	result = minio.ListObjectsV2Info{
+		IsTruncated:       more,
+		ContinuationToken: continuationToken,
+		Objects:           objects,
+		Prefixes:          prefixes,
+	}
+	if more {
+		result.NextContinuationToken = next
+	}
+
+	return result, nil
+}
+
+// listObjects lists a page of at most maxKeys objects and prefixes of bucket
+// in prefix, after the key marker, and returns the key the next page starts
+// after. The objects in the sub-prefixes of prefix are listed only without
+// a delimiter. The keys of the objects, the prefixes and the markers are the
+// full paths in the bucket, like in S3, where markers outside of prefix are
+// taken as relative to it.
+func (s *storjObjects) listObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (objects []minio.ObjectInfo, prefixes []string, next string, more bool, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if delimiter != "" && delimiter != "/" {
+		return nil, nil, "", false, Error.New("delimiter %s not supported", delimiter)
+	}
 	recursive := delimiter == ""
-	var nextContinuationToken string
 
-	var startAfterPath storj.Path
-	if continuationToken != "" {
-		startAfterPath = continuationToken
+	// the paths listed are relative to the prefix, which is a directory
+	dir := prefix
+	if dir != "" && !strings.HasSuffix(dir, "/") {
+		dir += "/"
 	}
-	if startAfterPath == "" && startAfter != "" {
-		startAfterPath = startAfter
+	startAfter := marker
+	if dir != "" && strings.HasPrefix(marker, dir) {
+		startAfter = strings.TrimPrefix(marker, dir)
 	}
 
-	var objects []minio.ObjectInfo
-	var prefixes []string
 	o, err := s.storj.bs.GetObjectStore(ctx, bucket)
 	if err != nil {
-		return minio.ListObjectsV2Info{ContinuationToken: continuationToken}, err
+		return nil, nil, "", false, err
 	}
-	items, more, err := o.List(ctx, prefix, startAfterPath, "", recursive, maxKeys, meta.All)
+	items, more, err := o.List(ctx, prefix, startAfter, "", recursive, maxKeys, meta.All)
 	if err != nil {
-		return result, err
+		return nil, nil, "", false, err
 	}
 
-	if len(items) > 0 {
-		for _, item := range items {
-			path := item.Path
-			if recursive && prefix != "" {
-				path = storj.JoinPaths(strings.TrimSuffix(prefix, "/"), path)
-			}
-			if item.IsPrefix {
-				prefixes = append(prefixes, path)
-				continue
+	for _, item := range items {
+		path := dir + item.Path
+		if item.IsPrefix {
+			if !strings.HasSuffix(path, "/") {
+				path += "/"
 			}
-			objects = append(objects, minio.ObjectInfo{
-				Bucket:      bucket,
-				IsDir:       false,
-				Name:        path,
-				ModTime:     item.Meta.Modified,
-				Size:        item.Meta.Size,
-				ContentType: item.Meta.ContentType,
-				UserDefined: item.Meta.UserDefined,
-				ETag:        item.Meta.Checksum,
-			})
+			prefixes = append(prefixes, path)
+			continue
 		}
-
-		nextContinuationToken = items[len(items)-1].Path + "\x00"
-	}
-
-	result = minio.ListObjectsV2Info{
-		IsTruncated:       more,
-		ContinuationToken: continuationToken,
-		Objects:           objects,
-		Prefixes:          prefixes,
+		objects = append(objects, minio.ObjectInfo{
+			Bucket:      bucket,
+			IsDir:       false,
+			Name:        path,
+			ModTime:     item.Meta.Modified,
+			Size:        item.Meta.Size,
+			ContentType: item.Meta.ContentType,
+			UserDefined: item.Meta.UserDefined,
+			ETag:        item.Meta.Checksum,
+		})
 	}
-	if more {
-		result.NextContinuationToken = nextContinuationToken
+	if len(items) > 0 {
+		next = dir + items[len(items)-1].Path
 	}
 
-	return result, err
+	return objects, prefixes, next, more, nil
 }
 
 func (s *storjObjects) MakeBucketWithLocation(ctx context.Context,
@@ -343,7 +401,7 @@ func (s *storjObjects) MakeBucketWithLocation(ctx context.Context,
 	if !storage.ErrKeyNotFound.Has(err) {
 		return err
 	}
-	_, err = s.storj.bs.Put(ctx, bucket)
+	_, err = s.storj.bs.Put(ctx, bucket, storj.RedundancyScheme{}, 0)
 	return err
 }
 
@@ -351,6 +409,18 @@ func (s *storjObjects) CopyObject(ctx context.Context, srcBucket, srcObject, des
 	destObject string, srcInfo minio.ObjectInfo) (objInfo minio.ObjectInfo, err error) {
 	defer mon.Task()(&ctx)(&err)
 
+	// objects copied within a bucket with their metadata are copied by
+	// pointerdb, without downloading and uploading their data again
+	if srcBucket == destBucket {
+		stored, err := s.GetObjectInfo(ctx, srcBucket, srcObject)
+		if err != nil {
+			return objInfo, err
+		}
+		if stored.ContentType == srcInfo.ContentType && sameMetadata(stored.UserDefined, srcInfo.UserDefined) {
+			return s.copyObject(ctx, srcBucket, srcObject, destObject, stored)
+		}
+	}
+
 	rr, err := s.getObject(ctx, srcBucket, srcObject)
 	if err != nil {
 		return objInfo, err
@@ -371,6 +441,55 @@ func (s *storjObjects) CopyObject(ctx context.Context, srcBucket, srcObject, des
 	return s.putObject(ctx, destBucket, destObject, r, serMetaInfo)
 }
 
+// copyObject copies the object of bucket at srcObject, whose info is
+// srcInfo, to destObject, sharing its data
+func (s *storjObjects) copyObject(ctx context.Context, bucket, srcObject, destObject string,
+	srcInfo minio.ObjectInfo) (objInfo minio.ObjectInfo, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	// an object copied to itself with its metadata is left as it is
+	if srcObject == destObject {
+		return srcInfo, nil
+	}
+
+	o, err := s.storj.bs.GetObjectStore(ctx, bucket)
+	if err != nil {
+		return objInfo, err
+	}
+	m, err := o.Copy(ctx, srcObject, destObject)
+	if err != nil {
+		if storage.ErrKeyNotFound.Has(err) {
+			return objInfo, minio.ObjectNotFound{
+				Bucket: bucket,
+				Object: srcObject,
+			}
+		}
+		return objInfo, err
+	}
+	return minio.ObjectInfo{
+		Name:        destObject,
+		Bucket:      bucket,
+		ModTime:     m.Modified,
+		Size:        m.Size,
+		ETag:        m.Checksum,
+		ContentType: m.ContentType,
+		UserDefined: m.UserDefined,
+	}, nil
+}
+
+// sameMetadata returns whether the user defined metadata a and b are the same
+func sameMetadata(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *storjObjects) putObject(ctx context.Context, bucket, object string, r io.Reader,
 	meta objects.SerializableMeta) (objInfo minio.ObjectInfo, err error) {
 	defer mon.Task()(&ctx)(&err)
@@ -9,11 +9,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/cmd/logger"
 	"github.com/minio/minio/pkg/hash"
 	"github.com/stretchr/testify/assert"
 
@@ -22,6 +24,7 @@ import (
 	mock_buckets "storj.io/storj/pkg/storage/buckets/mocks"
 	"storj.io/storj/pkg/storage/meta"
 	"storj.io/storj/pkg/storage/objects"
+	"storj.io/storj/pkg/storj"
 	"storj.io/storj/storage"
 )
 
@@ -93,13 +96,17 @@ func TestCopyObject(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		// if o.Get returns an error, only expect GetObjectStore once, do not expect Put
+		// the metadata is replaced, so the object is downloaded and uploaded
+		// again
+		mockOS.EXPECT().Meta(gomock.Any(), example.srcObject).Return(objects.Meta{Size: 1234}, nil)
+
+		// if o.Get returns an error, only expect GetObjectStore twice, do not expect Put
 		if example.errString != "some Get err" {
-			mockBS.EXPECT().GetObjectStore(gomock.Any(), example.bucket).Return(mockOS, nil).Times(2)
+			mockBS.EXPECT().GetObjectStore(gomock.Any(), example.bucket).Return(mockOS, nil).Times(3)
 			mockOS.EXPECT().Get(gomock.Any(), example.srcObject).Return(rr, meta, example.getErr)
 			mockOS.EXPECT().Put(gomock.Any(), example.destObject, r, serMeta, time.Time{}).Return(meta, example.putErr)
 		} else {
-			mockBS.EXPECT().GetObjectStore(gomock.Any(), example.bucket).Return(mockOS, nil)
+			mockBS.EXPECT().GetObjectStore(gomock.Any(), example.bucket).Return(mockOS, nil).Times(2)
 			mockOS.EXPECT().Get(gomock.Any(), example.srcObject).Return(rr, meta, example.getErr)
 		}
 
@@ -120,6 +127,72 @@ func TestCopyObject(t *testing.T) {
 	}
 }
 
+func TestCopyObjectSharingData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBS := mock_buckets.NewMockStore(ctrl)
+	b := Storj{bs: mockBS}
+
+	mockOS := NewMockStore(ctrl)
+
+	storjObj := storjObjects{storj: &b}
+
+	meta := objects.Meta{
+		SerializableMeta: objects.SerializableMeta{
+			ContentType: "media/foo",
+			UserDefined: map[string]string{"userdef_key1": "userdef_val1"},
+		},
+		Size:     1234,
+		Checksum: "test-checksum",
+	}
+
+	for i, example := range []struct {
+		srcObject, destObject string
+		copyErr               error
+		errString             string
+	}{
+		// happy scenario
+		{"mySrcObj", "myDestObj", nil, ""},
+		// error returned by the objects.Copy()
+		{"mySrcObj", "myDestObj", errors.New("some Copy err"), "some Copy err"},
+		// object copied to itself
+		{"mySrcObj", "mySrcObj", nil, ""},
+	} {
+		errTag := fmt.Sprintf("Test case #%d", i)
+
+		srcInfo := minio.ObjectInfo{
+			Bucket:      "mybucket",
+			Name:        example.srcObject,
+			Size:        meta.Size,
+			ContentType: meta.ContentType,
+			UserDefined: meta.UserDefined,
+		}
+
+		// the metadata isn't replaced, so the object isn't downloaded and
+		// uploaded again, but copied by pointerdb
+		mockBS.EXPECT().GetObjectStore(gomock.Any(), "mybucket").Return(mockOS, nil)
+		mockOS.EXPECT().Meta(gomock.Any(), example.srcObject).Return(meta, nil)
+		if example.srcObject != example.destObject {
+			mockBS.EXPECT().GetObjectStore(gomock.Any(), "mybucket").Return(mockOS, nil)
+			mockOS.EXPECT().Copy(gomock.Any(), example.srcObject, example.destObject).Return(meta, example.copyErr)
+		}
+
+		objInfo, err := storjObj.CopyObject(ctx, "mybucket", example.srcObject, "mybucket", example.destObject, srcInfo)
+		if example.errString != "" {
+			assert.EqualError(t, err, example.errString, errTag)
+			continue
+		}
+		if assert.NoError(t, err, errTag) {
+			assert.Equal(t, "mybucket", objInfo.Bucket, errTag)
+			assert.Equal(t, example.destObject, objInfo.Name, errTag)
+			assert.Equal(t, meta.Size, objInfo.Size, errTag)
+			assert.Equal(t, meta.ContentType, objInfo.ContentType, errTag)
+			assert.Equal(t, meta.UserDefined, objInfo.UserDefined, errTag)
+		}
+	}
+}
+
 func TestGetObject(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -200,6 +273,69 @@ func TestDeleteObject(t *testing.T) {
 	}
 }
 
+func TestDeleteObjectsInParallel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBS := mock_buckets.NewMockStore(ctrl)
+	b := Storj{bs: mockBS, deletes: newDeleteLimiter(2)}
+
+	mockOS := NewMockStore(ctrl)
+
+	storjObj := storjObjects{storj: &b}
+
+	var mu sync.Mutex
+	running := map[string]int{}
+	max := map[string]int{}
+	mockBS.EXPECT().GetObjectStore(gomock.Any(), "mybucket").Return(mockOS, nil).Times(13)
+	mockOS.EXPECT().Delete(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, path storj.Path) {
+		request := logger.GetReqInfo(ctx).RequestID
+		mu.Lock()
+		running[request]++
+		if running[request] > max[request] {
+			max[request] = running[request]
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running[request]--
+		mu.Unlock()
+	}).Return(nil).Times(13)
+
+	// the objects of each multi-object delete are deleted at most two at
+	// once, whatever the other requests delete
+	var wg sync.WaitGroup
+	for _, request := range []string{"request1", "request2"} {
+		reqCtx := logger.SetReqInfo(ctx, &logger.ReqInfo{RequestID: request, API: "DeleteMultipleObjects"})
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, storjObj.DeleteObject(reqCtx, "mybucket", fmt.Sprintf("myobject%d", i)))
+			}(i)
+		}
+	}
+	wg.Wait()
+	assert.Equal(t, map[string]int{"request1": 2, "request2": 2}, max)
+	assert.Empty(t, b.deletes.requests)
+
+	// deletes waiting for their turn give up once their context is canceled
+	reqCtx := logger.SetReqInfo(ctx, &logger.ReqInfo{RequestID: "request3", API: "DeleteMultipleObjects"})
+	for i := 0; i < 2; i++ {
+		release, err := b.deletes.acquire(reqCtx)
+		if assert.NoError(t, err) {
+			defer release()
+		}
+	}
+	canceled, cancel := context.WithCancel(reqCtx)
+	cancel()
+	assert.Equal(t, context.Canceled, storjObj.DeleteObject(canceled, "mybucket", "myobject"))
+
+	// while the deletes of other requests aren't held up
+	other := logger.SetReqInfo(ctx, &logger.ReqInfo{RequestID: "request4", API: "DeleteMultipleObjects"})
+	assert.NoError(t, storjObj.DeleteObject(other, "mybucket", "myobject"))
+}
+
 func TestPutObject(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -371,10 +507,10 @@ func TestListObjects(t *testing.T) {
 			}, err: nil, errString: "",
 		},
 		{
-			more: true, startAfter: "test-start-after", nextMarker: "test-file-2.txt", delimiter: "/", recursive: false,
+			more: true, startAfter: "test-start-after", nextMarker: "test-prefix/test-file-2.txt", delimiter: "/", recursive: false,
 			objInfos: []minio.ObjectInfo{
-				{Bucket: bucket, Name: "test-file-1.txt"},
-				{Bucket: bucket, Name: "test-file-2.txt"},
+				{Bucket: bucket, Name: "test-prefix/test-file-1.txt"},
+				{Bucket: bucket, Name: "test-prefix/test-file-2.txt"},
 			}, err: nil, errString: "",
 		},
 		{
@@ -408,6 +544,54 @@ func TestListObjects(t *testing.T) {
 	}
 }
 
+func TestListObjectsV2(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBS := mock_buckets.NewMockStore(ctrl)
+	b := Storj{bs: mockBS}
+
+	mockOS := NewMockStore(ctrl)
+
+	storjObj := storjObjects{storj: &b}
+
+	bucket := "test-bucket"
+	prefix := "test-prefix/"
+	maxKeys := 2
+
+	items := []objects.ListItem{
+		{Path: "test-file-1.txt"},
+		{Path: "test-dir/", IsPrefix: true},
+	}
+
+	for i, example := range []struct {
+		continuationToken string
+		startAfter        string
+		listStartAfter    string
+	}{
+		{"", "", ""},
+		// the markers are relative to the prefix when they're listed
+		{"", "test-prefix/test-file-0.txt", "test-file-0.txt"},
+		// and the continuation token takes precedence over start after
+		{"test-prefix/test-dir/", "test-prefix/test-file-0.txt", "test-dir/"},
+	} {
+		errTag := fmt.Sprintf("Test case #%d", i)
+
+		mockBS.EXPECT().GetObjectStore(gomock.Any(), bucket).Return(mockOS, nil)
+		mockOS.EXPECT().List(gomock.Any(), prefix, example.listStartAfter, "", false, maxKeys, meta.All).Return(items, true, nil)
+
+		listInfo, err := storjObj.ListObjectsV2(ctx, bucket, prefix, example.continuationToken, "/", maxKeys, false, example.startAfter)
+		if !assert.NoError(t, err, errTag) {
+			continue
+		}
+		assert.True(t, listInfo.IsTruncated, errTag)
+		assert.Equal(t, example.continuationToken, listInfo.ContinuationToken, errTag)
+		assert.Equal(t, "test-prefix/test-dir/", listInfo.NextContinuationToken, errTag)
+		assert.Equal(t, []minio.ObjectInfo{{Bucket: bucket, Name: "test-prefix/test-file-1.txt"}}, listInfo.Objects, errTag)
+		assert.Equal(t, []string{"test-prefix/test-dir/"}, listInfo.Prefixes, errTag)
+	}
+}
+
 func TestDeleteBucket(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -507,7 +691,7 @@ func TestMakeBucketWithLocation(t *testing.T) {
 		errTag := fmt.Sprintf("Test case #%d", i)
 		mockBS.EXPECT().Get(gomock.Any(), gomock.Any()).Return(buckets.Meta{Created: exp}, example.bucketStatus)
 		if storage.ErrKeyNotFound.Has(example.bucketStatus) {
-			mockBS.EXPECT().Put(gomock.Any(), example.bucket).Return(buckets.Meta{Created: example.meta}, nil)
+			mockBS.EXPECT().Put(gomock.Any(), example.bucket, storj.RedundancyScheme{}, int64(0)).Return(buckets.Meta{Created: example.meta}, nil)
 		}
 
 		err := storjObj.MakeBucketWithLocation(ctx, example.bucket, "location")
@@ -5,9 +5,11 @@ package miniogw
 
 import (
 	"context"
+	"encoding/hex"
 	"io"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -89,10 +91,12 @@ func (s *storjObjects) PutObjectPart(ctx context.Context, bucket, object, upload
 		return minio.PartInfo{}, err
 	}
 
+	// the entity tag of a part is the MD5 of its data, like in S3, since
+	// some clients verify it
 	partInfo := minio.PartInfo{
-		PartNumber:   part.Number,
+		PartNumber:   part.ID,
 		LastModified: time.Now(),
-		ETag:         data.SHA256HexString(),
+		ETag:         hex.EncodeToString(data.MD5Current()),
 		Size:         atomic.LoadInt64(&part.Size),
 	}
 
@@ -111,12 +115,10 @@ func (s *storjObjects) AbortMultipartUpload(ctx context.Context, bucket, object,
 		return err
 	}
 
-	errAbort := Error.New("abort")
-	upload.Stream.Abort(errAbort)
-	r := <-upload.Done
-	if r.Error != errAbort {
-		return r.Error
-	}
+	// the upload fails with the error it's aborted with, or with the one it
+	// failed with before, either of which is expected
+	upload.Stream.Abort(Error.New("abort"))
+	<-upload.Done
 	return nil
 }
 
@@ -129,6 +131,14 @@ func (s *storjObjects) CompleteMultipartUpload(ctx context.Context, bucket, obje
 		return minio.ObjectInfo{}, err
 	}
 
+	// the parts are already streamed into the object, so the upload fails
+	// if the client wants to complete it with other parts
+	if !upload.hasCompletedParts(uploadedParts) {
+		upload.Stream.Abort(minio.InvalidPart{})
+		<-upload.Done
+		return minio.ObjectInfo{}, minio.InvalidPart{}
+	}
+
 	// notify stream that there aren't more parts coming
 	upload.Stream.Close()
 	// wait for completion
@@ -176,8 +186,67 @@ func (s *storjObjects) ListObjectParts(ctx context.Context, bucket, object, uplo
 	return list, nil
 }
 
+// ListMultipartUploads lists the pending uploads to the objects in bucket with
+// prefix, ordered by the object and when they were initiated
+func (s *storjObjects) ListMultipartUploads(ctx context.Context, bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (result minio.ListMultipartsInfo, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	result = minio.ListMultipartsInfo{
+		KeyMarker:      keyMarker,
+		UploadIDMarker: uploadIDMarker,
+		MaxUploads:     maxUploads,
+		Prefix:         prefix,
+		Delimiter:      delimiter,
+	}
+
+	pending := s.storj.multipart.List(bucket)
+
+	// the uploads after the one of the markers are listed, or all of the
+	// uploads to the objects after the key marker if there's no upload marker
+	start := sort.Search(len(pending), func(i int) bool {
+		return pending[i].Object > keyMarker
+	})
+	if uploadIDMarker != "" {
+		for i, upload := range pending {
+			if upload.Object == keyMarker && upload.ID == uploadIDMarker {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	prefixes := map[string]bool{}
+	for _, upload := range pending[start:] {
+		if !strings.HasPrefix(upload.Object, prefix) {
+			continue
+		}
+		if len(result.Uploads)+len(result.CommonPrefixes) >= maxUploads {
+			result.IsTruncated = true
+			break
+		}
+		if delimiter != "" {
+			if i := strings.Index(upload.Object[len(prefix):], delimiter); i >= 0 {
+				commonPrefix := upload.Object[:len(prefix)+i+len(delimiter)]
+				if !prefixes[commonPrefix] {
+					prefixes[commonPrefix] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix)
+				}
+				continue
+			}
+		}
+		result.Uploads = append(result.Uploads, minio.MultipartInfo{
+			Object:    upload.Object,
+			UploadID:  upload.ID,
+			Initiated: upload.Initiated,
+		})
+		result.NextKeyMarker = upload.Object
+		result.NextUploadIDMarker = upload.ID
+	}
+
+	return result, nil
+}
+
 // TODO: implement
-// func (s *storjObjects) ListMultipartUploads(ctx context.Context, bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (result minio.ListMultipartsInfo, err error) {
 // func (s *storjObjects) CopyObjectPart(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, uploadID string, partID int, startOffset int64, length int64, srcInfo minio.ObjectInfo) (info minio.PartInfo, err error) {
 
 // MultipartUploads manages pending multipart uploads
@@ -210,6 +279,7 @@ func (uploads *MultipartUploads) Create(bucket, object string, metadata map[stri
 	uploadID := "Upload" + strconv.Itoa(uploads.lastID)
 
 	upload := NewMultipartUpload(uploadID, bucket, object, metadata)
+	upload.number = uploads.lastID
 	uploads.pending[uploadID] = upload
 
 	return upload, nil
@@ -220,28 +290,17 @@ func (uploads *MultipartUploads) Get(bucket, object, uploadID string) (*Multipar
 	uploads.mu.Lock()
 	defer uploads.mu.Unlock()
 
-	upload, ok := uploads.pending[uploadID]
-	if !ok {
-		return nil, Error.New("pending upload %q missing", uploadID)
-	}
-	if upload.Bucket != bucket || upload.Object != object {
-		return nil, Error.New("pending upload %q bucket/object name mismatch", uploadID)
-	}
-
-	return upload, nil
+	return uploads.find(bucket, object, uploadID)
 }
 
 // Remove returns and removes a pending upload
 func (uploads *MultipartUploads) Remove(bucket, object, uploadID string) (*MultipartUpload, error) {
-	uploads.mu.RLock()
-	defer uploads.mu.RUnlock()
+	uploads.mu.Lock()
+	defer uploads.mu.Unlock()
 
-	upload, ok := uploads.pending[uploadID]
-	if !ok {
-		return nil, Error.New("pending upload %q missing", uploadID)
-	}
-	if upload.Bucket != bucket || upload.Object != object {
-		return nil, Error.New("pending upload %q bucket/object name mismatch", uploadID)
+	upload, err := uploads.find(bucket, object, uploadID)
+	if err != nil {
+		return nil, err
 	}
 
 	delete(uploads.pending, uploadID)
@@ -249,21 +308,56 @@ func (uploads *MultipartUploads) Remove(bucket, object, uploadID string) (*Multi
 	return upload, nil
 }
 
+// find returns the pending upload with uploadID to object in bucket, or an
+// error which S3 clients get as a missing upload
+func (uploads *MultipartUploads) find(bucket, object, uploadID string) (*MultipartUpload, error) {
+	upload, ok := uploads.pending[uploadID]
+	if !ok || upload.Bucket != bucket || upload.Object != object {
+		return nil, minio.InvalidUploadID{UploadID: uploadID}
+	}
+	return upload, nil
+}
+
 // RemoveByID removes pending upload by id
 func (uploads *MultipartUploads) RemoveByID(uploadID string) {
+	uploads.mu.Lock()
+	defer uploads.mu.Unlock()
+	delete(uploads.pending, uploadID)
+}
+
+// List returns the pending uploads to bucket, ordered by their object and when
+// they were initiated
+func (uploads *MultipartUploads) List(bucket string) []*MultipartUpload {
 	uploads.mu.RLock()
 	defer uploads.mu.RUnlock()
-	delete(uploads.pending, uploadID)
+
+	var list []*MultipartUpload
+	for _, upload := range uploads.pending {
+		if upload.Bucket == bucket {
+			list = append(list, upload)
+		}
+	}
+	sort.Slice(list, func(i, k int) bool {
+		if list[i].Object != list[k].Object {
+			return list[i].Object < list[k].Object
+		}
+		return list[i].number < list[k].number
+	})
+	return list
 }
 
 // MultipartUpload is partial info about a pending upload
 type MultipartUpload struct {
-	ID       string
-	Bucket   string
-	Object   string
-	Metadata map[string]string
-	Done     chan (*MultipartUploadResult)
-	Stream   *MultipartStream
+	ID        string
+	Bucket    string
+	Object    string
+	Metadata  map[string]string
+	Initiated time.Time
+	Done      chan (*MultipartUploadResult)
+	Stream    *MultipartStream
+
+	// number orders the uploads by when they were created
+	number int
 
 	mu        sync.Mutex
 	completed []minio.PartInfo
@@ -278,12 +372,13 @@ type MultipartUploadResult struct {
 // NewMultipartUpload creates a new MultipartUpload
 func NewMultipartUpload(uploadID string, bucket, object string, metadata map[string]string) *MultipartUpload {
 	upload := &MultipartUpload{
-		ID:       uploadID,
-		Bucket:   bucket,
-		Object:   object,
-		Metadata: metadata,
-		Done:     make(chan *MultipartUploadResult, 1),
-		Stream:   NewMultipartStream(),
+		ID:        uploadID,
+		Bucket:    bucket,
+		Object:    object,
+		Metadata:  metadata,
+		Initiated: time.Now(),
+		Done:      make(chan *MultipartUploadResult, 1),
+		Stream:    NewMultipartStream(),
 	}
 	return upload
 }
@@ -303,6 +398,28 @@ func (upload *MultipartUpload) getCompletedParts() []minio.PartInfo {
 	return append([]minio.PartInfo{}, upload.completed...)
 }
 
+// hasCompletedParts returns whether parts are the completed parts of the
+// upload, with the same entity tags
+func (upload *MultipartUpload) hasCompletedParts(parts []minio.CompletePart) bool {
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+
+	if len(parts) != len(upload.completed) {
+		return false
+	}
+	etags := map[int]string{}
+	for _, part := range upload.completed {
+		etags[part.PartNumber] = part.ETag
+	}
+	for _, part := range parts {
+		etag, ok := etags[part.PartNumber]
+		if !ok || (part.ETag != "" && part.ETag != etag) {
+			return false
+		}
+	}
+	return true
+}
+
 // fail aborts the upload with an error
 func (upload *MultipartUpload) fail(err error) {
 	upload.Done <- &MultipartUploadResult{Error: err}
@@ -317,23 +434,25 @@ func (upload *MultipartUpload) complete(info minio.ObjectInfo) {
 
 // MultipartStream serializes multiple readers into a single reader
 type MultipartStream struct {
-	mu         sync.Mutex
-	moreParts  sync.Cond
-	err        error
-	closed     bool
-	finished   bool
-	nextID     int
-	nextNumber int
-	parts      []*StreamPart
+	mu        sync.Mutex
+	moreParts sync.Cond
+	err       error
+	closed    bool
+	finished  bool
+	nextID    int
+	parts     []*StreamPart
 }
 
 // StreamPart is a reader waiting in MultipartStream
 type StreamPart struct {
-	Number int
 	ID     int
 	Size   int64
 	Reader *hash.Reader
 	Done   chan error
+
+	// reading is set once the part is read from, after which it can't be
+	// replaced anymore
+	reading bool
 }
 
 // NewMultipartStream creates a new MultipartStream
@@ -384,17 +503,25 @@ func (stream *MultipartStream) Read(data []byte) (n int, err error) {
 	for {
 		// has an error occurred?
 		if stream.err != nil {
+			err = stream.err
 			stream.mu.Unlock()
 			return 0, Error.Wrap(err)
 		}
 		// do we have the next part?
 		if len(stream.parts) > 0 && stream.nextID == stream.parts[0].ID {
 			part = stream.parts[0]
+			part.reading = true
 			break
 		}
-		// we don't have the next part and are closed, hence we are complete
+		// we don't have the next part and are closed, hence we are complete.
+		// The parts after a missing one are never streamed, so they fail.
 		if stream.closed {
 			stream.finished = true
+			for _, part := range stream.parts {
+				part.Done <- Error.New("Part %d follows missing part %d", part.ID, stream.nextID)
+				close(part.Done)
+			}
+			stream.parts = nil
 			stream.mu.Unlock()
 			return 0, io.EOF
 		}
@@ -411,12 +538,18 @@ func (stream *MultipartStream) Read(data []byte) (n int, err error) {
 		// the part completed, hence advance to the next one
 		err = nil
 
+		// the parts are already done if the stream was aborted meanwhile
 		stream.mu.Lock()
-		stream.parts = stream.parts[1:]
-		stream.nextID++
+		aborted := stream.finished
+		if !aborted {
+			stream.parts = stream.parts[1:]
+			stream.nextID++
+		}
 		stream.mu.Unlock()
 
-		close(part.Done)
+		if !aborted {
+			close(part.Done)
+		}
 	} else if err != nil {
 		// something bad happened, abort the whole thing
 		stream.Abort(err)
@@ -426,20 +559,37 @@ func (stream *MultipartStream) Read(data []byte) (n int, err error) {
 	return n, err
 }
 
-// AddPart adds a new part to the stream to wait
+// AddPart adds a new part to the stream to wait. The parts are read in the
+// order of their IDs, so a part can't be added once it's read or the parts
+// after it are. A part added again before it's read, like when a client
+// retries it, replaces the pending one, which fails.
 func (stream *MultipartStream) AddPart(partID int, data *hash.Reader) (*StreamPart, error) {
 	stream.mu.Lock()
 	defer stream.mu.Unlock()
 
-	for _, p := range stream.parts {
-		if p.ID == partID {
-			return nil, Error.New("Part %d already exists", partID)
+	if stream.err != nil {
+		return nil, stream.err
+	}
+	if stream.closed {
+		return nil, Error.New("upload is already completed")
+	}
+	if partID < stream.nextID {
+		return nil, Error.New("Part %d is already streamed", partID)
+	}
+	for i, p := range stream.parts {
+		if p.ID != partID {
+			continue
+		}
+		if p.reading {
+			return nil, Error.New("Part %d is already streamed", partID)
 		}
+		p.Done <- Error.New("Part %d is replaced by a new upload of it", partID)
+		close(p.Done)
+		stream.parts = append(stream.parts[:i], stream.parts[i+1:]...)
+		break
 	}
 
-	stream.nextNumber++
 	part := &StreamPart{
-		Number: stream.nextNumber - 1,
 		ID:     partID,
 		Size:   0,
 		Reader: data,
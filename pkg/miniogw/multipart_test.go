@@ -0,0 +1,200 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	minio "github.com/minio/minio/cmd"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mock_buckets "storj.io/storj/pkg/storage/buckets/mocks"
+	"storj.io/storj/pkg/storage/objects"
+)
+
+func newPartReader(t *testing.T, data string) *hash.Reader {
+	r, err := hash.NewReader(bytes.NewReader([]byte(data)), int64(len(data)), "", "")
+	require.NoError(t, err)
+	return r
+}
+
+func md5Hex(data string) string {
+	sum := md5.Sum([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestMultipartUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBS := mock_buckets.NewMockStore(ctrl)
+	mockOS := NewMockStore(ctrl)
+	storjObj := storjObjects{storj: &Storj{bs: mockBS, multipart: NewMultipartUploads()}}
+
+	var uploaded []byte
+	mockBS.EXPECT().GetObjectStore(gomock.Any(), "bucket").Return(mockOS, nil)
+	mockOS.EXPECT().Put(gomock.Any(), "object", gomock.Any(), gomock.Any(), time.Time{}).DoAndReturn(
+		func(ctx context.Context, path string, data io.Reader, metadata objects.SerializableMeta, expiration time.Time) (objects.Meta, error) {
+			var err error
+			uploaded, err = ioutil.ReadAll(data)
+			return objects.Meta{SerializableMeta: metadata, Size: int64(len(uploaded))}, err
+		})
+
+	uploadID, err := storjObj.NewMultipartUpload(ctx, "bucket", "object", map[string]string{"content-type": "text/plain"})
+	require.NoError(t, err)
+
+	// the parts are uploaded at once, in any order
+	parts := []string{"first ", "second ", "third"}
+	var wg sync.WaitGroup
+	for i := len(parts) - 1; i >= 0; i-- {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			info, err := storjObj.PutObjectPart(ctx, "bucket", "object", uploadID, i+1, newPartReader(t, parts[i]))
+			if assert.NoError(t, err) {
+				assert.Equal(t, i+1, info.PartNumber)
+				assert.Equal(t, md5Hex(parts[i]), info.ETag)
+				assert.Equal(t, int64(len(parts[i])), info.Size)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	list, err := storjObj.ListObjectParts(ctx, "bucket", "object", uploadID, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, []int{list.Parts[0].PartNumber, list.Parts[1].PartNumber})
+	assert.True(t, list.IsTruncated)
+
+	// a streamed part can't be uploaded again
+	_, err = storjObj.PutObjectPart(ctx, "bucket", "object", uploadID, 2, newPartReader(t, "again"))
+	assert.Error(t, err)
+
+	info, err := storjObj.CompleteMultipartUpload(ctx, "bucket", "object", uploadID, []minio.CompletePart{
+		{PartNumber: 1, ETag: md5Hex(parts[0])},
+		{PartNumber: 2, ETag: md5Hex(parts[1])},
+		{PartNumber: 3, ETag: md5Hex(parts[2])},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "first second third", string(uploaded))
+	assert.Equal(t, int64(len(uploaded)), info.Size)
+	assert.Equal(t, "text/plain", info.ContentType)
+
+	// the upload isn't pending anymore
+	_, err = storjObj.CompleteMultipartUpload(ctx, "bucket", "object", uploadID, nil)
+	assert.Equal(t, minio.InvalidUploadID{UploadID: uploadID}, err)
+}
+
+func TestMultipartUploadInvalidParts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBS := mock_buckets.NewMockStore(ctrl)
+	mockOS := NewMockStore(ctrl)
+	storjObj := storjObjects{storj: &Storj{bs: mockBS, multipart: NewMultipartUploads()}}
+
+	mockBS.EXPECT().GetObjectStore(gomock.Any(), "bucket").Return(mockOS, nil).Times(2)
+	mockOS.EXPECT().Put(gomock.Any(), "object", gomock.Any(), gomock.Any(), time.Time{}).DoAndReturn(
+		func(ctx context.Context, path string, data io.Reader, metadata objects.SerializableMeta, expiration time.Time) (objects.Meta, error) {
+			_, err := ioutil.ReadAll(data)
+			return objects.Meta{}, err
+		}).Times(2)
+
+	// completing an upload with other parts than the uploaded ones fails it
+	uploadID, err := storjObj.NewMultipartUpload(ctx, "bucket", "object", map[string]string{})
+	require.NoError(t, err)
+	_, err = storjObj.PutObjectPart(ctx, "bucket", "object", uploadID, 1, newPartReader(t, "data"))
+	require.NoError(t, err)
+	_, err = storjObj.CompleteMultipartUpload(ctx, "bucket", "object", uploadID, []minio.CompletePart{
+		{PartNumber: 1, ETag: md5Hex("other data")},
+	})
+	assert.Equal(t, minio.InvalidPart{}, err)
+
+	// aborted uploads don't take parts anymore
+	uploadID, err = storjObj.NewMultipartUpload(ctx, "bucket", "object", map[string]string{})
+	require.NoError(t, err)
+	upload, err := storjObj.storj.multipart.Get("bucket", "object", uploadID)
+	require.NoError(t, err)
+	assert.NoError(t, storjObj.AbortMultipartUpload(ctx, "bucket", "object", uploadID))
+	_, err = upload.Stream.AddPart(1, newPartReader(t, "data"))
+	assert.Error(t, err)
+}
+
+func TestMultipartStream(t *testing.T) {
+	stream := NewMultipartStream()
+
+	// a part sent again before it's read replaces the pending one
+	replaced, err := stream.AddPart(2, newPartReader(t, "lost"))
+	require.NoError(t, err)
+	second, err := stream.AddPart(2, newPartReader(t, "second "))
+	require.NoError(t, err)
+	assert.Error(t, <-replaced.Done)
+
+	first, err := stream.AddPart(1, newPartReader(t, "first "))
+	require.NoError(t, err)
+	fourth, err := stream.AddPart(4, newPartReader(t, "fourth"))
+	require.NoError(t, err)
+
+	// completing the upload without the third part ends the stream there,
+	// and the parts after it fail instead of waiting for it
+	stream.Close()
+	uploaded, err := ioutil.ReadAll(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "first second ", string(uploaded))
+	assert.NoError(t, <-first.Done)
+	assert.NoError(t, <-second.Done)
+	assert.Error(t, <-fourth.Done)
+}
+
+func TestListMultipartUploads(t *testing.T) {
+	storjObj := storjObjects{storj: &Storj{multipart: NewMultipartUploads()}}
+	uploads := storjObj.storj.multipart
+
+	var ids []string
+	for _, object := range []string{"b", "a", "dir/c", "dir/d", "b"} {
+		upload, err := uploads.Create("bucket", object, nil)
+		require.NoError(t, err)
+		ids = append(ids, upload.ID)
+	}
+	_, err := uploads.Create("other", "a", nil)
+	require.NoError(t, err)
+
+	objects := func(list minio.ListMultipartsInfo) (names []string) {
+		for _, upload := range list.Uploads {
+			names = append(names, upload.Object+":"+upload.UploadID)
+		}
+		return names
+	}
+
+	// a new upload to an object replaces the pending one
+	list, err := storjObj.ListMultipartUploads(ctx, "bucket", "", "", "", "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:" + ids[1], "b:" + ids[4], "dir/c:" + ids[2], "dir/d:" + ids[3]}, objects(list))
+	assert.False(t, list.IsTruncated)
+
+	list, err = storjObj.ListMultipartUploads(ctx, "bucket", "", "", "", "/", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:" + ids[1], "b:" + ids[4]}, objects(list))
+	assert.Equal(t, []string{"dir/"}, list.CommonPrefixes)
+
+	list, err = storjObj.ListMultipartUploads(ctx, "bucket", "dir/", "", "", "/", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dir/c:" + ids[2]}, objects(list))
+	assert.True(t, list.IsTruncated)
+
+	list, err = storjObj.ListMultipartUploads(ctx, "bucket", "dir/", list.NextKeyMarker, list.NextUploadIDMarker, "/", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dir/d:" + ids[3]}, objects(list))
+	assert.False(t, list.IsTruncated)
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	minioclient "github.com/minio/minio-go"
+)
+
+// presignRegion is the region the gateway checks the signatures of requests
+// with
+const presignRegion = "us-east-1"
+
+// Presign returns a URL to use method on object in bucket through the gateway
+// at endpoint, presigned with the access and secret key of the gateway, so
+// it can be used without them until it expires. The gateway checks the
+// signature and the expiration of the URL like the ones of the other
+// requests. Only GET, HEAD and PUT are presigned, and URLs can't be valid for
+// longer than 7 days.
+func (c MinioConfig) Presign(endpoint string, secure bool, method, bucket, object string, expires time.Duration) (*url.URL, error) {
+	client, err := minioclient.NewWithRegion(endpoint, c.AccessKey, c.SecretKey, secure, presignRegion)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	var u *url.URL
+	switch method {
+	case http.MethodGet:
+		u, err = client.PresignedGetObject(bucket, object, expires, nil)
+	case http.MethodHead:
+		u, err = client.PresignedHeadObject(bucket, object, expires, nil)
+	case http.MethodPut:
+		u, err = client.PresignedPutObject(bucket, object, expires)
+	default:
+		return nil, Error.New("method %s can't be presigned", method)
+	}
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return u, nil
+}
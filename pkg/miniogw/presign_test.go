@@ -0,0 +1,51 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresign(t *testing.T) {
+	c := MinioConfig{AccessKey: "access", SecretKey: "secret"}
+
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodPut} {
+		u, err := c.Presign("localhost:7777", false, method, "bucket", "dir/object", time.Hour)
+		if !assert.NoError(t, err, method) {
+			continue
+		}
+		assert.Equal(t, "http", u.Scheme, method)
+		assert.Equal(t, "localhost:7777", u.Host, method)
+		assert.Equal(t, "/bucket/dir/object", u.Path, method)
+
+		query := u.Query()
+		assert.Equal(t, "AWS4-HMAC-SHA256", query.Get("X-Amz-Algorithm"), method)
+		assert.True(t, strings.HasPrefix(query.Get("X-Amz-Credential"), "access/"), method)
+		assert.True(t, strings.Contains(query.Get("X-Amz-Credential"), "/"+presignRegion+"/s3/"), method)
+		assert.Equal(t, "3600", query.Get("X-Amz-Expires"), method)
+		assert.NotEmpty(t, query.Get("X-Amz-Signature"), method)
+	}
+
+	// the signature depends on the secret key
+	u, err := c.Presign("localhost:7777", true, http.MethodGet, "bucket", "object", time.Hour)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https", u.Scheme)
+		other, err := MinioConfig{AccessKey: "access", SecretKey: "other"}.Presign("localhost:7777", true, http.MethodGet, "bucket", "object", time.Hour)
+		if assert.NoError(t, err) {
+			assert.NotEqual(t, u.Query().Get("X-Amz-Signature"), other.Query().Get("X-Amz-Signature"))
+		}
+	}
+
+	for _, expires := range []time.Duration{0, 8 * 24 * time.Hour} {
+		_, err := c.Presign("localhost:7777", false, http.MethodGet, "bucket", "object", expires)
+		assert.True(t, Error.Has(err), expires.String())
+	}
+	_, err = c.Presign("localhost:7777", false, http.MethodDelete, "bucket", "object", time.Hour)
+	assert.True(t, Error.Has(err))
+}
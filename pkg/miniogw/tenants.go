@@ -0,0 +1,404 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/utils"
+)
+
+// tenantsReloadInterval is how often the tenants file is checked for changes
+const tenantsReloadInterval = 10 * time.Second
+
+// Tenant is an S3 access key served by the gateway, with the satellite api
+// key and the encryption passphrase, or the access, its objects are stored
+// with
+type Tenant struct {
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+	APIKey    string `json:"api_key"`
+	EncKey    string `json:"enc_key"`
+	Access    string `json:"access"`
+}
+
+// LoadTenants loads the tenants of the JSON file at path, which holds a list
+// of them, by their access keys
+func LoadTenants(path string) (map[string]Tenant, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	var list []Tenant
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, Error.New("invalid tenants file %s: %v", path, err)
+	}
+
+	tenants := make(map[string]Tenant, len(list))
+	for _, tenant := range list {
+		// these are the lengths minio accepts
+		if len(tenant.AccessKey) < 3 || len(tenant.SecretKey) < 8 {
+			return nil, Error.New("tenant %q needs an access key of at least 3 characters and a secret key of at least 8", tenant.AccessKey)
+		}
+		if _, ok := tenants[tenant.AccessKey]; ok {
+			return nil, Error.New("tenant %q is repeated", tenant.AccessKey)
+		}
+		if tenant.Access == "" && tenant.APIKey == "" {
+			return nil, Error.New("tenant %q needs an api key or an access", tenant.AccessKey)
+		}
+		if tenant.Access != "" {
+			if _, err := ParseAccess(tenant.Access); err != nil {
+				return nil, Error.New("tenant %q: %v", tenant.AccessKey, err)
+			}
+		}
+		tenants[tenant.AccessKey] = tenant
+	}
+	return tenants, nil
+}
+
+// accessKeyOf returns the access key request r is signed with, with either
+// signature version, in its headers or presigned in its URL
+func accessKeyOf(r *http.Request) string {
+	query := r.URL.Query()
+	if credential := query.Get("X-Amz-Credential"); credential != "" {
+		return strings.SplitN(credential, "/", 2)[0]
+	}
+	if accessKey := query.Get("AWSAccessKeyId"); accessKey != "" {
+		return accessKey
+	}
+
+	auth := r.Header.Get("Authorization")
+	switch {
+	case strings.HasPrefix(auth, "AWS4-HMAC-SHA256 "):
+		for _, field := range strings.Split(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 "), ",") {
+			field = strings.TrimSpace(field)
+			if strings.HasPrefix(field, "Credential=") {
+				return strings.SplitN(strings.TrimPrefix(field, "Credential="), "/", 2)[0]
+			}
+		}
+	case strings.HasPrefix(auth, "AWS "):
+		return strings.SplitN(strings.TrimPrefix(auth, "AWS "), ":", 2)[0]
+	}
+	return ""
+}
+
+// tenantGateway is the gateway process serving a tenant
+type tenantGateway struct {
+	tenant Tenant
+	proxy  *httputil.ReverseProxy
+	cmd    *exec.Cmd
+	// exited is closed when the process exits
+	exited chan struct{}
+}
+
+// running returns whether the process of the gateway is running
+func (gw *tenantGateway) running() bool {
+	if gw.exited == nil {
+		return true
+	}
+	select {
+	case <-gw.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// stop stops the process of the gateway
+func (gw *tenantGateway) stop() {
+	if gw.cmd == nil || !gw.running() {
+		return
+	}
+	if err := gw.cmd.Process.Signal(os.Interrupt); err != nil {
+		zap.S().Warnf("Failed stopping the gateway of tenant %s: %v", gw.tenant.AccessKey, err)
+	}
+	select {
+	case <-gw.exited:
+	case <-time.After(10 * time.Second):
+		_ = gw.cmd.Process.Kill()
+		<-gw.exited
+	}
+}
+
+// TenantProxy serves several tenants, each with its own gateway. Minio only
+// serves a single access key, so every tenant is served by a gateway process
+// of its own, listening on localhost, and the requests are passed to the
+// gateway of the access key they're signed with, which checks the signature.
+type TenantProxy struct {
+	config Config
+	path   string
+
+	mu       sync.Mutex
+	tenants  map[string]Tenant
+	gateways map[string]*tenantGateway
+	modified time.Time
+}
+
+// NewTenantProxy returns a TenantProxy serving the tenants of the file at
+// path, started with the configuration c
+func NewTenantProxy(c Config, path string) *TenantProxy {
+	return &TenantProxy{config: c, path: path, gateways: map[string]*tenantGateway{}}
+}
+
+// ServeHTTP implements http.Handler
+func (p *TenantProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	gw, ok := p.gateways[accessKeyOf(r)]
+	p.mu.Unlock()
+	if !ok {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(xml.Header + "<Error><Code>InvalidAccessKeyId</Code>" +
+			"<Message>The access key ID you provided does not exist in our records.</Message></Error>"))
+		return
+	}
+	gw.proxy.ServeHTTP(w, r)
+}
+
+// Run serves the tenants on the address of the configuration until ctx is
+// done, reloading the tenants when their file changes
+func (p *TenantProxy) Run(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := p.reload(); err != nil {
+		return err
+	}
+	defer p.stopAll()
+
+	lis, err := net.Listen("tcp", p.config.Address)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	server := &http.Server{Handler: p}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	go func() {
+		ticker := time.NewTicker(tenantsReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := p.reload(); err != nil {
+					zap.S().Errorf("Failed reloading the tenants: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	err = server.Serve(lis)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return Error.Wrap(err)
+}
+
+// reload loads the tenants again if their file changed, starting and
+// stopping their gateways, and starts the gateways which exited again
+func (p *TenantProxy) reload() error {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+
+	// the gateways are stopped after the tenants are unlocked, so requests
+	// to the other tenants aren't held while they stop
+	var stopped []*tenantGateway
+	defer func() {
+		for _, gw := range stopped {
+			gw.stop()
+		}
+	}()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !info.ModTime().Equal(p.modified) {
+		tenants, err := LoadTenants(p.path)
+		if err != nil {
+			return err
+		}
+		p.tenants = tenants
+		p.modified = info.ModTime()
+	}
+
+	for accessKey, gw := range p.gateways {
+		if tenant, ok := p.tenants[accessKey]; !ok || tenant != gw.tenant || !gw.running() {
+			stopped = append(stopped, gw)
+			delete(p.gateways, accessKey)
+		}
+	}
+	var errs []error
+	for accessKey, tenant := range p.tenants {
+		if _, ok := p.gateways[accessKey]; ok {
+			continue
+		}
+		gw, err := p.start(tenant)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		p.gateways[accessKey] = gw
+	}
+	if len(errs) > 0 {
+		return Error.New("failed starting the gateways of %d tenants, the first with: %v", len(errs), errs[0])
+	}
+	return nil
+}
+
+// tenantListenerEnv is the environment variable telling a tenant gateway
+// that it was passed the listener of its address as its first extra file
+const tenantListenerEnv = "STORJ_TENANT_LISTENER"
+
+// tenantSecrets are the flags of the secrets of a tenant, which are passed to
+// its gateway in its environment rather than its arguments, where any local
+// user could read them
+var tenantSecrets = []string{"secret-key", "api-key", "enc-key", "access"}
+
+// start starts a gateway process for tenant, running the command of this
+// process with the configuration of the tenant
+func (p *TenantProxy) start(tenant Tenant) (*tenantGateway, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	// the listener is passed to the gateway, so its port stays taken until
+	// the gateway listens on it
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	address := lis.Addr().String()
+	file, err := lis.(*net.TCPListener).File()
+	if err := utils.CombineErrors(err, lis.Close()); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { _ = file.Close() }()
+
+	// each tenant has a minio directory of its own, named by its access key
+	hash := sha256.Sum256([]byte(tenant.AccessKey))
+	minioDir := filepath.Join(p.config.MinioDir, "tenants", hex.EncodeToString(hash[:8]))
+
+	// the flags given last replace the ones of this process
+	args := append(withoutFlags(os.Args[1:], tenantSecrets...),
+		"--tenants=",
+		"--address="+address,
+		"--minio-dir="+minioDir,
+		"--access-key="+tenant.AccessKey,
+	)
+	env := append(os.Environ(), tenantListenerEnv+"=1")
+	for i, value := range []string{tenant.SecretKey, tenant.APIKey, tenant.EncKey, tenant.Access} {
+		if value == "" {
+			// empty environment variables are ignored, so the values of the
+			// configuration file of this process would be used instead
+			args = append(args, "--"+tenantSecrets[i]+"=")
+			continue
+		}
+		env = append(env, flagEnv(tenantSecrets[i])+"="+value)
+	}
+
+	cmd := exec.Command(executable, args...)
+	cmd.Env = env
+	cmd.ExtraFiles = []*os.File{file}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	gw := &tenantGateway{
+		tenant: tenant,
+		proxy:  httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: address}),
+		cmd:    cmd,
+		exited: make(chan struct{}),
+	}
+	go func() {
+		err := cmd.Wait()
+		zap.S().Warnf("The gateway of tenant %s exited: %v", tenant.AccessKey, err)
+		close(gw.exited)
+	}()
+	return gw, nil
+}
+
+// releaseTenantListener closes the listener passed to the gateway of a
+// tenant, if it's one, right before minio listens on its address. Minio
+// can't serve a listener it's given.
+func releaseTenantListener() error {
+	if os.Getenv(tenantListenerEnv) == "" {
+		return nil
+	}
+	// the first extra file is the file descriptor after stderr
+	return Error.Wrap(os.NewFile(3, "tenant listener").Close())
+}
+
+// flagEnv returns the environment variable setting the flag with name
+func flagEnv(name string) string {
+	return "STORJ_" + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// withoutFlags returns args without the flags with the given names, and
+// their values
+func withoutFlags(args []string, names ...string) []string {
+	result := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		flag := strings.TrimLeft(args[i], "-")
+		if flag == args[i] || args[i] == "--" {
+			result = append(result, args[i])
+			continue
+		}
+		name := strings.SplitN(flag, "=", 2)[0]
+		if !containsString(names, name) {
+			result = append(result, args[i])
+			continue
+		}
+		if !strings.Contains(flag, "=") {
+			// the value is the next argument
+			i++
+		}
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// stopAll stops the gateways of all the tenants
+func (p *TenantProxy) stopAll() {
+	p.mu.Lock()
+	gateways := p.gateways
+	p.gateways = map[string]*tenantGateway{}
+	p.mu.Unlock()
+
+	for _, gw := range gateways {
+		gw.stop()
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTenants(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tenants")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "tenants.json")
+	write := func(data string) {
+		if !assert.NoError(t, ioutil.WriteFile(path, []byte(data), 0600)) {
+			t.FailNow()
+		}
+	}
+
+	write(`[
+		{"access_key": "alice", "secret_key": "alice secret", "api_key": "alice api key", "enc_key": "alice passphrase"},
+		{"access_key": "bob", "secret_key": "bob secret", "api_key": "bob api key"}
+	]`)
+	tenants, err := LoadTenants(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]Tenant{
+			"alice": {AccessKey: "alice", SecretKey: "alice secret", APIKey: "alice api key", EncKey: "alice passphrase"},
+			"bob":   {AccessKey: "bob", SecretKey: "bob secret", APIKey: "bob api key"},
+		}, tenants)
+	}
+
+	for _, invalid := range []string{
+		`{"access_key": "alice"}`,
+		`[{"access_key": "al", "secret_key": "alice secret", "api_key": "api key"}]`,
+		`[{"access_key": "alice", "secret_key": "secret", "api_key": "api key"}]`,
+		`[{"access_key": "alice", "secret_key": "alice secret"}]`,
+		`[{"access_key": "alice", "secret_key": "alice secret", "access": "not base58 0OIl"}]`,
+		`[{"access_key": "alice", "secret_key": "alice secret", "api_key": "api key"},
+		  {"access_key": "alice", "secret_key": "other secret", "api_key": "api key"}]`,
+	} {
+		write(invalid)
+		_, err := LoadTenants(path)
+		assert.True(t, Error.Has(err), invalid)
+	}
+
+	_, err = LoadTenants(filepath.Join(dir, "missing.json"))
+	assert.True(t, Error.Has(err))
+}
+
+func TestAccessKeyOf(t *testing.T) {
+	for i, tt := range []struct {
+		url       string
+		auth      string
+		accessKey string
+	}{
+		{"/bucket/key", "AWS4-HMAC-SHA256 Credential=alice/20181016/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=abc", "alice"},
+		{"/bucket/key", "AWS bob:c2lnbmF0dXJl", "bob"},
+		{"/bucket/key?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Credential=alice%2F20181016%2Fus-east-1%2Fs3%2Faws4_request", "", "alice"},
+		{"/bucket/key?AWSAccessKeyId=bob&Signature=abc&Expires=1539648000", "", "bob"},
+		{"/bucket/key", "", ""},
+		{"/bucket/key", "Bearer token", ""},
+	} {
+		r := httptest.NewRequest("GET", tt.url, nil)
+		if tt.auth != "" {
+			r.Header.Set("Authorization", tt.auth)
+		}
+		assert.Equal(t, tt.accessKey, accessKeyOf(r), i)
+	}
+}
+
+func TestTenantProxy(t *testing.T) {
+	gateway := func(name string) (*httptest.Server, *tenantGateway) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(name + " " + r.URL.Path))
+		}))
+		target, err := url.Parse(server.URL)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return server, &tenantGateway{
+			tenant: Tenant{AccessKey: name},
+			proxy:  httputil.NewSingleHostReverseProxy(target),
+		}
+	}
+	alice, aliceGateway := gateway("alice")
+	defer alice.Close()
+	bob, bobGateway := gateway("bob")
+	defer bob.Close()
+
+	p := NewTenantProxy(Config{}, "")
+	p.gateways["alice"] = aliceGateway
+	p.gateways["bob"] = bobGateway
+
+	for _, tt := range []struct {
+		auth   string
+		status int
+		body   string
+	}{
+		{"AWS alice:c2lnbmF0dXJl", http.StatusOK, "alice /bucket/key"},
+		{"AWS bob:c2lnbmF0dXJl", http.StatusOK, "bob /bucket/key"},
+		{"AWS carol:c2lnbmF0dXJl", http.StatusForbidden, ""},
+		{"", http.StatusForbidden, ""},
+	} {
+		r := httptest.NewRequest("GET", "/bucket/key", nil)
+		if tt.auth != "" {
+			r.Header.Set("Authorization", tt.auth)
+		}
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+		assert.Equal(t, tt.status, w.Code, tt.auth)
+		if tt.body != "" {
+			assert.Equal(t, tt.body, w.Body.String(), tt.auth)
+		}
+	}
+}
+
+func TestWithoutFlags(t *testing.T) {
+	args := []string{"run", "--api-key=secret", "--config", "gateway.yaml", "--enc-key", "passphrase",
+		"-access=restricted", "--access-key=alice", "--secret-key"}
+	assert.Equal(t, []string{"run", "--config", "gateway.yaml", "--access-key=alice"},
+		withoutFlags(args, tenantSecrets...))
+	assert.Equal(t, "STORJ_API_KEY", flagEnv("api-key"))
+}
@@ -5,22 +5,26 @@ package node
 
 import (
 	"context"
+	"time"
 
 	"github.com/zeebo/errs"
+	"storj.io/storj/pkg/ban"
 	"storj.io/storj/pkg/dht"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/provider"
+	"storj.io/storj/pkg/transport"
 )
 
 //NodeClientErr is the class for all errors pertaining to node client operations
 var NodeClientErr = errs.Class("node client error")
 
-// NewNodeClient instantiates a node client
-func NewNodeClient(identity *provider.FullIdentity, self pb.Node, dht dht.DHT) (Client, error) {
+// NewNodeClient instantiates a node client, which doesn't dial the nodes
+// banned by bans and notifies obs of the nodes it could or couldn't connect to
+func NewNodeClient(identity *provider.FullIdentity, self pb.Node, dht dht.DHT, bans *ban.List, obs ...transport.Observer) (Client, error) {
 	node := &Node{
 		dht:  dht,
 		self: self,
-		pool: NewConnectionPool(identity),
+		pool: NewConnectionPool(identity, bans, obs...),
 	}
 
 	node.pool.Init()
@@ -32,5 +36,7 @@ func NewNodeClient(identity *provider.FullIdentity, self pb.Node, dht dht.DHT) (
 type Client interface {
 	Lookup(ctx context.Context, to pb.Node, find pb.Node) ([]*pb.Node, error)
 	Ping(ctx context.Context, to pb.Node) (bool, error)
+	Leave(ctx context.Context, to pb.Node, downtime time.Duration) error
+	ExternalAddress(ctx context.Context, to pb.Node) (string, error)
 	Disconnect() error
 }
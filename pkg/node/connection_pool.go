@@ -10,6 +10,7 @@ import (
 	"github.com/zeebo/errs"
 	"google.golang.org/grpc"
 
+	"storj.io/storj/pkg/ban"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/provider"
 	"storj.io/storj/pkg/transport"
@@ -39,10 +40,14 @@ type Conn struct {
 // NewConn intitalizes a new Conn struct with the provided address, but does not iniate a connection
 func NewConn(addr string) *Conn { return &Conn{addr: addr} }
 
-// NewConnectionPool initializes a new in memory pool
-func NewConnectionPool(identity *provider.FullIdentity) *ConnectionPool {
+// NewConnectionPool initializes a new in memory pool, which doesn't dial the
+// nodes banned by bans and notifies obs of the nodes it could or couldn't
+// connect to
+func NewConnectionPool(identity *provider.FullIdentity, bans *ban.List, obs ...transport.Observer) *ConnectionPool {
+	tc := transport.NewClient(identity, obs...)
+	tc.SetBans(bans)
 	return &ConnectionPool{
-		tc:    transport.NewClient(identity),
+		tc:    tc,
 		items: make(map[string]*Conn),
 		mu:    sync.RWMutex{},
 	}
@@ -22,7 +22,7 @@ func TestGet(t *testing.T) {
 	}{
 		{
 			pool: func() *ConnectionPool {
-				p := NewConnectionPool(newTestIdentity(t))
+				p := NewConnectionPool(newTestIdentity(t), nil)
 				p.Init()
 				p.items["foo"] = &Conn{addr: "foo"}
 				return p
@@ -84,7 +84,7 @@ func TestDial(t *testing.T) {
 		expected      *Conn
 	}{
 		{
-			pool:          NewConnectionPool(newTestIdentity(t)),
+			pool:          NewConnectionPool(newTestIdentity(t), nil),
 			node:          &pb.Node{Id: "foo", Address: &pb.NodeAddress{Address: "127.0.0.1:0"}},
 			expected:      nil,
 			expectedError: nil,
@@ -5,6 +5,10 @@ package node
 
 import (
 	"context"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
 
 	"storj.io/storj/pkg/dht"
 	"storj.io/storj/pkg/pb"
@@ -29,6 +33,12 @@ func (n *Node) Lookup(ctx context.Context, to pb.Node, find pb.Node) ([]*pb.Node
 		return nil, NodeClientErr.Wrap(err)
 	}
 
+	if resp.GetSenderUnreachable() {
+		zap.L().Warn("node could not be reached at its advertised address, check the NAT and firewall configuration",
+			zap.String("Address", n.self.GetAddress().GetAddress()),
+			zap.String("ReportedBy", to.GetId()))
+	}
+
 	rt, err := n.dht.GetRoutingTable(ctx)
 	if err != nil {
 		return nil, NodeClientErr.Wrap(err)
@@ -39,7 +49,19 @@ func (n *Node) Lookup(ctx context.Context, to pb.Node, find pb.Node) ([]*pb.Node
 
 	}
 
-	return resp.Response, nil
+	var nodes []*pb.Node
+	for _, n := range resp.Response {
+		if err := VerifyNode(n); err != nil {
+			zap.L().Warn("ignoring node with invalid signature",
+				zap.String("NodeID", n.GetId()),
+				zap.String("ReportedBy", to.GetId()),
+				zap.Error(err))
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+
+	return nodes, nil
 }
 
 // Ping attempts to establish a connection with a node to verify it is alive
@@ -57,6 +79,34 @@ func (n *Node) Ping(ctx context.Context, to pb.Node) (bool, error) {
 	return true, nil
 }
 
+// Leave tells a node that this node is going offline for about downtime
+func (n *Node) Leave(ctx context.Context, to pb.Node, downtime time.Duration) error {
+	c, err := n.pool.Dial(ctx, &to)
+	if err != nil {
+		return NodeClientErr.Wrap(err)
+	}
+
+	_, err = c.Leave(ctx, &pb.LeaveRequest{Sender: &n.self, ExpectedDowntime: ptypes.DurationProto(downtime)})
+	if err != nil {
+		return NodeClientErr.Wrap(err)
+	}
+	return nil
+}
+
+// ExternalAddress asks a node which address this node's requests come from
+func (n *Node) ExternalAddress(ctx context.Context, to pb.Node) (string, error) {
+	c, err := n.pool.Dial(ctx, &to)
+	if err != nil {
+		return "", NodeClientErr.Wrap(err)
+	}
+
+	resp, err := c.ExternalAddress(ctx, &pb.ExternalAddressRequest{})
+	if err != nil {
+		return "", NodeClientErr.Wrap(err)
+	}
+	return resp.GetAddress(), nil
+}
+
 // Disconnect closes all connections within the pool
 func (n *Node) Disconnect() error {
 	return n.pool.DisconnectAll()
@@ -64,7 +64,7 @@ func TestLookup(t *testing.T) {
 		identity, err := ca.NewIdentity()
 		assert.NoError(t, err)
 
-		nc, err := NewNodeClient(identity, v.self, mdht)
+		nc, err := NewNodeClient(identity, v.self, mdht, nil)
 		assert.NoError(t, err)
 
 		_, err = nc.Lookup(ctx, v.to, v.find)
@@ -98,7 +98,7 @@ func TestPing(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mdht := mock_dht.NewMockDHT(ctrl)
 		// set up a node server
-		srv := NewServer(mdht)
+		srv := NewServer(mdht, nil)
 
 		msrv, _, err := newTestServer(ctx, srv, v.toIdentity)
 		assert.NoError(t, err)
@@ -106,7 +106,7 @@ func TestPing(t *testing.T) {
 		ctx.Go(func() error { return msrv.Serve(lis) })
 		defer msrv.Stop()
 
-		nc, err := NewNodeClient(v.toIdentity, v.self, mdht)
+		nc, err := NewNodeClient(v.toIdentity, v.self, mdht, nil)
 		assert.NoError(t, err)
 
 		id := ID(v.toIdentity.ID)
@@ -139,6 +139,14 @@ func (mn *mockNodeServer) Query(ctx context.Context, req *pb.QueryRequest) (*pb.
 	return &pb.QueryResponse{}, nil
 }
 
+func (mn *mockNodeServer) Leave(ctx context.Context, req *pb.LeaveRequest) (*pb.LeaveResponse, error) {
+	return &pb.LeaveResponse{}, nil
+}
+
+func (mn *mockNodeServer) ExternalAddress(ctx context.Context, req *pb.ExternalAddressRequest) (*pb.ExternalAddressResponse, error) {
+	return &pb.ExternalAddressResponse{}, nil
+}
+
 func (mn *mockNodeServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
 	mn.pingCalled++
 	return &pb.PingResponse{}, nil
@@ -5,23 +5,35 @@ package node
 
 import (
 	"context"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/peer"
 
+	"storj.io/storj/pkg/ban"
 	"storj.io/storj/pkg/dht"
 	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/provider"
 )
 
+// maxDowntime caps the downtime a node can announce, so that nodes that
+// never come back don't hold on to their routing table entries
+const maxDowntime = 24 * time.Hour
+
 // Server implements the grpc Node Server
 type Server struct {
 	dht    dht.DHT
+	bans   *ban.List
 	logger *zap.Logger
 }
 
-// NewServer returns a newly instantiated Node Server
-func NewServer(dht dht.DHT) *Server {
+// NewServer returns a newly instantiated Node Server, which strikes the
+// peers making invalid requests in bans
+func NewServer(dht dht.DHT, bans *ban.List) *Server {
 	return &Server{
 		dht:    dht,
+		bans:   bans,
 		logger: zap.L(),
 	}
 }
@@ -36,19 +48,35 @@ func (s *Server) Query(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResp
 		return &pb.QueryResponse{}, NodeClientErr.New("could not get routing table %s", err)
 	}
 
+	// dial the sender back at its advertised address, so that a node behind
+	// a NAT learns that other nodes can't reach it
+	unreachable := false
 	if req.GetPingback() {
+		// only signed records are added to the routing table, otherwise
+		// anyone could map a node id to an arbitrary address
+		if err := VerifyNode(req.Sender); err != nil {
+			// the sender record is unverified, the peer is struck by the id
+			// it authenticated with instead
+			if identity, err := provider.PeerIdentityFromContext(ctx); err == nil {
+				s.bans.Strike(identity.ID.String(), peerHost(ctx))
+			}
+			return &pb.QueryResponse{}, NodeClientErr.Wrap(err)
+		}
+
 		_, err = s.dht.Ping(ctx, *req.Sender)
 		if err != nil {
+			unreachable = true
+			s.logger.Error("connection to node failed", zap.Error(err), zap.String("nodeID", req.Sender.Id))
+
 			err = rt.ConnectionFailed(req.Sender)
 			if err != nil {
 				s.logger.Error("could not respond to connection failed", zap.Error(err))
 			}
-			s.logger.Error("connection to node failed", zap.Error(err), zap.String("nodeID", req.Sender.Id))
-		}
-
-		err = rt.ConnectionSuccess(req.Sender)
-		if err != nil {
-			s.logger.Error("could not respond to connection success", zap.Error(err))
+		} else {
+			err = rt.ConnectionSuccess(req.Sender)
+			if err != nil {
+				s.logger.Error("could not respond to connection success", zap.Error(err))
+			}
 		}
 	}
 
@@ -58,7 +86,61 @@ func (s *Server) Query(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResp
 		return &pb.QueryResponse{}, NodeClientErr.New("could not find near %s", err)
 	}
 
-	return &pb.QueryResponse{Sender: req.Sender, Response: nodes}, nil
+	return &pb.QueryResponse{Sender: req.Sender, Response: nodes, SenderUnreachable: unreachable}, nil
+}
+
+// Leave records that the sender is going offline temporarily, so that it
+// isn't treated as a failed node until it is expected back
+func (s *Server) Leave(ctx context.Context, req *pb.LeaveRequest) (*pb.LeaveResponse, error) {
+	if s.logger == nil {
+		s.logger = zap.L()
+	}
+
+	// only the node itself may announce its downtime
+	identity, err := provider.PeerIdentityFromContext(ctx)
+	if err != nil {
+		return &pb.LeaveResponse{}, NodeClientErr.Wrap(err)
+	}
+	if identity.ID.String() != req.GetSender().GetId() {
+		return &pb.LeaveResponse{}, NodeClientErr.New("node %s can't announce downtime of node %s", identity.ID, req.GetSender().GetId())
+	}
+
+	downtime, err := ptypes.Duration(req.GetExpectedDowntime())
+	if err != nil {
+		return &pb.LeaveResponse{}, NodeClientErr.Wrap(err)
+	}
+	if downtime > maxDowntime {
+		downtime = maxDowntime
+	}
+
+	rt, err := s.dht.GetRoutingTable(ctx)
+	if err != nil {
+		return &pb.LeaveResponse{}, NodeClientErr.New("could not get routing table %s", err)
+	}
+	if err := rt.ConnectionAway(req.Sender, time.Now().Add(downtime)); err != nil {
+		return &pb.LeaveResponse{}, NodeClientErr.Wrap(err)
+	}
+
+	s.logger.Info("node going offline", zap.String("nodeID", req.Sender.Id), zap.Duration("expectedDowntime", downtime))
+	return &pb.LeaveResponse{}, nil
+}
+
+// ExternalAddress returns the address the request was received from
+func (s *Server) ExternalAddress(ctx context.Context, req *pb.ExternalAddressRequest) (*pb.ExternalAddressResponse, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return &pb.ExternalAddressResponse{}, NodeClientErr.New("unknown peer address")
+	}
+	return &pb.ExternalAddressResponse{Address: p.Addr.String()}, nil
+}
+
+// peerHost returns the host the request was received from
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return ban.Host(p.Addr.String())
 }
 
 // Ping provides an easy way to verify a node is online and accepting requests
@@ -5,11 +5,18 @@ package node
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
 
 	"storj.io/storj/pkg/dht"
 	"storj.io/storj/pkg/dht/mocks"
@@ -22,7 +29,9 @@ func TestQuery(t *testing.T) {
 	mockDHT := mock_dht.NewMockDHT(ctrl)
 	mockRT := mock_dht.NewMockRoutingTable(ctrl)
 	s := &Server{dht: mockDHT}
-	sender := &pb.Node{Id: "A"}
+	identity := newTestIdentity(t)
+	sender := &pb.Node{Id: identity.ID.String(), Address: &pb.NodeAddress{Address: "127.0.0.1:7777"}}
+	assert.NoError(t, SignNode(sender, identity))
 	target := &pb.Node{Id: "B"}
 	node := &pb.Node{Id: "C"}
 	cases := []struct {
@@ -65,6 +74,19 @@ func TestQuery(t *testing.T) {
 			res:        &pb.QueryResponse{Sender: sender, Response: []*pb.Node{sender, node}},
 			err:        nil,
 		},
+		{caseName: "ping failure, report sender unreachable",
+			rt:         mockRT,
+			getRTErr:   nil,
+			pingNode:   pb.Node{},
+			pingErr:    errors.New("unreachable"),
+			successErr: nil,
+			failErr:    nil,
+			findNear:   []*pb.Node{target},
+			limit:      2,
+			nearErr:    nil,
+			res:        &pb.QueryResponse{Sender: sender, Response: []*pb.Node{target}, SenderUnreachable: true},
+			err:        nil,
+		},
 	}
 	for i, v := range cases {
 		req := pb.QueryRequest{Pingback: true, Sender: sender, Target: &pb.Node{Id: "B"}, Limit: int64(2)}
@@ -74,10 +96,8 @@ func TestQuery(t *testing.T) {
 			mockRT.EXPECT().ConnectionFailed(gomock.Any()).Return(v.failErr)
 		} else {
 			mockRT.EXPECT().ConnectionSuccess(gomock.Any()).Return(v.successErr)
-			if v.successErr == nil {
-				mockRT.EXPECT().FindNear(gomock.Any(), v.limit).Return(v.findNear, v.nearErr)
-			}
 		}
+		mockRT.EXPECT().FindNear(gomock.Any(), v.limit).Return(v.findNear, v.nearErr)
 		res, err := s.Query(context.Background(), &req)
 		if !assert.Equal(t, v.res, res) {
 			fmt.Printf("case %s (%v) failed\n", v.caseName, i)
@@ -87,3 +107,48 @@ func TestQuery(t *testing.T) {
 		}
 	}
 }
+
+func TestQueryUnsignedSender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDHT := mock_dht.NewMockDHT(ctrl)
+	mockRT := mock_dht.NewMockRoutingTable(ctrl)
+	s := &Server{dht: mockDHT}
+
+	mockDHT.EXPECT().GetRoutingTable(gomock.Any()).Return(mockRT, nil)
+
+	req := pb.QueryRequest{Pingback: true, Sender: &pb.Node{Id: "A"}, Target: &pb.Node{Id: "B"}, Limit: int64(2)}
+	_, err := s.Query(context.Background(), &req)
+	assert.True(t, SignatureErr.Has(err))
+}
+
+func TestLeave(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDHT := mock_dht.NewMockDHT(ctrl)
+	mockRT := mock_dht.NewMockRoutingTable(ctrl)
+	s := &Server{dht: mockDHT}
+
+	identity := newTestIdentity(t)
+	sender := &pb.Node{Id: identity.ID.String()}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{identity.Leaf, identity.CA},
+		}},
+	})
+
+	// requests must come from the leaving node
+	_, err := s.Leave(context.Background(), &pb.LeaveRequest{Sender: sender})
+	assert.True(t, NodeClientErr.Has(err))
+	_, err = s.Leave(ctx, &pb.LeaveRequest{Sender: &pb.Node{Id: "A"}})
+	assert.True(t, NodeClientErr.Has(err))
+
+	// downtime is capped
+	mockDHT.EXPECT().GetRoutingTable(gomock.Any()).Return(mockRT, nil)
+	mockRT.EXPECT().ConnectionAway(sender, gomock.Any()).DoAndReturn(func(_ *pb.Node, until time.Time) error {
+		assert.True(t, until.Before(time.Now().Add(maxDowntime+time.Minute)))
+		return nil
+	})
+	_, err = s.Leave(ctx, &pb.LeaveRequest{Sender: sender, ExpectedDowntime: ptypes.DurationProto(48 * time.Hour)})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package node
+
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/peertls"
+	"storj.io/storj/pkg/provider"
+)
+
+// SignatureErr is the class for errors signing or verifying node records
+var SignatureErr = errs.Class("node signature error")
+
+// SignNode signs the id, address, type and version of n, and the time it's
+// signed at, with the key of identity and attaches the identity's
+// certificate chain, so that other nodes can verify that the record was
+// announced by the node itself, and which of its records is the newest.
+func SignNode(n *pb.Node, identity *provider.FullIdentity) error {
+	signedAt, err := ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return SignatureErr.Wrap(err)
+	}
+	n.SignedAt = signedAt
+
+	data, err := signedNodeData(n)
+	if err != nil {
+		return SignatureErr.Wrap(err)
+	}
+
+	signature, err := peertls.SignMessage(identity.Key, data)
+	if err != nil {
+		return SignatureErr.Wrap(err)
+	}
+
+	n.Signature = signature
+	n.IdentityChain = identityChain(identity)
+	return nil
+}
+
+// VerifyNode checks that n is signed by the identity its id is derived from.
+func VerifyNode(n *pb.Node) error {
+	if len(n.GetSignature()) == 0 {
+		return SignatureErr.New("node %s is not signed", n.GetId())
+	}
+	if n.GetSignedAt() == nil {
+		return SignatureErr.New("node %s has no signing time", n.GetId())
+	}
+	identity, err := nodeIdentity(n)
+	if err != nil {
+		return err
+	}
+
+	data, err := signedNodeData(n)
+	if err != nil {
+		return SignatureErr.Wrap(err)
+	}
+	if err := peertls.VerifyMessage(identity.Leaf.PublicKey, data, n.GetSignature()); err != nil {
+		return SignatureErr.New("invalid signature for node %s: %v", n.GetId(), err)
+	}
+	return nil
+}
+
+// signedNodeData returns the serialized part of n that is covered by its
+// signature. Restrictions change over time and are left out.
+func signedNodeData(n *pb.Node) ([]byte, error) {
+	return proto.Marshal(&pb.Node{
+		Id:       n.GetId(),
+		Address:  n.GetAddress(),
+		Type:     n.GetType(),
+		Version:  n.GetVersion(),
+		SignedAt: n.GetSignedAt(),
+	})
+}
+
+// NewerNode returns whether n was signed after other, counting records which
+// aren't signed as the oldest
+func NewerNode(n, other *pb.Node) bool {
+	signedAt, err := ptypes.Timestamp(n.GetSignedAt())
+	if err != nil {
+		return false
+	}
+	otherSignedAt, err := ptypes.Timestamp(other.GetSignedAt())
+	if err != nil {
+		return true
+	}
+	return signedAt.After(otherSignedAt)
+}
+
+// VerifyPieceHash checks that the receipt pieceHash for a piece is signed by
+// the identity the id of n is derived from.
+func VerifyPieceHash(n *pb.Node, pieceHash *pb.PieceHash) error {
+	identity, err := nodeIdentity(n)
+	if err != nil {
+		return err
+	}
+	if err := peertls.VerifyMessage(identity.Leaf.PublicKey, pieceHash.GetData(), pieceHash.GetSignature()); err != nil {
+		return SignatureErr.New("invalid piece hash signature for node %s: %v", n.GetId(), err)
+	}
+	return nil
+}
+
+// nodeIdentity returns the identity of n from its certificate chain, once
+// its id is checked to be derived from it
+func nodeIdentity(n *pb.Node) (*provider.PeerIdentity, error) {
+	if len(n.GetIdentityChain()) < 2 {
+		return nil, SignatureErr.New("node %s has an incomplete certificate chain", n.GetId())
+	}
+
+	chain, err := provider.ParseCertChain(n.GetIdentityChain())
+	if err != nil {
+		return nil, SignatureErr.Wrap(err)
+	}
+	if err := peertls.VerifyPeerCertChains(nil, [][]*x509.Certificate{chain}); err != nil {
+		return nil, SignatureErr.Wrap(err)
+	}
+
+	identity, err := provider.PeerIdentityFromCerts(chain[0], chain[1], chain[2:])
+	if err != nil {
+		return nil, SignatureErr.Wrap(err)
+	}
+	if identity.ID.String() != n.GetId() {
+		return nil, SignatureErr.New("node id %s doesn't match its identity %s", n.GetId(), identity.ID)
+	}
+	return identity, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/peertls"
+	"storj.io/storj/pkg/provider"
+)
+
+func TestSignNode(t *testing.T) {
+	identity := newTestIdentity(t)
+	other := newTestIdentity(t)
+
+	signed := func() *pb.Node {
+		n := &pb.Node{Id: identity.ID.String(), Address: &pb.NodeAddress{Address: "127.0.0.1:7777"}}
+		assert.NoError(t, SignNode(n, identity))
+		return n
+	}
+
+	assert.NoError(t, VerifyNode(signed()))
+
+	// restrictions aren't covered by the signature
+	n := signed()
+	n.Restrictions = &pb.NodeRestrictions{FreeDisk: 10}
+	assert.NoError(t, VerifyNode(n))
+
+	for i, tamper := range []func(n *pb.Node){
+		func(n *pb.Node) { n.Signature = nil },
+		func(n *pb.Node) { n.IdentityChain = n.IdentityChain[:1] },
+		func(n *pb.Node) { n.Address = &pb.NodeAddress{Address: "127.0.0.1:6666"} },
+		func(n *pb.Node) { n.Type = pb.NodeType_STORAGE },
+		func(n *pb.Node) { n.Version = "v0.0.0-forged" },
+		func(n *pb.Node) { n.SignedAt.Seconds++ },
+		func(n *pb.Node) { n.SignedAt = nil },
+		func(n *pb.Node) { n.Id = other.ID.String() },
+		func(n *pb.Node) { assert.NoError(t, SignNode(n, other)) },
+	} {
+		n := signed()
+		tamper(n)
+		assert.True(t, SignatureErr.Has(VerifyNode(n)), "case %d", i)
+	}
+}
+
+func TestNewerNode(t *testing.T) {
+	identity := newTestIdentity(t)
+
+	older := &pb.Node{Id: identity.ID.String()}
+	assert.NoError(t, SignNode(older, identity))
+	newer := &pb.Node{Id: identity.ID.String()}
+	assert.NoError(t, SignNode(newer, identity))
+	newer.SignedAt.Seconds++
+
+	assert.True(t, NewerNode(newer, older))
+	assert.False(t, NewerNode(older, newer))
+	assert.False(t, NewerNode(older, older))
+
+	// records which aren't signed are the oldest
+	unsigned := &pb.Node{Id: identity.ID.String()}
+	assert.True(t, NewerNode(older, unsigned))
+	assert.False(t, NewerNode(unsigned, older))
+}
+
+func TestVerifyPieceHash(t *testing.T) {
+	identity := newTestIdentity(t)
+	other := newTestIdentity(t)
+
+	n := &pb.Node{Id: identity.ID.String()}
+	assert.NoError(t, SignNode(n, identity))
+
+	receipt := func(signer *provider.FullIdentity) *pb.PieceHash {
+		data := []byte("piece hash data")
+		signature, err := peertls.SignMessage(signer.Key, data)
+		assert.NoError(t, err)
+		return &pb.PieceHash{Data: data, Signature: signature}
+	}
+
+	assert.NoError(t, VerifyPieceHash(n, receipt(identity)))
+	assert.True(t, SignatureErr.Has(VerifyPieceHash(n, receipt(other))))
+
+	forged := receipt(identity)
+	forged.Data = []byte("forged piece hash data")
+	assert.True(t, SignatureErr.Has(VerifyPieceHash(n, forged)))
+
+	n.IdentityChain = nil
+	assert.True(t, SignatureErr.Has(VerifyPieceHash(n, receipt(identity))))
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package node
+
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/peertls"
+	"storj.io/storj/pkg/provider"
+)
+
+// TransitionErr is the class for errors creating or verifying identity
+// transitions
+var TransitionErr = errs.Class("identity transition error")
+
+const (
+	// MaxTransitionAge is how long after they are signed identity
+	// transitions are accepted
+	MaxTransitionAge = 24 * time.Hour
+	// maxTransitionSkew is how far in the future transitions signed by
+	// nodes whose clocks are ahead are accepted
+	maxTransitionSkew = 5 * time.Minute
+)
+
+// NewIdentityTransition returns a transition binding newIdentity to the node
+// id of oldIdentity, signed by both of them. It lets a node re-mine its
+// identity with a higher difficulty and keep its history on satellites.
+func NewIdentityTransition(oldIdentity, newIdentity *provider.FullIdentity) (*pb.IdentityTransition, error) {
+	return newIdentityTransition(oldIdentity, newIdentity, time.Now())
+}
+
+// newIdentityTransition returns a transition signed at signedAt
+func newIdentityTransition(oldIdentity, newIdentity *provider.FullIdentity, signedAt time.Time) (*pb.IdentityTransition, error) {
+	if newIdentity.ID.Difficulty() <= oldIdentity.ID.Difficulty() {
+		return nil, TransitionErr.New("new node id %s isn't harder than %s", newIdentity.ID, oldIdentity.ID)
+	}
+
+	t := &pb.IdentityTransition{
+		OldChain:  identityChain(oldIdentity),
+		NewChain:  identityChain(newIdentity),
+		Timestamp: signedAt.Unix(),
+	}
+	data, err := signedTransitionData(t)
+	if err != nil {
+		return nil, TransitionErr.Wrap(err)
+	}
+
+	t.OldSignature, err = peertls.SignMessage(oldIdentity.Key, data)
+	if err != nil {
+		return nil, TransitionErr.Wrap(err)
+	}
+	t.NewSignature, err = peertls.SignMessage(newIdentity.Key, data)
+	if err != nil {
+		return nil, TransitionErr.Wrap(err)
+	}
+	return t, nil
+}
+
+// VerifyIdentityTransition checks that t is signed by both of its
+// identities within the last MaxTransitionAge and that the new one is harder
+// than the old one, and returns the identities.
+func VerifyIdentityTransition(t *pb.IdentityTransition) (oldIdentity, newIdentity *provider.PeerIdentity, err error) {
+	signedAt := time.Unix(t.GetTimestamp(), 0)
+	if now := time.Now(); signedAt.Before(now.Add(-MaxTransitionAge)) || signedAt.After(now.Add(maxTransitionSkew)) {
+		return nil, nil, TransitionErr.New("transition signed at %v has expired or is in the future", signedAt)
+	}
+
+	oldIdentity, err = transitionIdentity(t.GetOldChain())
+	if err != nil {
+		return nil, nil, err
+	}
+	newIdentity, err = transitionIdentity(t.GetNewChain())
+	if err != nil {
+		return nil, nil, err
+	}
+	if newIdentity.ID.Difficulty() <= oldIdentity.ID.Difficulty() {
+		return nil, nil, TransitionErr.New("new node id %s isn't harder than %s", newIdentity.ID, oldIdentity.ID)
+	}
+
+	data, err := signedTransitionData(t)
+	if err != nil {
+		return nil, nil, TransitionErr.Wrap(err)
+	}
+	if err := peertls.VerifyMessage(oldIdentity.Leaf.PublicKey, data, t.GetOldSignature()); err != nil {
+		return nil, nil, TransitionErr.New("invalid signature of old node %s: %v", oldIdentity.ID, err)
+	}
+	if err := peertls.VerifyMessage(newIdentity.Leaf.PublicKey, data, t.GetNewSignature()); err != nil {
+		return nil, nil, TransitionErr.New("invalid signature of new node %s: %v", newIdentity.ID, err)
+	}
+	return oldIdentity, newIdentity, nil
+}
+
+// transitionIdentity returns the verified identity of a certificate chain
+func transitionIdentity(rawChain [][]byte) (*provider.PeerIdentity, error) {
+	if len(rawChain) < 2 {
+		return nil, TransitionErr.New("incomplete certificate chain")
+	}
+	chain, err := provider.ParseCertChain(rawChain)
+	if err != nil {
+		return nil, TransitionErr.Wrap(err)
+	}
+	if err := peertls.VerifyPeerCertChains(nil, [][]*x509.Certificate{chain}); err != nil {
+		return nil, TransitionErr.Wrap(err)
+	}
+	identity, err := provider.PeerIdentityFromCerts(chain[0], chain[1], chain[2:])
+	if err != nil {
+		return nil, TransitionErr.Wrap(err)
+	}
+	return identity, nil
+}
+
+// identityChain returns the raw certificate chain of identity, leaf first
+func identityChain(identity *provider.FullIdentity) [][]byte {
+	chain := [][]byte{identity.Leaf.Raw, identity.CA.Raw}
+	return append(chain, identity.RestChainRaw()...)
+}
+
+// signedTransitionData returns the serialized part of t that is covered by
+// its signatures
+func signedTransitionData(t *pb.IdentityTransition) ([]byte, error) {
+	return proto.Marshal(&pb.IdentityTransition{
+		OldChain:  t.GetOldChain(),
+		NewChain:  t.GetNewChain(),
+		Timestamp: t.GetTimestamp(),
+	})
+}
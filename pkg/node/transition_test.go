@@ -0,0 +1,79 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/provider"
+)
+
+func TestIdentityTransition(t *testing.T) {
+	oldIdentity, newIdentity := newTransitionIdentities(t)
+
+	_, err := NewIdentityTransition(newIdentity, oldIdentity)
+	assert.True(t, TransitionErr.Has(err), "the new identity has to be harder")
+
+	transition, err := NewIdentityTransition(oldIdentity, newIdentity)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	verifiedOld, verifiedNew, err := VerifyIdentityTransition(transition)
+	if assert.NoError(t, err) {
+		assert.Equal(t, oldIdentity.ID, verifiedOld.ID)
+		assert.Equal(t, newIdentity.ID, verifiedNew.ID)
+	}
+
+	for i, tamper := range []func(tr *pb.IdentityTransition){
+		func(tr *pb.IdentityTransition) { tr.OldSignature = nil },
+		func(tr *pb.IdentityTransition) { tr.NewSignature = tr.OldSignature },
+		func(tr *pb.IdentityTransition) { tr.Timestamp++ },
+		func(tr *pb.IdentityTransition) { tr.OldChain = tr.OldChain[:1] },
+		func(tr *pb.IdentityTransition) { tr.OldChain, tr.NewChain = tr.NewChain, tr.OldChain },
+	} {
+		transition, err := NewIdentityTransition(oldIdentity, newIdentity)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		tamper(transition)
+		_, _, err = VerifyIdentityTransition(transition)
+		assert.True(t, TransitionErr.Has(err), "case %d", i)
+	}
+
+	// transitions are only accepted for a while after they are signed
+	for _, signedAt := range []time.Time{
+		time.Now().Add(-MaxTransitionAge - time.Minute),
+		time.Now().Add(time.Hour),
+	} {
+		transition, err := newIdentityTransition(oldIdentity, newIdentity, signedAt)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		_, _, err = VerifyIdentityTransition(transition)
+		assert.True(t, TransitionErr.Has(err), "signed at %v", signedAt)
+	}
+}
+
+// newTransitionIdentities returns two identities, the second one with a
+// harder node id than the first one
+func newTransitionIdentities(t *testing.T) (*provider.FullIdentity, *provider.FullIdentity) {
+	newIdentity := func(difficulty uint16) *provider.FullIdentity {
+		ca, err := provider.NewCA(ctx, provider.NewCAOptions{Difficulty: difficulty})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		identity, err := ca.NewIdentity()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return identity
+	}
+
+	oldIdentity := newIdentity(0)
+	return oldIdentity, newIdentity(oldIdentity.ID.Difficulty() + 1)
+}
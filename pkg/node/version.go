@@ -0,0 +1,8 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package node
+
+// Version is the software version nodes report about themselves. Release
+// builds set it at link time with -ldflags "-X storj.io/storj/pkg/node.Version=..."
+var Version = "dev"
@@ -12,6 +12,7 @@ import (
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
 
+	"storj.io/storj/pkg/ban"
 	"storj.io/storj/pkg/dht"
 	"storj.io/storj/pkg/node"
 	"storj.io/storj/pkg/pb"
@@ -36,6 +37,8 @@ var OverlayError = errs.Class("Overlay Error")
 type Cache struct {
 	DB  storage.KeyValueStore
 	DHT dht.DHT
+	// Bans are the nodes which aren't cached, nil for none
+	Bans *ban.List
 }
 
 // NewRedisOverlayCache returns a pointer to a new Cache instance with an initialized connection to Redis.
@@ -47,6 +50,17 @@ func NewRedisOverlayCache(address, password string, dbindex int, dht dht.DHT) (*
 	return NewOverlayCache(storelogger.New(zap.L(), db), dht), nil
 }
 
+// NewRedisOverlayCacheFrom returns a pointer to a new Cache instance stored in
+// the redis described by the redis:// url address, which may be a standalone
+// redis, a cluster or a master monitored by sentinels
+func NewRedisOverlayCacheFrom(address string, dht dht.DHT) (*Cache, error) {
+	db, err := redis.NewClientFrom(address)
+	if err != nil {
+		return nil, err
+	}
+	return NewOverlayCache(storelogger.New(zap.L(), db), dht), nil
+}
+
 // NewBoltOverlayCache returns a pointer to a new Cache instance with an initialized connection to a Bolt db.
 func NewBoltOverlayCache(dbPath string, dht dht.DHT) (*Cache, error) {
 	db, err := boltdb.New(dbPath, OverlayBucket)
@@ -115,6 +129,10 @@ func (o *Cache) GetAll(ctx context.Context, keys []string) ([]*pb.Node, error) {
 
 // Put adds a nodeID to the redis cache with a binary representation of proto defined Node
 func (o *Cache) Put(nodeID string, value pb.Node) error {
+	if o.Bans.Banned(nodeID, ban.Host(value.GetAddress().GetAddress())) {
+		return OverlayError.New("node %s is banned", nodeID)
+	}
+
 	data, err := proto.Marshal(&value)
 	if err != nil {
 		return err
@@ -123,6 +141,32 @@ func (o *Cache) Put(nodeID string, value pb.Node) error {
 	return o.DB.Put(node.IDFromString(nodeID).Bytes(), data)
 }
 
+// Update changes the cached node with the given id to the one fn returns for
+// it, which is called with nil if the node isn't cached. Nodes changed
+// concurrently are passed to fn again, so no update is lost.
+func (o *Cache) Update(ctx context.Context, nodeID string, fn func(n *pb.Node) (*pb.Node, error)) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return storage.Update(o.DB, node.IDFromString(nodeID).Bytes(), func(old storage.Value) (storage.Value, error) {
+		var n *pb.Node
+		if !old.IsZero() {
+			n = &pb.Node{}
+			if err := proto.Unmarshal(old, n); err != nil {
+				return nil, OverlayError.New("could not unmarshal node: %v", err)
+			}
+		}
+
+		updated, err := fn(n)
+		if err != nil {
+			return nil, err
+		}
+		if o.Bans.Banned(nodeID, ban.Host(updated.GetAddress().GetAddress())) {
+			return nil, OverlayError.New("node %s is banned", nodeID)
+		}
+		return proto.Marshal(updated)
+	})
+}
+
 // Bootstrap walks the initialized network and populates the cache
 func (o *Cache) Bootstrap(ctx context.Context) error {
 	nodes, err := o.DHT.GetNodes(ctx, "", 1280)
@@ -130,6 +174,7 @@ func (o *Cache) Bootstrap(ctx context.Context) error {
 		zap.Error(OverlayError.New("Error getting nodes from DHT: %v", err))
 	}
 
+	items := make(storage.Items, 0, len(nodes))
 	for _, v := range nodes {
 		found, err := o.DHT.FindNode(ctx, node.IDFromString(v.Id))
 		if err != nil {
@@ -141,9 +186,13 @@ func (o *Cache) Bootstrap(ctx context.Context) error {
 			return err
 		}
 
-		if err := o.DB.Put(node.IDFromString(found.Id).Bytes(), n); err != nil {
-			return err
-		}
+		items = append(items, storage.ListItem{
+			Key:   node.IDFromString(found.Id).Bytes(),
+			Value: n,
+		})
+	}
+	if err := o.DB.PutAll(items); err != nil {
+		return err
 	}
 
 	return err
@@ -165,15 +214,19 @@ func (o *Cache) Refresh(ctx context.Context) error {
 		return err
 	}
 
+	items := make(storage.Items, 0, len(near))
 	for _, node := range near {
 		pinged, err := o.DHT.Ping(ctx, *node)
 		if err != nil {
 			return err
 		}
-		err = o.DB.Put([]byte(pinged.Id), []byte(pinged.Address.Address))
-		if err != nil {
-			return err
-		}
+		items = append(items, storage.ListItem{
+			Key:   []byte(pinged.Id),
+			Value: []byte(pinged.Address.Address),
+		})
+	}
+	if err := o.DB.PutAll(items); err != nil {
+		return err
 	}
 
 	// TODO: Kademlia hooks to do this automatically rather than at interval
@@ -182,20 +235,20 @@ func (o *Cache) Refresh(ctx context.Context) error {
 		return err
 	}
 
+	items = make(storage.Items, 0, len(nodes))
 	for _, node := range nodes {
 		pinged, err := o.DHT.Ping(ctx, *node)
 		if err != nil {
 			zap.Error(ErrNodeNotFound)
 			return err
 		}
-		err = o.DB.Put([]byte(pinged.Id), []byte(pinged.Address.Address))
-		if err != nil {
-			return err
-		}
-
+		items = append(items, storage.ListItem{
+			Key:   []byte(pinged.Id),
+			Value: []byte(pinged.Address.Address),
+		})
 	}
 
-	return err
+	return o.DB.PutAll(items)
 }
 
 // Walk iterates over each node in each bucket to traverse the network
@@ -7,6 +7,7 @@ import (
 	"context"
 
 	"github.com/zeebo/errs"
+	"google.golang.org/grpc"
 
 	"storj.io/storj/pkg/dht"
 	"storj.io/storj/pkg/pb"
@@ -34,6 +35,7 @@ type Client interface {
 // Overlay is the overlay concrete implementation of the client interface
 type Overlay struct {
 	client pb.OverlayClient
+	conn   *grpc.ClientConn
 }
 
 // Options contains parameters for selecting nodes
@@ -49,16 +51,25 @@ func NewOverlayClient(identity *provider.FullIdentity, address string) (*Overlay
 	if err != nil {
 		return nil, err
 	}
-	c, err := NewClient(address, dialOpt)
+	conn, err := grpc.Dial(address, dialOpt, grpc.WithUnaryInterceptor(provider.TraceUnaryClient))
 	if err != nil {
 		return nil, err
 	}
 
 	return &Overlay{
-		client: c,
+		client: pb.NewOverlayClient(conn),
+		conn:   conn,
 	}, nil
 }
 
+// Close closes the connection of the client
+func (o *Overlay) Close() error {
+	if o.conn == nil {
+		return nil
+	}
+	return o.conn.Close()
+}
+
 // a compiler trick to make sure *Overlay implements Client
 var _ Client = (*Overlay)(nil)
 
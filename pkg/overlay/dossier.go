@@ -0,0 +1,199 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package overlay
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/pb"
+	sdbproto "storj.io/storj/pkg/statdb/proto"
+	"storj.io/storj/pkg/statdb/sdbclient"
+)
+
+// NodeDossier is everything known about a node: how to reach it and what it
+// reports about itself, as cached from kademlia, how it has been behaving,
+// as recorded in statdb, and whether it could be contacted lately
+type NodeDossier struct {
+	Node *pb.Node
+	// Reputation is nil when the node has no stats yet or statdb isn't used
+	Reputation *sdbproto.NodeStats
+	Liveness   Liveness
+}
+
+// Liveness is what the satellite saw of the connections to a node since it
+// started. Nodes which weren't contacted yet have a zero Liveness.
+type Liveness struct {
+	// LastSuccess is when the node was last contacted
+	LastSuccess time.Time
+	// OfflineSince is when contacting the node started failing, which is
+	// zero if the last contact succeeded
+	OfflineSince time.Time
+}
+
+// OfflineFor returns for how long contacting the node has been failing at
+// now, which is 0 unless the last contact failed
+func (l Liveness) OfflineFor(now time.Time) time.Duration {
+	if l.OfflineSince.IsZero() {
+		return 0
+	}
+	return now.Sub(l.OfflineSince)
+}
+
+// Capacity returns the free disk and bandwidth the node reported
+func (d *NodeDossier) Capacity() *pb.NodeRestrictions {
+	return d.Node.GetRestrictions()
+}
+
+// Version returns the software version the node reported
+func (d *NodeDossier) Version() string {
+	return d.Node.GetVersion()
+}
+
+// MeetsReputation returns whether the node's reputation satisfies min. Nodes
+// without a reputation only satisfy an empty minimum.
+func (d *NodeDossier) MeetsReputation(min *pb.NodeRep) bool {
+	if min.GetMinUptime() == 0 && min.GetMinAuditSuccess() == 0 && min.GetMinAuditCount() == 0 {
+		return true
+	}
+	if d.Reputation == nil {
+		return false
+	}
+	return d.Reputation.GetUptimeRatio() >= float64(min.GetMinUptime()) &&
+		d.Reputation.GetAuditSuccessRatio() >= float64(min.GetMinAuditSuccess()) &&
+		d.Reputation.GetAuditCount() >= min.GetMinAuditCount()
+}
+
+// DossierService composes node dossiers from the overlay cache and statdb,
+// and the liveness of the nodes it observed connections to
+type DossierService struct {
+	cache  *Cache
+	statdb sdbclient.Client
+
+	mu       sync.Mutex
+	liveness map[string]Liveness
+}
+
+// NewDossierService returns a DossierService. statdb may be nil, in which case
+// dossiers have no reputation.
+func NewDossierService(cache *Cache, statdb sdbclient.Client) *DossierService {
+	return &DossierService{cache: cache, statdb: statdb, liveness: map[string]Liveness{}}
+}
+
+// Get returns the dossier of the node with the given id
+func (s *DossierService) Get(ctx context.Context, nodeID string) (d *NodeDossier, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	n, err := s.cache.Get(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return nil, ErrNodeNotFound
+	}
+	return s.Compose(ctx, n), nil
+}
+
+// GetAll returns the dossiers of the nodes with the given ids, in the same
+// order. Nodes that aren't in the cache have a nil dossier.
+func (s *DossierService) GetAll(ctx context.Context, nodeIDs []string) (ds []*NodeDossier, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	nodes, err := s.cache.GetAll(ctx, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nodes {
+		if n == nil {
+			ds = append(ds, nil)
+			continue
+		}
+		ds = append(ds, s.Compose(ctx, n))
+	}
+	return ds, nil
+}
+
+// Compose returns the dossier of n, looking up its reputation in statdb
+func (s *DossierService) Compose(ctx context.Context, n *pb.Node) *NodeDossier {
+	s.mu.Lock()
+	d := &NodeDossier{Node: n, Liveness: s.liveness[n.GetId()]}
+	s.mu.Unlock()
+	if s.statdb == nil {
+		return d
+	}
+
+	stats, err := s.statdb.Get(ctx, []byte(n.GetId()))
+	if err != nil {
+		// statdb doesn't tell missing nodes apart from other errors
+		zap.L().Debug("no reputation for node", zap.String("NodeID", n.GetId()), zap.Error(err))
+		return d
+	}
+	d.Reputation = stats
+	return d
+}
+
+// ConnSuccess implements transport.Observer by recording in statdb that the
+// node is up, and caching the node that could be connected to if it's a
+// signed record newer than the cached one. Records that can't be verified
+// leave the cached node unchanged.
+func (s *DossierService) ConnSuccess(ctx context.Context, n *pb.Node) {
+	if err := node.VerifyNode(n); err == nil {
+		err := s.cache.Update(ctx, n.GetId(), func(cached *pb.Node) (*pb.Node, error) {
+			if cached == nil {
+				return n, nil
+			}
+			if !node.NewerNode(n, cached) {
+				return cached, nil
+			}
+			updated := *n
+			// restrictions aren't signed and the dialer may not know them
+			if updated.Restrictions == nil {
+				updated.Restrictions = cached.Restrictions
+			}
+			return &updated, nil
+		})
+		if err != nil {
+			zap.L().Debug("could not cache contacted node", zap.String("NodeID", n.GetId()), zap.Error(err))
+		}
+	}
+	s.updateLiveness(n, true)
+	s.updateUptime(ctx, n, true)
+}
+
+// ConnFailure implements transport.Observer by recording in statdb that the
+// node is down
+func (s *DossierService) ConnFailure(ctx context.Context, n *pb.Node, err error) {
+	s.updateLiveness(n, false)
+	s.updateUptime(ctx, n, false)
+}
+
+// updateLiveness records whether contacting n succeeded now
+func (s *DossierService) updateLiveness(n *pb.Node, isUp bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	liveness := s.liveness[n.GetId()]
+	if isUp {
+		liveness.LastSuccess = now
+		liveness.OfflineSince = time.Time{}
+	} else if liveness.OfflineSince.IsZero() {
+		liveness.OfflineSince = now
+	}
+	s.liveness[n.GetId()] = liveness
+}
+
+func (s *DossierService) updateUptime(ctx context.Context, n *pb.Node, isUp bool) {
+	if s.statdb == nil {
+		return
+	}
+	_, err := s.statdb.Update(ctx, []byte(n.GetId()), false, isUp, nil, false, true, false)
+	if err != nil {
+		zap.L().Debug("could not update node uptime", zap.String("NodeID", n.GetId()), zap.Error(err))
+	}
+}
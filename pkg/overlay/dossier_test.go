@@ -0,0 +1,179 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package overlay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/provider"
+	sdbproto "storj.io/storj/pkg/statdb/proto"
+	"storj.io/storj/pkg/statdb/sdbclient"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+)
+
+type mockStatDB struct {
+	sdbclient.Client
+	stats map[string]*sdbproto.NodeStats
+}
+
+func (m *mockStatDB) Get(ctx context.Context, nodeID []byte) (*sdbproto.NodeStats, error) {
+	stats, ok := m.stats[string(nodeID)]
+	if !ok {
+		return nil, errs.New("node not found")
+	}
+	return stats, nil
+}
+
+func TestMeetsReputation(t *testing.T) {
+	good := &sdbproto.NodeStats{UptimeRatio: 0.99, AuditSuccessRatio: 0.95, AuditCount: 100}
+	for i, tt := range []struct {
+		reputation *sdbproto.NodeStats
+		min        *pb.NodeRep
+		meets      bool
+	}{
+		{nil, nil, true},
+		{nil, &pb.NodeRep{}, true},
+		{nil, &pb.NodeRep{MinUptime: 0.5}, false},
+		{good, &pb.NodeRep{MinUptime: 0.9, MinAuditSuccess: 0.9, MinAuditCount: 100}, true},
+		{good, &pb.NodeRep{MinUptime: 0.999}, false},
+		{good, &pb.NodeRep{MinAuditSuccess: 0.99}, false},
+		{good, &pb.NodeRep{MinAuditCount: 101}, false},
+	} {
+		d := &NodeDossier{Node: &pb.Node{}, Reputation: tt.reputation}
+		assert.Equal(t, tt.meets, d.MeetsReputation(tt.min), "case %d", i)
+	}
+}
+
+func TestDossierService(t *testing.T) {
+	n1 := &pb.Node{
+		Id:           "node1",
+		Address:      &pb.NodeAddress{Address: "127.0.0.1:9090"},
+		Restrictions: &pb.NodeRestrictions{FreeDisk: 10, FreeBandwidth: 20},
+		Version:      "v0.1.0",
+	}
+	n2 := &pb.Node{Id: "node2", Address: &pb.NodeAddress{Address: "127.0.0.1:9091"}}
+
+	db := teststore.New()
+	for _, n := range []*pb.Node{n1, n2} {
+		v, err := proto.Marshal(n)
+		assert.NoError(t, err)
+		assert.NoError(t, db.Put(storage.Key(n.Id), v))
+	}
+
+	stats := &sdbproto.NodeStats{NodeId: []byte("node1"), UptimeRatio: 1}
+	sdb := &mockStatDB{stats: map[string]*sdbproto.NodeStats{"node1": stats}}
+	s := NewDossierService(&Cache{DB: db}, sdb)
+
+	d, err := s.Get(ctx, "node1")
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(n1, d.Node))
+	assert.Equal(t, int64(10), d.Capacity().GetFreeDisk())
+	assert.Equal(t, "v0.1.0", d.Version())
+	assert.Equal(t, stats, d.Reputation)
+
+	ds, err := s.GetAll(ctx, []string{"node2", "missing", "node1"})
+	assert.NoError(t, err)
+	assert.Len(t, ds, 3)
+	assert.Nil(t, ds[0].Reputation)
+	assert.Nil(t, ds[1])
+	assert.Equal(t, stats, ds[2].Reputation)
+
+	// without statdb dossiers have no reputation
+	d, err = NewDossierService(&Cache{DB: db}, nil).Get(ctx, "node1")
+	assert.NoError(t, err)
+	assert.Nil(t, d.Reputation)
+}
+
+func TestDossierLiveness(t *testing.T) {
+	cache := &Cache{DB: teststore.New()}
+	s := NewDossierService(cache, nil)
+	n := &pb.Node{Id: "node1", Address: &pb.NodeAddress{Address: "127.0.0.1:9090"}}
+
+	// nodes which weren't contacted aren't offline
+	d := s.Compose(ctx, n)
+	assert.True(t, d.Liveness.LastSuccess.IsZero())
+	assert.Equal(t, time.Duration(0), d.Liveness.OfflineFor(time.Now()))
+
+	s.ConnSuccess(ctx, n)
+	success := s.Compose(ctx, n).Liveness.LastSuccess
+	assert.False(t, success.IsZero())
+
+	// nodes are offline since the first of the failures to contact them
+	s.ConnFailure(ctx, n, errs.New("dial failed"))
+	since := s.Compose(ctx, n).Liveness.OfflineSince
+	s.ConnFailure(ctx, n, errs.New("dial failed"))
+	d = s.Compose(ctx, n)
+	assert.Equal(t, since, d.Liveness.OfflineSince)
+	assert.Equal(t, success, d.Liveness.LastSuccess)
+	assert.Equal(t, time.Hour, d.Liveness.OfflineFor(since.Add(time.Hour)))
+
+	// until they're contacted again
+	s.ConnSuccess(ctx, n)
+	assert.Equal(t, time.Duration(0), s.Compose(ctx, n).Liveness.OfflineFor(time.Now()))
+}
+
+func TestDossierConnSuccess(t *testing.T) {
+	cache := &Cache{DB: teststore.New()}
+	s := NewDossierService(cache, nil)
+
+	ca, err := provider.NewTestCA(ctx)
+	assert.NoError(t, err)
+	identity, err := ca.NewIdentity()
+	assert.NoError(t, err)
+	id := identity.ID.String()
+
+	signed := &pb.Node{
+		Id:           id,
+		Address:      &pb.NodeAddress{Address: "127.0.0.1:9090"},
+		Restrictions: &pb.NodeRestrictions{FreeDisk: 10},
+		Version:      "v0.1.0",
+	}
+	assert.NoError(t, node.SignNode(signed, identity))
+	s.ConnSuccess(ctx, signed)
+	n, err := cache.Get(ctx, id)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(signed, n))
+
+	// records which aren't signed don't change the cached node
+	s.ConnSuccess(ctx, &pb.Node{Id: id, Address: &pb.NodeAddress{Address: "127.0.0.1:9091"}})
+	n, err = cache.Get(ctx, id)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(signed, n))
+
+	// neither do forged ones
+	forged := proto.Clone(signed).(*pb.Node)
+	forged.Address = &pb.NodeAddress{Address: "127.0.0.1:9091"}
+	s.ConnSuccess(ctx, forged)
+	n, err = cache.Get(ctx, id)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(signed, n))
+
+	// but newer signed records do, keeping the restrictions
+	moved := &pb.Node{Id: id, Address: &pb.NodeAddress{Address: "127.0.0.1:9091"}, Version: "v0.2.0"}
+	assert.NoError(t, node.SignNode(moved, identity))
+	s.ConnSuccess(ctx, moved)
+	n, err = cache.Get(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:9091", n.GetAddress().GetAddress())
+	assert.Equal(t, "v0.2.0", n.GetVersion())
+	assert.Equal(t, int64(10), n.GetRestrictions().GetFreeDisk())
+
+	// and older ones don't
+	s.ConnSuccess(ctx, signed)
+	n, err = cache.Get(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:9091", n.GetAddress().GetAddress())
+
+	// contacted nodes are up either way
+	assert.False(t, s.Compose(ctx, &pb.Node{Id: id}).Liveness.LastSuccess.IsZero())
+}
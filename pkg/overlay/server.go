@@ -24,10 +24,11 @@ var ServerError = errs.Class("Server Error")
 
 // Server implements our overlay RPC service
 type Server struct {
-	dht     dht.DHT
-	cache   *Cache
-	logger  *zap.Logger
-	metrics *monkit.Registry
+	dht      dht.DHT
+	cache    *Cache
+	dossiers *DossierService
+	logger   *zap.Logger
+	metrics  *monkit.Registry
 }
 
 // Lookup finds the address of a node in our overlay network
@@ -62,12 +63,13 @@ func (o *Server) FindStorageNodes(ctx context.Context, req *pb.FindStorageNodesR
 	restrictions := opts.GetRestrictions()
 	restrictedBandwidth := restrictions.GetFreeBandwidth()
 	restrictedSpace := restrictions.GetFreeDisk()
+	minReputation := opts.GetMinReputation()
 
 	var start storage.Key
 	result := []*pb.Node{}
 	for {
 		var nodes []*pb.Node
-		nodes, start, err = o.populate(ctx, start, maxNodes, restrictedBandwidth, restrictedSpace, excluded)
+		nodes, start, err = o.populate(ctx, start, maxNodes, restrictedBandwidth, restrictedSpace, minReputation, excluded)
 		if err != nil {
 			return nil, Error.Wrap(err)
 		}
@@ -97,52 +99,50 @@ func (o *Server) FindStorageNodes(ctx context.Context, req *pb.FindStorageNodesR
 	}, nil
 }
 
-func (o *Server) getNodes(ctx context.Context, keys storage.Keys) ([]*pb.Node, error) {
-	values, err := o.cache.DB.GetAll(keys)
-	if err != nil {
-		return nil, Error.Wrap(err)
-	}
-
-	nodes := []*pb.Node{}
-	for _, v := range values {
-		n := &pb.Node{}
-		if err := proto.Unmarshal(v, n); err != nil {
-			return nil, Error.Wrap(err)
-		}
-
-		nodes = append(nodes, n)
-	}
-
-	return nodes, nil
-
-}
-
-func (o *Server) populate(ctx context.Context, starting storage.Key, maxNodes, restrictedBandwidth, restrictedSpace int64, excluded []string) ([]*pb.Node, storage.Key, error) {
+func (o *Server) populate(ctx context.Context, starting storage.Key, maxNodes, restrictedBandwidth, restrictedSpace int64, minReputation *pb.NodeRep, excluded []string) ([]*pb.Node, storage.Key, error) {
 	limit := int(maxNodes * 2)
-	keys, err := o.cache.DB.List(starting, limit)
+	var keys storage.Keys
+	var nodes []*pb.Node
+	err := o.cache.DB.Iterate(storage.IterateOptions{First: starting, Recurse: true, Limit: limit}, func(it storage.Iterator) error {
+		var item storage.ListItem
+		for it.Next(&item) {
+			n := &pb.Node{}
+			if err := proto.Unmarshal(item.Value, n); err != nil {
+				return err
+			}
+			keys = append(keys, storage.CloneKey(item.Key))
+			nodes = append(nodes, n)
+		}
+		return nil
+	})
 	if err != nil {
 		o.logger.Error("Error listing nodes", zap.Error(err))
 		return nil, nil, Error.Wrap(err)
 	}
 
 	if len(keys) <= 0 {
-		o.logger.Info("No Keys returned from List operation")
+		o.logger.Info("No nodes returned from the iteration")
 		return []*pb.Node{}, starting, nil
 	}
 
+	rt := o.routingTable(ctx)
+
 	result := []*pb.Node{}
-	nodes, err := o.getNodes(ctx, keys)
-	if err != nil {
-		o.logger.Error("Error getting nodes", zap.Error(err))
-		return nil, nil, Error.Wrap(err)
-	}
 
 	for _, v := range nodes {
 		rest := v.GetRestrictions()
 
 		if rest.GetFreeBandwidth() < restrictedBandwidth ||
 			rest.GetFreeDisk() < restrictedSpace ||
-			contains(excluded, v.Id) {
+			contains(excluded, v.Id) ||
+			o.cache.Bans.BannedNode(v) {
+			continue
+		}
+		// nodes that announced downtime can't take new data for now
+		if rt != nil && rt.IsAway(v.Id) {
+			continue
+		}
+		if minReputation != nil && !o.dossierService().Compose(ctx, v).MeetsReputation(minReputation) {
 			continue
 		}
 		result = append(result, v)
@@ -156,6 +156,27 @@ func (o *Server) populate(ctx context.Context, starting storage.Key, maxNodes, r
 	return result, nextStart, nil
 }
 
+// routingTable returns the routing table of the dht, or nil if it isn't available
+func (o *Server) routingTable(ctx context.Context) dht.RoutingTable {
+	if o.dht == nil {
+		return nil
+	}
+	rt, err := o.dht.GetRoutingTable(ctx)
+	if err != nil {
+		o.logger.Warn("could not get routing table", zap.Error(err))
+		return nil
+	}
+	return rt
+}
+
+// dossierService returns the service node dossiers are composed with
+func (o *Server) dossierService() *DossierService {
+	if o.dossiers == nil {
+		return NewDossierService(o.cache, nil)
+	}
+	return o.dossiers
+}
+
 // contains checks if item exists in list
 func contains(list []string, item string) bool {
 	for _, listItem := range list {
@@ -6,6 +6,12 @@ package pb
 import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
+import timestamp "github.com/golang/protobuf/ptypes/timestamp"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -20,11 +26,12 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 // InjuredSegment is the queue item used for the data repair queue
 type InjuredSegment struct {
-	Path                 string   `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	LostPieces           []int32  `protobuf:"varint,2,rep,packed,name=lost_pieces,json=lostPieces,proto3" json:"lost_pieces,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Path                 string               `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	LostPieces           []int32              `protobuf:"varint,2,rep,packed,name=lost_pieces,json=lostPieces,proto3" json:"lost_pieces,omitempty"`
+	Queued               *timestamp.Timestamp `protobuf:"bytes,3,opt,name=queued,proto3" json:"queued,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
 func (m *InjuredSegment) Reset()         { *m = InjuredSegment{} }
@@ -65,20 +72,294 @@ func (m *InjuredSegment) GetLostPieces() []int32 {
 	return nil
 }
 
+func (m *InjuredSegment) GetQueued() *timestamp.Timestamp {
+	if m != nil {
+		return m.Queued
+	}
+	return nil
+}
+
+// IrreparableSegment is a segment which is on fewer online nodes than are
+// needed to reconstruct it, so it can't be repaired anymore
+type IrreparableSegment struct {
+	Path                 string               `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Reason               string               `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	AvailablePieces      int32                `protobuf:"varint,3,opt,name=available_pieces,json=availablePieces,proto3" json:"available_pieces,omitempty"`
+	MinReq               int32                `protobuf:"varint,4,opt,name=min_req,json=minReq,proto3" json:"min_req,omitempty"`
+	LastDetected         *timestamp.Timestamp `protobuf:"bytes,5,opt,name=last_detected,json=lastDetected,proto3" json:"last_detected,omitempty"`
+	RepairAttempts       int32                `protobuf:"varint,6,opt,name=repair_attempts,json=repairAttempts,proto3" json:"repair_attempts,omitempty"`
+	Escalated            bool                 `protobuf:"varint,7,opt,name=escalated,proto3" json:"escalated,omitempty"`
+	LossAccepted         bool                 `protobuf:"varint,8,opt,name=loss_accepted,json=lossAccepted,proto3" json:"loss_accepted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *IrreparableSegment) Reset()         { *m = IrreparableSegment{} }
+func (m *IrreparableSegment) String() string { return proto.CompactTextString(m) }
+func (*IrreparableSegment) ProtoMessage()    {}
+func (*IrreparableSegment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_datarepair_13e4beab54f194bd, []int{1}
+}
+func (m *IrreparableSegment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IrreparableSegment.Unmarshal(m, b)
+}
+func (m *IrreparableSegment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IrreparableSegment.Marshal(b, m, deterministic)
+}
+func (dst *IrreparableSegment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IrreparableSegment.Merge(dst, src)
+}
+func (m *IrreparableSegment) XXX_Size() int {
+	return xxx_messageInfo_IrreparableSegment.Size(m)
+}
+func (m *IrreparableSegment) XXX_DiscardUnknown() {
+	xxx_messageInfo_IrreparableSegment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IrreparableSegment proto.InternalMessageInfo
+
+func (m *IrreparableSegment) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *IrreparableSegment) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *IrreparableSegment) GetAvailablePieces() int32 {
+	if m != nil {
+		return m.AvailablePieces
+	}
+	return 0
+}
+
+func (m *IrreparableSegment) GetMinReq() int32 {
+	if m != nil {
+		return m.MinReq
+	}
+	return 0
+}
+
+func (m *IrreparableSegment) GetLastDetected() *timestamp.Timestamp {
+	if m != nil {
+		return m.LastDetected
+	}
+	return nil
+}
+
+func (m *IrreparableSegment) GetRepairAttempts() int32 {
+	if m != nil {
+		return m.RepairAttempts
+	}
+	return 0
+}
+
+func (m *IrreparableSegment) GetEscalated() bool {
+	if m != nil {
+		return m.Escalated
+	}
+	return false
+}
+
+func (m *IrreparableSegment) GetLossAccepted() bool {
+	if m != nil {
+		return m.LossAccepted
+	}
+	return false
+}
+
+type RepairSegmentRequest struct {
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// lost_pieces are rebuilt in addition to the pieces on nodes which
+	// aren't in the overlay
+	LostPieces           []int32  `protobuf:"varint,2,rep,packed,name=lost_pieces,json=lostPieces,proto3" json:"lost_pieces,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RepairSegmentRequest) Reset()         { *m = RepairSegmentRequest{} }
+func (m *RepairSegmentRequest) String() string { return proto.CompactTextString(m) }
+func (*RepairSegmentRequest) ProtoMessage()    {}
+func (*RepairSegmentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_datarepair_13e4beab54f194bd, []int{2}
+}
+func (m *RepairSegmentRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RepairSegmentRequest.Unmarshal(m, b)
+}
+func (m *RepairSegmentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RepairSegmentRequest.Marshal(b, m, deterministic)
+}
+func (dst *RepairSegmentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RepairSegmentRequest.Merge(dst, src)
+}
+func (m *RepairSegmentRequest) XXX_Size() int {
+	return xxx_messageInfo_RepairSegmentRequest.Size(m)
+}
+func (m *RepairSegmentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RepairSegmentRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RepairSegmentRequest proto.InternalMessageInfo
+
+func (m *RepairSegmentRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *RepairSegmentRequest) GetLostPieces() []int32 {
+	if m != nil {
+		return m.LostPieces
+	}
+	return nil
+}
+
+type RepairSegmentResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RepairSegmentResponse) Reset()         { *m = RepairSegmentResponse{} }
+func (m *RepairSegmentResponse) String() string { return proto.CompactTextString(m) }
+func (*RepairSegmentResponse) ProtoMessage()    {}
+func (*RepairSegmentResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_datarepair_13e4beab54f194bd, []int{3}
+}
+func (m *RepairSegmentResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RepairSegmentResponse.Unmarshal(m, b)
+}
+func (m *RepairSegmentResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RepairSegmentResponse.Marshal(b, m, deterministic)
+}
+func (dst *RepairSegmentResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RepairSegmentResponse.Merge(dst, src)
+}
+func (m *RepairSegmentResponse) XXX_Size() int {
+	return xxx_messageInfo_RepairSegmentResponse.Size(m)
+}
+func (m *RepairSegmentResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RepairSegmentResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RepairSegmentResponse proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterType((*InjuredSegment)(nil), "repair.InjuredSegment")
+	proto.RegisterType((*IrreparableSegment)(nil), "repair.IrreparableSegment")
+	proto.RegisterType((*RepairSegmentRequest)(nil), "repair.RepairSegmentRequest")
+	proto.RegisterType((*RepairSegmentResponse)(nil), "repair.RepairSegmentResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// DataRepairClient is the client API for DataRepair service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DataRepairClient interface {
+	// RepairSegment repairs a segment right away
+	RepairSegment(ctx context.Context, in *RepairSegmentRequest, opts ...grpc.CallOption) (*RepairSegmentResponse, error)
+}
+
+type dataRepairClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDataRepairClient(cc *grpc.ClientConn) DataRepairClient {
+	return &dataRepairClient{cc}
+}
+
+func (c *dataRepairClient) RepairSegment(ctx context.Context, in *RepairSegmentRequest, opts ...grpc.CallOption) (*RepairSegmentResponse, error) {
+	out := new(RepairSegmentResponse)
+	err := c.cc.Invoke(ctx, "/repair.DataRepair/RepairSegment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DataRepairServer is the server API for DataRepair service.
+type DataRepairServer interface {
+	// RepairSegment repairs a segment right away
+	RepairSegment(context.Context, *RepairSegmentRequest) (*RepairSegmentResponse, error)
+}
+
+func RegisterDataRepairServer(s *grpc.Server, srv DataRepairServer) {
+	s.RegisterService(&_DataRepair_serviceDesc, srv)
+}
+
+func _DataRepair_RepairSegment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RepairSegmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataRepairServer).RepairSegment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/repair.DataRepair/RepairSegment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataRepairServer).RepairSegment(ctx, req.(*RepairSegmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _DataRepair_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "repair.DataRepair",
+	HandlerType: (*DataRepairServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RepairSegment",
+			Handler:    _DataRepair_RepairSegment_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "datarepair.proto",
 }
 
 func init() { proto.RegisterFile("datarepair.proto", fileDescriptor_datarepair_13e4beab54f194bd) }
 
 var fileDescriptor_datarepair_13e4beab54f194bd = []byte{
-	// 119 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x48, 0x49, 0x2c, 0x49,
-	0x2c, 0x4a, 0x2d, 0x48, 0xcc, 0x2c, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x83, 0xf0,
-	0x94, 0x5c, 0xb9, 0xf8, 0x3c, 0xf3, 0xb2, 0x4a, 0x8b, 0x52, 0x53, 0x82, 0x53, 0xd3, 0x73, 0x53,
-	0xf3, 0x4a, 0x84, 0x84, 0xb8, 0x58, 0x0a, 0x12, 0x4b, 0x32, 0x24, 0x18, 0x15, 0x18, 0x35, 0x38,
-	0x83, 0xc0, 0x6c, 0x21, 0x79, 0x2e, 0xee, 0x9c, 0xfc, 0xe2, 0x92, 0xf8, 0x82, 0xcc, 0xd4, 0xe4,
-	0xd4, 0x62, 0x09, 0x26, 0x05, 0x66, 0x0d, 0xd6, 0x20, 0x2e, 0x90, 0x50, 0x00, 0x58, 0xc4, 0x89,
-	0x25, 0x8a, 0xa9, 0x20, 0x29, 0x89, 0x0d, 0x6c, 0xb6, 0x31, 0x20, 0x00, 0x00, 0xff, 0xff, 0x13,
-	0xff, 0xff, 0x1e, 0x6f, 0x00, 0x00, 0x00,
+	// 378 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x92, 0xcf, 0x6e, 0xd3, 0x40,
+	0x10, 0xc6, 0x65, 0x37, 0x71, 0xdb, 0x69, 0xd3, 0x56, 0x2b, 0xa0, 0x56, 0x54, 0x54, 0xcb, 0x1c,
+	0x30, 0x17, 0x47, 0x0a, 0x0f, 0x80, 0x82, 0x72, 0x89, 0xe0, 0x80, 0x16, 0x4e, 0xb9, 0x58, 0x63,
+	0x7b, 0x08, 0x46, 0xb6, 0x77, 0xbd, 0xbb, 0x46, 0xe2, 0xb1, 0x78, 0x43, 0xb4, 0xeb, 0x0d, 0x08,
+	0xc4, 0x1f, 0xa9, 0x37, 0xcf, 0x6f, 0xbe, 0x99, 0xef, 0xf3, 0xd8, 0x70, 0x53, 0xa3, 0x41, 0x45,
+	0x12, 0x1b, 0x95, 0x4b, 0x25, 0x8c, 0x60, 0xd1, 0x54, 0x2d, 0xef, 0x0f, 0x42, 0x1c, 0x5a, 0x5a,
+	0x39, 0x5a, 0x8e, 0x1f, 0x57, 0xa6, 0xe9, 0x48, 0x1b, 0xec, 0xe4, 0x24, 0x4c, 0xbf, 0xc2, 0xd5,
+	0xae, 0xff, 0x3c, 0x2a, 0xaa, 0xdf, 0xd3, 0xa1, 0xa3, 0xde, 0x30, 0x06, 0x33, 0x89, 0xe6, 0x53,
+	0x1c, 0x24, 0x41, 0x76, 0xce, 0xdd, 0x33, 0xbb, 0x87, 0x8b, 0x56, 0x68, 0x53, 0xc8, 0x86, 0x2a,
+	0xd2, 0x71, 0x98, 0x9c, 0x64, 0x73, 0x0e, 0x16, 0xbd, 0x73, 0x84, 0xad, 0x21, 0x1a, 0x46, 0x1a,
+	0xa9, 0x8e, 0x4f, 0x92, 0x20, 0xbb, 0x58, 0x2f, 0xf3, 0xc9, 0x38, 0x3f, 0x1a, 0xe7, 0x1f, 0x8e,
+	0xc6, 0xdc, 0x2b, 0xd3, 0x6f, 0x21, 0xb0, 0x9d, 0xb2, 0x41, 0x15, 0x96, 0x2d, 0xfd, 0xcb, 0xff,
+	0x09, 0x44, 0x8a, 0x50, 0x8b, 0x3e, 0x0e, 0x1d, 0xf5, 0x15, 0x7b, 0x01, 0x37, 0xf8, 0x05, 0x9b,
+	0xd6, 0xce, 0x1f, 0xc3, 0xd9, 0x00, 0x73, 0x7e, 0xfd, 0x83, 0xfb, 0x84, 0xb7, 0x70, 0xda, 0x35,
+	0x7d, 0xa1, 0x68, 0x88, 0x67, 0x4e, 0x11, 0x75, 0x4d, 0xcf, 0x69, 0x60, 0xaf, 0x60, 0xd1, 0xa2,
+	0x36, 0x45, 0x4d, 0x86, 0x2a, 0x43, 0x75, 0x3c, 0xff, 0xef, 0x1b, 0x5c, 0xda, 0x81, 0xad, 0xd7,
+	0xb3, 0xe7, 0x70, 0x3d, 0x5d, 0xbb, 0x40, 0x63, 0xa8, 0x93, 0x46, 0xc7, 0x91, 0x73, 0xb8, 0x9a,
+	0xf0, 0xc6, 0x53, 0x76, 0x07, 0xe7, 0xa4, 0x2b, 0x6c, 0xd1, 0xba, 0x9c, 0x26, 0x41, 0x76, 0xc6,
+	0x7f, 0x02, 0xf6, 0x0c, 0x16, 0xad, 0xd0, 0xba, 0xc0, 0xaa, 0x22, 0x69, 0x15, 0x67, 0x4e, 0x71,
+	0x69, 0xe1, 0xc6, 0xb3, 0xf4, 0x0d, 0x3c, 0xe2, 0x6e, 0xa9, 0xbf, 0x16, 0xa7, 0x61, 0x24, 0xfd,
+	0xb0, 0x8f, 0x96, 0xde, 0xc2, 0xe3, 0xdf, 0x96, 0x69, 0x29, 0x7a, 0x4d, 0xeb, 0x3d, 0xc0, 0x16,
+	0x0d, 0x4e, 0x4d, 0xf6, 0x16, 0x16, 0xbf, 0xc8, 0xd8, 0x5d, 0xee, 0xff, 0xb5, 0x3f, 0x45, 0x59,
+	0x3e, 0xfd, 0x4b, 0x77, 0xda, 0xfd, 0x7a, 0xb6, 0x0f, 0x65, 0x59, 0x46, 0xee, 0xaa, 0x2f, 0xbf,
+	0x0f, 0x00, 0x41, 0x48, 0xc0, 0x79, 0xba, 0x02, 0x00, 0x00,
 }
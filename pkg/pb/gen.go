@@ -8,3 +8,6 @@ package pb
 //go:generate protoc --go_out=plugins=grpc:. pointerdb.proto
 //go:generate protoc --go_out=plugins=grpc:. piecestore.proto
 //go:generate protoc --go_out=plugins=grpc:. bandwidth.proto
+//go:generate protoc --go_out=plugins=grpc:. certificates.proto
+//go:generate protoc --go_out=plugins=grpc:. transition.proto
+//go:generate protoc --go_out=plugins=grpc:. macaroon.proto
@@ -7,6 +7,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import duration "github.com/golang/protobuf/ptypes/duration"
+import timestamp "github.com/golang/protobuf/ptypes/timestamp"
 
 import (
 	context "golang.org/x/net/context"
@@ -29,14 +30,18 @@ type NodeTransport int32
 
 const (
 	NodeTransport_TCP_TLS_GRPC NodeTransport = 0
+	// QUIC_TLS_GRPC nodes also serve gRPC over QUIC, on the UDP port of their address
+	NodeTransport_QUIC_TLS_GRPC NodeTransport = 1
 )
 
 var NodeTransport_name = map[int32]string{
 	0: "TCP_TLS_GRPC",
+	1: "QUIC_TLS_GRPC",
 }
 
 var NodeTransport_value = map[string]int32{
-	"TCP_TLS_GRPC": 0,
+	"TCP_TLS_GRPC":  0,
+	"QUIC_TLS_GRPC": 1,
 }
 
 func (x NodeTransport) String() string {
@@ -104,7 +109,7 @@ func (x Restriction_Operator) String() string {
 }
 
 func (Restriction_Operator) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_61fc82527fbe24ad, []int{15, 0}
+	return fileDescriptor_61fc82527fbe24ad, []int{19, 0}
 }
 
 type Restriction_Operand int32
@@ -129,7 +134,7 @@ func (x Restriction_Operand) String() string {
 }
 
 func (Restriction_Operand) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_61fc82527fbe24ad, []int{15, 1}
+	return fileDescriptor_61fc82527fbe24ad, []int{19, 1}
 }
 
 // LookupRequest is is request message for the lookup rpc call
@@ -612,13 +617,24 @@ func (m *NodeRestrictions) GetFreeDisk() int64 {
 
 // Node represents a node in the overlay network
 type Node struct {
-	Id                   string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Address              *NodeAddress      `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
-	Type                 NodeType          `protobuf:"varint,3,opt,name=type,proto3,enum=overlay.NodeType" json:"type,omitempty"`
-	Restrictions         *NodeRestrictions `protobuf:"bytes,4,opt,name=restrictions,proto3" json:"restrictions,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	Id           string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Address      *NodeAddress      `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Type         NodeType          `protobuf:"varint,3,opt,name=type,proto3,enum=overlay.NodeType" json:"type,omitempty"`
+	Restrictions *NodeRestrictions `protobuf:"bytes,4,opt,name=restrictions,proto3" json:"restrictions,omitempty"`
+	// signature is made by the node's identity over its id, address and type
+	// so that other nodes can't forge the record
+	Signature []byte `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+	// identity_chain is the node's certificate chain, starting with the leaf,
+	// which the signature and the id are verified against
+	IdentityChain [][]byte `protobuf:"bytes,6,rep,name=identity_chain,json=identityChain,proto3" json:"identity_chain,omitempty"`
+	// version is the software version the node is running
+	Version string `protobuf:"bytes,7,opt,name=version,proto3" json:"version,omitempty"`
+	// signed_at is when the record was signed, so a newer record of the node
+	// supersedes the older ones
+	SignedAt             *timestamp.Timestamp `protobuf:"bytes,8,opt,name=signed_at,json=signedAt,proto3" json:"signed_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
 func (m *Node) Reset()         { *m = Node{} }
@@ -673,6 +689,34 @@ func (m *Node) GetRestrictions() *NodeRestrictions {
 	return nil
 }
 
+func (m *Node) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *Node) GetIdentityChain() [][]byte {
+	if m != nil {
+		return m.IdentityChain
+	}
+	return nil
+}
+
+func (m *Node) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Node) GetSignedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.SignedAt
+	}
+	return nil
+}
+
 type QueryRequest struct {
 	Sender               *Node    `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
 	Target               *Node    `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
@@ -736,8 +780,11 @@ func (m *QueryRequest) GetPingback() bool {
 }
 
 type QueryResponse struct {
-	Sender               *Node    `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
-	Response             []*Node  `protobuf:"bytes,2,rep,name=response,proto3" json:"response,omitempty"`
+	Sender   *Node   `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Response []*Node `protobuf:"bytes,2,rep,name=response,proto3" json:"response,omitempty"`
+	// sender_unreachable is set when a pingback was requested but the sender
+	// could not be reached at its advertised address
+	SenderUnreachable    bool     `protobuf:"varint,3,opt,name=sender_unreachable,json=senderUnreachable,proto3" json:"sender_unreachable,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -781,6 +828,13 @@ func (m *QueryResponse) GetResponse() []*Node {
 	return nil
 }
 
+func (m *QueryResponse) GetSenderUnreachable() bool {
+	if m != nil {
+		return m.SenderUnreachable
+	}
+	return false
+}
+
 type PingRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -841,6 +895,152 @@ func (m *PingResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_PingResponse proto.InternalMessageInfo
 
+type LeaveRequest struct {
+	Sender *Node `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	// expected_downtime is how long the sender expects to be offline
+	ExpectedDowntime     *duration.Duration `protobuf:"bytes,2,opt,name=expected_downtime,json=expectedDowntime,proto3" json:"expected_downtime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *LeaveRequest) Reset()         { *m = LeaveRequest{} }
+func (m *LeaveRequest) String() string { return proto.CompactTextString(m) }
+func (*LeaveRequest) ProtoMessage()    {}
+func (*LeaveRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_61fc82527fbe24ad, []int{15}
+}
+func (m *LeaveRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaveRequest.Unmarshal(m, b)
+}
+func (m *LeaveRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaveRequest.Marshal(b, m, deterministic)
+}
+func (dst *LeaveRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaveRequest.Merge(dst, src)
+}
+func (m *LeaveRequest) XXX_Size() int {
+	return xxx_messageInfo_LeaveRequest.Size(m)
+}
+func (m *LeaveRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaveRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaveRequest proto.InternalMessageInfo
+
+func (m *LeaveRequest) GetSender() *Node {
+	if m != nil {
+		return m.Sender
+	}
+	return nil
+}
+
+func (m *LeaveRequest) GetExpectedDowntime() *duration.Duration {
+	if m != nil {
+		return m.ExpectedDowntime
+	}
+	return nil
+}
+
+type LeaveResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaveResponse) Reset()         { *m = LeaveResponse{} }
+func (m *LeaveResponse) String() string { return proto.CompactTextString(m) }
+func (*LeaveResponse) ProtoMessage()    {}
+func (*LeaveResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_61fc82527fbe24ad, []int{16}
+}
+func (m *LeaveResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaveResponse.Unmarshal(m, b)
+}
+func (m *LeaveResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaveResponse.Marshal(b, m, deterministic)
+}
+func (dst *LeaveResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaveResponse.Merge(dst, src)
+}
+func (m *LeaveResponse) XXX_Size() int {
+	return xxx_messageInfo_LeaveResponse.Size(m)
+}
+func (m *LeaveResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaveResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaveResponse proto.InternalMessageInfo
+
+type ExternalAddressRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExternalAddressRequest) Reset()         { *m = ExternalAddressRequest{} }
+func (m *ExternalAddressRequest) String() string { return proto.CompactTextString(m) }
+func (*ExternalAddressRequest) ProtoMessage()    {}
+func (*ExternalAddressRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_61fc82527fbe24ad, []int{17}
+}
+func (m *ExternalAddressRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExternalAddressRequest.Unmarshal(m, b)
+}
+func (m *ExternalAddressRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExternalAddressRequest.Marshal(b, m, deterministic)
+}
+func (dst *ExternalAddressRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExternalAddressRequest.Merge(dst, src)
+}
+func (m *ExternalAddressRequest) XXX_Size() int {
+	return xxx_messageInfo_ExternalAddressRequest.Size(m)
+}
+func (m *ExternalAddressRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExternalAddressRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExternalAddressRequest proto.InternalMessageInfo
+
+type ExternalAddressResponse struct {
+	// address is the host and port the request was received from
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExternalAddressResponse) Reset()         { *m = ExternalAddressResponse{} }
+func (m *ExternalAddressResponse) String() string { return proto.CompactTextString(m) }
+func (*ExternalAddressResponse) ProtoMessage()    {}
+func (*ExternalAddressResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_61fc82527fbe24ad, []int{18}
+}
+func (m *ExternalAddressResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExternalAddressResponse.Unmarshal(m, b)
+}
+func (m *ExternalAddressResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExternalAddressResponse.Marshal(b, m, deterministic)
+}
+func (dst *ExternalAddressResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExternalAddressResponse.Merge(dst, src)
+}
+func (m *ExternalAddressResponse) XXX_Size() int {
+	return xxx_messageInfo_ExternalAddressResponse.Size(m)
+}
+func (m *ExternalAddressResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExternalAddressResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExternalAddressResponse proto.InternalMessageInfo
+
+func (m *ExternalAddressResponse) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
 type Restriction struct {
 	Operator             Restriction_Operator `protobuf:"varint,1,opt,name=operator,proto3,enum=overlay.Restriction_Operator" json:"operator,omitempty"`
 	Operand              Restriction_Operand  `protobuf:"varint,2,opt,name=operand,proto3,enum=overlay.Restriction_Operand" json:"operand,omitempty"`
@@ -854,7 +1054,7 @@ func (m *Restriction) Reset()         { *m = Restriction{} }
 func (m *Restriction) String() string { return proto.CompactTextString(m) }
 func (*Restriction) ProtoMessage()    {}
 func (*Restriction) Descriptor() ([]byte, []int) {
-	return fileDescriptor_61fc82527fbe24ad, []int{15}
+	return fileDescriptor_61fc82527fbe24ad, []int{19}
 }
 func (m *Restriction) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Restriction.Unmarshal(m, b)
@@ -911,6 +1111,10 @@ func init() {
 	proto.RegisterType((*QueryResponse)(nil), "overlay.QueryResponse")
 	proto.RegisterType((*PingRequest)(nil), "overlay.PingRequest")
 	proto.RegisterType((*PingResponse)(nil), "overlay.PingResponse")
+	proto.RegisterType((*LeaveRequest)(nil), "overlay.LeaveRequest")
+	proto.RegisterType((*LeaveResponse)(nil), "overlay.LeaveResponse")
+	proto.RegisterType((*ExternalAddressRequest)(nil), "overlay.ExternalAddressRequest")
+	proto.RegisterType((*ExternalAddressResponse)(nil), "overlay.ExternalAddressResponse")
 	proto.RegisterType((*Restriction)(nil), "overlay.Restriction")
 	proto.RegisterEnum("overlay.NodeTransport", NodeTransport_name, NodeTransport_value)
 	proto.RegisterEnum("overlay.NodeType", NodeType_name, NodeType_value)
@@ -1068,6 +1272,11 @@ var _Overlay_serviceDesc = grpc.ServiceDesc{
 type NodesClient interface {
 	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
 	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+	// Leave tells a node that the sender is going offline temporarily
+	Leave(ctx context.Context, in *LeaveRequest, opts ...grpc.CallOption) (*LeaveResponse, error)
+	// ExternalAddress returns the address the request was received from, so
+	// that nodes behind a NAT can learn their public address
+	ExternalAddress(ctx context.Context, in *ExternalAddressRequest, opts ...grpc.CallOption) (*ExternalAddressResponse, error)
 }
 
 type nodesClient struct {
@@ -1096,10 +1305,33 @@ func (c *nodesClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.Ca
 	return out, nil
 }
 
+func (c *nodesClient) Leave(ctx context.Context, in *LeaveRequest, opts ...grpc.CallOption) (*LeaveResponse, error) {
+	out := new(LeaveResponse)
+	err := c.cc.Invoke(ctx, "/overlay.Nodes/Leave", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodesClient) ExternalAddress(ctx context.Context, in *ExternalAddressRequest, opts ...grpc.CallOption) (*ExternalAddressResponse, error) {
+	out := new(ExternalAddressResponse)
+	err := c.cc.Invoke(ctx, "/overlay.Nodes/ExternalAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // NodesServer is the server API for Nodes service.
 type NodesServer interface {
 	Query(context.Context, *QueryRequest) (*QueryResponse, error)
 	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	// Leave tells a node that the sender is going offline temporarily
+	Leave(context.Context, *LeaveRequest) (*LeaveResponse, error)
+	// ExternalAddress returns the address the request was received from, so
+	// that nodes behind a NAT can learn their public address
+	ExternalAddress(context.Context, *ExternalAddressRequest) (*ExternalAddressResponse, error)
 }
 
 func RegisterNodesServer(s *grpc.Server, srv NodesServer) {
@@ -1142,6 +1374,42 @@ func _Nodes_Ping_Handler(srv interface{}, ctx context.Context, dec func(interfac
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Nodes_Leave_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodesServer).Leave(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/overlay.Nodes/Leave",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodesServer).Leave(ctx, req.(*LeaveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Nodes_ExternalAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExternalAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodesServer).ExternalAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/overlay.Nodes/ExternalAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodesServer).ExternalAddress(ctx, req.(*ExternalAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Nodes_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "overlay.Nodes",
 	HandlerType: (*NodesServer)(nil),
@@ -1154,6 +1422,14 @@ var _Nodes_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Ping",
 			Handler:    _Nodes_Ping_Handler,
 		},
+		{
+			MethodName: "Leave",
+			Handler:    _Nodes_Leave_Handler,
+		},
+		{
+			MethodName: "ExternalAddress",
+			Handler:    _Nodes_ExternalAddress_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "overlay.proto",
@@ -1162,66 +1438,79 @@ var _Nodes_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("overlay.proto", fileDescriptor_61fc82527fbe24ad) }
 
 var fileDescriptor_61fc82527fbe24ad = []byte{
-	// 968 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0x6d, 0x6f, 0xe3, 0x44,
-	0x10, 0xae, 0x9d, 0xf7, 0x49, 0xe2, 0xf3, 0x8d, 0xee, 0xda, 0x10, 0x1d, 0xa7, 0xd6, 0x50, 0x51,
-	0x8a, 0x94, 0x93, 0xd2, 0x53, 0xa5, 0x4a, 0xa0, 0xaa, 0x6f, 0x54, 0x27, 0x42, 0xdb, 0xdb, 0x04,
-	0x21, 0x21, 0xa1, 0xca, 0x89, 0xf7, 0x72, 0xa6, 0xc9, 0xae, 0xb1, 0xd7, 0x77, 0x17, 0x7e, 0x04,
-	0xff, 0x82, 0x0f, 0x48, 0xfc, 0x26, 0x7e, 0x07, 0x1f, 0x91, 0x77, 0xd7, 0x4e, 0xec, 0x36, 0x27,
-	0xf8, 0x64, 0xcf, 0x33, 0xcf, 0x8c, 0x67, 0x66, 0x1f, 0xcf, 0x42, 0x9b, 0xbf, 0xa3, 0xe1, 0xcc,
-	0x5d, 0xf4, 0x82, 0x90, 0x0b, 0x8e, 0x35, 0x6d, 0x76, 0x9f, 0x4f, 0x39, 0x9f, 0xce, 0xe8, 0x0b,
-	0x09, 0x8f, 0xe3, 0x37, 0x2f, 0xbc, 0x38, 0x74, 0x85, 0xcf, 0x99, 0x22, 0x3a, 0x5f, 0x40, 0x7b,
-	0xc0, 0xf9, 0x5d, 0x1c, 0x10, 0xfa, 0x6b, 0x4c, 0x23, 0x81, 0x9b, 0x50, 0x65, 0xdc, 0xa3, 0xaf,
-	0xce, 0x3b, 0xc6, 0xb6, 0xb1, 0xd7, 0x20, 0xda, 0x72, 0x0e, 0xc0, 0x4a, 0x89, 0x51, 0xc0, 0x59,
-	0x44, 0x71, 0x07, 0xca, 0x89, 0x4f, 0xf2, 0x9a, 0xfd, 0x76, 0x2f, 0xad, 0xe0, 0x8a, 0x7b, 0x94,
-	0x48, 0x97, 0x73, 0xb5, 0x0c, 0x92, 0xd9, 0x23, 0xfc, 0x1a, 0xda, 0x33, 0x89, 0x84, 0x0a, 0xe9,
-	0x18, 0xdb, 0xa5, 0xbd, 0x66, 0x7f, 0x33, 0x8b, 0xce, 0xf1, 0x49, 0x9e, 0xec, 0x10, 0x78, 0x94,
-	0x2f, 0x22, 0xc2, 0x63, 0xb0, 0x52, 0x8e, 0x82, 0x74, 0xc6, 0xad, 0x7b, 0x19, 0x95, 0x9b, 0x14,
-	0xe8, 0xce, 0x31, 0x74, 0xbe, 0xf5, 0x99, 0x37, 0x14, 0x3c, 0x74, 0xa7, 0x34, 0x29, 0x3e, 0xca,
-	0x5a, 0xfc, 0x0c, 0x2a, 0x49, 0x1f, 0x91, 0xce, 0x59, 0xe8, 0x51, 0xf9, 0x9c, 0x3f, 0x0d, 0xd8,
-	0xba, 0x9f, 0x41, 0x4d, 0xf3, 0x39, 0x00, 0x1f, 0xff, 0x42, 0x27, 0x62, 0xe8, 0xff, 0xa6, 0x26,
-	0x55, 0x22, 0x2b, 0x08, 0x9e, 0x80, 0x35, 0xe1, 0x4c, 0x84, 0xee, 0x44, 0x0c, 0x28, 0x9b, 0x8a,
-	0xb7, 0x1d, 0x53, 0x4e, 0xf3, 0x93, 0x9e, 0x3a, 0xb7, 0x5e, 0x7a, 0x6e, 0xbd, 0x73, 0x7d, 0x6e,
-	0xa4, 0x10, 0x80, 0x5f, 0x41, 0x99, 0x07, 0x22, 0xea, 0x94, 0x64, 0xe0, 0xb2, 0xed, 0x6b, 0xf5,
-	0xbc, 0x0e, 0x92, 0xa8, 0x88, 0x48, 0x92, 0xf3, 0x33, 0x34, 0x93, 0xfa, 0x4e, 0x3c, 0x2f, 0xa4,
-	0x51, 0x84, 0x2f, 0xa1, 0x21, 0x42, 0x97, 0x45, 0x01, 0x0f, 0x85, 0xac, 0xce, 0x5a, 0x39, 0x89,
-	0x84, 0x38, 0x4a, 0xbd, 0x64, 0x49, 0xc4, 0x0e, 0xd4, 0x5c, 0x95, 0x40, 0x56, 0xdb, 0x20, 0xa9,
-	0xe9, 0xfc, 0x61, 0x82, 0x95, 0xff, 0x2e, 0x1e, 0x01, 0xcc, 0xdd, 0x0f, 0x03, 0x57, 0x50, 0x36,
-	0x59, 0x68, 0xad, 0x7c, 0xa4, 0xbb, 0x15, 0x32, 0x1e, 0x42, 0x7b, 0xee, 0x33, 0x42, 0x83, 0x58,
-	0x48, 0xa7, 0x9e, 0x8d, 0x9d, 0x3f, 0x05, 0x1a, 0x90, 0x3c, 0x0d, 0x1d, 0x68, 0xcd, 0x7d, 0x36,
-	0x0c, 0x28, 0xf5, 0xbe, 0x1b, 0x07, 0x6a, 0x32, 0x25, 0x92, 0xc3, 0x12, 0x99, 0xbb, 0x73, 0x1e,
-	0x33, 0xd1, 0x29, 0x4b, 0xaf, 0xb6, 0xf0, 0x1b, 0x68, 0x85, 0x34, 0x12, 0xa1, 0x3f, 0x91, 0xe5,
-	0x77, 0x2a, 0xba, 0xe0, 0xfc, 0x27, 0x97, 0x04, 0x92, 0xa3, 0xe3, 0x2e, 0x58, 0xf4, 0xc3, 0x64,
-	0x16, 0x7b, 0xd4, 0xbb, 0x55, 0xca, 0xa9, 0x6e, 0x97, 0xf6, 0x1a, 0xa4, 0x9d, 0xa2, 0x52, 0x1d,
-	0xce, 0x7b, 0xa8, 0xe9, 0xda, 0xf1, 0x19, 0x34, 0xe6, 0x3e, 0xfb, 0x21, 0x10, 0xfe, 0x5c, 0x09,
-	0xc4, 0x24, 0x4b, 0x00, 0xf7, 0xe0, 0xd1, 0xdc, 0x67, 0x27, 0xb1, 0xe7, 0x8b, 0x61, 0x3c, 0x99,
-	0xa4, 0x23, 0x37, 0x49, 0x11, 0xc6, 0xcf, 0xe5, 0xb0, 0x24, 0x74, 0x26, 0xfb, 0x52, 0x5d, 0xe7,
-	0x41, 0x67, 0x04, 0x76, 0xb1, 0x83, 0x24, 0xf2, 0x4d, 0x48, 0xe9, 0xa9, 0xcb, 0xbc, 0xf7, 0xbe,
-	0x27, 0xde, 0x6a, 0x99, 0xe6, 0x41, 0xec, 0x42, 0x3d, 0x01, 0xce, 0xfd, 0xe8, 0x4e, 0x96, 0x50,
-	0x22, 0x99, 0xed, 0xfc, 0x65, 0x40, 0x39, 0x49, 0x8b, 0x16, 0x98, 0xbe, 0xa7, 0x17, 0x87, 0xe9,
-	0x7b, 0xd8, 0xcb, 0x2b, 0xa5, 0xd9, 0x7f, 0x92, 0x1b, 0xa4, 0x96, 0x61, 0xa6, 0x1f, 0xdc, 0x85,
-	0xb2, 0x58, 0x04, 0x54, 0xd6, 0x6e, 0xf5, 0x1f, 0xe7, 0xa5, 0xb8, 0x08, 0x28, 0x91, 0xee, 0x7b,
-	0x87, 0x54, 0xfe, 0x5f, 0x87, 0xe4, 0xfc, 0x6e, 0x40, 0xeb, 0x75, 0x4c, 0xc3, 0x45, 0xfa, 0x97,
-	0xee, 0x42, 0x35, 0xa2, 0xcc, 0xa3, 0xe1, 0xc3, 0xbb, 0x4c, 0x3b, 0x13, 0x9a, 0x70, 0xc3, 0x29,
-	0x15, 0xba, 0x99, 0x22, 0x4d, 0x39, 0xf1, 0x09, 0x54, 0x66, 0xfe, 0xdc, 0x4f, 0x4f, 0x40, 0x19,
-	0xc9, 0xfc, 0x02, 0x9f, 0x4d, 0xc7, 0xee, 0xe4, 0x4e, 0xd6, 0x5b, 0x27, 0x99, 0xed, 0xb8, 0xd0,
-	0xd6, 0xf5, 0xe8, 0xbd, 0xf3, 0x1f, 0x0b, 0xfa, 0x12, 0xea, 0xd9, 0xd6, 0x33, 0x1f, 0xda, 0x50,
-	0x99, 0xdb, 0x69, 0x43, 0xf3, 0xc6, 0x67, 0x53, 0xdd, 0xb1, 0x63, 0x41, 0x4b, 0x99, 0xda, 0xfd,
-	0x8f, 0x01, 0xcd, 0x95, 0x89, 0xe1, 0x11, 0xd4, 0x79, 0x40, 0x43, 0x57, 0xf0, 0x50, 0xef, 0x85,
-	0x4f, 0xb3, 0xcc, 0x2b, 0xbc, 0xde, 0xb5, 0x26, 0x91, 0x8c, 0x8e, 0x87, 0x50, 0x93, 0xef, 0xcc,
-	0x93, 0x63, 0xb2, 0xfa, 0xcf, 0xd6, 0x47, 0x32, 0x8f, 0xa4, 0xe4, 0x64, 0x6c, 0xef, 0xdc, 0x59,
-	0x4c, 0xd3, 0xb1, 0x49, 0xc3, 0x79, 0x09, 0xf5, 0xf4, 0x1b, 0x58, 0x05, 0x73, 0x30, 0xb2, 0x37,
-	0x92, 0xe7, 0xc5, 0x6b, 0xdb, 0x48, 0x9e, 0x97, 0x23, 0xdb, 0xc4, 0x1a, 0x94, 0x06, 0xa3, 0x0b,
-	0xbb, 0x94, 0xbc, 0x5c, 0x8e, 0x2e, 0xec, 0xb2, 0xb3, 0x0f, 0x35, 0x9d, 0x1f, 0x1f, 0x17, 0xd4,
-	0x6d, 0x6f, 0x60, 0x6b, 0x29, 0x65, 0xdb, 0xd8, 0xdf, 0x81, 0x76, 0x6e, 0xd3, 0xa1, 0x0d, 0xad,
-	0xd1, 0xd9, 0xcd, 0xed, 0x68, 0x30, 0xbc, 0xbd, 0x24, 0x37, 0x67, 0xf6, 0xc6, 0xbe, 0x03, 0xf5,
-	0x54, 0x81, 0xd8, 0x80, 0xca, 0xc9, 0xf9, 0xf7, 0xaf, 0xae, 0xec, 0x0d, 0x6c, 0x42, 0x6d, 0x38,
-	0xba, 0x26, 0x27, 0x97, 0x17, 0xb6, 0xd1, 0xff, 0xdb, 0x80, 0x9a, 0x5e, 0x7d, 0x78, 0x04, 0x55,
-	0x75, 0xe9, 0xe0, 0x9a, 0x7b, 0xad, 0xbb, 0xee, 0x76, 0xc2, 0x63, 0x80, 0xd3, 0x78, 0x76, 0xa7,
-	0xc3, 0xb7, 0x1e, 0x0e, 0x8f, 0xba, 0x9d, 0x35, 0xf1, 0x11, 0xfe, 0x08, 0x76, 0xf1, 0x32, 0xc2,
-	0xed, 0x8c, 0xbd, 0xe6, 0x9e, 0xea, 0xee, 0x7c, 0x84, 0xa1, 0x32, 0xf7, 0x05, 0x54, 0x54, 0xb6,
-	0x43, 0xa8, 0x48, 0xb5, 0xe2, 0xd3, 0x2c, 0x68, 0xf5, 0x6f, 0xea, 0x6e, 0x16, 0x61, 0xdd, 0xda,
-	0x01, 0x94, 0x13, 0xcd, 0xe1, 0x72, 0x07, 0xac, 0x28, 0xb2, 0xfb, 0xb4, 0x80, 0xaa, 0xa0, 0xd3,
-	0xf2, 0x4f, 0x66, 0x30, 0x1e, 0x57, 0xe5, 0x45, 0x71, 0xf0, 0x6f, 0x00, 0x00, 0x00, 0xff, 0xff,
-	0xcc, 0x47, 0x3e, 0xe7, 0xe6, 0x08, 0x00, 0x00,
+	// 1182 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x56, 0xdd, 0x6e, 0xe3, 0xc4,
+	0x17, 0xaf, 0x9d, 0xef, 0x93, 0x38, 0x75, 0x47, 0xbb, 0xad, 0xff, 0xd1, 0xfe, 0x77, 0xb3, 0x86,
+	0x15, 0x65, 0x11, 0x59, 0x29, 0x5d, 0x15, 0xad, 0x04, 0xaa, 0xd2, 0x8f, 0xad, 0x2a, 0x42, 0x3f,
+	0x26, 0xa9, 0x90, 0x90, 0x50, 0xe4, 0xd8, 0xb3, 0xa9, 0x69, 0x32, 0x36, 0xf6, 0xb8, 0x6d, 0x90,
+	0xb8, 0xe1, 0x01, 0x10, 0x2f, 0xc1, 0x05, 0xef, 0xc1, 0x73, 0xf0, 0x1c, 0x5c, 0x22, 0xcf, 0x8c,
+	0x9d, 0xd8, 0x6d, 0x58, 0xf6, 0xca, 0x39, 0xbf, 0xf3, 0x3b, 0xc7, 0xe7, 0x3b, 0x06, 0xcd, 0xbb,
+	0x21, 0xc1, 0xd4, 0x9a, 0x77, 0xfc, 0xc0, 0x63, 0x1e, 0xaa, 0x48, 0xb1, 0xf5, 0x74, 0xe2, 0x79,
+	0x93, 0x29, 0x79, 0xc5, 0xe1, 0x71, 0xf4, 0xee, 0x95, 0x13, 0x05, 0x16, 0x73, 0x3d, 0x2a, 0x88,
+	0xad, 0x67, 0x79, 0x3d, 0x73, 0x67, 0x24, 0x64, 0xd6, 0xcc, 0x17, 0x04, 0xf3, 0x13, 0xd0, 0xfa,
+	0x9e, 0x77, 0x1d, 0xf9, 0x98, 0xfc, 0x18, 0x91, 0x90, 0xa1, 0x4d, 0x28, 0x53, 0xcf, 0x21, 0x27,
+	0x87, 0x86, 0xd2, 0x56, 0xb6, 0x6b, 0x58, 0x4a, 0xe6, 0x0e, 0x34, 0x13, 0x62, 0xe8, 0x7b, 0x34,
+	0x24, 0xe8, 0x39, 0x14, 0x63, 0x1d, 0xe7, 0xd5, 0xbb, 0x5a, 0x27, 0x09, 0xf1, 0xd4, 0x73, 0x08,
+	0xe6, 0x2a, 0xf3, 0x14, 0x9a, 0x19, 0xef, 0x21, 0xfa, 0x12, 0xb4, 0x29, 0x47, 0x02, 0x81, 0x18,
+	0x4a, 0xbb, 0xb0, 0x5d, 0xef, 0x6e, 0xa6, 0xd6, 0x19, 0x3e, 0xce, 0x92, 0x4d, 0x0c, 0xeb, 0xd9,
+	0x20, 0x42, 0xb4, 0x07, 0xcd, 0x84, 0x23, 0x20, 0xe9, 0x71, 0xeb, 0x9e, 0x47, 0xa1, 0xc6, 0x39,
+	0xba, 0xb9, 0x07, 0xc6, 0x5b, 0x97, 0x3a, 0x03, 0xe6, 0x05, 0xd6, 0x84, 0xc4, 0xc1, 0x87, 0x69,
+	0x8a, 0x1f, 0x41, 0x29, 0xce, 0x23, 0x94, 0x3e, 0x73, 0x39, 0x0a, 0x9d, 0xf9, 0x87, 0x02, 0x5b,
+	0xf7, 0x3d, 0x88, 0x6a, 0x3e, 0x05, 0xf0, 0xc6, 0x3f, 0x10, 0x9b, 0x0d, 0xdc, 0x9f, 0x44, 0xa5,
+	0x0a, 0x78, 0x09, 0x41, 0x3d, 0x68, 0xda, 0x1e, 0x65, 0x81, 0x65, 0xb3, 0x3e, 0xa1, 0x13, 0x76,
+	0x65, 0xa8, 0xbc, 0x9a, 0xff, 0xeb, 0x88, 0xc6, 0x75, 0x92, 0xc6, 0x75, 0x0e, 0x65, 0x63, 0x71,
+	0xce, 0x00, 0x7d, 0x06, 0x45, 0xcf, 0x67, 0xa1, 0x51, 0x68, 0x2b, 0x99, 0xb4, 0xcf, 0xc4, 0xf3,
+	0xcc, 0x8f, 0xad, 0x42, 0xcc, 0x49, 0xe6, 0xf7, 0x50, 0x8f, 0xe3, 0xeb, 0x39, 0x4e, 0x40, 0xc2,
+	0x10, 0xbd, 0x86, 0x1a, 0x0b, 0x2c, 0x1a, 0xfa, 0x5e, 0xc0, 0x78, 0x74, 0xcd, 0xa5, 0x4e, 0xc4,
+	0xc4, 0x61, 0xa2, 0xc5, 0x0b, 0x22, 0x32, 0xa0, 0x62, 0x09, 0x07, 0x3c, 0xda, 0x1a, 0x4e, 0x44,
+	0xf3, 0x77, 0x15, 0x9a, 0xd9, 0xf7, 0xa2, 0x37, 0x00, 0x33, 0xeb, 0xae, 0x6f, 0x31, 0x42, 0xed,
+	0xb9, 0xa1, 0xbc, 0x2f, 0xbb, 0x25, 0x32, 0xda, 0x05, 0x6d, 0xe6, 0x52, 0x4c, 0xfc, 0x88, 0x71,
+	0xa5, 0xac, 0x8d, 0x9e, 0xed, 0x02, 0xf1, 0x71, 0x96, 0x86, 0x4c, 0x68, 0xcc, 0x5c, 0x3a, 0xf0,
+	0x09, 0x71, 0xbe, 0x1e, 0xfb, 0xa2, 0x32, 0x05, 0x9c, 0xc1, 0xe2, 0x31, 0xb7, 0x66, 0x5e, 0x44,
+	0x99, 0x51, 0xe4, 0x5a, 0x29, 0xa1, 0xaf, 0xa0, 0x11, 0x90, 0x90, 0x05, 0xae, 0xcd, 0xc3, 0x37,
+	0x4a, 0x32, 0xe0, 0xec, 0x2b, 0x17, 0x04, 0x9c, 0xa1, 0xa3, 0x17, 0xd0, 0x24, 0x77, 0xf6, 0x34,
+	0x72, 0x88, 0x33, 0x12, 0x93, 0x53, 0x6e, 0x17, 0xb6, 0x6b, 0x58, 0x4b, 0x50, 0x3e, 0x1d, 0xe6,
+	0x2d, 0x54, 0x64, 0xec, 0xe8, 0x09, 0xd4, 0x66, 0x2e, 0xbd, 0xf4, 0xe3, 0xc5, 0xe4, 0xe5, 0x51,
+	0xf1, 0x02, 0x40, 0xdb, 0xb0, 0x3e, 0x73, 0x69, 0x2f, 0x72, 0x5c, 0x36, 0x88, 0x6c, 0x3b, 0x29,
+	0xb9, 0x8a, 0xf3, 0x30, 0xfa, 0x18, 0xb4, 0x04, 0x3a, 0xe0, 0x79, 0x89, 0xac, 0xb3, 0xa0, 0x39,
+	0x04, 0x3d, 0x9f, 0x41, 0x6c, 0xf9, 0x2e, 0x20, 0x64, 0xdf, 0xa2, 0xce, 0xad, 0xeb, 0xb0, 0x2b,
+	0x39, 0xa6, 0x59, 0x10, 0xb5, 0xa0, 0x1a, 0x03, 0x87, 0x6e, 0x78, 0xcd, 0x43, 0x28, 0xe0, 0x54,
+	0x36, 0xff, 0x54, 0xa1, 0x18, 0xbb, 0x45, 0x4d, 0x50, 0x5d, 0x47, 0x1e, 0x0e, 0xd5, 0x75, 0x50,
+	0x27, 0x3b, 0x29, 0xf5, 0xee, 0xa3, 0x4c, 0x21, 0xe5, 0x18, 0xa6, 0xf3, 0x83, 0x5e, 0x40, 0x91,
+	0xcd, 0x7d, 0xc2, 0x63, 0x6f, 0x76, 0x37, 0xb2, 0xa3, 0x38, 0xf7, 0x09, 0xe6, 0xea, 0x7b, 0x4d,
+	0x2a, 0x7e, 0x58, 0x93, 0x9e, 0x40, 0x2d, 0x74, 0x27, 0xd4, 0x62, 0x51, 0x40, 0x78, 0x83, 0x1b,
+	0x78, 0x01, 0xc4, 0x2d, 0x74, 0x1d, 0x42, 0x99, 0xcb, 0xe6, 0x23, 0xfb, 0xca, 0x72, 0x29, 0x6f,
+	0x61, 0x03, 0x6b, 0x09, 0x7a, 0x10, 0x83, 0xf1, 0x12, 0xdc, 0x90, 0x20, 0x8c, 0xc7, 0xb2, 0x22,
+	0x96, 0x40, 0x8a, 0xe8, 0x0b, 0xe1, 0x9e, 0x38, 0x23, 0x8b, 0x19, 0x55, 0x1e, 0x5a, 0xeb, 0xde,
+	0xc0, 0x0f, 0x93, 0x3b, 0x8c, 0xab, 0x82, 0xdc, 0x63, 0xe6, 0xaf, 0x0a, 0x34, 0x2e, 0x22, 0x12,
+	0xcc, 0x93, 0xeb, 0xf1, 0x02, 0xca, 0x21, 0xa1, 0x0e, 0x09, 0x1e, 0xbe, 0xb1, 0x52, 0x19, 0xd3,
+	0x98, 0x15, 0x4c, 0x08, 0x33, 0xd4, 0x07, 0x69, 0x42, 0x89, 0x1e, 0x41, 0x69, 0xea, 0xce, 0xdc,
+	0x64, 0x32, 0x84, 0x10, 0xf7, 0xd5, 0x77, 0xe9, 0x64, 0x6c, 0xd9, 0xd7, 0xbc, 0x8e, 0x55, 0x9c,
+	0xca, 0xe6, 0x6f, 0x0a, 0x68, 0x32, 0x20, 0x79, 0x10, 0xff, 0x63, 0x44, 0x9f, 0x42, 0x35, 0x3d,
+	0xc7, 0xea, 0x43, 0xa7, 0x33, 0x55, 0xa3, 0xcf, 0x01, 0x09, 0xa3, 0x51, 0x44, 0x03, 0x62, 0xd9,
+	0x57, 0xd6, 0x78, 0x2a, 0x06, 0xa0, 0x8a, 0x37, 0x84, 0xe6, 0x72, 0xa1, 0x30, 0x35, 0xa8, 0x9f,
+	0xbb, 0x74, 0x22, 0x2b, 0x64, 0x36, 0xa1, 0x21, 0x44, 0x79, 0xcc, 0x7f, 0x86, 0x46, 0x9f, 0x58,
+	0x37, 0xe4, 0x03, 0x2b, 0xf8, 0x16, 0x36, 0xc8, 0x9d, 0x4f, 0x6c, 0x46, 0x9c, 0x91, 0xe3, 0xdd,
+	0x52, 0xbe, 0x8c, 0xef, 0xbd, 0xc4, 0x7a, 0x62, 0x73, 0x28, 0x4d, 0xcc, 0x75, 0xd0, 0xe4, 0xeb,
+	0x65, 0x3c, 0x06, 0x6c, 0x1e, 0xdd, 0x31, 0x12, 0x50, 0x6b, 0x9a, 0x0c, 0xbb, 0x8c, 0x7c, 0x07,
+	0xb6, 0xee, 0x69, 0x64, 0x49, 0x96, 0xee, 0xab, 0x92, 0xbd, 0xaf, 0x7f, 0x2b, 0x50, 0x5f, 0x1a,
+	0x6c, 0xf4, 0x06, 0xaa, 0x9e, 0x4f, 0x02, 0x8b, 0x79, 0x81, 0x3c, 0xdf, 0xff, 0x4f, 0x13, 0x5c,
+	0xe2, 0x75, 0xce, 0x24, 0x09, 0xa7, 0x74, 0xb4, 0x0b, 0x15, 0xfe, 0x9b, 0x3a, 0x3c, 0xd1, 0x66,
+	0xf7, 0xc9, 0x6a, 0x4b, 0xea, 0xe0, 0x84, 0x1c, 0x4f, 0xd1, 0x8d, 0x35, 0x8d, 0x48, 0x32, 0x45,
+	0x5c, 0x30, 0x5f, 0x43, 0x35, 0x79, 0x07, 0x2a, 0x83, 0xda, 0x1f, 0xea, 0x6b, 0xf1, 0xf3, 0xe8,
+	0x42, 0x57, 0xe2, 0xe7, 0xf1, 0x50, 0x57, 0x51, 0x05, 0x0a, 0xfd, 0xe1, 0x91, 0x5e, 0x88, 0x7f,
+	0x1c, 0x0f, 0x8f, 0xf4, 0xa2, 0xf9, 0x12, 0x2a, 0xd2, 0x3f, 0xda, 0xc8, 0x1d, 0x21, 0x7d, 0x0d,
+	0x35, 0x16, 0x17, 0x47, 0x57, 0x5e, 0xbe, 0x06, 0x2d, 0xf3, 0x87, 0x84, 0x74, 0x68, 0x0c, 0x0f,
+	0xce, 0x47, 0xc3, 0xfe, 0x60, 0x74, 0x8c, 0xcf, 0x0f, 0xf4, 0xb5, 0xd8, 0xc7, 0xc5, 0xe5, 0xc9,
+	0xc1, 0x02, 0x52, 0x5e, 0x9a, 0x50, 0x4d, 0x6e, 0x07, 0xaa, 0x41, 0xa9, 0x77, 0xf8, 0xcd, 0xc9,
+	0xa9, 0xbe, 0x86, 0xea, 0x50, 0x19, 0x0c, 0xcf, 0x70, 0xef, 0xf8, 0x48, 0x57, 0xba, 0x7f, 0x29,
+	0x50, 0x91, 0x7f, 0x5a, 0xe8, 0x0d, 0x94, 0xc5, 0xe7, 0x02, 0x5a, 0xf1, 0x45, 0xd2, 0x5a, 0xf5,
+	0x5d, 0x81, 0xf6, 0x00, 0xf6, 0xa3, 0xe9, 0xb5, 0x34, 0xdf, 0x7a, 0xd8, 0x3c, 0x6c, 0x19, 0x2b,
+	0xec, 0x43, 0xf4, 0x2d, 0xe8, 0xf9, 0xcf, 0x08, 0xd4, 0x4e, 0xd9, 0x2b, 0xbe, 0x30, 0x5a, 0xcf,
+	0xff, 0x85, 0x21, 0x3c, 0x77, 0x7f, 0x51, 0xa1, 0x24, 0xdc, 0xed, 0x42, 0x89, 0xef, 0x33, 0x7a,
+	0x9c, 0x5a, 0x2d, 0x1f, 0x9c, 0xd6, 0x66, 0x1e, 0x96, 0xb9, 0xed, 0x40, 0x31, 0x5e, 0x33, 0xb4,
+	0x38, 0xdf, 0x4b, 0x4b, 0xd8, 0x7a, 0x9c, 0x43, 0xa5, 0xd1, 0x2e, 0x94, 0xf8, 0x32, 0x2c, 0xbd,
+	0x6c, 0x79, 0x37, 0x5b, 0x9b, 0x79, 0x58, 0xda, 0x0d, 0x61, 0x3d, 0xb7, 0x19, 0xe8, 0x59, 0x4a,
+	0x7d, 0x78, 0x9b, 0x5a, 0xed, 0xd5, 0x04, 0xe1, 0x75, 0xbf, 0xf8, 0x9d, 0xea, 0x8f, 0xc7, 0x65,
+	0xbe, 0xc5, 0x3b, 0xff, 0x0c, 0x00, 0x91, 0x70, 0x4a, 0xb8, 0x50, 0x0b, 0x00, 0x00,
 }
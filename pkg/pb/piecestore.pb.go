@@ -47,8 +47,11 @@ func (PayerBandwidthAllocation_Action) EnumDescriptor() ([]byte, []int) {
 }
 
 type PayerBandwidthAllocation struct {
-	Signature            []byte   `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
-	Data                 []byte   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Signature []byte `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	Data      []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// identity_chain is the certificate chain of the satellite, starting with
+	// the leaf, which storage nodes verify the signature with
+	IdentityChain        [][]byte `protobuf:"bytes,3,rep,name=identity_chain,json=identityChain,proto3" json:"identity_chain,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -92,6 +95,13 @@ func (m *PayerBandwidthAllocation) GetData() []byte {
 	return nil
 }
 
+func (m *PayerBandwidthAllocation) GetIdentityChain() [][]byte {
+	if m != nil {
+		return m.IdentityChain
+	}
+	return nil
+}
+
 type PayerBandwidthAllocation_Data struct {
 	SatelliteId          []byte                          `protobuf:"bytes,1,opt,name=satellite_id,json=satelliteId,proto3" json:"satellite_id,omitempty"`
 	UplinkId             []byte                          `protobuf:"bytes,2,opt,name=uplink_id,json=uplinkId,proto3" json:"uplink_id,omitempty"`
@@ -716,19 +726,122 @@ func (m *PieceDeleteSummary) GetMessage() string {
 	return ""
 }
 
-type PieceStoreSummary struct {
-	Message              string   `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
-	TotalReceived        int64    `protobuf:"varint,2,opt,name=totalReceived,proto3" json:"totalReceived,omitempty"`
+// PieceHash is the receipt of a storage node for a piece it stored, with
+// which it commits to store the piece
+type PieceHash struct {
+	Signature            []byte   `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	Data                 []byte   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PieceHash) Reset()         { *m = PieceHash{} }
+func (m *PieceHash) String() string { return proto.CompactTextString(m) }
+func (*PieceHash) ProtoMessage()    {}
+func (*PieceHash) Descriptor() ([]byte, []int) {
+	return fileDescriptor_piecestore_f59ad3d0b0de7e9d, []int{9}
+}
+func (m *PieceHash) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PieceHash.Unmarshal(m, b)
+}
+func (m *PieceHash) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PieceHash.Marshal(b, m, deterministic)
+}
+func (dst *PieceHash) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PieceHash.Merge(dst, src)
+}
+func (m *PieceHash) XXX_Size() int {
+	return xxx_messageInfo_PieceHash.Size(m)
+}
+func (m *PieceHash) XXX_DiscardUnknown() {
+	xxx_messageInfo_PieceHash.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PieceHash proto.InternalMessageInfo
+
+func (m *PieceHash) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *PieceHash) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type PieceHash_Data struct {
+	PieceId              string   `protobuf:"bytes,1,opt,name=piece_id,json=pieceId,proto3" json:"piece_id,omitempty"`
+	Hash                 []byte   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Size                 int64    `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
+func (m *PieceHash_Data) Reset()         { *m = PieceHash_Data{} }
+func (m *PieceHash_Data) String() string { return proto.CompactTextString(m) }
+func (*PieceHash_Data) ProtoMessage()    {}
+func (*PieceHash_Data) Descriptor() ([]byte, []int) {
+	return fileDescriptor_piecestore_f59ad3d0b0de7e9d, []int{9, 0}
+}
+func (m *PieceHash_Data) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PieceHash_Data.Unmarshal(m, b)
+}
+func (m *PieceHash_Data) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PieceHash_Data.Marshal(b, m, deterministic)
+}
+func (dst *PieceHash_Data) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PieceHash_Data.Merge(dst, src)
+}
+func (m *PieceHash_Data) XXX_Size() int {
+	return xxx_messageInfo_PieceHash_Data.Size(m)
+}
+func (m *PieceHash_Data) XXX_DiscardUnknown() {
+	xxx_messageInfo_PieceHash_Data.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PieceHash_Data proto.InternalMessageInfo
+
+func (m *PieceHash_Data) GetPieceId() string {
+	if m != nil {
+		return m.PieceId
+	}
+	return ""
+}
+
+func (m *PieceHash_Data) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *PieceHash_Data) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type PieceStoreSummary struct {
+	Message              string     `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	TotalReceived        int64      `protobuf:"varint,2,opt,name=totalReceived,proto3" json:"totalReceived,omitempty"`
+	PieceHash            *PieceHash `protobuf:"bytes,3,opt,name=pieceHash,proto3" json:"pieceHash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
 func (m *PieceStoreSummary) Reset()         { *m = PieceStoreSummary{} }
 func (m *PieceStoreSummary) String() string { return proto.CompactTextString(m) }
 func (*PieceStoreSummary) ProtoMessage()    {}
 func (*PieceStoreSummary) Descriptor() ([]byte, []int) {
-	return fileDescriptor_piecestore_f59ad3d0b0de7e9d, []int{9}
+	return fileDescriptor_piecestore_f59ad3d0b0de7e9d, []int{10}
 }
 func (m *PieceStoreSummary) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PieceStoreSummary.Unmarshal(m, b)
@@ -762,6 +875,13 @@ func (m *PieceStoreSummary) GetTotalReceived() int64 {
 	return 0
 }
 
+func (m *PieceStoreSummary) GetPieceHash() *PieceHash {
+	if m != nil {
+		return m.PieceHash
+	}
+	return nil
+}
+
 type StatsReq struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -772,7 +892,7 @@ func (m *StatsReq) Reset()         { *m = StatsReq{} }
 func (m *StatsReq) String() string { return proto.CompactTextString(m) }
 func (*StatsReq) ProtoMessage()    {}
 func (*StatsReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_piecestore_f59ad3d0b0de7e9d, []int{10}
+	return fileDescriptor_piecestore_f59ad3d0b0de7e9d, []int{11}
 }
 func (m *StatsReq) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StatsReq.Unmarshal(m, b)
@@ -806,7 +926,7 @@ func (m *StatSummary) Reset()         { *m = StatSummary{} }
 func (m *StatSummary) String() string { return proto.CompactTextString(m) }
 func (*StatSummary) ProtoMessage()    {}
 func (*StatSummary) Descriptor() ([]byte, []int) {
-	return fileDescriptor_piecestore_f59ad3d0b0de7e9d, []int{11}
+	return fileDescriptor_piecestore_f59ad3d0b0de7e9d, []int{12}
 }
 func (m *StatSummary) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StatSummary.Unmarshal(m, b)
@@ -867,7 +987,7 @@ func (m *SignedMessage) Reset()         { *m = SignedMessage{} }
 func (m *SignedMessage) String() string { return proto.CompactTextString(m) }
 func (*SignedMessage) ProtoMessage()    {}
 func (*SignedMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_piecestore_f59ad3d0b0de7e9d, []int{12}
+	return fileDescriptor_piecestore_f59ad3d0b0de7e9d, []int{13}
 }
 func (m *SignedMessage) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SignedMessage.Unmarshal(m, b)
@@ -922,6 +1042,8 @@ func init() {
 	proto.RegisterType((*PieceRetrievalStream)(nil), "piecestoreroutes.PieceRetrievalStream")
 	proto.RegisterType((*PieceDelete)(nil), "piecestoreroutes.PieceDelete")
 	proto.RegisterType((*PieceDeleteSummary)(nil), "piecestoreroutes.PieceDeleteSummary")
+	proto.RegisterType((*PieceHash)(nil), "piecestoreroutes.PieceHash")
+	proto.RegisterType((*PieceHash_Data)(nil), "piecestoreroutes.PieceHash.Data")
 	proto.RegisterType((*PieceStoreSummary)(nil), "piecestoreroutes.PieceStoreSummary")
 	proto.RegisterType((*StatsReq)(nil), "piecestoreroutes.StatsReq")
 	proto.RegisterType((*StatSummary)(nil), "piecestoreroutes.StatSummary")
@@ -1203,59 +1325,64 @@ var _PieceStoreRoutes_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("piecestore.proto", fileDescriptor_piecestore_f59ad3d0b0de7e9d) }
 
 var fileDescriptor_piecestore_f59ad3d0b0de7e9d = []byte{
-	// 862 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x56, 0x51, 0x6e, 0xdb, 0x46,
-	0x10, 0x35, 0x29, 0x59, 0x32, 0x47, 0x96, 0xa3, 0x6c, 0x82, 0x82, 0x66, 0x9d, 0x56, 0x65, 0x02,
-	0x43, 0x48, 0x01, 0xa1, 0x75, 0x4f, 0x90, 0x40, 0x41, 0x2a, 0x14, 0x75, 0x0d, 0x32, 0xfe, 0x09,
-	0x50, 0xb0, 0x2b, 0xee, 0xc4, 0x59, 0x94, 0x22, 0x59, 0x72, 0xe9, 0xca, 0xfe, 0xeb, 0x39, 0x72,
-	0x82, 0x1e, 0xa5, 0xa7, 0xe8, 0x01, 0xda, 0x43, 0x14, 0xdc, 0x5d, 0x91, 0x92, 0x25, 0xda, 0x45,
-	0xe0, 0xfc, 0x71, 0x67, 0x76, 0xde, 0xbc, 0x79, 0x7a, 0xbb, 0x2b, 0x18, 0xa4, 0x1c, 0x43, 0xcc,
-	0x45, 0x92, 0xe1, 0x38, 0xcd, 0x12, 0x91, 0x90, 0x95, 0x48, 0x96, 0x14, 0x02, 0x73, 0xf7, 0x5f,
-	0x13, 0xec, 0x33, 0x7a, 0x85, 0xd9, 0x4b, 0x1a, 0xb3, 0xdf, 0x39, 0x13, 0xef, 0x5f, 0x44, 0x51,
-	0x12, 0x52, 0xc1, 0x93, 0x98, 0x1c, 0x81, 0x95, 0xf3, 0x8b, 0x98, 0x8a, 0x22, 0x43, 0xdb, 0x18,
-	0x1a, 0xa3, 0x7d, 0xaf, 0x0e, 0x10, 0x02, 0x6d, 0x46, 0x05, 0xb5, 0x4d, 0x99, 0x90, 0xdf, 0xce,
-	0x1f, 0x26, 0xb4, 0x27, 0x54, 0x50, 0xf2, 0x15, 0xec, 0xe7, 0x54, 0x60, 0x14, 0x71, 0x81, 0x01,
-	0x67, 0xba, 0xba, 0x57, 0xc5, 0xa6, 0x8c, 0x7c, 0x0e, 0x56, 0x91, 0x46, 0x3c, 0xfe, 0xb5, 0xcc,
-	0x2b, 0x90, 0x3d, 0x15, 0x98, 0x32, 0x72, 0x08, 0x7b, 0x73, 0xba, 0x08, 0x72, 0x7e, 0x8d, 0x76,
-	0x6b, 0x68, 0x8c, 0x5a, 0x5e, 0x77, 0x4e, 0x17, 0x3e, 0xbf, 0x46, 0x32, 0x86, 0x47, 0xb8, 0x48,
-	0x79, 0x26, 0x39, 0x06, 0x45, 0xcc, 0x17, 0x41, 0x8e, 0xa1, 0xdd, 0x96, 0xbb, 0x1e, 0xd6, 0xa9,
-	0xf3, 0x98, 0x2f, 0x7c, 0x0c, 0xc9, 0x53, 0xe8, 0xe7, 0x98, 0x71, 0x1a, 0x05, 0x71, 0x31, 0x9f,
-	0x61, 0x66, 0xef, 0x0e, 0x8d, 0x91, 0xe5, 0xed, 0xab, 0xe0, 0xa9, 0x8c, 0x91, 0x29, 0x74, 0x68,
-	0x58, 0x56, 0xd9, 0x9d, 0xa1, 0x31, 0x3a, 0x38, 0xf9, 0x76, 0x7c, 0x53, 0xaa, 0x71, 0x93, 0x4c,
-	0xe3, 0x17, 0xb2, 0xd0, 0xd3, 0x00, 0xae, 0x03, 0x1d, 0x15, 0x21, 0x5d, 0x68, 0x9d, 0x9d, 0xbf,
-	0x19, 0xec, 0x94, 0x1f, 0xaf, 0x5f, 0xbd, 0x19, 0x18, 0xee, 0x3f, 0x06, 0x1c, 0x7a, 0x18, 0x8b,
-	0xfb, 0xd2, 0xfb, 0x83, 0xa1, 0xf5, 0x3e, 0x87, 0x41, 0x5a, 0xf2, 0x0b, 0x68, 0x05, 0x27, 0x11,
-	0x7a, 0x27, 0xcf, 0xff, 0xff, 0x24, 0xde, 0x03, 0x89, 0xb1, 0xc2, 0xe8, 0x31, 0xec, 0x8a, 0x44,
-	0xd0, 0x48, 0x36, 0x6d, 0x79, 0x6a, 0x41, 0x8e, 0xe1, 0x41, 0x09, 0x47, 0x2f, 0x30, 0x88, 0x13,
-	0x26, 0x7f, 0xdf, 0x96, 0x24, 0xd5, 0xd7, 0xe1, 0xd3, 0x84, 0xe1, 0x94, 0xb9, 0x7f, 0x9b, 0x00,
-	0x67, 0x65, 0x73, 0xbf, 0x6c, 0x4e, 0x7e, 0x86, 0x47, 0xb3, 0x65, 0xd3, 0x0d, 0x9a, 0x5f, 0x6f,
-	0xd2, 0x6c, 0x14, 0xca, 0xdb, 0x86, 0x43, 0x26, 0x60, 0x49, 0x88, 0x4a, 0xa4, 0xde, 0xc9, 0xf1,
-	0x96, 0xd9, 0x2b, 0x3e, 0xea, 0xb3, 0x54, 0xcf, 0xab, 0x0b, 0xc9, 0x2b, 0xe8, 0xd3, 0x42, 0xbc,
-	0x4f, 0x32, 0x7e, 0xad, 0xe8, 0xb5, 0x24, 0xd2, 0x97, 0x9b, 0x48, 0x3e, 0xbf, 0x88, 0x91, 0xfd,
-	0x88, 0x79, 0x4e, 0x2f, 0xd0, 0x5b, 0xaf, 0x72, 0x10, 0xac, 0x0a, 0x9e, 0x1c, 0x80, 0xa9, 0x8f,
-	0x80, 0xe5, 0x99, 0x9c, 0x35, 0x39, 0xd8, 0x6c, 0x72, 0xb0, 0x0d, 0xdd, 0x30, 0x89, 0x05, 0xc6,
-	0x42, 0xeb, 0xbc, 0x5c, 0xba, 0xbf, 0x40, 0x57, 0xb6, 0x99, 0xb2, 0x8d, 0x26, 0x1b, 0x83, 0x98,
-	0x1f, 0x33, 0x88, 0x3b, 0x83, 0x7d, 0x25, 0x59, 0x31, 0x9f, 0xd3, 0xec, 0x6a, 0xa3, 0x0d, 0x81,
-	0xb6, 0x3c, 0xa4, 0x8a, 0xbc, 0xfc, 0x6e, 0x9a, 0xaf, 0xd5, 0x30, 0x9f, 0xfb, 0x97, 0x09, 0x07,
-	0xb2, 0x89, 0x87, 0x22, 0xe3, 0x78, 0x49, 0xa3, 0x4f, 0xed, 0x95, 0xef, 0xb5, 0x57, 0x26, 0xb5,
-	0x57, 0x9e, 0x37, 0x78, 0xa5, 0xe2, 0xb4, 0xe1, 0x97, 0xc9, 0x3d, 0xfa, 0xe5, 0xf5, 0x6d, 0x7e,
-	0xd9, 0xa6, 0xf1, 0x67, 0xd0, 0x49, 0xde, 0xbd, 0xcb, 0x51, 0x68, 0x59, 0xf5, 0xca, 0x9d, 0xc0,
-	0xe3, 0x75, 0xda, 0xbe, 0xc8, 0x90, 0xce, 0x2b, 0x0c, 0x63, 0x05, 0x63, 0xc5, 0x57, 0xe6, 0xba,
-	0xaf, 0x18, 0xf4, 0x14, 0x1d, 0x8c, 0x50, 0xe0, 0xdd, 0xde, 0xfa, 0xa8, 0xa1, 0xdd, 0x31, 0x90,
-	0x95, 0x2e, 0x4b, 0x87, 0xd9, 0xd0, 0x9d, 0xab, 0xfd, 0xba, 0xe3, 0x72, 0xe9, 0xfa, 0xf0, 0xb0,
-	0x3e, 0xbe, 0x77, 0x6e, 0x27, 0xcf, 0xa0, 0x2f, 0xef, 0x2b, 0x0f, 0x43, 0xe4, 0x97, 0xc8, 0xb4,
-	0x7e, 0xeb, 0x41, 0x17, 0x60, 0xcf, 0x17, 0x54, 0xe4, 0x1e, 0xfe, 0xe6, 0xfe, 0x69, 0x40, 0xaf,
-	0x5c, 0x2c, 0xb1, 0x8f, 0xc0, 0x2a, 0x72, 0x64, 0x7e, 0x4a, 0xc3, 0xa5, 0x72, 0x75, 0x80, 0x1c,
-	0xc3, 0x01, 0xbd, 0xa4, 0x3c, 0xa2, 0xb3, 0x08, 0xd5, 0x16, 0xd5, 0xe0, 0x46, 0xb4, 0xe4, 0x51,
-	0x16, 0x55, 0xe6, 0xd4, 0xbf, 0xd8, 0x7a, 0x90, 0x8c, 0x81, 0x54, 0x75, 0xf5, 0x56, 0xf5, 0xaa,
-	0x6d, 0xc9, 0xb8, 0x01, 0xf4, 0xd7, 0xc4, 0xad, 0xde, 0x07, 0xa3, 0x7e, 0x1f, 0xd6, 0x5f, 0x14,
-	0xf3, 0xe6, 0x8b, 0x72, 0x04, 0x56, 0x5a, 0xcc, 0x22, 0x1e, 0xfe, 0x80, 0x57, 0xfa, 0x66, 0xa9,
-	0x03, 0x27, 0x1f, 0x5a, 0x30, 0xa8, 0xe5, 0xf6, 0xe4, 0xef, 0x49, 0x26, 0xb0, 0x2b, 0x63, 0xe4,
-	0xb0, 0xe1, 0xb8, 0x4c, 0x99, 0xf3, 0x45, 0xd3, 0xad, 0xab, 0x54, 0x75, 0x77, 0xc8, 0x5b, 0xd8,
-	0xd3, 0xfe, 0x44, 0x32, 0xbc, 0xeb, 0xdc, 0x39, 0xc7, 0x77, 0xed, 0x50, 0x16, 0x77, 0x77, 0x46,
-	0xc6, 0x37, 0x06, 0x39, 0x85, 0x5d, 0xf5, 0xdc, 0x1c, 0xdd, 0x76, 0xf9, 0x3b, 0x4f, 0x6f, 0xcb,
-	0x56, 0x4c, 0x47, 0x06, 0xf9, 0x09, 0x3a, 0xfa, 0x14, 0x3c, 0x69, 0x28, 0x51, 0x69, 0xe7, 0xd9,
-	0xad, 0xe9, 0x7a, 0xf8, 0x49, 0x49, 0x90, 0x8a, 0x9c, 0x38, 0x5b, 0x8e, 0x8b, 0x76, 0xa2, 0xf3,
-	0x64, 0x7b, 0xae, 0x42, 0x79, 0xd9, 0x7e, 0x6b, 0xa6, 0xb3, 0x59, 0x47, 0xfe, 0xaf, 0xfb, 0xee,
-	0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x47, 0x43, 0x01, 0x53, 0xeb, 0x09, 0x00, 0x00,
+	// 944 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x56, 0xcd, 0x6e, 0xdb, 0x46,
+	0x10, 0x36, 0x49, 0x59, 0x3f, 0xa3, 0x9f, 0x28, 0x9b, 0xa0, 0xa0, 0x19, 0xa7, 0x55, 0x99, 0xd4,
+	0x10, 0x52, 0x40, 0x68, 0xdd, 0x53, 0x8f, 0x49, 0x15, 0x24, 0x42, 0x51, 0xd7, 0xa0, 0xe2, 0x4b,
+	0x80, 0x82, 0x5d, 0x91, 0x13, 0x6b, 0x51, 0x8a, 0x64, 0xc9, 0xa5, 0x2b, 0xfb, 0xd6, 0x5b, 0x4f,
+	0xbd, 0xf4, 0x98, 0x27, 0xe8, 0xa3, 0xf4, 0x29, 0xfa, 0x00, 0x7d, 0x89, 0x82, 0xbb, 0x2b, 0x52,
+	0xb2, 0x44, 0xbb, 0x30, 0xd2, 0xdb, 0xee, 0x37, 0xbb, 0x33, 0xdf, 0x7c, 0xfb, 0xed, 0x92, 0xd0,
+	0x8f, 0x19, 0x7a, 0x98, 0xf2, 0x28, 0xc1, 0x51, 0x9c, 0x44, 0x3c, 0x22, 0x6b, 0x48, 0x12, 0x65,
+	0x1c, 0x53, 0xfb, 0x0f, 0x03, 0xcc, 0x53, 0x7a, 0x89, 0xc9, 0x0b, 0x1a, 0xfa, 0xbf, 0x30, 0x9f,
+	0xcf, 0x9f, 0x07, 0x41, 0xe4, 0x51, 0xce, 0xa2, 0x90, 0x1c, 0x42, 0x2b, 0x65, 0xe7, 0x21, 0xe5,
+	0x59, 0x82, 0xa6, 0x36, 0xd0, 0x86, 0x1d, 0xa7, 0x04, 0x08, 0x81, 0x9a, 0x4f, 0x39, 0x35, 0x75,
+	0x11, 0x10, 0x63, 0xf2, 0x19, 0xf4, 0x98, 0x8f, 0x21, 0x67, 0xfc, 0xd2, 0xf5, 0xe6, 0x94, 0x85,
+	0xa6, 0x31, 0x30, 0x86, 0x1d, 0xa7, 0xbb, 0x42, 0xbf, 0xc9, 0x41, 0xeb, 0x57, 0x1d, 0x6a, 0xe3,
+	0x7c, 0xfd, 0xa7, 0xd0, 0x49, 0x29, 0xc7, 0x20, 0x60, 0x1c, 0x5d, 0xe6, 0xab, 0x22, 0xed, 0x02,
+	0x9b, 0xf8, 0xe4, 0x11, 0xb4, 0xb2, 0x38, 0x60, 0xe1, 0x4f, 0x79, 0x5c, 0xd6, 0x6a, 0x4a, 0x60,
+	0xe2, 0x93, 0x03, 0x68, 0x2e, 0xe8, 0xd2, 0x4d, 0xd9, 0x15, 0x9a, 0xc6, 0x40, 0x1b, 0x1a, 0x4e,
+	0x63, 0x41, 0x97, 0x53, 0x76, 0x85, 0x64, 0x04, 0x0f, 0x70, 0x19, 0xb3, 0x44, 0xb4, 0xe2, 0x66,
+	0x21, 0x5b, 0xba, 0x29, 0x7a, 0x66, 0x4d, 0xac, 0xba, 0x5f, 0x86, 0xce, 0x42, 0xb6, 0x9c, 0xa2,
+	0x47, 0x9e, 0x40, 0x37, 0xc5, 0x84, 0xd1, 0xc0, 0x0d, 0xb3, 0xc5, 0x0c, 0x13, 0x73, 0x7f, 0xa0,
+	0x0d, 0x5b, 0x4e, 0x47, 0x82, 0x27, 0x02, 0x23, 0x13, 0xa8, 0x53, 0x2f, 0xdf, 0x65, 0xd6, 0x07,
+	0xda, 0xb0, 0x77, 0xfc, 0xe5, 0xe8, 0xba, 0xa2, 0xa3, 0x2a, 0x35, 0x47, 0xcf, 0xc5, 0x46, 0x47,
+	0x25, 0xb0, 0x2d, 0xa8, 0x4b, 0x84, 0x34, 0xc0, 0x38, 0x3d, 0x7b, 0xd3, 0xdf, 0xcb, 0x07, 0xaf,
+	0x5e, 0xbe, 0xe9, 0x6b, 0xf6, 0x3f, 0x1a, 0x1c, 0x38, 0x18, 0xf2, 0x0f, 0x74, 0x2c, 0xd6, 0x7b,
+	0x4d, 0xe9, 0x7d, 0x06, 0xfd, 0x38, 0xe7, 0xe7, 0xd2, 0x22, 0x9d, 0xc8, 0xd0, 0x3e, 0x7e, 0xf6,
+	0xdf, 0x3b, 0x71, 0xee, 0x89, 0x1c, 0x6b, 0x8c, 0x1e, 0xc2, 0x3e, 0x8f, 0x38, 0x0d, 0x44, 0x51,
+	0xc3, 0x91, 0x13, 0x72, 0x04, 0xf7, 0xf2, 0x74, 0xf4, 0x1c, 0xdd, 0x30, 0xf2, 0xc5, 0xf9, 0x1a,
+	0x82, 0x54, 0x57, 0xc1, 0x27, 0x91, 0x8f, 0x13, 0xdf, 0xfe, 0x5b, 0x07, 0x38, 0xcd, 0x8b, 0x4f,
+	0xf3, 0xe2, 0xe4, 0x07, 0x78, 0x30, 0x5b, 0x15, 0xdd, 0xa2, 0xf9, 0xf9, 0x36, 0xcd, 0x4a, 0xa1,
+	0x9c, 0x5d, 0x79, 0xc8, 0x18, 0x5a, 0x22, 0x45, 0x21, 0x52, 0xfb, 0xf8, 0x68, 0x47, 0xef, 0x05,
+	0x1f, 0x39, 0xcc, 0xd5, 0x73, 0xca, 0x8d, 0xe4, 0x25, 0x74, 0x69, 0xc6, 0xe7, 0x51, 0xc2, 0xae,
+	0x24, 0x3d, 0x43, 0x64, 0xfa, 0x64, 0x3b, 0xd3, 0x94, 0x9d, 0x87, 0xe8, 0x7f, 0x87, 0x69, 0x4a,
+	0xcf, 0xd1, 0xd9, 0xdc, 0x65, 0x21, 0xb4, 0x8a, 0xf4, 0xa4, 0x07, 0xba, 0xba, 0x02, 0x2d, 0x47,
+	0x67, 0x7e, 0x95, 0x83, 0xf5, 0x2a, 0x07, 0x9b, 0xd0, 0xf0, 0xa2, 0x90, 0x63, 0xc8, 0x95, 0xce,
+	0xab, 0xa9, 0xfd, 0x23, 0x34, 0x44, 0x99, 0x89, 0xbf, 0x55, 0x64, 0xab, 0x11, 0xfd, 0x2e, 0x8d,
+	0xd8, 0x33, 0xe8, 0x48, 0xc9, 0xb2, 0xc5, 0x82, 0x26, 0x97, 0x5b, 0x65, 0x08, 0xd4, 0xc4, 0x25,
+	0x95, 0xe4, 0xc5, 0xb8, 0xaa, 0x3f, 0xa3, 0xa2, 0x3f, 0xfb, 0x2f, 0x1d, 0x7a, 0xa2, 0x88, 0x83,
+	0x3c, 0x61, 0x78, 0x41, 0x83, 0xff, 0xdb, 0x2b, 0xaf, 0x95, 0x57, 0xc6, 0xa5, 0x57, 0x9e, 0x55,
+	0x78, 0xa5, 0xe0, 0xb4, 0xe5, 0x97, 0xf1, 0x07, 0xf4, 0xcb, 0xab, 0x9b, 0xfc, 0xb2, 0x4b, 0xe3,
+	0x8f, 0xa0, 0x1e, 0xbd, 0x7b, 0x97, 0x22, 0x57, 0xb2, 0xaa, 0x99, 0x3d, 0x86, 0x87, 0x9b, 0xb4,
+	0xa7, 0x3c, 0x41, 0xba, 0x28, 0x72, 0x68, 0x6b, 0x39, 0xd6, 0x7c, 0xa5, 0x6f, 0xfa, 0xca, 0x87,
+	0xb6, 0xa4, 0x83, 0x01, 0x72, 0xbc, 0xdd, 0x5b, 0x77, 0x6a, 0xda, 0x1e, 0x01, 0x59, 0xab, 0xb2,
+	0x72, 0x98, 0x09, 0x8d, 0x85, 0x5c, 0xaf, 0x2a, 0xae, 0xa6, 0xf6, 0x6f, 0x9a, 0x52, 0xe9, 0x35,
+	0x4d, 0xe7, 0x77, 0x78, 0x2d, 0x27, 0xea, 0xb1, 0x3c, 0x80, 0xa6, 0x20, 0xea, 0x16, 0x4d, 0x35,
+	0x62, 0x75, 0x8b, 0x08, 0xd4, 0xe6, 0x34, 0x9d, 0xaf, 0xb6, 0xe5, 0xe3, 0x42, 0x3a, 0xa3, 0x94,
+	0xce, 0xfe, 0x5d, 0x83, 0xfb, 0xe5, 0x53, 0x72, 0x2b, 0x75, 0xf2, 0x14, 0xba, 0xe2, 0xed, 0x74,
+	0xd0, 0x43, 0x76, 0x81, 0xbe, 0x3a, 0xcb, 0x4d, 0x90, 0x7c, 0xad, 0x6c, 0x99, 0xf7, 0xa7, 0x34,
+	0x7d, 0x54, 0x61, 0xcb, 0x7c, 0x89, 0x53, 0xae, 0xb6, 0x01, 0x9a, 0x53, 0x4e, 0x79, 0xea, 0xe0,
+	0xcf, 0xf6, 0x9f, 0x1a, 0xb4, 0xf3, 0xc9, 0x8a, 0xd6, 0x21, 0xb4, 0xb2, 0x14, 0xfd, 0x69, 0x4c,
+	0xbd, 0x95, 0x01, 0x4a, 0x80, 0x1c, 0x41, 0x8f, 0x5e, 0x50, 0x16, 0xd0, 0x59, 0x80, 0x72, 0x89,
+	0xe4, 0x76, 0x0d, 0xcd, 0x5b, 0xc8, 0x37, 0x15, 0x77, 0x4c, 0xe9, 0xb1, 0x09, 0x92, 0x11, 0x90,
+	0x62, 0x5f, 0xb9, 0x54, 0x7e, 0x9c, 0x77, 0x44, 0x6c, 0x17, 0xba, 0x1b, 0x1e, 0x29, 0x0e, 0x4e,
+	0x5b, 0xfb, 0xfb, 0xd8, 0x38, 0x6a, 0xfd, 0xfa, 0x51, 0x1f, 0x42, 0x2b, 0xce, 0x66, 0x01, 0xf3,
+	0xbe, 0xc5, 0x4b, 0xf5, 0x40, 0x96, 0xc0, 0xf1, 0x7b, 0x03, 0xfa, 0xe5, 0x49, 0x39, 0x42, 0x42,
+	0x32, 0x86, 0x7d, 0x81, 0x91, 0x83, 0x0a, 0x79, 0x27, 0xbe, 0xf5, 0x71, 0x45, 0x48, 0xa9, 0x6a,
+	0xef, 0x91, 0xb7, 0xd0, 0x54, 0xd7, 0x0c, 0xc9, 0xe0, 0xb6, 0xe7, 0xc3, 0x3a, 0xba, 0x6d, 0x85,
+	0xbc, 0xa9, 0xf6, 0xde, 0x50, 0xfb, 0x42, 0x23, 0x27, 0xb0, 0x2f, 0xbf, 0x9a, 0x87, 0x37, 0x7d,
+	0xc3, 0xac, 0x27, 0x37, 0x45, 0x0b, 0xa6, 0x43, 0x8d, 0x7c, 0x0f, 0x75, 0x75, 0x99, 0x1f, 0x57,
+	0x6c, 0x91, 0x61, 0xeb, 0xe9, 0x8d, 0xe1, 0xb2, 0xf9, 0x71, 0x4e, 0x90, 0xf2, 0x94, 0x58, 0x3b,
+	0x6e, 0xbd, 0x72, 0xa2, 0xf5, 0x78, 0x77, 0xac, 0xc8, 0xf2, 0xa2, 0xf6, 0x56, 0x8f, 0x67, 0xb3,
+	0xba, 0xf8, 0x8b, 0xfd, 0xea, 0xdf, 0x01, 0x00, 0xdc, 0x21, 0x97, 0xd0, 0xd9, 0x0a, 0x00, 0x00,
 }
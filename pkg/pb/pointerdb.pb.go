@@ -27,14 +27,17 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 type RedundancyScheme_SchemeType int32
 
 const (
-	RedundancyScheme_RS RedundancyScheme_SchemeType = 0
+	RedundancyScheme_RS      RedundancyScheme_SchemeType = 0
+	RedundancyScheme_RS_SIMD RedundancyScheme_SchemeType = 1
 )
 
 var RedundancyScheme_SchemeType_name = map[int32]string{
 	0: "RS",
+	1: "RS_SIMD",
 }
 var RedundancyScheme_SchemeType_value = map[string]int32{
-	"RS": 0,
+	"RS":      0,
+	"RS_SIMD": 1,
 }
 
 func (x RedundancyScheme_SchemeType) String() string {
@@ -147,11 +150,16 @@ func (m *RedundancyScheme) GetErasureShareSize() int32 {
 }
 
 type RemotePiece struct {
-	PieceNum             int32    `protobuf:"varint,1,opt,name=piece_num,json=pieceNum,proto3" json:"piece_num,omitempty"`
-	NodeId               string   `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	PieceNum int32  `protobuf:"varint,1,opt,name=piece_num,json=pieceNum,proto3" json:"piece_num,omitempty"`
+	NodeId   string `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	// hash of the hashes of the erasure shares of the piece, which are stored
+	// after the erasure shares
+	Hash []byte `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	// receipt signed by the node for the piece when it was uploaded
+	Receipt              *PieceHash `protobuf:"bytes,4,opt,name=receipt,proto3" json:"receipt,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
 }
 
 func (m *RemotePiece) Reset()         { *m = RemotePiece{} }
@@ -192,14 +200,31 @@ func (m *RemotePiece) GetNodeId() string {
 	return ""
 }
 
+func (m *RemotePiece) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *RemotePiece) GetReceipt() *PieceHash {
+	if m != nil {
+		return m.Receipt
+	}
+	return nil
+}
+
 type RemoteSegment struct {
-	Redundancy           *RedundancyScheme `protobuf:"bytes,1,opt,name=redundancy,proto3" json:"redundancy,omitempty"`
-	PieceId              string            `protobuf:"bytes,2,opt,name=piece_id,json=pieceId,proto3" json:"piece_id,omitempty"`
-	RemotePieces         []*RemotePiece    `protobuf:"bytes,3,rep,name=remote_pieces,json=remotePieces,proto3" json:"remote_pieces,omitempty"`
-	MerkleRoot           []byte            `protobuf:"bytes,4,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	Redundancy   *RedundancyScheme `protobuf:"bytes,1,opt,name=redundancy,proto3" json:"redundancy,omitempty"`
+	PieceId      string            `protobuf:"bytes,2,opt,name=piece_id,json=pieceId,proto3" json:"piece_id,omitempty"`
+	RemotePieces []*RemotePiece    `protobuf:"bytes,3,rep,name=remote_pieces,json=remotePieces,proto3" json:"remote_pieces,omitempty"`
+	MerkleRoot   []byte            `protobuf:"bytes,4,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
+	// paths of the other segments sharing these pieces, which are copies of
+	// this segment; the pieces are deleted with the last of them
+	Copies               []string `protobuf:"bytes,5,rep,name=copies,proto3" json:"copies,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *RemoteSegment) Reset()         { *m = RemoteSegment{} }
@@ -254,6 +279,13 @@ func (m *RemoteSegment) GetMerkleRoot() []byte {
 	return nil
 }
 
+func (m *RemoteSegment) GetCopies() []string {
+	if m != nil {
+		return m.Copies
+	}
+	return nil
+}
+
 type Pointer struct {
 	Type                 Pointer_DataType     `protobuf:"varint,1,opt,name=type,proto3,enum=pointerdb.Pointer_DataType" json:"type,omitempty"`
 	InlineSegment        []byte               `protobuf:"bytes,3,opt,name=inline_segment,json=inlineSegment,proto3" json:"inline_segment,omitempty"`
@@ -732,6 +764,9 @@ func (m *DeleteRequest) GetPath() string {
 
 // DeleteResponse is a response message for the Delete rpc call
 type DeleteResponse struct {
+	// whether the pieces of the deleted pointer are still shared by copies of
+	// it, so they must not be deleted
+	Shared               bool     `protobuf:"varint,1,opt,name=shared,proto3" json:"shared,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -761,6 +796,13 @@ func (m *DeleteResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_DeleteResponse proto.InternalMessageInfo
 
+func (m *DeleteResponse) GetShared() bool {
+	if m != nil {
+		return m.Shared
+	}
+	return false
+}
+
 // IterateRequest is a request message for the Iterate rpc call
 type IterateRequest struct {
 	Prefix               string   `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
@@ -824,6 +866,93 @@ func (m *IterateRequest) GetReverse() bool {
 	return false
 }
 
+// CopyRequest is a request message for the Copy rpc call
+type CopyRequest struct {
+	SrcPath string `protobuf:"bytes,1,opt,name=src_path,json=srcPath,proto3" json:"src_path,omitempty"`
+	DstPath string `protobuf:"bytes,2,opt,name=dst_path,json=dstPath,proto3" json:"dst_path,omitempty"`
+	// metadata of the copy, replacing the metadata of the source pointer
+	Metadata             []byte   `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CopyRequest) Reset()         { *m = CopyRequest{} }
+func (m *CopyRequest) String() string { return proto.CompactTextString(m) }
+func (*CopyRequest) ProtoMessage()    {}
+func (*CopyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_pointerdb_c06a8fdf5756a947, []int{13}
+}
+func (m *CopyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CopyRequest.Unmarshal(m, b)
+}
+func (m *CopyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CopyRequest.Marshal(b, m, deterministic)
+}
+func (dst *CopyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CopyRequest.Merge(dst, src)
+}
+func (m *CopyRequest) XXX_Size() int {
+	return xxx_messageInfo_CopyRequest.Size(m)
+}
+func (m *CopyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CopyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CopyRequest proto.InternalMessageInfo
+
+func (m *CopyRequest) GetSrcPath() string {
+	if m != nil {
+		return m.SrcPath
+	}
+	return ""
+}
+
+func (m *CopyRequest) GetDstPath() string {
+	if m != nil {
+		return m.DstPath
+	}
+	return ""
+}
+
+func (m *CopyRequest) GetMetadata() []byte {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+// CopyResponse is a response message for the Copy rpc call
+type CopyResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CopyResponse) Reset()         { *m = CopyResponse{} }
+func (m *CopyResponse) String() string { return proto.CompactTextString(m) }
+func (*CopyResponse) ProtoMessage()    {}
+func (*CopyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_pointerdb_c06a8fdf5756a947, []int{14}
+}
+func (m *CopyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CopyResponse.Unmarshal(m, b)
+}
+func (m *CopyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CopyResponse.Marshal(b, m, deterministic)
+}
+func (dst *CopyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CopyResponse.Merge(dst, src)
+}
+func (m *CopyResponse) XXX_Size() int {
+	return xxx_messageInfo_CopyResponse.Size(m)
+}
+func (m *CopyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CopyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CopyResponse proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterType((*RedundancyScheme)(nil), "pointerdb.RedundancyScheme")
 	proto.RegisterType((*RemotePiece)(nil), "pointerdb.RemotePiece")
@@ -839,6 +968,8 @@ func init() {
 	proto.RegisterType((*DeleteRequest)(nil), "pointerdb.DeleteRequest")
 	proto.RegisterType((*DeleteResponse)(nil), "pointerdb.DeleteResponse")
 	proto.RegisterType((*IterateRequest)(nil), "pointerdb.IterateRequest")
+	proto.RegisterType((*CopyRequest)(nil), "pointerdb.CopyRequest")
+	proto.RegisterType((*CopyResponse)(nil), "pointerdb.CopyResponse")
 	proto.RegisterEnum("pointerdb.RedundancyScheme_SchemeType", RedundancyScheme_SchemeType_name, RedundancyScheme_SchemeType_value)
 	proto.RegisterEnum("pointerdb.Pointer_DataType", Pointer_DataType_name, Pointer_DataType_value)
 }
@@ -863,6 +994,8 @@ type PointerDBClient interface {
 	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
 	// Delete formats and hands off a file path to delete from boltdb
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// Copy copies the pointer of a path to another path, sharing its pieces
+	Copy(ctx context.Context, in *CopyRequest, opts ...grpc.CallOption) (*CopyResponse, error)
 }
 
 type pointerDBClient struct {
@@ -909,6 +1042,15 @@ func (c *pointerDBClient) Delete(ctx context.Context, in *DeleteRequest, opts ..
 	return out, nil
 }
 
+func (c *pointerDBClient) Copy(ctx context.Context, in *CopyRequest, opts ...grpc.CallOption) (*CopyResponse, error) {
+	out := new(CopyResponse)
+	err := c.cc.Invoke(ctx, "/pointerdb.PointerDB/Copy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PointerDBServer is the server API for PointerDB service.
 type PointerDBServer interface {
 	// Put formats and hands off a file path to be saved to boltdb
@@ -919,6 +1061,8 @@ type PointerDBServer interface {
 	List(context.Context, *ListRequest) (*ListResponse, error)
 	// Delete formats and hands off a file path to delete from boltdb
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// Copy copies the pointer of a path to another path, sharing its pieces
+	Copy(context.Context, *CopyRequest) (*CopyResponse, error)
 }
 
 func RegisterPointerDBServer(s *grpc.Server, srv PointerDBServer) {
@@ -997,6 +1141,24 @@ func _PointerDB_Delete_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PointerDB_Copy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CopyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PointerDBServer).Copy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pointerdb.PointerDB/Copy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PointerDBServer).Copy(ctx, req.(*CopyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _PointerDB_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pointerdb.PointerDB",
 	HandlerType: (*PointerDBServer)(nil),
@@ -1017,6 +1179,10 @@ var _PointerDB_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _PointerDB_Delete_Handler,
 		},
+		{
+			MethodName: "Copy",
+			Handler:    _PointerDB_Copy_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pointerdb.proto",
@@ -1025,66 +1191,74 @@ var _PointerDB_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("pointerdb.proto", fileDescriptor_pointerdb_c06a8fdf5756a947) }
 
 var fileDescriptor_pointerdb_c06a8fdf5756a947 = []byte{
-	// 976 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0xcf, 0x6e, 0xdb, 0xc6,
-	0x13, 0x8e, 0xfe, 0x4b, 0x43, 0xcb, 0xd6, 0x6f, 0xe1, 0x9f, 0xc3, 0x28, 0x29, 0x62, 0x30, 0x68,
-	0xe1, 0xb6, 0x01, 0x5d, 0xa8, 0x01, 0x0a, 0x34, 0x2d, 0x8a, 0x38, 0x76, 0x03, 0x01, 0xa9, 0x2b,
-	0xac, 0x7d, 0xea, 0x85, 0x58, 0x89, 0x23, 0x6b, 0x51, 0x92, 0x4b, 0xef, 0x2e, 0xdd, 0x38, 0x6f,
-	0xd2, 0x4b, 0x9f, 0xa3, 0x97, 0x1e, 0xfb, 0x38, 0x45, 0x5f, 0xa1, 0xd8, 0x5d, 0x52, 0xa2, 0xe3,
-	0xc6, 0x39, 0xf4, 0x22, 0x71, 0xbe, 0xf9, 0x66, 0x76, 0xe7, 0x9b, 0x99, 0x85, 0x9d, 0x5c, 0xf0,
-	0x4c, 0xa3, 0x8c, 0xe7, 0x61, 0x2e, 0x85, 0x16, 0x64, 0xb0, 0x06, 0xc6, 0x8f, 0x2f, 0x84, 0xb8,
-	0x48, 0xf0, 0xd0, 0x3a, 0xe6, 0xc5, 0xf2, 0x50, 0xf3, 0x14, 0x95, 0x66, 0x69, 0xee, 0xb8, 0xe3,
-	0xa1, 0xb8, 0x42, 0x99, 0xb0, 0xeb, 0xd2, 0x1c, 0xe5, 0x1c, 0x17, 0xa8, 0xb4, 0x90, 0xe8, 0x90,
-	0xe0, 0xd7, 0x26, 0x8c, 0x28, 0xc6, 0x45, 0x16, 0xb3, 0x6c, 0x71, 0x7d, 0xb6, 0x58, 0x61, 0x8a,
-	0xe4, 0x6b, 0x68, 0xeb, 0xeb, 0x1c, 0xfd, 0xc6, 0x7e, 0xe3, 0x60, 0x7b, 0xf2, 0x49, 0xb8, 0xb9,
-	0xc1, 0xbb, 0xd4, 0xd0, 0xfd, 0x9d, 0x5f, 0xe7, 0x48, 0x6d, 0x0c, 0xb9, 0x0f, 0xbd, 0x94, 0x67,
-	0x91, 0xc4, 0x4b, 0xbf, 0xb9, 0xdf, 0x38, 0xe8, 0xd0, 0x6e, 0xca, 0x33, 0x8a, 0x97, 0x64, 0x17,
-	0x3a, 0x5a, 0x68, 0x96, 0xf8, 0x2d, 0x0b, 0x3b, 0x83, 0x7c, 0x0a, 0x23, 0x89, 0x39, 0xe3, 0x32,
-	0xd2, 0x2b, 0x89, 0x6a, 0x25, 0x92, 0xd8, 0x6f, 0x5b, 0xc2, 0x8e, 0xc3, 0xcf, 0x2b, 0x98, 0x7c,
-	0x0e, 0xff, 0x53, 0xc5, 0x62, 0x81, 0x4a, 0xd5, 0xb8, 0x1d, 0xcb, 0x1d, 0x95, 0x8e, 0x0d, 0xf9,
-	0x29, 0x10, 0x94, 0x4c, 0x15, 0x12, 0x23, 0xb5, 0x62, 0xe6, 0x97, 0xbf, 0x45, 0xbf, 0xeb, 0xd8,
-	0xa5, 0xe7, 0xcc, 0x38, 0xce, 0xf8, 0x5b, 0x0c, 0x76, 0x01, 0x36, 0x85, 0x90, 0x2e, 0x34, 0xe9,
-	0xd9, 0xe8, 0x5e, 0xf0, 0x12, 0x3c, 0x8a, 0xa9, 0xd0, 0x38, 0x33, 0xaa, 0x91, 0x87, 0x30, 0xb0,
-	0xf2, 0x45, 0x59, 0x91, 0x5a, 0x69, 0x3a, 0xb4, 0x6f, 0x81, 0xd3, 0x22, 0x35, 0x65, 0x67, 0x22,
-	0xc6, 0x88, 0xc7, 0xb6, 0xec, 0x01, 0xed, 0x1a, 0x73, 0x1a, 0x07, 0x7f, 0x36, 0x60, 0xe8, 0xb2,
-	0x9c, 0xe1, 0x45, 0x8a, 0x99, 0x26, 0xcf, 0x01, 0xe4, 0x5a, 0x46, 0x9b, 0xc8, 0x9b, 0x3c, 0xbc,
-	0x43, 0x63, 0x5a, 0xa3, 0x93, 0x07, 0xe0, 0xce, 0xdc, 0x1c, 0xd4, 0xb3, 0xf6, 0x34, 0x26, 0xcf,
-	0x61, 0x28, 0xed, 0x41, 0x91, 0xeb, 0xb2, 0xdf, 0xda, 0x6f, 0x1d, 0x78, 0x93, 0xbd, 0x1b, 0xa9,
-	0xd7, 0xe5, 0xd0, 0x2d, 0xb9, 0x31, 0x14, 0x79, 0x0c, 0x5e, 0x8a, 0xf2, 0xe7, 0x04, 0x23, 0x29,
-	0x84, 0xb6, 0x2d, 0xd8, 0xa2, 0xe0, 0x20, 0x2a, 0x84, 0x0e, 0xfe, 0x6a, 0x42, 0x6f, 0xe6, 0x12,
-	0x91, 0xc3, 0x1b, 0xf3, 0x51, 0xbf, 0x7b, 0xc9, 0x08, 0x8f, 0x99, 0x66, 0xb5, 0xa1, 0xf8, 0x18,
-	0xb6, 0x79, 0x96, 0xf0, 0x0c, 0x23, 0xe5, 0x44, 0xb0, 0x43, 0xb0, 0x45, 0x87, 0x0e, 0xad, 0x94,
-	0xf9, 0x02, 0xba, 0xee, 0x52, 0xf6, 0x7c, 0x6f, 0xe2, 0xdf, 0xba, 0x7a, 0xc9, 0xa4, 0x25, 0x8f,
-	0x10, 0x68, 0xdb, 0xc6, 0x9a, 0x31, 0x68, 0x51, 0xfb, 0x4d, 0xbe, 0x83, 0xe1, 0x42, 0x22, 0xd3,
-	0x5c, 0x64, 0x51, 0xcc, 0xb4, 0xeb, 0xba, 0x37, 0x19, 0x87, 0x6e, 0x59, 0xc2, 0x6a, 0x59, 0xc2,
-	0xf3, 0x6a, 0x59, 0xe8, 0x56, 0x15, 0x70, 0xcc, 0x34, 0x92, 0x97, 0xb0, 0x83, 0x6f, 0x72, 0x2e,
-	0x6b, 0x29, 0x7a, 0x1f, 0x4c, 0xb1, 0xbd, 0x09, 0xb1, 0x49, 0xc6, 0xd0, 0x4f, 0x51, 0xb3, 0x98,
-	0x69, 0xe6, 0xf7, 0x6d, 0xb1, 0x6b, 0x3b, 0x08, 0xa0, 0x5f, 0x09, 0x44, 0x00, 0xba, 0xd3, 0xd3,
-	0xd7, 0xd3, 0xd3, 0x93, 0xd1, 0x3d, 0xf3, 0x4d, 0x4f, 0x7e, 0xf8, 0xf1, 0xfc, 0x64, 0xd4, 0x08,
-	0x4e, 0x01, 0x66, 0x85, 0xa6, 0x78, 0x59, 0xa0, 0xd2, 0xa6, 0xce, 0x9c, 0xe9, 0x95, 0x55, 0x7c,
-	0x40, 0xed, 0x37, 0x79, 0x0a, 0xbd, 0x52, 0x1e, 0x3b, 0x09, 0xde, 0x84, 0xdc, 0x6e, 0x04, 0xad,
-	0x28, 0xc1, 0x3e, 0xc0, 0x2b, 0xbc, 0x2b, 0x5f, 0xf0, 0x7b, 0x03, 0xbc, 0xd7, 0x5c, 0xad, 0x39,
-	0x7b, 0xd0, 0xcd, 0x25, 0x2e, 0xf9, 0x9b, 0x92, 0x55, 0x5a, 0x66, 0x54, 0x94, 0x66, 0x52, 0x47,
-	0x6c, 0x59, 0x9d, 0x3d, 0xa0, 0x60, 0xa1, 0x17, 0x06, 0x21, 0x1f, 0x01, 0x60, 0x16, 0x47, 0x73,
-	0x5c, 0x0a, 0x89, 0xb6, 0xd3, 0x03, 0x3a, 0xc0, 0x2c, 0x3e, 0xb2, 0x00, 0x79, 0x04, 0x03, 0x89,
-	0x8b, 0x42, 0x2a, 0x7e, 0xe5, 0x1a, 0xdd, 0xa7, 0x1b, 0xc0, 0x3c, 0x13, 0x09, 0x4f, 0xb9, 0x2e,
-	0x37, 0xdb, 0x19, 0x26, 0xa5, 0x51, 0x2f, 0x5a, 0x26, 0xec, 0x42, 0xd9, 0x86, 0xf6, 0xe8, 0xc0,
-	0x20, 0xdf, 0x1b, 0x20, 0x18, 0x82, 0x67, 0xc5, 0x52, 0xb9, 0xc8, 0x14, 0x06, 0xbf, 0x35, 0xc0,
-	0xb3, 0xc5, 0x3a, 0xbb, 0xae, 0x54, 0xe3, 0x83, 0x4a, 0x91, 0x27, 0xd0, 0x31, 0xbb, 0xab, 0xfc,
-	0xa6, 0xdd, 0x9f, 0x61, 0x58, 0xbd, 0xa1, 0xa7, 0x22, 0x46, 0xea, 0x7c, 0xe4, 0x1b, 0x68, 0xe5,
-	0x73, 0x66, 0x8b, 0xf3, 0x26, 0x9f, 0x85, 0x9b, 0x77, 0x55, 0x8a, 0x42, 0xa3, 0x0a, 0x67, 0xec,
-	0x1a, 0xe5, 0x11, 0xcb, 0xe2, 0x5f, 0x78, 0xac, 0x57, 0x2f, 0x92, 0x44, 0x2c, 0xec, 0x6c, 0x50,
-	0x13, 0x16, 0xfc, 0xd1, 0x80, 0x2d, 0x27, 0x75, 0x79, 0xc3, 0x09, 0x74, 0xb8, 0xc6, 0x54, 0xf9,
-	0x0d, 0x7b, 0xe6, 0xa3, 0xda, 0xfd, 0xea, 0xbc, 0x70, 0xaa, 0x31, 0xa5, 0x8e, 0x6a, 0x7a, 0x98,
-	0x1a, 0x81, 0x9b, 0x56, 0x42, 0xfb, 0x3d, 0x46, 0x68, 0x1b, 0xca, 0x7f, 0x9f, 0x17, 0xf3, 0xda,
-	0x71, 0x15, 0x95, 0x03, 0xd0, 0xb2, 0x47, 0xf4, 0xb9, 0x9a, 0x59, 0x3b, 0x78, 0x02, 0xc3, 0x63,
-	0x4c, 0x50, 0xe3, 0x5d, 0xf3, 0x34, 0x82, 0xed, 0x8a, 0x54, 0xf6, 0x45, 0xc2, 0xf6, 0x54, 0xa3,
-	0x64, 0x9b, 0xb8, 0xf7, 0xcd, 0xd8, 0x2e, 0x74, 0x96, 0x5c, 0x2a, 0x5d, 0x4e, 0x97, 0x33, 0x88,
-	0x0f, 0x3d, 0x37, 0x28, 0x58, 0xde, 0xa8, 0x32, 0x9d, 0xe7, 0x0a, 0x8d, 0xa7, 0x5d, 0x79, 0xac,
-	0x39, 0xf9, 0xbb, 0x01, 0x83, 0xb2, 0xb8, 0xe3, 0x23, 0xf2, 0x0c, 0x5a, 0xb3, 0x42, 0x93, 0xff,
-	0xd7, 0x2b, 0x5f, 0x6f, 0xd9, 0x78, 0xef, 0x5d, 0xb8, 0xec, 0xce, 0x33, 0x68, 0xbd, 0xc2, 0x9b,
-	0x51, 0x9b, 0x5d, 0xba, 0x11, 0x55, 0x9f, 0xba, 0xaf, 0xa0, 0x6d, 0x7a, 0x47, 0xf6, 0x6e, 0x35,
-	0xd3, 0xc5, 0xdd, 0x7f, 0x4f, 0x93, 0xc9, 0xb7, 0xd0, 0x75, 0xc2, 0x91, 0xfa, 0x03, 0x78, 0x43,
-	0xf0, 0xf1, 0x83, 0x7f, 0xf1, 0xb8, 0xf0, 0xa3, 0xf6, 0x4f, 0xcd, 0x7c, 0x3e, 0xef, 0xda, 0x37,
-	0xea, 0xcb, 0x7f, 0x02, 0x00, 0x00, 0xff, 0xff, 0x95, 0x96, 0x58, 0x93, 0x3f, 0x08, 0x00, 0x00,
+	// 1096 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0xdb, 0x8e, 0x1b, 0x45,
+	0x13, 0xce, 0xf8, 0xec, 0x1a, 0xdb, 0xf1, 0xdf, 0xca, 0xef, 0xcc, 0x3a, 0x41, 0x59, 0x26, 0x02,
+	0x2d, 0x10, 0x39, 0xc8, 0x04, 0x21, 0x11, 0x10, 0xca, 0x66, 0xc3, 0x62, 0x29, 0x59, 0xac, 0xf6,
+	0x5e, 0x71, 0x33, 0x6a, 0xcf, 0x94, 0xd7, 0x2d, 0x3c, 0x87, 0x74, 0xf7, 0x84, 0x38, 0x0f, 0xc1,
+	0x23, 0x70, 0x89, 0x78, 0x04, 0x6e, 0x78, 0x14, 0x1e, 0x81, 0x77, 0x40, 0xdd, 0x3d, 0x63, 0xcf,
+	0x66, 0x73, 0xb8, 0xe0, 0xc6, 0x9e, 0xaa, 0xfa, 0xba, 0xab, 0xea, 0xab, 0xaf, 0x0b, 0xae, 0x67,
+	0x29, 0x4f, 0x14, 0x8a, 0x68, 0x39, 0xc9, 0x44, 0xaa, 0x52, 0xd2, 0xdd, 0x39, 0xc6, 0x77, 0x2e,
+	0xd2, 0xf4, 0x62, 0x83, 0xf7, 0x4d, 0x60, 0x99, 0xaf, 0xee, 0x2b, 0x1e, 0xa3, 0x54, 0x2c, 0xce,
+	0x2c, 0x76, 0xdc, 0x4f, 0x5f, 0xa0, 0xd8, 0xb0, 0x6d, 0x61, 0x0e, 0x33, 0x8e, 0x21, 0x4a, 0x95,
+	0x0a, 0xb4, 0x1e, 0xff, 0xf7, 0x1a, 0x0c, 0x29, 0x46, 0x79, 0x12, 0xb1, 0x24, 0xdc, 0x2e, 0xc2,
+	0x35, 0xc6, 0x48, 0xbe, 0x86, 0x86, 0xda, 0x66, 0xe8, 0x39, 0x87, 0xce, 0xd1, 0x60, 0xfa, 0xf1,
+	0x64, 0x5f, 0xc1, 0xeb, 0xd0, 0x89, 0xfd, 0x3b, 0xdf, 0x66, 0x48, 0xcd, 0x19, 0x72, 0x13, 0xda,
+	0x31, 0x4f, 0x02, 0x81, 0xcf, 0xbd, 0xda, 0xa1, 0x73, 0xd4, 0xa4, 0xad, 0x98, 0x27, 0x14, 0x9f,
+	0x93, 0x1b, 0xd0, 0x54, 0xa9, 0x62, 0x1b, 0xaf, 0x6e, 0xdc, 0xd6, 0x20, 0x9f, 0xc0, 0x50, 0x60,
+	0xc6, 0xb8, 0x08, 0xd4, 0x5a, 0xa0, 0x5c, 0xa7, 0x9b, 0xc8, 0x6b, 0x18, 0xc0, 0x75, 0xeb, 0x3f,
+	0x2f, 0xdd, 0xe4, 0x33, 0xf8, 0x9f, 0xcc, 0xc3, 0x10, 0xa5, 0xac, 0x60, 0x9b, 0x06, 0x3b, 0x2c,
+	0x02, 0x7b, 0xf0, 0x3d, 0x20, 0x28, 0x98, 0xcc, 0x05, 0x06, 0x72, 0xcd, 0xf4, 0x2f, 0x7f, 0x85,
+	0x5e, 0xcb, 0xa2, 0x8b, 0xc8, 0x42, 0x07, 0x16, 0xfc, 0x15, 0xfa, 0x1f, 0x02, 0xec, 0x1b, 0x21,
+	0x2d, 0xa8, 0xd1, 0xc5, 0xf0, 0x1a, 0x71, 0xa1, 0x4d, 0x17, 0xc1, 0x62, 0xf6, 0xec, 0x64, 0xe8,
+	0xf8, 0xbf, 0x3a, 0xe0, 0x52, 0x8c, 0x53, 0x85, 0x73, 0xcd, 0x21, 0xb9, 0x05, 0x5d, 0x43, 0x66,
+	0x90, 0xe4, 0xb1, 0x21, 0xaa, 0x49, 0x3b, 0xc6, 0x71, 0x96, 0xc7, 0x9a, 0x84, 0x24, 0x8d, 0x30,
+	0xe0, 0x91, 0x21, 0xa1, 0x4b, 0x5b, 0xda, 0x9c, 0x45, 0x84, 0x40, 0x63, 0xcd, 0xe4, 0xda, 0x70,
+	0xd0, 0xa3, 0xe6, 0x9b, 0x7c, 0x09, 0x6d, 0x81, 0x21, 0xf2, 0x4c, 0x99, 0xce, 0xdd, 0xe9, 0xad,
+	0xc9, 0x7e, 0x4c, 0x22, 0xcd, 0x15, 0xca, 0x89, 0xc9, 0xf9, 0x03, 0x93, 0x6b, 0x5a, 0x62, 0xfd,
+	0xbf, 0x1d, 0xe8, 0xdb, 0x82, 0x16, 0x78, 0x11, 0x63, 0xa2, 0xc8, 0x43, 0x00, 0xb1, 0x9b, 0x8f,
+	0xe7, 0x94, 0x77, 0xbd, 0x75, 0x78, 0xb4, 0x02, 0x27, 0x07, 0x60, 0xcb, 0xdf, 0xd7, 0xdc, 0x36,
+	0xf6, 0x2c, 0x22, 0x0f, 0xa1, 0x2f, 0x4c, 0xa2, 0xc0, 0xd6, 0xe5, 0xd5, 0x0f, 0xeb, 0x47, 0xee,
+	0x74, 0x74, 0xe9, 0xea, 0x1d, 0x33, 0xb4, 0x27, 0xf6, 0x86, 0x24, 0x77, 0xc0, 0x8d, 0x51, 0xfc,
+	0xbc, 0xc1, 0x40, 0xa4, 0xa9, 0xed, 0xb0, 0x47, 0xc1, 0xba, 0x68, 0x9a, 0x2a, 0x32, 0x82, 0x56,
+	0x98, 0x66, 0x1c, 0xa5, 0xd7, 0x3c, 0xac, 0x6b, 0xaa, 0xac, 0xe5, 0xff, 0x53, 0x83, 0xf6, 0xdc,
+	0x26, 0x20, 0xf7, 0x2f, 0x09, 0xb2, 0xda, 0x53, 0x81, 0x98, 0x9c, 0x30, 0xc5, 0x2a, 0x2a, 0xfc,
+	0x08, 0x06, 0x3c, 0xd9, 0xf0, 0x04, 0x03, 0x69, 0xc9, 0x29, 0x18, 0xef, 0x5b, 0x6f, 0xc9, 0xd8,
+	0xe7, 0xd0, 0xb2, 0xc5, 0x16, 0xcc, 0x7b, 0x57, 0x5a, 0x2a, 0x90, 0xb4, 0xc0, 0xe9, 0x01, 0x1a,
+	0x25, 0x69, 0xdd, 0xd5, 0xa9, 0xf9, 0x26, 0xdf, 0x41, 0x3f, 0x14, 0xc8, 0x14, 0x4f, 0x93, 0x20,
+	0x62, 0xca, 0xca, 0xcc, 0x9d, 0x8e, 0x27, 0xf6, 0x75, 0x4e, 0xca, 0xd7, 0x39, 0x39, 0x2f, 0x5f,
+	0x27, 0xed, 0x95, 0x07, 0x4e, 0x98, 0x42, 0xf2, 0x18, 0xae, 0xe3, 0xcb, 0x8c, 0x8b, 0xca, 0x15,
+	0xed, 0xf7, 0x5e, 0x31, 0xd8, 0x1f, 0x31, 0x97, 0x8c, 0xa1, 0x13, 0xa3, 0x62, 0x11, 0x53, 0xcc,
+	0xeb, 0x98, 0x66, 0x77, 0xb6, 0xef, 0x43, 0xa7, 0x24, 0x88, 0x00, 0xb4, 0x66, 0x67, 0x4f, 0x67,
+	0x67, 0x4f, 0x86, 0xd7, 0xf4, 0x37, 0x7d, 0xf2, 0xec, 0xc7, 0xf3, 0x27, 0x43, 0xc7, 0x3f, 0x03,
+	0x98, 0xe7, 0x8a, 0xe2, 0xf3, 0x1c, 0xa5, 0xd2, 0x7d, 0x66, 0x4c, 0xad, 0x0d, 0xe3, 0x5d, 0x6a,
+	0xbe, 0xc9, 0x3d, 0x68, 0x17, 0xf4, 0x18, 0x85, 0xb8, 0x53, 0x72, 0x75, 0x10, 0xb4, 0x84, 0xf8,
+	0x87, 0x00, 0xa7, 0xf8, 0xae, 0xfb, 0xfc, 0x3f, 0x1d, 0x70, 0x9f, 0x72, 0xb9, 0xc3, 0x8c, 0xa0,
+	0x95, 0x09, 0x5c, 0xf1, 0x97, 0x05, 0xaa, 0xb0, 0xb4, 0x84, 0xa4, 0x62, 0x42, 0x05, 0x6c, 0x55,
+	0xe6, 0xee, 0x52, 0x30, 0xae, 0x47, 0xda, 0x43, 0x3e, 0x00, 0xc0, 0x24, 0x0a, 0x96, 0xb8, 0x4a,
+	0x05, 0x9a, 0x49, 0x77, 0x69, 0x17, 0x93, 0xe8, 0xd8, 0x38, 0xc8, 0x6d, 0xe8, 0x0a, 0x0c, 0x73,
+	0x21, 0xf9, 0x0b, 0x3b, 0xe8, 0x0e, 0xdd, 0x3b, 0xf4, 0x5e, 0xda, 0xf0, 0x98, 0xab, 0x62, 0x95,
+	0x58, 0x43, 0x5f, 0xa9, 0xd9, 0x0b, 0x56, 0x1b, 0x76, 0x21, 0xcd, 0x40, 0xdb, 0xb4, 0xab, 0x3d,
+	0xdf, 0x6b, 0x87, 0xdf, 0x07, 0xd7, 0x90, 0x25, 0xb3, 0x34, 0x91, 0xe8, 0xff, 0xe6, 0x80, 0x7b,
+	0x8a, 0x3b, 0xbb, 0xca, 0x94, 0xf3, 0x5e, 0xa6, 0xc8, 0x5d, 0x68, 0xea, 0xf5, 0x20, 0xbd, 0x9a,
+	0x79, 0x57, 0xfd, 0x49, 0xb9, 0xb4, 0xcf, 0xd2, 0x08, 0xa9, 0x8d, 0x91, 0x6f, 0xa0, 0x9e, 0x2d,
+	0x99, 0x69, 0xce, 0x9d, 0x7e, 0xfa, 0x86, 0x0d, 0xc1, 0xb6, 0x28, 0x8e, 0x59, 0x12, 0xfd, 0xc2,
+	0x23, 0xb5, 0x7e, 0xb4, 0xd9, 0xa4, 0xa1, 0xd1, 0x06, 0xd5, 0xc7, 0xfc, 0xbf, 0x1c, 0xe8, 0x59,
+	0xaa, 0x8b, 0x0a, 0xa7, 0xd0, 0xe4, 0x0a, 0x63, 0xe9, 0x39, 0x26, 0xe7, 0xed, 0x4a, 0x7d, 0x55,
+	0xdc, 0x64, 0xa6, 0x30, 0xa6, 0x16, 0xaa, 0x67, 0x18, 0x6b, 0x82, 0x6b, 0x86, 0x42, 0xf3, 0x3d,
+	0x46, 0x68, 0x68, 0xc8, 0x7f, 0xd7, 0x8b, 0x5e, 0xa8, 0x5c, 0x06, 0x85, 0x00, 0xea, 0x26, 0x45,
+	0x87, 0xcb, 0xb9, 0xb1, 0xfd, 0xbb, 0xd0, 0x3f, 0xc1, 0x0d, 0x2a, 0x7c, 0x97, 0x9e, 0x8e, 0x60,
+	0x50, 0x82, 0x8a, 0x2e, 0x47, 0xd0, 0x32, 0xdb, 0x3f, 0x32, 0xb8, 0x0e, 0x2d, 0x2c, 0x5f, 0xc0,
+	0x60, 0xa6, 0x50, 0x30, 0x85, 0xef, 0xd3, 0xde, 0x0d, 0x68, 0xae, 0xb8, 0x90, 0xaa, 0x50, 0x9d,
+	0x35, 0x88, 0x67, 0x56, 0x76, 0x2e, 0x24, 0x16, 0x95, 0x96, 0xa6, 0x8d, 0xbc, 0x40, 0x21, 0x4b,
+	0xa5, 0x95, 0xa6, 0x1f, 0x80, 0xfb, 0x38, 0xcd, 0xb6, 0x65, 0xc2, 0x03, 0xe8, 0x48, 0x11, 0x06,
+	0x95, 0x26, 0xda, 0x52, 0x84, 0x73, 0xcd, 0xdb, 0x01, 0x74, 0x22, 0xa9, 0x6c, 0xa8, 0x58, 0xc5,
+	0x91, 0x54, 0x26, 0x54, 0x7d, 0xe4, 0xf5, 0xd7, 0x1e, 0xf9, 0x00, 0x7a, 0x36, 0x81, 0x6d, 0x7e,
+	0xfa, 0x47, 0x0d, 0xba, 0x05, 0xcb, 0x27, 0xc7, 0xe4, 0x01, 0xd4, 0xe7, 0xb9, 0x22, 0xff, 0xaf,
+	0x8e, 0x60, 0xf7, 0xdc, 0xc7, 0xa3, 0xd7, 0xdd, 0x05, 0x81, 0x0f, 0xa0, 0x7e, 0x8a, 0x97, 0x4f,
+	0x9d, 0xe2, 0x1b, 0x4f, 0x55, 0xe5, 0xff, 0x15, 0x34, 0xb4, 0x88, 0xc8, 0xe8, 0x8a, 0xaa, 0xec,
+	0xb9, 0x9b, 0x6f, 0x51, 0x1b, 0xf9, 0x16, 0x5a, 0x76, 0x82, 0xa4, 0xba, 0x89, 0x2f, 0x4d, 0x7e,
+	0x7c, 0xf0, 0x86, 0xc8, 0x3e, 0xaf, 0x66, 0xe0, 0x52, 0xde, 0x0a, 0xe7, 0xe3, 0x9b, 0x57, 0xfc,
+	0xf6, 0xe0, 0x71, 0xe3, 0xa7, 0x5a, 0xb6, 0x5c, 0xb6, 0xcc, 0x96, 0xfd, 0xe2, 0xdf, 0x01, 0x00,
+	0xbb, 0x33, 0x02, 0x72, 0x72, 0x09, 0x00, 0x00,
 }
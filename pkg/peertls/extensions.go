@@ -0,0 +1,166 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package peertls
+
+import (
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+
+	"github.com/zeebo/errs"
+)
+
+// ErrExtension is used when a certificate extension fails verification
+var ErrExtension = errs.Class("certificate extension error")
+
+// ExtensionHandlerFunc verifies an extension of cert, a certificate of a
+// peer's chain. chain is the whole chain, leaf first.
+type ExtensionHandlerFunc func(ext pkix.Extension, cert *x509.Certificate, chain []*x509.Certificate) error
+
+// ExtensionHandlers is a registry of the handlers verifying certificate
+// extensions during peer verification, by extension id. New handshake
+// policies are added by registering a handler for the extension carrying
+// them, and, for policies that apply to chains without the extension, like
+// revocations, a check of the whole chain.
+type ExtensionHandlers struct {
+	handlers map[string]ExtensionHandlerFunc
+	checks   []PeerCertVerificationFunc
+}
+
+// NewExtensionHandlers returns an empty registry of extension handlers
+func NewExtensionHandlers() *ExtensionHandlers {
+	return &ExtensionHandlers{handlers: make(map[string]ExtensionHandlerFunc)}
+}
+
+// DefaultExtensionHandlers returns a registry of the handlers of the
+// extensions identities use: revocations of leaves by later leaves of their
+// CA and, if there are authorities, the signatures of node ids by them.
+func DefaultExtensionHandlers(authorities []*x509.Certificate) *ExtensionHandlers {
+	h := NewExtensionHandlers()
+	revocations := NewRevocations()
+	h.Register(RevocationExtID, revocations.Handler)
+	h.RegisterCheck(revocations.Verify)
+	if len(authorities) > 0 {
+		h.Register(SignedNodeIDExtID, SignedNodeIDHandler(authorities))
+	}
+	return h
+}
+
+// Register sets the handler of the extension with the given id, replacing
+// the one registered before, if any
+func (h *ExtensionHandlers) Register(id asn1.ObjectIdentifier, handler ExtensionHandlerFunc) {
+	h.handlers[id.String()] = handler
+}
+
+// RegisterCheck adds a check of the whole chain of peers, run after the
+// handlers of its extensions
+func (h *ExtensionHandlers) RegisterCheck(check PeerCertVerificationFunc) {
+	h.checks = append(h.checks, check)
+}
+
+// Handler returns the handler of the extension with the given id, or nil
+func (h *ExtensionHandlers) Handler(id asn1.ObjectIdentifier) ExtensionHandlerFunc {
+	if h == nil {
+		return nil
+	}
+	return h.handlers[id.String()]
+}
+
+// verifyCert runs the registered handlers of the extensions of cert, a
+// certificate of chain. Unless ignoreCritical is set, critical extensions
+// without a handler fail the verification.
+func (h *ExtensionHandlers) verifyCert(cert *x509.Certificate, chain []*x509.Certificate, ignoreCritical bool) error {
+	for _, ext := range cert.Extensions {
+		handler := h.Handler(ext.Id)
+		if handler == nil {
+			if !ignoreCritical && ext.Critical && isUnhandledCritical(cert, ext.Id) {
+				return ErrExtension.New("unhandled critical extension %s", ext.Id)
+			}
+			continue
+		}
+		if err := handler(ext, cert, chain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyExtensions returns a peer certificate verification function which
+// runs the registered handler of each extension of the certificates of the
+// peer's chain, and then the registered checks. Extensions without a handler
+// are ignored unless they're critical, in which case the peer is rejected.
+// It returns nil, i.e. no verification, if there is no registry.
+func VerifyExtensions(h *ExtensionHandlers) PeerCertVerificationFunc {
+	if h == nil {
+		return nil
+	}
+
+	return func(rawChain [][]byte, parsedChains [][]*x509.Certificate) error {
+		chain := parsedChains[0]
+		for _, cert := range chain {
+			if err := h.verifyCert(cert, chain, false); err != nil {
+				return ErrExtension.Wrap(err)
+			}
+		}
+		for _, check := range h.checks {
+			if err := check(rawChain, parsedChains); err != nil {
+				return ErrExtension.Wrap(err)
+			}
+		}
+		return nil
+	}
+}
+
+// isUnhandledCritical returns whether the x509 package didn't handle the
+// critical extension with the given id itself
+func isUnhandledCritical(cert *x509.Certificate, id asn1.ObjectIdentifier) bool {
+	for _, unhandled := range cert.UnhandledCriticalExtensions {
+		if unhandled.Equal(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyAuthoritySignatureExt is the ExtensionHandlerFunc of
+// AuthoritySignatureExtID, which holds a signature of the certificate
+func VerifyAuthoritySignatureExt(ext pkix.Extension, cert *x509.Certificate, _ []*x509.Certificate) error {
+	if err := verifySignature(ext.Value, cert.RawTBSCertificate, cert.PublicKey); err != nil {
+		return ErrVerifyCAWhitelist.New("authority signature extension verification error: %s", err.Error())
+	}
+	return nil
+}
+
+// authoritySignatureHandlers is the registry VerifyCAWhitelist verifies the
+// extensions of leaves of whitelisted CAs with
+var authoritySignatureHandlers = func() *ExtensionHandlers {
+	h := NewExtensionHandlers()
+	h.Register(AuthoritySignatureExtID, VerifyAuthoritySignatureExt)
+	return h
+}()
+
+// NewSignedNodeIDExt returns the extension of the CA cert ca holding a
+// signature of its public key, which its node id is derived from, by the
+// authority with the given key
+func NewSignedNodeIDExt(key crypto.PrivateKey, ca *x509.Certificate) (pkix.Extension, error) {
+	signature, err := SignMessage(key, ca.RawSubjectPublicKeyInfo)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: SignedNodeIDExtID, Value: signature}, nil
+}
+
+// SignedNodeIDHandler returns the ExtensionHandlerFunc of SignedNodeIDExtID
+// accepting node ids signed by any of the authorities
+func SignedNodeIDHandler(authorities []*x509.Certificate) ExtensionHandlerFunc {
+	return func(ext pkix.Extension, cert *x509.Certificate, _ []*x509.Certificate) error {
+		for _, authority := range authorities {
+			if VerifyMessage(authority.PublicKey, cert.RawSubjectPublicKeyInfo, ext.Value) == nil {
+				return nil
+			}
+		}
+		return ErrExtension.New("node id isn't signed by any authority")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package peertls
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebo/errs"
+)
+
+func TestVerifyExtensions(t *testing.T) {
+	testExtID := asn1.ObjectIdentifier{2, 999, 9999}
+
+	newChain := func(ext pkix.Extension) []*x509.Certificate {
+		k, err := NewKey()
+		assert.NoError(t, err)
+		pk, err := PublicKey(k)
+		assert.NoError(t, err)
+
+		ct, err := CATemplate()
+		assert.NoError(t, err)
+		c, err := NewCert(ct, nil, pk, k)
+		assert.NoError(t, err)
+
+		lt, err := LeafTemplate()
+		assert.NoError(t, err)
+		lt.ExtraExtensions = []pkix.Extension{ext}
+		l, err := NewCert(lt, c, pk, k)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return []*x509.Certificate{l, c}
+	}
+
+	assert.Nil(t, VerifyExtensions(nil))
+
+	handlers := NewExtensionHandlers()
+	var handled [][]byte
+	handlers.Register(testExtID, func(ext pkix.Extension, cert *x509.Certificate, chain []*x509.Certificate) error {
+		assert.Equal(t, chain[0], cert)
+		handled = append(handled, ext.Value)
+		if bytes.Equal(ext.Value, []byte("bad")) {
+			return errs.New("bad extension")
+		}
+		return nil
+	})
+	verify := VerifyExtensions(handlers)
+
+	err := verify(nil, [][]*x509.Certificate{newChain(pkix.Extension{Id: testExtID, Value: []byte("good")})})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("good")}, handled)
+
+	err = verify(nil, [][]*x509.Certificate{newChain(pkix.Extension{Id: testExtID, Value: []byte("bad")})})
+	assert.True(t, ErrExtension.Has(err))
+
+	// critical extensions need a handler, others are ignored without one
+	otherExtID := asn1.ObjectIdentifier{2, 999, 9998}
+	err = verify(nil, [][]*x509.Certificate{newChain(pkix.Extension{Id: otherExtID, Value: []byte("x")})})
+	assert.NoError(t, err)
+	err = verify(nil, [][]*x509.Certificate{newChain(pkix.Extension{Id: otherExtID, Critical: true, Value: []byte("x")})})
+	assert.True(t, ErrExtension.Has(err))
+	err = verify(nil, [][]*x509.Certificate{newChain(pkix.Extension{Id: testExtID, Critical: true, Value: []byte("good")})})
+	assert.NoError(t, err)
+}
+
+func TestDefaultExtensionHandlers(t *testing.T) {
+	newCA := func() (*x509.Certificate, interface{}) {
+		k, err := NewKey()
+		assert.NoError(t, err)
+		pk, err := PublicKey(k)
+		assert.NoError(t, err)
+		ct, err := CATemplate()
+		assert.NoError(t, err)
+		c, err := NewCert(ct, nil, pk, k)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return c, k
+	}
+	newLeaf := func(ca *x509.Certificate, caKey interface{}, exts ...pkix.Extension) *x509.Certificate {
+		k, err := NewKey()
+		assert.NoError(t, err)
+		pk, err := PublicKey(k)
+		assert.NoError(t, err)
+		lt, err := LeafTemplate()
+		assert.NoError(t, err)
+		lt.ExtraExtensions = exts
+		l, err := NewCert(lt, ca, pk, caKey)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return l
+	}
+
+	authority, authorityKey := newCA()
+	ca, caKey := newCA()
+	verify := VerifyExtensions(DefaultExtensionHandlers([]*x509.Certificate{authority}))
+
+	// leaves are rejected once a later leaf of their CA revoked them
+	old := newLeaf(ca, caKey)
+	assert.NoError(t, verify(nil, [][]*x509.Certificate{{old, ca}}))
+	revocation, err := NewRevocationExt(caKey, old)
+	assert.NoError(t, err)
+	rotated := newLeaf(ca, caKey, revocation)
+	assert.NoError(t, verify(nil, [][]*x509.Certificate{{rotated, ca}}))
+	assert.True(t, ErrExtension.Has(verify(nil, [][]*x509.Certificate{{old, ca}})))
+
+	// only the CA of a leaf can revoke it
+	other, otherKey := newCA()
+	victim := newLeaf(ca, caKey)
+	forged, err := NewRevocationExt(otherKey, victim)
+	assert.NoError(t, err)
+	assert.Error(t, verify(nil, [][]*x509.Certificate{{newLeaf(ca, caKey, forged), ca}}))
+	assert.NoError(t, verify(nil, [][]*x509.Certificate{{newLeaf(other, otherKey, forged), other}}))
+	assert.NoError(t, verify(nil, [][]*x509.Certificate{{victim, ca}}))
+
+	// signed node ids have to be signed by an authority
+	signed, err := NewSignedNodeIDExt(authorityKey, ca)
+	assert.NoError(t, err)
+	unsigned, err := NewSignedNodeIDExt(otherKey, ca)
+	assert.NoError(t, err)
+	for _, test := range []struct {
+		ext   pkix.Extension
+		valid bool
+	}{
+		{signed, true},
+		{unsigned, false},
+	} {
+		ct, err := CATemplate()
+		assert.NoError(t, err)
+		ct.ExtraExtensions = []pkix.Extension{test.ext}
+		signedCA, err := NewCert(ct, nil, ca.PublicKey, caKey)
+		assert.NoError(t, err)
+		err = verify(nil, [][]*x509.Certificate{{newLeaf(signedCA, caKey), signedCA}})
+		assert.Equal(t, test.valid, err == nil, "%v", err)
+	}
+}
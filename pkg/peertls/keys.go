@@ -0,0 +1,113 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package peertls
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/pem"
+
+	"github.com/zeebo/errs"
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters for deriving key encryption keys from passphrases
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptSalt   = 16
+	scryptKeyLen = 32
+)
+
+// ErrPassphrase is used when an encrypted private key can't be decrypted
+var ErrPassphrase = errs.Class("private key passphrase error")
+
+// EncryptKeyBlock returns the private key as a PEM block encrypted with
+// AES-GCM, under a key derived from the passphrase with scrypt. The salt and
+// nonce are stored in the block headers.
+func EncryptKeyBlock(key crypto.PrivateKey, passphrase []byte) (*pem.Block, error) {
+	kb, err := marshalKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, scryptSalt)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	aead, err := newKeyCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	return &pem.Block{
+		Type: BlockTypeEncryptedEcPrivateKey,
+		Headers: map[string]string{
+			"Salt":  hex.EncodeToString(salt),
+			"Nonce": hex.EncodeToString(nonce),
+		},
+		Bytes: aead.Seal(nil, nonce, kb, nil),
+	}, nil
+}
+
+// DecryptKeyBlock decrypts a block created by EncryptKeyBlock
+func DecryptKeyBlock(b *pem.Block, passphrase []byte) (crypto.PrivateKey, error) {
+	if !IsEncryptedKeyBlock(b) {
+		return nil, ErrPassphrase.New("not an encrypted key: %s", b.Type)
+	}
+
+	salt, err := hex.DecodeString(b.Headers["Salt"])
+	if err != nil {
+		return nil, ErrPassphrase.Wrap(err)
+	}
+	nonce, err := hex.DecodeString(b.Headers["Nonce"])
+	if err != nil {
+		return nil, ErrPassphrase.Wrap(err)
+	}
+	aead, err := newKeyCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrPassphrase.New("invalid nonce")
+	}
+
+	kb, err := aead.Open(nil, nonce, b.Bytes, nil)
+	if err != nil {
+		return nil, ErrPassphrase.New("wrong passphrase or corrupted key")
+	}
+	return ParseKey(kb)
+}
+
+// IsEncryptedKeyBlock returns whether the block holds an encrypted private key
+func IsEncryptedKeyBlock(b *pem.Block) bool {
+	return b != nil && b.Type == BlockTypeEncryptedEcPrivateKey
+}
+
+func newKeyCipher(passphrase, salt []byte) (cipher.AEAD, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrPassphrase.New("empty passphrase")
+	}
+	dk, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, ErrPassphrase.Wrap(err)
+	}
+	block, err := aes.NewCipher(dk)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return aead, nil
+}
@@ -6,6 +6,7 @@ package peertls
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
@@ -19,6 +20,12 @@ import (
 const (
 	// BlockTypeEcPrivateKey is the value to define a block type of private key
 	BlockTypeEcPrivateKey = "EC PRIVATE KEY"
+	// BlockTypePrivateKey is the value to define a block type of PKCS #8
+	// private key, which Ed25519 keys are stored as
+	BlockTypePrivateKey = "PRIVATE KEY"
+	// BlockTypeEncryptedEcPrivateKey is the value to define a block type of
+	// passphrase encrypted private key
+	BlockTypeEncryptedEcPrivateKey = "ENCRYPTED EC PRIVATE KEY"
 	// BlockTypeCertificate is the value to define a block type of certificate
 	BlockTypeCertificate = "CERTIFICATE"
 	// BlockTypeIDOptions is the value to define a block type of id options
@@ -30,6 +37,12 @@ var (
 	// AuthoritySignatureExtID is the asn1 object ID for a pkix extension holding a signature of the leaf cert, signed by some CA (e.g. the root cert)
 	// This extension allows for an additional signature per certificate
 	AuthoritySignatureExtID = asn1.ObjectIdentifier{2, 999, 1}
+	// SignedNodeIDExtID is the asn1 object ID of the extension of CA certs
+	// holding a signature of their node id by an authority vouching for it
+	SignedNodeIDExtID = asn1.ObjectIdentifier{2, 999, 2}
+	// RevocationExtID is the asn1 object ID of the extension of leaves
+	// holding a Revocation of an earlier leaf of their CA
+	RevocationExtID = asn1.ObjectIdentifier{2, 999, 3}
 	// ErrNotExist is used when a file or directory doesn't exist
 	ErrNotExist = errs.Class("file or directory not found error")
 	// ErrGenerate is used when an error occurred during cert/key generation
@@ -70,9 +83,10 @@ func NewKey() (crypto.PrivateKey, error) {
 // NewCert returns a new x509 certificate using the provided templates and
 // signed by the `signer` key
 func NewCert(template, parentTemplate *x509.Certificate, pubKey crypto.PublicKey, signer crypto.PrivateKey) (*x509.Certificate, error) {
-	k, ok := signer.(*ecdsa.PrivateKey)
-	if !ok {
-		return nil, ErrUnsupportedKey.New("%T", k)
+	switch signer.(type) {
+	case *ecdsa.PrivateKey, ed25519.PrivateKey:
+	default:
+		return nil, ErrUnsupportedKey.New("%T", signer)
 	}
 
 	if parentTemplate == nil {
@@ -84,7 +98,7 @@ func NewCert(template, parentTemplate *x509.Certificate, pubKey crypto.PublicKey
 		template,
 		parentTemplate,
 		pubKey,
-		k,
+		signer,
 	)
 	if err != nil {
 		return nil, errs.Wrap(err)
@@ -117,6 +131,22 @@ func VerifyPeerFunc(next ...PeerCertVerificationFunc) PeerCertVerificationFunc {
 	}
 }
 
+// VerifyResumedFunc returns a `*tls.Config#VerifyConnection` function
+// running verify on the peer certificates of resumed connections, as
+// `VerifyPeerCertificate` is only called on full handshakes.
+func VerifyResumedFunc(verify PeerCertVerificationFunc) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if !cs.DidResume {
+			return nil
+		}
+		chain := make([][]byte, 0, len(cs.PeerCertificates))
+		for _, cert := range cs.PeerCertificates {
+			chain = append(chain, cert.Raw)
+		}
+		return verify(chain, nil)
+	}
+}
+
 // VerifyPeerCertChains verifies that the first certificate chain contains certificates
 // which are signed by their respective parents, ending with a self-signed root
 func VerifyPeerCertChains(_ [][]byte, parsedChains [][]*x509.Certificate) error {
@@ -145,16 +175,7 @@ func VerifyCAWhitelist(cas []*x509.Certificate, verifyExtension bool) PeerCertVe
 					break
 				}
 
-				for _, ext := range leaf.Extensions {
-					if ext.Id.Equal(AuthoritySignatureExtID) {
-						err = verifySignature(ext.Value, leaf.RawTBSCertificate, leaf.PublicKey)
-						if err != nil {
-							return ErrVerifyCAWhitelist.New("authority signature extension verification error: %s", err.Error())
-						}
-						return nil
-					}
-				}
-				break
+				return authoritySignatureHandlers.verifyCert(leaf, parsedChains[0], true)
 			}
 		}
 
@@ -207,23 +228,59 @@ func WriteChain(w io.Writer, chain ...*x509.Certificate) error {
 
 // WriteKey writes the private key to the writer, PEM-encoded.
 func WriteKey(w io.Writer, key crypto.PrivateKey) error {
-	var (
-		kb  []byte
-		err error
-	)
+	b, err := keyBlock(key)
+	if err != nil {
+		return err
+	}
+
+	if err := pem.Encode(w, b); err != nil {
+		return errs.Wrap(err)
+	}
+	return nil
+}
+
+// WriteEncryptedKey writes the private key to the writer, encrypted with
+// the passphrase and PEM-encoded.
+func WriteEncryptedKey(w io.Writer, key crypto.PrivateKey, passphrase []byte) error {
+	b, err := EncryptKeyBlock(key, passphrase)
+	if err != nil {
+		return err
+	}
 
+	if err := pem.Encode(w, b); err != nil {
+		return errs.Wrap(err)
+	}
+	return nil
+}
+
+// keyBlock returns the private key as a PEM block, ECDSA keys as SEC 1 EC
+// keys and Ed25519 keys as PKCS #8 keys
+func keyBlock(key crypto.PrivateKey) (*pem.Block, error) {
+	kb, err := marshalKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		return &pem.Block{Type: BlockTypePrivateKey, Bytes: kb}, nil
+	}
+	return NewKeyBlock(kb), nil
+}
+
+func marshalKey(key crypto.PrivateKey) ([]byte, error) {
 	switch k := key.(type) {
 	case *ecdsa.PrivateKey:
-		kb, err = x509.MarshalECPrivateKey(k)
+		kb, err := x509.MarshalECPrivateKey(k)
 		if err != nil {
-			return errs.Wrap(err)
+			return nil, errs.Wrap(err)
 		}
+		return kb, nil
+	case ed25519.PrivateKey:
+		kb, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		return kb, nil
 	default:
-		return ErrUnsupportedKey.New("%T", k)
-	}
-
-	if err := pem.Encode(w, NewKeyBlock(kb)); err != nil {
-		return errs.Wrap(err)
+		return nil, ErrUnsupportedKey.New("%T", k)
 	}
-	return nil
 }
@@ -6,6 +6,7 @@ package peertls
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/tls"
 	"crypto/x509"
 	"testing"
 
@@ -102,6 +103,31 @@ func TestVerifyPeerFunc(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestVerifyResumedFunc(t *testing.T) {
+	k, err := NewKey()
+	assert.NoError(t, err)
+	ct, err := CATemplate()
+	assert.NoError(t, err)
+	cp, _ := k.(*ecdsa.PrivateKey)
+	c, err := NewCert(ct, nil, &cp.PublicKey, k)
+	assert.NoError(t, err)
+
+	var verified [][]byte
+	verify := func(chain [][]byte, _ [][]*x509.Certificate) error {
+		verified = chain
+		return errs.New("rejected")
+	}
+
+	// full handshakes were verified by VerifyPeerCertificate already
+	err = VerifyResumedFunc(verify)(tls.ConnectionState{PeerCertificates: []*x509.Certificate{c}})
+	assert.NoError(t, err)
+	assert.Nil(t, verified)
+
+	err = VerifyResumedFunc(verify)(tls.ConnectionState{DidResume: true, PeerCertificates: []*x509.Certificate{c}})
+	assert.Error(t, err)
+	assert.Equal(t, [][]byte{c.Raw}, verified)
+}
+
 func TestVerifyPeerCertChains(t *testing.T) {
 	k, err := NewKey()
 	assert.NoError(t, err)
@@ -225,3 +251,23 @@ func TestVerifyCAWhitelist(t *testing.T) {
 	err = VerifyPeerFunc(VerifyCAWhitelist([]*x509.Certificate{c, z}, true))([][]byte{z.Raw, x.Raw, y.Raw}, nil)
 	assert.NoError(t, err)
 }
+
+func TestEncryptKeyBlock(t *testing.T) {
+	k, err := NewKey()
+	assert.NoError(t, err)
+
+	b, err := EncryptKeyBlock(k, []byte("passphrase"))
+	assert.NoError(t, err)
+	assert.True(t, IsEncryptedKeyBlock(b))
+	assert.False(t, IsEncryptedKeyBlock(NewKeyBlock(nil)))
+
+	decrypted, err := DecryptKeyBlock(b, []byte("passphrase"))
+	assert.NoError(t, err)
+	assert.Equal(t, k, decrypted)
+
+	_, err = DecryptKeyBlock(b, []byte("wrong"))
+	assert.True(t, ErrPassphrase.Has(err))
+
+	_, err = EncryptKeyBlock(k, nil)
+	assert.True(t, ErrPassphrase.Has(err))
+}
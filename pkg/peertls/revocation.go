@@ -0,0 +1,102 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package peertls
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"sync"
+)
+
+// maxRevocations is the number of revocations Revocations remembers; past
+// it, arbitrary ones are forgotten
+const maxRevocations = 10000
+
+// Revocation is the value of a RevocationExtID extension: the CA of the leaf
+// holding it revokes its leaf whose raw certificate hashes to LeafHash. It's
+// signed by the CA, so that no one else can revoke its leaves.
+type Revocation struct {
+	LeafHash  []byte
+	Signature []byte
+}
+
+// NewRevocationExt returns the extension of a new leaf of the CA with the
+// given key, revoking the leaf before it
+func NewRevocationExt(caKey crypto.PrivateKey, revoked *x509.Certificate) (pkix.Extension, error) {
+	hash := sha256.Sum256(revoked.Raw)
+	signature, err := SignMessage(caKey, hash[:])
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	value, err := asn1.Marshal(Revocation{LeafHash: hash[:], Signature: signature})
+	if err != nil {
+		return pkix.Extension{}, ErrExtension.Wrap(err)
+	}
+	return pkix.Extension{Id: RevocationExtID, Value: value}, nil
+}
+
+// Revocations remembers the leaves revoked by the chains of peers, so that
+// chains with them are rejected afterwards
+type Revocations struct {
+	mu      sync.Mutex
+	revoked map[string]struct{}
+}
+
+// NewRevocations returns an empty set of revocations
+func NewRevocations() *Revocations {
+	return &Revocations{revoked: make(map[string]struct{})}
+}
+
+// revocationKey returns the key of the revocation of the leaf with the
+// given hash by ca
+func revocationKey(ca *x509.Certificate, leafHash []byte) string {
+	caHash := sha256.Sum256(ca.RawSubjectPublicKeyInfo)
+	return string(caHash[:]) + string(leafHash)
+}
+
+// Handler is the ExtensionHandlerFunc of RevocationExtID, which remembers
+// the revocation once its signature by the CA of the leaf is verified
+func (r *Revocations) Handler(ext pkix.Extension, cert *x509.Certificate, chain []*x509.Certificate) error {
+	if len(chain) < 2 || cert != chain[0] {
+		return ErrExtension.New("revocations are only valid on leaves")
+	}
+	var revocation Revocation
+	if _, err := asn1.Unmarshal(ext.Value, &revocation); err != nil {
+		return ErrExtension.Wrap(err)
+	}
+	if err := VerifyMessage(chain[1].PublicKey, revocation.LeafHash, revocation.Signature); err != nil {
+		return ErrExtension.New("revocation isn't signed by the CA: %v", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.revoked) >= maxRevocations {
+		for key := range r.revoked {
+			delete(r.revoked, key)
+			break
+		}
+	}
+	r.revoked[revocationKey(chain[1], revocation.LeafHash)] = struct{}{}
+	return nil
+}
+
+// Verify is a PeerCertVerificationFunc rejecting chains whose leaf was
+// revoked by its CA
+func (r *Revocations) Verify(_ [][]byte, parsedChains [][]*x509.Certificate) error {
+	chain := parsedChains[0]
+	if len(chain) < 2 {
+		return nil
+	}
+	hash := sha256.Sum256(chain[0].Raw)
+	r.mu.Lock()
+	_, revoked := r.revoked[revocationKey(chain[1], hash[:])]
+	r.mu.Unlock()
+	if revoked {
+		return ErrExtension.New("leaf is revoked by its CA")
+	}
+	return nil
+}
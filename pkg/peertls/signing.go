@@ -0,0 +1,155 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package peertls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+
+	"github.com/gtank/cryptopasta"
+	"github.com/zeebo/errs"
+)
+
+// Key types identities can be generated with. Which one an identity uses is
+// told by the public keys in its certificates, so peers with different key
+// types can talk to each other.
+const (
+	// KeyTypeECDSA is the type of ECDSA P-256 keys
+	KeyTypeECDSA = "ecdsa"
+	// KeyTypeEd25519 is the type of Ed25519 keys, which are faster to sign
+	// with on low-power nodes
+	KeyTypeEd25519 = "ed25519"
+)
+
+// NewKeyOfType returns a new PrivateKey of the given type, an empty type
+// being KeyTypeECDSA
+func NewKeyOfType(keyType string) (crypto.PrivateKey, error) {
+	switch keyType {
+	case "", KeyTypeECDSA:
+		return NewKey()
+	case KeyTypeEd25519:
+		_, k, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, ErrGenerate.New("failed to generate private key: %v", err)
+		}
+		return k, nil
+	default:
+		return nil, ErrUnsupportedKey.New("%s", keyType)
+	}
+}
+
+// KeyTypeOf returns the type of a private key, or an empty string if it's
+// not supported
+func KeyTypeOf(key crypto.PrivateKey) string {
+	switch key.(type) {
+	case *ecdsa.PrivateKey:
+		return KeyTypeECDSA
+	case ed25519.PrivateKey:
+		return KeyTypeEd25519
+	default:
+		return ""
+	}
+}
+
+// PublicKey returns the public key of a private key
+func PublicKey(key crypto.PrivateKey) (crypto.PublicKey, error) {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey, nil
+	case ed25519.PrivateKey:
+		return k.Public(), nil
+	default:
+		return nil, ErrUnsupportedKey.New("%T", key)
+	}
+}
+
+// ParseKey parses an ASN.1/DER-encoded private key, either a SEC 1 EC key or
+// a PKCS #8 key
+func ParseKey(der []byte) (crypto.PrivateKey, error) {
+	if k, err := x509.ParseECPrivateKey(der); err == nil {
+		return k, nil
+	}
+
+	k, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, errs.New("unable to parse private key: %v", err)
+	}
+	switch k := k.(type) {
+	case *ecdsa.PrivateKey, ed25519.PrivateKey:
+		return k, nil
+	default:
+		return nil, ErrUnsupportedKey.New("%T", k)
+	}
+}
+
+// SignData signs data with key in the format certificate signatures and
+// authority signature extensions are verified in
+func SignData(key crypto.PrivateKey, data []byte) ([]byte, error) {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		hash := crypto.SHA256.New()
+		if _, err := hash.Write(data); err != nil {
+			return nil, ErrSign.Wrap(err)
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, k, hash.Sum(nil))
+		if err != nil {
+			return nil, ErrSign.Wrap(err)
+		}
+		signature, err := asn1.Marshal(ECDSASignature{R: r, S: s})
+		if err != nil {
+			return nil, ErrSign.Wrap(err)
+		}
+		return signature, nil
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, data), nil
+	default:
+		return nil, ErrUnsupportedKey.New("%T", key)
+	}
+}
+
+// SignMessage signs a protocol message, such as a bandwidth allocation or a
+// node record, with key
+func SignMessage(key crypto.PrivateKey, msg []byte) ([]byte, error) {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		signature, err := cryptopasta.Sign(msg, k)
+		if err != nil {
+			return nil, ErrSign.Wrap(err)
+		}
+		return signature, nil
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, msg), nil
+	default:
+		return nil, ErrUnsupportedKey.New("%T", key)
+	}
+}
+
+// ecdsaSignatureSize is the size of the ECDSA P-256 signatures of
+// SignMessage, which are the r and s values of 32 bytes each
+const ecdsaSignatureSize = 64
+
+// VerifyMessage verifies the signature of a protocol message created with
+// SignMessage by the private key of pubKey
+func VerifyMessage(pubKey crypto.PublicKey, msg, signature []byte) error {
+	var ok bool
+	switch k := pubKey.(type) {
+	case *ecdsa.PublicKey:
+		// cryptopasta slices the signature into its r and s halves without
+		// checking its length, so shorter signatures from peers would panic
+		ok = len(signature) == ecdsaSignatureSize && cryptopasta.Verify(msg, signature, k)
+	case ed25519.PublicKey:
+		ok = len(k) == ed25519.PublicKeySize && len(signature) == ed25519.SignatureSize &&
+			ed25519.Verify(k, msg, signature)
+	default:
+		return ErrUnsupportedKey.New("%T", pubKey)
+	}
+	if !ok {
+		return ErrVerifySignature.New("signature is not valid")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package peertls
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyTypes(t *testing.T) {
+	for _, keyType := range []string{KeyTypeECDSA, KeyTypeEd25519} {
+		t.Run(keyType, func(t *testing.T) {
+			k, err := NewKeyOfType(keyType)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, keyType, KeyTypeOf(k))
+			pk, err := PublicKey(k)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			ct, err := CATemplate()
+			assert.NoError(t, err)
+			c, err := NewCert(ct, nil, pk, k)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			lk, err := NewKeyOfType(keyType)
+			assert.NoError(t, err)
+			lpk, err := PublicKey(lk)
+			assert.NoError(t, err)
+			lt, err := LeafTemplate()
+			assert.NoError(t, err)
+			l, err := NewCert(lt, c, lpk, k)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			assert.NoError(t, VerifyPeerCertChains(nil, [][]*x509.Certificate{{l, c}}))
+
+			signature, err := SignData(k, l.RawTBSCertificate)
+			assert.NoError(t, err)
+			assert.NoError(t, verifySignature(signature, l.RawTBSCertificate, pk))
+			assert.True(t, ErrVerifySignature.Has(verifySignature(signature, l.RawTBSCertificate, lpk)))
+
+			msg := []byte("bandwidth allocation")
+			signature, err = SignMessage(lk, msg)
+			assert.NoError(t, err)
+			assert.NoError(t, VerifyMessage(lpk, msg, signature))
+			assert.True(t, ErrVerifySignature.Has(VerifyMessage(lpk, []byte("tampered"), signature)))
+			assert.True(t, ErrVerifySignature.Has(VerifyMessage(pk, msg, signature)))
+			for _, tt := range []struct {
+				name      string
+				signature []byte
+			}{
+				{"nil", nil},
+				{"empty", []byte{}},
+				{"short", signature[:len(signature)/2]},
+				{"long", append(append([]byte{}, signature...), 0)},
+			} {
+				assert.True(t, ErrVerifySignature.Has(VerifyMessage(lpk, msg, tt.signature)), tt.name)
+			}
+
+			var buf bytes.Buffer
+			assert.NoError(t, WriteKey(&buf, k))
+			b, _ := pem.Decode(buf.Bytes())
+			if assert.NotNil(t, b) {
+				parsed, err := ParseKey(b.Bytes)
+				assert.NoError(t, err)
+				assert.Equal(t, k, parsed)
+			}
+		})
+	}
+
+	_, err := NewKeyOfType("rsa")
+	assert.True(t, ErrUnsupportedKey.Has(err))
+}
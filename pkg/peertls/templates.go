@@ -6,8 +6,13 @@ package peertls
 import (
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"time"
 )
 
+// LeafValidity is how long leaf certificates are valid for. Leafs are
+// replaced before they expire, the certificate authority stays the same.
+const LeafValidity = 90 * 24 * time.Hour
+
 // CATemplate returns x509.Certificate template for certificate authority
 func CATemplate() (*x509.Certificate, error) {
 	serialNumber, err := newSerialNumber()
@@ -34,8 +39,11 @@ func LeafTemplate() (*x509.Certificate, error) {
 		return nil, ErrTLSTemplate.Wrap(err)
 	}
 
+	now := time.Now()
 	template := &x509.Certificate{
 		SerialNumber:          serialNumber,
+		NotBefore:             now,
+		NotAfter:              now.Add(LeafValidity),
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
 		BasicConstraintsValid: true,
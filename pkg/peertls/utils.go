@@ -14,6 +14,7 @@ package peertls
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/x509"
@@ -79,9 +80,16 @@ func verifyCertSignature(parentCert, childCert *x509.Certificate) error {
 }
 
 func verifySignature(signedData []byte, data []byte, pubKey crypto.PublicKey) error {
+	if key, ok := pubKey.(ed25519.PublicKey); ok {
+		if len(key) != ed25519.PublicKeySize || !ed25519.Verify(key, data, signedData) {
+			return ErrVerifySignature.New("signature is not valid")
+		}
+		return nil
+	}
+
 	key, ok := pubKey.(*ecdsa.PublicKey)
 	if !ok {
-		return ErrUnsupportedKey.New("%T", key)
+		return ErrUnsupportedKey.New("%T", pubKey)
 	}
 
 	signature := new(ECDSASignature)
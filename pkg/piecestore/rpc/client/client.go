@@ -5,15 +5,16 @@ package client
 
 import (
 	"bufio"
+	"bytes"
 	"crypto"
-	"crypto/ecdsa"
+	"crypto/sha256"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"time"
 
-	"github.com/gtank/cryptopasta"
+	"github.com/gogo/protobuf/proto"
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
 	"golang.org/x/net/context"
@@ -21,6 +22,7 @@ import (
 
 	"storj.io/storj/pkg/node"
 	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/peertls"
 	"storj.io/storj/pkg/ranger"
 )
 
@@ -39,7 +41,7 @@ var (
 // PSClient is an interface describing the functions for interacting with piecestore nodes
 type PSClient interface {
 	Meta(ctx context.Context, id PieceID) (*pb.PieceSummary, error)
-	Put(ctx context.Context, id PieceID, data io.Reader, ttl time.Time, ba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) error
+	Put(ctx context.Context, id PieceID, data io.Reader, ttl time.Time, ba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (*pb.PieceHash, error)
 	Get(ctx context.Context, id PieceID, size int64, ba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (ranger.Ranger, error)
 	Delete(ctx context.Context, pieceID PieceID, authorization *pb.SignedMessage) error
 	Stats(ctx context.Context) (*pb.StatSummary, error)
@@ -53,6 +55,7 @@ type Client struct {
 	prikey           crypto.PrivateKey         // Uplink private key
 	bandwidthMsgSize int                       // max bandwidth message size in bytes
 	nodeID           *node.ID                  // Storage node being connected to
+	release          func() error              // Returns a pooled connection, if set
 }
 
 // NewPSClient initilizes a PSClient
@@ -92,8 +95,24 @@ func NewCustomRoute(route pb.PieceStoreRoutesClient, nodeID *node.ID, bandwidthM
 	}, nil
 }
 
+// NewPooledPSClient initializes a PSClient on a connection lent out by a
+// connection pool. Closing the client calls release instead of closing the
+// connection.
+func NewPooledPSClient(conn *grpc.ClientConn, release func() error, nodeID *node.ID, bandwidthMsgSize int, prikey crypto.PrivateKey) (PSClient, error) {
+	client, err := NewCustomRoute(pb.NewPieceStoreRoutesClient(conn), nodeID, bandwidthMsgSize, prikey)
+	if err != nil {
+		return nil, err
+	}
+	client.conn = conn
+	client.release = release
+	return client, nil
+}
+
 // Close closes the connection with piecestore
 func (client *Client) Close() error {
+	if client.release != nil {
+		return client.release()
+	}
 	return client.conn.Close()
 }
 
@@ -102,11 +121,12 @@ func (client *Client) Meta(ctx context.Context, id PieceID) (*pb.PieceSummary, e
 	return client.route.Piece(ctx, &pb.PieceId{Id: id.String()})
 }
 
-// Put uploads a Piece to a piece store Server
-func (client *Client) Put(ctx context.Context, id PieceID, data io.Reader, ttl time.Time, ba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) error {
+// Put uploads a Piece to a piece store Server, and returns the receipt
+// signed by the server for the piece, once its hash is verified
+func (client *Client) Put(ctx context.Context, id PieceID, data io.Reader, ttl time.Time, ba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (*pb.PieceHash, error) {
 	stream, err := client.route.Store(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	msg := &pb.PieceStore{
@@ -118,7 +138,7 @@ func (client *Client) Put(ctx context.Context, id PieceID, data io.Reader, ttl t
 			zap.S().Errorf("error closing stream %s :: %v.Send() = %v", closeErr, stream, closeErr)
 		}
 
-		return fmt.Errorf("%v.Send() = %v", stream, err)
+		return nil, fmt.Errorf("%v.Send() = %v", stream, err)
 	}
 
 	writer := &StreamWriter{signer: client, stream: stream, pba: ba}
@@ -131,20 +151,48 @@ func (client *Client) Put(ctx context.Context, id PieceID, data io.Reader, ttl t
 
 	bufw := bufio.NewWriterSize(writer, 32*1024)
 
-	_, err = io.Copy(bufw, data)
+	hash := sha256.New()
+	_, err = io.Copy(bufw, io.TeeReader(data, hash))
 	if err == io.ErrUnexpectedEOF {
 		_ = writer.Close()
 		zap.S().Infof("Node cut from upload due to slow connection. Deleting piece %s...", id)
 		deleteErr := client.Delete(ctx, id, authorization)
 		if deleteErr != nil {
-			return deleteErr
+			return nil, deleteErr
 		}
 	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return bufw.Flush()
+	if err = bufw.Flush(); err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+
+	pieceHash := writer.reply.GetPieceHash()
+	if err = verifyPieceHash(pieceHash, id, hash.Sum(nil), writer.totalWritten); err != nil {
+		return nil, err
+	}
+	return pieceHash, nil
+}
+
+// verifyPieceHash checks that the receipt pieceHash is the one of the piece
+// with id, size and hash
+func verifyPieceHash(pieceHash *pb.PieceHash, id PieceID, hash []byte, size int64) error {
+	if pieceHash == nil {
+		return ClientError.New("no receipt for piece %s", id)
+	}
+	data := &pb.PieceHash_Data{}
+	if err := proto.Unmarshal(pieceHash.GetData(), data); err != nil {
+		return ClientError.Wrap(err)
+	}
+	if data.GetPieceId() != id.String() || data.GetSize() != size || !bytes.Equal(data.GetHash(), hash) {
+		return ClientError.New("receipt doesn't match piece %s", id)
+	}
+	return nil
 }
 
 // Get begins downloading a Piece from a piece store Server
@@ -179,5 +227,5 @@ func (client *Client) sign(msg []byte) (signature []byte, err error) {
 	}
 
 	// use c.pkey to sign msg
-	return cryptopasta.Sign(msg, client.prikey.(*ecdsa.PrivateKey))
+	return peertls.SignMessage(client.prikey, msg)
 }
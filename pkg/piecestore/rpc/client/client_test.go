@@ -0,0 +1,36 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package client
+
+import (
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/pb"
+)
+
+func TestVerifyPieceHash(t *testing.T) {
+	id := NewPieceID()
+	hash := []byte("hash")
+
+	receipt := func(data *pb.PieceHash_Data) *pb.PieceHash {
+		serialized, err := proto.Marshal(data)
+		assert.NoError(t, err)
+		return &pb.PieceHash{Data: serialized, Signature: []byte("signature")}
+	}
+
+	assert.NoError(t, verifyPieceHash(receipt(&pb.PieceHash_Data{PieceId: id.String(), Hash: hash, Size: 10}), id, hash, 10))
+
+	for i, pieceHash := range []*pb.PieceHash{
+		nil,
+		{Data: []byte("invalid")},
+		receipt(&pb.PieceHash_Data{PieceId: NewPieceID().String(), Hash: hash, Size: 10}),
+		receipt(&pb.PieceHash_Data{PieceId: id.String(), Hash: []byte("other hash"), Size: 10}),
+		receipt(&pb.PieceHash_Data{PieceId: id.String(), Hash: hash, Size: 9}),
+	} {
+		assert.True(t, ClientError.Has(verifyPieceHash(pieceHash, id, hash, 10)), "case %d", i)
+	}
+}
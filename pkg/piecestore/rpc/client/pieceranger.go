@@ -68,5 +68,6 @@ func (r *pieceRanger) Range(ctx context.Context, offset, length int64) (io.ReadC
 		return nil, err
 	}
 
-	return NewStreamReader(r.c, r.stream, r.pba, r.size), nil
+	// bandwidth is allocated just for the range, not for the whole piece
+	return NewStreamReader(r.c, r.stream, r.pba, length), nil
 }
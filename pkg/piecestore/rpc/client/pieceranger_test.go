@@ -13,6 +13,7 @@ import (
 	"io/ioutil"
 	"testing"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
@@ -163,3 +164,48 @@ func TestPieceRangerSize(t *testing.T) {
 		}
 	}
 }
+
+func TestPieceRangerAllocatesRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	route := pb.NewMockPieceStoreRoutesClient(ctrl)
+	stream := pb.NewMockPieceStoreRoutes_RetrieveClient(ctrl)
+	pid := NewPieceID()
+	data := make([]byte, 10)
+
+	allocated := make(chan int64, 1)
+	stream.EXPECT().Send(&pb.PieceRetrieval{
+		PieceData: &pb.PieceRetrieval_PieceData{
+			Id: pid.String(), Size: int64(len(data)), Offset: 1000,
+		},
+	}).Return(nil)
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg *pb.PieceRetrieval) error {
+		allocation := &pb.RenterBandwidthAllocation_Data{}
+		if err := proto.Unmarshal(msg.GetBandwidthallocation().GetData(), allocation); err != nil {
+			return err
+		}
+		allocated <- allocation.GetTotal()
+		return nil
+	})
+	stream.EXPECT().Recv().Return(&pb.PieceRetrievalStream{Size: int64(len(data)), Content: data}, nil)
+	stream.EXPECT().Recv().Return(&pb.PieceRetrievalStream{}, io.EOF)
+
+	ctx := context.Background()
+	c, err := NewCustomRoute(route, node.IDFromString("test-node-id-1234567"), 32*1024, priv)
+	assert.NoError(t, err)
+
+	// a range in a large piece allocates bandwidth just for the range
+	rr := PieceRangerSize(c, stream, pid, 1024*1024, &pb.PayerBandwidthAllocation{}, nil)
+	r, err := rr.Range(ctx, 1000, int64(len(data)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	got, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+	assert.Equal(t, int64(len(data)), <-allocated)
+}
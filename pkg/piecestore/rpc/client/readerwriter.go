@@ -20,6 +20,8 @@ type StreamWriter struct {
 	signer       *Client // We need this for signing
 	totalWritten int64
 	pba          *pb.PayerBandwidthAllocation
+	reply        *pb.PieceStoreSummary
+	closed       bool
 }
 
 // Write Piece data to a piece store server upload stream
@@ -58,14 +60,20 @@ func (s *StreamWriter) Write(b []byte) (int, error) {
 	return len(b), nil
 }
 
-// Close the piece store Write Stream
+// Close the piece store Write Stream. Closing it again does nothing.
 func (s *StreamWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
 	reply, err := s.stream.CloseAndRecv()
 	if err != nil {
 		return err
 	}
 
 	log.Printf("Route summary: %v", reply)
+	s.reply = reply
 
 	return nil
 }
@@ -17,12 +17,15 @@ import (
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/piecestore/rpc/server/psdb"
 	"storj.io/storj/pkg/provider"
+	"storj.io/storj/pkg/transport"
+	"storj.io/storj/pkg/trust"
 	"storj.io/storj/pkg/utils"
 )
 
 var (
 	defaultCheckInterval = flag.Duration("piecestore.agreementsender.check_interval", time.Hour, "number of seconds to sleep between agreement checks")
 	defaultOverlayAddr   = flag.String("piecestore.agreementsender.overlay_addr", "127.0.0.1:7777", "Overlay Address")
+	defaultDialTimeout   = flag.Duration("piecestore.agreementsender.dial_timeout", 20*time.Second, "how long to wait for a connection to a satellite")
 
 	// ASError wraps errors returned from agreementsender package
 	ASError = errs.Class("agreement sender error")
@@ -30,20 +33,32 @@ var (
 
 // AgreementSender maintains variables required for reading bandwidth agreements from a DB and sending them to a Payers
 type AgreementSender struct {
-	DB       *psdb.DB
-	overlay  overlay.Client
-	identity *provider.FullIdentity
-	errs     []error
+	DB        *psdb.DB
+	overlay   overlay.Client
+	identity  *provider.FullIdentity
+	transport *transport.Transport
+	trust     *trust.Pool
+	errs      []error
 }
 
-// Initialize the Agreement Sender
-func Initialize(DB *psdb.DB, identity *provider.FullIdentity) (*AgreementSender, error) {
+// Initialize the Agreement Sender, which only sends agreements to the
+// satellites trusted by pool
+func Initialize(DB *psdb.DB, identity *provider.FullIdentity, pool *trust.Pool) (*AgreementSender, error) {
 	overlay, err := overlay.NewOverlayClient(identity, *defaultOverlayAddr)
 	if err != nil {
 		return nil, err
 	}
 
-	return &AgreementSender{DB: DB, identity: identity, overlay: overlay}, nil
+	transportConfig := transport.DefaultConfig
+	transportConfig.DialTimeout = *defaultDialTimeout
+
+	return &AgreementSender{
+		DB:        DB,
+		identity:  identity,
+		transport: transportConfig.NewClient(identity),
+		trust:     pool,
+		overlay:   overlay,
+	}, nil
 }
 
 // Run the afreement sender with a context to cehck for cancel
@@ -82,21 +97,13 @@ func (as *AgreementSender) Run(ctx context.Context) error {
 			go func() {
 				zap.S().Info("Sending %v agreements to satellite %s\n", len(agreementGroup.agreements), agreementGroup.satellite)
 
-				// Get satellite ip from overlay by Lookup agreementGroup.satellite
-				satellite, err := as.overlay.Lookup(ctx, node.IDFromString(agreementGroup.satellite))
-				if err != nil {
-					zap.S().Error(err)
-					return
-				}
-
-				// Create client from satellite ip
-				identOpt, err := as.identity.DialOption()
-				if err != nil {
+				// the agreements are kept in case the satellite is trusted again
+				if err := as.trust.VerifyID(agreementGroup.satellite); err != nil {
 					zap.S().Error(err)
 					return
 				}
 
-				conn, err := grpc.Dial(satellite.GetAddress().String(), identOpt)
+				conn, err := as.dialSatellite(ctx, agreementGroup.satellite)
 				if err != nil {
 					zap.S().Error(err)
 					return
@@ -138,3 +145,22 @@ func (as *AgreementSender) Run(ctx context.Context) error {
 		}
 	}
 }
+
+// dialSatellite connects to the satellite with the given id, at its trusted
+// address if it has one and at the address the overlay knows otherwise
+func (as *AgreementSender) dialSatellite(ctx context.Context, id string) (*grpc.ClientConn, error) {
+	if satellite, ok := as.trust.Satellite(id); ok {
+		identOpt, err := as.identity.DialOptionID(satellite.ID)
+		if err != nil {
+			return nil, err
+		}
+		return as.transport.DialAddress(ctx, satellite.Address, identOpt)
+	}
+
+	// Get satellite ip from overlay by Lookup id
+	satellite, err := as.overlay.Lookup(ctx, node.IDFromString(id))
+	if err != nil {
+		return nil, err
+	}
+	return as.transport.DialNode(ctx, satellite)
+}
@@ -55,6 +55,9 @@ func NewStreamReader(s *Server, stream pb.PieceStoreRoutes_StoreServer) *StreamR
 			if err = s.verifySignature(stream.Context(), ba); err != nil {
 				return nil, err
 			}
+			if err = s.verifyPayer(ba); err != nil {
+				return nil, err
+			}
 
 			deserializedData := &pb.RenterBandwidthAllocation_Data{}
 			err = proto.Unmarshal(ba.GetData(), deserializedData)
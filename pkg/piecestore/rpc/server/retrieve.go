@@ -131,6 +131,10 @@ func (s *Server) retrieveData(ctx context.Context, stream pb.PieceStoreRoutes_Re
 				allocationTracking.Fail(err)
 				return
 			}
+			if err = s.verifyPayer(alloc); err != nil {
+				allocationTracking.Fail(err)
+				return
+			}
 
 			// TODO: break when lastTotal >= allocData.GetPayer_allocation().GetData().GetMax_size()
 
@@ -5,7 +5,6 @@ package server
 
 import (
 	"crypto"
-	"crypto/ecdsa"
 	"crypto/hmac"
 	"crypto/sha512"
 	"errors"
@@ -15,7 +14,7 @@ import (
 	"regexp"
 	"time"
 
-	"github.com/gtank/cryptopasta"
+	"github.com/gogo/protobuf/proto"
 	"github.com/mr-tron/base58/base58"
 	"github.com/shirou/gopsutil/disk"
 	"github.com/zeebo/errs"
@@ -30,6 +29,7 @@ import (
 	as "storj.io/storj/pkg/piecestore/rpc/server/agreementsender"
 	"storj.io/storj/pkg/piecestore/rpc/server/psdb"
 	"storj.io/storj/pkg/provider"
+	"storj.io/storj/pkg/trust"
 )
 
 var (
@@ -44,6 +44,7 @@ type Config struct {
 	Path               string `help:"path to store data in" default:"$CONFDIR"`
 	AllocatedDiskSpace int64  `help:"total allocated disk space, default(1GB)" default:"1073741824"`
 	AllocatedBandwidth int64  `help:"total allocated bandwidth, default(100GB)" default:"107374182400"`
+	Trust              trust.Config
 }
 
 // Run implements provider.Responsibility
@@ -57,10 +58,16 @@ func (c Config) Run(ctx context.Context, server *provider.Provider) (err error)
 		return err
 	}
 
+	s.trust, err = c.Trust.NewPool()
+	if err != nil {
+		return err
+	}
+	go s.trust.Run(ctx)
+
 	pb.RegisterPieceStoreRoutesServer(server.GRPC(), s)
 
 	// Run the agreement sender process
-	asProcess, err := as.Initialize(s.DB, server.Identity())
+	asProcess, err := as.Initialize(s.DB, server.Identity(), s.trust)
 	if err != nil {
 		return err
 	}
@@ -103,6 +110,7 @@ type Server struct {
 	totalAllocated   int64
 	totalBwAllocated int64
 	verifier         auth.SignedMessageVerifier
+	trust            *trust.Pool
 }
 
 // Initialize -- initializes a server struct
@@ -297,17 +305,36 @@ func (s *Server) verifySignature(ctx context.Context, ba *pb.RenterBandwidthAllo
 		return err
 	}
 
-	k, ok := pi.Leaf.PublicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return peertls.ErrUnsupportedKey.New("%T", pi.Leaf.PublicKey)
-	}
-
-	if ok := cryptopasta.Verify(ba.GetData(), ba.GetSignature(), k); !ok {
+	if err := peertls.VerifyMessage(pi.Leaf.PublicKey, ba.GetData(), ba.GetSignature()); err != nil {
+		if peertls.ErrUnsupportedKey.Has(err) {
+			return err
+		}
 		return ServerError.New("Failed to verify Signature")
 	}
 	return nil
 }
 
+// verifyPayer checks that the satellite paying for the bandwidth of ba is
+// trusted and signed its payer allocation. Allocations which name no
+// satellite are only accepted by nodes which trust every satellite.
+func (s *Server) verifyPayer(ba *pb.RenterBandwidthAllocation) error {
+	rbad := &pb.RenterBandwidthAllocation_Data{}
+	if err := proto.Unmarshal(ba.GetData(), rbad); err != nil {
+		return err
+	}
+	payer := rbad.GetPayerAllocation()
+	pbad := &pb.PayerBandwidthAllocation_Data{}
+	if err := proto.Unmarshal(payer.GetData(), pbad); err != nil {
+		return err
+	}
+	if len(pbad.GetSatelliteId()) > 0 || len(payer.GetSignature()) > 0 {
+		if _, err := auth.VerifyPayerAllocation(payer); err != nil {
+			return err
+		}
+	}
+	return s.trust.VerifyID(string(pbad.GetSatelliteId()))
+}
+
 func getBeginningOfMonth() time.Time {
 	t := time.Now()
 	y, m, _ := t.Date()
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -26,10 +27,13 @@ import (
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 
+	"storj.io/storj/pkg/auth"
 	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/peertls"
 	pstore "storj.io/storj/pkg/piecestore"
 	"storj.io/storj/pkg/piecestore/rpc/server/psdb"
 	"storj.io/storj/pkg/provider"
+	"storj.io/storj/pkg/trust"
 )
 
 var ctx = context.Background()
@@ -400,6 +404,17 @@ func TestStore(t *testing.T) {
 
 			assert.Equal(tt.message, resp.Message)
 			assert.Equal(tt.totalReceived, resp.TotalReceived)
+
+			// check the receipt of the piece signed by the node
+			pieceHash := &pb.PieceHash_Data{}
+			err = proto.Unmarshal(resp.GetPieceHash().GetData(), pieceHash)
+			assert.NoError(err)
+			hash := sha256.Sum256(tt.content)
+			assert.Equal(&pb.PieceHash_Data{PieceId: tt.id, Hash: hash[:], Size: tt.totalReceived}, pieceHash)
+
+			pubKey, err := peertls.PublicKey(TS.s.pkey)
+			assert.NoError(err)
+			assert.NoError(peertls.VerifyMessage(pubKey, resp.GetPieceHash().GetData(), resp.GetPieceHash().GetSignature()))
 		})
 	}
 }
@@ -478,6 +493,48 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestVerifyPayer(t *testing.T) {
+	newIdentity := func() *provider.FullIdentity {
+		ca, err := provider.NewTestCA(ctx)
+		assert.NoError(t, err)
+		identity, err := ca.NewIdentity()
+		assert.NoError(t, err)
+		return identity
+	}
+	trusted, untrusted := newIdentity(), newIdentity()
+
+	signed := func(identity *provider.FullIdentity) *pb.PayerBandwidthAllocation {
+		pba, err := auth.NewPayerAllocation(&pb.PayerBandwidthAllocation_Data{}, identity)
+		assert.NoError(t, err)
+		return pba
+	}
+	forged := signed(untrusted)
+	forged.Data, _ = proto.Marshal(&pb.PayerBandwidthAllocation_Data{SatelliteId: trusted.ID.Bytes()})
+	unnamed := &pb.PayerBandwidthAllocation{}
+
+	restricted := trust.NewPool([]trust.Satellite{{ID: trusted.ID.String(), Address: "127.0.0.1:7777"}}, "", "", nil)
+	for i, tt := range []struct {
+		pool  *trust.Pool
+		payer *pb.PayerBandwidthAllocation
+		err   bool
+	}{
+		{restricted, signed(trusted), false},
+		{restricted, signed(untrusted), true},
+		{restricted, forged, true},
+		{restricted, unnamed, true},
+		{nil, signed(untrusted), false},
+		{nil, forged, true},
+		{nil, unnamed, false},
+	} {
+		s := &Server{trust: tt.pool}
+		ba := &pb.RenterBandwidthAllocation{
+			Data: serializeData(&pb.RenterBandwidthAllocation_Data{PayerAllocation: tt.payer}),
+		}
+		err := s.verifyPayer(ba)
+		assert.Equal(t, tt.err, err != nil, "case %d: %v", i, err)
+	}
+}
+
 func newTestServerStruct(t *testing.T) (*Server, func()) {
 	tmp, err := ioutil.TempDir("", "storj-piecestore")
 	if err != nil {
@@ -550,6 +607,7 @@ func NewTestServer(t *testing.T) *TestServer {
 	check(err)
 
 	s, cleanup := newTestServerStruct(t)
+	s.pkey = fiS.Key
 	grpcs := grpc.NewServer(so)
 
 	k, ok := fiC.Key.(*ecdsa.PrivateKey)
@@ -5,12 +5,16 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"hash"
 	"io"
 	"log"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/zeebo/errs"
 
 	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/peertls"
 	pstore "storj.io/storj/pkg/piecestore"
 	"storj.io/storj/pkg/utils"
 )
@@ -54,7 +58,8 @@ func (s *Server) Store(reqStream pb.PieceStoreRoutes_StoreServer) (err error) {
 	if err != nil {
 		return err
 	}
-	total, err := s.storeData(ctx, reqStream, id)
+	hash := sha256.New()
+	total, err := s.storeData(ctx, reqStream, id, hash)
 	if err != nil {
 		return err
 	}
@@ -69,10 +74,35 @@ func (s *Server) Store(reqStream pb.PieceStoreRoutes_StoreServer) (err error) {
 	}
 	log.Printf("Successfully stored %s.", pd.GetId())
 
-	return reqStream.SendAndClose(&pb.PieceStoreSummary{Message: OK, TotalReceived: total})
+	pieceHash, err := s.signPieceHash(pd.GetId(), hash.Sum(nil), total)
+	if err != nil {
+		return StoreError.Wrap(err)
+	}
+
+	return reqStream.SendAndClose(&pb.PieceStoreSummary{Message: OK, TotalReceived: total, PieceHash: pieceHash})
+}
+
+// signPieceHash returns the receipt for the piece with id, which the uplink
+// keeps as the proof of what the node committed to store
+func (s *Server) signPieceHash(id string, hash []byte, size int64) (*pb.PieceHash, error) {
+	data, err := proto.Marshal(&pb.PieceHash_Data{
+		PieceId: id,
+		Hash:    hash,
+		Size:    size,
+	})
+	if err != nil {
+		return nil, err
+	}
+	signature, err := peertls.SignMessage(s.pkey, data)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PieceHash{Signature: signature, Data: data}, nil
 }
 
-func (s *Server) storeData(ctx context.Context, stream pb.PieceStoreRoutes_StoreServer, id string) (total int64, err error) {
+// storeData stores the piece data received from stream as id, and writes it
+// to h too
+func (s *Server) storeData(ctx context.Context, stream pb.PieceStoreRoutes_StoreServer, id string, h hash.Hash) (total int64, err error) {
 	defer mon.Task()(&ctx)(&err)
 
 	// Delete data if we error
@@ -101,7 +131,7 @@ func (s *Server) storeData(ctx context.Context, stream pb.PieceStoreRoutes_Store
 		}
 	}()
 
-	total, err = io.Copy(storeFile, reader)
+	total, err = io.Copy(io.MultiWriter(storeFile, h), reader)
 
 	if err != nil && err != io.EOF {
 		return 0, err
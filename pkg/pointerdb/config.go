@@ -6,14 +6,19 @@ package pointerdb
 import (
 	"context"
 
+	"github.com/mr-tron/base58/base58"
 	"go.uber.org/zap"
 
 	"storj.io/storj/pkg/overlay"
 	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/process"
 	"storj.io/storj/pkg/provider"
 	"storj.io/storj/pkg/utils"
 	"storj.io/storj/storage"
 	"storj.io/storj/storage/boltdb"
+	"storj.io/storj/storage/cachekv"
+	"storj.io/storj/storage/chunkkv"
+	"storj.io/storj/storage/encryptedkv"
 	"storj.io/storj/storage/postgreskv"
 	"storj.io/storj/storage/storelogger"
 )
@@ -34,9 +39,15 @@ type Config struct {
 	MinRemoteSegmentSize int    `default:"1240" help:"minimum remote segment size"`
 	MaxInlineSegmentSize int    `default:"8000" help:"maximum inline segment size"`
 	Overlay              bool   `default:"false" help:"toggle flag if overlay is enabled"`
+	APIKeySecret         string `default:"" help:"base58 encoded secret the api keys of uplinks are created with; if empty, api keys are compared to pointer-db.auth.api-key instead"`
+	PeerWhitelist        provider.PeerWhitelistConfig
+	Chunking             chunkkv.Config
+	EncryptAtRest        encryptedkv.Config
+	MemoryCache          cachekv.Config
 }
 
-func newKeyValueStore(dbURLString string) (db storage.KeyValueStore, err error) {
+// NewKeyValueStore opens the pointer store described by dbURLString
+func NewKeyValueStore(dbURLString string) (db storage.KeyValueStore, err error) {
 	dburl, err := utils.ParseURL(dbURLString)
 	if err != nil {
 		return nil, err
@@ -51,17 +62,48 @@ func newKeyValueStore(dbURLString string) (db storage.KeyValueStore, err error)
 	return db, err
 }
 
+// LoadAPIKeySecret returns the decoded api key secret, or nil if there is
+// none
+func (c Config) LoadAPIKeySecret() ([]byte, error) {
+	if c.APIKeySecret == "" {
+		return nil, nil
+	}
+	secret, err := base58.Decode(c.APIKeySecret)
+	if err != nil {
+		return nil, Error.New("invalid api key secret: %v", err)
+	}
+	return secret, nil
+}
+
 // Run implements the provider.Responsibility interface
 func (c Config) Run(ctx context.Context, server *provider.Provider) error {
-	db, err := newKeyValueStore(c.DatabaseURL)
+	db, err := NewKeyValueStore(c.DatabaseURL)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = db.Close() }()
+	defer process.RegisterHealthCheck("pointerdb", db.Ping)()
+
+	chunked, err := c.Chunking.Wrap(db)
+	if err != nil {
+		return err
+	}
+	store, err := c.EncryptAtRest.Wrap(storelogger.New(zap.L(), chunked))
+	if err != nil {
+		return err
+	}
+	store = c.MemoryCache.Wrap(store)
 
 	cache := overlay.LoadFromContext(ctx)
-	dblogged := storelogger.New(zap.L(), db)
-	s := NewServer(dblogged, cache, zap.L(), c, server.Identity())
+	s := NewServer(store, cache, zap.L(), c, server.Identity())
+	s.whitelist, err = c.PeerWhitelist.Load()
+	if err != nil {
+		return err
+	}
+	s.apiKeySecret, err = c.LoadAPIKeySecret()
+	if err != nil {
+		return err
+	}
 	pb.RegisterPointerDBServer(server.GRPC(), s)
 	// add the server to the context
 	ctx = context.WithValue(ctx, ctxKey, s)
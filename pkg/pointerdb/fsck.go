@@ -0,0 +1,222 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package pointerdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+
+	"storj.io/storj/pkg/overlay"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/storage"
+)
+
+// SegmentHealth describes the state of a segment found during a consistency check
+type SegmentHealth int
+
+const (
+	// SegmentHealthy means enough pieces are online to stay above the repair threshold
+	SegmentHealthy SegmentHealth = iota
+	// SegmentRepairable means the segment dropped below the repair threshold
+	// but can still be reconstructed
+	SegmentRepairable
+	// SegmentIrreparable means too few pieces are online to reconstruct the segment
+	SegmentIrreparable
+	// SegmentInvalid means the pointer itself is malformed
+	SegmentInvalid
+)
+
+// String implements fmt.Stringer
+func (h SegmentHealth) String() string {
+	switch h {
+	case SegmentHealthy:
+		return "healthy"
+	case SegmentRepairable:
+		return "repairable"
+	case SegmentIrreparable:
+		return "irreparable"
+	case SegmentInvalid:
+		return "invalid"
+	default:
+		return fmt.Sprintf("SegmentHealth(%d)", int(h))
+	}
+}
+
+// SegmentReport is the result of checking a single pointer
+type SegmentReport struct {
+	Path         string
+	Health       SegmentHealth
+	Online       int
+	Total        int
+	MissingNodes []string
+	Problems     []string
+}
+
+// FsckReport summarizes a consistency check over all pointers
+type FsckReport struct {
+	Checked     int
+	Inline      int
+	Healthy     int
+	Repairable  int
+	Irreparable int
+	Invalid     int
+
+	// Segments contains every remote segment that is not healthy
+	Segments []SegmentReport
+}
+
+// Fsck iterates over all pointers in db, checks referenced nodes against the
+// overlay cache and validates redundancy parameters. It never modifies db.
+func Fsck(ctx context.Context, db storage.KeyValueStore, cache *overlay.Cache) (report *FsckReport, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	report = &FsckReport{}
+	err = db.Iterate(storage.IterateOptions{Recurse: true},
+		func(it storage.Iterator) error {
+			var item storage.ListItem
+			for it.Next(&item) {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
+				segment, err := checkPointer(ctx, cache, item)
+				if err != nil {
+					return err
+				}
+				report.add(segment)
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return report, nil
+}
+
+func (report *FsckReport) add(segment *SegmentReport) {
+	report.Checked++
+	if segment == nil {
+		report.Inline++
+		return
+	}
+
+	switch segment.Health {
+	case SegmentHealthy:
+		report.Healthy++
+		return
+	case SegmentRepairable:
+		report.Repairable++
+	case SegmentIrreparable:
+		report.Irreparable++
+	case SegmentInvalid:
+		report.Invalid++
+	}
+	report.Segments = append(report.Segments, *segment)
+}
+
+// checkPointer returns nil for inline segments
+func checkPointer(ctx context.Context, cache *overlay.Cache, item storage.ListItem) (*SegmentReport, error) {
+	segment := &SegmentReport{Path: item.Key.String()}
+
+	pointer := &pb.Pointer{}
+	if err := proto.Unmarshal(item.Value, pointer); err != nil {
+		segment.Health = SegmentInvalid
+		segment.Problems = append(segment.Problems, fmt.Sprintf("could not unmarshal pointer: %v", err))
+		return segment, nil
+	}
+
+	if pointer.GetType() == pb.Pointer_INLINE {
+		return nil, nil
+	}
+
+	remote := pointer.GetRemote()
+	if remote == nil {
+		segment.Health = SegmentInvalid
+		segment.Problems = append(segment.Problems, "remote pointer without remote segment")
+		return segment, nil
+	}
+
+	segment.Problems = append(segment.Problems, validateRedundancy(remote.GetRedundancy())...)
+	segment.Problems = append(segment.Problems, validatePieces(remote)...)
+	if len(segment.Problems) > 0 {
+		segment.Health = SegmentInvalid
+		return segment, nil
+	}
+
+	pieces := remote.GetRemotePieces()
+	segment.Total = len(pieces)
+	if len(pieces) > 0 {
+		var nodeIDs []string
+		for _, piece := range pieces {
+			nodeIDs = append(nodeIDs, piece.NodeId)
+		}
+		nodes, err := cache.GetAll(ctx, nodeIDs)
+		if err != nil {
+			return nil, Error.New("error looking up nodes for %s: %v", segment.Path, err)
+		}
+		for i, n := range nodes {
+			if n == nil {
+				segment.MissingNodes = append(segment.MissingNodes, nodeIDs[i])
+				continue
+			}
+			segment.Online++
+		}
+	}
+
+	redundancy := remote.GetRedundancy()
+	switch {
+	case int32(segment.Online) < redundancy.GetMinReq():
+		segment.Health = SegmentIrreparable
+	case int32(segment.Online) < redundancy.GetRepairThreshold():
+		segment.Health = SegmentRepairable
+	default:
+		segment.Health = SegmentHealthy
+	}
+	return segment, nil
+}
+
+func validateRedundancy(rs *pb.RedundancyScheme) (problems []string) {
+	if rs == nil {
+		return []string{"missing redundancy scheme"}
+	}
+	if rs.GetMinReq() <= 0 {
+		problems = append(problems, fmt.Sprintf("invalid min_req %d", rs.GetMinReq()))
+	}
+	if rs.GetTotal() < rs.GetMinReq() {
+		problems = append(problems, fmt.Sprintf("total %d is less than min_req %d", rs.GetTotal(), rs.GetMinReq()))
+	}
+	if rs.GetRepairThreshold() < rs.GetMinReq() || rs.GetRepairThreshold() > rs.GetTotal() {
+		problems = append(problems, fmt.Sprintf("repair_threshold %d is outside of [%d, %d]", rs.GetRepairThreshold(), rs.GetMinReq(), rs.GetTotal()))
+	}
+	if rs.GetSuccessThreshold() < rs.GetRepairThreshold() || rs.GetSuccessThreshold() > rs.GetTotal() {
+		problems = append(problems, fmt.Sprintf("success_threshold %d is outside of [%d, %d]", rs.GetSuccessThreshold(), rs.GetRepairThreshold(), rs.GetTotal()))
+	}
+	if rs.GetErasureShareSize() <= 0 {
+		problems = append(problems, fmt.Sprintf("invalid erasure_share_size %d", rs.GetErasureShareSize()))
+	}
+	return problems
+}
+
+func validatePieces(remote *pb.RemoteSegment) (problems []string) {
+	if remote.GetPieceId() == "" {
+		problems = append(problems, "missing piece id")
+	}
+	total := remote.GetRedundancy().GetTotal()
+	seen := map[int32]bool{}
+	for _, piece := range remote.GetRemotePieces() {
+		if piece.GetNodeId() == "" {
+			problems = append(problems, fmt.Sprintf("piece %d has no node id", piece.GetPieceNum()))
+		}
+		if piece.GetPieceNum() < 0 || piece.GetPieceNum() >= total {
+			problems = append(problems, fmt.Sprintf("piece number %d is outside of [0, %d)", piece.GetPieceNum(), total))
+		}
+		if seen[piece.GetPieceNum()] {
+			problems = append(problems, fmt.Sprintf("duplicate piece number %d", piece.GetPieceNum()))
+		}
+		seen[piece.GetPieceNum()] = true
+	}
+	return problems
+}
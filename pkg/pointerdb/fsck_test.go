@@ -0,0 +1,102 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package pointerdb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/overlay"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+)
+
+func TestFsck(t *testing.T) {
+	ctx := context.Background()
+
+	cache := overlay.NewOverlayCache(teststore.New(), nil)
+	for _, id := range []string{"a", "b", "c"} {
+		assert.NoError(t, cache.Put(id, pb.Node{Id: id, Address: &pb.NodeAddress{Address: id}}))
+	}
+
+	redundancy := &pb.RedundancyScheme{
+		MinReq:           2,
+		RepairThreshold:  3,
+		SuccessThreshold: 4,
+		Total:            4,
+		ErasureShareSize: 1024,
+	}
+	remote := func(nodeIDs ...string) *pb.Pointer {
+		var pieces []*pb.RemotePiece
+		for i, id := range nodeIDs {
+			pieces = append(pieces, &pb.RemotePiece{PieceNum: int32(i), NodeId: id})
+		}
+		return &pb.Pointer{
+			Type: pb.Pointer_REMOTE,
+			Remote: &pb.RemoteSegment{
+				Redundancy:   redundancy,
+				PieceId:      "piece",
+				RemotePieces: pieces,
+			},
+		}
+	}
+
+	db := teststore.New()
+	for path, pointer := range map[string]*pb.Pointer{
+		"healthy":     remote("a", "b", "c", "x"),
+		"repairable":  remote("a", "b", "x", "y"),
+		"irreparable": remote("a", "x", "y", "z"),
+		"invalid":     {Type: pb.Pointer_REMOTE},
+		"inline":      {Type: pb.Pointer_INLINE, InlineSegment: []byte("data")},
+	} {
+		data, err := proto.Marshal(pointer)
+		assert.NoError(t, err)
+		assert.NoError(t, db.Put(storage.Key(path), data))
+	}
+
+	report, err := Fsck(ctx, db, cache)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 5, report.Checked)
+	assert.Equal(t, 1, report.Inline)
+	assert.Equal(t, 1, report.Healthy)
+	assert.Equal(t, 1, report.Repairable)
+	assert.Equal(t, 1, report.Irreparable)
+	assert.Equal(t, 1, report.Invalid)
+
+	health := map[string]SegmentHealth{}
+	for _, segment := range report.Segments {
+		health[segment.Path] = segment.Health
+	}
+	assert.Equal(t, map[string]SegmentHealth{
+		"repairable":  SegmentRepairable,
+		"irreparable": SegmentIrreparable,
+		"invalid":     SegmentInvalid,
+	}, health)
+
+	// fsck must not modify the pointers
+	keys, err := db.List(nil, 0)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 5)
+}
+
+func TestValidateRedundancy(t *testing.T) {
+	for i, tt := range []struct {
+		rs       *pb.RedundancyScheme
+		problems int
+	}{
+		{nil, 1},
+		{&pb.RedundancyScheme{MinReq: 2, RepairThreshold: 3, SuccessThreshold: 4, Total: 4, ErasureShareSize: 1}, 0},
+		{&pb.RedundancyScheme{MinReq: 2, RepairThreshold: 3, SuccessThreshold: 4, Total: 4}, 1},
+		{&pb.RedundancyScheme{MinReq: 5, RepairThreshold: 5, SuccessThreshold: 5, Total: 4, ErasureShareSize: 1}, 3},
+	} {
+		problems := validateRedundancy(tt.rs)
+		assert.Len(t, problems, tt.problems, fmt.Sprintf("Test case #%d: %v", i, problems))
+	}
+}
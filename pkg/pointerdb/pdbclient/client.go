@@ -30,6 +30,7 @@ var (
 // PointerDB creates a grpcClient
 type PointerDB struct {
 	grpcClient      pb.PointerDBClient
+	conn            *grpc.ClientConn
 	signatureHeader *metadata.MD
 	peer            *peer.Peer
 	pba             *pb.PayerBandwidthAllocation
@@ -55,7 +56,8 @@ type Client interface {
 	Put(ctx context.Context, path storj.Path, pointer *pb.Pointer) error
 	Get(ctx context.Context, path storj.Path) (*pb.Pointer, error)
 	List(ctx context.Context, prefix, startAfter, endBefore storj.Path, recursive bool, limit int, metaFlags uint32) (items []ListItem, more bool, err error)
-	Delete(ctx context.Context, path storj.Path) error
+	Delete(ctx context.Context, path storj.Path) (shared bool, err error)
+	Copy(ctx context.Context, src, dst storj.Path, metadata []byte) error
 
 	SignedMessage() (*pb.SignedMessage, error)
 	PayerBandwidthAllocation() *pb.PayerBandwidthAllocation
@@ -71,27 +73,25 @@ func NewClient(identity *provider.FullIdentity, address string, APIKey string) (
 	signatureHeader := &metadata.MD{}
 	peer := &peer.Peer{}
 	apiKeyInjector := grpcauth.NewAPIKeyInjector(APIKey, grpc.Header(signatureHeader), grpc.Peer(peer))
-	c, err := clientConnection(address, dialOpt, grpc.WithUnaryInterceptor(apiKeyInjector))
-
+	interceptor := provider.ChainUnaryClientInterceptors(provider.TraceUnaryClient, apiKeyInjector)
+	conn, err := grpc.Dial(address, dialOpt, grpc.WithUnaryInterceptor(interceptor))
 	if err != nil {
 		return nil, err
 	}
-	return &PointerDB{grpcClient: c, signatureHeader: signatureHeader, peer: peer}, nil
+	return &PointerDB{grpcClient: pb.NewPointerDBClient(conn), conn: conn, signatureHeader: signatureHeader, peer: peer}, nil
 }
 
-// a compiler trick to make sure *PointerDB implements Client
-var _ Client = (*PointerDB)(nil)
-
-// ClientConnection makes a server connection
-func clientConnection(serverAddr string, opts ...grpc.DialOption) (pb.PointerDBClient, error) {
-	conn, err := grpc.Dial(serverAddr, opts...)
-
-	if err != nil {
-		return nil, err
+// Close closes the connection of the client
+func (pdb *PointerDB) Close() error {
+	if pdb.conn == nil {
+		return nil
 	}
-	return pb.NewPointerDBClient(conn), nil
+	return pdb.conn.Close()
 }
 
+// a compiler trick to make sure *PointerDB implements Client
+var _ Client = (*PointerDB)(nil)
+
 // Put is the interface to make a PUT request, needs Pointer and APIKey
 func (pdb *PointerDB) Put(ctx context.Context, path storj.Path, pointer *pb.Pointer) (err error) {
 	defer mon.Task()(&ctx)(&err)
@@ -147,11 +147,26 @@ func (pdb *PointerDB) List(ctx context.Context, prefix, startAfter, endBefore st
 	return items, res.GetMore(), nil
 }
 
-// Delete is the interface to make a Delete request, needs Path and APIKey
-func (pdb *PointerDB) Delete(ctx context.Context, path storj.Path) (err error) {
+// Delete is the interface to make a Delete request, needs Path and APIKey.
+// It returns whether the pieces of the deleted pointer are still shared by
+// copies of it.
+func (pdb *PointerDB) Delete(ctx context.Context, path storj.Path) (shared bool, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	res, err := pdb.grpcClient.Delete(ctx, &pb.DeleteRequest{Path: path})
+
+	return res.GetShared(), err
+}
+
+// Copy is the interface to make a Copy request, copying the pointer at src
+// to dst with metadata
+func (pdb *PointerDB) Copy(ctx context.Context, src, dst storj.Path, metadata []byte) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	_, err = pdb.grpcClient.Delete(ctx, &pb.DeleteRequest{Path: path})
+	_, err = pdb.grpcClient.Copy(ctx, &pb.CopyRequest{SrcPath: src, DstPath: dst, Metadata: metadata})
+	if status.Code(err) == codes.NotFound {
+		return storage.ErrKeyNotFound.Wrap(err)
+	}
 
 	return err
 }
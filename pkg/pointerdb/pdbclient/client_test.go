@@ -288,7 +288,7 @@ func TestDelete(t *testing.T) {
 
 		gc.EXPECT().Delete(gomock.Any(), &deleteRequest).Return(nil, tt.err)
 
-		err := pdb.Delete(ctx, tt.path)
+		_, err := pdb.Delete(ctx, tt.path)
 
 		if err != nil {
 			assert.EqualError(t, err, tt.errString, errTag)
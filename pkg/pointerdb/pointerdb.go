@@ -6,6 +6,8 @@ package pointerdb
 import (
 	"context"
 	"encoding/base64"
+	"sync"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
@@ -18,11 +20,13 @@ import (
 	monkit "gopkg.in/spacemonkeygo/monkit.v2"
 
 	"storj.io/storj/pkg/auth"
+	"storj.io/storj/pkg/macaroon"
 	"storj.io/storj/pkg/overlay"
 	"storj.io/storj/pkg/pb"
 	pointerdbAuth "storj.io/storj/pkg/pointerdb/auth"
 	"storj.io/storj/pkg/provider"
 	"storj.io/storj/pkg/storage/meta"
+	"storj.io/storj/pkg/storj"
 	"storj.io/storj/storage"
 )
 
@@ -38,6 +42,14 @@ type Server struct {
 	config   Config
 	cache    *overlay.Cache
 	identity *provider.FullIdentity
+	// whitelist restricts the peers allowed to use the service
+	whitelist *provider.PeerWhitelist
+	// apiKeySecret is the secret the api keys are created with
+	apiKeySecret []byte
+	// copies guards the pointers sharing pieces with copies: copying and
+	// updating such pointers locks it, while updating other pointers only
+	// read locks it
+	copies sync.RWMutex
 }
 
 // NewServer creates instance of Server
@@ -51,15 +63,63 @@ func NewServer(db storage.KeyValueStore, cache *overlay.Cache, logger *zap.Logge
 	}
 }
 
-func (s *Server) validateAuth(ctx context.Context) error {
+func (s *Server) validateAuth(ctx context.Context, op macaroon.ActionType, path string) error {
+	if err := s.whitelist.Verify(ctx); err != nil {
+		s.logger.Error("unauthorized peer: ", zap.Error(err))
+		return status.Errorf(codes.PermissionDenied, "Peer not allowed")
+	}
 	APIKey, ok := auth.GetAPIKey(ctx)
-	if !ok || !pointerdbAuth.ValidateAPIKey(string(APIKey)) {
+	if !ok || !s.validateAPIKey(string(APIKey), op, path) {
 		s.logger.Error("unauthorized request: ", zap.Error(status.Errorf(codes.Unauthenticated, "Invalid API credential")))
 		return status.Errorf(codes.Unauthenticated, "Invalid API credential")
 	}
 	return nil
 }
 
+// validateAPIKey returns whether key allows the operation op on path. Without
+// an api key secret, key is compared to the static api key instead.
+func (s *Server) validateAPIKey(key string, op macaroon.ActionType, path string) bool {
+	if len(s.apiKeySecret) == 0 {
+		return pointerdbAuth.ValidateAPIKey(key)
+	}
+
+	apiKey, err := macaroon.ParseAPIKey(key)
+	if err != nil {
+		return false
+	}
+	err = apiKey.Check(s.apiKeySecret, macaroon.Action{
+		Op:     op,
+		Bucket: bucketOf(path),
+		Path:   pathOf(path),
+		Time:   time.Now(),
+	})
+	if err != nil {
+		s.logger.Debug("api key check failed", zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// bucketOf returns the bucket of a segment path, which starts with the
+// segment and the bucket, or nil if the path has no bucket
+func bucketOf(path string) []byte {
+	comps := storj.SplitPath(path)
+	if len(comps) < 2 {
+		return nil
+	}
+	return []byte(comps[1])
+}
+
+// pathOf returns a segment path without the segment, or nil if the path has
+// no bucket
+func pathOf(path string) []byte {
+	comps := storj.SplitPath(path)
+	if len(comps) < 2 {
+		return nil
+	}
+	return []byte(storj.JoinPaths(comps[1:]...))
+}
+
 func (s *Server) appendSignature(ctx context.Context) error {
 	signature, err := auth.GenerateSignature(s.identity.ID.Bytes(), s.identity)
 	if err != nil {
@@ -103,23 +163,34 @@ func (s *Server) Put(ctx context.Context, req *pb.PutRequest) (resp *pb.PutRespo
 		return nil, status.Errorf(codes.InvalidArgument, err.Error())
 	}
 
-	if err = s.validateAuth(ctx); err != nil {
+	if err = s.validateAuth(ctx, macaroon.ActionWrite, req.GetPath()); err != nil {
 		return nil, err
 	}
 
 	// Update the pointer with the creation date
-	req.GetPointer().CreationDate = ptypes.TimestampNow()
+	pointer := req.GetPointer()
+	pointer.CreationDate = ptypes.TimestampNow()
+	// the copies sharing the pieces are only known by pointerdb
+	if pointer.GetRemote() != nil {
+		pointer.Remote.Copies = nil
+	}
 
-	pointerBytes, err := proto.Marshal(req.GetPointer())
+	unlock, err := s.lockCopies(req.GetPath())
 	if err != nil {
-		s.logger.Error("err marshaling pointer", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, err.Error())
+		s.logger.Error("err getting pointer", zap.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
 	}
+	defer unlock()
 
 	// TODO(kaloyan): make sure that we know we are overwriting the pointer!
 	// In such case we should delete the pieces of the old segment if it was
 	// a remote one.
-	if err = s.DB.Put([]byte(req.GetPath()), pointerBytes); err != nil {
+	if err = s.replace(req.GetPath(), pointer); err != nil {
+		s.logger.Error("err replacing pointer", zap.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = s.putPointer(req.GetPath(), pointer); err != nil {
 		s.logger.Error("err putting pointer", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, err.Error())
 	}
@@ -133,7 +204,7 @@ func (s *Server) Get(ctx context.Context, req *pb.GetRequest) (resp *pb.GetRespo
 
 	s.logger.Debug("entering pointerdb get")
 
-	if err = s.validateAuth(ctx); err != nil {
+	if err = s.validateAuth(ctx, macaroon.ActionRead, req.GetPath()); err != nil {
 		return nil, err
 	}
 
@@ -196,7 +267,7 @@ func (s *Server) Get(ctx context.Context, req *pb.GetRequest) (resp *pb.GetRespo
 func (s *Server) List(ctx context.Context, req *pb.ListRequest) (resp *pb.ListResponse, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	if err = s.validateAuth(ctx); err != nil {
+	if err = s.validateAuth(ctx, macaroon.ActionList, req.GetPrefix()); err != nil {
 		return nil, err
 	}
 
@@ -208,21 +279,65 @@ func (s *Server) List(ctx context.Context, req *pb.ListRequest) (resp *pb.ListRe
 		}
 	}
 
-	rawItems, more, err := storage.ListV2(s.DB, storage.ListOptions{
-		Prefix:       prefix, //storage.Key(req.Prefix),
-		StartAfter:   storage.Key(req.StartAfter),
-		EndBefore:    storage.Key(req.EndBefore),
-		Recursive:    req.Recursive,
-		Limit:        int(req.Limit),
-		IncludeValue: req.MetaFlags != meta.None,
+	if req.StartAfter != "" && req.EndBefore != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "start-after and end-before cannot be combined")
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 || limit > storage.LookupLimit {
+		limit = storage.LookupLimit
+	}
+
+	// the items are listed after start-after, or before end-before in
+	// reverse, excluding the one the listing starts from
+	reverse := req.EndBefore != ""
+	var first storage.Key
+	if reverse {
+		first = storage.Key(req.EndBefore)
+	} else {
+		first = storage.Key(req.StartAfter)
+	}
+	var firstFull storage.Key
+	if !first.IsZero() {
+		firstFull = append(storage.CloneKey(prefix), first...)
+	}
+
+	var items []*pb.ListResponse_Item
+	more := false
+	err = s.DB.Iterate(storage.IterateOptions{
+		Prefix:  prefix,
+		First:   firstFull,
+		Reverse: reverse,
+		Recurse: req.Recursive,
+		// the first item may be skipped and one more is needed for more
+		Limit: limit + 2,
+	}, func(it storage.Iterator) error {
+		var item storage.ListItem
+		for it.Next(&item) {
+			if len(items) == limit {
+				more = true
+				break
+			}
+			relativeKey := item.Key[len(prefix):]
+			if !firstFull.IsZero() && item.Key.Equal(firstFull) {
+				continue
+			}
+			items = append(items, s.createListItem(storage.ListItem{
+				Key:      storage.CloneKey(relativeKey),
+				Value:    item.Value,
+				IsPrefix: item.IsPrefix,
+			}, req.MetaFlags))
+		}
+		return nil
 	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "ListV2: %v", err)
+		return nil, status.Errorf(codes.Internal, "List: %v", err)
 	}
 
-	var items []*pb.ListResponse_Item
-	for _, rawItem := range rawItems {
-		items = append(items, s.createListItem(rawItem, req.MetaFlags))
+	if reverse {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
 	}
 
 	return &pb.ListResponse{Items: items, More: more}, nil
@@ -283,17 +398,207 @@ func (s *Server) Delete(ctx context.Context, req *pb.DeleteRequest) (resp *pb.De
 	defer mon.Task()(&ctx)(&err)
 	s.logger.Debug("entering pointerdb delete")
 
-	if err = s.validateAuth(ctx); err != nil {
+	if err = s.validateAuth(ctx, macaroon.ActionDelete, req.GetPath()); err != nil {
 		return nil, err
 	}
 
+	unlock, err := s.lockCopies(req.GetPath())
+	if err != nil {
+		s.logger.Error("err getting pointer", zap.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer unlock()
+
+	pointer, err := s.getPointer(req.GetPath())
+	if err != nil && !storage.ErrKeyNotFound.Has(err) {
+		s.logger.Error("err getting pointer", zap.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	remote := pointer.GetRemote()
+
 	err = s.DB.Delete([]byte(req.GetPath()))
 	if err != nil {
 		s.logger.Error("err deleting path and pointer", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, err.Error())
 	}
 	s.logger.Debug("deleted pointer at path: " + req.GetPath())
-	return &pb.DeleteResponse{}, nil
+
+	if err = s.unshare(req.GetPath(), remote); err != nil {
+		s.logger.Error("err unsharing pieces of deleted pointer", zap.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.DeleteResponse{Shared: len(remote.GetCopies()) > 0}, nil
+}
+
+// Copy copies the pointer of a path to another path with new metadata. The
+// pieces of a remote segment aren't copied, but shared by the copies, and
+// they're deleted with the last of them.
+func (s *Server) Copy(ctx context.Context, req *pb.CopyRequest) (resp *pb.CopyResponse, err error) {
+	defer mon.Task()(&ctx)(&err)
+	s.logger.Debug("entering pointerdb copy")
+
+	if err = s.validateAuth(ctx, macaroon.ActionRead, req.GetSrcPath()); err != nil {
+		return nil, err
+	}
+	if err = s.validateAuth(ctx, macaroon.ActionWrite, req.GetDstPath()); err != nil {
+		return nil, err
+	}
+	if req.GetSrcPath() == req.GetDstPath() {
+		return nil, status.Errorf(codes.InvalidArgument, "copying %s to itself", req.GetSrcPath())
+	}
+
+	s.copies.Lock()
+	defer s.copies.Unlock()
+
+	src, err := s.getPointer(req.GetSrcPath())
+	if err != nil {
+		if storage.ErrKeyNotFound.Has(err) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		s.logger.Error("err getting pointer", zap.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	pointer := proto.Clone(src).(*pb.Pointer)
+	pointer.Metadata = req.GetMetadata()
+	pointer.CreationDate = ptypes.TimestampNow()
+	if pointer.GetRemote() != nil {
+		pointer.Remote.Copies = removePath(append(pointer.Remote.Copies, req.GetSrcPath()), req.GetDstPath())
+	}
+
+	if err = s.replace(req.GetDstPath(), pointer); err != nil {
+		s.logger.Error("err replacing pointer", zap.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err = s.share(req.GetDstPath(), pointer.GetRemote()); err != nil {
+		s.logger.Error("err sharing pieces of copied pointer", zap.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err = s.putPointer(req.GetDstPath(), pointer); err != nil {
+		s.logger.Error("err putting pointer", zap.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.CopyResponse{}, nil
+}
+
+// getPointer returns the pointer at path
+func (s *Server) getPointer(path string) (*pb.Pointer, error) {
+	pointerBytes, err := s.DB.Get([]byte(path))
+	if err != nil {
+		return nil, err
+	}
+	pointer := &pb.Pointer{}
+	if err = proto.Unmarshal(pointerBytes, pointer); err != nil {
+		return nil, err
+	}
+	return pointer, nil
+}
+
+// putPointer puts pointer at path
+func (s *Server) putPointer(path string, pointer *pb.Pointer) error {
+	pointerBytes, err := proto.Marshal(pointer)
+	if err != nil {
+		return err
+	}
+	return s.DB.Put([]byte(path), pointerBytes)
+}
+
+// lockCopies locks the copies for updating the pointer at path, which
+// only needs a read lock as long as the pointer doesn't share its pieces
+// with copies. Copying never adds copies to a pointer without holding the
+// lock, so the pointer can't get any until unlock is called.
+func (s *Server) lockCopies(path string) (unlock func(), err error) {
+	s.copies.RLock()
+	pointer, err := s.getPointer(path)
+	if err != nil && !storage.ErrKeyNotFound.Has(err) {
+		s.copies.RUnlock()
+		return nil, err
+	}
+	if len(pointer.GetRemote().GetCopies()) == 0 {
+		return s.copies.RUnlock, nil
+	}
+	s.copies.RUnlock()
+
+	// the pointer is read again by the update, as it may change until the
+	// lock is acquired
+	s.copies.Lock()
+	return s.copies.Unlock, nil
+}
+
+// replace prepares pointer to replace the pointer at path. If the replaced
+// pointer shares its pieces with copies, pointer keeps sharing them if it
+// has the same pieces, whose nodes and receipts are updated in the copies
+// as they may have been repaired, and otherwise they're unshared. The
+// copies must be locked if the replaced pointer has any.
+func (s *Server) replace(path string, pointer *pb.Pointer) error {
+	old, err := s.getPointer(path)
+	if err != nil {
+		if storage.ErrKeyNotFound.Has(err) {
+			return nil
+		}
+		return err
+	}
+	if len(old.GetRemote().GetCopies()) == 0 {
+		return nil
+	}
+	if pointer.GetRemote().GetPieceId() == old.GetRemote().GetPieceId() {
+		pointer.Remote.Copies = old.Remote.Copies
+		return s.updateCopies(pointer.Remote, func(remote *pb.RemoteSegment) {
+			remote.RemotePieces = pointer.Remote.RemotePieces
+			remote.MerkleRoot = pointer.Remote.MerkleRoot
+		})
+	}
+	return s.unshare(path, old.GetRemote())
+}
+
+// share adds path to the copies of the segments sharing the pieces of
+// remote. The copies must be locked.
+func (s *Server) share(path string, remote *pb.RemoteSegment) error {
+	return s.updateCopies(remote, func(remote *pb.RemoteSegment) {
+		remote.Copies = append(removePath(remote.Copies, path), path)
+	})
+}
+
+// unshare removes path from the copies of the segments sharing the pieces
+// of remote. The copies must be locked.
+func (s *Server) unshare(path string, remote *pb.RemoteSegment) error {
+	return s.updateCopies(remote, func(remote *pb.RemoteSegment) {
+		remote.Copies = removePath(remote.Copies, path)
+	})
+}
+
+// updateCopies updates the remote segments of the copies sharing the pieces
+// of remote which still have the same pieces
+func (s *Server) updateCopies(remote *pb.RemoteSegment, update func(remote *pb.RemoteSegment)) error {
+	for _, path := range remote.GetCopies() {
+		pointer, err := s.getPointer(path)
+		if err != nil {
+			if storage.ErrKeyNotFound.Has(err) {
+				continue
+			}
+			return err
+		}
+		if pointer.GetRemote().GetPieceId() != remote.GetPieceId() {
+			continue
+		}
+		update(pointer.Remote)
+		if err = s.putPointer(path, pointer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removePath returns paths without path
+func removePath(paths []string, path string) []string {
+	var removed []string
+	for _, p := range paths {
+		if p != path {
+			removed = append(removed, p)
+		}
+	}
+	return removed
 }
 
 // Iterate iterates over items based on IterateRequest
@@ -308,26 +613,14 @@ func (s *Server) Iterate(ctx context.Context, req *pb.IterateRequest, f func(it
 }
 
 func (s *Server) getPayerBandwidthAllocation(ctx context.Context) (*pb.PayerBandwidthAllocation, error) {
-	payer := s.identity.ID.Bytes()
-
 	// TODO(michal) should be replaced with renter id when available
 	peerIdentity, err := provider.PeerIdentityFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	pbad := &pb.PayerBandwidthAllocation_Data{
-		SatelliteId: payer,
-		UplinkId:    peerIdentity.ID.Bytes(),
+		UplinkId: peerIdentity.ID.Bytes(),
 		// TODO: Action: pb.PayerBandwidthAllocation_GET, // Action should be a GET or a PUT
 	}
-
-	data, err := proto.Marshal(pbad)
-	if err != nil {
-		return nil, err
-	}
-	signature, err := auth.GenerateSignature(data, s.identity)
-	if err != nil {
-		return nil, err
-	}
-	return &pb.PayerBandwidthAllocation{Signature: signature, Data: data}, nil
+	return auth.NewPayerAllocation(pbad, s.identity)
 }
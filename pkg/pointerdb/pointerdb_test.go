@@ -22,6 +22,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"storj.io/storj/pkg/auth"
+	"storj.io/storj/pkg/macaroon"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/provider"
 	"storj.io/storj/pkg/storage/meta"
@@ -140,8 +141,11 @@ func TestServiceDelete(t *testing.T) {
 
 		path := "a/b/c"
 
+		pointerBytes, err := proto.Marshal(&pb.Pointer{})
+		assert.NoError(t, err, errTag)
+
 		db := teststore.New()
-		_ = db.Put(storage.Key(path), storage.Value("hello"))
+		_ = db.Put(storage.Key(path), storage.Value(pointerBytes))
 		s := Server{DB: db, logger: zap.NewNop()}
 
 		if tt.err != nil {
@@ -149,7 +153,7 @@ func TestServiceDelete(t *testing.T) {
 		}
 
 		req := pb.DeleteRequest{Path: path}
-		_, err := s.Delete(ctx, &req)
+		_, err = s.Delete(ctx, &req)
 
 		if err != nil {
 			assert.EqualError(t, err, tt.errString, errTag)
@@ -159,6 +163,85 @@ func TestServiceDelete(t *testing.T) {
 	}
 }
 
+func TestServiceCopy(t *testing.T) {
+	ctx := auth.WithAPIKey(context.Background(), nil)
+	db := teststore.New()
+	s := Server{DB: db, logger: zap.NewNop()}
+
+	getPointer := func(path string) *pb.Pointer {
+		pointer, err := s.getPointer(path)
+		assert.NoError(t, err, path)
+		return pointer
+	}
+
+	_, err := s.Put(ctx, &pb.PutRequest{Path: "l/bucket/a", Pointer: &pb.Pointer{
+		Type:     pb.Pointer_REMOTE,
+		Remote:   &pb.RemoteSegment{PieceId: "piece"},
+		Metadata: []byte("a"),
+	}})
+	assert.NoError(t, err)
+
+	_, err = s.Copy(ctx, &pb.CopyRequest{SrcPath: "l/bucket/a", DstPath: "l/bucket/b", Metadata: []byte("b")})
+	assert.NoError(t, err)
+	_, err = s.Copy(ctx, &pb.CopyRequest{SrcPath: "l/bucket/b", DstPath: "l/bucket/c", Metadata: []byte("c")})
+	assert.NoError(t, err)
+
+	// the copies share the pieces, and know about each other
+	for path, copies := range map[string][]string{
+		"l/bucket/a": {"l/bucket/b", "l/bucket/c"},
+		"l/bucket/b": {"l/bucket/a", "l/bucket/c"},
+		"l/bucket/c": {"l/bucket/a", "l/bucket/b"},
+	} {
+		pointer := getPointer(path)
+		assert.Equal(t, "piece", pointer.GetRemote().GetPieceId(), path)
+		assert.ElementsMatch(t, copies, pointer.GetRemote().GetCopies(), path)
+		assert.Equal(t, []byte(path[len(path)-1:]), pointer.GetMetadata(), path)
+	}
+
+	// copying a missing pointer or a pointer to itself fails
+	_, err = s.Copy(ctx, &pb.CopyRequest{SrcPath: "l/bucket/missing", DstPath: "l/bucket/d"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = s.Copy(ctx, &pb.CopyRequest{SrcPath: "l/bucket/a", DstPath: "l/bucket/a"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// repairing the pieces of a copy updates them in the other copies
+	repaired := []*pb.RemotePiece{{PieceNum: 1, NodeId: "repaired"}}
+	_, err = s.Put(ctx, &pb.PutRequest{Path: "l/bucket/a", Pointer: &pb.Pointer{
+		Type:     pb.Pointer_REMOTE,
+		Remote:   &pb.RemoteSegment{PieceId: "piece", RemotePieces: repaired, MerkleRoot: []byte("root")},
+		Metadata: []byte("a"),
+	}})
+	assert.NoError(t, err)
+	for _, path := range []string{"l/bucket/a", "l/bucket/b", "l/bucket/c"} {
+		pointer := getPointer(path)
+		assert.Len(t, pointer.GetRemote().GetCopies(), 2, path)
+		assert.Equal(t, []byte("root"), pointer.GetRemote().GetMerkleRoot(), path)
+		if assert.Len(t, pointer.GetRemote().GetRemotePieces(), 1, path) {
+			assert.Equal(t, "repaired", pointer.GetRemote().GetRemotePieces()[0].GetNodeId(), path)
+		}
+	}
+
+	// replacing a copy with other pieces unshares the pieces
+	_, err = s.Put(ctx, &pb.PutRequest{Path: "l/bucket/c", Pointer: &pb.Pointer{
+		Type:   pb.Pointer_REMOTE,
+		Remote: &pb.RemoteSegment{PieceId: "other piece"},
+	}})
+	assert.NoError(t, err)
+	assert.Empty(t, getPointer("l/bucket/c").GetRemote().GetCopies())
+	assert.Equal(t, []string{"l/bucket/b"}, getPointer("l/bucket/a").GetRemote().GetCopies())
+
+	// the pieces are only deleted with the last copy
+	resp, err := s.Delete(ctx, &pb.DeleteRequest{Path: "l/bucket/a"})
+	if assert.NoError(t, err) {
+		assert.True(t, resp.Shared)
+	}
+	assert.Empty(t, getPointer("l/bucket/b").GetRemote().GetCopies())
+	resp, err = s.Delete(ctx, &pb.DeleteRequest{Path: "l/bucket/b"})
+	if assert.NoError(t, err) {
+		assert.False(t, resp.Shared)
+	}
+}
+
 func TestServiceList(t *testing.T) {
 	db := teststore.New()
 	server := Server{DB: db, logger: zap.NewNop()}
@@ -342,3 +425,53 @@ func TestServiceList(t *testing.T) {
 		}
 	}
 }
+
+func TestServiceAPIKeySecret(t *testing.T) {
+	secret, err := macaroon.NewSecret()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	key, err := macaroon.NewAPIKey(secret)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	key, err = key.Restrict(&pb.Caveat{AllowedBuckets: [][]byte{[]byte("bucket")}})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	serialized, err := key.Serialize()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	key, err = key.Restrict(&pb.Caveat{AllowedPaths: [][]byte{[]byte("bucket/shared")}})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	shared, err := key.Serialize()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	s := Server{DB: teststore.New(), logger: zap.NewNop(), apiKeySecret: secret}
+	for _, tt := range []struct {
+		apiKey    []byte
+		path      string
+		errString string
+	}{
+		{[]byte(serialized), "l/bucket/path", ""},
+		{[]byte(serialized), "l/other/path", status.Errorf(codes.Unauthenticated, "Invalid API credential").Error()},
+		{[]byte("abc123"), "l/bucket/path", status.Errorf(codes.Unauthenticated, "Invalid API credential").Error()},
+		{[]byte(shared), "l/bucket/shared/path", ""},
+		{[]byte(shared), "s0/bucket/shared", ""},
+		{[]byte(shared), "l/bucket/sharedpath", status.Errorf(codes.Unauthenticated, "Invalid API credential").Error()},
+		{[]byte(shared), "l/bucket/path", status.Errorf(codes.Unauthenticated, "Invalid API credential").Error()},
+	} {
+		ctx := auth.WithAPIKey(context.Background(), tt.apiKey)
+		_, err := s.Put(ctx, &pb.PutRequest{Path: tt.path, Pointer: &pb.Pointer{}})
+		if tt.errString != "" {
+			assert.EqualError(t, err, tt.errString, tt.path)
+		} else {
+			assert.NoError(t, err, tt.path)
+		}
+	}
+}
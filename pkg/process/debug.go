@@ -5,7 +5,6 @@ package process
 
 import (
 	"flag"
-	"fmt"
 	"net"
 	"net/http"
 	"net/http/pprof"
@@ -35,9 +34,7 @@ func initDebug(logger *zap.Logger, r *monkit.Registry) (
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	mux.Handle("/mon/", http.StripPrefix("/mon", present.HTTP(r)))
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		_, _ = fmt.Fprintln(w, "OK")
-	})
+	mux.HandleFunc("/health", serveHealth)
 	ln, err := net.Listen("tcp", *debugAddr)
 	if err != nil {
 		return err
@@ -0,0 +1,71 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package process
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+var healthChecks = struct {
+	mu     sync.Mutex
+	checks map[string]func() error
+}{checks: map[string]func() error{}}
+
+// RegisterHealthCheck adds check to the checks of the /health debug
+// endpoint, which reports the process as unhealthy while any of them fails,
+// like when a database it depends on can't be reached. The returned func
+// removes the check again.
+func RegisterHealthCheck(name string, check func() error) (unregister func()) {
+	healthChecks.mu.Lock()
+	defer healthChecks.mu.Unlock()
+	healthChecks.checks[name] = check
+	return func() {
+		healthChecks.mu.Lock()
+		defer healthChecks.mu.Unlock()
+		delete(healthChecks.checks, name)
+	}
+}
+
+// checkHealth runs the registered health checks, returning the errors of
+// the failing ones by name
+func checkHealth() map[string]error {
+	healthChecks.mu.Lock()
+	checks := make(map[string]func() error, len(healthChecks.checks))
+	for name, check := range healthChecks.checks {
+		checks[name] = check
+	}
+	healthChecks.mu.Unlock()
+
+	failures := map[string]error{}
+	for name, check := range checks {
+		if err := check(); err != nil {
+			failures[name] = err
+		}
+	}
+	return failures
+}
+
+// serveHealth responds with OK if all health checks pass, and with the
+// failing checks and a 503 otherwise
+func serveHealth(w http.ResponseWriter, r *http.Request) {
+	failures := checkHealth()
+	if len(failures) == 0 {
+		_, _ = fmt.Fprintln(w, "OK")
+		return
+	}
+
+	names := make([]string, 0, len(failures))
+	for name := range failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w.WriteHeader(http.StatusServiceUnavailable)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(w, "%s: %v\n", name, failures[name])
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package process
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	health := func() (int, string) {
+		recorder := httptest.NewRecorder()
+		serveHealth(recorder, httptest.NewRequest("GET", "/health", nil))
+		return recorder.Code, recorder.Body.String()
+	}
+
+	code, body := health()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "OK\n", body)
+
+	var err error
+	unregister := RegisterHealthCheck("db", func() error { return err })
+	code, _ = health()
+	assert.Equal(t, http.StatusOK, code)
+
+	err = errors.New("connection refused")
+	code, body = health()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "db: connection refused\n", body)
+
+	unregister()
+	code, _ = health()
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/zeebo/errs"
+)
+
+// claimedDir is the directory of a CAPool that claimed CAs are moved to
+const claimedDir = "claimed"
+
+// ErrCAPool is used when something goes wrong with a CA pool
+var ErrCAPool = errs.Class("ca pool error")
+
+// CAPool is a directory of pre-generated certificate authorities, so that
+// identities can be handed out without mining them on demand. Each CA is
+// kept in a directory named after its node id; claimed CAs are moved out of
+// the pool, which makes claiming safe across processes.
+type CAPool struct {
+	Dir string
+}
+
+// NewCAPool returns the pool in dir
+func NewCAPool(dir string) *CAPool {
+	return &CAPool{Dir: dir}
+}
+
+// Mine generates count CAs with opts and adds them to the pool
+func (p *CAPool) Mine(ctx context.Context, opts NewCAOptions, count int) error {
+	for i := 0; i < count; i++ {
+		ca, err := NewCA(ctx, opts)
+		if err != nil {
+			return err
+		}
+		// CAs are only moved into the pool once saved completely, so that
+		// they can't be claimed half written
+		id := ca.ID.String()
+		if err := poolCAConfig(p.Dir, "."+id).Save(ca); err != nil {
+			return ErrCAPool.Wrap(err)
+		}
+		if err := os.Rename(filepath.Join(p.Dir, "."+id), filepath.Join(p.Dir, id)); err != nil {
+			return ErrCAPool.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// Available returns the number of unclaimed CAs with at least the given
+// difficulty
+func (p *CAPool) Available(minDifficulty uint16) (int, error) {
+	ids, err := p.ids(minDifficulty)
+	return len(ids), err
+}
+
+// Claim removes a CA with at least the given difficulty from the pool and
+// returns it. It returns nil if there is none.
+func (p *CAPool) Claim(minDifficulty uint16) (*FullCertificateAuthority, error) {
+	ids, err := p.ids(minDifficulty)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Join(p.Dir, claimedDir), 0700); err != nil {
+		return nil, ErrCAPool.Wrap(err)
+	}
+
+	for _, id := range ids {
+		err := os.Rename(filepath.Join(p.Dir, id), filepath.Join(p.Dir, claimedDir, id))
+		if os.IsNotExist(err) {
+			// claimed by someone else in the meantime
+			continue
+		}
+		if err != nil {
+			return nil, ErrCAPool.Wrap(err)
+		}
+		return poolCAConfig(filepath.Join(p.Dir, claimedDir), id).Load()
+	}
+	return nil, nil
+}
+
+// ids returns the ids of the unclaimed CAs with at least the given difficulty
+func (p *CAPool) ids(minDifficulty uint16) ([]string, error) {
+	entries, err := ioutil.ReadDir(p.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, ErrCAPool.Wrap(err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == claimedDir {
+			continue
+		}
+		if _, err := base64.URLEncoding.DecodeString(entry.Name()); err != nil {
+			continue
+		}
+		if nodeID(entry.Name()).Difficulty() < minDifficulty {
+			continue
+		}
+		ids = append(ids, entry.Name())
+	}
+	return ids, nil
+}
+
+func poolCAConfig(dir, id string) FullCAConfig {
+	return FullCAConfig{
+		CertPath: filepath.Join(dir, id, "ca.cert"),
+		KeyPath:  filepath.Join(dir, id, "ca.key"),
+	}
+}
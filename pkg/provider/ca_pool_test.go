@@ -0,0 +1,46 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCAPool(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ca-pool")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	pool := NewCAPool(dir)
+	available, err := pool.Available(0)
+	assert.NoError(t, err)
+	assert.Zero(t, available)
+
+	assert.NoError(t, pool.Mine(context.Background(), NewCAOptions{Difficulty: 4}, 2))
+	available, err = pool.Available(4)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, available)
+
+	claimed := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		ca, err := pool.Claim(4)
+		assert.NoError(t, err)
+		if assert.NotNil(t, ca) {
+			assert.True(t, ca.ID.Difficulty() >= 4)
+			claimed[ca.ID.String()] = true
+		}
+	}
+	assert.Len(t, claimed, 2, "each CA is claimed once")
+
+	ca, err := pool.Claim(4)
+	assert.NoError(t, err)
+	assert.Nil(t, ca)
+}
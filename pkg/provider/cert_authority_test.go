@@ -5,9 +5,16 @@ package provider
 
 import (
 	"context"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/peertls"
 )
 
 func TestNewCA(t *testing.T) {
@@ -24,6 +31,78 @@ func TestNewCA(t *testing.T) {
 	assert.True(t, actualDifficulty >= expectedDifficulty)
 }
 
+func TestNewCA_Ed25519(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ca-ed25519")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	ca, err := NewCA(context.Background(), NewCAOptions{
+		Difficulty: 4,
+		KeyType:    peertls.KeyTypeEd25519,
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, peertls.KeyTypeEd25519, peertls.KeyTypeOf(ca.Key))
+
+	fi, err := ca.NewIdentity()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, peertls.KeyTypeEd25519, peertls.KeyTypeOf(fi.Key))
+	assert.NoError(t, peertls.VerifyPeerCertChains(nil, [][]*x509.Certificate{{fi.Leaf, fi.CA}}))
+
+	ic := IdentityConfig{
+		CertPath: filepath.Join(dir, "identity.cert"),
+		KeyPath:  filepath.Join(dir, "identity.key"),
+	}
+	assert.NoError(t, ic.Save(fi))
+	loaded, err := ic.Load()
+	if assert.NoError(t, err) {
+		assert.Equal(t, fi.Key, loaded.Key)
+		assert.Equal(t, fi.ID, loaded.ID)
+	}
+}
+
+func TestNewCA_Checkpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ca-checkpoint")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "ca.checkpoint")
+
+	// an interrupted generation saves the best key found so far
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = NewCA(ctx, NewCAOptions{
+		Difficulty:     200,
+		Concurrency:    2,
+		CheckpointPath: path,
+	})
+	assert.Error(t, err)
+
+	saved, err := loadCheckpoint(path)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, saved) {
+		t.FailNow()
+	}
+	assert.NotZero(t, saved.Attempts)
+
+	// and resuming with a difficulty the saved key meets uses it
+	ca, err := NewCA(context.Background(), NewCAOptions{
+		Difficulty:     saved.difficulty(),
+		CheckpointPath: path,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, saved.Key, ca.Key)
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "checkpoint is removed once done")
+}
+
 func TestFullCertificateAuthority_NewIdentity(t *testing.T) {
 	check := func(err error, v interface{}) {
 		if !assert.NoError(t, err) || !assert.NotEmpty(t, v) {
@@ -6,11 +6,14 @@ package provider
 import (
 	"context"
 	"crypto"
-	"crypto/ecdsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"io/ioutil"
 	"os"
+	"runtime"
+	"sync/atomic"
+	"time"
 
 	"github.com/zeebo/errs"
 
@@ -47,15 +50,25 @@ type CASetupConfig struct {
 	Difficulty     uint64 `help:"minimum difficulty for identity generation" default:"12"`
 	Timeout        string `help:"timeout for CA generation; golang duration string (0 no timeout)" default:"5m"`
 	Overwrite      bool   `help:"if true, existing CA certs AND keys will overwritten" default:"false"`
-	Concurrency    uint   `help:"number of concurrent workers for certificate authority generation" default:"4"`
+	Concurrency    uint   `help:"number of concurrent workers for certificate authority generation, 0 uses all cores" default:"0"`
+	CheckpointPath string `help:"path generation progress is saved to, so that an interrupted generation can be resumed" default:"$CONFDIR/ca.checkpoint"`
+	EncryptKey     bool   `help:"encrypt the private key with a passphrase, read from STORJ_KEY_PASSPHRASE or prompted for" default:"false"`
+	KeyType        string `help:"type of the keys of the identity, ecdsa or ed25519" default:"ecdsa"`
 }
 
 // NewCAOptions is used to pass parameters to `NewCA`
 type NewCAOptions struct {
 	// Difficulty is the number of trailing zero-bits the nodeID must have
 	Difficulty uint16
-	// Concurrency is the number of go routines used to generate a CA of sufficient difficulty
+	// KeyType is the type of the CA key, see peertls.NewKeyOfType. Identities
+	// of the CA use the same type.
+	KeyType string
+	// Concurrency is the number of go routines used to generate a CA of sufficient difficulty,
+	// 0 uses one per CPU core
 	Concurrency uint
+	// CheckpointPath, if provided, is where the best key found so far is saved
+	// periodically and when generation is interrupted; generation resumes from it
+	CheckpointPath string
 	// ParentCert, if provided will be prepended to the certificate chain
 	ParentCert *x509.Certificate
 	// ParentKey ()
@@ -99,10 +112,12 @@ func (caS CASetupConfig) Create(ctx context.Context) (*FullCertificateAuthority,
 	}
 
 	ca, err := NewCA(ctx, NewCAOptions{
-		Difficulty:  uint16(caS.Difficulty),
-		Concurrency: caS.Concurrency,
-		ParentCert:  parent.Cert,
-		ParentKey:   parent.Key,
+		Difficulty:     uint16(caS.Difficulty),
+		KeyType:        caS.KeyType,
+		Concurrency:    caS.Concurrency,
+		CheckpointPath: caS.CheckpointPath,
+		ParentCert:     parent.Cert,
+		ParentKey:      parent.Key,
 	})
 	if err != nil {
 		return nil, err
@@ -111,7 +126,7 @@ func (caS CASetupConfig) Create(ctx context.Context) (*FullCertificateAuthority,
 		CertPath: caS.CertPath,
 		KeyPath:  caS.KeyPath,
 	}
-	return ca, caC.Save(ca)
+	return ca, caC.save(ca, caS.EncryptKey)
 }
 
 // Load loads a CA from the given configuration
@@ -125,10 +140,9 @@ func (fc FullCAConfig) Load() (*FullCertificateAuthority, error) {
 	if err != nil {
 		return nil, peertls.ErrNotExist.Wrap(err)
 	}
-	kp, _ := pem.Decode(kb)
-	k, err := x509.ParseECPrivateKey(kp.Bytes)
+	k, err := parseKeyPEM(kb, fc.KeyPath)
 	if err != nil {
-		return nil, errs.New("unable to parse EC private key: %v", err)
+		return nil, err
 	}
 
 	return &FullCertificateAuthority{
@@ -180,71 +194,132 @@ func (pc PeerCAConfig) Load() (*PeerCertificateAuthority, error) {
 	}, nil
 }
 
-// NewCA creates a new full identity with the given difficulty
+// NewCA creates a new full identity with the given difficulty. Key mining
+// is spread over opts.Concurrency workers. If opts.CheckpointPath is set,
+// the best key found so far is checkpointed there, so that an interrupted
+// generation can be resumed: a checkpointed key that meets the difficulty
+// is used right away, otherwise mining carries on where it left off.
 func NewCA(ctx context.Context, opts NewCAOptions) (*FullCertificateAuthority, error) {
 	if opts.Concurrency < 1 {
-		opts.Concurrency = 1
+		opts.Concurrency = uint(runtime.NumCPU())
+	}
+
+	var best *checkpoint
+	if opts.CheckpointPath != "" {
+		var err error
+		best, err = loadCheckpoint(opts.CheckpointPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if best == nil || !best.hasKeyType(opts.KeyType) {
+		best = &checkpoint{}
 	}
+
+	key, err := mineKey(ctx, opts, best)
+	if err != nil {
+		return nil, err
+	}
+
+	ca, err := newCAFromKey(key, opts.ParentCert, opts.ParentKey)
+	if err != nil {
+		return nil, err
+	}
+	if opts.CheckpointPath != "" {
+		removeCheckpoint(opts.CheckpointPath)
+	}
+	return ca, nil
+}
+
+// mineKey runs the workers until one of them finds a key with the required
+// difficulty, keeping best up to date and checkpointing it
+func mineKey(ctx context.Context, opts NewCAOptions, best *checkpoint) (crypto.PrivateKey, error) {
+	if best.Key != nil && best.difficulty() >= opts.Difficulty {
+		return best.Key, nil
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	eC := make(chan error)
-	caC := make(chan FullCertificateAuthority, 1)
+	var attempts uint64
+	eC := make(chan error, opts.Concurrency)
+	keyC := make(chan crypto.PrivateKey, opts.Concurrency)
 	for i := 0; i < int(opts.Concurrency); i++ {
-		go newCAWorker(ctx, opts.Difficulty, opts.ParentCert, opts.ParentKey, caC, eC)
+		go newKeyWorker(ctx, opts.KeyType, &attempts, keyC, eC)
 	}
 
-	select {
-	case ca := <-caC:
-		cancel()
-		return &ca, nil
-	case err := <-eC:
-		cancel()
-		return nil, err
+	save := func() error {
+		best.Attempts += atomic.SwapUint64(&attempts, 0)
+		if opts.CheckpointPath == "" {
+			return nil
+		}
+		return best.save(opts.CheckpointPath)
+	}
+
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case k := <-keyC:
+			if best.Key != nil && difficultyOf(k) <= best.difficulty() {
+				continue
+			}
+			best.Key = k
+			if best.difficulty() >= opts.Difficulty {
+				return k, nil
+			}
+		case <-ticker.C:
+			if err := save(); err != nil {
+				return nil, err
+			}
+		case err := <-eC:
+			return nil, err
+		case <-ctx.Done():
+			return nil, utils.CombineErrors(ctx.Err(), save())
+		}
 	}
 }
 
-// Save saves a CA with the given configuration
+// Save saves a CA with the given configuration. An encrypted key file stays
+// encrypted.
 func (fc FullCAConfig) Save(ca *FullCertificateAuthority) error {
+	return fc.save(ca, isEncryptedKeyFile(fc.KeyPath))
+}
+
+func (fc FullCAConfig) save(ca *FullCertificateAuthority, encrypt bool) error {
 	f := os.O_WRONLY | os.O_CREATE
 	c, err := openCert(fc.CertPath, f)
 	if err != nil {
 		return err
 	}
 	defer utils.LogClose(c)
-	k, err := openKey(fc.KeyPath, f)
-	if err != nil {
-		return err
-	}
-	defer utils.LogClose(k)
 
 	chain := []*x509.Certificate{ca.Cert}
 	chain = append(chain, ca.RestChain...)
 	if err = peertls.WriteChain(c, chain...); err != nil {
 		return err
 	}
-	if err = peertls.WriteKey(k, ca.Key); err != nil {
-		return err
-	}
-	return nil
+	return writeKeyFile(fc.KeyPath, ca.Key, encrypt)
 }
 
 // NewIdentity generates a new `FullIdentity` based on the CA. The CA
 // cert is included in the identity's cert chain and the identity's leaf cert
-// is signed by the CA.
-func (ca FullCertificateAuthority) NewIdentity() (*FullIdentity, error) {
+// is signed by the CA. The leaf cert gets the given extra extensions.
+func (ca FullCertificateAuthority) NewIdentity(exts ...pkix.Extension) (*FullIdentity, error) {
 	lT, err := peertls.LeafTemplate()
 	if err != nil {
 		return nil, err
 	}
-	k, err := peertls.NewKey()
+	lT.ExtraExtensions = append(lT.ExtraExtensions, exts...)
+	k, err := peertls.NewKeyOfType(peertls.KeyTypeOf(ca.Key))
 	if err != nil {
 		return nil, err
 	}
-	pk, ok := k.(*ecdsa.PrivateKey)
-	if !ok {
-		return nil, peertls.ErrUnsupportedKey.New("%T", k)
+	pk, err := peertls.PublicKey(k)
+	if err != nil {
+		return nil, err
 	}
-	l, err := peertls.NewCert(lT, ca.Cert, &pk.PublicKey, ca.Key)
+	l, err := peertls.NewCert(lT, ca.Cert, pk, ca.Key)
 	if err != nil {
 		return nil, err
 	}
@@ -258,10 +333,25 @@ func (ca FullCertificateAuthority) NewIdentity() (*FullIdentity, error) {
 	}, nil
 }
 
-// NewTestCA returns a ca with a default difficulty and concurrency for use in tests
+// TestCAPoolEnv is the environment variable that points tests to a CAPool
+// to take their CAs from instead of mining them
+const TestCAPoolEnv = "STORJ_TEST_CA_POOL"
+
+// NewTestCA returns a ca with a default difficulty and concurrency for use in
+// tests. It is claimed from the pool in TestCAPoolEnv if there is one left.
 func NewTestCA(ctx context.Context) (*FullCertificateAuthority, error) {
+	const difficulty = 12
+	if dir := os.Getenv(TestCAPoolEnv); dir != "" {
+		ca, err := NewCAPool(dir).Claim(difficulty)
+		if err != nil {
+			return nil, err
+		}
+		if ca != nil {
+			return ca, nil
+		}
+	}
 	return NewCA(ctx, NewCAOptions{
-		Difficulty:  12,
+		Difficulty:  difficulty,
 		Concurrency: 4,
 	})
 }
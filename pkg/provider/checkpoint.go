@@ -0,0 +1,114 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/peertls"
+)
+
+// checkpointInterval is how often CA generation progress is saved
+const checkpointInterval = time.Minute
+
+// ErrCheckpoint is used when a CA generation checkpoint can't be read or written
+var ErrCheckpoint = errs.Class("ca generation checkpoint error")
+
+// checkpoint is the progress of a CA generation. Keys are mined at random,
+// so the only state worth keeping is the hardest key found so far, which
+// completes a resumed generation right away if it meets the difficulty, and
+// the number of keys tried, for reporting.
+type checkpoint struct {
+	Key      crypto.PrivateKey
+	Attempts uint64
+}
+
+// difficulty returns the difficulty of the node id of the checkpointed key
+func (c *checkpoint) difficulty() uint16 {
+	if c.Key == nil {
+		return 0
+	}
+	return difficultyOf(c.Key)
+}
+
+// hasKeyType returns whether the checkpointed key is of the given type, see
+// peertls.NewKeyOfType
+func (c *checkpoint) hasKeyType(keyType string) bool {
+	if keyType == "" {
+		keyType = peertls.KeyTypeECDSA
+	}
+	return peertls.KeyTypeOf(c.Key) == keyType
+}
+
+// loadCheckpoint reads the checkpoint at path, returning nil if there is none
+func loadCheckpoint(path string) (*checkpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, ErrCheckpoint.Wrap(err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrCheckpoint.New("no key in %s", path)
+	}
+	key, err := peertls.ParseKey(block.Bytes)
+	if err != nil {
+		return nil, ErrCheckpoint.Wrap(err)
+	}
+	attempts, err := strconv.ParseUint(block.Headers["Attempts"], 10, 64)
+	if err != nil {
+		return nil, ErrCheckpoint.Wrap(err)
+	}
+
+	c := &checkpoint{Key: key, Attempts: attempts}
+	zap.S().Infof("resuming CA generation after %d attempts, best difficulty so far is %d", c.Attempts, c.difficulty())
+	return c, nil
+}
+
+// save atomically writes the checkpoint to path. Nothing is written until a
+// key has been found.
+func (c *checkpoint) save(path string) error {
+	if c.Key == nil {
+		return nil
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(c.Key)
+	if err != nil {
+		return ErrCheckpoint.Wrap(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{
+		Type:    peertls.BlockTypePrivateKey,
+		Headers: map[string]string{"Attempts": strconv.FormatUint(c.Attempts, 10)},
+		Bytes:   der,
+	})
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return ErrCheckpoint.Wrap(err)
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return ErrCheckpoint.Wrap(err)
+	}
+	return ErrCheckpoint.Wrap(os.Rename(tmp, path))
+}
+
+// removeCheckpoint deletes the checkpoint of a completed generation
+func removeCheckpoint(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		zap.S().Warnf("could not remove CA generation checkpoint %s: %v", path, err)
+	}
+}
@@ -13,4 +13,8 @@ var (
 
 	// Error is a provider error
 	Error = errs.Class("provider error")
+	// ErrDifficulty is used when a peer's node id doesn't meet the required difficulty
+	ErrDifficulty = errs.Class("node id difficulty error")
+	// ErrPeerID is used when a dialed peer doesn't have the expected node id
+	ErrPeerID = errs.Class("peer id error")
 )
@@ -11,6 +11,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"math/bits"
 	"net"
 	"os"
@@ -22,6 +23,7 @@ import (
 	"google.golang.org/grpc/peer"
 
 	"storj.io/storj/pkg/peertls"
+	"storj.io/storj/pkg/transport/quic"
 	"storj.io/storj/pkg/utils"
 )
 
@@ -61,15 +63,29 @@ type FullIdentity struct {
 	// VerfyAuthExtSig if true, client leafs which handshake with this identity must contain a valid "authority signature extension"
 	// (NB: authority signature extensions are verified against certs in the `PeerCAWhitelist`; i.e. if true, a whitelist must be provided)
 	VerifyAuthExtSig bool
+	// PeerIDDifficulty is the minimum difficulty the node ids of peers must
+	// have; connections with peers with easier node ids are rejected
+	PeerIDDifficulty uint16
+	// PeerWhitelist, if not nil, restricts the peers this identity accepts
+	// connections from to the ones it allows
+	PeerWhitelist *PeerWhitelist
+	// ExtensionHandlers, if not nil, verify the certificate extensions of
+	// peers in both incoming and outgoing connections
+	ExtensionHandlers *peertls.ExtensionHandlers
+	// SessionCache, if not nil, caches the TLS sessions of outgoing
+	// connections, so reconnecting to a node resumes its session instead of
+	// making a full handshake
+	SessionCache tls.ClientSessionCache
 }
 
 // IdentitySetupConfig allows you to run a set of Responsibilities with the given
 // identity. You can also just load an Identity from disk.
 type IdentitySetupConfig struct {
-	CertPath  string `help:"path to the certificate chain for this identity" default:"$CONFDIR/identity.cert"`
-	KeyPath   string `help:"path to the private key for this identity" default:"$CONFDIR/identity.key"`
-	Overwrite bool   `help:"if true, existing identity certs AND keys will overwritten for" default:"false"`
-	Version   string `help:"semantic version of identity storage format" default:"0"`
+	CertPath   string `help:"path to the certificate chain for this identity" default:"$CONFDIR/identity.cert"`
+	KeyPath    string `help:"path to the private key for this identity" default:"$CONFDIR/identity.key"`
+	Overwrite  bool   `help:"if true, existing identity certs AND keys will overwritten for" default:"false"`
+	Version    string `help:"semantic version of identity storage format" default:"0"`
+	EncryptKey bool   `help:"encrypt the private key with a passphrase, read from STORJ_KEY_PASSPHRASE or prompted for" default:"false"`
 }
 
 // IdentityConfig allows you to run a set of Responsibilities with the given
@@ -79,7 +95,12 @@ type IdentityConfig struct {
 	KeyPath             string `help:"path to the private key for this identity" default:"$CONFDIR/identity.key"`
 	PeerCAWhitelistPath string `help:"path to the CA cert whitelist (peer identities must be signed by one these to be verified)"`
 	VerifyAuthExtSig    bool   `help:"if true, client leafs must contain a valid \"authority signature extension\" (NB: authority signature extensions are verified against certs in the peer ca whitelist; i.e. if true, a whitelist must be provided)" default:"false"`
+	PeerIDDifficulty    uint64 `help:"minimum difficulty of peer node ids, connections with peers with easier node ids are rejected" default:"12"`
 	Address             string `help:"address to listen on" default:":7777"`
+	PrivateAddress      string `help:"address to listen on for private services, which are served on the public address if empty" default:""`
+	QUIC                bool   `help:"also serve QUIC on the UDP port of the address, and announce it to other nodes" default:"false"`
+	PeerWhitelist       PeerWhitelistConfig
+	LeafRotation        LeafRotationConfig
 }
 
 // FullIdentityFromPEM loads a FullIdentity from a certificate chain and
@@ -92,15 +113,11 @@ func FullIdentityFromPEM(chainPEM, keyPEM, CAWhitelistPEM []byte) (*FullIdentity
 	if len(cb) < 2 {
 		return nil, errs.New("too few certificates in chain")
 	}
-	kb, err := decodePEM(keyPEM)
-	if err != nil {
-		return nil, errs.Wrap(err)
-	}
 	// NB: there shouldn't be multiple keys in the key file but if there
 	// are, this uses the first one
-	k, err := x509.ParseECPrivateKey(kb[0])
+	k, err := parseKeyPEM(keyPEM, "identity key")
 	if err != nil {
-		return nil, errs.New("unable to parse EC private key: %v", err)
+		return nil, err
 	}
 	ch, err := ParseCertChain(cb)
 	if err != nil {
@@ -166,7 +183,10 @@ func PeerIdentityFromCerts(leaf, ca *x509.Certificate, rest []*x509.Certificate)
 
 // PeerIdentityFromPeer loads a PeerIdentity from a peer connection
 func PeerIdentityFromPeer(peer *peer.Peer) (*PeerIdentity, error) {
-	tlsInfo := peer.AuthInfo.(credentials.TLSInfo)
+	tlsInfo, ok := peer.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, Error.New("peer is not using TLS")
+	}
 	c := tlsInfo.State.PeerCertificates
 	if len(c) < 2 {
 		return nil, Error.New("invalid certificate chain")
@@ -205,11 +225,15 @@ func (is IdentitySetupConfig) Create(ca *FullCertificateAuthority) (*FullIdentit
 		CertPath: is.CertPath,
 		KeyPath:  is.KeyPath,
 	}
-	return fi, ic.Save(fi)
+	return fi, ic.save(fi, is.EncryptKey)
 }
 
 // Load loads a FullIdentity from the config
 func (ic IdentityConfig) Load() (*FullIdentity, error) {
+	if ic.PeerIDDifficulty > math.MaxUint16 {
+		return nil, errs.New("peer id difficulty %d is higher than the maximum of %d", ic.PeerIDDifficulty, math.MaxUint16)
+	}
+
 	c, err := ioutil.ReadFile(ic.CertPath)
 	if err != nil {
 		return nil, peertls.ErrNotExist.Wrap(err)
@@ -228,32 +252,35 @@ func (ic IdentityConfig) Load() (*FullIdentity, error) {
 		return nil, errs.New("failed to load identity %#v, %#v: %v",
 			ic.CertPath, ic.KeyPath, err)
 	}
+	fi.PeerIDDifficulty = uint16(ic.PeerIDDifficulty)
+	fi.ExtensionHandlers = peertls.DefaultExtensionHandlers(fi.PeerCAWhitelist)
+	fi.PeerWhitelist, err = ic.PeerWhitelist.Load()
+	if err != nil {
+		return nil, err
+	}
 	return fi, nil
 }
 
-// Save saves a FullIdentity according to the config
+// Save saves a FullIdentity according to the config. An encrypted key file
+// stays encrypted.
 func (ic IdentityConfig) Save(fi *FullIdentity) error {
-	f := os.O_WRONLY | os.O_CREATE
+	return ic.save(fi, isEncryptedKeyFile(ic.KeyPath))
+}
+
+func (ic IdentityConfig) save(fi *FullIdentity, encrypt bool) error {
+	f := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
 	c, err := openCert(ic.CertPath, f)
 	if err != nil {
 		return err
 	}
 	defer utils.LogClose(c)
-	k, err := openKey(ic.KeyPath, f)
-	if err != nil {
-		return err
-	}
-	defer utils.LogClose(k)
 
 	chain := []*x509.Certificate{fi.Leaf, fi.CA}
 	chain = append(chain, fi.RestChain...)
 	if err = peertls.WriteChain(c, chain...); err != nil {
 		return err
 	}
-	if err = peertls.WriteKey(k, fi.Key); err != nil {
-		return err
-	}
-	return nil
+	return writeKeyFile(ic.KeyPath, fi.Key, encrypt)
 }
 
 // Run will run the given responsibilities with the configured identity.
@@ -276,8 +303,26 @@ func (ic IdentityConfig) Run(ctx context.Context, interceptor grpc.UnaryServerIn
 		return err
 	}
 	defer func() { _ = s.Close() }()
+
+	if ic.QUIC {
+		if err := s.ListenQUIC(ic.Address); err != nil {
+			return err
+		}
+	}
+	if ic.PrivateAddress != "" {
+		privateLis, err := net.Listen("tcp", ic.PrivateAddress)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = privateLis.Close() }()
+		s.ListenPrivate(privateLis)
+	}
 	zap.S().Infof("Node %s started", s.Identity().ID)
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go ic.rotateLeaf(ctx, s)
+
 	return s.Run(ctx)
 }
 
@@ -293,53 +338,137 @@ func (fi *FullIdentity) RestChainRaw() [][]byte {
 // ServerOption returns a grpc `ServerOption` for incoming connections
 // to the node with this full identity
 func (fi *FullIdentity) ServerOption(pcvFuncs ...peertls.PeerCertVerificationFunc) (grpc.ServerOption, error) {
+	tlsConfig, err := fi.serverTLSConfig(pcvFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.Creds(credentials.NewTLS(tlsConfig)), nil
+}
+
+// tlsCert returns the TLS certificate of the identity's chain and key
+func (fi *FullIdentity) tlsCert() (*tls.Certificate, error) {
 	ch := [][]byte{fi.Leaf.Raw, fi.CA.Raw}
 	ch = append(ch, fi.RestChainRaw()...)
-	c, err := peertls.TLSCert(ch, fi.Leaf, fi.Key)
+	return peertls.TLSCert(ch, fi.Leaf, fi.Key)
+}
+
+func (fi *FullIdentity) serverTLSConfig(pcvFuncs ...peertls.PeerCertVerificationFunc) (*tls.Config, error) {
+	c, err := fi.tlsCert()
 	if err != nil {
 		return nil, err
 	}
 
 	pcvFuncs = append(
-		[]peertls.PeerCertVerificationFunc{peertls.VerifyPeerCertChains},
+		[]peertls.PeerCertVerificationFunc{
+			peertls.VerifyPeerCertChains,
+			VerifyPeerDifficulty(fi.PeerIDDifficulty),
+			peertls.VerifyExtensions(fi.ExtensionHandlers),
+		},
 		pcvFuncs...,
 	)
-	tlsConfig := &tls.Config{
-		Certificates:       []tls.Certificate{*c},
-		InsecureSkipVerify: true,
-		ClientAuth:         tls.RequireAnyClientCert,
-		VerifyPeerCertificate: peertls.VerifyPeerFunc(
-			pcvFuncs...,
-		),
-	}
-
-	return grpc.Creds(credentials.NewTLS(tlsConfig)), nil
+	verify := peertls.VerifyPeerFunc(pcvFuncs...)
+	return &tls.Config{
+		Certificates:          []tls.Certificate{*c},
+		InsecureSkipVerify:    true,
+		ClientAuth:            tls.RequireAnyClientCert,
+		VerifyPeerCertificate: verify,
+		VerifyConnection:      peertls.VerifyResumedFunc(verify),
+	}, nil
 }
 
 // DialOption returns a grpc `DialOption` for making outgoing connections
 // to the node with this peer identity
 func (fi *FullIdentity) DialOption() (grpc.DialOption, error) {
 	// TODO(coyle): add ID
-	ch := [][]byte{fi.Leaf.Raw, fi.CA.Raw}
-	ch = append(ch, fi.RestChainRaw()...)
-	c, err := peertls.TLSCert(ch, fi.Leaf, fi.Key)
+	return fi.dialOption()
+}
+
+// DialOptionID returns a grpc `DialOption` for making outgoing connections
+// to the node with this peer identity, which only succeed if the dialed
+// peer's node id is id
+func (fi *FullIdentity) DialOptionID(id string) (grpc.DialOption, error) {
+	return fi.dialOption(VerifyPeerID(id))
+}
+
+func (fi *FullIdentity) dialOption(pcvFuncs ...peertls.PeerCertVerificationFunc) (grpc.DialOption, error) {
+	tlsConfig, err := fi.ClientTLSConfig(pcvFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	// nodes dialed over QUIC made the TLS handshake in QUIC already
+	return grpc.WithTransportCredentials(quic.Credentials(credentials.NewTLS(tlsConfig))), nil
+}
+
+// ClientTLSConfig returns the TLS configuration of outgoing connections
+// with this identity, which verifies peers with pcvFuncs too
+func (fi *FullIdentity) ClientTLSConfig(pcvFuncs ...peertls.PeerCertVerificationFunc) (*tls.Config, error) {
+	c, err := fi.tlsCert()
 	if err != nil {
 		return nil, err
 	}
 
-	tlsConfig := &tls.Config{
-		Certificates:       []tls.Certificate{*c},
-		InsecureSkipVerify: true,
-		VerifyPeerCertificate: peertls.VerifyPeerFunc(
+	pcvFuncs = append(
+		[]peertls.PeerCertVerificationFunc{
 			peertls.VerifyPeerCertChains,
-			func(_ [][]byte, parsedChains [][]*x509.Certificate) error {
-				return nil
-			},
-			// TODO(coyle): Check that the ID of the node we are dialing is the owner of the certificate.
-		),
+			VerifyPeerDifficulty(fi.PeerIDDifficulty),
+			peertls.VerifyExtensions(fi.ExtensionHandlers),
+		},
+		pcvFuncs...,
+	)
+	verify := peertls.VerifyPeerFunc(pcvFuncs...)
+	return &tls.Config{
+		Certificates:          []tls.Certificate{*c},
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: verify,
+		// resumed sessions may have been verified with other functions,
+		// e.g. for another node id, so they're verified again
+		VerifyConnection:   peertls.VerifyResumedFunc(verify),
+		ClientSessionCache: fi.SessionCache,
+	}, nil
+}
+
+// VerifyPeerID returns a peer certificate verification function which
+// rejects peers whose node id isn't id
+func VerifyPeerID(id string) peertls.PeerCertVerificationFunc {
+	return func(_ [][]byte, parsedChains [][]*x509.Certificate) error {
+		if len(parsedChains[0]) < 2 {
+			return ErrPeerID.New("certificate chain has no CA")
+		}
+
+		peerID, err := idFromKey(parsedChains[0][1].PublicKey)
+		if err != nil {
+			return ErrPeerID.Wrap(err)
+		}
+		if peerID.String() != id {
+			return ErrPeerID.New("dialed node %s, got %s", id, peerID)
+		}
+		return nil
 	}
+}
 
-	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
+// VerifyPeerDifficulty returns a peer certificate verification function
+// which rejects peers whose node id, which is derived from the CA of their
+// certificate chain, doesn't have at least the given difficulty. It returns
+// nil, i.e. no verification, if difficulty is 0.
+func VerifyPeerDifficulty(difficulty uint16) peertls.PeerCertVerificationFunc {
+	if difficulty == 0 {
+		return nil
+	}
+
+	return func(_ [][]byte, parsedChains [][]*x509.Certificate) error {
+		if len(parsedChains[0]) < 2 {
+			return ErrDifficulty.New("certificate chain has no CA")
+		}
+
+		id, err := idFromKey(parsedChains[0][1].PublicKey)
+		if err != nil {
+			return ErrDifficulty.Wrap(err)
+		}
+		if d := id.Difficulty(); d < difficulty {
+			return ErrDifficulty.New("node id %s has difficulty %d, at least %d is required", id, d, difficulty)
+		}
+		return nil
+	}
 }
 
 type nodeID string
@@ -139,6 +139,32 @@ func TestIdentityConfig_SaveIdentity(t *testing.T) {
 	assert.Equal(t, keyPEM.Bytes(), savedKeyPEM)
 }
 
+func TestIdentityConfig_EncryptedKey(t *testing.T) {
+	done, ic, fi, _ := tempIdentity(t)
+	defer done()
+
+	defer func(old func(string) ([]byte, error)) { Passphrase = old }(Passphrase)
+	defer func(old func(string) ([]byte, error)) { NewPassphrase = old }(NewPassphrase)
+	passphrase := []byte("passphrase")
+	Passphrase = func(string) ([]byte, error) { return passphrase, nil }
+	NewPassphrase = Passphrase
+
+	assert.NoError(t, ic.save(fi, true))
+	assert.True(t, isEncryptedKeyFile(ic.KeyPath))
+
+	loaded, err := ic.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, fi.Key, loaded.Key)
+
+	// re-saving keeps the key encrypted
+	assert.NoError(t, ic.Save(fi))
+	assert.True(t, isEncryptedKeyFile(ic.KeyPath))
+
+	passphrase = []byte("wrong")
+	_, err = ic.Load()
+	assert.Error(t, err)
+}
+
 func tempIdentityConfig() (*IdentityConfig, func(), error) {
 	tmpDir, err := ioutil.TempDir("", "storj-identity")
 	if err != nil {
@@ -229,6 +255,11 @@ func TestIdentityConfig_LoadIdentity(t *testing.T) {
 	fi, err = ic.Load()
 	assert.NoError(t, err)
 	assert.NotEmpty(t, fi.PeerCAWhitelist)
+
+	// difficulties which don't fit the one of the identity are rejected
+	ic.PeerIDDifficulty = 65548
+	_, err = ic.Load()
+	assert.Error(t, err)
 }
 
 func TestNodeID_Difficulty(t *testing.T) {
@@ -254,3 +285,24 @@ func TestVerifyPeer(t *testing.T) {
 	err = peertls.VerifyPeerFunc(peertls.VerifyPeerCertChains)([][]byte{fi.Leaf.Raw, fi.CA.Raw}, nil)
 	assert.NoError(t, err)
 }
+
+func TestVerifyPeerDifficulty(t *testing.T) {
+	ca, err := NewTestCA(context.Background())
+	assert.NoError(t, err)
+	fi, err := ca.NewIdentity()
+	assert.NoError(t, err)
+
+	assert.Nil(t, VerifyPeerDifficulty(0))
+
+	chains := [][]*x509.Certificate{{fi.Leaf, fi.CA}}
+	difficulty := fi.ID.Difficulty()
+
+	err = VerifyPeerDifficulty(difficulty)(nil, chains)
+	assert.NoError(t, err)
+
+	err = VerifyPeerDifficulty(difficulty+1)(nil, chains)
+	assert.True(t, ErrDifficulty.Has(err))
+
+	err = VerifyPeerDifficulty(difficulty)(nil, [][]*x509.Certificate{{fi.Leaf}})
+	assert.True(t, ErrDifficulty.Has(err))
+}
@@ -0,0 +1,143 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"time"
+
+	"storj.io/storj/pkg/peertls"
+	"storj.io/storj/pkg/utils"
+)
+
+// CertInfo describes a certificate of an identity's chain
+type CertInfo struct {
+	// Role is "leaf", "ca" or "parent" for the certificates after the CA
+	Role      string
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// ExpiredAt returns whether the certificate is expired at t
+func (c CertInfo) ExpiredAt(t time.Time) bool {
+	return t.After(c.NotAfter)
+}
+
+// IdentityInfo describes an identity: its node id and its certificate chain
+type IdentityInfo struct {
+	ID         string
+	Difficulty uint16
+	Certs      []CertInfo
+	// ChainError is the reason the chain is invalid, or nil if it's valid
+	ChainError error
+}
+
+// Inspect returns the description of pi
+func (pi *PeerIdentity) Inspect() *IdentityInfo {
+	chain := append([]*x509.Certificate{pi.Leaf, pi.CA}, pi.RestChain...)
+	info := &IdentityInfo{
+		ID:         pi.ID.String(),
+		Difficulty: pi.ID.Difficulty(),
+		ChainError: peertls.VerifyPeerCertChains(nil, [][]*x509.Certificate{chain}),
+	}
+	for i, cert := range chain {
+		role := "parent"
+		switch i {
+		case 0:
+			role = "leaf"
+		case 1:
+			role = "ca"
+		}
+		info.Certs = append(info.Certs, CertInfo{
+			Role:      role,
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+		})
+	}
+	return info
+}
+
+// PeerIdentity returns the public part of fi
+func (fi *FullIdentity) PeerIdentity() *PeerIdentity {
+	return &PeerIdentity{
+		RestChain: fi.RestChain,
+		CA:        fi.CA,
+		Leaf:      fi.Leaf,
+		ID:        fi.ID,
+	}
+}
+
+// PeerIdentityFromPEM loads a PeerIdentity from a certificate chain, leaf
+// first
+func PeerIdentityFromPEM(chainPEM []byte) (*PeerIdentity, error) {
+	cb, err := decodePEM(chainPEM)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if len(cb) < 2 {
+		return nil, Error.New("too few certificates in chain")
+	}
+	chain, err := ParseCertChain(cb)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return PeerIdentityFromCerts(chain[0], chain[1], chain[2:])
+}
+
+// FetchPeerIdentity connects to the node serving at address and returns its
+// identity. The connection is made with fi, as nodes only accept
+// connections from peers with an identity.
+func (fi *FullIdentity) FetchPeerIdentity(ctx context.Context, address string) (_ *PeerIdentity, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	c, err := fi.tlsCert()
+	if err != nil {
+		return nil, err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer utils.LogClose(conn)
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, Error.Wrap(err)
+		}
+	}
+
+	tlsConn := tls.Client(conn, &tls.Config{
+		Certificates:       []tls.Certificate{*c},
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: peertls.VerifyPeerFunc(
+			peertls.VerifyPeerCertChains,
+		),
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	certs := tlsConn.ConnectionState().PeerCertificates
+	if len(certs) < 2 {
+		return nil, Error.New("invalid certificate chain")
+	}
+	return PeerIdentityFromCerts(certs[0], certs[1], certs[2:])
+}
+
+// VerifyPeer checks that the node serving at address has the node id id and
+// returns its identity
+func (fi *FullIdentity) VerifyPeer(ctx context.Context, address, id string) (*PeerIdentity, error) {
+	pi, err := fi.FetchPeerIdentity(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if pi.ID.String() != id {
+		return pi, ErrPeerID.New("expected node %s at %s, got %s", id, address, pi.ID)
+	}
+	return pi, nil
+}
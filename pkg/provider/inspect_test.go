@@ -0,0 +1,106 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/peertls"
+)
+
+func TestInspect(t *testing.T) {
+	ctx := context.Background()
+	ca, err := NewTestCA(ctx)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	fi, err := ca.NewIdentity()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	var chainPEM bytes.Buffer
+	chain := append([]*x509.Certificate{fi.Leaf, fi.CA}, fi.RestChain...)
+	if !assert.NoError(t, peertls.WriteChain(&chainPEM, chain...)) {
+		t.FailNow()
+	}
+	pi, err := PeerIdentityFromPEM(chainPEM.Bytes())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	info := pi.Inspect()
+	assert.Equal(t, fi.ID.String(), info.ID)
+	assert.Equal(t, fi.ID.Difficulty(), info.Difficulty)
+	assert.NoError(t, info.ChainError)
+	if assert.Len(t, info.Certs, 2) {
+		assert.Equal(t, "leaf", info.Certs[0].Role)
+		assert.Equal(t, "ca", info.Certs[1].Role)
+		assert.False(t, info.Certs[0].ExpiredAt(time.Now()))
+		assert.True(t, info.Certs[0].ExpiredAt(fi.Leaf.NotAfter.Add(time.Second)))
+	}
+
+	// a leaf of another CA breaks the chain
+	other, err := NewTestCA(ctx)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	otherFi, err := other.NewIdentity()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	broken := &PeerIdentity{CA: fi.CA, Leaf: otherFi.Leaf, ID: fi.ID}
+	assert.Error(t, broken.Inspect().ChainError)
+}
+
+func TestFullIdentity_VerifyPeer(t *testing.T) {
+	ctx := context.Background()
+	ca, err := NewTestCA(ctx)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	serverFi, err := ca.NewIdentity()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	clientCA, err := NewTestCA(ctx)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	clientFi, err := clientCA.NewIdentity()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer func() { _ = lis.Close() }()
+	p, err := NewProvider(serverFi, lis, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	go func() { _ = p.Run(ctx) }()
+	defer func() { _ = p.Close() }()
+
+	address := lis.Addr().String()
+	pi, err := clientFi.VerifyPeer(ctx, address, serverFi.ID.String())
+	if assert.NoError(t, err) {
+		assert.Equal(t, serverFi.ID, pi.ID)
+	}
+
+	pi, err = clientFi.VerifyPeer(ctx, address, clientFi.ID.String())
+	assert.True(t, ErrPeerID.Has(err))
+	if assert.NotNil(t, pi) {
+		assert.Equal(t, serverFi.ID, pi.ID)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"context"
+	"io"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"storj.io/storj/storage"
+)
+
+// ChainUnaryInterceptors returns an interceptor running the given
+// interceptors in order, the first one being the outermost
+func ChainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return runUnary(ctx, interceptors, req, info, handler)
+	}
+}
+
+// ChainStreamInterceptors returns an interceptor running the given
+// interceptors in order, the first one being the outermost
+func ChainStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return runStream(interceptors, srv, ss, info, handler)
+	}
+}
+
+// ChainUnaryClientInterceptors returns a client interceptor running the
+// given interceptors in order, the first one being the outermost
+func ChainUnaryClientInterceptors(interceptors ...grpc.UnaryClientInterceptor) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return runUnaryClient(ctx, interceptors, method, req, reply, cc, invoker, opts...)
+	}
+}
+
+func runUnary(ctx context.Context, interceptors []grpc.UnaryServerInterceptor, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if len(interceptors) == 0 {
+		return handler(ctx, req)
+	}
+	return interceptors[0](ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return runUnary(ctx, interceptors[1:], req, info, handler)
+	})
+}
+
+func runUnaryClient(ctx context.Context, interceptors []grpc.UnaryClientInterceptor, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if len(interceptors) == 0 {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	return interceptors[0](ctx, method, req, reply, cc, func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return runUnaryClient(ctx, interceptors[1:], method, req, reply, cc, invoker, opts...)
+	}, opts...)
+}
+
+func runStream(interceptors []grpc.StreamServerInterceptor, srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if len(interceptors) == 0 {
+		return handler(srv, ss)
+	}
+	return interceptors[0](srv, ss, info, func(srv interface{}, ss grpc.ServerStream) error {
+		return runStream(interceptors[1:], srv, ss, info, handler)
+	})
+}
+
+// recoverUnary turns a panic of a unary handler into an internal error so
+// that it doesn't take the whole process down
+func recoverUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			zap.S().Errorf("panic in %s: %v\n%s", info.FullMethod, r, debug.Stack())
+			resp, err = nil, status.Errorf(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// recoverStream turns a panic of a stream handler into an internal error so
+// that it doesn't take the whole process down
+func recoverStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			zap.S().Errorf("panic in %s: %v\n%s", info.FullMethod, r, debug.Stack())
+			err = status.Errorf(codes.Internal, "internal error")
+		}
+	}()
+	return handler(srv, ss)
+}
+
+// monitorUnary records a monkit task per unary method, continuing the
+// request id and the monkit trace of the calling node
+func monitorUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	ctx = incomingContext(ctx)
+	defer remoteTask(&ctx, info.FullMethod)(&err)
+	return handler(ctx, req)
+}
+
+// monitorStream records a monkit task per stream method, continuing the
+// request id and the monkit trace of the calling node
+func monitorStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx := incomingContext(ss.Context())
+	defer remoteTask(&ctx, info.FullMethod)(&err)
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+func logUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{},
+	err error) {
+	resp, err = handler(ctx, req)
+	if err != nil {
+		// no zap errors for wrong file downloads
+		if status.Code(err) == codes.NotFound {
+			return resp, err
+		}
+		zap.S().With(zap.String("RequestID", RequestID(ctx))).Errorf("%+v", err)
+	}
+	return resp, err
+}
+
+func logStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	err = handler(srv, ss)
+	if err != nil {
+		// no zap errors for canceled or wrong file downloads
+		if storage.ErrKeyNotFound.Has(err) ||
+			status.Code(err) == codes.Canceled ||
+			status.Code(err) == codes.Unavailable ||
+			err == io.EOF {
+			return err
+		}
+		zap.S().With(zap.String("RequestID", RequestID(ss.Context()))).Errorf("%+v", err)
+	}
+	return err
+}
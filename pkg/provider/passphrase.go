@@ -0,0 +1,122 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/ssh/terminal"
+
+	"storj.io/storj/pkg/peertls"
+	"storj.io/storj/pkg/utils"
+)
+
+// PassphraseEnv is the environment variable passphrases for encrypted
+// private keys are read from
+const PassphraseEnv = "STORJ_KEY_PASSPHRASE"
+
+var (
+	// Passphrase returns the passphrase to decrypt the key at path with. It
+	// uses PassphraseEnv if set and otherwise prompts on the terminal.
+	Passphrase = func(path string) ([]byte, error) {
+		return readPassphrase(fmt.Sprintf("Passphrase for %s: ", path), false)
+	}
+	// NewPassphrase returns the passphrase to encrypt a new key at path
+	// with. It uses PassphraseEnv if set and otherwise prompts on the
+	// terminal, asking twice.
+	NewPassphrase = func(path string) ([]byte, error) {
+		return readPassphrase(fmt.Sprintf("New passphrase for %s: ", path), true)
+	}
+)
+
+func readPassphrase(prompt string, confirm bool) ([]byte, error) {
+	if p, ok := os.LookupEnv(PassphraseEnv); ok {
+		return []byte(p), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return nil, peertls.ErrPassphrase.New("no terminal to prompt on, set %s", PassphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	p, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, peertls.ErrPassphrase.Wrap(err)
+	}
+	if !confirm {
+		return p, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+	again, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, peertls.ErrPassphrase.Wrap(err)
+	}
+	if !bytes.Equal(p, again) {
+		return nil, peertls.ErrPassphrase.New("passphrases don't match")
+	}
+	return p, nil
+}
+
+// parseKeyPEM parses the first private key in keyPEM, decrypting it if it
+// is encrypted. path is used to ask for the passphrase.
+func parseKeyPEM(keyPEM []byte, path string) (crypto.PrivateKey, error) {
+	b, _ := pem.Decode(keyPEM)
+	if b == nil {
+		return nil, ErrZeroBytes
+	}
+
+	if !peertls.IsEncryptedKeyBlock(b) {
+		return peertls.ParseKey(b.Bytes)
+	}
+
+	passphrase, err := Passphrase(path)
+	if err != nil {
+		return nil, err
+	}
+	return peertls.DecryptKeyBlock(b, passphrase)
+}
+
+// isEncryptedKeyFile returns whether the key file at path exists and is
+// encrypted
+func isEncryptedKeyFile(path string) bool {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	b, _ := pem.Decode(data)
+	return peertls.IsEncryptedKeyBlock(b)
+}
+
+// writeKeyFile writes key to path, encrypted with a new passphrase if
+// encrypt is set
+func writeKeyFile(path string, key crypto.PrivateKey, encrypt bool) error {
+	var passphrase []byte
+	if encrypt {
+		var err error
+		passphrase, err = NewPassphrase(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	k, err := openKey(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	defer utils.LogClose(k)
+
+	if encrypt {
+		return peertls.WriteEncryptedKey(k, key, passphrase)
+	}
+	return peertls.WriteKey(k, key)
+}
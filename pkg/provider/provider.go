@@ -5,18 +5,18 @@ package provider
 
 import (
 	"context"
-	"io"
+	"crypto/tls"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/zeebo/errs"
-	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/credentials"
 
+	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/peertls"
-	"storj.io/storj/storage"
+	"storj.io/storj/pkg/transport/quic"
 )
 
 var (
@@ -34,10 +34,21 @@ type Responsibility interface {
 // Provider represents a bundle of responsibilities defined by a specific ID.
 // Examples of providers are the heavy client, the storagenode, and the gateway.
 type Provider struct {
-	lis      net.Listener
-	grpc     *grpc.Server
-	next     []Responsibility
+	lis  net.Listener
+	grpc *grpc.Server
+	next []Responsibility
+
+	tlsConfig  *tls.Config
+	creds      credentials.TransportCredentials
+	quicLis    net.Listener
+	privateLis net.Listener
+	private    *grpc.Server
+	unary      []grpc.UnaryServerInterceptor
+	stream     []grpc.StreamServerInterceptor
+
+	mu       sync.RWMutex
 	identity *FullIdentity
+	cert     *tls.Certificate
 }
 
 // NewProvider creates a Provider out of an Identity, a net.Listener, a UnaryInterceptorProvider and
@@ -45,29 +56,55 @@ type Provider struct {
 func NewProvider(identity *FullIdentity, lis net.Listener, interceptor grpc.UnaryServerInterceptor,
 	responsibilities ...Responsibility) (*Provider, error) {
 	// NB: talk to anyone with an identity
-	ident, err := identity.ServerOption(peertls.VerifyCAWhitelist(
-		identity.PeerCAWhitelist,
-		identity.VerifyAuthExtSig,
-	))
+	tlsConfig, err := identity.serverTLSConfig(
+		peertls.VerifyCAWhitelist(
+			identity.PeerCAWhitelist,
+			identity.VerifyAuthExtSig,
+		),
+		VerifyPeerWhitelist(identity.PeerWhitelist),
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	unaryInterceptor := unaryInterceptor
+	p := &Provider{
+		lis:      lis,
+		next:     responsibilities,
+		unary:    []grpc.UnaryServerInterceptor{recoverUnary, monitorUnary, logUnary},
+		stream:   []grpc.StreamServerInterceptor{recoverStream, monitorStream, logStream},
+		identity: identity,
+		cert:     &tlsConfig.Certificates[0],
+	}
 	if interceptor != nil {
-		unaryInterceptor = combineInterceptors(unaryInterceptor, interceptor)
+		p.unary = append(p.unary, interceptor)
 	}
 
-	return &Provider{
-		lis: lis,
-		grpc: grpc.NewServer(
-			grpc.StreamInterceptor(streamInterceptor),
-			grpc.UnaryInterceptor(unaryInterceptor),
-			ident,
-		),
-		next:     responsibilities,
-		identity: identity,
-	}, nil
+	// the certificate is looked up per handshake, so that a rotated leaf is
+	// used for new connections right away
+	tlsConfig.Certificates = nil
+	tlsConfig.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		p.mu.RLock()
+		defer p.mu.RUnlock()
+		return p.cert, nil
+	}
+
+	p.tlsConfig = tlsConfig
+	p.creds = quic.Credentials(credentials.NewTLS(tlsConfig))
+	p.grpc = p.newServer()
+	return p, nil
+}
+
+// newServer returns a gRPC server running the provider's interceptor chains
+func (p *Provider) newServer() *grpc.Server {
+	return grpc.NewServer(
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return runStream(p.stream, srv, ss, info, handler)
+		}),
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return runUnary(ctx, p.unary, req, info, handler)
+		}),
+		grpc.Creds(p.creds),
+	)
 }
 
 // SetupIdentity ensures a CA and identity exist and returns a config overrides map
@@ -99,14 +136,113 @@ func SetupIdentity(ctx context.Context, c CASetupConfig, i IdentitySetupConfig)
 }
 
 // Identity returns the provider's identity
-func (p *Provider) Identity() *FullIdentity { return p.identity }
+func (p *Provider) Identity() *FullIdentity {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.identity
+}
+
+// RotateLeaf replaces the leaf of the provider's identity with a new one
+// signed by ca, which has to be the identity's CA, and returns the new
+// identity. New connections are made with the new leaf; it's up to the
+// caller to save the identity to keep it across restarts.
+func (p *Provider) RotateLeaf(ca *FullCertificateAuthority) (*FullIdentity, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ca.ID != p.identity.ID {
+		return nil, ErrLeafRotation.New("CA %s didn't issue identity %s", ca.ID, p.identity.ID)
+	}
+
+	// the new leaf revokes the old one, so that peers with the default
+	// extension handlers reject it once they've seen the new one
+	revocation, err := peertls.NewRevocationExt(ca.Key, p.identity.Leaf)
+	if err != nil {
+		return nil, ErrLeafRotation.Wrap(err)
+	}
+	fi, err := ca.NewIdentity(revocation)
+	if err != nil {
+		return nil, ErrLeafRotation.Wrap(err)
+	}
+	fi.RestChain = p.identity.RestChain
+	fi.PeerCAWhitelist = p.identity.PeerCAWhitelist
+	fi.VerifyAuthExtSig = p.identity.VerifyAuthExtSig
+	fi.PeerIDDifficulty = p.identity.PeerIDDifficulty
+	fi.PeerWhitelist = p.identity.PeerWhitelist
+	fi.ExtensionHandlers = p.identity.ExtensionHandlers
+	fi.SessionCache = p.identity.SessionCache
+
+	cert, err := fi.tlsCert()
+	if err != nil {
+		return nil, ErrLeafRotation.Wrap(err)
+	}
+	p.identity, p.cert = fi, cert
+	return fi, nil
+}
 
 // GRPC returns the provider's gRPC server for registration purposes
 func (p *Provider) GRPC() *grpc.Server { return p.grpc }
 
+// PrivateGRPC returns the provider's gRPC server for services that are only
+// meant for other parts of the same deployment. It's the public server
+// unless the provider got a private listener with ListenPrivate.
+func (p *Provider) PrivateGRPC() *grpc.Server {
+	if p.private == nil {
+		return p.grpc
+	}
+	return p.private
+}
+
+// ListenPrivate makes the provider serve its private gRPC server on lis. It
+// has to be called before the responsibilities run.
+func (p *Provider) ListenPrivate(lis net.Listener) {
+	p.privateLis = lis
+	p.private = p.newServer()
+}
+
+// ListenQUIC makes the provider serve its public gRPC server over QUIC as
+// well, on the UDP port of address. It has to be called before the
+// responsibilities run.
+func (p *Provider) ListenQUIC(address string) error {
+	lis, err := quic.Listen(address, p.tlsConfig)
+	if err != nil {
+		return err
+	}
+	p.quicLis = lis
+	return nil
+}
+
+// Transport returns the transport other nodes can dial the provider with
+func (p *Provider) Transport() pb.NodeTransport {
+	if p.quicLis != nil {
+		return pb.NodeTransport_QUIC_TLS_GRPC
+	}
+	return pb.NodeTransport_TCP_TLS_GRPC
+}
+
+// AddUnaryInterceptor appends interceptors to the chain of interceptors run
+// for unary calls, after the recovery, metrics and logging ones. As the
+// chain is fixed once the provider serves, responsibilities add theirs in
+// Run before running the next responsibility.
+func (p *Provider) AddUnaryInterceptor(interceptors ...grpc.UnaryServerInterceptor) {
+	p.unary = append(p.unary, interceptors...)
+}
+
+// AddStreamInterceptor appends interceptors to the chain of interceptors run
+// for streams, with the same constraints as AddUnaryInterceptor
+func (p *Provider) AddStreamInterceptor(interceptors ...grpc.StreamServerInterceptor) {
+	p.stream = append(p.stream, interceptors...)
+}
+
 // Close shuts down the provider
 func (p *Provider) Close() error {
+	if p.private != nil {
+		p.private.GracefulStop()
+	}
 	p.grpc.GracefulStop()
+	if p.quicLis != nil {
+		return p.quicLis.Close()
+	}
 	return nil
 }
 
@@ -122,43 +258,22 @@ func (p *Provider) Run(ctx context.Context) (err error) {
 		return next.Run(ctx, p)
 	}
 
-	return p.grpc.Serve(p.lis)
-}
-
-func streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
-	err = handler(srv, ss)
-	if err != nil {
-		// no zap errors for canceled or wrong file downloads
-		if storage.ErrKeyNotFound.Has(err) ||
-			status.Code(err) == codes.Canceled ||
-			status.Code(err) == codes.Unavailable ||
-			err == io.EOF {
-			return err
-		}
-		zap.S().Errorf("%+v", err)
+	serve := []func() error{func() error { return p.grpc.Serve(p.lis) }}
+	if p.quicLis != nil {
+		serve = append(serve, func() error { return p.grpc.Serve(p.quicLis) })
 	}
-	return err
-}
-
-func unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{},
-	err error) {
-	resp, err = handler(ctx, req)
-	if err != nil {
-		// no zap errors for wrong file downloads
-		if status.Code(err) == codes.NotFound {
-			return resp, err
-		}
-		zap.S().Errorf("%+v", err)
+	if p.private != nil {
+		serve = append(serve, func() error { return p.private.Serve(p.privateLis) })
+	}
+	if len(serve) == 1 {
+		return serve[0]()
 	}
-	return resp, err
-}
 
-func combineInterceptors(a, b grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		return a(ctx, req, info, func(actx context.Context, areq interface{}) (interface{}, error) {
-			return b(actx, areq, info, func(bctx context.Context, breq interface{}) (interface{}, error) {
-				return handler(bctx, breq)
-			})
-		})
+	// the servers run until the provider is closed, an error of any is
+	// returned as soon as it occurs
+	errch := make(chan error, len(serve))
+	for _, f := range serve {
+		go func(f func() error) { errch <- f() }(f)
 	}
+	return <-errch
 }
@@ -0,0 +1,78 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+)
+
+// ErrLeafRotation is used when the leaf of an identity can't be rotated
+var ErrLeafRotation = errs.Class("leaf rotation error")
+
+// LeafRotationConfig configures the replacement of leaf certificates before
+// they expire
+type LeafRotationConfig struct {
+	CheckInterval time.Duration `help:"how often the expiry of the leaf certificate is checked" default:"1h"`
+	RenewBefore   time.Duration `help:"how long before its expiry the leaf certificate is replaced, 0 disables rotation" default:"168h"`
+	CA            FullCAConfig
+}
+
+// rotateLeaf checks the leaf of the provider's identity every check
+// interval until ctx is done, replacing it before it expires
+func (ic IdentityConfig) rotateLeaf(ctx context.Context, p *Provider) {
+	if ic.LeafRotation.CheckInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(ic.LeafRotation.CheckInterval)
+	defer ticker.Stop()
+	for {
+		if err := ic.checkLeaf(p); err != nil {
+			zap.S().Errorf("unable to rotate leaf certificate: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkLeaf reports the expiry of the provider's leaf and, if it's due for
+// renewal, replaces it with one signed by the configured CA and saves it
+func (ic IdentityConfig) checkLeaf(p *Provider) error {
+	leaf := p.Identity().Leaf
+	remaining := time.Until(leaf.NotAfter)
+	mon.IntVal("leaf_seconds_until_expiry").Observe(int64(remaining / time.Second))
+
+	renewBefore := ic.LeafRotation.RenewBefore
+	if remaining > renewBefore {
+		return nil
+	}
+	if renewBefore <= 0 {
+		if remaining <= 0 {
+			zap.S().Warnf("leaf certificate expired at %s and rotation is disabled, peers may refuse connections", leaf.NotAfter)
+		}
+		return nil
+	}
+
+	zap.S().Infof("leaf certificate expires at %s, rotating it", leaf.NotAfter)
+	ca, err := ic.LeafRotation.CA.Load()
+	if err != nil {
+		return ErrLeafRotation.Wrap(err)
+	}
+	fi, err := p.RotateLeaf(ca)
+	if err != nil {
+		return err
+	}
+	mon.Event("leaf_rotated")
+	zap.S().Infof("rotated leaf certificate, the new one expires at %s", fi.Leaf.NotAfter)
+
+	return ErrLeafRotation.Wrap(ic.Save(fi))
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/peertls"
+)
+
+func TestIdentityConfig_CheckLeaf(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "leaf-rotation")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	ca, err := NewCA(ctx, NewCAOptions{Difficulty: 4})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	caConfig := FullCAConfig{
+		CertPath: filepath.Join(dir, "ca.cert"),
+		KeyPath:  filepath.Join(dir, "ca.key"),
+	}
+	assert.NoError(t, caConfig.Save(ca))
+
+	fi, err := ca.NewIdentity()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.WithinDuration(t, time.Now().Add(peertls.LeafValidity), fi.Leaf.NotAfter, time.Minute)
+
+	ic := IdentityConfig{
+		CertPath: filepath.Join(dir, "identity.cert"),
+		KeyPath:  filepath.Join(dir, "identity.key"),
+		LeafRotation: LeafRotationConfig{
+			RenewBefore: time.Hour,
+			CA:          caConfig,
+		},
+	}
+	assert.NoError(t, ic.Save(fi))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer func() { _ = lis.Close() }()
+	p, err := NewProvider(fi, lis, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// far from expiry, nothing happens
+	assert.NoError(t, ic.checkLeaf(p))
+	assert.Equal(t, fi, p.Identity())
+
+	// due for renewal, the leaf is replaced and saved
+	ic.LeafRotation.RenewBefore = 2 * peertls.LeafValidity
+	assert.NoError(t, ic.checkLeaf(p))
+	rotated := p.Identity()
+	assert.NotEqual(t, fi.Leaf.Raw, rotated.Leaf.Raw)
+	assert.Equal(t, fi.CA.Raw, rotated.CA.Raw)
+	assert.Equal(t, fi.ID, rotated.ID)
+
+	loaded, err := ic.Load()
+	if assert.NoError(t, err) {
+		assert.Equal(t, rotated.Leaf.Raw, loaded.Leaf.Raw)
+
+		// peers loaded with the default extension handlers reject the old
+		// leaf once they've seen the rotated one, which revokes it
+		verify := peertls.VerifyExtensions(loaded.ExtensionHandlers)
+		if assert.NotNil(t, verify) {
+			assert.NoError(t, verify(nil, [][]*x509.Certificate{{fi.Leaf, fi.CA}}))
+			assert.NoError(t, verify(nil, [][]*x509.Certificate{{rotated.Leaf, rotated.CA}}))
+			assert.Error(t, verify(nil, [][]*x509.Certificate{{fi.Leaf, fi.CA}}))
+		}
+	}
+
+	// a CA which didn't issue the identity can't rotate its leaf
+	other, err := NewCA(ctx, NewCAOptions{Difficulty: 4})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	_, err = p.RotateLeaf(other)
+	assert.True(t, ErrLeafRotation.Has(err))
+	assert.Equal(t, rotated, p.Identity())
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	monkit "gopkg.in/spacemonkeygo/monkit.v2"
+)
+
+// metadata keys propagating requests from node to node
+const (
+	requestIDKey = "storj-request-id"
+	traceIDKey   = "storj-trace-id"
+	parentIDKey  = "storj-parent-id"
+)
+
+type requestIDCtxKey struct{}
+
+// NewRequestID returns a new random request id
+func NewRequestID() string {
+	return fmt.Sprintf("%016x", uint64(monkit.NewId()))
+}
+
+// WithRequestID returns a context carrying the request id id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+// RequestID returns the request id carried by ctx, or "" if it has none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+// OutgoingContext returns ctx with the metadata passing its request id and
+// its monkit trace on to the called node. Contexts without a request id get
+// a new one, so calls made on behalf of the same request share it.
+func OutgoingContext(ctx context.Context) context.Context {
+	id := RequestID(ctx)
+	if id == "" {
+		id = NewRequestID()
+		ctx = WithRequestID(ctx, id)
+	}
+
+	pairs := []string{requestIDKey, id}
+	if span := monkit.SpanFromCtx(ctx); span != nil {
+		pairs = append(pairs,
+			traceIDKey, strconv.FormatInt(span.Trace().Id(), 10),
+			parentIDKey, strconv.FormatInt(span.Id(), 10),
+		)
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// TraceUnaryClient is a client interceptor passing the request id and the
+// monkit trace of unary calls on to the called node
+func TraceUnaryClient(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(OutgoingContext(ctx), method, req, reply, cc, opts...)
+}
+
+// TraceStreamClient is a client interceptor passing the request id and the
+// monkit trace of streams on to the called node
+func TraceStreamClient(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+	streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(OutgoingContext(ctx), desc, cc, method, opts...)
+}
+
+// incomingContext returns ctx carrying the request id sent by the calling
+// node, or a new one if it didn't send any
+func incomingContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := firstValue(md, requestIDKey)
+	if id == "" {
+		id = NewRequestID()
+	}
+	return WithRequestID(ctx, id)
+}
+
+// remoteTask starts the monkit task of a call to method, continuing the
+// trace of the calling node if it sent one
+func remoteTask(ctx *context.Context, method string) func(*error) {
+	md, _ := metadata.FromIncomingContext(*ctx)
+	traceID, traceErr := strconv.ParseInt(firstValue(md, traceIDKey), 10, 64)
+	parentID, parentErr := strconv.ParseInt(firstValue(md, parentIDKey), 10, 64)
+	if traceErr != nil || parentErr != nil {
+		return mon.TaskNamed(method)(ctx)
+	}
+	return mon.FuncNamed(method).RemoteTrace(ctx, parentID, monkit.NewTrace(traceID))
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// contextStream is a server stream with its context replaced
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream
+func (s *contextStream) Context() context.Context { return s.ctx }
@@ -0,0 +1,61 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	monkit "gopkg.in/spacemonkeygo/monkit.v2"
+)
+
+// forward turns the outgoing metadata of ctx into the incoming metadata of
+// the called node
+func forward(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	// calls without a request id get a new one
+	ctx := OutgoingContext(context.Background())
+	id := RequestID(ctx)
+	assert.NotEmpty(t, id)
+
+	// which is passed on to the called node
+	incoming := incomingContext(forward(ctx))
+	assert.Equal(t, id, RequestID(incoming))
+	assert.Equal(t, id, RequestID(incomingContext(forward(OutgoingContext(incoming)))))
+
+	// nodes called without a request id make one up
+	assert.NotEmpty(t, RequestID(incomingContext(context.Background())))
+	assert.NotEqual(t, NewRequestID(), NewRequestID())
+}
+
+func TestTracePropagation(t *testing.T) {
+	ctx := context.Background()
+	defer mon.Task()(&ctx)(nil)
+	span := monkit.SpanFromCtx(ctx)
+	if !assert.NotNil(t, span) {
+		t.FailNow()
+	}
+
+	// the called node continues the trace of the caller
+	remote := forward(OutgoingContext(ctx))
+	defer remoteTask(&remote, "/test/Method")(nil)
+	remoteSpan := monkit.SpanFromCtx(remote)
+	if assert.NotNil(t, remoteSpan) {
+		assert.Equal(t, span.Trace().Id(), remoteSpan.Trace().Id())
+	}
+
+	// or starts a new one
+	local := context.Background()
+	defer remoteTask(&local, "/test/Method")(nil)
+	localSpan := monkit.SpanFromCtx(local)
+	if assert.NotNil(t, localSpan) {
+		assert.NotEqual(t, span.Trace().Id(), localSpan.Trace().Id())
+	}
+}
@@ -6,15 +6,13 @@ package provider
 import (
 	"context"
 	"crypto"
-	"crypto/ecdsa"
-	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
-	"encoding/asn1"
 	"encoding/base64"
 	"encoding/pem"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 
 	"github.com/zeebo/errs"
 	"golang.org/x/crypto/sha3"
@@ -59,53 +57,75 @@ func decodePEM(PEMBytes []byte) ([][]byte, error) {
 	return DERBytes, nil
 }
 
-func newCAWorker(ctx context.Context, difficulty uint16, parentCert *x509.Certificate, parentKey crypto.PrivateKey, caC chan FullCertificateAuthority, eC chan error) {
-	var (
-		k   crypto.PrivateKey
-		i   nodeID
-		err error
-	)
-	for {
+// newKeyWorker generates keys of the given type until ctx is done, counting
+// them in attempts and sending each key whose node id is harder than the
+// previous ones it sent
+func newKeyWorker(ctx context.Context, keyType string, attempts *uint64, keyC chan<- crypto.PrivateKey, eC chan<- error) {
+	var best uint16
+	for first := true; ; first = false {
 		select {
 		case <-ctx.Done():
 			return
 		default:
-			k, err = peertls.NewKey()
-			if err != nil {
-				eC <- err
-				return
-			}
-			switch kE := k.(type) {
-			case *ecdsa.PrivateKey:
-				i, err = idFromKey(&kE.PublicKey)
-				if err != nil {
-					eC <- err
-					return
-				}
-			default:
-				eC <- peertls.ErrUnsupportedKey.New("%T", k)
-				return
-			}
 		}
 
-		if i.Difficulty() >= difficulty {
-			break
+		k, err := peertls.NewKeyOfType(keyType)
+		if err != nil {
+			eC <- err
+			return
+		}
+		atomic.AddUint64(attempts, 1)
+
+		d := difficultyOf(k)
+		if !first && d <= best {
+			continue
+		}
+		best = d
+
+		select {
+		case keyC <- k:
+		case <-ctx.Done():
+			return
 		}
 	}
+}
+
+// difficultyOf returns the difficulty of the node id derived from k
+func difficultyOf(k crypto.PrivateKey) uint16 {
+	pk, err := peertls.PublicKey(k)
+	if err != nil {
+		return 0
+	}
+	id, err := idFromKey(pk)
+	if err != nil {
+		return 0
+	}
+	return id.Difficulty()
+}
+
+// newCAFromKey creates a CA certificate for k, signed by the parent if
+// there is one
+func newCAFromKey(k crypto.PrivateKey, parentCert *x509.Certificate, parentKey crypto.PrivateKey) (*FullCertificateAuthority, error) {
+	pk, err := peertls.PublicKey(k)
+	if err != nil {
+		return nil, err
+	}
+	i, err := idFromKey(pk)
+	if err != nil {
+		return nil, err
+	}
 
 	ct, err := peertls.CATemplate()
 	if err != nil {
-		eC <- err
-		return
+		return nil, err
 	}
 
 	c, err := newCACert(k, parentKey, ct, parentCert)
 	if err != nil {
-		eC <- err
-		return
+		return nil, err
 	}
 
-	ca := FullCertificateAuthority{
+	ca := &FullCertificateAuthority{
 		Cert: c,
 		Key:  k,
 		ID:   i,
@@ -113,13 +133,13 @@ func newCAWorker(ctx context.Context, difficulty uint16, parentCert *x509.Certif
 	if parentCert != nil {
 		ca.RestChain = []*x509.Certificate{parentCert}
 	}
-	caC <- ca
+	return ca, nil
 }
 
 func newCACert(key, parentKey crypto.PrivateKey, template, parentCert *x509.Certificate) (*x509.Certificate, error) {
-	p, ok := key.(*ecdsa.PrivateKey)
-	if !ok {
-		return nil, peertls.ErrUnsupportedKey.New("%T", key)
+	pk, err := peertls.PublicKey(key)
+	if err != nil {
+		return nil, err
 	}
 
 	var signingKey crypto.PrivateKey
@@ -129,30 +149,15 @@ func newCACert(key, parentKey crypto.PrivateKey, template, parentCert *x509.Cert
 		signingKey = key
 	}
 
-	cert, err := peertls.NewCert(template, parentCert, &p.PublicKey, signingKey)
+	cert, err := peertls.NewCert(template, parentCert, pk, signingKey)
 	if err != nil {
 		return nil, err
 	}
 
 	if parentKey != nil {
-		p, ok := parentKey.(*ecdsa.PrivateKey)
-		if !ok {
-			return nil, peertls.ErrUnsupportedKey.New("%T", key)
-		}
-
-		hash := crypto.SHA256.New()
-		_, err := hash.Write(cert.RawTBSCertificate)
-		if err != nil {
-			return nil, peertls.ErrSign.Wrap(err)
-		}
-		r, s, err := ecdsa.Sign(rand.Reader, p, hash.Sum(nil))
-		if err != nil {
-			return nil, peertls.ErrSign.Wrap(err)
-		}
-
-		signature, err := asn1.Marshal(peertls.ECDSASignature{R: r, S: s})
+		signature, err := peertls.SignData(parentKey, cert.RawTBSCertificate)
 		if err != nil {
-			return nil, peertls.ErrSign.Wrap(err)
+			return nil, err
 		}
 
 		cert.ExtraExtensions = append(cert.ExtraExtensions, pkix.Extension{
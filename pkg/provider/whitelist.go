@@ -0,0 +1,135 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"io/ioutil"
+	"strings"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/peertls"
+)
+
+// ErrPeerNotWhitelisted is used when a peer is neither whitelisted by node id
+// nor signed by a trusted CA
+var ErrPeerNotWhitelisted = errs.Class("peer not whitelisted")
+
+// PeerWhitelistConfig configures which peers are allowed to connect
+type PeerWhitelistConfig struct {
+	PeerIDs       string `help:"comma separated node ids of the peers allowed to connect" default:""`
+	TrustedCAPath string `help:"path to the certificates of the CAs whose signed peers are allowed to connect" default:""`
+}
+
+// Load returns the configured whitelist, or nil if neither peer ids nor
+// trusted CAs are configured, in which case any peer is allowed
+func (c PeerWhitelistConfig) Load() (*PeerWhitelist, error) {
+	var ids []string
+	for _, id := range strings.Split(c.PeerIDs, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	var cas []*x509.Certificate
+	if c.TrustedCAPath != "" {
+		data, err := ioutil.ReadFile(c.TrustedCAPath)
+		if err != nil {
+			return nil, ErrPeerNotWhitelisted.Wrap(err)
+		}
+		raw, err := decodePEM(data)
+		if err != nil {
+			return nil, ErrPeerNotWhitelisted.Wrap(err)
+		}
+		if cas, err = ParseCertChain(raw); err != nil {
+			return nil, ErrPeerNotWhitelisted.Wrap(err)
+		}
+		if len(cas) == 0 {
+			return nil, ErrPeerNotWhitelisted.New("no certificates in %s", c.TrustedCAPath)
+		}
+	}
+
+	if len(ids) == 0 && len(cas) == 0 {
+		return nil, nil
+	}
+	return NewPeerWhitelist(ids, cas), nil
+}
+
+// PeerWhitelist restricts the peers accepted to the ones with a whitelisted
+// node id and the ones whose CA is signed by a trusted CA. The trusted CAs
+// themselves are only allowed if their node id is whitelisted. A nil
+// PeerWhitelist allows any peer.
+type PeerWhitelist struct {
+	ids map[string]bool
+	cas []*x509.Certificate
+}
+
+// NewPeerWhitelist returns a whitelist of the peers with the given node ids
+// and the peers signed by any of the given CAs
+func NewPeerWhitelist(ids []string, cas []*x509.Certificate) *PeerWhitelist {
+	w := &PeerWhitelist{ids: make(map[string]bool, len(ids)), cas: cas}
+	for _, id := range ids {
+		w.ids[id] = true
+	}
+	return w
+}
+
+// Allows returns an error if the peer with the given CA isn't whitelisted
+func (w *PeerWhitelist) Allows(ca *x509.Certificate) error {
+	if w == nil {
+		return nil
+	}
+
+	id, err := idFromKey(ca.PublicKey)
+	if err != nil {
+		return ErrPeerNotWhitelisted.Wrap(err)
+	}
+	if w.ids[id.String()] {
+		return nil
+	}
+	for _, trusted := range w.cas {
+		// a self-signed trusted CA would pass its own signature check
+		if bytes.Equal(trusted.RawSubjectPublicKeyInfo, ca.RawSubjectPublicKeyInfo) {
+			continue
+		}
+		if trusted.CheckSignature(ca.SignatureAlgorithm, ca.RawTBSCertificate, ca.Signature) == nil {
+			return nil
+		}
+	}
+	return ErrPeerNotWhitelisted.New("%s", id)
+}
+
+// Verify returns an error if the peer of the grpc request in ctx isn't
+// whitelisted. It's meant for services which only some of the peers a
+// provider accepts connections from may use.
+func (w *PeerWhitelist) Verify(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+
+	identity, err := PeerIdentityFromContext(ctx)
+	if err != nil {
+		return ErrPeerNotWhitelisted.Wrap(err)
+	}
+	return w.Allows(identity.CA)
+}
+
+// VerifyPeerWhitelist returns a peer certificate verification function which
+// rejects the TLS handshake of peers which aren't whitelisted. It returns
+// nil, i.e. no verification, if the whitelist is nil.
+func VerifyPeerWhitelist(w *PeerWhitelist) peertls.PeerCertVerificationFunc {
+	if w == nil {
+		return nil
+	}
+
+	return func(_ [][]byte, parsedChains [][]*x509.Certificate) error {
+		if len(parsedChains[0]) < 2 {
+			return ErrPeerNotWhitelisted.New("certificate chain has no CA")
+		}
+		return w.Allows(parsedChains[0][1])
+	}
+}
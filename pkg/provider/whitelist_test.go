@@ -0,0 +1,104 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"storj.io/storj/pkg/peertls"
+)
+
+func TestPeerWhitelist(t *testing.T) {
+	ctx := context.Background()
+	newCA := func() *FullCertificateAuthority {
+		ca, err := NewCA(ctx, NewCAOptions{Difficulty: 4})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return ca
+	}
+	allowed, signer, other := newCA(), newCA(), newCA()
+
+	signed, err := peertls.NewCert(other.Cert, signer.Cert, other.Cert.PublicKey, signer.Key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	var nilWhitelist *PeerWhitelist
+	assert.NoError(t, nilWhitelist.Allows(other.Cert))
+	assert.Nil(t, VerifyPeerWhitelist(nil))
+
+	w := NewPeerWhitelist([]string{allowed.ID.String()}, []*x509.Certificate{signer.Cert})
+	assert.NoError(t, w.Allows(allowed.Cert))
+	assert.NoError(t, w.Allows(signed))
+	assert.True(t, ErrPeerNotWhitelisted.Has(w.Allows(other.Cert)))
+	// the trusted CA itself isn't allowed unless its id is whitelisted
+	assert.True(t, ErrPeerNotWhitelisted.Has(w.Allows(signer.Cert)))
+	assert.NoError(t, NewPeerWhitelist([]string{signer.ID.String()}, []*x509.Certificate{signer.Cert}).Allows(signer.Cert))
+
+	verify := VerifyPeerWhitelist(w)
+	assert.NoError(t, verify(nil, [][]*x509.Certificate{{nil, allowed.Cert}}))
+	assert.Error(t, verify(nil, [][]*x509.Certificate{{nil, other.Cert}}))
+	assert.Error(t, verify(nil, [][]*x509.Certificate{{other.Cert}}))
+
+	peerCtx := func(ca *FullCertificateAuthority) context.Context {
+		identity, err := ca.NewIdentity()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return peer.NewContext(ctx, &peer.Peer{
+			AuthInfo: credentials.TLSInfo{
+				State: tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{identity.Leaf, identity.CA},
+				},
+			},
+		})
+	}
+	assert.NoError(t, w.Verify(peerCtx(allowed)))
+	assert.Error(t, w.Verify(peerCtx(other)))
+	assert.Error(t, w.Verify(ctx))
+	assert.NoError(t, nilWhitelist.Verify(ctx))
+}
+
+func TestPeerWhitelistConfig_Load(t *testing.T) {
+	w, err := PeerWhitelistConfig{}.Load()
+	assert.NoError(t, err)
+	assert.Nil(t, w)
+
+	w, err = PeerWhitelistConfig{PeerIDs: " a, b ,"}.Load()
+	assert.NoError(t, err)
+	if assert.NotNil(t, w) {
+		assert.Equal(t, map[string]bool{"a": true, "b": true}, w.ids)
+	}
+
+	ca, err := NewCA(context.Background(), NewCAOptions{Difficulty: 4})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	tmp, err := ioutil.TempFile("", "trusted-ca")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	assert.NoError(t, peertls.WriteChain(tmp, ca.Cert))
+	assert.NoError(t, tmp.Close())
+
+	w, err = PeerWhitelistConfig{TrustedCAPath: tmp.Name()}.Load()
+	assert.NoError(t, err)
+	if assert.NotNil(t, w) && assert.Len(t, w.cas, 1) {
+		assert.Equal(t, ca.Cert.Raw, w.cas[0].Raw)
+	}
+
+	_, err = PeerWhitelistConfig{TrustedCAPath: tmp.Name() + ".missing"}.Load()
+	assert.True(t, ErrPeerNotWhitelisted.Has(err))
+}
@@ -8,6 +8,7 @@
 package ranger
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -22,10 +23,17 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"storj.io/storj/internal/readcloser"
 )
 
 // ServeContent is the Go standard library's http.ServeContent but modified to
 // work with Rangers.
+//
+// If the caller has set w's ETag header formatted per RFC 7232, section 2.3,
+// ServeContent uses it to handle requests using If-Match, If-None-Match, or
+// If-Range. Responses to HEAD requests have the headers of the response to
+// the same GET request, Content-Length included, but no content is ranged.
 func ServeContent(ctx context.Context, w http.ResponseWriter, r *http.Request, name string, modtime time.Time, content Ranger) {
 	setLastModified(w, modtime)
 	done, rangeReq := checkPreconditions(w, r, modtime)
@@ -37,15 +45,36 @@ func ServeContent(ctx context.Context, w http.ResponseWriter, r *http.Request, n
 
 	size := content.Size()
 
-	if size <= 0 {
-		w.WriteHeader(code)
-		return
+	// the sniffed content is kept, so it isn't ranged again
+	var sniffed []byte
+
+	// If Content-Type isn't set, use the file's extension to find it, but
+	// if the Content-Type is unset explicitly, do not sniff the type.
+	ctypes, haveType := w.Header()["Content-Type"]
+	var ctype string
+	if !haveType {
+		ctype = mime.TypeByExtension(filepath.Ext(name))
+		if ctype == "" && size > 0 {
+			// read a chunk to decide between utf-8 text and binary
+			var err error
+			sniffed, err = readPrefix(ctx, content, sniffLen)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			ctype = http.DetectContentType(sniffed)
+		}
+		if ctype != "" {
+			w.Header().Set("Content-Type", ctype)
+		}
+	} else if len(ctypes) > 0 {
+		ctype = ctypes[0]
 	}
 
 	// handle Content-Range header.
 	sendSize := size
 	sendContent := func() (io.ReadCloser, error) {
-		return content.Range(ctx, 0, size)
+		return rangeWithPrefix(ctx, content, sniffed, 0, size)
 	}
 
 	ranges, err := parseRange(rangeReq, size)
@@ -77,48 +106,13 @@ func ServeContent(ctx context.Context, w http.ResponseWriter, r *http.Request, n
 		// A response to a request for a single range MUST NOT
 		// be sent using the multipart/byteranges media type."
 		ra := ranges[0]
-		sendContent = func() (io.ReadCloser, error) { return content.Range(ctx, ra.start, ra.length) }
+		sendContent = func() (io.ReadCloser, error) {
+			return rangeWithPrefix(ctx, content, sniffed, ra.start, ra.length)
+		}
 		sendSize = ra.length
 		code = http.StatusPartialContent
 		w.Header().Set("Content-Range", ra.contentRange(size))
 	case len(ranges) > 1:
-		// If Content-Type isn't set, use the file's extension to find it, but
-		// if the Content-Type is unset explicitly, do not sniff the type.
-		ctypes, haveType := w.Header()["Content-Type"]
-		var ctype string
-		if !haveType {
-			ctype = mime.TypeByExtension(filepath.Ext(name))
-			if ctype == "" {
-				// read a chunk to decide between utf-8 text and binary
-				var buf [sniffLen]byte
-				amount := content.Size()
-				if amount > sniffLen {
-					amount = sniffLen
-				}
-				// TODO: cache this somewhere so we don't have to pull it out again
-				r, err := content.Range(ctx, 0, amount)
-				if err == nil {
-					defer func() {
-						if err := r.Close(); err != nil {
-							log.Printf("Error Closing ranger: %s", err)
-						}
-					}()
-
-					n, err := io.ReadFull(r, buf[:])
-					if err != nil {
-						log.Printf("Error Reading full: %s", err)
-					}
-
-					ctype = http.DetectContentType(buf[:n])
-				}
-			}
-			if ctype != "" {
-				w.Header().Set("Content-Type", ctype)
-			}
-		} else if len(ctypes) > 0 {
-			ctype = ctypes[0]
-		}
-
 		sendSize = rangesMIMESize(ranges, ctype, size)
 		code = http.StatusPartialContent
 
@@ -126,54 +120,17 @@ func ServeContent(ctx context.Context, w http.ResponseWriter, r *http.Request, n
 		mw := multipart.NewWriter(pw)
 		w.Header().Set("Content-Type",
 			"multipart/byteranges; boundary="+mw.Boundary())
-		sendContent = func() (io.ReadCloser, error) { return ioutil.NopCloser(pr), nil }
+		// the parts are only ranged when the content is sent
+		sendContent = func() (io.ReadCloser, error) {
+			go writeParts(ctx, pw, mw, content, sniffed, ranges, ctype, size)
+			return pr, nil
+		}
 		// cause writing goroutine to fail and exit if CopyN doesn't finish.
 		defer func() {
 			if err := pr.Close(); err != nil {
 				log.Printf("Error Closing pipereader: %s", err)
 			}
 		}()
-
-		go func() {
-			for _, ra := range ranges {
-				part, err := mw.CreatePart(ra.mimeHeader(ctype, size))
-				if err != nil {
-					if err := pw.CloseWithError(err); err != nil {
-						log.Printf("Error Closing pipewriter with errors: %s", err)
-					}
-
-					return
-				}
-				partReader, err := content.Range(ctx, ra.start, ra.length)
-				if err != nil {
-					if err := pw.CloseWithError(err); err != nil {
-						log.Printf("Error Closing pipewriter with errors: %s", err)
-					}
-
-					return
-				}
-				defer func() {
-					if err := partReader.Close(); err != nil {
-						log.Printf("Error Closing partReader: %s", err)
-					}
-				}()
-
-				if _, err := io.Copy(part, partReader); err != nil {
-					if err := pw.CloseWithError(err); err != nil {
-						log.Printf("Error Closing pipewriter with errors: %s", err)
-					}
-
-					return
-				}
-			}
-			if err := mw.Close(); err != nil {
-				log.Printf("Error Closing writer: %s", err)
-			}
-
-			if err := pw.Close(); err != nil {
-				log.Printf("Error closing pipewriter: %s", err)
-			}
-		}()
 	}
 
 	w.Header().Set("Accept-Ranges", "bytes")
@@ -183,9 +140,10 @@ func ServeContent(ctx context.Context, w http.ResponseWriter, r *http.Request, n
 
 	w.WriteHeader(code)
 
-	if r.Method != http.MethodHead {
+	if r.Method != http.MethodHead && sendSize > 0 {
 		r, err := sendContent()
 		if err != nil {
+			log.Printf("Error Ranging content: %s", err)
 			return
 		}
 
@@ -199,6 +157,87 @@ func ServeContent(ctx context.Context, w http.ResponseWriter, r *http.Request, n
 	}
 }
 
+// writeParts writes the ranges of content to mw as the parts of a
+// multipart/byteranges response, and closes pw when they're written
+func writeParts(ctx context.Context, pw *io.PipeWriter, mw *multipart.Writer, content Ranger, prefix []byte,
+	ranges []httpRange, ctype string, size int64) {
+	writePart := func(ra httpRange) error {
+		part, err := mw.CreatePart(ra.mimeHeader(ctype, size))
+		if err != nil {
+			return err
+		}
+		partReader, err := rangeWithPrefix(ctx, content, prefix, ra.start, ra.length)
+		if err != nil {
+			return err
+		}
+		// each part is closed once it's written, so at most one is open
+		defer func() {
+			if err := partReader.Close(); err != nil {
+				log.Printf("Error Closing partReader: %s", err)
+			}
+		}()
+		_, err = io.Copy(part, partReader)
+		return err
+	}
+
+	for _, ra := range ranges {
+		if err := writePart(ra); err != nil {
+			if err := pw.CloseWithError(err); err != nil {
+				log.Printf("Error Closing pipewriter with errors: %s", err)
+			}
+			return
+		}
+	}
+	if err := mw.Close(); err != nil {
+		log.Printf("Error Closing writer: %s", err)
+	}
+
+	if err := pw.Close(); err != nil {
+		log.Printf("Error closing pipewriter: %s", err)
+	}
+}
+
+// readPrefix returns up to the first n bytes of content
+func readPrefix(ctx context.Context, content Ranger, n int64) (_ []byte, err error) {
+	if size := content.Size(); n > size {
+		n = size
+	}
+	r, err := content.Range(ctx, 0, n)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			log.Printf("Error Closing ranger: %s", err)
+		}
+	}()
+
+	buf := make([]byte, n)
+	read, err := io.ReadFull(r, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return buf[:read], nil
+}
+
+// rangeWithPrefix returns the range of content of length bytes at offset.
+// The part of the range within prefix, the bytes at the start of content
+// which were already read, isn't ranged again.
+func rangeWithPrefix(ctx context.Context, content Ranger, prefix []byte, offset, length int64) (io.ReadCloser, error) {
+	if offset >= int64(len(prefix)) {
+		return content.Range(ctx, offset, length)
+	}
+	cached := int64(len(prefix)) - offset
+	if cached >= length {
+		return ioutil.NopCloser(bytes.NewReader(prefix[offset : offset+length])), nil
+	}
+	return readcloser.MultiReadCloser(
+		ioutil.NopCloser(bytes.NewReader(prefix[offset:])),
+		readcloser.LazyReadCloser(func() (io.ReadCloser, error) {
+			return content.Range(ctx, offset+cached, length-cached)
+		})), nil
+}
+
 var unixEpochTime = time.Unix(0, 0)
 
 // isZeroTime reports whether t is obviously unspecified (either zero or
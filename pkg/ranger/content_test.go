@@ -4,9 +4,15 @@
 package ranger
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -72,6 +78,178 @@ func TestServeContentParseRange(t *testing.T) {
 	assert.Equal(t, "23", writer.Result().Header.Get("Content-Length"))
 }
 
+// recordingRanger records the ranges requested from it
+type recordingRanger struct {
+	Ranger
+	ranges [][2]int64
+}
+
+func (rr *recordingRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	rr.ranges = append(rr.ranges, [2]int64{offset, length})
+	return rr.Ranger.Range(ctx, offset, length)
+}
+
+func TestServeContentRanges(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100)
+	for _, tt := range []struct {
+		name           string
+		method         string
+		requestHeaders map[string]string
+		etag           string
+		sniff          bool
+		content        []byte
+		code           int
+		headers        map[string]string
+		body           []byte
+		parts          []string
+		ranges         [][2]int64
+	}{
+		{
+			name:    "HEAD with accurate Content-Length",
+			method:  http.MethodHead,
+			sniff:   true,
+			content: content,
+			code:    http.StatusOK,
+			headers: map[string]string{"Content-Length": "1000", "Content-Type": "text/plain; charset=utf-8", "Accept-Ranges": "bytes"},
+			ranges:  [][2]int64{{0, 512}},
+		},
+		{
+			name:    "HEAD of known type doesn't range",
+			method:  http.MethodHead,
+			content: content,
+			code:    http.StatusOK,
+			headers: map[string]string{"Content-Length": "1000"},
+		},
+		{
+			name:    "HEAD of empty content",
+			method:  http.MethodHead,
+			content: []byte{},
+			code:    http.StatusOK,
+			headers: map[string]string{"Content-Length": "0"},
+		},
+		{
+			name:    "GET reuses the sniffed content",
+			method:  http.MethodGet,
+			sniff:   true,
+			content: content,
+			code:    http.StatusOK,
+			body:    content,
+			ranges:  [][2]int64{{0, 512}, {512, 488}},
+		},
+		{
+			name:           "single range",
+			method:         http.MethodGet,
+			requestHeaders: map[string]string{"Range": "bytes=600-609"},
+			content:        content,
+			code:           http.StatusPartialContent,
+			headers:        map[string]string{"Content-Length": "10", "Content-Range": "bytes 600-609/1000"},
+			body:           content[600:610],
+			ranges:         [][2]int64{{600, 10}},
+		},
+		{
+			name:           "multiple ranges",
+			method:         http.MethodGet,
+			requestHeaders: map[string]string{"Range": "bytes=0-1,998-"},
+			sniff:          true,
+			content:        content,
+			code:           http.StatusPartialContent,
+			parts:          []string{"01", "89"},
+			ranges:         [][2]int64{{0, 512}, {998, 2}},
+		},
+		{
+			name:           "HEAD of multiple ranges doesn't range",
+			method:         http.MethodHead,
+			requestHeaders: map[string]string{"Range": "bytes=0-1,998-"},
+			content:        content,
+			code:           http.StatusPartialContent,
+		},
+		{
+			name:           "range not satisfiable",
+			method:         http.MethodGet,
+			requestHeaders: map[string]string{"Range": "bytes=1000-"},
+			content:        content,
+			code:           http.StatusRequestedRangeNotSatisfiable,
+			headers:        map[string]string{"Content-Range": "bytes */1000"},
+		},
+		{
+			name:           "If-Range with the ETag",
+			method:         http.MethodGet,
+			requestHeaders: map[string]string{"Range": "bytes=0-1", "If-Range": `"etag"`},
+			etag:           `"etag"`,
+			content:        content,
+			code:           http.StatusPartialContent,
+			body:           content[:2],
+			ranges:         [][2]int64{{0, 2}},
+		},
+		{
+			name:           "If-Range with another ETag",
+			method:         http.MethodGet,
+			requestHeaders: map[string]string{"Range": "bytes=0-1", "If-Range": `"other"`},
+			etag:           `"etag"`,
+			content:        content,
+			code:           http.StatusOK,
+			body:           content,
+			ranges:         [][2]int64{{0, 1000}},
+		},
+		{
+			name:           "If-None-Match with the ETag",
+			method:         http.MethodGet,
+			requestHeaders: map[string]string{"If-None-Match": `"etag"`},
+			etag:           `"etag"`,
+			content:        content,
+			code:           http.StatusNotModified,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			for k, v := range tt.requestHeaders {
+				req.Header.Add(k, v)
+			}
+			writer := httptest.NewRecorder()
+			if tt.etag != "" {
+				writer.Header().Set("Etag", tt.etag)
+			}
+			// the type of a name with an extension isn't sniffed
+			name := "object.txt"
+			if tt.sniff {
+				name = "object"
+			}
+			rr := &recordingRanger{Ranger: ByteRanger(tt.content)}
+
+			ServeContent(context.Background(), writer, req, name, time.Now(), rr)
+
+			assert.Equal(t, tt.code, writer.Code)
+			for k, v := range tt.headers {
+				assert.Equal(t, v, writer.Header().Get(k), k)
+			}
+			assert.Equal(t, tt.ranges, rr.ranges)
+			if tt.method == http.MethodHead {
+				assert.Empty(t, writer.Body.Bytes())
+			}
+			if tt.body != nil {
+				assert.Equal(t, tt.body, writer.Body.Bytes())
+			}
+			if tt.parts != nil {
+				assert.Equal(t, strconv.Itoa(writer.Body.Len()), writer.Header().Get("Content-Length"))
+				_, params, err := mime.ParseMediaType(writer.Header().Get("Content-Type"))
+				if !assert.NoError(t, err) {
+					return
+				}
+				mr := multipart.NewReader(writer.Body, params["boundary"])
+				for _, expected := range tt.parts {
+					part, err := mr.NextPart()
+					if !assert.NoError(t, err) {
+						return
+					}
+					data, err := ioutil.ReadAll(part)
+					assert.NoError(t, err)
+					assert.Equal(t, expected, string(data))
+				}
+			}
+		})
+	}
+}
+
 func Test_isZeroTime(t *testing.T) {
 	for _, tt := range []struct {
 		name     string
@@ -8,8 +8,9 @@ import (
 
 	"go.uber.org/zap"
 
+	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/provider"
-	pb "storj.io/storj/pkg/statdb/proto"
+	sdbproto "storj.io/storj/pkg/statdb/proto"
 )
 
 // Config is a configuration struct that is everything you need to start a
@@ -17,6 +18,7 @@ import (
 type Config struct {
 	DatabaseURL    string `help:"the database connection string to use" default:"$CONFDIR/stats.db"`
 	DatabaseDriver string `help:"the database driver to use" default:"sqlite3"`
+	PeerWhitelist  provider.PeerWhitelistConfig
 }
 
 // Run implements the provider.Responsibility interface
@@ -25,8 +27,14 @@ func (c Config) Run(ctx context.Context, server *provider.Provider) error {
 	if err != nil {
 		return err
 	}
+	ns.whitelist, err = c.PeerWhitelist.Load()
+	if err != nil {
+		return err
+	}
 
-	pb.RegisterStatDBServer(server.GRPC(), ns)
+	sdbproto.RegisterStatDBServer(server.PrivateGRPC(), ns)
+	// storage nodes submit their transitions themselves
+	pb.RegisterIdentityTransitionsServer(server.GRPC(), ns)
 
 	return server.Run(ctx)
 }
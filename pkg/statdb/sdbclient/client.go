@@ -41,7 +41,7 @@ func NewClient(identity *provider.FullIdentity, address string, APIKey []byte) (
 	if err != nil {
 		return nil, err
 	}
-	c, err := clientConnection(address, dialOpt)
+	c, err := clientConnection(address, dialOpt, grpc.WithUnaryInterceptor(provider.TraceUnaryClient))
 
 	if err != nil {
 		return nil, err
@@ -14,6 +14,7 @@ import (
 	monkit "gopkg.in/spacemonkeygo/monkit.v2"
 
 	"storj.io/storj/pkg/pointerdb/auth"
+	"storj.io/storj/pkg/provider"
 	dbx "storj.io/storj/pkg/statdb/dbx"
 	pb "storj.io/storj/pkg/statdb/proto"
 )
@@ -24,8 +25,9 @@ var (
 
 // Server implements the statdb RPC service
 type Server struct {
-	DB     *dbx.DB
-	logger *zap.Logger
+	DB        *dbx.DB
+	logger    *zap.Logger
+	whitelist *provider.PeerWhitelist
 }
 
 // NewServer creates instance of Server
@@ -46,7 +48,11 @@ func NewServer(driver, source string, logger *zap.Logger) (*Server, error) {
 	}, nil
 }
 
-func (s *Server) validateAuth(APIKeyBytes []byte) error {
+func (s *Server) validateAuth(ctx context.Context, APIKeyBytes []byte) error {
+	if err := s.whitelist.Verify(ctx); err != nil {
+		s.logger.Error("unauthorized peer: ", zap.Error(err))
+		return status.Errorf(codes.PermissionDenied, "Peer not allowed")
+	}
 	if !auth.ValidateAPIKey(string(APIKeyBytes)) {
 		s.logger.Error("unauthorized request: ", zap.Error(status.Errorf(codes.Unauthenticated, "Invalid API credential")))
 		return status.Errorf(codes.Unauthenticated, "Invalid API credential")
@@ -60,7 +66,7 @@ func (s *Server) Create(ctx context.Context, createReq *pb.CreateRequest) (resp
 	s.logger.Debug("entering statdb Create")
 
 	APIKeyBytes := createReq.APIKey
-	if err := s.validateAuth(APIKeyBytes); err != nil {
+	if err := s.validateAuth(ctx, APIKeyBytes); err != nil {
 		return nil, err
 	}
 
@@ -100,7 +106,7 @@ func (s *Server) Get(ctx context.Context, getReq *pb.GetRequest) (resp *pb.GetRe
 	s.logger.Debug("entering statdb Get")
 
 	APIKeyBytes := getReq.APIKey
-	err = s.validateAuth(APIKeyBytes)
+	err = s.validateAuth(ctx, APIKeyBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -125,6 +131,10 @@ func (s *Server) FindValidNodes(ctx context.Context, getReq *pb.FindValidNodesRe
 	defer mon.Task()(&ctx)(&err)
 	s.logger.Debug("entering statdb FindValidNodes")
 
+	if err := s.validateAuth(ctx, getReq.APIKey); err != nil {
+		return nil, err
+	}
+
 	passedIds := [][]byte{}
 
 	nodeIds := getReq.NodeIds
@@ -182,7 +192,7 @@ func (s *Server) Update(ctx context.Context, updateReq *pb.UpdateRequest) (resp
 	s.logger.Debug("entering statdb Update")
 
 	APIKeyBytes := updateReq.APIKey
-	err = s.validateAuth(APIKeyBytes)
+	err = s.validateAuth(ctx, APIKeyBytes)
 	if err != nil {
 		return nil, err
 	}
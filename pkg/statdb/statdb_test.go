@@ -5,13 +5,22 @@ package statdb
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
-
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"storj.io/storj/pkg/node"
+	storjpb "storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/provider"
 	dbx "storj.io/storj/pkg/statdb/dbx"
 	pb "storj.io/storj/pkg/statdb/proto"
 )
@@ -171,6 +180,15 @@ func TestFindValidNodes(t *testing.T) {
 	assert.Contains(t, passed, []byte("id2"))
 	assert.Contains(t, passed, []byte("id7"))
 	assert.Len(t, passed, 2)
+
+	// peers which aren't whitelisted can't look up nodes
+	ca, err := provider.NewTestCA(ctx)
+	assert.NoError(t, err)
+	identity, err := ca.NewIdentity()
+	assert.NoError(t, err)
+	statdb.whitelist = provider.NewPeerWhitelist([]string{"other"}, nil)
+	_, err = statdb.FindValidNodes(peerContext(identity), findValidNodesReq)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
 }
 
 func TestUpdateExists(t *testing.T) {
@@ -363,6 +381,86 @@ func TestCreateEntryIfNotExists(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestTransition(t *testing.T) {
+	dbPath := getDBPath()
+	statdb, db, err := getServerAndDB(dbPath)
+	assert.NoError(t, err)
+
+	newIdentity := func(difficulty uint16) *provider.FullIdentity {
+		ca, err := provider.NewCA(ctx, provider.NewCAOptions{Difficulty: difficulty})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		identity, err := ca.NewIdentity()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return identity
+	}
+	oldIdentity := newIdentity(0)
+	newerIdentity := newIdentity(oldIdentity.ID.Difficulty() + 1)
+	oldID, newID := []byte(oldIdentity.ID.String()), []byte(newerIdentity.ID.String())
+
+	auditSuccessCount, totalAuditCount, auditRatio := getRatio(4, 10)
+	uptimeSuccessCount, totalUptimeCount, uptimeRatio := getRatio(8, 25)
+	err = createNode(ctx, db, oldID, auditSuccessCount, totalAuditCount, auditRatio,
+		uptimeSuccessCount, totalUptimeCount, uptimeRatio)
+	assert.NoError(t, err)
+	err = createNode(ctx, db, newID, 0, 1, 0, 0, 1, 0)
+	assert.NoError(t, err)
+
+	transition, err := node.NewIdentityTransition(oldIdentity, newerIdentity)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	req := &storjpb.TransitionRequest{Transition: transition}
+
+	_, err = statdb.Transition(ctx, req)
+	assert.NoError(t, err)
+
+	nodeInfo, err := db.Get_Node_By_Id(ctx, dbx.Node_Id(newID))
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, totalAuditCount, nodeInfo.TotalAuditCount)
+		assert.EqualValues(t, auditRatio, nodeInfo.AuditSuccessRatio)
+		assert.EqualValues(t, totalUptimeCount, nodeInfo.TotalUptimeCount)
+		assert.EqualValues(t, uptimeRatio, nodeInfo.UptimeRatio)
+	}
+	_, err = db.Get_Node_By_Id(ctx, dbx.Node_Id(oldID))
+	assert.Error(t, err)
+
+	// repeating the transition leaves the moved stats alone
+	_, err = statdb.Transition(ctx, req)
+	assert.NoError(t, err)
+	nodeInfo, err = db.Get_Node_By_Id(ctx, dbx.Node_Id(newID))
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, totalAuditCount, nodeInfo.TotalAuditCount)
+	}
+
+	transition.NewSignature = nil
+	_, err = statdb.Transition(ctx, req)
+	assert.Error(t, err)
+
+	// peers which aren't whitelisted can't move stats either
+	transition, err = node.NewIdentityTransition(oldIdentity, newerIdentity)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	statdb.whitelist = provider.NewPeerWhitelist([]string{newerIdentity.ID.String()}, nil)
+	_, err = statdb.Transition(peerContext(oldIdentity), &storjpb.TransitionRequest{Transition: transition})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = statdb.Transition(peerContext(newerIdentity), &storjpb.TransitionRequest{Transition: transition})
+	assert.NoError(t, err)
+}
+
+// peerContext returns a context of a call by the peer with identity
+func peerContext(identity *provider.FullIdentity) context.Context {
+	return peer.NewContext(ctx, &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{identity.Leaf, identity.CA},
+		}},
+	})
+}
+
 func getDBPath() string {
 	return fmt.Sprintf("file:memdb%d?mode=memory&cache=shared", rand.Int63())
 }
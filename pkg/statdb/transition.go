@@ -0,0 +1,100 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package statdb
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/pb"
+	dbx "storj.io/storj/pkg/statdb/dbx"
+)
+
+// Transition moves the stats of the old node id of an identity transition
+// to the new node id, once both identities have signed it. It's safe to
+// repeat: once the stats are moved there is nothing left to move. Like the
+// other calls, it's only served to whitelisted peers, but as nodes make it
+// themselves it needs no api key.
+func (s *Server) Transition(ctx context.Context, req *pb.TransitionRequest) (resp *pb.TransitionResponse, err error) {
+	defer mon.Task()(&ctx)(&err)
+	s.logger.Debug("entering statdb Transition")
+
+	if err := s.whitelist.Verify(ctx); err != nil {
+		s.logger.Error("unauthorized peer: ", zap.Error(err))
+		return nil, status.Errorf(codes.PermissionDenied, "Peer not allowed")
+	}
+
+	oldIdentity, newIdentity, err := node.VerifyIdentityTransition(req.GetTransition())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	moved, err := s.moveNode(ctx, []byte(oldIdentity.ID.String()), []byte(newIdentity.ID.String()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if moved {
+		s.logger.Info("node transitioned to a new identity",
+			zap.String("Old", oldIdentity.ID.String()),
+			zap.String("New", newIdentity.ID.String()))
+	}
+	return &pb.TransitionResponse{}, nil
+}
+
+// moveNode replaces the stats of newID with the ones of oldID and deletes
+// oldID. It returns false if there are no stats for oldID.
+func (s *Server) moveNode(ctx context.Context, oldID, newID []byte) (moved bool, err error) {
+	tx, err := s.DB.Open(ctx)
+	if err != nil {
+		return false, Error.Wrap(err)
+	}
+	defer func() {
+		if err == nil {
+			err = Error.Wrap(tx.Commit())
+			return
+		}
+		if errRollback := tx.Rollback(); errRollback != nil {
+			s.logger.Error("rollback failed", zap.Error(errRollback))
+		}
+	}()
+
+	old, err := tx.Get_Node_By_Id(ctx, dbx.Node_Id(oldID))
+	if isNoRows(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, Error.Wrap(err)
+	}
+
+	// the stats the new identity gathered so far are dropped in favor of
+	// the longer history of the old one
+	if _, err := tx.Delete_Node_By_Id(ctx, dbx.Node_Id(newID)); err != nil {
+		return false, Error.Wrap(err)
+	}
+	_, err = tx.Create_Node(ctx,
+		dbx.Node_Id(newID),
+		dbx.Node_AuditSuccessCount(old.AuditSuccessCount),
+		dbx.Node_TotalAuditCount(old.TotalAuditCount),
+		dbx.Node_AuditSuccessRatio(old.AuditSuccessRatio),
+		dbx.Node_UptimeSuccessCount(old.UptimeSuccessCount),
+		dbx.Node_TotalUptimeCount(old.TotalUptimeCount),
+		dbx.Node_UptimeRatio(old.UptimeRatio),
+	)
+	if err != nil {
+		return false, Error.Wrap(err)
+	}
+	if _, err := tx.Delete_Node_By_Id(ctx, dbx.Node_Id(oldID)); err != nil {
+		return false, Error.Wrap(err)
+	}
+	return true, nil
+}
+
+func isNoRows(err error) bool {
+	e, ok := err.(*dbx.Error)
+	return ok && e.Code == dbx.ErrorCode_NoRows
+}
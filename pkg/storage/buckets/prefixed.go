@@ -8,14 +8,21 @@ import (
 	"io"
 	"time"
 
+	"storj.io/storj/pkg/eestream"
 	"storj.io/storj/pkg/ranger"
 	"storj.io/storj/pkg/storage/objects"
+	"storj.io/storj/pkg/storage/segments"
+	"storj.io/storj/pkg/storage/streams"
 	"storj.io/storj/pkg/storj"
 )
 
 type prefixedObjStore struct {
 	o      objects.Store
 	prefix string
+	// rs is the redundancy strategy of the bucket, if it has one
+	rs *eestream.RedundancyStrategy
+	// segmentSize is the segment size of the bucket, if it has one
+	segmentSize int64
 }
 
 func (o *prefixedObjStore) Meta(ctx context.Context, path storj.Path) (meta objects.Meta, err error) {
@@ -47,6 +54,12 @@ func (o *prefixedObjStore) Put(ctx context.Context, path storj.Path, data io.Rea
 		return objects.Meta{}, objects.NoPathError.New("")
 	}
 
+	if o.rs != nil {
+		ctx = segments.WithRedundancy(ctx, *o.rs)
+	}
+	if o.segmentSize > 0 {
+		ctx = streams.WithSegmentSize(ctx, o.segmentSize)
+	}
 	m, err := o.o.Put(ctx, storj.JoinPaths(o.prefix, path), data, metadata, expiration)
 	return m, err
 }
@@ -61,6 +74,16 @@ func (o *prefixedObjStore) Delete(ctx context.Context, path storj.Path) (err err
 	return o.o.Delete(ctx, storj.JoinPaths(o.prefix, path))
 }
 
+func (o *prefixedObjStore) Copy(ctx context.Context, src, dst storj.Path) (meta objects.Meta, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if len(src) == 0 || len(dst) == 0 {
+		return objects.Meta{}, objects.NoPathError.New("")
+	}
+
+	return o.o.Copy(ctx, storj.JoinPaths(o.prefix, src), storj.JoinPaths(o.prefix, dst))
+}
+
 func (o *prefixedObjStore) List(ctx context.Context, prefix, startAfter, endBefore storj.Path, recursive bool, limit int, metaFlags uint32) (items []objects.ListItem, more bool, err error) {
 	defer mon.Task()(&ctx)(&err)
 	return o.o.List(ctx, storj.JoinPaths(o.prefix, prefix), startAfter, endBefore, recursive, limit, metaFlags)
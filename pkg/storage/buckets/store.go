@@ -12,8 +12,10 @@ import (
 	"github.com/zeebo/errs"
 	monkit "gopkg.in/spacemonkeygo/monkit.v2"
 
+	"storj.io/storj/pkg/eestream"
 	"storj.io/storj/pkg/storage/meta"
 	"storj.io/storj/pkg/storage/objects"
+	"storj.io/storj/pkg/storj"
 	"storj.io/storj/storage"
 )
 
@@ -25,7 +27,7 @@ var NoBucketError = errs.Class("no bucket specified")
 // Store creates an interface for interacting with buckets
 type Store interface {
 	Get(ctx context.Context, bucket string) (meta Meta, err error)
-	Put(ctx context.Context, bucket string) (meta Meta, err error)
+	Put(ctx context.Context, bucket string, redundancy storj.RedundancyScheme, segmentSize int64) (meta Meta, err error)
 	Delete(ctx context.Context, bucket string) (err error)
 	List(ctx context.Context, startAfter, endBefore string, limit int) (items []ListItem, more bool, err error)
 	GetObjectStore(ctx context.Context, bucketName string) (store objects.Store, err error)
@@ -45,6 +47,12 @@ type BucketStore struct {
 // Meta is the bucket metadata struct
 type Meta struct {
 	Created time.Time
+	// RedundancyScheme is the scheme the objects of the bucket are stored
+	// with. It's zero for buckets using the scheme of the uplink.
+	RedundancyScheme storj.RedundancyScheme
+	// SegmentSize is the size of the segments the objects of the bucket are
+	// stored in. It's 0 for buckets using the segment size of the uplink.
+	SegmentSize int64
 }
 
 // NewStore instantiates BucketStore
@@ -58,7 +66,7 @@ func (b *BucketStore) GetObjectStore(ctx context.Context, bucket string) (object
 		return nil, NoBucketError.New("")
 	}
 
-	_, err := b.Get(ctx, bucket)
+	m, err := b.Get(ctx, bucket)
 	if err != nil {
 		if storage.ErrKeyNotFound.Has(err) {
 			return nil, minio.BucketNotFound{Bucket: bucket}
@@ -69,6 +77,14 @@ func (b *BucketStore) GetObjectStore(ctx context.Context, bucket string) (object
 		o:      b.o,
 		prefix: bucket,
 	}
+	if m.RedundancyScheme.Algorithm != storj.InvalidRedundancyAlgorithm {
+		rs, err := eestream.NewRedundancyStrategyFromStorj(m.RedundancyScheme)
+		if err != nil {
+			return nil, err
+		}
+		prefixed.rs = &rs
+	}
+	prefixed.segmentSize = m.SegmentSize
 	return &prefixed, nil
 }
 
@@ -87,17 +103,38 @@ func (b *BucketStore) Get(ctx context.Context, bucket string) (meta Meta, err er
 	return convertMeta(objMeta), nil
 }
 
-// Put calls objects store Put
-func (b *BucketStore) Put(ctx context.Context, bucket string) (meta Meta, err error) {
+// Put calls objects store Put. The objects of the bucket are stored with
+// redundancy, unless it's zero, in which case they're stored with the scheme
+// of the segments store, and in segments of segmentSize bytes, unless it's 0,
+// in which case they're stored in segments of the size of the streams store.
+func (b *BucketStore) Put(ctx context.Context, bucket string, redundancy storj.RedundancyScheme, segmentSize int64) (meta Meta, err error) {
 	defer mon.Task()(&ctx)(&err)
 
 	if bucket == "" {
 		return Meta{}, NoBucketError.New("")
 	}
 
+	if segmentSize < 0 {
+		return Meta{}, errs.New("negative segment size %d", segmentSize)
+	}
+
+	serMeta := objects.SerializableMeta{SegmentSize: segmentSize}
+	if redundancy != (storj.RedundancyScheme{}) {
+		if _, err := eestream.NewRedundancyStrategyFromStorj(redundancy); err != nil {
+			return Meta{}, err
+		}
+		serMeta.Redundancy = &objects.RedundancyScheme{
+			ShareSize:      redundancy.ShareSize,
+			RequiredShares: int32(redundancy.RequiredShares),
+			RepairShares:   int32(redundancy.RepairShares),
+			OptimalShares:  int32(redundancy.OptimalShares),
+			TotalShares:    int32(redundancy.TotalShares),
+		}
+	}
+
 	r := bytes.NewReader(nil)
 	var exp time.Time
-	m, err := b.o.Put(ctx, bucket, r, objects.SerializableMeta{}, exp)
+	m, err := b.o.Put(ctx, bucket, r, serMeta, exp)
 	if err != nil {
 		return Meta{}, err
 	}
@@ -139,7 +176,19 @@ func (b *BucketStore) List(ctx context.Context, startAfter, endBefore string, li
 
 // convertMeta converts stream metadata to object metadata
 func convertMeta(m objects.Meta) Meta {
-	return Meta{
-		Created: m.Modified,
+	meta := Meta{
+		Created:     m.Modified,
+		SegmentSize: m.GetSegmentSize(),
+	}
+	if rs := m.GetRedundancy(); rs != nil {
+		meta.RedundancyScheme = storj.RedundancyScheme{
+			Algorithm:      storj.ReedSolomon,
+			ShareSize:      rs.GetShareSize(),
+			RequiredShares: int16(rs.GetRequiredShares()),
+			RepairShares:   int16(rs.GetRepairShares()),
+			OptimalShares:  int16(rs.GetOptimalShares()),
+			TotalShares:    int16(rs.GetTotalShares()),
+		}
 	}
+	return meta
 }
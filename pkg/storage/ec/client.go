@@ -8,6 +8,7 @@ import (
 	"io"
 	"io/ioutil"
 	"sort"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -26,11 +27,26 @@ import (
 var mon = monkit.Package()
 
 // Client defines an interface for storing erasure coded data to piece store nodes
+//
+// The erasure shares of the pieces put are followed by their hashes, and Put
+// returns the hash of the hashes of each piece, with the receipt signed by
+// its node for the SHA-256 hash of the piece as it was stored. Pieces which are got with
+// their hash are verified while they're read, so corrupted erasure shares
+// aren't decoded.
+//
+// Get and Repair download the pieces from the nodes which served pieces
+// with the lowest latency before.
+//
+// Repair rebuilds the pieces to be stored on repairNodes from the pieces on
+// nodes, without encoding again the healthy pieces.
 type Client interface {
 	Put(ctx context.Context, nodes []*pb.Node, rs eestream.RedundancyStrategy,
-		pieceID client.PieceID, data io.Reader, expiration time.Time, pba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (successfulNodes []*pb.Node, err error)
+		pieceID client.PieceID, data io.Reader, expiration time.Time, pba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (successfulNodes []*pb.Node, pieceHashes [][]byte, receipts []*pb.PieceHash, err error)
 	Get(ctx context.Context, nodes []*pb.Node, es eestream.ErasureScheme,
-		pieceID client.PieceID, size int64, pba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (ranger.Ranger, error)
+		pieceID client.PieceID, size int64, pieceHashes [][]byte, pba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (ranger.Ranger, error)
+	Repair(ctx context.Context, nodes []*pb.Node, rs eestream.RedundancyStrategy,
+		pieceID client.PieceID, size int64, pieceHashes [][]byte, repairNodes []*pb.Node, expiration time.Time,
+		pba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (successfulNodes []*pb.Node, successfulHashes [][]byte, receipts []*pb.PieceHash, err error)
 	Delete(ctx context.Context, nodes []*pb.Node, pieceID client.PieceID, authorization *pb.SignedMessage) error
 }
 
@@ -46,53 +62,135 @@ type defaultDialer struct {
 func (dialer *defaultDialer) dial(ctx context.Context, storageNode *pb.Node) (ps client.PSClient, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	conn, err := dialer.transport.DialNode(ctx, storageNode)
+	conn, release, err := transport.Acquire(ctx, dialer.transport, storageNode)
 	if err != nil {
 		return nil, err
 	}
 
-	return client.NewPSClient(conn, node.IDFromString(storageNode.GetId()), 0, dialer.identity.Key)
+	ps, err = client.NewPooledPSClient(conn, release, node.IDFromString(storageNode.GetId()), 0, dialer.identity.Key)
+	if err != nil {
+		return nil, utils.CombineErrors(err, release())
+	}
+	return ps, nil
 }
 
 type ecClient struct {
-	d   dialer
-	mbm int
+	d            dialer
+	mbm          int
+	pieceTimeout time.Duration
+	budget       *eestream.MemoryBudget
+	extraPieces  int
+	latencies    *latencies
+	uploads      *UploadLimiter
+	uploadRate   *RateLimiter
+	downloadRate *RateLimiter
 }
 
-// NewClient from the given TransportClient and max buffer memory
-func NewClient(identity *provider.FullIdentity, transport transport.Client, mbm int) Client {
+// NewClient from the given TransportClient, max buffer memory and the time
+// after which a stalled piece download is canceled, so the segment is read
+// from the other pieces. The buffers of all the uploads and downloads are
+// reserved from budget, unless it's nil. Get downloads extraPieces pieces
+// more than the required ones, or all the pieces if it's negative. The piece
+// uploads are limited by uploads, unless it's nil. The bytes of the pieces
+// uploaded and downloaded are limited by uploadRate and downloadRate, unless
+// they're nil.
+func NewClient(identity *provider.FullIdentity, transport transport.Client, mbm int, pieceTimeout time.Duration,
+	budget *eestream.MemoryBudget, extraPieces int, uploads *UploadLimiter, uploadRate, downloadRate *RateLimiter) Client {
 	d := defaultDialer{identity: identity, transport: transport}
-	return &ecClient{d: &d, mbm: mbm}
+	return &ecClient{d: &d, mbm: mbm, pieceTimeout: pieceTimeout, budget: budget,
+		extraPieces: extraPieces, latencies: newLatencies(), uploads: uploads,
+		uploadRate: uploadRate, downloadRate: downloadRate}
+}
+
+// withBudget returns ctx with the memory budget of the client, if it has one
+func (ec *ecClient) withBudget(ctx context.Context) context.Context {
+	if ec.budget == nil {
+		return ctx
+	}
+	return eestream.WithMemoryBudget(ctx, ec.budget)
 }
 
 func (ec *ecClient) Put(ctx context.Context, nodes []*pb.Node, rs eestream.RedundancyStrategy,
-	pieceID client.PieceID, data io.Reader, expiration time.Time, pba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (successfulNodes []*pb.Node, err error) {
+	pieceID client.PieceID, data io.Reader, expiration time.Time, pba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (successfulNodes []*pb.Node, pieceHashes [][]byte, receipts []*pb.PieceHash, err error) {
 	defer mon.Task()(&ctx)(&err)
 
 	if len(nodes) != rs.TotalCount() {
-		return nil, Error.New("number of nodes (%d) do not match total count (%d) of erasure scheme", len(nodes), rs.TotalCount())
+		return nil, nil, nil, Error.New("number of nodes (%d) do not match total count (%d) of erasure scheme", len(nodes), rs.TotalCount())
 	}
 	if !unique(nodes) {
-		return nil, Error.New("duplicated nodes are not allowed")
+		return nil, nil, nil, Error.New("duplicated nodes are not allowed")
 	}
 
+	// the uploads which are still running once the optimal threshold is
+	// reached are cut, so the slowest nodes don't hold up the upload
+	putCtx, cut := context.WithCancel(ctx)
+	defer cut()
+
 	padded := eestream.PadReader(ioutil.NopCloser(data), rs.StripeSize())
-	readers, err := eestream.EncodeReader(ctx, padded, rs, ec.mbm)
+	readers, err := eestream.EncodeReader(ec.withBudget(putCtx), padded, rs, ec.mbm)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
+	}
+
+	successfulNodes, pieceHashes, receipts, successfulCount := ec.putPieces(ctx, putCtx, cut, rs.OptimalThreshold(),
+		nodes, readers, rs.ErasureShareSize(), pieceID, expiration, pba, authorization)
+
+	/* clean up the partially uploaded segment's pieces */
+	defer func() {
+		select {
+		case <-ctx.Done():
+			err = utils.CombineErrors(
+				Error.New("upload cancelled by user"),
+				ec.Delete(context.Background(), nodes, pieceID, authorization),
+			)
+		default:
+		}
+	}()
+
+	if successfulCount < rs.RepairThreshold() {
+		return nil, nil, nil, Error.New("successful puts (%d) less than repair threshold (%d)", successfulCount, rs.RepairThreshold())
+	}
+
+	return successfulNodes, pieceHashes, receipts, nil
+}
+
+// putPieces uploads the pieces read from readers to the nodes with the same
+// index, and returns the nodes which stored their piece with the hashes of
+// the pieces and the receipts of the nodes. The pieces of nil nodes are read and discarded, and count as
+// successful like the uploaded ones, unless there's no piece. Once cutAt
+// uploads succeeded, the uploads which are still running are cut by calling
+// cut, which cancels putCtx.
+func (ec *ecClient) putPieces(ctx, putCtx context.Context, cut func(), cutAt int,
+	nodes []*pb.Node, readers []io.Reader, shareSize int, pieceID client.PieceID, expiration time.Time,
+	pba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (successfulNodes []*pb.Node, pieceHashes [][]byte, receipts []*pb.PieceHash, successfulCount int) {
+	hashed := make([]*eestream.HashingReader, len(readers))
+	for i, r := range readers {
+		if r != nil {
+			hashed[i] = eestream.NewHashingReader(r, shareSize)
+		}
 	}
 
 	type info struct {
-		i   int
-		err error
+		i       int
+		receipt *pb.PieceHash
+		err     error
+		offline bool
 	}
 	infos := make(chan info, len(nodes))
 
 	for i, n := range nodes {
 
 		go func(i int, n *pb.Node) {
+			// a piece which isn't read anymore is closed, if it can be, so
+			// it doesn't hold up the other pieces
+			if c, ok := readers[i].(io.Closer); ok {
+				defer func() { _ = c.Close() }()
+			}
 			if n == nil {
-				_, err := io.Copy(ioutil.Discard, readers[i])
+				var err error
+				if hashed[i] != nil {
+					_, err = io.Copy(ioutil.Discard, hashed[i])
+				}
 				infos <- info{i: i, err: err}
 				return
 			}
@@ -103,66 +201,116 @@ func (ec *ecClient) Put(ctx context.Context, nodes []*pb.Node, rs eestream.Redun
 				infos <- info{i: i, err: err}
 				return
 			}
-			ps, err := ec.d.dial(ctx, n)
+			release, err := ec.uploads.acquire(putCtx, n.GetId())
 			if err != nil {
-				zap.S().Errorf("Failed dialing for putting piece %s -> %s to node %s: %v",
-					pieceID, derivedPieceID, n.GetId(), err)
 				infos <- info{i: i, err: err}
 				return
 			}
-			err = ps.Put(ctx, derivedPieceID, readers[i], expiration, pba, authorization)
+			defer release()
+			ps, err := ec.d.dial(putCtx, n)
+			if err != nil {
+				if putCtx.Err() == nil {
+					zap.S().Errorf("Failed dialing for putting piece %s -> %s to node %s: %v",
+						pieceID, derivedPieceID, n.GetId(), err)
+				}
+				infos <- info{i: i, err: err, offline: true}
+				return
+			}
+			receipt, err := ps.Put(putCtx, derivedPieceID, ec.uploadRate.reader(putCtx, hashed[i]), expiration, pba, authorization)
 			// normally the bellow call should be deferred, but doing so fails
 			// randomly the unit tests
 			utils.LogClose(ps)
 			// io.ErrUnexpectedEOF means the piece upload was interrupted due to slow connection.
-			// No error logging for this case.
-			if err != nil && err != io.ErrUnexpectedEOF {
+			// No error logging for this case, nor for the uploads which were cut.
+			if err != nil && err != io.ErrUnexpectedEOF && putCtx.Err() == nil {
 				zap.S().Errorf("Failed putting piece %s -> %s to node %s: %v",
 					pieceID, derivedPieceID, n.GetId(), err)
 			}
-			infos <- info{i: i, err: err}
+			infos <- info{i: i, receipt: receipt, err: err}
 		}(i, n)
 	}
 
 	successfulNodes = make([]*pb.Node, len(nodes))
-	var successfulCount int
+	pieceHashes = make([][]byte, len(nodes))
+	receipts = make([]*pb.PieceHash, len(nodes))
+	report := pieceReports(ctx)
+	var cutNodes []string
 	for range nodes {
 		info := <-infos
+		cutOrCanceled := putCtx.Err() != nil && info.err != nil
+		if report != nil && nodes[info.i] != nil && info.err != io.ErrUnexpectedEOF && !cutOrCanceled {
+			report(PieceReport{NodeID: nodes[info.i].GetId(), Upload: true, Offline: info.offline, Err: info.err})
+		}
 		if info.err == nil {
+			if hashed[info.i] == nil {
+				continue
+			}
 			successfulNodes[info.i] = nodes[info.i]
+			pieceHashes[info.i] = hashed[info.i].Hash()
+			receipts[info.i] = info.receipt
 			successfulCount++
+			if successfulCount == cutAt {
+				cut()
+			}
+			continue
 		}
-	}
-
-	/* clean up the partially uploaded segment's pieces */
-	defer func() {
-		select {
-		case <-ctx.Done():
-			err = utils.CombineErrors(
-				Error.New("upload cancelled by user"),
-				ec.Delete(context.Background(), nodes, pieceID, authorization),
-			)
-		default:
+		// uploads failing with io.ErrUnexpectedEOF were cut by the encoder for
+		// being slower than the others
+		if nodes[info.i] != nil && (info.err == io.ErrUnexpectedEOF || (putCtx.Err() != nil && ctx.Err() == nil)) {
+			cutNodes = append(cutNodes, nodes[info.i].GetId())
 		}
-	}()
-
-	if successfulCount < rs.RepairThreshold() {
-		return nil, Error.New("successful puts (%d) less than repair threshold (%d)", successfulCount, rs.RepairThreshold())
+	}
+	mon.IntVal("cut_piece_uploads").Observe(int64(len(cutNodes)))
+	if len(cutNodes) > 0 {
+		zap.S().Debugf("Cut %d slow uploads of piece %s to nodes %v", len(cutNodes), pieceID, cutNodes)
 	}
 
-	return successfulNodes, nil
+	return successfulNodes, pieceHashes, receipts, successfulCount
 }
 
 func (ec *ecClient) Get(ctx context.Context, nodes []*pb.Node, es eestream.ErasureScheme,
-	pieceID client.PieceID, size int64, pba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (rr ranger.Ranger, err error) {
+	pieceID client.PieceID, size int64, pieceHashes [][]byte, pba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (rr ranger.Ranger, err error) {
 	defer mon.Task()(&ctx)(&err)
 
 	if len(nodes) != es.TotalCount() {
 		return nil, Error.New("number of nodes (%v) do not match total count (%v) of erasure scheme", len(nodes), es.TotalCount())
 	}
 
-	paddedSize := calcPadded(size, es.StripeSize())
+	paddedSize := eestream.PaddedSize(size, es.StripeSize())
 	pieceSize := paddedSize / int64(es.RequiredCount())
+	download := nodes
+	if ec.extraPieces >= 0 {
+		download = fastestPieces(nodes, es.RequiredCount()+ec.extraPieces, ec.latencies)
+	}
+	rrs := ec.pieceRangers(download, es, pieceID, pieceSize, pieceHashes, pba, authorization)
+
+	rr, err = eestream.Decode(rrs, es, ec.mbm, ec.pieceTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if ec.budget != nil {
+		rr = &budgetRanger{Ranger: rr, budget: ec.budget}
+	}
+
+	return eestream.Unpad(rr, int(paddedSize-size))
+}
+
+// budgetRanger reserves the memory of the buffers of its ranges from budget
+type budgetRanger struct {
+	ranger.Ranger
+	budget *eestream.MemoryBudget
+}
+
+// Range implements Ranger.Range
+func (rr *budgetRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	return rr.Ranger.Range(eestream.WithMemoryBudget(ctx, rr.budget), offset, length)
+}
+
+// pieceRangers returns the rangers of the pieces of pieceSize bytes stored on
+// nodes, indexed by the piece number. Pieces with a hash are verified while
+// they're read.
+func (ec *ecClient) pieceRangers(nodes []*pb.Node, es eestream.ErasureScheme, pieceID client.PieceID, pieceSize int64,
+	pieceHashes [][]byte, pba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) map[int]ranger.Ranger {
 	rrs := map[int]ranger.Ranger{}
 
 	type rangerInfo struct {
@@ -186,32 +334,154 @@ func (ec *ecClient) Get(ctx context.Context, nodes []*pb.Node, es eestream.Erasu
 				return
 			}
 
-			rr := &lazyPieceRanger{
+			lazy := &lazyPieceRanger{
 				dialer:        ec.d,
 				node:          n,
 				id:            derivedPieceID,
 				size:          pieceSize,
 				pba:           pba,
 				authorization: authorization,
+				latencies:     ec.latencies,
+			}
+			if i >= len(pieceHashes) || pieceHashes[i] == nil {
+				ch <- rangerInfo{i: i, rr: &reportingRanger{Ranger: lazy, nodeID: n.GetId()}, err: nil}
+				return
 			}
 
-			ch <- rangerInfo{i: i, rr: rr, err: nil}
+			// the erasure shares of the piece are followed by their hashes,
+			// so corrupted shares are reported like failed reads
+			lazy.size = eestream.HashedPieceSize(pieceSize, es.ErasureShareSize())
+			rr, err := eestream.VerifyShares(lazy, es.ErasureShareSize(), pieceHashes[i])
+			if err == nil {
+				rr = &reportingRanger{Ranger: rr, nodeID: n.GetId()}
+			}
+			ch <- rangerInfo{i: i, rr: rr, err: err}
 		}(i, n)
 	}
 
 	for range nodes {
 		rri := <-ch
 		if rri.err == nil && rri.rr != nil {
-			rrs[rri.i] = rri.rr
+			rrs[rri.i] = ec.downloadRate.ranger(rri.rr)
+		}
+	}
+	return rrs
+}
+
+func (ec *ecClient) Repair(ctx context.Context, nodes []*pb.Node, rs eestream.RedundancyStrategy,
+	pieceID client.PieceID, size int64, pieceHashes [][]byte, repairNodes []*pb.Node, expiration time.Time,
+	pba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (successfulNodes []*pb.Node, successfulHashes [][]byte, receipts []*pb.PieceHash, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if len(nodes) != rs.TotalCount() {
+		return nil, nil, nil, Error.New("number of nodes (%d) do not match total count (%d) of erasure scheme", len(nodes), rs.TotalCount())
+	}
+	if len(repairNodes) != rs.TotalCount() {
+		return nil, nil, nil, Error.New("number of repair nodes (%d) do not match total count (%d) of erasure scheme", len(repairNodes), rs.TotalCount())
+	}
+	if !unique(repairNodes) {
+		return nil, nil, nil, Error.New("duplicated nodes are not allowed")
+	}
+
+	var healthy int
+	var nums []int
+	for i := range nodes {
+		switch {
+		case repairNodes[i] != nil:
+			nums = append(nums, i)
+		case nodes[i] != nil:
+			healthy++
+		}
+	}
+	if len(nums) == 0 {
+		return nil, nil, nil, Error.New("no pieces to repair")
+	}
+
+	paddedSize := eestream.PaddedSize(size, rs.StripeSize())
+	pieceSize := paddedSize / int64(rs.RequiredCount())
+	download := make([]*pb.Node, len(nodes))
+	for i, n := range nodes {
+		if repairNodes[i] == nil {
+			download[i] = n
 		}
 	}
+	rrs := ec.pieceRangers(selectPieces(download, pieceHashes, rs.RequiredCount(), ec.latencies),
+		rs, pieceID, pieceSize, pieceHashes, pba, authorization)
+	downloaded, uploaded := trafficCounters(ctx)
+	for i, rr := range rrs {
+		rrs[i] = &meteredRanger{Ranger: rr, meter: mon.Meter("repair_download_bytes"), count: downloaded}
+	}
+
+	// the repaired uploads which are still running once the optimal
+	// threshold is reached are cut, like those of Put
+	putCtx, cut := context.WithCancel(ctx)
+	defer cut()
 
-	rr, err = eestream.Decode(rrs, es, ec.mbm)
+	rebuilt, err := eestream.Reconstruct(ec.withBudget(putCtx), rrs, rs, nums, ec.mbm, ec.pieceTimeout)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
+	}
+	readers := make([]io.Reader, len(repairNodes))
+	for num, r := range rebuilt {
+		readers[num] = &meteredReader{ReadCloser: r, meter: mon.Meter("repair_upload_bytes"), count: uploaded}
 	}
 
-	return eestream.Unpad(rr, int(paddedSize-size))
+	successfulNodes, successfulHashes, receipts, successfulCount := ec.putPieces(ctx, putCtx, cut, rs.OptimalThreshold()-healthy,
+		repairNodes, readers, rs.ErasureShareSize(), pieceID, expiration, pba, authorization)
+	mon.IntVal("repaired_pieces").Observe(int64(successfulCount))
+
+	/* clean up the partially uploaded repaired pieces */
+	defer func() {
+		select {
+		case <-ctx.Done():
+			err = utils.CombineErrors(
+				Error.New("repair cancelled by user"),
+				ec.Delete(context.Background(), repairNodes, pieceID, authorization),
+			)
+		default:
+		}
+	}()
+
+	if healthy+successfulCount < rs.RepairThreshold() {
+		return nil, nil, nil, Error.New("healthy (%d) and repaired (%d) pieces less than repair threshold (%d)",
+			healthy, successfulCount, rs.RepairThreshold())
+	}
+
+	return successfulNodes, successfulHashes, receipts, nil
+}
+
+// selectPieces returns the nodes of the pieces which are downloaded to
+// rebuild other pieces: the required count of them, preferring pieces with a
+// hash and then the nodes with the lowest latency, plus one more to detect
+// corrupted erasure shares if any of them has no hash.
+func selectPieces(nodes []*pb.Node, pieceHashes [][]byte, required int, l *latencies) []*pb.Node {
+	hasHash := func(i int) bool {
+		return i < len(pieceHashes) && pieceHashes[i] != nil
+	}
+	var order []int
+	for _, withHash := range []bool{true, false} {
+		var nums []int
+		for i, n := range nodes {
+			if n != nil && hasHash(i) == withHash {
+				nums = append(nums, i)
+			}
+		}
+		l.sort(nodes, nums)
+		order = append(order, nums...)
+	}
+
+	selected := make([]*pb.Node, len(nodes))
+	count := required
+	for j, i := range order {
+		if j == count {
+			break
+		}
+		if !hasHash(i) {
+			count = required + 1
+		}
+		selected[i] = nodes[i]
+	}
+	return selected
 }
 
 func (ec *ecClient) Delete(ctx context.Context, nodes []*pb.Node, pieceID client.PieceID, authorization *pb.SignedMessage) (err error) {
@@ -292,22 +562,17 @@ func unique(nodes []*pb.Node) bool {
 	return true
 }
 
-func calcPadded(size int64, blockSize int) int64 {
-	mod := size % int64(blockSize)
-	if mod == 0 {
-		return size
-	}
-	return size + int64(blockSize) - mod
-}
-
 type lazyPieceRanger struct {
-	ranger        ranger.Ranger
 	dialer        dialer
 	node          *pb.Node
 	id            client.PieceID
 	size          int64
 	pba           *pb.PayerBandwidthAllocation
 	authorization *pb.SignedMessage
+	latencies     *latencies
+
+	mu sync.Mutex
+	ps client.PSClient
 }
 
 // Size implements Ranger.Size
@@ -315,18 +580,50 @@ func (lr *lazyPieceRanger) Size() int64 {
 	return lr.size
 }
 
-// Range implements Ranger.Range to be lazily connected
-func (lr *lazyPieceRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
-	if lr.ranger == nil {
-		ps, err := lr.dialer.dial(ctx, lr.node)
-		if err != nil {
-			return nil, err
-		}
-		ranger, err := ps.Get(ctx, lr.id, lr.size, lr.pba, lr.authorization)
+// dial returns the client of the node of the piece, which is dialed the
+// first time, so all the ranges of the piece reuse the same connection
+func (lr *lazyPieceRanger) dial(ctx context.Context) (client.PSClient, error) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	if lr.ps != nil {
+		return lr.ps, nil
+	}
+	ps, err := lr.dialer.dial(ctx, lr.node)
+	if err != nil {
+		return nil, dialError{err}
+	}
+	lr.ps = ps
+	return ps, nil
+}
+
+// Range implements Ranger.Range to be lazily connected. The node is dialed
+// for the first range, and the following ranges reuse the connection, though
+// each range requests the piece again. The latency of the node to serve the
+// range is observed.
+func (lr *lazyPieceRanger) Range(ctx context.Context, offset, length int64) (_ io.ReadCloser, err error) {
+	start := time.Now()
+	defer func() {
 		if err != nil {
-			return nil, err
+			lr.latencies.observe(lr.node.GetId(), failedLatency)
 		}
-		lr.ranger = ranger
+	}()
+
+	ps, err := lr.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ranger, err := ps.Get(ctx, lr.id, lr.size, lr.pba, lr.authorization)
+	if err != nil {
+		return nil, err
+	}
+	r, err := ranger.Range(ctx, offset, length)
+	if err != nil || length == 0 {
+		return r, err
 	}
-	return lr.ranger.Range(ctx, offset, length)
+	return &latencyReader{
+		ReadCloser: r,
+		latencies:  lr.latencies,
+		nodeID:     lr.node.GetId(),
+		start:      start,
+	}, nil
 }
@@ -4,6 +4,7 @@
 package ecclient
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"crypto/elliptic"
@@ -12,6 +13,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"sync"
 	"testing"
 	"time"
 
@@ -45,10 +47,19 @@ var (
 
 type mockDialer struct {
 	m map[*pb.Node]client.PSClient
+
+	mu    sync.Mutex
+	dials map[*pb.Node]int
 }
 
 func (d *mockDialer) dial(ctx context.Context, node *pb.Node) (
 	ps client.PSClient, err error) {
+	d.mu.Lock()
+	if d.dials == nil {
+		d.dials = make(map[*pb.Node]int)
+	}
+	d.dials[node]++
+	d.mu.Unlock()
 	ps = d.m[node]
 	if ps == nil {
 		return nil, ErrDialFailed
@@ -62,16 +73,23 @@ func TestNewECClient(t *testing.T) {
 
 	transport := NewMockClient(ctrl)
 	mbm := 1234
+	pieceTimeout := 5 * time.Second
+	budget, err := eestream.NewMemoryBudget(1 << 20)
+	assert.NoError(t, err)
 
 	privKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	identity := &provider.FullIdentity{Key: privKey}
-	ec := NewClient(identity, transport, mbm)
+	ec := NewClient(identity, transport, mbm, pieceTimeout, budget, 3, nil, nil, nil)
 	assert.NotNil(t, ec)
 
 	ecc, ok := ec.(*ecClient)
 	assert.True(t, ok)
 	assert.NotNil(t, ecc.d)
 	assert.Equal(t, mbm, ecc.mbm)
+	assert.Equal(t, pieceTimeout, ecc.pieceTimeout)
+	assert.Equal(t, budget, ecc.budget)
+	assert.Equal(t, 3, ecc.extraPieces)
+	assert.NotNil(t, ecc.latencies)
 
 	dd, ok := ecc.d.(*defaultDialer)
 	assert.True(t, ok)
@@ -178,7 +196,7 @@ TestLoop:
 			}
 			ps := NewMockPSClient(ctrl)
 			gomock.InOrder(
-				ps.EXPECT().Put(gomock.Any(), derivedID, gomock.Any(), ttl, gomock.Any(), gomock.Any()).Return(errs[n]).
+				ps.EXPECT().Put(gomock.Any(), derivedID, gomock.Any(), ttl, gomock.Any(), gomock.Any()).Return(receipt(n), errs[n]).
 					Do(func(ctx context.Context, id client.PieceID, data io.Reader, ttl time.Time, ba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) {
 						// simulate that the mocked piece store client is reading the data
 						_, err := io.Copy(ioutil.Discard, data)
@@ -195,24 +213,97 @@ TestLoop:
 		r := io.LimitReader(rand.Reader, int64(size))
 		ec := ecClient{d: &mockDialer{m: m}, mbm: tt.mbm}
 
-		successfulNodes, err := ec.Put(ctx, tt.nodes, rs, id, r, ttl, nil, nil)
+		reports := map[string]error{}
+		reportCtx := WithPieceReports(ctx, func(report PieceReport) {
+			assert.True(t, report.Upload, errTag)
+			reports[report.NodeID] = report.Err
+		})
+
+		successfulNodes, hashes, receipts, err := ec.Put(reportCtx, tt.nodes, rs, id, r, ttl, nil, nil)
+
+		if !tt.badInput {
+			expected := map[string]error{}
+			for i, n := range tt.nodes {
+				if n != nil {
+					expected[n.GetId()] = tt.errs[i]
+				}
+			}
+			assert.Equal(t, expected, reports, errTag)
+		}
 
 		if tt.errString != "" {
 			assert.EqualError(t, err, tt.errString, errTag)
 		} else {
 			assert.NoError(t, err, errTag)
 			assert.Equal(t, len(tt.nodes), len(successfulNodes), errTag)
+			assert.Equal(t, len(tt.nodes), len(hashes), errTag)
+			assert.Equal(t, len(tt.nodes), len(receipts), errTag)
 			for i := range tt.nodes {
 				if tt.errs[i] != nil {
 					assert.Nil(t, successfulNodes[i], errTag)
+					assert.Nil(t, hashes[i], errTag)
+					assert.Nil(t, receipts[i], errTag)
 				} else {
 					assert.Equal(t, tt.nodes[i], successfulNodes[i], errTag)
+					assert.Len(t, hashes[i], eestream.ShareHashSize, errTag)
+					assert.Equal(t, receipt(tt.nodes[i]), receipts[i], errTag)
 				}
 			}
 		}
 	}
 }
 
+func TestPutCutsSlowUploads(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	size := 32 * 1024
+	fc, err := infectious.NewFEC(2, 4)
+	if !assert.NoError(t, err) {
+		return
+	}
+	rs, err := eestream.NewRedundancyStrategy(eestream.NewRSScheme(fc, size/4), 2, 3)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	id := client.NewPieceID()
+	ttl := time.Now()
+	nodes := []*pb.Node{node0, node1, node2, node3}
+
+	m := make(map[*pb.Node]client.PSClient, len(nodes))
+	for _, n := range nodes {
+		derivedID, err := id.Derive([]byte(n.GetId()))
+		if !assert.NoError(t, err) {
+			return
+		}
+		slow := n == node3
+		ps := NewMockPSClient(ctrl)
+		gomock.InOrder(
+			ps.EXPECT().Put(gomock.Any(), derivedID, gomock.Any(), ttl, gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, id client.PieceID, data io.Reader, ttl time.Time, ba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (*pb.PieceHash, error) {
+					if slow {
+						// the slow node doesn't finish until its upload is cut
+						<-ctx.Done()
+						return nil, ctx.Err()
+					}
+					_, err := io.Copy(ioutil.Discard, data)
+					return receipt(n), err
+				}),
+			ps.EXPECT().Close().Return(nil),
+		)
+		m[n] = ps
+	}
+
+	r := io.LimitReader(rand.Reader, int64(size))
+	ec := ecClient{d: &mockDialer{m: m}}
+
+	successfulNodes, _, _, err := ec.Put(ctx, nodes, rs, id, r, ttl, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*pb.Node{node0, node1, node2, nil}, successfulNodes)
+}
+
 func TestGet(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -269,15 +360,22 @@ TestLoop:
 					continue TestLoop
 				}
 				ps := NewMockPSClient(ctrl)
-				ps.EXPECT().Get(gomock.Any(), derivedID, int64(size/k), gomock.Any(), gomock.Any()).Return(ranger.ByteRanger(nil), errs[n])
+				// the pieces are the padded data split in k
+				pieceSize := eestream.PaddedSize(int64(size), es.StripeSize()) / int64(k)
+				ps.EXPECT().Get(gomock.Any(), derivedID, pieceSize, gomock.Any(), gomock.Any()).Return(ranger.ByteRanger(nil), errs[n])
 				m[n] = ps
 			}
 		}
-		ec := ecClient{d: &mockDialer{m: m}, mbm: tt.mbm}
-		rr, err := ec.Get(ctx, tt.nodes, es, id, int64(size), nil, nil)
+		// all the pieces are downloaded, so each failing node is requested
+		ec := ecClient{d: &mockDialer{m: m}, mbm: tt.mbm, extraPieces: -1}
+		rr, err := ec.Get(ctx, tt.nodes, es, id, int64(size), nil, nil, nil)
 		if err == nil {
-			_, err := rr.Range(ctx, 0, 0)
-			assert.NoError(t, err, errTag)
+			r, err := rr.Range(ctx, 0, 0)
+			if assert.NoError(t, err, errTag) {
+				// the pieces are requested in the background until the
+				// reader is closed
+				assert.NoError(t, r.Close(), errTag)
+			}
 		}
 		if tt.errString != "" {
 			assert.EqualError(t, err, tt.errString, errTag)
@@ -288,6 +386,337 @@ TestLoop:
 	}
 }
 
+// memPieceStores are mocked piece store clients which keep the pieces put to
+// them in memory
+type memPieceStores struct {
+	mu         sync.Mutex
+	pieces     map[*pb.Node][]byte
+	gets       map[*pb.Node]int
+	downloaded map[*pb.Node]int64
+}
+
+// memPiece is a piece got from memPieceStores, which counts the bytes of the
+// ranges requested from it
+type memPiece struct {
+	ranger.Ranger
+	stores *memPieceStores
+	node   *pb.Node
+}
+
+func (piece *memPiece) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	piece.stores.mu.Lock()
+	piece.stores.downloaded[piece.node] += length
+	piece.stores.mu.Unlock()
+	return piece.Ranger.Range(ctx, offset, length)
+}
+
+func newMemPieceStores(t *testing.T, ctrl *gomock.Controller, id client.PieceID, nodes ...*pb.Node) (*memPieceStores, map[*pb.Node]client.PSClient) {
+	stores := &memPieceStores{
+		pieces:     make(map[*pb.Node][]byte, len(nodes)),
+		gets:       make(map[*pb.Node]int, len(nodes)),
+		downloaded: make(map[*pb.Node]int64, len(nodes)),
+	}
+	m := make(map[*pb.Node]client.PSClient, len(nodes))
+	for _, n := range nodes {
+		n := n
+		derivedID, err := id.Derive([]byte(n.GetId()))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		ps := NewMockPSClient(ctrl)
+		ps.EXPECT().Put(gomock.Any(), derivedID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, id client.PieceID, data io.Reader, ttl time.Time, ba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (*pb.PieceHash, error) {
+				piece, err := ioutil.ReadAll(data)
+				stores.mu.Lock()
+				stores.pieces[n] = piece
+				stores.mu.Unlock()
+				return receipt(n), err
+			}).AnyTimes()
+		ps.EXPECT().Get(gomock.Any(), derivedID, gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, id client.PieceID, size int64, ba *pb.PayerBandwidthAllocation, authorization *pb.SignedMessage) (ranger.Ranger, error) {
+				stores.mu.Lock()
+				defer stores.mu.Unlock()
+				stores.gets[n]++
+				assert.Equal(t, int64(len(stores.pieces[n])), size)
+				return &memPiece{Ranger: ranger.ByteRanger(stores.pieces[n]), stores: stores, node: n}, nil
+			}).AnyTimes()
+		ps.EXPECT().Close().Return(nil).AnyTimes()
+		m[n] = ps
+	}
+	return stores, m
+}
+
+// receipt returns the receipt of the mocked piece store client of n, if any
+func receipt(n *pb.Node) *pb.PieceHash {
+	if n == nil {
+		return nil
+	}
+	return &pb.PieceHash{Signature: []byte(n.GetId())}
+}
+
+func (stores *memPieceStores) piece(n *pb.Node) []byte {
+	stores.mu.Lock()
+	defer stores.mu.Unlock()
+	return stores.pieces[n]
+}
+
+func TestPutGetVerifiesShares(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	size := 32 * 1024
+	fc, err := infectious.NewFEC(2, 4)
+	if !assert.NoError(t, err) {
+		return
+	}
+	es := eestream.NewRSScheme(fc, 1024)
+	rs, err := eestream.NewRedundancyStrategy(es, 0, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	id := client.NewPieceID()
+	nodes := []*pb.Node{node0, node1, node2, node3}
+	stores, m := newMemPieceStores(t, ctrl, id, nodes...)
+
+	data := make([]byte, size)
+	_, err = rand.Read(data)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// one piece more than required is downloaded, to decode without the
+	// corrupted one
+	dialer := &mockDialer{m: m}
+	ec := ecClient{d: dialer, extraPieces: 1}
+
+	_, hashes, _, err := ec.Put(ctx, nodes, rs, id, bytes.NewReader(data), time.Now(), nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the pieces are stored with the hashes of their erasure shares, in
+	// the size they're got with
+	pieceSize := eestream.PaddedSize(int64(size), es.StripeSize()) / int64(es.RequiredCount())
+	for _, n := range nodes {
+		assert.Equal(t, eestream.HashedPieceSize(pieceSize, es.ErasureShareSize()), int64(len(stores.piece(n))))
+	}
+
+	// corrupt an erasure share of a piece, which is then left out of decoding
+	stores.piece(node1)[0] ^= 1
+
+	dialer.dials = nil
+	rr, err := ec.Get(ctx, nodes, es, id, int64(size), hashes, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	r, err := rr.Range(ctx, 0, rr.Size())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() { assert.NoError(t, r.Close()) }()
+	got, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	// the hashes and the erasure shares of each piece are requested over
+	// the same connection
+	assert.Equal(t, map[*pb.Node]int{node0: 1, node1: 1, node2: 1}, dialer.dials)
+	assert.Equal(t, map[*pb.Node]int{node0: 2, node1: 2, node2: 2}, stores.gets)
+}
+
+func TestGetRange(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	size := 32 * 1024
+	fc, err := infectious.NewFEC(2, 4)
+	if !assert.NoError(t, err) {
+		return
+	}
+	es := eestream.NewRSScheme(fc, 1024)
+	rs, err := eestream.NewRedundancyStrategy(es, 0, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	id := client.NewPieceID()
+	nodes := []*pb.Node{node0, node1, node2, node3}
+	stores, m := newMemPieceStores(t, ctrl, id, nodes...)
+
+	data := make([]byte, size)
+	_, err = rand.Read(data)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ec := ecClient{d: &mockDialer{m: m}}
+
+	_, hashes, _, err := ec.Put(ctx, nodes, rs, id, bytes.NewReader(data), time.Now(), nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rr, err := ec.Get(ctx, nodes, es, id, int64(size), hashes, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// a range within the stripe 5 is decoded from the erasure shares of the
+	// stripe, verified with the hashes of the shares of the pieces
+	offset, length := int64(5*es.StripeSize()+10), int64(100)
+	r, err := rr.Range(ctx, offset, length)
+	if !assert.NoError(t, err) {
+		return
+	}
+	got, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, data[offset:offset+length], got)
+
+	pieceSize := eestream.PaddedSize(int64(size), es.StripeSize()) / int64(es.RequiredCount())
+	hashesSize := pieceSize / int64(es.ErasureShareSize()) * eestream.ShareHashSize
+	downloaded := int64(es.ErasureShareSize()) + hashesSize
+	assert.Equal(t, map[*pb.Node]int64{node0: downloaded, node1: downloaded}, stores.downloaded)
+	assert.True(t, downloaded < eestream.HashedPieceSize(pieceSize, es.ErasureShareSize())/10)
+}
+
+func TestRepair(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	size := 32 * 1024
+	fc, err := infectious.NewFEC(2, 4)
+	if !assert.NoError(t, err) {
+		return
+	}
+	rs, err := eestream.NewRedundancyStrategy(eestream.NewRSScheme(fc, 1024), 3, 4)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	id := client.NewPieceID()
+	nodes := []*pb.Node{node0, node1, node2, node3}
+	node4, node5 := &pb.Node{Id: "node-4"}, &pb.Node{Id: "node-5"}
+	stores, m := newMemPieceStores(t, ctrl, id, node0, node1, node2, node3, node4, node5)
+
+	data := make([]byte, size)
+	_, err = rand.Read(data)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ec := ecClient{d: &mockDialer{m: m}}
+
+	_, hashes, _, err := ec.Put(ctx, nodes, rs, id, bytes.NewReader(data), time.Now(), nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the pieces 1 and 3 are lost, and rebuilt on new nodes
+	healthy := []*pb.Node{node0, nil, node2, nil}
+	repairNodes := []*pb.Node{nil, node4, nil, node5}
+	successfulNodes, successfulHashes, receipts, err := ec.Repair(ctx, healthy, rs, id, int64(size), hashes,
+		repairNodes, time.Now(), nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, repairNodes, successfulNodes)
+	assert.Equal(t, [][]byte{nil, hashes[1], nil, hashes[3]}, successfulHashes)
+	assert.Equal(t, []*pb.PieceHash{nil, receipt(node4), nil, receipt(node5)}, receipts)
+	assert.Equal(t, stores.piece(node1), stores.piece(node4))
+	assert.Equal(t, stores.piece(node3), stores.piece(node5))
+
+	// just the required pieces are downloaded, as they're verified with
+	// their hashes, which are requested separately from the erasure shares
+	assert.Equal(t, map[*pb.Node]int{node0: 2, node2: 2}, stores.gets)
+
+	_, _, _, err = ec.Repair(ctx, healthy, rs, id, int64(size), hashes, make([]*pb.Node, 4), time.Now(), nil, nil)
+	assert.EqualError(t, err, "ecclient error: no pieces to repair")
+	_, _, _, err = ec.Repair(ctx, healthy, rs, id, int64(size), hashes, []*pb.Node{nil, node4, nil, node4}, time.Now(), nil, nil)
+	assert.EqualError(t, err, "ecclient error: duplicated nodes are not allowed")
+}
+
+func TestSelectPieces(t *testing.T) {
+	nodes := []*pb.Node{node0, nil, node2, node3}
+	hash := []byte("hash")
+
+	// pieces with hashes are preferred
+	selected := selectPieces(nodes, [][]byte{nil, nil, hash, hash}, 2, nil)
+	assert.Equal(t, []*pb.Node{nil, nil, node2, node3}, selected)
+
+	// without hashes, an extra piece is selected
+	selected = selectPieces(nodes, [][]byte{nil, nil, hash, nil}, 2, nil)
+	assert.Equal(t, []*pb.Node{node0, nil, node2, node3}, selected)
+	selected = selectPieces(nodes, nil, 2, nil)
+	assert.Equal(t, []*pb.Node{node0, nil, node2, node3}, selected)
+
+	// among the pieces with hashes, the fastest nodes are preferred
+	l := newLatencies()
+	l.observe(node0.Id, 3*time.Millisecond)
+	l.observe(node2.Id, 2*time.Millisecond)
+	l.observe(node3.Id, time.Millisecond)
+	selected = selectPieces(nodes, [][]byte{hash, nil, hash, hash}, 2, l)
+	assert.Equal(t, []*pb.Node{nil, nil, node2, node3}, selected)
+}
+
+func TestGetPrefersFastNodes(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	size := 32 * 1024
+	fc, err := infectious.NewFEC(2, 4)
+	if !assert.NoError(t, err) {
+		return
+	}
+	es := eestream.NewRSScheme(fc, 1024)
+	rs, err := eestream.NewRedundancyStrategy(es, 0, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	id := client.NewPieceID()
+	nodes := []*pb.Node{node0, node1, node2, node3}
+	stores, m := newMemPieceStores(t, ctrl, id, nodes...)
+
+	data := make([]byte, size)
+	_, err = rand.Read(data)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ec := ecClient{d: &mockDialer{m: m}, extraPieces: 1, latencies: newLatencies()}
+
+	_, hashes, _, err := ec.Put(ctx, nodes, rs, id, bytes.NewReader(data), time.Now(), nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// node0 is slow and node1 failed before
+	ec.latencies.observe(node0.Id, time.Second)
+	ec.latencies.observe(node1.Id, failedLatency)
+	ec.latencies.observe(node2.Id, time.Millisecond)
+
+	rr, err := ec.Get(ctx, nodes, es, id, int64(size), hashes, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	r, err := rr.Range(ctx, 0, rr.Size())
+	if !assert.NoError(t, err) {
+		return
+	}
+	got, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+	assert.NoError(t, r.Close())
+
+	// node3, without history, is deemed as fast as the average, and the
+	// hashes of the pieces are requested separately from the erasure shares
+	stores.mu.Lock()
+	defer stores.mu.Unlock()
+	assert.Equal(t, map[*pb.Node]int{node0: 2, node2: 2, node3: 2}, stores.gets)
+}
+
 func TestDelete(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -0,0 +1,136 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ecclient
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"storj.io/storj/pkg/pb"
+)
+
+// failedLatency is the latency observed for a node which fails to serve a
+// piece, so its pieces are downloaded after those of the nodes which serve
+// them
+const failedLatency = 10 * time.Second
+
+// latencies keeps the historic latency of the nodes pieces are downloaded
+// from, as a moving average of the time to the first byte of their pieces
+type latencies struct {
+	mu    sync.Mutex
+	nodes map[string]time.Duration
+}
+
+func newLatencies() *latencies {
+	return &latencies{nodes: make(map[string]time.Duration)}
+}
+
+// observe adds the latency of the node with nodeID to its moving average,
+// which weights the last latency by a quarter
+func (l *latencies) observe(nodeID string, latency time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if average, ok := l.nodes[nodeID]; ok {
+		latency = average + (latency-average)/4
+	}
+	l.nodes[nodeID] = latency
+}
+
+// sort sorts the piece numbers nums by the latency of their nodes. The nodes
+// without history are deemed to have the average latency of the others, so
+// they're tried before the slow nodes. Pieces with the same latency keep
+// their order.
+func (l *latencies) sort(nodes []*pb.Node, nums []int) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var sum time.Duration
+	var known int
+	for _, num := range nums {
+		if latency, ok := l.nodes[nodes[num].GetId()]; ok {
+			sum += latency
+			known++
+		}
+	}
+	if known == 0 {
+		return
+	}
+	average := sum / time.Duration(known)
+
+	latency := func(num int) time.Duration {
+		if latency, ok := l.nodes[nodes[num].GetId()]; ok {
+			return latency
+		}
+		return average
+	}
+	sort.SliceStable(nums, func(i, j int) bool {
+		return latency(nums[i]) < latency(nums[j])
+	})
+}
+
+// fastestPieces returns the nodes of the count pieces of nodes with the
+// lowest latency. The others are nil, so they aren't downloaded.
+func fastestPieces(nodes []*pb.Node, count int, l *latencies) []*pb.Node {
+	var nums []int
+	for i, n := range nodes {
+		if n != nil {
+			nums = append(nums, i)
+		}
+	}
+	l.sort(nodes, nums)
+
+	selected := make([]*pb.Node, len(nodes))
+	for j, i := range nums {
+		if j == count {
+			break
+		}
+		selected[i] = nodes[i]
+	}
+	return selected
+}
+
+// latencyReader observes the latency of a node when the first byte of its
+// piece is read. If the piece is closed before, it was slower than the other
+// pieces, so the time until it's closed is observed.
+type latencyReader struct {
+	io.ReadCloser
+	latencies *latencies
+	nodeID    string
+	start     time.Time
+	once      sync.Once
+}
+
+// observe observes the latency of the node, if it wasn't yet
+func (r *latencyReader) observe(failed bool) {
+	r.once.Do(func() {
+		latency := time.Since(r.start)
+		if failed {
+			latency = failedLatency
+		}
+		r.latencies.observe(r.nodeID, latency)
+	})
+}
+
+// Read implements io.Reader
+func (r *latencyReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	if n > 0 || err != nil {
+		r.observe(n == 0 && err != io.EOF)
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (r *latencyReader) Close() error {
+	r.observe(false)
+	return r.ReadCloser.Close()
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ecclient
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/internal/readcloser"
+	"storj.io/storj/pkg/pb"
+)
+
+func TestLatencies(t *testing.T) {
+	l := newLatencies()
+	l.observe("node", 100*time.Millisecond)
+	assert.Equal(t, 100*time.Millisecond, l.nodes["node"])
+	l.observe("node", 500*time.Millisecond)
+	assert.Equal(t, 200*time.Millisecond, l.nodes["node"])
+
+	// without a history, the order is kept
+	nodes := []*pb.Node{node0, node1, node2, node3}
+	nums := []int{0, 1, 2, 3}
+	newLatencies().sort(nodes, nums)
+	assert.Equal(t, []int{0, 1, 2, 3}, nums)
+
+	// nodes without history are deemed to have the average latency
+	l = newLatencies()
+	l.observe(node0.Id, 4*time.Millisecond)
+	l.observe(node2.Id, time.Millisecond)
+	l.observe(node3.Id, 2*time.Millisecond)
+	l.sort(nodes, nums)
+	assert.Equal(t, []int{2, 3, 1, 0}, nums)
+
+	assert.Equal(t, []*pb.Node{nil, node1, node2, node3}, fastestPieces(nodes, 3, l))
+	assert.Equal(t, []*pb.Node{nil, nil, node2, nil}, fastestPieces([]*pb.Node{node0, nil, node2, nil}, 1, l))
+	assert.Equal(t, nodes, fastestPieces(nodes, 4, nil))
+}
+
+func TestLatencyReader(t *testing.T) {
+	l := newLatencies()
+	r := &latencyReader{
+		ReadCloser: ioutil.NopCloser(bytes.NewReader([]byte("data"))),
+		latencies:  l,
+		nodeID:     "node",
+		start:      time.Now().Add(-time.Second),
+	}
+	data, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+	assert.NoError(t, r.Close())
+	latency := l.nodes["node"]
+	assert.True(t, latency >= time.Second && latency < failedLatency, "%v", latency)
+
+	// a failed read observes the latency of failures
+	l = newLatencies()
+	r = &latencyReader{
+		ReadCloser: readcloser.FatalReadCloser(errors.New("read failed")),
+		latencies:  l,
+		nodeID:     "node",
+		start:      time.Now(),
+	}
+	_, err = ioutil.ReadAll(r)
+	assert.Error(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, failedLatency, l.nodes["node"])
+
+	// a piece closed before its first byte was slower than the others
+	l = newLatencies()
+	r = &latencyReader{
+		ReadCloser: ioutil.NopCloser(bytes.NewReader([]byte("data"))),
+		latencies:  l,
+		nodeID:     "node",
+		start:      time.Now().Add(-time.Second),
+	}
+	assert.NoError(t, r.Close())
+	assert.True(t, l.nodes["node"] >= time.Second)
+}
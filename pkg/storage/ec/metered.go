@@ -0,0 +1,83 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ecclient
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+
+	monkit "gopkg.in/spacemonkeygo/monkit.v2"
+
+	"storj.io/storj/pkg/ranger"
+)
+
+// Traffic counts the bytes of the pieces downloaded and uploaded by repairs
+type Traffic struct {
+	downloaded int64
+	uploaded   int64
+}
+
+// Downloaded returns the bytes of the pieces downloaded so far
+func (traffic *Traffic) Downloaded() int64 {
+	return atomic.LoadInt64(&traffic.downloaded)
+}
+
+// Uploaded returns the bytes of the pieces uploaded so far
+func (traffic *Traffic) Uploaded() int64 {
+	return atomic.LoadInt64(&traffic.uploaded)
+}
+
+type trafficCtxKey struct{}
+
+// WithTraffic returns a context with which the bytes of the pieces
+// downloaded and uploaded by repairs are counted in traffic
+func WithTraffic(ctx context.Context, traffic *Traffic) context.Context {
+	return context.WithValue(ctx, trafficCtxKey{}, traffic)
+}
+
+// trafficCounters returns the counters of the bytes downloaded and uploaded
+// with ctx, which are nil if they aren't counted
+func trafficCounters(ctx context.Context) (downloaded, uploaded *int64) {
+	traffic, _ := ctx.Value(trafficCtxKey{}).(*Traffic)
+	if traffic == nil {
+		return nil, nil
+	}
+	return &traffic.downloaded, &traffic.uploaded
+}
+
+// meteredRanger marks the bytes read from its ranges on meter, and adds them
+// to count if it's set
+type meteredRanger struct {
+	ranger.Ranger
+	meter *monkit.Meter
+	count *int64
+}
+
+// Range implements Ranger.Range
+func (rr *meteredRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	r, err := rr.Ranger.Range(ctx, offset, length)
+	if err != nil {
+		return r, err
+	}
+	return &meteredReader{ReadCloser: r, meter: rr.meter, count: rr.count}, nil
+}
+
+// meteredReader marks the bytes read from it on meter, and adds them to count
+// if it's set
+type meteredReader struct {
+	io.ReadCloser
+	meter *monkit.Meter
+	count *int64
+}
+
+// Read implements io.Reader
+func (r *meteredReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	r.meter.Mark(n)
+	if r.count != nil {
+		atomic.AddInt64(r.count, int64(n))
+	}
+	return n, err
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ecclient
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"storj.io/storj/pkg/ranger"
+)
+
+// RateLimiter limits the bytes per second transferred by all the piece
+// transfers sharing it, with a token bucket holding up to a second of bytes.
+// A nil RateLimiter doesn't limit anything.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter transferring at most rate bytes per
+// second, or nil if rate is 0, which doesn't limit anything
+func NewRateLimiter(rate int64) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &RateLimiter{
+		rate:   float64(rate),
+		burst:  float64(rate),
+		tokens: float64(rate),
+		last:   time.Now(),
+	}
+}
+
+// wait takes n bytes from the bucket, waiting until they're refilled if
+// the bucket runs out of them
+func (l *RateLimiter) wait(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	// the bytes are taken right away, so the transfers waiting after this
+	// one wait for them to be refilled too
+	l.tokens -= float64(n)
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reader returns r limited by l, reading with ctx
+func (l *RateLimiter) reader(ctx context.Context, r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &limitedReader{Reader: r, ctx: ctx, limiter: l}
+}
+
+// ranger returns rr, whose ranges are limited by l
+func (l *RateLimiter) ranger(rr ranger.Ranger) ranger.Ranger {
+	if l == nil {
+		return rr
+	}
+	return &limitedRanger{Ranger: rr, limiter: l}
+}
+
+// limitedRanger is a ranger whose ranges are read at the rate of limiter
+type limitedRanger struct {
+	ranger.Ranger
+	limiter *RateLimiter
+}
+
+// Range implements Ranger.Range
+func (rr *limitedRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	r, err := rr.Ranger.Range(ctx, offset, length)
+	if err != nil {
+		return r, err
+	}
+	return &limitedReadCloser{
+		limitedReader: limitedReader{Reader: r, ctx: ctx, limiter: rr.limiter},
+		Closer:        r,
+	}, nil
+}
+
+// limitedReader is a reader read at the rate of limiter
+type limitedReader struct {
+	io.Reader
+	ctx     context.Context
+	limiter *RateLimiter
+}
+
+// Read implements io.Reader
+func (r *limitedReader) Read(p []byte) (n int, err error) {
+	// reads are at most as big as the bucket, so they don't wait for more
+	// than a second
+	if len(p) > int(r.limiter.burst) {
+		p = p[:int(r.limiter.burst)]
+	}
+	n, err = r.Reader.Read(p)
+	if waitErr := r.limiter.wait(r.ctx, n); waitErr != nil && err == nil {
+		err = waitErr
+	}
+	return n, err
+}
+
+// limitedReadCloser is a limitedReader closing the reader it reads
+type limitedReadCloser struct {
+	limitedReader
+	io.Closer
+}
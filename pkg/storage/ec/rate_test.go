@@ -0,0 +1,54 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ecclient
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/ranger"
+)
+
+func TestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	l := NewRateLimiter(1000)
+	data := make([]byte, 1500)
+
+	// a second of bytes is transferred right away, and the rest at the rate
+	// of the limiter, over all the transfers
+	start := time.Now()
+	read, err := ioutil.ReadAll(l.reader(ctx, bytes.NewReader(data)))
+	assert.NoError(t, err)
+	assert.Equal(t, data, read)
+
+	r, err := l.ranger(ranger.ByteRanger(data)).Range(ctx, 0, 100)
+	if assert.NoError(t, err) {
+		read, err = ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, data[:100], read)
+		assert.NoError(t, r.Close())
+	}
+	assert.True(t, time.Since(start) >= 550*time.Millisecond)
+
+	// a canceled wait fails the read
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = ioutil.ReadAll(l.reader(canceled, bytes.NewReader(data)))
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestRateLimiter_Nil(t *testing.T) {
+	l := NewRateLimiter(0)
+	assert.Nil(t, l)
+
+	r := bytes.NewReader(nil)
+	assert.Equal(t, r, l.reader(context.Background(), r))
+	rr := ranger.ByteRanger(nil)
+	assert.Equal(t, rr, l.ranger(rr))
+}
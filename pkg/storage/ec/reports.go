@@ -0,0 +1,121 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ecclient
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"storj.io/storj/pkg/ranger"
+)
+
+// PieceReport is the outcome of downloading a piece from a node, or of
+// uploading one to it
+type PieceReport struct {
+	NodeID string
+	// Upload is set if the piece was uploaded rather than downloaded
+	Upload bool
+	// Offline is set if the node couldn't be dialed
+	Offline bool
+	// Err is why the transfer failed, or nil if the node served or stored the
+	// piece
+	Err error
+}
+
+type reportsCtxKey struct{}
+
+// WithPieceReports returns a context with which the outcome of each piece
+// downloaded or uploaded is reported to report, once the node served or
+// stored the piece or failed to. The transfers which are canceled, because
+// enough pieces were transferred or the context is canceled, aren't
+// reported.
+func WithPieceReports(ctx context.Context, report func(PieceReport)) context.Context {
+	return context.WithValue(ctx, reportsCtxKey{}, report)
+}
+
+// pieceReports returns the function the downloads with ctx are reported to,
+// or nil if they aren't reported
+func pieceReports(ctx context.Context) func(PieceReport) {
+	report, _ := ctx.Value(reportsCtxKey{}).(func(PieceReport))
+	return report
+}
+
+// dialError is an error dialing the node of a piece, which is reported as
+// the node being offline
+type dialError struct {
+	error
+}
+
+// reportingRanger reports the outcome of reading the ranges of the piece on
+// the node with the given id, where the context of the range has a function
+// to report to
+type reportingRanger struct {
+	ranger.Ranger
+	nodeID string
+}
+
+// Range implements Ranger.Range
+func (rr *reportingRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	report := pieceReports(ctx)
+	r, err := rr.Ranger.Range(ctx, offset, length)
+	if report == nil {
+		return r, err
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			_, offline := err.(dialError)
+			report(PieceReport{NodeID: rr.nodeID, Offline: offline, Err: err})
+		}
+		return r, err
+	}
+	return &reportingReader{ReadCloser: r, ctx: ctx, nodeID: rr.nodeID, report: report, remaining: length}, nil
+}
+
+// reportingReader reports whether the remaining bytes of its range were read
+type reportingReader struct {
+	io.ReadCloser
+	ctx       context.Context
+	nodeID    string
+	report    func(PieceReport)
+	remaining int64
+
+	mu   sync.Mutex
+	done bool
+}
+
+// Read implements io.Reader
+func (r *reportingReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	r.remaining -= int64(n)
+	switch {
+	case r.remaining <= 0 && (err == nil || err == io.EOF):
+		r.finish(nil)
+	case err == io.EOF:
+		r.finish(io.ErrUnexpectedEOF)
+	case err != nil && r.ctx.Err() == nil:
+		r.finish(err)
+	}
+	return n, err
+}
+
+// Close implements io.Closer. Pieces closed before they're read to the end
+// aren't reported.
+func (r *reportingReader) Close() error {
+	r.mu.Lock()
+	r.done = true
+	r.mu.Unlock()
+	return r.ReadCloser.Close()
+}
+
+// finish reports the outcome of the read of the piece, unless it's done
+func (r *reportingReader) finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		return
+	}
+	r.done = true
+	r.report(PieceReport{NodeID: r.nodeID, Err: err})
+}
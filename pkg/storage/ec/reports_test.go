@@ -0,0 +1,82 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ecclient
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/piecestore/rpc/client"
+	"storj.io/storj/pkg/ranger"
+)
+
+// failingRanger fails its ranges with err
+type failingRanger struct {
+	ranger.Ranger
+	err error
+}
+
+func (rr *failingRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	return nil, rr.err
+}
+
+func TestPieceReports(t *testing.T) {
+	var reports []PieceReport
+	ctx := WithPieceReports(context.Background(), func(report PieceReport) {
+		reports = append(reports, report)
+	})
+
+	// pieces read to the end are served
+	rr := &reportingRanger{Ranger: ranger.ByteRanger("abcdef"), nodeID: "served"}
+	r, err := rr.Range(ctx, 1, 3)
+	if assert.NoError(t, err) {
+		data, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, "bcd", string(data))
+		assert.NoError(t, r.Close())
+	}
+
+	// pieces closed before they're read to the end aren't reported
+	r, err = rr.Range(ctx, 0, 6)
+	if assert.NoError(t, err) {
+		_, err = r.Read(make([]byte, 2))
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+	}
+
+	// failed ranges are reported, and nodes which can't be dialed as offline
+	rr = &reportingRanger{Ranger: &failingRanger{err: ErrOpFailed}, nodeID: "failed"}
+	_, err = rr.Range(ctx, 0, 1)
+	assert.Equal(t, ErrOpFailed, err)
+	rr = &reportingRanger{
+		Ranger: &lazyPieceRanger{dialer: &mockDialer{}, node: &pb.Node{Id: "offline"}, id: client.NewPieceID(),
+			size: 1, latencies: newLatencies()},
+		nodeID: "offline",
+	}
+	_, err = rr.Range(ctx, 0, 1)
+	assert.EqualError(t, err, dialFailed)
+
+	// canceled ranges aren't reported
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = rr.Range(canceled, 0, 1)
+	assert.Error(t, err)
+
+	assert.Equal(t, []PieceReport{
+		{NodeID: "served"},
+		{NodeID: "failed", Err: ErrOpFailed},
+		{NodeID: "offline", Offline: true, Err: dialError{ErrDialFailed}},
+	}, reports)
+
+	// ranges without a function to report to aren't reported
+	reports = nil
+	_, err = rr.Range(context.Background(), 0, 1)
+	assert.Error(t, err)
+	assert.Empty(t, reports)
+}
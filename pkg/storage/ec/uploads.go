@@ -0,0 +1,137 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ecclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// UploadLimiter limits the piece uploads of the clients sharing it, to a
+// rate of uploads started per second over all the nodes, and to a number of
+// concurrent uploads to each node, so many uploads at once, like those of
+// repairs, don't overwhelm the nodes they're uploaded to. A nil
+// UploadLimiter doesn't limit anything.
+type UploadLimiter struct {
+	interval   time.Duration
+	maxPerNode int
+
+	mu    sync.Mutex
+	next  time.Time
+	nodes map[string]*nodeUploads
+}
+
+type nodeUploads struct {
+	refs  int
+	slots chan struct{}
+}
+
+// NewUploadLimiter returns an UploadLimiter which starts at most rate
+// uploads per second and runs at most maxPerNode uploads to a node at once.
+// A rate or maxPerNode of 0 disables that limit.
+func NewUploadLimiter(rate float64, maxPerNode int) *UploadLimiter {
+	l := &UploadLimiter{
+		maxPerNode: maxPerNode,
+		nodes:      make(map[string]*nodeUploads),
+	}
+	if rate > 0 {
+		l.interval = time.Duration(float64(time.Second) / rate)
+	}
+	return l
+}
+
+// acquire waits until an upload to the node with the given id may start,
+// first for a free slot of the node and then for its turn among all the
+// uploads. The returned function frees the slot of the node again.
+func (l *UploadLimiter) acquire(ctx context.Context, nodeID string) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	n := l.node(nodeID)
+	if n.slots != nil {
+		select {
+		case n.slots <- struct{}{}:
+		case <-ctx.Done():
+			l.forget(nodeID, n)
+			return nil, ctx.Err()
+		}
+	}
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			if n.slots != nil {
+				<-n.slots
+			}
+			l.forget(nodeID, n)
+		})
+	}
+
+	if err := l.wait(ctx); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// wait waits for the turn of an upload, which are spaced evenly by the rate
+// of the limiter
+func (l *UploadLimiter) wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// the turn is lost, as later ones may have been given out already
+		return ctx.Err()
+	}
+}
+
+// node returns the uploads of the node with the given id, which have to be
+// forgotten once done with
+func (l *UploadLimiter) node(nodeID string) *nodeUploads {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n, ok := l.nodes[nodeID]
+	if !ok {
+		n = &nodeUploads{}
+		if l.maxPerNode > 0 {
+			n.slots = make(chan struct{}, l.maxPerNode)
+		}
+		l.nodes[nodeID] = n
+	}
+	n.refs++
+	return n
+}
+
+func (l *UploadLimiter) forget(nodeID string, n *nodeUploads) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n.refs--
+	if n.refs == 0 {
+		delete(l.nodes, nodeID)
+	}
+}
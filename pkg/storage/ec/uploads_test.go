@@ -0,0 +1,80 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ecclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadLimiter_PerNode(t *testing.T) {
+	ctx := context.Background()
+	l := NewUploadLimiter(0, 2)
+
+	tryAcquire := func(nodeID string) (func(), error) {
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		return l.acquire(ctx, nodeID)
+	}
+
+	release1, err := tryAcquire("a")
+	assert.NoError(t, err)
+	release2, err := tryAcquire("a")
+	assert.NoError(t, err)
+	_, err = tryAcquire("a")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// other nodes have slots of their own
+	release3, err := tryAcquire("b")
+	assert.NoError(t, err)
+
+	// releasing twice frees a single slot
+	release1()
+	release1()
+	release4, err := tryAcquire("a")
+	assert.NoError(t, err)
+	_, err = tryAcquire("a")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// the uploads of nodes without uploads running are forgotten
+	release2()
+	release3()
+	release4()
+	l.mu.Lock()
+	assert.Len(t, l.nodes, 0)
+	l.mu.Unlock()
+}
+
+func TestUploadLimiter_Rate(t *testing.T) {
+	ctx := context.Background()
+	l := NewUploadLimiter(100, 0)
+
+	// the uploads are spaced by 10ms, whichever nodes they're to
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		release, err := l.acquire(ctx, "a")
+		assert.NoError(t, err)
+		release()
+	}
+	assert.True(t, time.Since(start) >= 90*time.Millisecond)
+
+	// a canceled wait gives up its turn
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	l.mu.Lock()
+	l.next = time.Now().Add(time.Hour)
+	l.mu.Unlock()
+	_, err := l.acquire(canceled, "b")
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestUploadLimiter_Nil(t *testing.T) {
+	var l *UploadLimiter
+	release, err := l.acquire(context.Background(), "a")
+	assert.NoError(t, err)
+	release()
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package objects
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"storj.io/storj/pkg/storage/meta"
+	"storj.io/storj/pkg/storj"
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
+)
+
+// DeletePrefix deletes all the objects under prefix in o, deleting up to
+// parallelism objects at once. The objects are listed and deleted a page at
+// a time, so prefixes with many objects aren't listed in memory first. Each
+// object deleted, or failed to be, is reported to deleted, if it's set, with
+// its path relative to prefix. The objects which fail to be deleted don't
+// stop the others from being deleted; the number of the objects deleted and
+// the first error are returned.
+func DeletePrefix(ctx context.Context, o Store, prefix storj.Path, parallelism int,
+	deleted func(path storj.Path, err error)) (count int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	// the listed paths are relative to prefix
+	dir := strings.TrimSuffix(prefix, "/")
+	if dir != "" {
+		dir += "/"
+	}
+
+	var mu sync.Mutex
+	var firstErr error
+	startAfter := ""
+	for {
+		items, more, err := o.List(ctx, prefix, startAfter, "", true, 0, meta.None)
+		if err != nil {
+			return count, utils.CombineErrors(firstErr, err)
+		}
+
+		paths := make(chan storj.Path)
+		var wg sync.WaitGroup
+		for i := 0; i < parallelism; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for path := range paths {
+					err := o.Delete(ctx, dir+path)
+					// objects deleted since they were listed are gone anyway
+					if storage.ErrKeyNotFound.Has(err) {
+						err = nil
+					}
+
+					mu.Lock()
+					if err == nil {
+						count++
+					} else if firstErr == nil {
+						firstErr = err
+					}
+					if deleted != nil {
+						deleted(path, err)
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		for _, item := range items {
+			if ctx.Err() != nil {
+				break
+			}
+			paths <- item.Path
+		}
+		close(paths)
+		wg.Wait()
+
+		if ctx.Err() != nil {
+			return count, ctx.Err()
+		}
+		if !more || len(items) == 0 {
+			return count, firstErr
+		}
+		startAfter = items[len(items)-1].Path
+	}
+}
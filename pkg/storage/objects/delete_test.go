@@ -0,0 +1,89 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package objects
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/storj"
+	"storj.io/storj/storage"
+)
+
+// pagedObjects is a Store keeping just the paths of its objects, which are
+// listed a page of pageSize objects at a time
+type pagedObjects struct {
+	Store
+	pageSize int
+
+	mu    sync.Mutex
+	paths map[storj.Path]bool
+	lists int
+}
+
+func (o *pagedObjects) List(ctx context.Context, prefix, startAfter, endBefore storj.Path, recursive bool, limit int, metaFlags uint32) (items []ListItem, more bool, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.lists++
+
+	var paths []storj.Path
+	for path := range o.paths {
+		rel := strings.TrimPrefix(path, prefix+"/")
+		if rel != path && rel > startAfter {
+			paths = append(paths, rel)
+		}
+	}
+	sort.Strings(paths)
+	if len(paths) > o.pageSize {
+		paths, more = paths[:o.pageSize], true
+	}
+	for _, path := range paths {
+		items = append(items, ListItem{Path: path})
+	}
+	return items, more, nil
+}
+
+func (o *pagedObjects) Delete(ctx context.Context, path storj.Path) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.paths[path] {
+		return storage.ErrKeyNotFound.New("%s", path)
+	}
+	delete(o.paths, path)
+	return nil
+}
+
+func TestDeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	o := &pagedObjects{pageSize: 2, paths: map[storj.Path]bool{
+		"photos/1.jpg":      true,
+		"photos/2.jpg":      true,
+		"photos/2018/3.jpg": true,
+		"photos/2018/4.jpg": true,
+		"photos/5.jpg":      true,
+		"notes.txt":         true,
+	}}
+
+	var mu sync.Mutex
+	var deleted []storj.Path
+	count, err := DeletePrefix(ctx, o, "photos", 3, func(path storj.Path, err error) {
+		assert.NoError(t, err)
+		mu.Lock()
+		deleted = append(deleted, path)
+		mu.Unlock()
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count)
+
+	// the objects are deleted a page at a time, until the last page
+	assert.Equal(t, 3, o.lists)
+	sort.Strings(deleted)
+	assert.Equal(t, []storj.Path{"1.jpg", "2.jpg", "2018/3.jpg", "2018/4.jpg", "5.jpg"}, deleted)
+	assert.Equal(t, map[storj.Path]bool{"notes.txt": true}, o.paths)
+}
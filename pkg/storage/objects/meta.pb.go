@@ -23,18 +23,26 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 // SerializableMeta is the object metadata that will be stored serialized
 type SerializableMeta struct {
-	ContentType          string            `protobuf:"bytes,1,opt,name=ContentType,proto3" json:"ContentType,omitempty"`
-	UserDefined          map[string]string `protobuf:"bytes,2,rep,name=UserDefined,proto3" json:"UserDefined,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	ContentType string            `protobuf:"bytes,1,opt,name=ContentType,proto3" json:"ContentType,omitempty"`
+	UserDefined map[string]string `protobuf:"bytes,2,rep,name=UserDefined,proto3" json:"UserDefined,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Redundancy is the Reed-Solomon scheme of the objects of a bucket, in
+	// the metadata of buckets. The objects of buckets without one are
+	// uploaded with the scheme configured by the uplink.
+	Redundancy *RedundancyScheme `protobuf:"bytes,3,opt,name=Redundancy,proto3" json:"Redundancy,omitempty"`
+	// SegmentSize is the size of the segments of the objects of a bucket, in
+	// the metadata of buckets. The objects of buckets without one are
+	// uploaded with the segment size configured by the uplink.
+	SegmentSize          int64    `protobuf:"varint,4,opt,name=SegmentSize,proto3" json:"SegmentSize,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *SerializableMeta) Reset()         { *m = SerializableMeta{} }
 func (m *SerializableMeta) String() string { return proto.CompactTextString(m) }
 func (*SerializableMeta) ProtoMessage()    {}
 func (*SerializableMeta) Descriptor() ([]byte, []int) {
-	return fileDescriptor_meta_5a7fa61f937f80b3, []int{0}
+	return fileDescriptor_3b5ea8fe65782bcc, []int{0}
 }
 func (m *SerializableMeta) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SerializableMeta.Unmarshal(m, b)
@@ -68,24 +76,118 @@ func (m *SerializableMeta) GetUserDefined() map[string]string {
 	return nil
 }
 
+func (m *SerializableMeta) GetRedundancy() *RedundancyScheme {
+	if m != nil {
+		return m.Redundancy
+	}
+	return nil
+}
+
+func (m *SerializableMeta) GetSegmentSize() int64 {
+	if m != nil {
+		return m.SegmentSize
+	}
+	return 0
+}
+
+// RedundancyScheme are the Reed-Solomon parameters of a bucket
+type RedundancyScheme struct {
+	ShareSize            int64    `protobuf:"varint,1,opt,name=ShareSize,proto3" json:"ShareSize,omitempty"`
+	RequiredShares       int32    `protobuf:"varint,2,opt,name=RequiredShares,proto3" json:"RequiredShares,omitempty"`
+	RepairShares         int32    `protobuf:"varint,3,opt,name=RepairShares,proto3" json:"RepairShares,omitempty"`
+	OptimalShares        int32    `protobuf:"varint,4,opt,name=OptimalShares,proto3" json:"OptimalShares,omitempty"`
+	TotalShares          int32    `protobuf:"varint,5,opt,name=TotalShares,proto3" json:"TotalShares,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RedundancyScheme) Reset()         { *m = RedundancyScheme{} }
+func (m *RedundancyScheme) String() string { return proto.CompactTextString(m) }
+func (*RedundancyScheme) ProtoMessage()    {}
+func (*RedundancyScheme) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3b5ea8fe65782bcc, []int{1}
+}
+func (m *RedundancyScheme) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RedundancyScheme.Unmarshal(m, b)
+}
+func (m *RedundancyScheme) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RedundancyScheme.Marshal(b, m, deterministic)
+}
+func (dst *RedundancyScheme) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RedundancyScheme.Merge(dst, src)
+}
+func (m *RedundancyScheme) XXX_Size() int {
+	return xxx_messageInfo_RedundancyScheme.Size(m)
+}
+func (m *RedundancyScheme) XXX_DiscardUnknown() {
+	xxx_messageInfo_RedundancyScheme.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RedundancyScheme proto.InternalMessageInfo
+
+func (m *RedundancyScheme) GetShareSize() int64 {
+	if m != nil {
+		return m.ShareSize
+	}
+	return 0
+}
+
+func (m *RedundancyScheme) GetRequiredShares() int32 {
+	if m != nil {
+		return m.RequiredShares
+	}
+	return 0
+}
+
+func (m *RedundancyScheme) GetRepairShares() int32 {
+	if m != nil {
+		return m.RepairShares
+	}
+	return 0
+}
+
+func (m *RedundancyScheme) GetOptimalShares() int32 {
+	if m != nil {
+		return m.OptimalShares
+	}
+	return 0
+}
+
+func (m *RedundancyScheme) GetTotalShares() int32 {
+	if m != nil {
+		return m.TotalShares
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*SerializableMeta)(nil), "objects.SerializableMeta")
 	proto.RegisterMapType((map[string]string)(nil), "objects.SerializableMeta.UserDefinedEntry")
+	proto.RegisterType((*RedundancyScheme)(nil), "objects.RedundancyScheme")
 }
 
-func init() { proto.RegisterFile("meta.proto", fileDescriptor_meta_5a7fa61f937f80b3) }
-
-var fileDescriptor_meta_5a7fa61f937f80b3 = []byte{
-	// 172 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xca, 0x4d, 0x2d, 0x49,
-	0xd4, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0xcf, 0x4f, 0xca, 0x4a, 0x4d, 0x2e, 0x29, 0x56,
-	0x3a, 0xc4, 0xc8, 0x25, 0x10, 0x9c, 0x5a, 0x94, 0x99, 0x98, 0x93, 0x59, 0x95, 0x98, 0x94, 0x93,
-	0xea, 0x9b, 0x5a, 0x92, 0x28, 0xa4, 0xc0, 0xc5, 0xed, 0x9c, 0x9f, 0x57, 0x92, 0x9a, 0x57, 0x12,
-	0x52, 0x59, 0x90, 0x2a, 0xc1, 0xa8, 0xc0, 0xa8, 0xc1, 0x19, 0x84, 0x2c, 0x24, 0xe4, 0xc3, 0xc5,
-	0x1d, 0x5a, 0x9c, 0x5a, 0xe4, 0x92, 0x9a, 0x96, 0x99, 0x97, 0x9a, 0x22, 0xc1, 0xa4, 0xc0, 0xac,
-	0xc1, 0x6d, 0xa4, 0xa5, 0x07, 0x35, 0x55, 0x0f, 0xdd, 0x44, 0x3d, 0x24, 0xc5, 0xae, 0x79, 0x25,
-	0x45, 0x95, 0x41, 0xc8, 0xda, 0xa5, 0xec, 0xb8, 0x04, 0xd0, 0x15, 0x08, 0x09, 0x70, 0x31, 0x67,
-	0xa7, 0x56, 0x42, 0xed, 0x06, 0x31, 0x85, 0x44, 0xb8, 0x58, 0xcb, 0x12, 0x73, 0x4a, 0x53, 0x25,
-	0x98, 0xc0, 0x62, 0x10, 0x8e, 0x15, 0x93, 0x05, 0x63, 0x12, 0x1b, 0xd8, 0x53, 0xc6, 0x80, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0x65, 0x5b, 0x93, 0x65, 0xe2, 0x00, 0x00, 0x00,
+func init() { proto.RegisterFile("meta.proto", fileDescriptor_3b5ea8fe65782bcc) }
+
+var fileDescriptor_3b5ea8fe65782bcc = []byte{
+	// 299 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x91, 0xc1, 0x4e, 0x83, 0x30,
+	0x18, 0xc7, 0x53, 0xd8, 0x34, 0xfb, 0x50, 0x43, 0x1a, 0x0f, 0x68, 0x3c, 0x90, 0xc5, 0x18, 0xe2,
+	0x81, 0xc3, 0xbc, 0xa8, 0x07, 0x2f, 0xea, 0x4d, 0x63, 0x52, 0xe6, 0x03, 0x14, 0xf8, 0x74, 0x55,
+	0x28, 0x58, 0x8a, 0x09, 0x7b, 0x19, 0x5f, 0xc6, 0x07, 0x33, 0x54, 0xb2, 0x75, 0xdc, 0xe0, 0xd7,
+	0x5f, 0xff, 0xff, 0xaf, 0x2d, 0x40, 0x89, 0x9a, 0xc7, 0xb5, 0xaa, 0x74, 0x45, 0xf7, 0xab, 0xf4,
+	0x03, 0x33, 0xdd, 0xcc, 0x7f, 0x1c, 0xf0, 0x13, 0x54, 0x82, 0x17, 0x62, 0xcd, 0xd3, 0x02, 0x9f,
+	0x51, 0x73, 0x1a, 0x82, 0x77, 0x5f, 0x49, 0x8d, 0x52, 0x2f, 0xbb, 0x1a, 0x03, 0x12, 0x92, 0x68,
+	0xc6, 0x6c, 0x44, 0x9f, 0xc0, 0x7b, 0x6d, 0x50, 0x3d, 0xe0, 0x9b, 0x90, 0x98, 0x07, 0x4e, 0xe8,
+	0x46, 0xde, 0xe2, 0x32, 0x1e, 0x52, 0xe3, 0x71, 0x62, 0x6c, 0xc9, 0x8f, 0x52, 0xab, 0x8e, 0xd9,
+	0xdb, 0xe9, 0x0d, 0x00, 0xc3, 0xbc, 0x95, 0x39, 0x97, 0x59, 0x17, 0xb8, 0x21, 0x89, 0xbc, 0xc5,
+	0xc9, 0x26, 0x6c, 0xbb, 0x94, 0x64, 0x2b, 0x2c, 0x91, 0x59, 0x72, 0x3f, 0x6a, 0x82, 0xef, 0x25,
+	0x4a, 0x9d, 0x88, 0x35, 0x06, 0x93, 0x90, 0x44, 0x2e, 0xb3, 0xd1, 0xe9, 0x1d, 0xf8, 0xe3, 0x76,
+	0xea, 0x83, 0xfb, 0x89, 0xdd, 0x70, 0xb0, 0xfe, 0x93, 0x1e, 0xc3, 0xf4, 0x9b, 0x17, 0x2d, 0x06,
+	0x8e, 0x61, 0xff, 0x3f, 0xb7, 0xce, 0x35, 0x99, 0xff, 0x12, 0xf0, 0xc7, 0x23, 0xd0, 0x33, 0x98,
+	0x25, 0x2b, 0xae, 0xd0, 0x94, 0x12, 0x53, 0xba, 0x05, 0xf4, 0x02, 0x8e, 0x18, 0x7e, 0xb5, 0x42,
+	0x61, 0x6e, 0x60, 0x63, 0x52, 0xa7, 0x6c, 0x44, 0xe9, 0x1c, 0x0e, 0x18, 0xd6, 0x5c, 0xa8, 0xc1,
+	0x72, 0x8d, 0xb5, 0xc3, 0xe8, 0x39, 0x1c, 0xbe, 0xd4, 0x5a, 0x94, 0xbc, 0x18, 0xa4, 0x89, 0x91,
+	0x76, 0x61, 0x7f, 0x0d, 0xcb, 0x4a, 0x6f, 0x9c, 0xa9, 0x71, 0x6c, 0x94, 0xee, 0x99, 0x87, 0xbf,
+	0xfa, 0x1b, 0x00, 0xc7, 0x38, 0xfd, 0x04, 0x06, 0x02, 0x00, 0x00,
 }
@@ -6,6 +6,8 @@ package objects
 import (
 	"context"
 	"io"
+	"mime"
+	"path/filepath"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -45,6 +47,7 @@ type Store interface {
 	Get(ctx context.Context, path storj.Path) (rr ranger.Ranger, meta Meta, err error)
 	Put(ctx context.Context, path storj.Path, data io.Reader, metadata SerializableMeta, expiration time.Time) (meta Meta, err error)
 	Delete(ctx context.Context, path storj.Path) (err error)
+	Copy(ctx context.Context, src, dst storj.Path) (meta Meta, err error)
 	List(ctx context.Context, prefix, startAfter, endBefore storj.Path, recursive bool, limit int, metaFlags uint32) (items []ListItem, more bool, err error)
 }
 
@@ -87,8 +90,10 @@ func (o *objStore) Put(ctx context.Context, path storj.Path, data io.Reader, met
 		return Meta{}, NoPathError.New("")
 	}
 
-	// TODO(kaloyan): autodetect content type
-	// if metadata.GetContentType() == "" {}
+	// objects without a content type get the one of their extension, if any
+	if metadata.GetContentType() == "" {
+		metadata.ContentType = mime.TypeByExtension(filepath.Ext(path))
+	}
 
 	b, err := proto.Marshal(&metadata)
 	if err != nil {
@@ -108,6 +113,19 @@ func (o *objStore) Delete(ctx context.Context, path storj.Path) (err error) {
 	return o.s.Delete(ctx, path)
 }
 
+// Copy copies the object at src to dst with its metadata, without copying
+// its data, which is shared by the copies
+func (o *objStore) Copy(ctx context.Context, src, dst storj.Path) (meta Meta, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if len(src) == 0 || len(dst) == 0 {
+		return Meta{}, NoPathError.New("")
+	}
+
+	m, err := o.s.Copy(ctx, src, dst)
+	return convertMeta(m), err
+}
+
 func (o *objStore) List(ctx context.Context, prefix, startAfter, endBefore storj.Path, recursive bool, limit int, metaFlags uint32) (
 	items []ListItem, more bool, err error) {
 	defer mon.Task()(&ctx)(&err)
@@ -0,0 +1,131 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package segments
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+
+	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/piecestore/rpc/client"
+)
+
+// ReceiptsRoot returns the root of the Merkle tree of the pieces, with their
+// receipts, in the order of the piece numbers. It's kept in the pointer of a
+// segment as the proof of what its nodes committed to store at upload time.
+func ReceiptsRoot(pieces []*pb.RemotePiece) ([]byte, error) {
+	sorted := append([]*pb.RemotePiece(nil), pieces...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GetPieceNum() < sorted[j].GetPieceNum()
+	})
+
+	var hashes [][]byte
+	for _, p := range sorted {
+		data, err := proto.Marshal(p)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		hashes = append(hashes, merkleHash(0, data))
+	}
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	// the odd hash of a level is carried up to the next one
+	for len(hashes) > 1 {
+		var level [][]byte
+		for i := 0; i < len(hashes); i += 2 {
+			if i+1 == len(hashes) {
+				level = append(level, hashes[i])
+				continue
+			}
+			level = append(level, merkleHash(1, hashes[i], hashes[i+1]))
+		}
+		hashes = level
+	}
+	return hashes[0], nil
+}
+
+// merkleHash returns the hash of data, prefixed with the byte telling leaves
+// and inner nodes of the tree apart
+func merkleHash(prefix byte, data ...[]byte) []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte{prefix})
+	for _, d := range data {
+		_, _ = h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// VerifyReceipts checks that the receipts of the pieces of seg match its
+// Merkle root, and that each of them was signed by the node of its piece for
+// the piece ID derived for the node. The nodes are indexed by the piece
+// number. Pieces stored without a receipt are left out.
+func VerifyReceipts(seg *pb.RemoteSegment, nodes []*pb.Node) error {
+	if err := VerifyReceiptsRoot(seg); err != nil {
+		return err
+	}
+	for _, p := range seg.GetRemotePieces() {
+		if p.GetReceipt() == nil {
+			continue
+		}
+		num := int(p.GetPieceNum())
+		if num >= len(nodes) || nodes[num] == nil {
+			return Error.New("no node %s for the receipt of piece %d", p.GetNodeId(), num)
+		}
+		if err := VerifyReceipt(seg, p, nodes[num]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyReceiptsRoot checks that the pieces of seg, with their receipts,
+// match its Merkle root
+func VerifyReceiptsRoot(seg *pb.RemoteSegment) error {
+	if len(seg.GetMerkleRoot()) == 0 {
+		return Error.New("segment stored without receipts")
+	}
+	root, err := ReceiptsRoot(seg.GetRemotePieces())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(root, seg.GetMerkleRoot()) {
+		return Error.New("receipts don't match the merkle root of the segment")
+	}
+	return nil
+}
+
+// VerifyReceipt checks that the receipt of the piece p of seg was signed by
+// n, the node of the piece, for the piece ID derived for it
+func VerifyReceipt(seg *pb.RemoteSegment, p *pb.RemotePiece, n *pb.Node) error {
+	num := p.GetPieceNum()
+	receipt := p.GetReceipt()
+	if receipt == nil {
+		return Error.New("piece %d stored without a receipt", num)
+	}
+	if n.GetId() != p.GetNodeId() {
+		return Error.New("no node %s for the receipt of piece %d", p.GetNodeId(), num)
+	}
+	if err := node.VerifyPieceHash(n, receipt); err != nil {
+		return Error.Wrap(err)
+	}
+
+	data := &pb.PieceHash_Data{}
+	if err := proto.Unmarshal(receipt.GetData(), data); err != nil {
+		return Error.Wrap(err)
+	}
+	derivedPieceID, err := client.PieceID(seg.GetPieceId()).Derive([]byte(p.GetNodeId()))
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	if data.GetPieceId() != derivedPieceID.String() {
+		return Error.New("receipt of piece %d is for piece ID %s", num, data.GetPieceId())
+	}
+	return nil
+}
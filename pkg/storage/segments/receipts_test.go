@@ -0,0 +1,116 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package segments
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/peertls"
+	"storj.io/storj/pkg/piecestore/rpc/client"
+	"storj.io/storj/pkg/provider"
+)
+
+func TestReceiptsRoot(t *testing.T) {
+	root, err := ReceiptsRoot(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, root)
+
+	pieces := []*pb.RemotePiece{
+		{PieceNum: 0, NodeId: "node-0", Receipt: &pb.PieceHash{Signature: []byte("sig0")}},
+		{PieceNum: 1, NodeId: "node-1", Receipt: &pb.PieceHash{Signature: []byte("sig1")}},
+		{PieceNum: 3, NodeId: "node-3", Receipt: &pb.PieceHash{Signature: []byte("sig3")}},
+	}
+	root, err = ReceiptsRoot(pieces)
+	assert.NoError(t, err)
+	assert.Len(t, root, 32)
+
+	// the root doesn't depend on the order of the pieces
+	reordered, err := ReceiptsRoot([]*pb.RemotePiece{pieces[2], pieces[0], pieces[1]})
+	assert.NoError(t, err)
+	assert.Equal(t, root, reordered)
+
+	// but on every receipt
+	tampered := *pieces[2]
+	tampered.Receipt = &pb.PieceHash{Signature: []byte("forged")}
+	forged, err := ReceiptsRoot([]*pb.RemotePiece{pieces[0], pieces[1], &tampered})
+	assert.NoError(t, err)
+	assert.NotEqual(t, root, forged)
+}
+
+func TestVerifyReceipts(t *testing.T) {
+	pieceID := client.NewPieceID()
+
+	var nodes []*pb.Node
+	var pieces []*pb.RemotePiece
+	for i := 0; i < 3; i++ {
+		ca, err := provider.NewTestCA(context.Background())
+		if !assert.NoError(t, err) {
+			return
+		}
+		identity, err := ca.NewIdentity()
+		if !assert.NoError(t, err) {
+			return
+		}
+		n := &pb.Node{Id: identity.ID.String()}
+		if !assert.NoError(t, node.SignNode(n, identity)) {
+			return
+		}
+		nodes = append(nodes, n)
+
+		derivedPieceID, err := pieceID.Derive([]byte(n.GetId()))
+		if !assert.NoError(t, err) {
+			return
+		}
+		data, err := proto.Marshal(&pb.PieceHash_Data{PieceId: derivedPieceID.String(), Hash: []byte("hash"), Size: 10})
+		if !assert.NoError(t, err) {
+			return
+		}
+		signature, err := peertls.SignMessage(identity.Key, data)
+		if !assert.NoError(t, err) {
+			return
+		}
+		pieces = append(pieces, &pb.RemotePiece{
+			PieceNum: int32(i),
+			NodeId:   n.GetId(),
+			Receipt:  &pb.PieceHash{Data: data, Signature: signature},
+		})
+	}
+
+	segment := func() *pb.RemoteSegment {
+		seg := &pb.RemoteSegment{PieceId: string(pieceID)}
+		for _, p := range pieces {
+			seg.RemotePieces = append(seg.RemotePieces, proto.Clone(p).(*pb.RemotePiece))
+		}
+		root, err := ReceiptsRoot(seg.RemotePieces)
+		assert.NoError(t, err)
+		seg.MerkleRoot = root
+		return seg
+	}
+
+	assert.NoError(t, VerifyReceipts(segment(), nodes))
+
+	for i, tamper := range []func(seg *pb.RemoteSegment){
+		func(seg *pb.RemoteSegment) { seg.MerkleRoot = nil },
+		func(seg *pb.RemoteSegment) { seg.RemotePieces[1].Hash = []byte("forged") },
+		func(seg *pb.RemoteSegment) { seg.PieceId = string(client.NewPieceID()) },
+		func(seg *pb.RemoteSegment) {
+			// a receipt of another node is rejected even with a matching root
+			seg.RemotePieces[0].Receipt = seg.RemotePieces[1].Receipt
+			seg.MerkleRoot, _ = ReceiptsRoot(seg.RemotePieces)
+		},
+	} {
+		seg := segment()
+		tamper(seg)
+		assert.Error(t, VerifyReceipts(seg, nodes), "case %d", i)
+	}
+
+	// the node of each receipt is needed to verify it
+	assert.Error(t, VerifyReceipts(segment(), []*pb.Node{nodes[0], nil, nodes[2]}))
+}
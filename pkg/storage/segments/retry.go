@@ -0,0 +1,151 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package segments
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/ranger"
+	"storj.io/storj/pkg/storj"
+)
+
+// retryRanger is the ranger of a remote segment whose reads are retried when
+// they fail, continuing where they stopped. Before retrying, the pointer of
+// the segment is got again, so pieces repaired to other nodes are read, and
+// the pieces are downloaded from the fastest nodes again, where the nodes
+// which failed are the slowest.
+type retryRanger struct {
+	ranger.Ranger
+	s       *segmentStore
+	path    storj.Path
+	pointer *pb.Pointer
+
+	mu sync.Mutex
+}
+
+// Range implements Ranger.Range
+func (rr *retryRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	r := &retryReader{ctx: ctx, rr: rr, offset: offset, remaining: length, retries: rr.s.retries}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// current returns the ranger the segment is read from
+func (rr *retryRanger) current() ranger.Ranger {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	return rr.Ranger
+}
+
+// refresh gets the pointer of the segment again and replaces the ranger the
+// segment is read from. The segment must not have been replaced since it was
+// got.
+func (rr *retryRanger) refresh(ctx context.Context) error {
+	pr, err := rr.s.pdb.Get(ctx, rr.path)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	if pr.GetType() != pb.Pointer_REMOTE ||
+		pr.GetRemote().GetPieceId() != rr.pointer.GetRemote().GetPieceId() ||
+		pr.GetSize() != rr.pointer.GetSize() {
+		return Error.New("segment %s was replaced while it was read", rr.path)
+	}
+	remote, err := rr.s.remoteRanger(ctx, pr)
+	if err != nil {
+		return err
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.Ranger = remote
+	return nil
+}
+
+// retryReader reads a range of a retryRanger, opening the rest of the range
+// again when a read fails, as long as it has retries left
+type retryReader struct {
+	ctx       context.Context
+	rr        *retryRanger
+	offset    int64
+	remaining int64
+	retries   int
+	r         io.ReadCloser
+	// err is the error of the read which wasn't retried
+	err error
+}
+
+// open opens the rest of the range
+func (r *retryReader) open() error {
+	for {
+		rc, err := r.rr.current().Range(r.ctx, r.offset, r.remaining)
+		if err == nil {
+			r.r = rc
+			return nil
+		}
+		if !r.retry(err) {
+			return err
+		}
+	}
+}
+
+// retry returns whether the read which failed with err is retried
+func (r *retryReader) retry(err error) bool {
+	if r.retries <= 0 || r.ctx.Err() != nil {
+		return false
+	}
+	r.retries--
+	mon.Event("segment_read_retried")
+	zap.S().Warnf("Retrying the read of segment %s at offset %d: %v", r.rr.path, r.offset, err)
+
+	if err := r.rr.refresh(r.ctx); err != nil {
+		zap.S().Warnf("Failed getting segment %s again: %v", r.rr.path, err)
+		return false
+	}
+	return true
+}
+
+// Read implements io.Reader
+func (r *retryReader) Read(p []byte) (n int, err error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.r == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err = r.r.Read(p)
+	r.offset += int64(n)
+	r.remaining -= int64(n)
+	if err == nil || err == io.EOF {
+		return n, err
+	}
+
+	// the reader is opened again at the next read if the read is retried
+	closeErr := r.r.Close()
+	if closeErr != nil {
+		zap.S().Debugf("Failed closing the failed read of segment %s: %v", r.rr.path, closeErr)
+	}
+	r.r = nil
+	if !r.retry(err) {
+		r.err = err
+		return n, err
+	}
+	return n, nil
+}
+
+// Close implements io.Closer
+func (r *retryReader) Close() error {
+	if r.r == nil {
+		return nil
+	}
+	return r.r.Close()
+}
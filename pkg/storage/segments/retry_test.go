@@ -0,0 +1,120 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package segments
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/eestream"
+	mock_eestream "storj.io/storj/pkg/eestream/mocks"
+	mock_overlay "storj.io/storj/pkg/overlay/mocks"
+	"storj.io/storj/pkg/pb"
+	mock_pointerdb "storj.io/storj/pkg/pointerdb/pdbclient/mocks"
+	"storj.io/storj/pkg/ranger"
+	mock_ecclient "storj.io/storj/pkg/storage/ec/mocks"
+)
+
+// failingRanger is a ranger whose ranges fail after failAfter bytes are read
+type failingRanger struct {
+	ranger.Ranger
+	failAfter int64
+}
+
+func (rr *failingRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	r, err := rr.Ranger.Range(ctx, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	limit := rr.failAfter - offset
+	if limit < 0 {
+		limit = 0
+	}
+	return ioutil.NopCloser(io.MultiReader(io.LimitReader(r, limit), failingReader{})), nil
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errs.New("piece download failed")
+}
+
+func TestSegmentStoreGetRetries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	data := []byte("0123456789")
+	pointer := &pb.Pointer{
+		Type: pb.Pointer_REMOTE,
+		Remote: &pb.RemoteSegment{
+			Redundancy: &pb.RedundancyScheme{
+				Type:             pb.RedundancyScheme_RS,
+				MinReq:           1,
+				Total:            2,
+				RepairThreshold:  1,
+				SuccessThreshold: 2,
+			},
+			PieceId: "here's my piece id",
+		},
+		Size: int64(len(data)),
+	}
+
+	for i, tt := range []struct {
+		retries    int
+		rangers    []ranger.Ranger
+		downloaded []byte
+		err        bool
+	}{
+		{0, []ranger.Ranger{&failingRanger{ranger.ByteRanger(data), 4}}, data[:4], true},
+		{1, []ranger.Ranger{&failingRanger{ranger.ByteRanger(data), 4}, ranger.ByteRanger(data)}, data, false},
+		{2, []ranger.Ranger{
+			&failingRanger{ranger.ByteRanger(data), 4},
+			&failingRanger{ranger.ByteRanger(data), 7},
+			ranger.ByteRanger(data),
+		}, data, false},
+		{1, []ranger.Ranger{
+			&failingRanger{ranger.ByteRanger(data), 4},
+			&failingRanger{ranger.ByteRanger(data), 7},
+		}, data[:7], true},
+	} {
+		mockOC := mock_overlay.NewMockClient(ctrl)
+		mockEC := mock_ecclient.NewMockClient(ctrl)
+		mockPDB := mock_pointerdb.NewMockClient(ctrl)
+		rs := eestream.RedundancyStrategy{
+			ErasureScheme: mock_eestream.NewMockErasureScheme(ctrl),
+		}
+		ss := segmentStore{mockOC, mockEC, mockPDB, rs, 10, tt.retries}
+
+		// the pointer is got again for each retry
+		for _, rr := range tt.rangers {
+			mockPDB.EXPECT().Get(gomock.Any(), "path").Return(pointer, nil)
+			mockOC.EXPECT().BulkLookup(gomock.Any(), gomock.Any())
+			mockPDB.EXPECT().SignedMessage()
+			mockPDB.EXPECT().PayerBandwidthAllocation()
+			mockEC.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+				gomock.Any(), gomock.Any(), gomock.Any()).Return(rr, nil)
+		}
+
+		rr, _, err := ss.Get(ctx, "path")
+		if !assert.NoError(t, err, i) {
+			continue
+		}
+		r, err := rr.Range(ctx, 0, rr.Size())
+		if !assert.NoError(t, err, i) {
+			continue
+		}
+		var downloaded bytes.Buffer
+		_, err = io.Copy(&downloaded, r)
+		assert.Equal(t, tt.err, err != nil, i)
+		assert.Equal(t, tt.downloaded, downloaded.Bytes(), i)
+		assert.NoError(t, r.Close(), i)
+	}
+}
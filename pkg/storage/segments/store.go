@@ -10,7 +10,6 @@ import (
 
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/timestamp"
-	"github.com/vivint/infectious"
 	"go.uber.org/zap"
 	monkit "gopkg.in/spacemonkeygo/monkit.v2"
 
@@ -24,7 +23,6 @@ import (
 	"storj.io/storj/pkg/ranger"
 	ecclient "storj.io/storj/pkg/storage/ec"
 	"storj.io/storj/pkg/storj"
-	"storj.io/storj/pkg/utils"
 )
 
 var (
@@ -53,6 +51,7 @@ type Store interface {
 	Repair(ctx context.Context, path storj.Path, lostPieces []int) (err error)
 	Put(ctx context.Context, data io.Reader, expiration time.Time, segmentInfo func() (storj.Path, []byte, error)) (meta Meta, err error)
 	Delete(ctx context.Context, path storj.Path) (err error)
+	Copy(ctx context.Context, src, dst storj.Path, metadata []byte) (err error)
 	List(ctx context.Context, prefix, startAfter, endBefore storj.Path, recursive bool, limit int, metaFlags uint32) (items []ListItem, more bool, err error)
 }
 
@@ -62,12 +61,31 @@ type segmentStore struct {
 	pdb           pdbclient.Client
 	rs            eestream.RedundancyStrategy
 	thresholdSize int
+	retries       int
 }
 
-// NewSegmentStore creates a new instance of segmentStore
+// NewSegmentStore creates a new instance of segmentStore. The reads of remote
+// segments which fail are retried up to retries times, from the pieces of
+// the pointer of the segment got again.
 func NewSegmentStore(oc overlay.Client, ec ecclient.Client,
-	pdb pdbclient.Client, rs eestream.RedundancyStrategy, t int) Store {
-	return &segmentStore{oc: oc, ec: ec, pdb: pdb, rs: rs, thresholdSize: t}
+	pdb pdbclient.Client, rs eestream.RedundancyStrategy, t int, retries int) Store {
+	return &segmentStore{oc: oc, ec: ec, pdb: pdb, rs: rs, thresholdSize: t, retries: retries}
+}
+
+type redundancyCtxKey struct{}
+
+// WithRedundancy returns a context with which segments are put with the
+// redundancy strategy rs, instead of the one of the store
+func WithRedundancy(ctx context.Context, rs eestream.RedundancyStrategy) context.Context {
+	return context.WithValue(ctx, redundancyCtxKey{}, rs)
+}
+
+// redundancy returns the redundancy strategy to put segments with in ctx
+func (s *segmentStore) redundancy(ctx context.Context) eestream.RedundancyStrategy {
+	if rs, ok := ctx.Value(redundancyCtxKey{}).(eestream.RedundancyStrategy); ok {
+		return rs
+	}
+	return s.rs
 }
 
 // Meta retrieves the metadata of the segment
@@ -114,8 +132,10 @@ func (s *segmentStore) Put(ctx context.Context, data io.Reader, expiration time.
 			Metadata:       metadata,
 		}
 	} else {
+		rs := s.redundancy(ctx)
+
 		// uses overlay client to request a list of nodes
-		nodes, err := s.oc.Choose(ctx, overlay.Options{Amount: s.rs.TotalCount(), Space: 0, Excluded: nil})
+		nodes, err := s.oc.Choose(ctx, overlay.Options{Amount: rs.TotalCount(), Space: 0, Excluded: nil})
 		if err != nil {
 			return Meta{}, Error.Wrap(err)
 		}
@@ -128,7 +148,7 @@ func (s *segmentStore) Put(ctx context.Context, data io.Reader, expiration time.
 		}
 		pba := s.pdb.PayerBandwidthAllocation()
 		// puts file to ecclient
-		successfulNodes, err := s.ec.Put(ctx, nodes, s.rs, pieceID, sizedReader, expiration, pba, signedMessage)
+		successfulNodes, hashes, receipts, err := s.ec.Put(ctx, nodes, rs, pieceID, sizedReader, expiration, pba, signedMessage)
 		if err != nil {
 			return Meta{}, Error.Wrap(err)
 		}
@@ -139,7 +159,7 @@ func (s *segmentStore) Put(ctx context.Context, data io.Reader, expiration time.
 		}
 		path = p
 
-		pointer, err = s.makeRemotePointer(successfulNodes, pieceID, sizedReader.Size(), exp, metadata)
+		pointer, err = makeRemotePointer(rs, successfulNodes, hashes, receipts, pieceID, sizedReader.Size(), exp, metadata)
 		if err != nil {
 			return Meta{}, err
 		}
@@ -159,33 +179,48 @@ func (s *segmentStore) Put(ctx context.Context, data io.Reader, expiration time.
 	return m, nil
 }
 
-// makeRemotePointer creates a pointer of type remote
-func (s *segmentStore) makeRemotePointer(nodes []*pb.Node, pieceID client.PieceID, readerSize int64,
+// makeRemotePointer creates a pointer of type remote, of a segment stored
+// with the redundancy strategy rs, and the hashes and receipts of the pieces
+// on nodes. The root of the receipts is kept as the proof of the segment.
+func makeRemotePointer(rs eestream.RedundancyStrategy, nodes []*pb.Node, hashes [][]byte, receipts []*pb.PieceHash, pieceID client.PieceID, readerSize int64,
 	exp *timestamp.Timestamp, metadata []byte) (pointer *pb.Pointer, err error) {
 	var remotePieces []*pb.RemotePiece
 	for i := range nodes {
 		if nodes[i] == nil {
 			continue
 		}
-		remotePieces = append(remotePieces, &pb.RemotePiece{
+		piece := &pb.RemotePiece{
 			PieceNum: int32(i),
 			NodeId:   nodes[i].Id,
-		})
+		}
+		if i < len(hashes) {
+			piece.Hash = hashes[i]
+		}
+		if i < len(receipts) {
+			piece.Receipt = receipts[i]
+		}
+		remotePieces = append(remotePieces, piece)
+	}
+
+	root, err := ReceiptsRoot(remotePieces)
+	if err != nil {
+		return nil, err
 	}
 
 	pointer = &pb.Pointer{
 		Type: pb.Pointer_REMOTE,
 		Remote: &pb.RemoteSegment{
 			Redundancy: &pb.RedundancyScheme{
-				Type:             pb.RedundancyScheme_RS,
-				MinReq:           int32(s.rs.RequiredCount()),
-				Total:            int32(s.rs.TotalCount()),
-				RepairThreshold:  int32(s.rs.RepairThreshold()),
-				SuccessThreshold: int32(s.rs.OptimalThreshold()),
-				ErasureShareSize: int32(s.rs.ErasureShareSize()),
+				Type:             schemeType(rs),
+				MinReq:           int32(rs.RequiredCount()),
+				Total:            int32(rs.TotalCount()),
+				RepairThreshold:  int32(rs.RepairThreshold()),
+				SuccessThreshold: int32(rs.OptimalThreshold()),
+				ErasureShareSize: int32(rs.ErasureShareSize()),
 			},
 			PieceId:      string(pieceID),
 			RemotePieces: remotePieces,
+			MerkleRoot:   root,
 		},
 		Size:           readerSize,
 		ExpirationDate: exp,
@@ -205,26 +240,12 @@ func (s *segmentStore) Get(ctx context.Context, path storj.Path) (
 	}
 
 	if pr.GetType() == pb.Pointer_REMOTE {
-		seg := pr.GetRemote()
-		pid := client.PieceID(seg.GetPieceId())
-		nodes, err := s.lookupNodes(ctx, seg)
-		if err != nil {
-			return nil, Meta{}, Error.Wrap(err)
-		}
-
-		es, err := makeErasureScheme(pr.GetRemote().GetRedundancy())
+		rr, err = s.remoteRanger(ctx, pr)
 		if err != nil {
 			return nil, Meta{}, err
 		}
-
-		signedMessage, err := s.pdb.SignedMessage()
-		if err != nil {
-			return nil, Meta{}, Error.Wrap(err)
-		}
-		pba := s.pdb.PayerBandwidthAllocation()
-		rr, err = s.ec.Get(ctx, nodes, es, pid, pr.GetSize(), pba, signedMessage)
-		if err != nil {
-			return nil, Meta{}, Error.Wrap(err)
+		if s.retries > 0 {
+			rr = &retryRanger{Ranger: rr, s: s, path: path, pointer: pr}
 		}
 	} else {
 		rr = ranger.ByteRanger(pr.InlineSegment)
@@ -233,15 +254,72 @@ func (s *segmentStore) Get(ctx context.Context, path storj.Path) (
 	return rr, convertMeta(pr), nil
 }
 
+// remoteRanger returns the ranger of the remote segment of pointer pr
+func (s *segmentStore) remoteRanger(ctx context.Context, pr *pb.Pointer) (rr ranger.Ranger, err error) {
+	seg := pr.GetRemote()
+	pid := client.PieceID(seg.GetPieceId())
+	nodes, err := s.lookupNodes(ctx, seg)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	es, err := makeErasureScheme(seg.GetRedundancy())
+	if err != nil {
+		return nil, err
+	}
+
+	signedMessage, err := s.pdb.SignedMessage()
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	pba := s.pdb.PayerBandwidthAllocation()
+	rr, err = s.ec.Get(ctx, nodes, es, pid, pr.GetSize(), pieceHashes(seg), pba, signedMessage)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return rr, nil
+}
+
 func makeErasureScheme(rs *pb.RedundancyScheme) (eestream.ErasureScheme, error) {
-	fc, err := infectious.NewFEC(int(rs.GetMinReq()), int(rs.GetTotal()))
+	var algorithm storj.RedundancyAlgorithm
+	switch rs.GetType() {
+	case pb.RedundancyScheme_RS:
+		algorithm = storj.ReedSolomon
+	case pb.RedundancyScheme_RS_SIMD:
+		algorithm = storj.ReedSolomonSIMD
+	default:
+		return nil, Error.New("invalid redundancy scheme type %v", rs.GetType())
+	}
+	es, err := eestream.NewErasureScheme(algorithm, int(rs.GetMinReq()), int(rs.GetTotal()), int(rs.GetErasureShareSize()))
 	if err != nil {
 		return nil, Error.Wrap(err)
 	}
-	es := eestream.NewRSScheme(fc, int(rs.GetErasureShareSize()))
 	return es, nil
 }
 
+// schemeType returns the type of redundancy scheme of the segments stored
+// with rs
+func schemeType(rs eestream.RedundancyStrategy) pb.RedundancyScheme_SchemeType {
+	if eestream.Algorithm(rs) == storj.ReedSolomonSIMD {
+		return pb.RedundancyScheme_RS_SIMD
+	}
+	return pb.RedundancyScheme_RS
+}
+
+// makeRedundancyStrategy returns the redundancy strategy of a segment
+// stored with the scheme rs
+func makeRedundancyStrategy(rs *pb.RedundancyScheme) (eestream.RedundancyStrategy, error) {
+	es, err := makeErasureScheme(rs)
+	if err != nil {
+		return eestream.RedundancyStrategy{}, err
+	}
+	strategy, err := eestream.NewRedundancyStrategy(es, int(rs.GetRepairThreshold()), int(rs.GetSuccessThreshold()))
+	if err != nil {
+		return eestream.RedundancyStrategy{}, Error.Wrap(err)
+	}
+	return strategy, nil
+}
+
 // Delete tells piece stores to delete a segment and deletes pointer from pointerdb
 func (s *segmentStore) Delete(ctx context.Context, path storj.Path) (err error) {
 	defer mon.Task()(&ctx)(&err)
@@ -251,27 +329,45 @@ func (s *segmentStore) Delete(ctx context.Context, path storj.Path) (err error)
 		return Error.Wrap(err)
 	}
 
-	if pr.GetType() == pb.Pointer_REMOTE {
-		seg := pr.GetRemote()
-		pid := client.PieceID(seg.PieceId)
-		nodes, err := s.lookupNodes(ctx, seg)
-		if err != nil {
-			return Error.Wrap(err)
-		}
+	if pr.GetType() != pb.Pointer_REMOTE {
+		// deletes pointer from pointerdb
+		_, err = s.pdb.Delete(ctx, path)
+		return err
+	}
 
-		signedMessage, err := s.pdb.SignedMessage()
-		if err != nil {
-			return Error.Wrap(err)
-		}
-		// ecclient sends delete request
-		err = s.ec.Delete(ctx, nodes, pid, signedMessage)
-		if err != nil {
-			return Error.Wrap(err)
-		}
+	seg := pr.GetRemote()
+	pid := client.PieceID(seg.PieceId)
+	nodes, err := s.lookupNodes(ctx, seg)
+	if err != nil {
+		return Error.Wrap(err)
 	}
 
-	// deletes pointer from pointerdb
-	return s.pdb.Delete(ctx, path)
+	signedMessage, err := s.pdb.SignedMessage()
+	if err != nil {
+		return Error.Wrap(err)
+	}
+
+	// the pointer is deleted first, so the pieces aren't deleted while
+	// pointerdb copies it
+	shared, err := s.pdb.Delete(ctx, path)
+	if err != nil || shared {
+		return err
+	}
+
+	// ecclient sends delete request
+	err = s.ec.Delete(ctx, nodes, pid, signedMessage)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	return nil
+}
+
+// Copy copies the segment at src to dst with metadata. The pieces of a
+// remote segment aren't copied, but shared by the copies.
+func (s *segmentStore) Copy(ctx context.Context, src, dst storj.Path, metadata []byte) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return Error.Wrap(s.pdb.Copy(ctx, src, dst, metadata))
 }
 
 // Repair retrieves an at-risk segment and repairs and stores lost pieces on new nodes
@@ -299,21 +395,24 @@ func (s *segmentStore) Repair(ctx context.Context, path storj.Path, lostPieces [
 
 	// get the nodes list that needs to be excluded
 	var excludeNodeIDs []dht.NodeID
-
-	// count the number of nil nodes thats needs to be repaired
-	totalNilNodes := 0
-	for j, v := range originalNodes {
+	for _, v := range originalNodes {
 		if v != nil {
 			excludeNodeIDs = append(excludeNodeIDs, node.IDFromString(v.GetId()))
-		} else {
-			totalNilNodes++
 		}
+	}
 
-		//remove all lost pieces from the list to have only healthy pieces
-		for i := range lostPieces {
-			if j == lostPieces[i] {
-				totalNilNodes++
-			}
+	// the lost pieces are rebuilt like the missing ones
+	for _, i := range lostPieces {
+		if i >= 0 && i < len(originalNodes) {
+			originalNodes[i] = nil
+		}
+	}
+
+	// count the number of nil nodes thats needs to be repaired
+	totalNilNodes := 0
+	for _, v := range originalNodes {
+		if v == nil {
+			totalNilNodes++
 		}
 	}
 
@@ -330,14 +429,15 @@ func (s *segmentStore) Repair(ctx context.Context, path storj.Path, lostPieces [
 	repairNodesList := make([]*pb.Node, len(originalNodes))
 	for j, vr := range originalNodes {
 		// find the nil in the original node list
-		if vr == nil {
+		if vr == nil && totalRepairCount > 0 {
 			// replace the location with the newNode Node info
 			totalRepairCount--
 			repairNodesList[j] = newNodes[totalRepairCount]
 		}
 	}
 
-	es, err := makeErasureScheme(pr.GetRemote().GetRedundancy())
+	// the segment is repaired with the scheme it was stored with
+	rs, err := makeRedundancyStrategy(pr.GetRemote().GetRedundancy())
 	if err != nil {
 		return Error.Wrap(err)
 	}
@@ -348,23 +448,13 @@ func (s *segmentStore) Repair(ctx context.Context, path storj.Path, lostPieces [
 	}
 	pba := s.pdb.PayerBandwidthAllocation()
 
-	// download the segment using the nodes just with healthy nodes
-	rr, err := s.ec.Get(ctx, originalNodes, es, pid, pr.GetSize(), pba, signedMessage)
-	if err != nil {
-		return Error.Wrap(err)
-	}
-
-	// get io.Reader from ranger
-	r, err := rr.Range(ctx, 0, rr.Size())
-	if err != nil {
-		return err
-	}
-	defer utils.LogClose(r)
-
-	// puts file to ecclient
+	// rebuild just the missing pieces from the healthy ones, and store them
+	// on the new nodes
 	exp := pr.GetExpirationDate()
-
-	successfulNodes, err := s.ec.Put(ctx, repairNodesList, s.rs, pid, r, time.Unix(exp.GetSeconds(), 0), pba, signedMessage)
+	hashes := pieceHashes(seg)
+	receipts := pieceReceipts(seg)
+	successfulNodes, successfulHashes, successfulReceipts, err := s.ec.Repair(ctx, originalNodes, rs, pid, pr.GetSize(), hashes,
+		repairNodesList, time.Unix(exp.GetSeconds(), 0), pba, signedMessage)
 	if err != nil {
 		return Error.Wrap(err)
 	}
@@ -374,11 +464,13 @@ func (s *segmentStore) Repair(ctx context.Context, path storj.Path, lostPieces [
 		if v == nil {
 			// copy the successfuNode info
 			originalNodes[i] = successfulNodes[i]
+			hashes[i] = successfulHashes[i]
+			receipts[i] = successfulReceipts[i]
 		}
 	}
 
 	metadata := pr.GetMetadata()
-	pointer, err := s.makeRemotePointer(originalNodes, pid, rr.Size(), exp, metadata)
+	pointer, err := makeRemotePointer(rs, originalNodes, hashes, receipts, pid, pr.GetSize(), exp, metadata)
 	if err != nil {
 		return err
 	}
@@ -408,6 +500,30 @@ func (s *segmentStore) lookupNodes(ctx context.Context, seg *pb.RemoteSegment) (
 	return nodes, nil
 }
 
+// pieceHashes returns the hashes of the pieces of seg, indexed by the piece
+// number. Pieces stored without hashes have a nil hash.
+func pieceHashes(seg *pb.RemoteSegment) [][]byte {
+	hashes := make([][]byte, seg.GetRedundancy().GetTotal())
+	for _, p := range seg.GetRemotePieces() {
+		if int(p.PieceNum) < len(hashes) {
+			hashes[p.PieceNum] = p.GetHash()
+		}
+	}
+	return hashes
+}
+
+// pieceReceipts returns the receipts of the pieces of seg, indexed by the
+// piece number. Pieces stored without receipts have a nil receipt.
+func pieceReceipts(seg *pb.RemoteSegment) []*pb.PieceHash {
+	receipts := make([]*pb.PieceHash, seg.GetRedundancy().GetTotal())
+	for _, p := range seg.GetRemotePieces() {
+		if int(p.PieceNum) < len(receipts) {
+			receipts[p.PieceNum] = p.GetReceipt()
+		}
+	}
+	return receipts
+}
+
 // List retrieves paths to segments and their metadata stored in the pointerdb
 func (s *segmentStore) List(ctx context.Context, prefix, startAfter, endBefore storj.Path, recursive bool, limit int, metaFlags uint32) (items []ListItem, more bool, err error) {
 	defer mon.Task()(&ctx)(&err)
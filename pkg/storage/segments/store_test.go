@@ -15,11 +15,11 @@ import (
 
 	"storj.io/storj/pkg/eestream"
 	mock_eestream "storj.io/storj/pkg/eestream/mocks"
+	"storj.io/storj/pkg/overlay"
 	mock_overlay "storj.io/storj/pkg/overlay/mocks"
 	"storj.io/storj/pkg/pb"
 	pdb "storj.io/storj/pkg/pointerdb/pdbclient"
 	mock_pointerdb "storj.io/storj/pkg/pointerdb/pdbclient/mocks"
-	"storj.io/storj/pkg/ranger"
 	mock_ecclient "storj.io/storj/pkg/storage/ec/mocks"
 	"storj.io/storj/pkg/storage/meta"
 	"storj.io/storj/pkg/storj"
@@ -40,7 +40,7 @@ func TestNewSegmentStore(t *testing.T) {
 		ErasureScheme: mock_eestream.NewMockErasureScheme(ctrl),
 	}
 
-	ss := NewSegmentStore(mockOC, mockEC, mockPDB, rs, 10)
+	ss := NewSegmentStore(mockOC, mockEC, mockPDB, rs, 10, 0)
 	assert.NotNil(t, ss)
 }
 
@@ -55,7 +55,7 @@ func TestSegmentStoreMeta(t *testing.T) {
 		ErasureScheme: mock_eestream.NewMockErasureScheme(ctrl),
 	}
 
-	ss := segmentStore{mockOC, mockEC, mockPDB, rs, 10}
+	ss := segmentStore{mockOC, mockEC, mockPDB, rs, 10, 0}
 	assert.NotNil(t, ss)
 
 	var mExp time.Time
@@ -104,7 +104,7 @@ func TestSegmentStorePutRemote(t *testing.T) {
 			ErasureScheme: mockES,
 		}
 
-		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize}
+		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize, 0}
 		assert.NotNil(t, ss)
 
 		calls := []*gomock.Call{
@@ -138,6 +138,67 @@ func TestSegmentStorePutRemote(t *testing.T) {
 	}
 }
 
+func TestSegmentStorePutRemoteWithRedundancy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOC := mock_overlay.NewMockClient(ctrl)
+	mockEC := mock_ecclient.NewMockClient(ctrl)
+	mockPDB := mock_pointerdb.NewMockClient(ctrl)
+	rs := eestream.RedundancyStrategy{
+		ErasureScheme: mock_eestream.NewMockErasureScheme(ctrl),
+	}
+
+	ss := segmentStore{mockOC, mockEC, mockPDB, rs, 2, 0}
+	assert.NotNil(t, ss)
+
+	bucketRS, err := eestream.NewRedundancyStrategyFromStorj(storj.RedundancyScheme{
+		Algorithm:      storj.ReedSolomon,
+		ShareSize:      1,
+		RequiredShares: 1,
+		RepairShares:   2,
+		OptimalShares:  3,
+		TotalShares:    4,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	calls := []*gomock.Call{
+		mockOC.EXPECT().Choose(
+			gomock.Any(), overlay.Options{Amount: 4},
+		).Return([]*pb.Node{
+			{Id: "im-a-node"},
+		}, nil),
+		mockPDB.EXPECT().SignedMessage(),
+		mockPDB.EXPECT().PayerBandwidthAllocation(),
+		mockEC.EXPECT().Put(
+			gomock.Any(), gomock.Any(), bucketRS, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		),
+		mockPDB.EXPECT().Put(
+			gomock.Any(), gomock.Any(), gomock.Any(),
+		).Do(func(ctx context.Context, path storj.Path, pointer *pb.Pointer) {
+			assert.Equal(t, &pb.RedundancyScheme{
+				Type:             pb.RedundancyScheme_RS,
+				MinReq:           1,
+				Total:            4,
+				RepairThreshold:  2,
+				SuccessThreshold: 3,
+				ErasureShareSize: 1,
+			}, pointer.GetRemote().GetRedundancy())
+		}).Return(nil),
+		mockPDB.EXPECT().Get(
+			gomock.Any(), gomock.Any(),
+		),
+	}
+	gomock.InOrder(calls...)
+
+	_, err = ss.Put(WithRedundancy(ctx, bucketRS), strings.NewReader("readerreaderreader"), time.Unix(0, 0).UTC(), func() (storj.Path, []byte, error) {
+		return "path/1", []byte("metadata"), nil
+	})
+	assert.NoError(t, err)
+}
+
 func TestSegmentStorePutInline(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -160,7 +221,7 @@ func TestSegmentStorePutInline(t *testing.T) {
 			ErasureScheme: mockES,
 		}
 
-		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize}
+		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize, 0}
 		assert.NotNil(t, ss)
 
 		calls := []*gomock.Call{
@@ -206,7 +267,7 @@ func TestSegmentStoreGetInline(t *testing.T) {
 			ErasureScheme: mockES,
 		}
 
-		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize}
+		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize, 0}
 		assert.NotNil(t, ss)
 
 		calls := []*gomock.Call{
@@ -244,12 +305,14 @@ func TestSegmentStoreRepairRemote(t *testing.T) {
 		metadata                []byte
 		lostPieces              []int
 		newNodes                []*pb.Node
+		newHashes               [][]byte
+		newReceipts             []*pb.PieceHash
 		data                    string
 		strsize, offset, length int64
 		substr                  string
 		meta                    Meta
 	}{
-		{"path/1/2/3", 10, pb.Pointer_REMOTE, int64(3), []byte("metadata"), []int{}, []*pb.Node{{Id: "1"}, {Id: "2"}}, "abcdefghijkl", 12, 1, 4, "bcde", Meta{}},
+		{"path/1/2/3", 10, pb.Pointer_REMOTE, int64(3), []byte("metadata"), []int{}, []*pb.Node{{Id: "1"}, {Id: "2"}}, [][]byte{[]byte("hash1"), []byte("hash2")}, []*pb.PieceHash{{Signature: []byte("sig1")}, {Signature: []byte("sig2")}}, "abcdefghijkl", 12, 1, 4, "bcde", Meta{}},
 	} {
 		mockOC := mock_overlay.NewMockClient(ctrl)
 		mockEC := mock_ecclient.NewMockClient(ctrl)
@@ -259,22 +322,25 @@ func TestSegmentStoreRepairRemote(t *testing.T) {
 			ErasureScheme: mockES,
 		}
 
-		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize}
+		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize, 0}
 		assert.NotNil(t, ss)
 
+		redundancy := &pb.RedundancyScheme{
+			Type:             pb.RedundancyScheme_RS,
+			MinReq:           1,
+			Total:            2,
+			RepairThreshold:  1,
+			SuccessThreshold: 2,
+			ErasureShareSize: 1,
+		}
+
 		calls := []*gomock.Call{
 			mockPDB.EXPECT().Get(
 				gomock.Any(), gomock.Any(),
 			).Return(&pb.Pointer{
 				Type: tt.pointerType,
 				Remote: &pb.RemoteSegment{
-					Redundancy: &pb.RedundancyScheme{
-						Type:             pb.RedundancyScheme_RS,
-						MinReq:           1,
-						Total:            2,
-						RepairThreshold:  1,
-						SuccessThreshold: 2,
-					},
+					Redundancy:   redundancy,
 					PieceId:      "here's my piece id",
 					RemotePieces: []*pb.RemotePiece{},
 				},
@@ -287,18 +353,23 @@ func TestSegmentStoreRepairRemote(t *testing.T) {
 			mockOC.EXPECT().Choose(gomock.Any(), gomock.Any()).Return(tt.newNodes, nil),
 			mockPDB.EXPECT().SignedMessage(),
 			mockPDB.EXPECT().PayerBandwidthAllocation(),
-			mockEC.EXPECT().Get(
-				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
-			).Return(ranger.ByteRanger([]byte(tt.data)), nil),
-			mockEC.EXPECT().Put(
-				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
-			).Return(tt.newNodes, nil),
-			mockES.EXPECT().RequiredCount().Return(1),
-			mockES.EXPECT().TotalCount().Return(1),
-			mockES.EXPECT().ErasureShareSize().Return(1),
+			mockEC.EXPECT().Repair(
+				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), tt.size, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+			).Return(tt.newNodes, tt.newHashes, tt.newReceipts, nil),
 			mockPDB.EXPECT().Put(
 				gomock.Any(), gomock.Any(), gomock.Any(),
-			).Return(nil),
+			).Do(func(ctx context.Context, path storj.Path, pointer *pb.Pointer) {
+				// the segment is repaired with its own scheme, not the one of the store
+				assert.Equal(t, redundancy, pointer.GetRemote().GetRedundancy())
+				// the repaired pieces are stored with their hashes and receipts
+				for _, piece := range pointer.GetRemote().GetRemotePieces() {
+					assert.Equal(t, tt.newHashes[piece.PieceNum], piece.GetHash())
+					assert.Equal(t, tt.newReceipts[piece.PieceNum], piece.GetReceipt())
+				}
+				root, err := ReceiptsRoot(pointer.GetRemote().GetRemotePieces())
+				assert.NoError(t, err)
+				assert.Equal(t, root, pointer.GetRemote().GetMerkleRoot())
+			}).Return(nil),
 		}
 		gomock.InOrder(calls...)
 
@@ -332,7 +403,7 @@ func TestSegmentStoreGetRemote(t *testing.T) {
 			ErasureScheme: mockES,
 		}
 
-		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize}
+		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize, 0}
 		assert.NotNil(t, ss)
 
 		calls := []*gomock.Call{
@@ -360,7 +431,7 @@ func TestSegmentStoreGetRemote(t *testing.T) {
 			mockPDB.EXPECT().SignedMessage(),
 			mockPDB.EXPECT().PayerBandwidthAllocation(),
 			mockEC.EXPECT().Get(
-				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
 			),
 		}
 		gomock.InOrder(calls...)
@@ -396,7 +467,7 @@ func TestSegmentStoreDeleteInline(t *testing.T) {
 			ErasureScheme: mockES,
 		}
 
-		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize}
+		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize, 0}
 		assert.NotNil(t, ss)
 
 		calls := []*gomock.Call{
@@ -435,8 +506,11 @@ func TestSegmentStoreDeleteRemote(t *testing.T) {
 		pointerType   pb.Pointer_DataType
 		size          int64
 		metadata      []byte
+		shared        bool
 	}{
-		{"path/1/2/3", 10, pb.Pointer_REMOTE, int64(3), []byte("metadata")},
+		{"path/1/2/3", 10, pb.Pointer_REMOTE, int64(3), []byte("metadata"), false},
+		// the pieces shared by copies of the segment aren't deleted
+		{"path/1/2/3", 10, pb.Pointer_REMOTE, int64(3), []byte("metadata"), true},
 	} {
 		mockOC := mock_overlay.NewMockClient(ctrl)
 		mockEC := mock_ecclient.NewMockClient(ctrl)
@@ -446,7 +520,7 @@ func TestSegmentStoreDeleteRemote(t *testing.T) {
 			ErasureScheme: mockES,
 		}
 
-		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize}
+		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize, 0}
 		assert.NotNil(t, ss)
 
 		calls := []*gomock.Call{
@@ -472,12 +546,14 @@ func TestSegmentStoreDeleteRemote(t *testing.T) {
 			}, nil),
 			mockOC.EXPECT().BulkLookup(gomock.Any(), gomock.Any()),
 			mockPDB.EXPECT().SignedMessage(),
-			mockEC.EXPECT().Delete(
-				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
-			),
 			mockPDB.EXPECT().Delete(
 				gomock.Any(), gomock.Any(),
-			),
+			).Return(tt.shared, nil),
+		}
+		if !tt.shared {
+			calls = append(calls, mockEC.EXPECT().Delete(
+				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+			))
 		}
 		gomock.InOrder(calls...)
 
@@ -508,7 +584,7 @@ func TestSegmentStoreList(t *testing.T) {
 			ErasureScheme: mockES,
 		}
 
-		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize}
+		ss := segmentStore{mockOC, mockEC, mockPDB, rs, tt.thresholdSize, 0}
 		assert.NotNil(t, ss)
 
 		ti := time.Unix(0, 0).UTC()
@@ -539,3 +615,37 @@ func TestSegmentStoreList(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestMakeRedundancyStrategy(t *testing.T) {
+	for _, algorithm := range []storj.RedundancyAlgorithm{storj.ReedSolomon, storj.ReedSolomonSIMD} {
+		rs, err := eestream.NewRedundancyStrategyFromStorj(storj.RedundancyScheme{
+			Algorithm:      algorithm,
+			ShareSize:      1024,
+			RequiredShares: 2,
+			RepairShares:   3,
+			OptimalShares:  4,
+			TotalShares:    5,
+		})
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		// the pointer records the scheme the segment is decoded with
+		pointer, err := makeRemotePointer(rs, nil, nil, nil, "pieceID", 10, nil, nil)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		strategy, err := makeRedundancyStrategy(pointer.GetRemote().GetRedundancy())
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.Equal(t, algorithm, eestream.Algorithm(strategy))
+		assert.Equal(t, rs.RequiredCount(), strategy.RequiredCount())
+		assert.Equal(t, rs.TotalCount(), strategy.TotalCount())
+		assert.Equal(t, rs.RepairThreshold(), strategy.RepairThreshold())
+		assert.Equal(t, rs.OptimalThreshold(), strategy.OptimalThreshold())
+	}
+
+	_, err := makeRedundancyStrategy(&pb.RedundancyScheme{Type: 100, MinReq: 2, Total: 5})
+	assert.EqualError(t, err, "segment error: invalid redundancy scheme type 100")
+}
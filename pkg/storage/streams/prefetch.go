@@ -0,0 +1,290 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package streams
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/eestream"
+	"storj.io/storj/pkg/ranger"
+	"storj.io/storj/pkg/utils"
+)
+
+// prefetchRanger concatenates the rangers of the segments of a stream. The
+// segment read is streamed, while up to parallelism-1 segments after it are
+// downloaded ahead, one at a time, and buffered in memory until they're
+// read. The memory of the buffered segments is reserved from budget, and
+// segments which don't fit in it aren't downloaded ahead.
+type prefetchRanger struct {
+	segments    []ranger.Ranger
+	size        int64
+	parallelism int
+	budget      *eestream.MemoryBudget
+}
+
+// newPrefetchRanger returns the segments concatenated, downloading up to
+// parallelism of them at once, as long as the ones ahead fit in budget
+func newPrefetchRanger(segments []ranger.Ranger, parallelism int, budget *eestream.MemoryBudget) ranger.Ranger {
+	if parallelism <= 1 || len(segments) <= 1 {
+		return ranger.Concat(segments...)
+	}
+	var size int64
+	for _, rr := range segments {
+		size += rr.Size()
+	}
+	return &prefetchRanger{segments: segments, size: size, parallelism: parallelism, budget: budget}
+}
+
+// Size implements Ranger.Size
+func (rr *prefetchRanger) Size() int64 {
+	return rr.size
+}
+
+// segmentRange is the range of a segment read by a prefetchReader
+type segmentRange struct {
+	segment        ranger.Ranger
+	offset, length int64
+}
+
+// Range implements Ranger.Range
+func (rr *prefetchRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 {
+		return nil, errs.New("negative offset")
+	}
+	if length < 0 {
+		return nil, errs.New("negative length")
+	}
+	if offset+length > rr.size {
+		return nil, errs.New("range beyond end")
+	}
+
+	var ranges []segmentRange
+	for _, segment := range rr.segments {
+		if length == 0 {
+			break
+		}
+		size := segment.Size()
+		if offset >= size {
+			offset -= size
+			continue
+		}
+		n := size - offset
+		if n > length {
+			n = length
+		}
+		ranges = append(ranges, segmentRange{segment: segment, offset: offset, length: n})
+		offset = 0
+		length -= n
+	}
+
+	switch len(ranges) {
+	case 0:
+		return ioutil.NopCloser(bytes.NewReader(nil)), nil
+	case 1:
+		return ranges[0].segment.Range(ctx, ranges[0].offset, ranges[0].length)
+	}
+	return newPrefetchReader(ctx, ranges, rr.parallelism-1, rr.budget), nil
+}
+
+// prefetched is a segment downloaded by a prefetchReader
+type prefetched struct {
+	data []byte
+	err  error
+}
+
+// prefetchReader reads the ranges of segments in order, streaming the one
+// read, while the ranges after it are downloaded ahead
+type prefetchReader struct {
+	ctx    context.Context
+	cancel func()
+	wg     sync.WaitGroup
+	ranges []segmentRange
+	budget *eestream.MemoryBudget
+	ahead  int
+
+	// downloaded has the range of each segment downloaded ahead once it's
+	// downloaded
+	downloaded []chan prefetched
+	// advanced wakes the download of the ranges ahead up when the range
+	// read changes
+	advanced chan struct{}
+
+	mu sync.Mutex
+	// reading is the index of the range read, and started the index of the
+	// range downloaded ahead next; the ranges in between are downloaded
+	// ahead, and their memory is reserved from the budget until they're
+	// read. The first range is always streamed.
+	reading int
+	started int
+
+	current io.ReadCloser
+	err     error
+}
+
+// newPrefetchReader returns the reader of ranges, which downloads up to
+// ahead of them after the one read, as long as they fit in budget
+func newPrefetchReader(ctx context.Context, ranges []segmentRange, ahead int, budget *eestream.MemoryBudget) *prefetchReader {
+	ctx, cancel := context.WithCancel(ctx)
+	r := &prefetchReader{
+		ctx:        ctx,
+		cancel:     cancel,
+		ranges:     ranges,
+		budget:     budget,
+		ahead:      ahead,
+		downloaded: make([]chan prefetched, len(ranges)),
+		advanced:   make(chan struct{}, 1),
+		reading:    -1,
+		started:    1,
+	}
+	for i := range r.downloaded {
+		r.downloaded[i] = make(chan prefetched, 1)
+	}
+
+	r.wg.Add(1)
+	go r.downloadAhead()
+	return r
+}
+
+// downloadAhead downloads the ranges after the one read, one at a time, as
+// long as they're at most r.ahead after it and fit in the budget
+func (r *prefetchReader) downloadAhead() {
+	defer r.wg.Done()
+	for {
+		r.mu.Lock()
+		i := r.started
+		if i >= len(r.ranges) {
+			r.mu.Unlock()
+			return
+		}
+		ok := i-r.reading <= r.ahead && r.budget.TryReserve(r.ranges[i].length)
+		if ok {
+			r.started++
+		}
+		r.mu.Unlock()
+
+		if !ok {
+			select {
+			case <-r.advanced:
+				continue
+			case <-r.ctx.Done():
+				return
+			}
+		}
+		r.downloaded[i] <- download(r.ctx, r.ranges[i])
+	}
+}
+
+// download reads all of the range sr
+func download(ctx context.Context, sr segmentRange) prefetched {
+	rc, err := sr.segment.Range(ctx, sr.offset, sr.length)
+	if err != nil {
+		return prefetched{err: err}
+	}
+	data, err := ioutil.ReadAll(rc)
+	return prefetched{data: data, err: utils.CombineErrors(err, rc.Close())}
+}
+
+// advance makes the next range the one read. It's streamed unless it was
+// downloaded ahead. The memory of a range downloaded ahead is released once
+// it's the one read, so that its download isn't held back by it.
+func (r *prefetchReader) advance() (err error) {
+	if r.current != nil {
+		err = r.current.Close()
+		r.current = nil
+		if err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	r.reading++
+	i := r.reading
+	if i >= len(r.ranges) {
+		r.mu.Unlock()
+		return io.EOF
+	}
+	prefetching := i > 0 && i < r.started
+	if prefetching {
+		r.budget.Release(r.ranges[i].length)
+	} else {
+		r.started = i + 1
+	}
+	r.mu.Unlock()
+
+	select {
+	case r.advanced <- struct{}{}:
+	default:
+	}
+
+	if !prefetching {
+		sr := r.ranges[i]
+		r.current, err = sr.segment.Range(r.ctx, sr.offset, sr.length)
+		return err
+	}
+	select {
+	case segment := <-r.downloaded[i]:
+		if segment.err != nil {
+			return segment.err
+		}
+		r.current = ioutil.NopCloser(bytes.NewReader(segment.data))
+		return nil
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+}
+
+// Read implements io.Reader
+func (r *prefetchReader) Read(p []byte) (n int, err error) {
+	// the segments downloaded before the reader was closed or its context
+	// canceled aren't read either
+	if r.err == nil {
+		r.err = r.ctx.Err()
+	}
+	for r.err == nil {
+		if r.current != nil {
+			n, err = r.current.Read(p)
+			if err == io.EOF {
+				err = nil
+				if n == 0 {
+					r.err = r.advance()
+					continue
+				}
+			}
+			if err != nil {
+				r.err = err
+			}
+			return n, err
+		}
+		r.err = r.advance()
+	}
+	return 0, r.err
+}
+
+// Close implements io.Closer. It stops the downloads, waits for them and
+// releases the memory of the segments downloaded ahead.
+func (r *prefetchReader) Close() (err error) {
+	r.cancel()
+	r.wg.Wait()
+	if r.current != nil {
+		err = r.current.Close()
+		r.current = nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := r.reading + 1; i < r.started; i++ {
+		if i == 0 {
+			continue
+		}
+		r.budget.Release(r.ranges[i].length)
+	}
+	r.reading = r.started - 1
+	return err
+}
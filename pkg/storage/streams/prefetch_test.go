@@ -0,0 +1,170 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package streams
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/eestream"
+	"storj.io/storj/pkg/ranger"
+)
+
+// slowSegments are the rangers of segments which take a while to download,
+// counting how many are downloaded at once
+type slowSegments struct {
+	mu      sync.Mutex
+	running int
+	max     int
+	fail    int
+}
+
+type slowRanger struct {
+	ranger.Ranger
+	segments *slowSegments
+	index    int
+}
+
+func (rr *slowRanger) Range(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	s := rr.segments
+	s.mu.Lock()
+	s.running++
+	if s.running > s.max {
+		s.max = s.running
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running--
+		s.mu.Unlock()
+	}()
+
+	// give the other downloads the chance to start
+	time.Sleep(10 * time.Millisecond)
+	if rr.index == s.fail {
+		return nil, errs.New("range failed")
+	}
+	return rr.Ranger.Range(ctx, offset, length)
+}
+
+func (s *slowSegments) rangers(data []byte, segmentSize int) []ranger.Ranger {
+	var rangers []ranger.Ranger
+	for i := 0; i*segmentSize < len(data); i++ {
+		end := (i + 1) * segmentSize
+		if end > len(data) {
+			end = len(data)
+		}
+		rangers = append(rangers, &slowRanger{Ranger: ranger.ByteRanger(data[i*segmentSize : end]), segments: s, index: i})
+	}
+	return rangers
+}
+
+func TestPrefetchRanger(t *testing.T) {
+	data := make([]byte, 950)
+	_, err := rand.Read(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, parallelism := range []int{1, 3} {
+		for _, tt := range []struct {
+			offset, length int64
+		}{
+			{0, 950},
+			{150, 500},
+			{210, 50},
+			{900, 50},
+			{300, 0},
+		} {
+			errTag := fmt.Sprintf("parallelism %d, range %d-%d", parallelism, tt.offset, tt.offset+tt.length)
+
+			segments := &slowSegments{fail: -1}
+			rr := newPrefetchRanger(segments.rangers(data, 100), parallelism, nil)
+			assert.Equal(t, int64(len(data)), rr.Size(), errTag)
+
+			r, err := rr.Range(ctx, tt.offset, tt.length)
+			if !assert.NoError(t, err, errTag) {
+				continue
+			}
+			downloaded, err := ioutil.ReadAll(r)
+			assert.NoError(t, err, errTag)
+			assert.Equal(t, data[tt.offset:tt.offset+tt.length], downloaded, errTag)
+			assert.NoError(t, r.Close(), errTag)
+
+			// no more segments are downloaded at once than the parallelism
+			assert.True(t, segments.max <= parallelism, errTag)
+		}
+	}
+
+	rr := newPrefetchRanger((&slowSegments{fail: -1}).rangers(data, 100), 3, nil)
+	for _, invalid := range [][2]int64{{-1, 10}, {10, -1}, {900, 51}} {
+		_, err := rr.Range(ctx, invalid[0], invalid[1])
+		assert.Error(t, err)
+	}
+
+	// a failed segment fails the read after the segments before it
+	segments := &slowSegments{fail: 4}
+	r, err := newPrefetchRanger(segments.rangers(data, 100), 3, nil).Range(ctx, 0, 950)
+	if assert.NoError(t, err) {
+		downloaded, err := ioutil.ReadAll(r)
+		assert.Error(t, err)
+		assert.Equal(t, data[:400], downloaded)
+		assert.NoError(t, r.Close())
+	}
+
+	// a reader closed before it's read stops its downloads
+	r, err = newPrefetchRanger((&slowSegments{fail: -1}).rangers(data, 100), 3, nil).Range(ctx, 0, 950)
+	if assert.NoError(t, err) {
+		assert.NoError(t, r.Close())
+		_, err = r.Read(make([]byte, 1))
+		assert.Equal(t, context.Canceled, err)
+	}
+}
+
+func TestPrefetchRangerBudget(t *testing.T) {
+	ctx := context.Background()
+	data := make([]byte, 950)
+	_, err := rand.Read(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// segments ahead are only downloaded while they fit in the budget, and
+	// their memory is released once they're read or the reader is closed
+	for _, size := range []int64{50, 250, 1000} {
+		budget, err := eestream.NewMemoryBudget(size)
+		if !assert.NoError(t, err) {
+			return
+		}
+		segments := &slowSegments{fail: -1}
+		r, err := newPrefetchRanger(segments.rangers(data, 100), 4, budget).Range(ctx, 0, 950)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.True(t, budget.Reserved() <= size)
+		downloaded, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, data, downloaded)
+		assert.NoError(t, r.Close())
+		assert.Equal(t, int64(0), budget.Reserved())
+
+		r, err = newPrefetchRanger(segments.rangers(data, 100), 4, budget).Range(ctx, 0, 950)
+		if assert.NoError(t, err) {
+			_, err = r.Read(make([]byte, 10))
+			assert.NoError(t, err)
+			time.Sleep(50 * time.Millisecond)
+			assert.NoError(t, r.Close())
+			assert.Equal(t, int64(0), budget.Reserved())
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package streams
+
+import (
+	"context"
+	"sync"
+)
+
+// Resume continues an interrupted upload of a stream after the segments the
+// upload committed, and keeps track of the segments committed by the upload
+// it's given to, so that one can be continued too. The segments are
+// committed in order, once they and the ones before them are stored. The
+// last segment isn't committed, since the stream is complete once it's
+// stored.
+type Resume struct {
+	mu        sync.Mutex
+	committed int64
+	stored    map[int64]bool
+	onCommit  func(committed int64)
+}
+
+// NewResume returns a Resume continuing an upload after its first committed
+// segments, which calls onCommit, if it's set, with the number of committed
+// segments whenever segments are committed
+func NewResume(committed int64, onCommit func(committed int64)) *Resume {
+	return &Resume{
+		committed: committed,
+		stored:    map[int64]bool{},
+		onCommit:  onCommit,
+	}
+}
+
+// Committed returns how many segments of the stream are committed
+func (r *Resume) Committed() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.committed
+}
+
+// commit records that the segment with index is stored, and commits it if
+// the segments before it are
+func (r *Resume) commit(index int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stored[index] = true
+	committed := r.committed
+	for r.stored[r.committed] {
+		delete(r.stored, r.committed)
+		r.committed++
+	}
+	if r.committed > committed && r.onCommit != nil {
+		r.onCommit(r.committed)
+	}
+}
+
+type resumeKey struct{}
+
+// WithResume returns a context which makes the stream uploaded with it
+// continue after the committed segments of resume, keeping the committed
+// segments if it's interrupted. The data of the upload has to start after
+// the committed segments.
+func WithResume(ctx context.Context, resume *Resume) context.Context {
+	return context.WithValue(ctx, resumeKey{}, resume)
+}
+
+// resumeFromContext returns the Resume of ctx, or nil if it has none
+func resumeFromContext(ctx context.Context) *Resume {
+	resume, _ := ctx.Value(resumeKey{}).(*Resume)
+	return resume
+}
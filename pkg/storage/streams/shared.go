@@ -0,0 +1,139 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package streams
+
+import (
+	"strings"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/eestream"
+	"storj.io/storj/pkg/encryption"
+	"storj.io/storj/pkg/storage/segments"
+	"storj.io/storj/pkg/storj"
+)
+
+// ErrNotShared is returned for the paths outside the prefix of a shared
+// stream store
+var ErrNotShared = errs.Class("path not shared")
+
+// SharedPrefix is the part of the key hierarchy which is shared to give
+// access to the paths under a prefix only
+type SharedPrefix struct {
+	// Prefix is the shared prefix, starting with its bucket
+	Prefix storj.Path
+	// EncryptedPrefix is Prefix with the components after the bucket
+	// encrypted, as the paths under it are stored
+	EncryptedPrefix storj.Path
+	// Key is the key of Prefix, the paths under it are encrypted with
+	Key *storj.Key
+	// BucketKey is the key the metadata of the bucket of Prefix is encrypted
+	// with, so the bucket can be looked up
+	BucketKey *storj.Key
+}
+
+// NewSharedPrefix returns the part of the key hierarchy of rootKey to share to
+// give access to the paths under prefix only
+func NewSharedPrefix(prefix storj.Path, rootKey *storj.Key) (*SharedPrefix, error) {
+	comps := storj.SplitPath(strings.Trim(prefix, "/"))
+	if len(comps) == 0 || comps[0] == "" {
+		return nil, errs.New("shared prefix must start with a bucket")
+	}
+	prefix = storj.JoinPaths(comps...)
+
+	encPrefix, err := encryptAfterBucket(prefix, rootKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := encryption.DerivePathKey(prefix, rootKey, len(comps))
+	if err != nil {
+		return nil, err
+	}
+	bucketKey, err := encryption.DeriveContentKey(comps[0], rootKey)
+	if err != nil {
+		return nil, err
+	}
+	return &SharedPrefix{
+		Prefix:          prefix,
+		EncryptedPrefix: encPrefix,
+		Key:             key,
+		BucketKey:       bucketKey,
+	}, nil
+}
+
+// NewSharedStreamStore returns a stream store like NewStreamStore, which has
+// access to the paths under the prefix of shared only
+func NewSharedStreamStore(segments segments.Store, segmentSize int64, shared *SharedPrefix, encBlockSize int, cipher storj.Cipher, hideSizes bool, parallelism int, maxBufferMem int64, downloads int, budget *eestream.MemoryBudget) (Store, error) {
+	if shared == nil || shared.Prefix == "" || shared.Key == nil || shared.BucketKey == nil {
+		return nil, errs.New("shared prefix must not be empty")
+	}
+	store, err := NewStreamStore(segments, segmentSize, shared.Key, encBlockSize, cipher, hideSizes, parallelism, maxBufferMem, downloads, budget)
+	if err != nil {
+		return nil, err
+	}
+	s := store.(*streamStore)
+	s.rootKey = nil
+	s.shared = shared
+	return s, nil
+}
+
+// bucket returns the bucket of the shared prefix
+func (sp *SharedPrefix) bucket() string {
+	return storj.SplitPath(sp.Prefix)[0]
+}
+
+// relative returns path relative to the shared prefix, if it's under it
+func (sp *SharedPrefix) relative(path storj.Path) (storj.Path, error) {
+	if path == sp.Prefix {
+		return "", nil
+	}
+	if strings.HasPrefix(path, sp.Prefix+"/") {
+		return path[len(sp.Prefix)+1:], nil
+	}
+	return "", ErrNotShared.New("%s", path)
+}
+
+// encryptPath encrypts path like encryptAfterBucket
+func (sp *SharedPrefix) encryptPath(path storj.Path) (storj.Path, error) {
+	if path == sp.bucket() {
+		return path, nil
+	}
+	rel, err := sp.relative(path)
+	if err != nil {
+		return "", err
+	}
+	if rel == "" {
+		return sp.EncryptedPrefix, nil
+	}
+	encrypted, err := encryption.EncryptPath(rel, sp.Key)
+	if err != nil {
+		return "", err
+	}
+	return storj.JoinPaths(sp.EncryptedPrefix, encrypted), nil
+}
+
+// contentKey derives the key of the content at path like
+// encryption.DeriveContentKey
+func (sp *SharedPrefix) contentKey(path storj.Path) (*storj.Key, error) {
+	if path == sp.bucket() {
+		return sp.BucketKey, nil
+	}
+	rel, err := sp.relative(path)
+	if err != nil {
+		return nil, err
+	}
+	if rel == "" {
+		return encryption.DeriveKey(sp.Key, "content")
+	}
+	return encryption.DeriveContentKey(rel, sp.Key)
+}
+
+// prefixKey derives the key of prefix like encryption.DerivePathKey
+func (sp *SharedPrefix) prefixKey(prefix storj.Path) (*storj.Key, error) {
+	rel, err := sp.relative(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return encryption.DerivePathKey(rel, sp.Key, len(storj.SplitPath(rel)))
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package streams
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/storj"
+)
+
+func TestSharedStreamStore(t *testing.T) {
+	rootKey := new(storj.Key)
+	copy(rootKey[:], "root key")
+
+	mem := newMemSegments()
+	rootStore, err := NewStreamStore(mem, 100, rootKey, 1024, storj.AESGCM, false, 1, 0, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []storj.Path{"bucket", "bucket/shared/file", "bucket/other"} {
+		_, err := rootStore.Put(ctx, path, bytes.NewReader([]byte(path)), []byte(path), time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	shared, err := NewSharedPrefix("bucket/shared/", rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "bucket/shared", shared.Prefix)
+	sharedStore, err := NewSharedStreamStore(mem, 100, shared, 1024, storj.AESGCM, false, 1, 0, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the paths under the prefix and the bucket can be read
+	for _, path := range []storj.Path{"bucket", "bucket/shared/file"} {
+		rr, meta, err := sharedStore.Get(ctx, path)
+		if !assert.NoError(t, err, path) {
+			continue
+		}
+		assert.Equal(t, []byte(path), meta.Data, path)
+		r, err := rr.Range(ctx, 0, rr.Size())
+		if !assert.NoError(t, err, path) {
+			continue
+		}
+		data, err := ioutil.ReadAll(r)
+		assert.NoError(t, err, path)
+		assert.Equal(t, []byte(path), data, path)
+		assert.NoError(t, r.Close(), path)
+	}
+
+	// the ones the store puts can be read with the root key
+	_, err = sharedStore.Put(ctx, "bucket/shared/new", bytes.NewReader([]byte("new")), nil, time.Time{})
+	assert.NoError(t, err)
+	meta, err := rootStore.Meta(ctx, "bucket/shared/new")
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(3), meta.Size)
+	}
+
+	// and the other paths can't be used at all
+	_, err = sharedStore.Meta(ctx, "bucket/other")
+	assert.True(t, ErrNotShared.Has(err))
+	_, err = sharedStore.Put(ctx, "bucket/sharedfile", bytes.NewReader(nil), nil, time.Time{})
+	assert.True(t, ErrNotShared.Has(err))
+	_, _, err = sharedStore.List(ctx, "bucket", "", "", false, 0, 0)
+	assert.True(t, ErrNotShared.Has(err))
+}
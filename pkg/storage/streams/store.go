@@ -4,6 +4,7 @@
 package streams
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/rand"
@@ -11,6 +12,7 @@ import (
 	"io"
 	"io/ioutil"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -60,20 +62,39 @@ type Store interface {
 	Get(ctx context.Context, path storj.Path) (ranger.Ranger, Meta, error)
 	Put(ctx context.Context, path storj.Path, data io.Reader, metadata []byte, expiration time.Time) (Meta, error)
 	Delete(ctx context.Context, path storj.Path) error
+	Copy(ctx context.Context, src, dst storj.Path) (Meta, error)
 	List(ctx context.Context, prefix, startAfter, endBefore storj.Path, recursive bool, limit int, metaFlags uint32) (items []ListItem, more bool, err error)
 }
 
 // streamStore is a store for streams
 type streamStore struct {
-	segments     segments.Store
-	segmentSize  int64
-	rootKey      *storj.Key
+	segments    segments.Store
+	segmentSize int64
+	rootKey     *storj.Key
+	// shared is the prefix the store has access to, instead of rootKey, if
+	// it's shared
+	shared       *SharedPrefix
 	encBlockSize int
 	cipher       storj.Cipher
+	hideSizes    bool
+	// parallelism is how many segments of a stream are uploaded at once, as
+	// long as they fit in maxBufferMem, if it's set
+	parallelism  int
+	maxBufferMem int64
+	// downloads is how many segments of a stream are downloaded at once,
+	// counting the one read, as long as the ones ahead fit in budget
+	downloads int
+	budget    *eestream.MemoryBudget
 }
 
-// NewStreamStore stuff
-func NewStreamStore(segments segments.Store, segmentSize int64, rootKey *storj.Key, encBlockSize int, cipher storj.Cipher) (Store, error) {
+// NewStreamStore stuff. If hideSizes is true, the last segments of the streams
+// are padded to sizes which hide their exact size from the storage nodes.
+// Up to parallelism segments of a stream are uploaded at once, which are
+// buffered in memory, so fewer are if they don't fit in maxBufferMem, unless
+// it's 0. Up to downloads segments of a stream are downloaded at once; the
+// ones after the segment read are buffered in memory until they're read,
+// which is reserved from budget, unless it's nil.
+func NewStreamStore(segments segments.Store, segmentSize int64, rootKey *storj.Key, encBlockSize int, cipher storj.Cipher, hideSizes bool, parallelism int, maxBufferMem int64, downloads int, budget *eestream.MemoryBudget) (Store, error) {
 	if segmentSize <= 0 {
 		return nil, errs.New("segment size must be larger than 0")
 	}
@@ -90,192 +111,378 @@ func NewStreamStore(segments segments.Store, segmentSize int64, rootKey *storj.K
 		rootKey:      rootKey,
 		encBlockSize: encBlockSize,
 		cipher:       cipher,
+		hideSizes:    hideSizes,
+		parallelism:  parallelism,
+		maxBufferMem: maxBufferMem,
+		downloads:    downloads,
+		budget:       budget,
 	}, nil
 }
 
+type segmentSizeCtxKey struct{}
+
+// WithSegmentSize returns a context with which streams are put in segments of
+// segmentSize bytes, instead of the segment size of the store
+func WithSegmentSize(ctx context.Context, segmentSize int64) context.Context {
+	return context.WithValue(ctx, segmentSizeCtxKey{}, segmentSize)
+}
+
+// withSegmentSize returns the store to put streams with in ctx, which is s
+// with the segment size of ctx, if it has one
+func (s *streamStore) withSegmentSize(ctx context.Context) *streamStore {
+	segmentSize, ok := ctx.Value(segmentSizeCtxKey{}).(int64)
+	if !ok || segmentSize <= 0 || segmentSize == s.segmentSize {
+		return s
+	}
+	sized := *s
+	sized.segmentSize = segmentSize
+	return &sized
+}
+
 // Put breaks up data as it comes in into s.segmentSize length pieces, then
 // store the first piece at s0/<path>, second piece at s1/<path>, and the
 // *last* piece at l/<path>. Store the given metadata, along with the number
-// of segments, in a new protobuf, in the metadata of l/<path>.
+// of segments, in a new protobuf, in the metadata of l/<path>. If ctx has a
+// Resume, the upload continues after its committed segments.
 func (s *streamStore) Put(ctx context.Context, path storj.Path, data io.Reader, metadata []byte, expiration time.Time) (m Meta, err error) {
 	defer mon.Task()(&ctx)(&err)
-	// previously file uploaded?
-	err = s.Delete(ctx, path)
-	if err != nil && !storage.ErrKeyNotFound.Has(err) {
-		//something wrong happened checking for an existing
-		//file with the same name
-		return Meta{}, err
+
+	s = s.withSegmentSize(ctx)
+
+	// the stream at path was already deleted by the upload which is resumed
+	resume := resumeFromContext(ctx)
+	if resume == nil || resume.Committed() == 0 {
+		// previously file uploaded?
+		err = s.Delete(ctx, path)
+		if err != nil && !storage.ErrKeyNotFound.Has(err) {
+			//something wrong happened checking for an existing
+			//file with the same name
+			return Meta{}, err
+		}
 	}
 
 	m, lastSegment, err := s.upload(ctx, path, data, metadata, expiration)
 	if err != nil {
-		s.cancelHandler(context.Background(), lastSegment, path)
+		s.cancelHandler(context.Background(), s.keptSegments(resume), lastSegment, path)
 	}
 
 	return m, err
 }
 
+// keptSegments returns how many segments of a failed upload are kept, which
+// are the ones committed for resuming it, if it can be resumed
+func (s *streamStore) keptSegments(resume *Resume) int64 {
+	if resume == nil {
+		return 0
+	}
+	return resume.Committed()
+}
+
 func (s *streamStore) upload(ctx context.Context, path storj.Path, data io.Reader, metadata []byte, expiration time.Time) (m Meta, lastSegment int64, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	var currentSegment int64
-	var streamSize int64
+	resume := resumeFromContext(ctx)
+	currentSegment := s.keptSegments(resume)
+	streamSize := currentSegment * s.segmentSize
 	var putMeta segments.Meta
 
 	defer func() {
 		select {
 		case <-ctx.Done():
-			s.cancelHandler(context.Background(), currentSegment, path)
+			s.cancelHandler(context.Background(), s.keptSegments(resume), currentSegment, path)
 		default:
 		}
 	}()
 
-	derivedKey, err := encryption.DeriveContentKey(path, s.rootKey)
+	derivedKey, err := s.contentKey(path)
 	if err != nil {
 		return Meta{}, currentSegment, err
 	}
 
-	eofReader := NewEOFReader(data)
-
-	for !eofReader.isEOF() && !eofReader.hasError() {
-		// generate random key for encrypting the segment's content
-		var contentKey storj.Key
-		_, err = rand.Read(contentKey[:])
+	if s.concurrency() > 1 {
+		var size int64
+		putMeta, currentSegment, size, err = s.uploadConcurrently(ctx, path, derivedKey, currentSegment, resume, data, metadata, expiration)
 		if err != nil {
 			return Meta{}, currentSegment, err
 		}
+		streamSize += size
+	} else {
+		eofReader := NewEOFReader(data)
+
+		for !eofReader.isEOF() && !eofReader.hasError() {
+			var size int64
+			putMeta, size, err = s.putSegment(ctx, path, derivedKey, currentSegment,
+				io.LimitReader(eofReader, s.segmentSize), eofReader.isEOF, metadata, expiration)
+			if err != nil {
+				return Meta{}, currentSegment, err
+			}
+			if resume != nil && !eofReader.isEOF() {
+				resume.commit(currentSegment)
+			}
 
-		// Initialize the content nonce with the segment's index incremented by 1.
-		// The increment by 1 is to avoid nonce reuse with the metadata encryption,
-		// which is encrypted with the zero nonce.
-		var contentNonce storj.Nonce
-		_, err := encryption.Increment(&contentNonce, currentSegment+1)
-		if err != nil {
-			return Meta{}, currentSegment, err
+			currentSegment++
+			streamSize += size
 		}
 
-		encrypter, err := encryption.NewEncrypter(s.cipher, &contentKey, &contentNonce, s.encBlockSize)
-		if err != nil {
-			return Meta{}, currentSegment, err
+		if eofReader.hasError() {
+			return Meta{}, currentSegment, eofReader.err
 		}
+	}
 
-		// generate random nonce for encrypting the content key
-		var keyNonce storj.Nonce
-		_, err = rand.Read(keyNonce[:])
-		if err != nil {
-			return Meta{}, currentSegment, err
+	resultMeta := Meta{
+		Modified:   putMeta.Modified,
+		Expiration: expiration,
+		Size:       streamSize,
+		Data:       metadata,
+	}
+
+	return resultMeta, currentSegment, nil
+}
+
+// concurrency returns how many segments of a stream are uploaded at once,
+// which is limited by the memory the segments are buffered in
+func (s *streamStore) concurrency() int {
+	n := s.parallelism
+	if s.maxBufferMem > 0 && s.maxBufferMem/s.segmentSize < int64(n) {
+		n = int(s.maxBufferMem / s.segmentSize)
+	}
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// uploadConcurrently uploads the segments of data as they're read, starting
+// with the segment with index first, with as many uploads running at once as
+// the concurrency of s. Each segment is buffered until it's uploaded, and the
+// last segment is only uploaded once all the others are, since it makes the
+// stream available. The uploaded segments are committed to resume, if it's
+// set. It returns the meta of the last segment, the index after the last
+// segment started, and the size of data.
+func (s *streamStore) uploadConcurrently(ctx context.Context, path storj.Path, derivedKey *storj.Key, first int64, resume *Resume, data io.Reader, metadata []byte, expiration time.Time) (putMeta segments.Meta, started int64, size int64, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	failed := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
 		}
+	}
+	uploadErr := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr
+	}
 
-		encryptedKey, err := encryption.EncryptKey(&contentKey, s.cipher, derivedKey, &keyNonce)
-		if err != nil {
-			return Meta{}, currentSegment, err
+	// a buffer is taken from the slots before a segment is read into it, and
+	// returned once the segment is uploaded
+	slots := make(chan struct{}, s.concurrency())
+	reader := bufio.NewReader(data)
+	started = first
+
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := uploadErr(); err != nil {
+			wg.Wait()
+			return segments.Meta{}, started, 0, err
+		}
+		if err := ctx.Err(); err != nil {
+			wg.Wait()
+			return segments.Meta{}, started, 0, err
 		}
 
-		sizeReader := NewSizeReader(eofReader)
-		segmentReader := io.LimitReader(sizeReader, s.segmentSize)
-		peekReader := segments.NewPeekThresholdReader(segmentReader)
-		largeData, err := peekReader.IsLargerThan(encrypter.InBlockSize())
-		if err != nil {
-			return Meta{}, currentSegment, err
+		buf := make([]byte, s.segmentSize)
+		n, err := io.ReadFull(reader, buf)
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err == nil {
+			_, err = reader.Peek(1)
+			last = err == io.EOF
 		}
-		var transformedReader io.Reader
-		if largeData {
-			paddedReader := eestream.PadReader(ioutil.NopCloser(peekReader), encrypter.InBlockSize())
-			transformedReader = encryption.TransformReader(paddedReader, encrypter, 0)
-		} else {
-			data, err := ioutil.ReadAll(peekReader)
-			if err != nil {
-				return Meta{}, currentSegment, err
+		if err != nil && !last {
+			cancel()
+			wg.Wait()
+			return segments.Meta{}, started, 0, err
+		}
+		segment := bytes.NewReader(buf[:n])
+		index := started
+		started++
+		size += int64(n)
+
+		if last {
+			wg.Wait()
+			if err := uploadErr(); err != nil {
+				return segments.Meta{}, started, 0, err
 			}
-			cipherData, err := encryption.Encrypt(data, s.cipher, &contentKey, &contentNonce)
+			putMeta, _, err = s.putSegment(ctx, path, derivedKey, index, segment,
+				func() bool { return true }, metadata, expiration)
 			if err != nil {
-				return Meta{}, currentSegment, err
+				return segments.Meta{}, started, 0, err
 			}
-			transformedReader = bytes.NewReader(cipherData)
+			return putMeta, started, size, nil
 		}
 
-		putMeta, err = s.segments.Put(ctx, transformedReader, expiration, func() (storj.Path, []byte, error) {
-			encPath, err := encryptAfterBucket(path, s.rootKey)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			_, _, err := s.putSegment(ctx, path, derivedKey, index, segment,
+				func() bool { return false }, metadata, expiration)
 			if err != nil {
-				return "", nil, err
+				failed(err)
+				return
 			}
+			if resume != nil {
+				resume.commit(index)
+			}
+		}()
+	}
+}
 
-			if !eofReader.isEOF() {
-				segmentPath := getSegmentPath(encPath, currentSegment)
+// putSegment encrypts and uploads the segment of the stream at path with
+// index, which is the last segment of the stream if isLast returns true once
+// data is read. The last segment keeps the info of the stream, with its
+// metadata. It returns the meta of the segment and its size.
+func (s *streamStore) putSegment(ctx context.Context, path storj.Path, derivedKey *storj.Key, index int64, data io.Reader, isLast func() bool, metadata []byte, expiration time.Time) (putMeta segments.Meta, size int64, err error) {
+	// generate random key for encrypting the segment's content
+	var contentKey storj.Key
+	_, err = rand.Read(contentKey[:])
+	if err != nil {
+		return segments.Meta{}, 0, err
+	}
 
-				if s.cipher == storj.Unencrypted {
-					return segmentPath, nil, nil
-				}
+	// Initialize the content nonce with the segment's index incremented by 1.
+	// The increment by 1 is to avoid nonce reuse with the metadata encryption,
+	// which is encrypted with the zero nonce.
+	var contentNonce storj.Nonce
+	_, err = encryption.Increment(&contentNonce, index+1)
+	if err != nil {
+		return segments.Meta{}, 0, err
+	}
 
-				segmentMeta, err := proto.Marshal(&pb.SegmentMeta{
-					EncryptedKey: encryptedKey,
-					KeyNonce:     keyNonce[:],
-				})
-				if err != nil {
-					return "", nil, err
-				}
+	encrypter, err := encryption.NewEncrypter(s.cipher, &contentKey, &contentNonce, s.encBlockSize)
+	if err != nil {
+		return segments.Meta{}, 0, err
+	}
 
-				return segmentPath, segmentMeta, nil
-			}
+	// generate random nonce for encrypting the content key
+	var keyNonce storj.Nonce
+	_, err = rand.Read(keyNonce[:])
+	if err != nil {
+		return segments.Meta{}, 0, err
+	}
 
-			lastSegmentPath := storj.JoinPaths("l", encPath)
+	encryptedKey, err := encryption.EncryptKey(&contentKey, s.cipher, derivedKey, &keyNonce)
+	if err != nil {
+		return segments.Meta{}, 0, err
+	}
 
-			streamInfo, err := proto.Marshal(&pb.StreamInfo{
-				NumberOfSegments: currentSegment + 1,
-				SegmentsSize:     s.segmentSize,
-				LastSegmentSize:  sizeReader.Size(),
-				Metadata:         metadata,
-			})
-			if err != nil {
-				return "", nil, err
-			}
+	sizeReader := NewSizeReader(data)
+	peekReader := segments.NewPeekThresholdReader(sizeReader)
+	largeData, err := peekReader.IsLargerThan(encrypter.InBlockSize())
+	if err != nil {
+		return segments.Meta{}, 0, err
+	}
+	var transformedReader io.Reader
+	if largeData {
+		var paddedReader io.ReadCloser
+		if s.hideSizes {
+			// the true size of the last segment is kept in the
+			// encrypted stream info, so the padding is removed on reads
+			paddedReader = eestream.PadReaderHidingSize(ioutil.NopCloser(peekReader), encrypter.InBlockSize(), s.segmentSize)
+		} else {
+			paddedReader = eestream.PadReader(ioutil.NopCloser(peekReader), encrypter.InBlockSize())
+		}
+		transformedReader = encryption.TransformReader(paddedReader, encrypter, 0)
+	} else {
+		data, err := ioutil.ReadAll(peekReader)
+		if err != nil {
+			return segments.Meta{}, 0, err
+		}
+		cipherData, err := encryption.Encrypt(data, s.cipher, &contentKey, &contentNonce)
+		if err != nil {
+			return segments.Meta{}, 0, err
+		}
+		transformedReader = bytes.NewReader(cipherData)
+	}
 
-			// encrypt metadata with the content encryption key and zero nonce
-			encryptedStreamInfo, err := encryption.Encrypt(streamInfo, s.cipher, &contentKey, &storj.Nonce{})
-			if err != nil {
-				return "", nil, err
-			}
+	putMeta, err = s.segments.Put(ctx, transformedReader, expiration, func() (storj.Path, []byte, error) {
+		encPath, err := s.encryptPath(path)
+		if err != nil {
+			return "", nil, err
+		}
 
-			streamMeta := pb.StreamMeta{
-				EncryptedStreamInfo: encryptedStreamInfo,
-				EncryptionType:      int32(s.cipher),
-				EncryptionBlockSize: int32(s.encBlockSize),
-			}
+		if !isLast() {
+			segmentPath := getSegmentPath(encPath, index)
 
-			if s.cipher != storj.Unencrypted {
-				streamMeta.LastSegmentMeta = &pb.SegmentMeta{
-					EncryptedKey: encryptedKey,
-					KeyNonce:     keyNonce[:],
-				}
+			if s.cipher == storj.Unencrypted {
+				return segmentPath, nil, nil
 			}
 
-			lastSegmentMeta, err := proto.Marshal(&streamMeta)
+			segmentMeta, err := proto.Marshal(&pb.SegmentMeta{
+				EncryptedKey: encryptedKey,
+				KeyNonce:     keyNonce[:],
+			})
 			if err != nil {
 				return "", nil, err
 			}
 
-			return lastSegmentPath, lastSegmentMeta, nil
+			return segmentPath, segmentMeta, nil
+		}
+
+		lastSegmentPath := storj.JoinPaths("l", encPath)
+
+		streamInfo, err := proto.Marshal(&pb.StreamInfo{
+			NumberOfSegments: index + 1,
+			SegmentsSize:     s.segmentSize,
+			LastSegmentSize:  sizeReader.Size(),
+			Metadata:         metadata,
 		})
 		if err != nil {
-			return Meta{}, currentSegment, err
+			return "", nil, err
 		}
 
-		currentSegment++
-		streamSize += sizeReader.Size()
-	}
+		// encrypt metadata with the content encryption key and zero nonce
+		encryptedStreamInfo, err := encryption.Encrypt(streamInfo, s.cipher, &contentKey, &storj.Nonce{})
+		if err != nil {
+			return "", nil, err
+		}
 
-	if eofReader.hasError() {
-		return Meta{}, currentSegment, eofReader.err
-	}
+		streamMeta := pb.StreamMeta{
+			EncryptedStreamInfo: encryptedStreamInfo,
+			EncryptionType:      int32(s.cipher),
+			EncryptionBlockSize: int32(s.encBlockSize),
+		}
 
-	resultMeta := Meta{
-		Modified:   putMeta.Modified,
-		Expiration: expiration,
-		Size:       streamSize,
-		Data:       metadata,
+		if s.cipher != storj.Unencrypted {
+			streamMeta.LastSegmentMeta = &pb.SegmentMeta{
+				EncryptedKey: encryptedKey,
+				KeyNonce:     keyNonce[:],
+			}
+		}
+
+		lastSegmentMeta, err := proto.Marshal(&streamMeta)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return lastSegmentPath, lastSegmentMeta, nil
+	})
+	if err != nil {
+		return segments.Meta{}, 0, err
 	}
 
-	return resultMeta, currentSegment, nil
+	return putMeta, sizeReader.Size(), nil
 }
 
 // getSegmentPath returns the unique path for a particular segment
@@ -289,7 +496,7 @@ func getSegmentPath(path storj.Path, segNum int64) storj.Path {
 func (s *streamStore) Get(ctx context.Context, path storj.Path) (rr ranger.Ranger, meta Meta, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	encPath, err := encryptAfterBucket(path, s.rootKey)
+	encPath, err := s.encryptPath(path)
 	if err != nil {
 		return nil, Meta{}, err
 	}
@@ -299,7 +506,7 @@ func (s *streamStore) Get(ctx context.Context, path storj.Path) (rr ranger.Range
 		return nil, Meta{}, err
 	}
 
-	streamInfo, err := decryptStreamInfo(ctx, lastSegmentMeta, path, s.rootKey)
+	streamInfo, err := s.decryptStreamInfo(ctx, lastSegmentMeta, path)
 	if err != nil {
 		return nil, Meta{}, err
 	}
@@ -316,7 +523,7 @@ func (s *streamStore) Get(ctx context.Context, path storj.Path) (rr ranger.Range
 		return nil, Meta{}, err
 	}
 
-	derivedKey, err := encryption.DeriveContentKey(path, s.rootKey)
+	derivedKey, err := s.contentKey(path)
 	if err != nil {
 		return nil, Meta{}, err
 	}
@@ -364,7 +571,7 @@ func (s *streamStore) Get(ctx context.Context, path storj.Path) (rr ranger.Range
 	}
 	rangers = append(rangers, decryptedLastSegmentRanger)
 
-	catRangers := ranger.Concat(rangers...)
+	catRangers := newPrefetchRanger(rangers, s.downloads, s.budget)
 
 	lastSegmentMeta.Data = streamInfo
 	meta, err = convertMeta(lastSegmentMeta)
@@ -379,7 +586,7 @@ func (s *streamStore) Get(ctx context.Context, path storj.Path) (rr ranger.Range
 func (s *streamStore) Meta(ctx context.Context, path storj.Path) (meta Meta, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	encPath, err := encryptAfterBucket(path, s.rootKey)
+	encPath, err := s.encryptPath(path)
 	if err != nil {
 		return Meta{}, err
 	}
@@ -389,7 +596,7 @@ func (s *streamStore) Meta(ctx context.Context, path storj.Path) (meta Meta, err
 		return Meta{}, err
 	}
 
-	streamInfo, err := decryptStreamInfo(ctx, lastSegmentMeta, path, s.rootKey)
+	streamInfo, err := s.decryptStreamInfo(ctx, lastSegmentMeta, path)
 	if err != nil {
 		return Meta{}, err
 	}
@@ -407,7 +614,7 @@ func (s *streamStore) Meta(ctx context.Context, path storj.Path) (meta Meta, err
 func (s *streamStore) Delete(ctx context.Context, path storj.Path) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	encPath, err := encryptAfterBucket(path, s.rootKey)
+	encPath, err := s.encryptPath(path)
 	if err != nil {
 		return err
 	}
@@ -416,7 +623,7 @@ func (s *streamStore) Delete(ctx context.Context, path storj.Path) (err error) {
 		return err
 	}
 
-	streamInfo, err := decryptStreamInfo(ctx, lastSegmentMeta, path, s.rootKey)
+	streamInfo, err := s.decryptStreamInfo(ctx, lastSegmentMeta, path)
 	if err != nil {
 		return err
 	}
@@ -428,7 +635,7 @@ func (s *streamStore) Delete(ctx context.Context, path storj.Path) (err error) {
 	}
 
 	for i := 0; i < int(stream.NumberOfSegments-1); i++ {
-		encPath, err = encryptAfterBucket(path, s.rootKey)
+		encPath, err = s.encryptPath(path)
 		if err != nil {
 			return err
 		}
@@ -442,6 +649,143 @@ func (s *streamStore) Delete(ctx context.Context, path storj.Path) (err error) {
 	return s.segments.Delete(ctx, storj.JoinPaths("l", encPath))
 }
 
+// Copy copies the stream at src to dst, keeping its metadata. Only the
+// pointers of the segments are copied, sharing the data of the segments,
+// with their content keys encrypted again with the key of dst. The metadata
+// can't be changed, as the stream info is encrypted with the content key of
+// the last segment and the zero nonce, which can't be reused.
+func (s *streamStore) Copy(ctx context.Context, src, dst storj.Path) (meta Meta, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	srcPath, err := s.encryptPath(src)
+	if err != nil {
+		return Meta{}, err
+	}
+	dstPath, err := s.encryptPath(dst)
+	if err != nil {
+		return Meta{}, err
+	}
+	srcKey, err := s.contentKey(src)
+	if err != nil {
+		return Meta{}, err
+	}
+	dstKey, err := s.contentKey(dst)
+	if err != nil {
+		return Meta{}, err
+	}
+
+	lastSegmentMeta, err := s.segments.Meta(ctx, storj.JoinPaths("l", srcPath))
+	if err != nil {
+		return Meta{}, err
+	}
+
+	streamInfo, err := s.decryptStreamInfo(ctx, lastSegmentMeta, src)
+	if err != nil {
+		return Meta{}, err
+	}
+
+	stream := pb.StreamInfo{}
+	err = proto.Unmarshal(streamInfo, &stream)
+	if err != nil {
+		return Meta{}, err
+	}
+
+	streamMeta := pb.StreamMeta{}
+	err = proto.Unmarshal(lastSegmentMeta.Data, &streamMeta)
+	if err != nil {
+		return Meta{}, err
+	}
+
+	err = s.copySegments(ctx, srcPath, dstPath, dst, srcKey, dstKey, stream.NumberOfSegments, &streamMeta)
+	if err != nil {
+		return Meta{}, err
+	}
+
+	return s.Meta(ctx, dst)
+}
+
+// copySegments copies the segments of the stream at the encrypted srcPath to
+// the encrypted dstPath of dst, encrypting their content keys with dstKey
+// instead of srcKey. The last segment, whose metadata is streamMeta, is
+// copied last, so the copy is only found once all of its segments are.
+func (s *streamStore) copySegments(ctx context.Context, srcPath, dstPath, dst storj.Path, srcKey, dstKey *storj.Key, numberOfSegments int64, streamMeta *pb.StreamMeta) (err error) {
+	cipher := storj.Cipher(streamMeta.EncryptionType)
+
+	var copied int64
+	defer func() {
+		// deleting the copied segments only deletes their pointers, as their
+		// pieces are shared with the segments of src
+		if err != nil {
+			s.cancelHandler(context.Background(), 0, copied, dst)
+		}
+	}()
+
+	for ; copied < numberOfSegments-1; copied++ {
+		segmentMeta, err := s.segments.Meta(ctx, getSegmentPath(srcPath, copied))
+		if err != nil {
+			return err
+		}
+
+		metadata := segmentMeta.Data
+		if len(metadata) > 0 {
+			segment := pb.SegmentMeta{}
+			err = proto.Unmarshal(metadata, &segment)
+			if err != nil {
+				return err
+			}
+			err = reencryptKey(&segment, cipher, srcKey, dstKey)
+			if err != nil {
+				return err
+			}
+			metadata, err = proto.Marshal(&segment)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = s.segments.Copy(ctx, getSegmentPath(srcPath, copied), getSegmentPath(dstPath, copied), metadata)
+		if err != nil {
+			return err
+		}
+	}
+
+	if streamMeta.LastSegmentMeta != nil {
+		err = reencryptKey(streamMeta.LastSegmentMeta, cipher, srcKey, dstKey)
+		if err != nil {
+			return err
+		}
+	}
+	metadata, err := proto.Marshal(streamMeta)
+	if err != nil {
+		return err
+	}
+
+	return s.segments.Copy(ctx, storj.JoinPaths("l", srcPath), storj.JoinPaths("l", dstPath), metadata)
+}
+
+// reencryptKey encrypts the content key of segment, which is encrypted with
+// srcKey, with dstKey instead, with a new random nonce
+func reencryptKey(segment *pb.SegmentMeta, cipher storj.Cipher, srcKey, dstKey *storj.Key) error {
+	encryptedKey, keyNonce := getEncryptedKeyAndNonce(segment)
+	contentKey, err := encryption.DecryptKey(encryptedKey, cipher, srcKey, keyNonce)
+	if err != nil {
+		return err
+	}
+
+	var nonce storj.Nonce
+	_, err = rand.Read(nonce[:])
+	if err != nil {
+		return err
+	}
+
+	segment.EncryptedKey, err = encryption.EncryptKey(contentKey, cipher, dstKey, &nonce)
+	if err != nil {
+		return err
+	}
+	segment.KeyNonce = nonce[:]
+	return nil
+}
+
 // ListItem is a single item in a listing
 type ListItem struct {
 	Path     storj.Path
@@ -461,22 +805,22 @@ func (s *streamStore) List(ctx context.Context, prefix, startAfter, endBefore st
 
 	prefix = strings.TrimSuffix(prefix, "/")
 
-	encPrefix, err := encryptAfterBucket(prefix, s.rootKey)
+	encPrefix, err := s.encryptPath(prefix)
 	if err != nil {
 		return nil, false, err
 	}
 
-	prefixKey, err := encryption.DerivePathKey(prefix, s.rootKey, len(storj.SplitPath(prefix)))
+	prefixKey, err := s.prefixKey(prefix)
 	if err != nil {
 		return nil, false, err
 	}
 
-	encStartAfter, err := s.encryptMarker(startAfter, prefixKey)
+	encStartAfter, err := s.encryptMarker(startAfter, prefix, prefixKey)
 	if err != nil {
 		return nil, false, err
 	}
 
-	encEndBefore, err := s.encryptMarker(endBefore, prefixKey)
+	encEndBefore, err := s.encryptMarker(endBefore, prefix, prefixKey)
 	if err != nil {
 		return nil, false, err
 	}
@@ -488,12 +832,12 @@ func (s *streamStore) List(ctx context.Context, prefix, startAfter, endBefore st
 
 	items = make([]ListItem, len(segments))
 	for i, item := range segments {
-		path, err := s.decryptMarker(item.Path, prefixKey)
+		path, err := s.decryptMarker(item.Path, prefix, prefixKey)
 		if err != nil {
 			return nil, false, err
 		}
 
-		streamInfo, err := decryptStreamInfo(ctx, item.Meta, storj.JoinPaths(prefix, path), s.rootKey)
+		streamInfo, err := s.decryptStreamInfo(ctx, item.Meta, storj.JoinPaths(prefix, path))
 		if err != nil {
 			return nil, false, err
 		}
@@ -510,17 +854,25 @@ func (s *streamStore) List(ctx context.Context, prefix, startAfter, endBefore st
 	return items, more, nil
 }
 
-// encryptMarker is a helper method for encrypting startAfter and endBefore markers
-func (s *streamStore) encryptMarker(marker storj.Path, prefixKey *storj.Key) (storj.Path, error) {
-	if bytes.Equal(s.rootKey[:], prefixKey[:]) { // empty prefix
-		return encryptAfterBucket(marker, s.rootKey)
+// encryptMarker is a helper method for encrypting startAfter and endBefore
+// markers. The markers of prefixes keep their trailing slash, so they're
+// encrypted like the prefixes they were listed as.
+func (s *streamStore) encryptMarker(marker, prefix storj.Path, prefixKey *storj.Key) (encrypted storj.Path, err error) {
+	trimmed := strings.TrimSuffix(marker, "/")
+	if prefix == "" {
+		encrypted, err = encryptAfterBucket(trimmed, s.rootKey)
+	} else {
+		encrypted, err = encryption.EncryptPath(trimmed, prefixKey)
+	}
+	if err != nil || trimmed == marker {
+		return encrypted, err
 	}
-	return encryption.EncryptPath(marker, prefixKey)
+	return encrypted + "/", nil
 }
 
 // decryptMarker is a helper method for decrypting listed path markers
-func (s *streamStore) decryptMarker(marker storj.Path, prefixKey *storj.Key) (storj.Path, error) {
-	if bytes.Equal(s.rootKey[:], prefixKey[:]) { // empty prefix
+func (s *streamStore) decryptMarker(marker, prefix storj.Path, prefixKey *storj.Key) (storj.Path, error) {
+	if prefix == "" {
 		return decryptAfterBucket(marker, s.rootKey)
 	}
 	return encryption.DecryptPath(marker, prefixKey)
@@ -599,6 +951,30 @@ func decryptRanger(ctx context.Context, rr ranger.Ranger, decryptedSize int64, c
 	return eestream.Unpad(rd, int(rd.Size()-decryptedSize))
 }
 
+// encryptPath encrypts path, except its bucket
+func (s *streamStore) encryptPath(path storj.Path) (storj.Path, error) {
+	if s.shared != nil {
+		return s.shared.encryptPath(path)
+	}
+	return encryptAfterBucket(path, s.rootKey)
+}
+
+// contentKey derives the key the content at path is encrypted with
+func (s *streamStore) contentKey(path storj.Path) (*storj.Key, error) {
+	if s.shared != nil {
+		return s.shared.contentKey(path)
+	}
+	return encryption.DeriveContentKey(path, s.rootKey)
+}
+
+// prefixKey derives the key the paths under prefix are encrypted with
+func (s *streamStore) prefixKey(prefix storj.Path) (*storj.Key, error) {
+	if s.shared != nil {
+		return s.shared.prefixKey(prefix)
+	}
+	return encryption.DerivePathKey(prefix, s.rootKey, len(storj.SplitPath(prefix)))
+}
+
 // encryptAfterBucket encrypts a path without encrypting its first element
 func encryptAfterBucket(path storj.Path, key *storj.Key) (encrypted storj.Path, err error) {
 	comps := storj.SplitPath(path)
@@ -606,13 +982,17 @@ func encryptAfterBucket(path storj.Path, key *storj.Key) (encrypted storj.Path,
 		return path, nil
 	}
 
-	encrypted, err = encryption.EncryptPath(path, key)
+	bucketKey, err := encryption.DeriveBucketKey(comps[0], key)
+	if err != nil {
+		return "", err
+	}
+
+	encPath, err := encryption.EncryptPath(storj.JoinPaths(comps[1:]...), bucketKey)
 	if err != nil {
 		return "", err
 	}
 
-	// replace the first path component with the unencrypted bucket name
-	return storj.JoinPaths(comps[0], storj.JoinPaths(storj.SplitPath(encrypted)[1:]...)), nil
+	return storj.JoinPaths(comps[0], encPath), nil
 }
 
 // decryptAfterBucket decrypts a path without modifying its first element
@@ -622,26 +1002,24 @@ func decryptAfterBucket(path storj.Path, key *storj.Key) (decrypted storj.Path,
 		return path, nil
 	}
 
-	bucket := comps[0]
-	toDecrypt := storj.JoinPaths(comps[1:]...)
-
-	bucketKey, err := encryption.DerivePathKey(path, key, 1)
+	bucketKey, err := encryption.DeriveBucketKey(comps[0], key)
 	if err != nil {
 		return "", err
 	}
 
-	decPath, err := encryption.DecryptPath(toDecrypt, bucketKey)
+	decPath, err := encryption.DecryptPath(storj.JoinPaths(comps[1:]...), bucketKey)
 	if err != nil {
 		return "", err
 	}
 
-	return storj.JoinPaths(bucket, decPath), nil
+	return storj.JoinPaths(comps[0], decPath), nil
 }
 
-// CancelHandler handles clean up of segments on receiving CTRL+C
-func (s *streamStore) cancelHandler(ctx context.Context, totalSegments int64, path storj.Path) {
-	for i := int64(0); i < totalSegments; i++ {
-		encPath, err := encryptAfterBucket(path, s.rootKey)
+// CancelHandler handles clean up of segments on receiving CTRL+C, deleting
+// the segments from first until totalSegments
+func (s *streamStore) cancelHandler(ctx context.Context, first, totalSegments int64, path storj.Path) {
+	for i := first; i < totalSegments; i++ {
+		encPath, err := s.encryptPath(path)
 		if err != nil {
 			zap.S().Warnf("Failed deleting a segment due to encryption path %v %v", i, err)
 		}
@@ -665,14 +1043,14 @@ func getEncryptedKeyAndNonce(m *pb.SegmentMeta) (storj.EncryptedPrivateKey, *sto
 	return m.EncryptedKey, &nonce
 }
 
-func decryptStreamInfo(ctx context.Context, item segments.Meta, path storj.Path, rootKey *storj.Key) (streamInfo []byte, err error) {
+func (s *streamStore) decryptStreamInfo(ctx context.Context, item segments.Meta, path storj.Path) (streamInfo []byte, err error) {
 	streamMeta := pb.StreamMeta{}
 	err = proto.Unmarshal(item.Data, &streamMeta)
 	if err != nil {
 		return nil, err
 	}
 
-	derivedKey, err := encryption.DeriveContentKey(path, rootKey)
+	derivedKey, err := s.contentKey(path)
 	if err != nil {
 		return nil, err
 	}
@@ -4,21 +4,29 @@
 package streams
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
+	"github.com/zeebo/errs"
 
+	"storj.io/storj/pkg/encryption"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/ranger"
+	"storj.io/storj/pkg/storage/meta"
 	"storj.io/storj/pkg/storage/segments"
 	"storj.io/storj/pkg/storj"
+	"storj.io/storj/storage"
 )
 
 var (
@@ -91,7 +99,7 @@ func TestStreamStoreMeta(t *testing.T) {
 			Meta(gomock.Any(), gomock.Any()).
 			Return(test.segmentMeta, test.segmentError)
 
-		streamStore, err := NewStreamStore(mockSegmentStore, 10, new(storj.Key), 10, 0)
+		streamStore, err := NewStreamStore(mockSegmentStore, 10, new(storj.Key), 10, 0, false, 1, 0, 1, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -163,7 +171,7 @@ func TestStreamStorePut(t *testing.T) {
 			Delete(gomock.Any(), gomock.Any()).
 			Return(test.segmentError)
 
-		streamStore, err := NewStreamStore(mockSegmentStore, 10, new(storj.Key), 10, 0)
+		streamStore, err := NewStreamStore(mockSegmentStore, 10, new(storj.Key), 10, 0, false, 1, 0, 1, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -263,7 +271,7 @@ func TestStreamStoreGet(t *testing.T) {
 
 		gomock.InOrder(calls...)
 
-		streamStore, err := NewStreamStore(mockSegmentStore, 10, new(storj.Key), 10, 0)
+		streamStore, err := NewStreamStore(mockSegmentStore, 10, new(storj.Key), 10, 0, false, 1, 0, 1, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -312,7 +320,7 @@ func TestStreamStoreDelete(t *testing.T) {
 			Delete(gomock.Any(), gomock.Any()).
 			Return(test.segmentError)
 
-		streamStore, err := NewStreamStore(mockSegmentStore, 10, new(storj.Key), 10, 0)
+		streamStore, err := NewStreamStore(mockSegmentStore, 10, new(storj.Key), 10, 0, false, 1, 0, 1, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -356,7 +364,7 @@ func TestStreamStoreList(t *testing.T) {
 			List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(test.segments, test.segmentMore, test.segmentError)
 
-		streamStore, err := NewStreamStore(mockSegmentStore, 10, new(storj.Key), 10, 0)
+		streamStore, err := NewStreamStore(mockSegmentStore, 10, new(storj.Key), 10, 0, false, 1, 0, 1, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -370,3 +378,339 @@ func TestStreamStoreList(t *testing.T) {
 		assert.Equal(t, test.streamMore, more, errTag)
 	}
 }
+
+// memSegments keeps the segments put in memory, tracking how many are put at
+// once, and fails the puts once failAfter segments were put, if it's set
+type memSegments struct {
+	segments.Store
+
+	mu        sync.Mutex
+	data      map[storj.Path][]byte
+	meta      map[storj.Path][]byte
+	order     []storj.Path
+	running   int
+	max       int
+	failAfter int
+}
+
+func newMemSegments() *memSegments {
+	return &memSegments{data: map[storj.Path][]byte{}, meta: map[storj.Path][]byte{}}
+}
+
+func (m *memSegments) Put(ctx context.Context, data io.Reader, expiration time.Time, segmentInfo func() (storj.Path, []byte, error)) (segments.Meta, error) {
+	m.mu.Lock()
+	m.running++
+	if m.running > m.max {
+		m.max = m.running
+	}
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.running--
+		m.mu.Unlock()
+	}()
+
+	// give the other uploads the chance to start
+	time.Sleep(10 * time.Millisecond)
+
+	buf, err := ioutil.ReadAll(data)
+	if err != nil {
+		return segments.Meta{}, err
+	}
+	path, meta, err := segmentInfo()
+	if err != nil {
+		return segments.Meta{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failAfter > 0 && len(m.order) >= m.failAfter {
+		return segments.Meta{}, errs.New("put failed")
+	}
+	m.data[path] = buf
+	m.meta[path] = meta
+	m.order = append(m.order, path)
+	return segments.Meta{Data: meta}, nil
+}
+
+func (m *memSegments) Get(ctx context.Context, path storj.Path) (ranger.Ranger, segments.Meta, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.data[path]
+	if !ok {
+		return nil, segments.Meta{}, storage.ErrKeyNotFound.New("%s", path)
+	}
+	return ranger.ByteRanger(data), segments.Meta{Data: m.meta[path]}, nil
+}
+
+func (m *memSegments) Meta(ctx context.Context, path storj.Path) (segments.Meta, error) {
+	_, meta, err := m.Get(ctx, path)
+	return meta, err
+}
+
+func (m *memSegments) Delete(ctx context.Context, path storj.Path) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, path)
+	delete(m.meta, path)
+	return nil
+}
+
+func (m *memSegments) Copy(ctx context.Context, src, dst storj.Path, metadata []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.data[src]
+	if !ok {
+		return storage.ErrKeyNotFound.New("%s", src)
+	}
+	m.data[dst] = data
+	m.meta[dst] = metadata
+	return nil
+}
+
+func TestStreamStorePutConcurrently(t *testing.T) {
+	data := make([]byte, 950)
+	_, err := rand.Read(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		parallelism  int
+		maxBufferMem int64
+		concurrency  int
+	}{
+		{1, 0, 1},
+		{3, 0, 3},
+		{3, 200, 2},
+		{3, 50, 1},
+	} {
+		errTag := fmt.Sprintf("parallelism %d, max buffer memory %d", test.parallelism, test.maxBufferMem)
+
+		mem := newMemSegments()
+		streamStore, err := NewStreamStore(mem, 100, new(storj.Key), 1024, storj.AESGCM, false, test.parallelism, test.maxBufferMem, test.parallelism, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		meta, err := streamStore.Put(ctx, "bucket/object", bytes.NewReader(data), []byte("metadata"), time.Time{})
+		if !assert.NoError(t, err, errTag) {
+			continue
+		}
+		assert.Equal(t, int64(len(data)), meta.Size, errTag)
+		assert.Equal(t, test.concurrency, mem.max, errTag)
+
+		// the last segment is only put once all the others are
+		assert.Len(t, mem.order, 10, errTag)
+		assert.True(t, strings.HasPrefix(mem.order[9], "l/"), errTag)
+
+		rr, meta, err := streamStore.Get(ctx, "bucket/object")
+		if !assert.NoError(t, err, errTag) {
+			continue
+		}
+		assert.Equal(t, []byte("metadata"), meta.Data, errTag)
+		r, err := rr.Range(ctx, 0, rr.Size())
+		if !assert.NoError(t, err, errTag) {
+			continue
+		}
+		downloaded, err := ioutil.ReadAll(r)
+		assert.NoError(t, err, errTag)
+		assert.Equal(t, data, downloaded, errTag)
+	}
+
+	// a failed segment fails the stream, without putting its last segment
+	mem := newMemSegments()
+	mem.failAfter = 2
+	streamStore, err := NewStreamStore(mem, 100, new(storj.Key), 1024, storj.AESGCM, false, 3, 0, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = streamStore.Put(ctx, "bucket/object", bytes.NewReader(data), nil, time.Time{})
+	assert.Error(t, err)
+	for _, path := range mem.order {
+		assert.False(t, strings.HasPrefix(path, "l/"))
+	}
+}
+
+func TestStreamStoreResume(t *testing.T) {
+	data := make([]byte, 950)
+	_, err := rand.Read(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, parallelism := range []int{1, 3} {
+		errTag := fmt.Sprintf("parallelism %d", parallelism)
+
+		mem := newMemSegments()
+		mem.failAfter = 4
+		streamStore, err := NewStreamStore(mem, 100, new(storj.Key), 1024, storj.AESGCM, false, parallelism, 0, 1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the committed segments of an interrupted upload are kept
+		var commits []int64
+		resume := NewResume(0, func(committed int64) { commits = append(commits, committed) })
+		_, err = streamStore.Put(WithResume(ctx, resume), "bucket/object", bytes.NewReader(data), nil, time.Time{})
+		assert.Error(t, err, errTag)
+		committed := resume.Committed()
+		assert.True(t, committed > 0 && committed <= 4, errTag)
+		assert.Equal(t, committed, commits[len(commits)-1], errTag)
+		for i := int64(0); i < committed; i++ {
+			_, err := mem.Meta(ctx, getSegmentPath(mem.order[0][len("s0/"):], i))
+			assert.NoError(t, err, errTag)
+		}
+
+		// and the upload continues after them
+		mem.failAfter = 0
+		resume = NewResume(committed, nil)
+		meta, err := streamStore.Put(WithResume(ctx, resume), "bucket/object", bytes.NewReader(data[committed*100:]), nil, time.Time{})
+		if !assert.NoError(t, err, errTag) {
+			continue
+		}
+		assert.Equal(t, int64(len(data)), meta.Size, errTag)
+		assert.Equal(t, int64(9), resume.Committed(), errTag)
+
+		rr, _, err := streamStore.Get(ctx, "bucket/object")
+		if !assert.NoError(t, err, errTag) {
+			continue
+		}
+		r, err := rr.Range(ctx, 0, rr.Size())
+		if !assert.NoError(t, err, errTag) {
+			continue
+		}
+		downloaded, err := ioutil.ReadAll(r)
+		assert.NoError(t, err, errTag)
+		assert.Equal(t, data, downloaded, errTag)
+	}
+}
+
+func TestStreamStoreSegmentSize(t *testing.T) {
+	data := make([]byte, 950)
+	_, err := rand.Read(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mem := newMemSegments()
+	streamStore, err := NewStreamStore(mem, 100, new(storj.Key), 1024, storj.AESGCM, false, 1, 0, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the segment size of the context replaces the one of the store
+	meta, err := streamStore.Put(WithSegmentSize(ctx, 300), "bucket/object", bytes.NewReader(data), nil, time.Time{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(len(data)), meta.Size)
+	assert.Len(t, mem.order, 4)
+
+	rr, _, err := streamStore.Get(ctx, "bucket/object")
+	if !assert.NoError(t, err) {
+		return
+	}
+	r, err := rr.Range(ctx, 0, rr.Size())
+	if !assert.NoError(t, err) {
+		return
+	}
+	downloaded, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, data, downloaded)
+}
+
+func TestStreamStoreCopy(t *testing.T) {
+	data := make([]byte, 950)
+	_, err := rand.Read(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mem := newMemSegments()
+	streamStore, err := NewStreamStore(mem, 300, new(storj.Key), 1024, storj.AESGCM, false, 1, 0, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = streamStore.Put(ctx, "bucket/object", bytes.NewReader(data), []byte("metadata"), time.Time{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	put := len(mem.order)
+
+	meta, err := streamStore.Copy(ctx, "bucket/object", "bucket/dir/copy")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(len(data)), meta.Size)
+	assert.Equal(t, []byte("metadata"), meta.Data)
+	// the segments are copied without being put again
+	assert.Len(t, mem.order, put)
+
+	// the copy is decrypted with its own path
+	rr, _, err := streamStore.Get(ctx, "bucket/dir/copy")
+	if !assert.NoError(t, err) {
+		return
+	}
+	r, err := rr.Range(ctx, 0, rr.Size())
+	if !assert.NoError(t, err) {
+		return
+	}
+	downloaded, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, data, downloaded)
+
+	_, err = streamStore.Copy(ctx, "bucket/missing", "bucket/copy")
+	assert.True(t, storage.ErrKeyNotFound.Has(err))
+}
+
+func TestStreamStoreListAfterPrefix(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSegmentStore := segments.NewMockStore(ctrl)
+	streamStore, err := NewStreamStore(mockSegmentStore, 10, new(storj.Key), 10, 0, false, 1, 0, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var markers []storj.Path
+	mockSegmentStore.EXPECT().
+		List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, prefix, startAfter, endBefore storj.Path, recursive bool, limit int, metaFlags uint32) {
+			markers = append(markers, startAfter)
+		}).
+		Return(nil, false, nil).Times(2)
+
+	// the marker of a prefix is encrypted like the prefix was listed
+	for _, startAfter := range []string{"dir", "dir/"} {
+		_, _, err := streamStore.List(ctx, "bucket/path", startAfter, "", false, 0, meta.None)
+		assert.NoError(t, err)
+	}
+	if assert.Len(t, markers, 2) {
+		assert.Equal(t, markers[0]+"/", markers[1])
+	}
+}
+
+func TestEncryptAfterBucket(t *testing.T) {
+	key := new(storj.Key)
+	copy(key[:], "root key")
+
+	encrypted, err := encryptAfterBucket("bucket/a/b", key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// paths stay encrypted the way they were before bucket keys, as the
+	// whole path with the root key, with the bucket name left in the clear
+	legacy, err := encryption.EncryptPath("bucket/a/b", key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, storj.JoinPaths(append([]string{"bucket"}, storj.SplitPath(legacy)[1:]...)...), encrypted)
+
+	decrypted, err := decryptAfterBucket(encrypted, key)
+	assert.NoError(t, err)
+	assert.Equal(t, "bucket/a/b", decrypted)
+}
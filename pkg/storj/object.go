@@ -9,6 +9,12 @@ import "time"
 type Bucket struct {
 	Name    string
 	Created time.Time
+	// RedundancyScheme specifies redundancy strategy used for the objects of
+	// this bucket. It's zero for buckets using the scheme of the uplink.
+	RedundancyScheme
+	// SegmentSize is the size of the segments of the objects of this bucket.
+	// It's 0 for buckets using the segment size of the uplink.
+	SegmentSize int64
 }
 
 // Object contains information about a specific object
@@ -22,4 +22,5 @@ type RedundancyAlgorithm byte
 const (
 	InvalidRedundancyAlgorithm = RedundancyAlgorithm(iota)
 	ReedSolomon
+	ReedSolomonSIMD
 )
@@ -0,0 +1,145 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCircuitOpen is the errs class of dials and requests failing fast,
+// because the node failed too many times in a row
+var ErrCircuitOpen = errs.Class("circuit open")
+
+// BreakerConfig configures when nodes are given up on for a while. After
+// MaxFailures failed dials or unavailable responses in a row, the dials and
+// requests to a node fail fast with ErrCircuitOpen until Cooldown is over.
+// Then a single call is let through, which closes the circuit again if it
+// succeeds, or restarts the cooldown if it doesn't.
+type BreakerConfig struct {
+	MaxFailures int           `help:"number of failures in a row after which calls to a node fail fast, 0 to never fail fast" default:"5"`
+	Cooldown    time.Duration `help:"how long calls to a node fail fast before it's tried again" default:"1m"`
+}
+
+// breaker enforces a BreakerConfig. A nil breaker lets all calls through.
+type breaker struct {
+	config BreakerConfig
+	now    func() time.Time
+
+	mu    sync.Mutex
+	nodes map[string]*circuit
+}
+
+// circuit tracks the failures of a node which failed since it last succeeded
+type circuit struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newBreaker(config BreakerConfig) *breaker {
+	if config.MaxFailures <= 0 {
+		return nil
+	}
+	return &breaker{
+		config: config,
+		now:    time.Now,
+		nodes:  make(map[string]*circuit),
+	}
+}
+
+// check fails fast if the circuit of the node with the given key is open
+func (b *breaker) check(key string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.nodes[key]
+	if ok && c.failures >= b.config.MaxFailures && (c.probing || b.now().Before(c.openUntil)) {
+		mon.Event("transport_circuit_open")
+		return ErrCircuitOpen.New("%s failed %d times in a row", key, c.failures)
+	}
+	return nil
+}
+
+// allow fails fast if the circuit of the node with the given key is open.
+// Otherwise the call has to report the failure of the node, or nil if the
+// node responded, to the returned function. Once the cooldown of an open
+// circuit is over, the first call allowed through checks whether the node is
+// back, while the others keep failing fast.
+func (b *breaker) allow(ctx context.Context, key string) (done func(err error), err error) {
+	if b == nil {
+		return func(error) {}, nil
+	}
+	if err := b.check(key); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	probe := false
+	if c, ok := b.nodes[key]; ok && c.failures >= b.config.MaxFailures {
+		if c.probing {
+			mon.Event("transport_circuit_open")
+			return nil, ErrCircuitOpen.New("%s failed %d times in a row", key, c.failures)
+		}
+		c.probing = true
+		probe = true
+	}
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { b.record(ctx, key, probe, err) })
+	}, nil
+}
+
+// record records the outcome of a call to the node with the given key
+func (b *breaker) record(ctx context.Context, key string, probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.nodes[key]
+	if ok && probe {
+		c.probing = false
+	}
+	// calls given up by the caller say nothing about the node
+	if ctx.Err() != nil {
+		return
+	}
+	if err == nil {
+		if ok && c.failures >= b.config.MaxFailures {
+			mon.Event("transport_circuit_closed")
+		}
+		delete(b.nodes, key)
+		return
+	}
+
+	if !ok {
+		c = &circuit{}
+		b.nodes[key] = c
+	}
+	c.failures++
+	if c.failures >= b.config.MaxFailures {
+		if c.failures == b.config.MaxFailures {
+			mon.Event("transport_circuit_opened")
+		}
+		c.openUntil = b.now().Add(b.config.Cooldown)
+	}
+}
+
+// unavailable returns err if it says the node couldn't be reached, and nil
+// if the node responded, even if with an error
+func unavailable(err error) error {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return err
+	}
+	return nil
+}
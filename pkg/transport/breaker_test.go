@@ -0,0 +1,76 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBreaker(t *testing.T) {
+	b := newBreaker(BreakerConfig{MaxFailures: 2, Cooldown: time.Minute})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+	failure := status.Error(codes.Unavailable, "node is down")
+
+	call := func(key string, err error) error {
+		done, allowErr := b.allow(ctx, key)
+		if allowErr != nil {
+			return allowErr
+		}
+		done(err)
+		return nil
+	}
+
+	// failures are only counted while they're in a row
+	assert.NoError(t, call("a", failure))
+	assert.NoError(t, call("a", nil))
+	assert.NoError(t, call("a", failure))
+	assert.NoError(t, call("a", failure))
+
+	// so the circuit is open now, for this node only
+	err := call("a", nil)
+	assert.True(t, ErrCircuitOpen.Has(err))
+	assert.True(t, ErrCircuitOpen.Has(b.check("a")))
+	assert.NoError(t, b.check("b"))
+
+	// after the cooldown, a single call checks whether the node is back
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.check("a"))
+	done, err := b.allow(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ErrCircuitOpen.Has(call("a", nil)))
+	done(failure)
+	assert.True(t, ErrCircuitOpen.Has(call("a", nil)))
+
+	// calls given up by the caller don't count
+	now = now.Add(time.Minute)
+	canceled, cancel := context.WithCancel(ctx)
+	done, err = b.allow(canceled, "a")
+	assert.NoError(t, err)
+	cancel()
+	done(failure)
+
+	// and the node is back
+	assert.NoError(t, call("a", nil))
+	assert.NoError(t, call("a", failure))
+	assert.NoError(t, call("a", nil))
+
+	// nodes responding with errors are up
+	assert.Nil(t, unavailable(status.Error(codes.NotFound, "no such piece")))
+	assert.Equal(t, failure, unavailable(failure))
+
+	// transports without a breaker let everything through
+	var none *breaker
+	assert.Nil(t, newBreaker(BreakerConfig{}))
+	assert.NoError(t, none.check("a"))
+	done, err = none.allow(ctx, "a")
+	assert.NoError(t, err)
+	done(failure)
+}
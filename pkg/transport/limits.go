@@ -0,0 +1,198 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"storj.io/storj/pkg/provider"
+)
+
+// Limits caps the dials and requests in flight, to each node and overall, so
+// bursts of work can't open thousands of connections to the same slow node.
+// Callers over a limit wait for a free slot.
+type Limits struct {
+	MaxDials           int `help:"maximum number of dials in flight, 0 for no limit" default:"100"`
+	MaxDialsPerNode    int `help:"maximum number of dials to a single node in flight, 0 for no limit" default:"2"`
+	MaxRequests        int `help:"maximum number of requests in flight, 0 for no limit" default:"1000"`
+	MaxRequestsPerNode int `help:"maximum number of requests to a single node in flight, 0 for no limit" default:"32"`
+}
+
+// semaphore is a counting semaphore, which is unlimited if nil
+type semaphore chan struct{}
+
+func newSemaphore(size int) semaphore {
+	if size <= 0 {
+		return nil
+	}
+	return make(semaphore, size)
+}
+
+func (s semaphore) acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// limiter enforces Limits. A nil limiter doesn't limit anything.
+type limiter struct {
+	limits   Limits
+	dials    semaphore
+	requests semaphore
+
+	mu    sync.Mutex
+	nodes map[string]*nodeLimiter
+}
+
+type nodeLimiter struct {
+	refs     int
+	dials    semaphore
+	requests semaphore
+}
+
+func newLimiter(limits Limits) *limiter {
+	return &limiter{
+		limits:   limits,
+		dials:    newSemaphore(limits.MaxDials),
+		requests: newSemaphore(limits.MaxRequests),
+		nodes:    make(map[string]*nodeLimiter),
+	}
+}
+
+// acquire waits for a free slot for a dial, or a request if request is set,
+// to the node with the given key, first among the ones to the node, then
+// among all. The returned function frees the slot again.
+func (l *limiter) acquire(ctx context.Context, key string, request bool) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	n := l.node(key)
+	global, perNode := l.dials, n.dials
+	if request {
+		global, perNode = l.requests, n.requests
+	}
+
+	if err := perNode.acquire(ctx); err != nil {
+		l.forget(key, n)
+		return nil, err
+	}
+	if err := global.acquire(ctx); err != nil {
+		perNode.release()
+		l.forget(key, n)
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			global.release()
+			perNode.release()
+			l.forget(key, n)
+		})
+	}, nil
+}
+
+// node returns the limiter of the node with the given key, which has to be
+// forgotten once done with
+func (l *limiter) node(key string) *nodeLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n, ok := l.nodes[key]
+	if !ok {
+		n = &nodeLimiter{
+			dials:    newSemaphore(l.limits.MaxDialsPerNode),
+			requests: newSemaphore(l.limits.MaxRequestsPerNode),
+		}
+		l.nodes[key] = n
+	}
+	n.refs++
+	return n
+}
+
+func (l *limiter) forget(key string, n *nodeLimiter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n.refs--
+	if n.refs == 0 {
+		delete(l.nodes, key)
+	}
+}
+
+// streamClientInterceptor returns the interceptor of the streams to the node
+// with the given key. It passes the request id and the monkit trace on to the
+// node, fails them fast while the circuit of the node is open and counts the
+// streams as requests in flight, until they're over.
+func (o *Transport) streamClientInterceptor(key string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		report, err := o.breaker.allow(ctx, key)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		release, err := o.limiter.acquire(ctx, key, true)
+		if err != nil {
+			report(err)
+			return nil, err
+		}
+
+		stream, err := streamer(provider.OutgoingContext(ctx), desc, cc, method, opts...)
+		report(unavailable(err))
+		if err != nil {
+			release()
+			return nil, err
+		}
+
+		done := make(chan struct{})
+		var once sync.Once
+		finish := func() {
+			once.Do(func() {
+				close(done)
+				release()
+			})
+		}
+		go func() {
+			select {
+			case <-stream.Context().Done():
+				finish()
+			case <-done:
+			}
+		}()
+		return &limitedStream{ClientStream: stream, desc: desc, finish: finish}, nil
+	}
+}
+
+// limitedStream calls finish once the stream is over
+type limitedStream struct {
+	grpc.ClientStream
+	desc   *grpc.StreamDesc
+	finish func()
+}
+
+// RecvMsg implements grpc.ClientStream
+func (s *limitedStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	// the response of a stream without server streaming ends it
+	if err != nil || !s.desc.ServerStreams {
+		s.finish()
+	}
+	return err
+}
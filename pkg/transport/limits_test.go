@@ -0,0 +1,80 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(Limits{MaxDials: 3, MaxDialsPerNode: 2})
+
+	tryAcquire := func(key string) (func(), error) {
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		return l.acquire(ctx, key, false)
+	}
+
+	// the per node limit is reached first
+	release1, err := tryAcquire("a")
+	assert.NoError(t, err)
+	release2, err := tryAcquire("a")
+	assert.NoError(t, err)
+	_, err = tryAcquire("a")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// then the global one
+	release3, err := tryAcquire("b")
+	assert.NoError(t, err)
+	_, err = tryAcquire("c")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// releasing twice frees a single slot
+	release1()
+	release1()
+	release4, err := tryAcquire("c")
+	assert.NoError(t, err)
+	_, err = tryAcquire("a")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// waiting callers get the freed slots
+	acquired := make(chan error)
+	go func() {
+		release, err := l.acquire(ctx, "a", false)
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+	release2()
+	assert.NoError(t, <-acquired)
+
+	release5, err := tryAcquire("a")
+	assert.NoError(t, err)
+	release5()
+
+	// requests have limits of their own, none here
+	for i := 0; i < 10; i++ {
+		_, err := l.acquire(ctx, "a", true)
+		assert.NoError(t, err)
+	}
+
+	// limiters of nodes without dials or requests in flight are forgotten
+	release3()
+	release4()
+	l.mu.Lock()
+	assert.Len(t, l.nodes, 1)
+	l.mu.Unlock()
+}
+
+func TestLimiter_Nil(t *testing.T) {
+	var l *limiter
+	release, err := l.acquire(ctx, "a", true)
+	assert.NoError(t, err)
+	release()
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+
+	"storj.io/storj/pkg/pb"
+)
+
+// Observer is notified of whether the connections made to nodes could be
+// established, e.g. to track the uptime of nodes from real traffic
+type Observer interface {
+	ConnSuccess(ctx context.Context, node *pb.Node)
+	ConnFailure(ctx context.Context, node *pb.Node, err error)
+}
+
+// Observers is an Observer notifying a list of observers, which can still be
+// added to after the Transports notifying it were created
+type Observers struct {
+	mu   sync.RWMutex
+	list []Observer
+}
+
+// Add adds obs to the notified observers
+func (os *Observers) Add(obs Observer) {
+	os.mu.Lock()
+	defer os.mu.Unlock()
+	os.list = append(os.list, obs)
+}
+
+// ConnSuccess implements Observer
+func (os *Observers) ConnSuccess(ctx context.Context, node *pb.Node) {
+	os.mu.RLock()
+	defer os.mu.RUnlock()
+	for _, obs := range os.list {
+		obs.ConnSuccess(ctx, node)
+	}
+}
+
+// ConnFailure implements Observer
+func (os *Observers) ConnFailure(ctx context.Context, node *pb.Node, err error) {
+	os.mu.RLock()
+	defer os.mu.RUnlock()
+	for _, obs := range os.list {
+		obs.ConnFailure(ctx, node, err)
+	}
+}
+
+// observe notifies the observers of o of the outcome of dialing node. Lazily
+// established connections are watched until they're connected or failed.
+func (o *Transport) observe(ctx context.Context, node *pb.Node, conn *grpc.ClientConn, err error) {
+	if len(o.observers) == 0 {
+		return
+	}
+	if err != nil {
+		// dials given up by the caller or failing fast say nothing new
+		// about the node
+		if ctx.Err() == nil && !ErrCircuitOpen.Has(err) {
+			o.connFailure(ctx, node, err)
+		}
+		return
+	}
+	if o.config.DialTimeout > 0 {
+		o.connSuccess(ctx, node)
+		return
+	}
+
+	go func() {
+		// the dialing request may be over by the time the connection is
+		// established
+		ctx := context.Background()
+		for state := conn.GetState(); ; state = conn.GetState() {
+			switch state {
+			case connectivity.Ready:
+				o.connSuccess(ctx, node)
+				return
+			case connectivity.TransientFailure:
+				o.connFailure(ctx, node, Error.New("connection to %s failed", node.GetId()))
+				return
+			case connectivity.Shutdown:
+				return
+			}
+			conn.WaitForStateChange(ctx, state)
+		}
+	}()
+}
+
+func (o *Transport) connSuccess(ctx context.Context, node *pb.Node) {
+	for _, obs := range o.observers {
+		obs.ConnSuccess(ctx, node)
+	}
+}
+
+func (o *Transport) connFailure(ctx context.Context, node *pb.Node, err error) {
+	for _, obs := range o.observers {
+		obs.ConnFailure(ctx, node, err)
+	}
+}
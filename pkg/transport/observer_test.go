@@ -0,0 +1,96 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"storj.io/storj/pkg/pb"
+)
+
+type contact struct {
+	id string
+	up bool
+}
+
+type recordingObserver chan contact
+
+func (r recordingObserver) ConnSuccess(ctx context.Context, node *pb.Node) {
+	r <- contact{node.GetId(), true}
+}
+
+func (r recordingObserver) ConnFailure(ctx context.Context, node *pb.Node, err error) {
+	r <- contact{node.GetId(), false}
+}
+
+func (r recordingObserver) next(t *testing.T) contact {
+	select {
+	case c := <-r:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("observer not notified")
+		return contact{}
+	}
+}
+
+func TestObserve(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	server := grpc.NewServer()
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	var observers Observers
+	obs := make(recordingObserver, 10)
+	observers.Add(obs)
+	o := &Transport{observers: []Observer{&observers}}
+	node := &pb.Node{Id: "DUMMYID1"}
+
+	// failed dials
+	o.observe(ctx, node, nil, errors.New("dial failed"))
+	assert.Equal(t, contact{"DUMMYID1", false}, obs.next(t))
+
+	// dials given up by the caller aren't reported
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	o.observe(canceled, node, nil, canceled.Err())
+
+	// lazily established connections are reported once connected
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer func() { _ = conn.Close() }()
+	o.observe(ctx, node, conn, nil)
+	assert.Equal(t, contact{"DUMMYID1", true}, obs.next(t))
+
+	// or once failed
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, closed.Close())
+	conn, err = grpc.Dial(closed.Addr().String(), grpc.WithInsecure())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer func() { _ = conn.Close() }()
+	o.observe(ctx, &pb.Node{Id: "DUMMYID2"}, conn, nil)
+	assert.Equal(t, contact{"DUMMYID2", false}, obs.next(t))
+
+	// established connections are reported right away
+	o.config.DialTimeout = time.Second
+	o.observe(ctx, node, conn, nil)
+	assert.Equal(t, contact{"DUMMYID1", true}, obs.next(t))
+	assert.Len(t, obs, 0)
+}
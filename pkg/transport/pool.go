@@ -0,0 +1,204 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/utils"
+)
+
+// PoolConfig is the configuration of a connection pool
+type PoolConfig struct {
+	IdleTimeout time.Duration `help:"how long connections to nodes are kept open once unused" default:"1m"`
+	MaxPerNode  int           `help:"maximum number of connections open to a single node, more callers share them" default:"4"`
+}
+
+// Pool is a Client which keeps the connections it dials open, to lend them
+// out again to later callers. gRPC connections multiplex calls, so once
+// there are MaxPerNode connections to a node, callers share them.
+type Pool struct {
+	tc     Client
+	config PoolConfig
+
+	mu     sync.Mutex
+	conns  map[string][]*pooledConn
+	closed bool
+}
+
+type pooledConn struct {
+	conn *grpc.ClientConn
+	refs int
+	idle *time.Timer
+}
+
+// NewPool returns a pool of the connections dialed with tc
+func NewPool(tc Client, config PoolConfig) *Pool {
+	if config.MaxPerNode < 1 {
+		config.MaxPerNode = 1
+	}
+	return &Pool{
+		tc:     tc,
+		config: config,
+		conns:  make(map[string][]*pooledConn),
+	}
+}
+
+// DialNode dials a new connection to node, which isn't pooled
+func (p *Pool) DialNode(ctx context.Context, node *pb.Node) (*grpc.ClientConn, error) {
+	return p.tc.DialNode(ctx, node)
+}
+
+// Acquire returns a connection to node and a function to call instead of
+// closing the connection once the caller is done with it
+func (p *Pool) Acquire(ctx context.Context, node *pb.Node) (conn *grpc.ClientConn, release func() error, err error) {
+	defer mon.Task()(&ctx)(&err)
+	key := node.GetId()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, nil, Error.New("connection pool closed")
+	}
+	if pc := p.pick(key); pc != nil {
+		p.mu.Unlock()
+		mon.Event("pooled_connection_reused")
+		return pc.conn, p.releaser(key, pc), nil
+	}
+	p.mu.Unlock()
+
+	conn, err = p.tc.DialNode(ctx, node)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, nil, utils.CombineErrors(Error.New("connection pool closed"), conn.Close())
+	}
+	pc := &pooledConn{conn: conn, refs: 1}
+	p.conns[key] = append(p.conns[key], pc)
+	return conn, p.releaser(key, pc), nil
+}
+
+// pick returns the least used healthy connection to the node with the given
+// key, or nil if a new one should be dialed. Broken connections are closed
+// and forgotten on the way.
+func (p *Pool) pick(key string) *pooledConn {
+	var kept []*pooledConn
+	var best *pooledConn
+	for _, pc := range p.conns[key] {
+		if state := pc.conn.GetState(); state == connectivity.TransientFailure || state == connectivity.Shutdown {
+			if pc.refs == 0 {
+				p.stopIdle(pc)
+				_ = pc.conn.Close()
+				continue
+			}
+		} else if best == nil || pc.refs < best.refs {
+			best = pc
+		}
+		kept = append(kept, pc)
+	}
+	p.conns[key] = kept
+	if len(kept) == 0 {
+		delete(p.conns, key)
+	}
+
+	if best == nil || (best.refs > 0 && len(kept) < p.config.MaxPerNode) {
+		return nil
+	}
+	best.refs++
+	p.stopIdle(best)
+	return best
+}
+
+// releaser returns the function releasing pc once its borrower is done
+func (p *Pool) releaser(key string, pc *pooledConn) func() error {
+	var once sync.Once
+	return func() error {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			pc.refs--
+			if pc.refs > 0 || p.closed {
+				return
+			}
+			pc.idle = time.AfterFunc(p.config.IdleTimeout, func() {
+				p.expire(key, pc)
+			})
+		})
+		return nil
+	}
+}
+
+// expire closes pc if it's still unused
+func (p *Pool) expire(key string, pc *pooledConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if pc.refs > 0 || pc.idle == nil {
+		return
+	}
+	pc.idle = nil
+
+	conns := p.conns[key]
+	for i, other := range conns {
+		if other == pc {
+			p.conns[key] = append(conns[:i:i], conns[i+1:]...)
+			break
+		}
+	}
+	if len(p.conns[key]) == 0 {
+		delete(p.conns, key)
+	}
+	_ = pc.conn.Close()
+}
+
+func (p *Pool) stopIdle(pc *pooledConn) {
+	if pc.idle != nil {
+		pc.idle.Stop()
+		pc.idle = nil
+	}
+}
+
+// Close closes all connections of the pool, including the lent out ones
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	var errs []error
+	for key, conns := range p.conns {
+		for _, pc := range conns {
+			p.stopIdle(pc)
+			if err := pc.conn.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		delete(p.conns, key)
+	}
+	return utils.CombineErrors(errs...)
+}
+
+// Acquire returns a connection to node and a function to call instead of
+// closing it once the caller is done with it. If tc is a Pool, the
+// connection is lent out by the pool, otherwise it's dialed and closed by
+// the returned function.
+func Acquire(ctx context.Context, tc Client, node *pb.Node) (*grpc.ClientConn, func() error, error) {
+	if pool, ok := tc.(*Pool); ok {
+		return pool.Acquire(ctx, node)
+	}
+	conn, err := tc.DialNode(ctx, node)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, conn.Close, nil
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+
+	"storj.io/storj/pkg/pb"
+)
+
+type countingClient struct {
+	address string
+	dials   int
+}
+
+func (c *countingClient) DialNode(ctx context.Context, node *pb.Node) (*grpc.ClientConn, error) {
+	c.dials++
+	return grpc.Dial(c.address, grpc.WithInsecure())
+}
+
+func TestPool(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	server := grpc.NewServer()
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	tc := &countingClient{address: lis.Addr().String()}
+	pool := NewPool(tc, PoolConfig{IdleTimeout: 50 * time.Millisecond, MaxPerNode: 2})
+	node := &pb.Node{Id: "DUMMYID1"}
+
+	// released connections are reused
+	conn1, release1, err := pool.Acquire(ctx, node)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, release1())
+	conn2, release2, err := pool.Acquire(ctx, node)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, conn1 == conn2)
+	assert.Equal(t, 1, tc.dials)
+
+	// busy connections are only shared once there are MaxPerNode of them
+	conn3, release3, err := pool.Acquire(ctx, node)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, conn2 != conn3)
+	conn4, release4, err := pool.Acquire(ctx, node)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, conn4 == conn2 || conn4 == conn3)
+	assert.Equal(t, 2, tc.dials)
+
+	// other nodes get their own connections
+	_, release5, err := pool.Acquire(ctx, &pb.Node{Id: "DUMMYID2"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 3, tc.dials)
+
+	// idle connections are closed after IdleTimeout
+	for _, release := range []func() error{release2, release3, release4, release4} {
+		assert.NoError(t, release())
+	}
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, connectivity.Shutdown, conn3.GetState())
+	_, release6, err := pool.Acquire(ctx, node)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, release6())
+	assert.Equal(t, 4, tc.dials)
+
+	// closing the pool closes lent out connections too
+	assert.NoError(t, pool.Close())
+	_, _, err = pool.Acquire(ctx, node)
+	assert.Error(t, err)
+	assert.NoError(t, release5())
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+	"google.golang.org/grpc"
+)
+
+// ProxyConfig configures the proxy nodes are dialed through. Without a
+// proxy URL, the ALL_PROXY environment variable is used, and if it's unset
+// as well, gRPC honors HTTPS_PROXY and NO_PROXY by itself.
+type ProxyConfig struct {
+	URL     string `help:"proxy to dial nodes through, socks5://[user:password@]host:port or http://[user:password@]host:port" default:""`
+	NoProxy string `help:"comma separated hosts, domains and networks dialed without the proxy, NO_PROXY if empty" default:""`
+}
+
+// dialOption returns the option making grpc dial through the proxy, or nil
+// if there's none
+func (c ProxyConfig) dialOption() (grpc.DialOption, error) {
+	proxyURL, noProxy := c.URL, c.NoProxy
+	if proxyURL == "" {
+		proxyURL = getenv("ALL_PROXY", "all_proxy")
+	}
+	if proxyURL == "" {
+		return nil, nil
+	}
+	if noProxy == "" {
+		noProxy = getenv("NO_PROXY", "no_proxy")
+	}
+
+	if _, err := url.Parse(proxyURL); err != nil {
+		return nil, Error.New("invalid proxy %q: %v", proxyURL, err)
+	}
+	proxyFunc := (&httpproxy.Config{HTTPSProxy: proxyURL, NoProxy: noProxy}).ProxyFunc()
+
+	return grpc.WithDialer(func(address string, timeout time.Duration) (net.Conn, error) {
+		// nodes are dialed with TLS, so they're matched like https urls
+		u, err := proxyFunc(&url.URL{Scheme: "https", Host: address})
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		if u == nil {
+			return net.DialTimeout("tcp", address, timeout)
+		}
+		return dialProxy(u, address, timeout)
+	}), nil
+}
+
+// dialProxy connects to address through the proxy at u
+func dialProxy(u *url.URL, address string, timeout time.Duration) (net.Conn, error) {
+	forward := &net.Dialer{Timeout: timeout}
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if u.User != nil {
+			password, _ := u.User.Password()
+			auth = &proxy.Auth{User: u.User.Username(), Password: password}
+		}
+		dialer, err := proxy.SOCKS5("tcp", u.Host, auth, forward)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		conn, err := dialer.Dial("tcp", address)
+		return conn, Error.Wrap(err)
+	case "http":
+		return dialHTTPConnect(forward, u, address)
+	default:
+		return nil, Error.New("unsupported proxy scheme %q", u.Scheme)
+	}
+}
+
+// dialHTTPConnect connects to address through the http proxy at u, with a
+// CONNECT request
+func dialHTTPConnect(forward *net.Dialer, u *url.URL, address string) (_ net.Conn, err error) {
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "80")
+	}
+	conn, err := forward.Dial("tcp", host)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = conn.Close()
+		}
+	}()
+	if forward.Timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(forward.Timeout)); err != nil {
+			return nil, Error.Wrap(err)
+		}
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		req.SetBasicAuth(u.User.Username(), password)
+		req.Header.Set("Proxy-Authorization", req.Header.Get("Authorization"))
+		req.Header.Del("Authorization")
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, Error.New("proxy refused connection to %s: %s", address, resp.Status)
+	}
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a connection whose reads start with what was already
+// buffered from it
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Read implements net.Conn
+func (c *bufferedConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+func getenv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// listen starts serving connections accepted on a new local listener with
+// serve and returns its address
+func listen(t *testing.T, serve func(net.Conn)) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func TestDialHTTPConnect(t *testing.T) {
+	echo := listen(t, func(conn net.Conn) {
+		defer func() { _ = conn.Close() }()
+		_, _ = io.Copy(conn, conn)
+	})
+
+	requests := make(chan *http.Request, 2)
+	proxyAddr := listen(t, func(conn net.Conn) {
+		defer func() { _ = conn.Close() }()
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			return
+		}
+		requests <- req
+		if req.Header.Get("Proxy-Authorization") == "" {
+			_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+			return
+		}
+		target, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			_, _ = io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+			return
+		}
+		defer func() { _ = target.Close() }()
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 OK\r\n\r\n")
+		go func() { _, _ = io.Copy(target, conn) }()
+		_, _ = io.Copy(conn, target)
+	})
+
+	u := &url.URL{Scheme: "http", Host: proxyAddr, User: url.UserPassword("user", "password")}
+	conn, err := dialProxy(u, echo, time.Second)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer func() { _ = conn.Close() }()
+
+	_, err = io.WriteString(conn, "ping")
+	assert.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+
+	req := <-requests
+	assert.Equal(t, http.MethodConnect, req.Method)
+	assert.Equal(t, echo, req.Host)
+
+	// refused connections fail
+	_, err = dialProxy(&url.URL{Scheme: "http", Host: proxyAddr}, echo, time.Second)
+	assert.True(t, Error.Has(err))
+
+	_, err = dialProxy(&url.URL{Scheme: "ftp", Host: proxyAddr}, echo, time.Second)
+	assert.True(t, Error.Has(err))
+}
+
+func TestProxyConfig(t *testing.T) {
+	opt, err := ProxyConfig{URL: "socks5://127.0.0.1:1080"}.dialOption()
+	assert.NoError(t, err)
+	assert.NotNil(t, opt)
+
+	_, err = ProxyConfig{URL: "http://[::1"}.dialOption()
+	assert.Error(t, err)
+}
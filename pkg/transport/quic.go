@@ -0,0 +1,47 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"storj.io/storj/pkg/transport/quic"
+)
+
+// quicDialOption returns the option making grpc dial nodes over QUIC. If
+// QUIC fails, e.g. because UDP is blocked on the way, the node is dialed
+// over TCP instead, which the nodes serving QUIC serve as well.
+func (o *Transport) quicDialOption() (grpc.DialOption, error) {
+	tlsConfig, err := o.identity.ClientTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithDialer(func(address string, timeout time.Duration) (net.Conn, error) {
+		quicTimeout := o.config.QUICTimeout
+		if timeout > 0 && timeout < quicTimeout {
+			quicTimeout = timeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), quicTimeout)
+		defer cancel()
+
+		start := time.Now()
+		conn, err := quic.Dial(ctx, address, tlsConfig)
+		if err == nil {
+			return conn, nil
+		}
+		mon.Event("transport_quic_fallback")
+
+		if timeout > 0 {
+			timeout -= time.Since(start)
+			if timeout <= 0 {
+				return nil, Error.Wrap(context.DeadlineExceeded)
+			}
+		}
+		return net.DialTimeout("tcp", address, timeout)
+	}), nil
+}
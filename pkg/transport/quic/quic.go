@@ -0,0 +1,207 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package quic
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+	"time"
+
+	quicgo "github.com/quic-go/quic-go"
+	"github.com/zeebo/errs"
+	"google.golang.org/grpc/credentials"
+)
+
+var (
+	// Error is the errs class of QUIC errors
+	Error = errs.Class("quic error")
+)
+
+// Protocol is the ALPN protocol of gRPC over QUIC. Each QUIC connection
+// carries a single stream, which gRPC multiplexes its calls over.
+const Protocol = "storj-grpc"
+
+// streamTimeout is how long accepted connections have to open their stream
+const streamTimeout = 10 * time.Second
+
+// Conn is a net.Conn over the stream of a QUIC connection. It's secured
+// with TLS by QUIC already.
+type Conn struct {
+	*quicgo.Stream
+	conn *quicgo.Conn
+}
+
+// LocalAddr implements net.Conn
+func (c *Conn) LocalAddr() net.Addr { return c.conn.LocalAddr() }
+
+// RemoteAddr implements net.Conn
+func (c *Conn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+// Close implements net.Conn, closing the QUIC connection of the stream
+func (c *Conn) Close() error {
+	return Error.Wrap(c.conn.CloseWithError(0, ""))
+}
+
+// ConnectionState returns the state of the TLS handshake of the QUIC
+// connection
+func (c *Conn) ConnectionState() tls.ConnectionState {
+	return c.conn.ConnectionState().TLS
+}
+
+// withProtocol returns a copy of tlsConfig negotiating Protocol
+func withProtocol(tlsConfig *tls.Config) *tls.Config {
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.NextProtos = []string{Protocol}
+	return tlsConfig
+}
+
+// Dial connects to the UDP port of address with QUIC, making the TLS
+// handshake with tlsConfig, and opens the stream of the connection
+func Dial(ctx context.Context, address string, tlsConfig *tls.Config) (_ *Conn, err error) {
+	conn, err := quicgo.DialAddr(ctx, address, withProtocol(tlsConfig), nil)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		_ = conn.CloseWithError(0, "")
+		return nil, Error.Wrap(err)
+	}
+	return &Conn{Stream: stream, conn: conn}, nil
+}
+
+// Listener is a net.Listener accepting QUIC connections. Accept returns
+// the stream of each connection once the peer opened it.
+type Listener struct {
+	lis       *quicgo.Listener
+	conns     chan *Conn
+	closed    chan struct{}
+	once      sync.Once
+	closeOnce sync.Once
+
+	mu  sync.Mutex
+	err error
+}
+
+// Listen listens for QUIC connections on the UDP port of address, making
+// their TLS handshakes with tlsConfig
+func Listen(address string, tlsConfig *tls.Config) (*Listener, error) {
+	lis, err := quicgo.ListenAddr(address, withProtocol(tlsConfig), nil)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	l := &Listener{
+		lis:    lis,
+		conns:  make(chan *Conn),
+		closed: make(chan struct{}),
+	}
+	go l.accept()
+	return l, nil
+}
+
+// accept accepts connections until the listener fails or is closed
+func (l *Listener) accept() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-l.closed
+		cancel()
+	}()
+
+	for {
+		conn, err := l.lis.Accept(ctx)
+		if err != nil {
+			l.mu.Lock()
+			l.err = err
+			l.mu.Unlock()
+			l.close()
+			return
+		}
+		// streams are accepted concurrently, so that peers which don't open
+		// theirs don't hold up the others
+		go l.acceptStream(ctx, conn)
+	}
+}
+
+// acceptStream hands the stream of conn to Accept
+func (l *Listener) acceptStream(ctx context.Context, conn *quicgo.Conn) {
+	streamCtx, cancel := context.WithTimeout(ctx, streamTimeout)
+	defer cancel()
+	stream, err := conn.AcceptStream(streamCtx)
+	if err != nil {
+		_ = conn.CloseWithError(0, "")
+		return
+	}
+	select {
+	case l.conns <- &Conn{Stream: stream, conn: conn}:
+	case <-l.closed:
+		_ = conn.CloseWithError(0, "")
+	}
+}
+
+// Accept implements net.Listener
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.err != nil {
+			return nil, Error.Wrap(l.err)
+		}
+		return nil, Error.New("listener closed")
+	}
+}
+
+// Close implements net.Listener
+func (l *Listener) Close() (err error) {
+	l.closeOnce.Do(func() {
+		l.close()
+		err = Error.Wrap(l.lis.Close())
+	})
+	return err
+}
+
+func (l *Listener) close() {
+	l.once.Do(func() { close(l.closed) })
+}
+
+// Addr implements net.Listener
+func (l *Listener) Addr() net.Addr { return l.lis.Addr() }
+
+// Credentials returns gRPC credentials which handshake with creds, except
+// on QUIC connections, which are secured already. The TLS state of their
+// QUIC handshake is passed on to gRPC instead, so peers are identified the
+// same way on both.
+func Credentials(creds credentials.TransportCredentials) credentials.TransportCredentials {
+	return &quicCredentials{TransportCredentials: creds}
+}
+
+type quicCredentials struct {
+	credentials.TransportCredentials
+}
+
+// ClientHandshake implements credentials.TransportCredentials
+func (c *quicCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	if conn, ok := rawConn.(*Conn); ok {
+		return conn, credentials.TLSInfo{State: conn.ConnectionState()}, nil
+	}
+	return c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+}
+
+// ServerHandshake implements credentials.TransportCredentials
+func (c *quicCredentials) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	if conn, ok := rawConn.(*Conn); ok {
+		return conn, credentials.TLSInfo{State: conn.ConnectionState()}, nil
+	}
+	return c.TransportCredentials.ServerHandshake(rawConn)
+}
+
+// Clone implements credentials.TransportCredentials
+func (c *quicCredentials) Clone() credentials.TransportCredentials {
+	return &quicCredentials{TransportCredentials: c.TransportCredentials.Clone()}
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package quic
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+)
+
+func newTestCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestQUIC(t *testing.T) {
+	ctx := context.Background()
+	serverConfig := &tls.Config{
+		Certificates: []tls.Certificate{newTestCert(t)},
+		ClientAuth:   tls.RequireAnyClientCert,
+	}
+	clientConfig := &tls.Config{
+		Certificates:       []tls.Certificate{newTestCert(t)},
+		InsecureSkipVerify: true,
+	}
+
+	lis, err := Listen("127.0.0.1:0", serverConfig)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	client, err := Dial(ctx, lis.Addr().String(), clientConfig)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	// the stream is accepted once the client writes to it
+	_, err = client.Write([]byte("hello"))
+	assert.NoError(t, err)
+
+	accepted, err := lis.Accept()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(accepted, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+	assert.Equal(t, "udp", accepted.RemoteAddr().Network())
+
+	// gRPC gets the state of the QUIC handshake instead of making another one
+	creds := Credentials(credentials.NewTLS(serverConfig))
+	conn, info, err := creds.ServerHandshake(accepted)
+	assert.NoError(t, err)
+	assert.Equal(t, accepted, conn)
+	if assert.IsType(t, credentials.TLSInfo{}, info) {
+		assert.Len(t, info.(credentials.TLSInfo).State.PeerCertificates, 1)
+	}
+	conn, info, err = creds.Clone().ClientHandshake(ctx, "", client)
+	assert.NoError(t, err)
+	assert.Equal(t, client, conn)
+	if assert.IsType(t, credentials.TLSInfo{}, info) {
+		assert.Equal(t, Protocol, info.(credentials.TLSInfo).State.NegotiatedProtocol)
+	}
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, lis.Close())
+	assert.NoError(t, lis.Close())
+	_, err = lis.Accept()
+	assert.True(t, Error.Has(err))
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/peer"
+
+	"storj.io/storj/pkg/pb"
+	"storj.io/storj/pkg/provider"
+)
+
+// peerNetwork answers ExternalAddress with the network of the verified
+// peer's request
+type peerNetwork struct{ pb.NodesServer }
+
+func (peerNetwork) ExternalAddress(ctx context.Context, _ *pb.ExternalAddressRequest) (*pb.ExternalAddressResponse, error) {
+	if _, err := provider.PeerIdentityFromContext(ctx); err != nil {
+		return nil, err
+	}
+	p, _ := peer.FromContext(ctx)
+	return &pb.ExternalAddressResponse{Address: p.Addr.Network()}, nil
+}
+
+func TestDialNodeQUIC(t *testing.T) {
+	ca, err := provider.NewTestCA(ctx)
+	assert.NoError(t, err)
+	serverFi, err := ca.NewIdentity()
+	assert.NoError(t, err)
+	clientFi, err := ca.NewIdentity()
+	assert.NoError(t, err)
+
+	serve := func(withQUIC bool) (address string, stop func()) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		p, err := provider.NewProvider(serverFi, lis, nil)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		if withQUIC {
+			assert.NoError(t, p.ListenQUIC(lis.Addr().String()))
+			assert.Equal(t, pb.NodeTransport_QUIC_TLS_GRPC, p.Transport())
+		}
+		pb.RegisterNodesServer(p.GRPC(), peerNetwork{})
+		go func() { _ = p.Run(ctx) }()
+		return lis.Addr().String(), func() { _ = p.Close() }
+	}
+	quicAddress, stop := serve(true)
+	defer stop()
+	tcpAddress, stop := serve(false)
+	defer stop()
+
+	config := DefaultConfig
+	config.QUICTimeout = time.Second
+	for _, c := range []struct {
+		config    Config
+		transport pb.NodeTransport
+		address   string
+		network   string
+	}{
+		{config, pb.NodeTransport_QUIC_TLS_GRPC, quicAddress, "udp"},
+		{config, pb.NodeTransport_TCP_TLS_GRPC, quicAddress, "tcp"},
+		// nodes announcing QUIC are dialed over TCP if QUIC fails
+		{config, pb.NodeTransport_QUIC_TLS_GRPC, tcpAddress, "tcp"},
+		// or if QUIC is disabled
+		{Config{}, pb.NodeTransport_QUIC_TLS_GRPC, quicAddress, "tcp"},
+	} {
+		oc := c.config.NewClient(clientFi)
+		conn, err := oc.DialNode(ctx, &pb.Node{
+			Id:      serverFi.ID.String(),
+			Address: &pb.NodeAddress{Transport: c.transport, Address: c.address},
+		})
+		if !assert.NoError(t, err) {
+			continue
+		}
+		resp, err := pb.NewNodesClient(conn).ExternalAddress(ctx, &pb.ExternalAddressRequest{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.network, resp.Address)
+		}
+		assert.NoError(t, conn.Close())
+	}
+}
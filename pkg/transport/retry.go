@@ -0,0 +1,127 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"storj.io/storj/pkg/provider"
+)
+
+// RetryPolicy configures how often and how fast operations failing with
+// transient errors are retried
+type RetryPolicy struct {
+	MaxAttempts    int           `help:"how many times an operation failing with a transient error is tried" default:"3"`
+	InitialBackoff time.Duration `help:"how long to wait before the first retry" default:"500ms"`
+	MaxBackoff     time.Duration `help:"maximum time to wait between retries" default:"10s"`
+}
+
+// Backoff returns how long to wait before the retry following the given
+// (zero based) failed attempt. The backoff doubles with every attempt, with
+// up to half of it randomized to spread out retries of concurrent callers.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 0; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if backoff <= 1 {
+		return backoff
+	}
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// Do calls fn until it succeeds, fails with an error which isn't transient
+// or was tried MaxAttempts times, waiting between the attempts
+func (p RetryPolicy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return p.do(ctx, fn, IsTransient)
+}
+
+func (p RetryPolicy) do(ctx context.Context, fn func(ctx context.Context) error, retryable func(error) bool) (err error) {
+	for attempt := 0; ; attempt++ {
+		err = fn(ctx)
+		if err == nil || attempt+1 >= p.MaxAttempts || !retryable(err) {
+			return err
+		}
+
+		mon.Event("transport_retry")
+		timer := time.NewTimer(p.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// IsTransient returns whether err is likely to go away when the operation
+// failing with it is retried: timeouts and temporary network failures
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == context.DeadlineExceeded {
+		return true
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+			return true
+		}
+	}
+	if netErr, ok := err.(net.Error); ok {
+		return netErr.Timeout() || netErr.Temporary()
+	}
+	return false
+}
+
+// unaryClientInterceptor returns the interceptor of the requests to the
+// node with the given key. It passes the request id and the monkit trace on
+// to the node, counts the requests against the limits on requests in
+// flight, fails them fast while the circuit of the node is open, gives the
+// ones without a deadline the configured request timeout and retries the ones
+// failing because the node was unavailable. Other failures aren't retried, as
+// the node may have handled the request already.
+func (o *Transport) unaryClientInterceptor(key string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = provider.OutgoingContext(ctx)
+		done, err := o.breaker.allow(ctx, key)
+		if err != nil {
+			return Error.Wrap(err)
+		}
+		err = o.config.Retry.do(ctx, func(ctx context.Context) error {
+			release, err := o.limiter.acquire(ctx, key, true)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			if _, ok := ctx.Deadline(); !ok && o.config.RequestTimeout > 0 {
+				var cancel func()
+				ctx, cancel = context.WithTimeout(ctx, o.config.RequestTimeout)
+				defer cancel()
+			}
+			start := time.Now()
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			o.stats.recordRequest(key, time.Since(start), err)
+			return err
+		}, func(err error) bool {
+			return status.Code(err) == codes.Unavailable
+		})
+		done(unavailable(err))
+		return err
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for _, tt := range []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{0, 50 * time.Millisecond, 100 * time.Millisecond},
+		{1, 100 * time.Millisecond, 200 * time.Millisecond},
+		{2, 200 * time.Millisecond, 400 * time.Millisecond},
+		{10, 500 * time.Millisecond, time.Second},
+	} {
+		backoff := policy.Backoff(tt.attempt)
+		assert.True(t, backoff >= tt.min && backoff <= tt.max, "attempt %d: %s", tt.attempt, backoff)
+	}
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	// transient errors are retried up to MaxAttempts times
+	calls := 0
+	err := policy.Do(ctx, func(context.Context) error {
+		calls++
+		return unavailable
+	})
+	assert.Equal(t, unavailable, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = policy.Do(ctx, func(context.Context) error {
+		calls++
+		if calls < 2 {
+			return context.DeadlineExceeded
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// other errors aren't
+	calls = 0
+	permanent := errors.New("permanent")
+	err = policy.Do(ctx, func(context.Context) error {
+		calls++
+		return permanent
+	})
+	assert.Equal(t, permanent, err)
+	assert.Equal(t, 1, calls)
+
+	// nor are canceled operations
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	calls = 0
+	policy.InitialBackoff, policy.MaxBackoff = time.Hour, time.Hour
+	err = policy.Do(canceled, func(context.Context) error {
+		calls++
+		return unavailable
+	})
+	assert.Equal(t, unavailable, err)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/stats"
+)
+
+// NodeStats aggregates the traffic to a node. Latencies are exponentially
+// weighted moving averages, so they follow changes of the node's network.
+// Requests only count unary requests, as the duration of streams depends on
+// how much is transferred, but the bytes of both are counted.
+type NodeStats struct {
+	Dials          int64
+	DialFailures   int64
+	DialLatency    time.Duration
+	Requests       int64
+	RequestErrors  int64
+	RequestLatency time.Duration
+	BytesSent      int64
+	BytesReceived  int64
+}
+
+// weight of a new sample in the latency averages
+const latencyWeight = 0.2
+
+func average(avg, sample time.Duration, first bool) time.Duration {
+	if first {
+		return sample
+	}
+	return avg + time.Duration(latencyWeight*float64(sample-avg))
+}
+
+// Stats collects the NodeStats of the nodes a Transport talks to
+type Stats struct {
+	mu    sync.Mutex
+	nodes map[string]*NodeStats
+}
+
+// NewStats returns an empty Stats
+func NewStats() *Stats {
+	return &Stats{nodes: make(map[string]*NodeStats)}
+}
+
+// Node returns the stats of the node with the given id, if it was talked to
+func (s *Stats) Node(id string) (NodeStats, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.nodes[id]
+	if !ok {
+		return NodeStats{}, false
+	}
+	return *n, true
+}
+
+// All returns the stats of all nodes talked to, by node id
+func (s *Stats) All() map[string]NodeStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all := make(map[string]NodeStats, len(s.nodes))
+	for id, n := range s.nodes {
+		all[id] = *n
+	}
+	return all
+}
+
+// update calls fn with the stats of the node with the given id
+func (s *Stats) update(id string, fn func(n *NodeStats)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.nodes[id]
+	if !ok {
+		n = &NodeStats{}
+		s.nodes[id] = n
+	}
+	fn(n)
+}
+
+// recordDial records a dial to the node with the given id which took d
+func (s *Stats) recordDial(id string, d time.Duration, err error) {
+	if s == nil {
+		return
+	}
+	mon.IntVal("dial_latency_ms").Observe(int64(d / time.Millisecond))
+	s.update(id, func(n *NodeStats) {
+		if err != nil {
+			n.DialFailures++
+			return
+		}
+		n.DialLatency = average(n.DialLatency, d, n.Dials == 0)
+		n.Dials++
+	})
+}
+
+// recordRequest records a unary request to the node with the given id which
+// took d
+func (s *Stats) recordRequest(id string, d time.Duration, err error) {
+	if s == nil {
+		return
+	}
+	mon.IntVal("request_latency_ms").Observe(int64(d / time.Millisecond))
+	s.update(id, func(n *NodeStats) {
+		n.RequestLatency = average(n.RequestLatency, d, n.Requests == 0)
+		n.Requests++
+		if err != nil {
+			n.RequestErrors++
+		}
+	})
+}
+
+// statsHandler records the bytes transferred on a connection to a node
+type statsHandler struct {
+	stats *Stats
+	id    string
+}
+
+// TagRPC implements stats.Handler
+func (h *statsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC implements stats.Handler
+func (h *statsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	switch s := s.(type) {
+	case *stats.InPayload:
+		mon.IntVal("bytes_received").Observe(int64(s.WireLength))
+		h.stats.update(h.id, func(n *NodeStats) { n.BytesReceived += int64(s.WireLength) })
+	case *stats.OutPayload:
+		mon.IntVal("bytes_sent").Observe(int64(s.WireLength))
+		h.stats.update(h.id, func(n *NodeStats) { n.BytesSent += int64(s.WireLength) })
+	}
+}
+
+// TagConn implements stats.Handler
+func (h *statsHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler
+func (h *statsHandler) HandleConn(ctx context.Context, s stats.ConnStats) {}
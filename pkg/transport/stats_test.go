@@ -0,0 +1,54 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package transport
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/stats"
+)
+
+func TestStats(t *testing.T) {
+	s := NewStats()
+	_, ok := s.Node("a")
+	assert.False(t, ok)
+
+	s.recordDial("a", 100*time.Millisecond, nil)
+	s.recordDial("a", 200*time.Millisecond, nil)
+	s.recordDial("a", time.Second, errors.New("dial failed"))
+	s.recordRequest("a", 10*time.Millisecond, nil)
+	s.recordRequest("a", 10*time.Millisecond, errors.New("request failed"))
+
+	h := &statsHandler{stats: s, id: "a"}
+	h.HandleRPC(ctx, &stats.OutPayload{WireLength: 100})
+	h.HandleRPC(ctx, &stats.InPayload{WireLength: 50})
+	h.HandleRPC(ctx, &stats.InPayload{WireLength: 25})
+
+	a, ok := s.Node("a")
+	assert.True(t, ok)
+	assert.Equal(t, NodeStats{
+		Dials:          2,
+		DialFailures:   1,
+		DialLatency:    120 * time.Millisecond,
+		Requests:       2,
+		RequestErrors:  1,
+		RequestLatency: 10 * time.Millisecond,
+		BytesSent:      100,
+		BytesReceived:  75,
+	}, a)
+
+	s.recordRequest("b", time.Millisecond, nil)
+	all := s.All()
+	assert.Len(t, all, 2)
+	assert.Equal(t, a, all["a"])
+	assert.Equal(t, int64(1), all["b"].Requests)
+
+	// transports without stats don't record anything
+	var none *Stats
+	none.recordDial("a", time.Millisecond, nil)
+	none.recordRequest("a", time.Millisecond, nil)
+}
@@ -5,21 +5,92 @@ package transport
 
 import (
 	"context"
+	"crypto/tls"
+	"time"
 
 	"google.golang.org/grpc"
 
+	"storj.io/storj/pkg/ban"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/provider"
 )
 
+// Config is the configuration of how nodes are dialed and called
+type Config struct {
+	DialTimeout      time.Duration `help:"how long to wait for a connection to a node to be established, 0 to connect lazily" default:"20s"`
+	RequestTimeout   time.Duration `help:"timeout of requests to nodes which don't have a deadline already" default:"1m"`
+	SessionCacheSize int           `help:"number of TLS sessions with nodes cached to resume them when reconnecting, 0 to disable" default:"1024"`
+	QUICTimeout      time.Duration `help:"how long to try dialing the nodes which announce QUIC over QUIC before falling back to TCP, 0 to always dial TCP" default:"5s"`
+	Retry            RetryPolicy
+	Limits           Limits
+	Breaker          BreakerConfig
+	Proxy            ProxyConfig
+}
+
+// DefaultConfig is the configuration of the Transports returned by
+// NewClient. It has no dial timeout, so their connections are established
+// lazily by the first request, like grpc.Dial's.
+var DefaultConfig = Config{
+	RequestTimeout:   time.Minute,
+	SessionCacheSize: 1024,
+	QUICTimeout:      5 * time.Second,
+	Retry: RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+	},
+	Limits: Limits{
+		MaxDials:           100,
+		MaxDialsPerNode:    2,
+		MaxRequests:        1000,
+		MaxRequestsPerNode: 32,
+	},
+	Breaker: BreakerConfig{
+		MaxFailures: 5,
+		Cooldown:    time.Minute,
+	},
+}
+
 // Transport interface structure
 type Transport struct {
-	identity *provider.FullIdentity
+	identity  *provider.FullIdentity
+	config    Config
+	limiter   *limiter
+	breaker   *breaker
+	stats     *Stats
+	observers []Observer
+	bans      *ban.List
+}
+
+// NewClient returns a newly instantiated Transport Client, which notifies
+// obs of the nodes it could or couldn't connect to
+func NewClient(identity *provider.FullIdentity, obs ...Observer) *Transport {
+	return DefaultConfig.NewClient(identity, obs...)
+}
+
+// NewClient returns a newly instantiated Transport Client with the timeouts
+// and retries of c, which notifies obs of the nodes it could or couldn't
+// connect to
+func (c Config) NewClient(identity *provider.FullIdentity, obs ...Observer) *Transport {
+	if identity != nil && identity.SessionCache == nil && c.SessionCacheSize > 0 {
+		withCache := *identity
+		withCache.SessionCache = tls.NewLRUClientSessionCache(c.SessionCacheSize)
+		identity = &withCache
+	}
+	return &Transport{
+		identity:  identity,
+		config:    c,
+		limiter:   newLimiter(c.Limits),
+		breaker:   newBreaker(c.Breaker),
+		stats:     NewStats(),
+		observers: obs,
+	}
 }
 
-// NewClient returns a newly instantiated Transport Client
-func NewClient(identity *provider.FullIdentity) *Transport {
-	return &Transport{identity: identity}
+// SetBans sets the ban list of the nodes which aren't dialed, nil to dial
+// every node
+func (o *Transport) SetBans(bans *ban.List) {
+	o.bans = bans
 }
 
 // DialNode using the authenticated mode
@@ -29,10 +100,94 @@ func (o *Transport) DialNode(ctx context.Context, node *pb.Node) (conn *grpc.Cli
 	if node.Address == nil || node.Address.Address == "" {
 		return nil, Error.New("no address")
 	}
+
+	if o.bans.BannedNode(node) {
+		return nil, Error.New("node %s is banned", node.GetId())
+	}
+
 	// TODO(coyle): pass ID
 	dialOpt, err := o.identity.DialOption()
 	if err != nil {
 		return nil, err
 	}
-	return grpc.Dial(node.Address.Address, dialOpt)
+	opts := []grpc.DialOption{dialOpt}
+	if node.Address.Transport == pb.NodeTransport_QUIC_TLS_GRPC && o.config.QUICTimeout > 0 {
+		quicOpt, err := o.quicDialOption()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, quicOpt)
+	}
+	conn, err = o.dial(ctx, node.GetId(), node.Address.Address, opts...)
+	o.observe(ctx, node, conn, err)
+	return conn, err
+}
+
+// DialAddress dials address with the given options, which have to include
+// the credentials, retrying dials failing with transient errors. Unless the
+// Transport has no dial timeout, the connection is established before
+// returning.
+func (o *Transport) DialAddress(ctx context.Context, address string, opts ...grpc.DialOption) (conn *grpc.ClientConn, err error) {
+	defer mon.Task()(&ctx)(&err)
+	return o.dial(ctx, address, address, opts...)
+}
+
+// Stats returns the stats of the nodes the Transport talked to. Nodes
+// dialed with DialAddress are keyed by their address.
+func (o *Transport) Stats() *Stats {
+	return o.stats
+}
+
+// dial dials address, counting the dial and the requests on the connection
+// against the limits of the peer with the given key. Lazily established
+// connections only count against the dial limits until grpc.Dial returns,
+// and only their requests count against the circuit breaker.
+func (o *Transport) dial(ctx context.Context, key, address string, opts ...grpc.DialOption) (conn *grpc.ClientConn, err error) {
+	if err := o.breaker.check(key); err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	release, err := o.limiter.acquire(ctx, key, false)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer release()
+
+	opts = append(opts,
+		grpc.WithUnaryInterceptor(o.unaryClientInterceptor(key)),
+		grpc.WithStreamInterceptor(o.streamClientInterceptor(key)),
+	)
+	if o.stats != nil {
+		opts = append(opts, grpc.WithStatsHandler(&statsHandler{stats: o.stats, id: key}))
+	}
+	proxyOpt, err := o.config.Proxy.dialOption()
+	if err != nil {
+		return nil, err
+	}
+	// proxies can't carry QUIC, their dialer replaces the QUIC one
+	if proxyOpt != nil {
+		opts = append(opts, proxyOpt)
+	}
+	if o.config.DialTimeout <= 0 {
+		return grpc.Dial(address, opts...)
+	}
+
+	done, err := o.breaker.allow(ctx, key)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	opts = append(opts, grpc.WithBlock(), grpc.FailOnNonTempDialError(true))
+	err = o.config.Retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, o.config.DialTimeout)
+		defer cancel()
+		start := time.Now()
+		conn, err = grpc.DialContext(ctx, address, opts...)
+		o.stats.recordDial(key, time.Since(start), err)
+		return err
+	})
+	done(err)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return conn, nil
 }
@@ -5,9 +5,11 @@ package transport
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"storj.io/storj/pkg/ban"
 	"storj.io/storj/pkg/pb"
 	"storj.io/storj/pkg/provider"
 )
@@ -56,4 +58,19 @@ func TestDialNode(t *testing.T) {
 	conn, err = oc.DialNode(ctx, &node)
 	assert.NoError(t, err)
 	assert.NotNil(t, conn)
+
+	// banned node condition test
+	node = pb.Node{
+		Id: "DUMMYID4",
+		Address: &pb.NodeAddress{
+			Transport: pb.NodeTransport_TCP_TLS_GRPC,
+			Address:   "127.0.0.0:9000",
+		},
+	}
+	bans := ban.NewList(3, time.Hour, false)
+	bans.Ban(node.Id, "")
+	oc.SetBans(bans)
+	conn, err = oc.DialNode(ctx, &node)
+	assert.True(t, Error.Has(err))
+	assert.Nil(t, conn)
 }
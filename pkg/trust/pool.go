@@ -0,0 +1,221 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package trust
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"storj.io/storj/pkg/utils"
+)
+
+// maxListSize is the largest list of trusted satellites or signature which
+// is fetched
+const maxListSize = 1 << 20
+
+// Config is the configuration of the satellites a storage node trusts
+type Config struct {
+	Satellites      string        `help:"comma separated list of trusted satellites, as id@host:port; if neither these nor a list url are given, every satellite that isn't excluded is trusted" default:""`
+	ListURL         string        `help:"url of a list of trusted satellites, one id@host:port per line, refreshed periodically" default:""`
+	ListSigner      string        `help:"id of the node whose identity signs the list at the list url; its signature is fetched from the list url with .sig appended" default:""`
+	Exclusions      string        `help:"comma separated list of satellites that are never trusted, as id or id@host:port" default:""`
+	RefreshInterval time.Duration `help:"how often the list of trusted satellites is fetched again" default:"6h"`
+}
+
+// NewPool returns the pool of trusted satellites c describes. It isn't
+// refreshed from the list url before it's run.
+func (c Config) NewPool() (*Pool, error) {
+	if c.ListURL != "" && c.ListSigner == "" {
+		return nil, Error.New("the list url requires a list signer")
+	}
+	satellites, err := ParseSatellites(c.Satellites)
+	if err != nil {
+		return nil, err
+	}
+	var exclusions []string
+	for _, exclusion := range splitList(c.Exclusions) {
+		// only the id of a satellite matters
+		exclusions = append(exclusions, strings.SplitN(exclusion, "@", 2)[0])
+	}
+	pool := NewPool(satellites, c.ListURL, c.ListSigner, exclusions)
+	pool.refreshInterval = c.RefreshInterval
+	return pool, nil
+}
+
+// Pool keeps track of the satellites a storage node trusts: the configured
+// ones and the ones of the list at its url, except the excluded ones. A nil
+// Pool trusts every satellite.
+type Pool struct {
+	listURL         string
+	listSigner      string
+	static          []Satellite
+	excluded        map[string]bool
+	refreshInterval time.Duration
+	client          *http.Client
+
+	mu         sync.RWMutex
+	satellites map[string]Satellite
+}
+
+// NewPool returns a pool trusting satellites and, once refreshed, the
+// satellites of the list at listURL signed by the node with the id
+// listSigner, except the ones with an excluded id
+func NewPool(satellites []Satellite, listURL, listSigner string, excluded []string) *Pool {
+	p := &Pool{
+		listURL:         listURL,
+		listSigner:      listSigner,
+		static:          satellites,
+		excluded:        make(map[string]bool),
+		refreshInterval: 6 * time.Hour,
+		client:          &http.Client{Timeout: time.Minute},
+	}
+	for _, id := range excluded {
+		p.excluded[id] = true
+	}
+	p.satellites = p.merge(nil)
+	return p
+}
+
+// merge returns the satellites of the pool by id, with the listed ones
+func (p *Pool) merge(listed []Satellite) map[string]Satellite {
+	satellites := make(map[string]Satellite)
+	for _, list := range [][]Satellite{listed, p.static} {
+		for _, satellite := range list {
+			if !p.excluded[satellite.ID] {
+				satellites[satellite.ID] = satellite
+			}
+		}
+	}
+	return satellites
+}
+
+// Run refreshes the pool until ctx is canceled
+func (p *Pool) Run(ctx context.Context) {
+	if p == nil || p.listURL == "" {
+		return
+	}
+
+	ticker := time.NewTicker(p.refreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := p.Refresh(ctx); err != nil {
+			zap.S().Errorf("refreshing trusted satellites: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh fetches the list of trusted satellites and its signature again.
+// The pool is left unchanged if that fails or the list isn't signed by the
+// list signer.
+func (p *Pool) Refresh(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	if p.listURL == "" {
+		return nil
+	}
+
+	list, err := p.fetch(ctx, p.listURL)
+	if err != nil {
+		return err
+	}
+	signature, err := p.fetch(ctx, p.listURL+".sig")
+	if err != nil {
+		return err
+	}
+	if err := VerifyList(list, signature, p.listSigner); err != nil {
+		return err
+	}
+
+	listed, err := ReadSatellites(bytes.NewReader(list))
+	if err != nil {
+		return err
+	}
+
+	satellites := p.merge(listed)
+	p.mu.Lock()
+	p.satellites = satellites
+	p.mu.Unlock()
+	return nil
+}
+
+// fetch returns the body at url
+func (p *Pool) fetch(ctx context.Context, url string) (_ []byte, err error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer utils.LogClose(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, Error.New("fetching %s: %s", url, resp.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxListSize))
+	return body, Error.Wrap(err)
+}
+
+// restricted returns whether only the satellites of the pool are trusted
+func (p *Pool) restricted() bool {
+	return len(p.static) > 0 || p.listURL != ""
+}
+
+// VerifyID returns an ErrUntrusted error if the satellite with the given id
+// isn't trusted
+func (p *Pool) VerifyID(id string) error {
+	if p == nil {
+		return nil
+	}
+	if p.excluded[id] {
+		return ErrUntrusted.New("%s is excluded", id)
+	}
+	if !p.restricted() {
+		return nil
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if _, ok := p.satellites[id]; !ok {
+		return ErrUntrusted.New("%s", id)
+	}
+	return nil
+}
+
+// Satellite returns the trusted satellite with the given id, if the pool
+// knows it
+func (p *Pool) Satellite(id string) (satellite Satellite, ok bool) {
+	if p == nil {
+		return Satellite{}, false
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	satellite, ok = p.satellites[id]
+	return satellite, ok
+}
+
+// Satellites returns the satellites of the pool
+func (p *Pool) Satellites() (satellites []Satellite) {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, satellite := range p.satellites {
+		satellites = append(satellites, satellite)
+	}
+	return satellites
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package trust
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/provider"
+)
+
+func TestParseSatellite(t *testing.T) {
+	satellite, err := ParseSatellite(" id1@127.0.0.1:7777 ")
+	assert.NoError(t, err)
+	assert.Equal(t, Satellite{ID: "id1", Address: "127.0.0.1:7777"}, satellite)
+	assert.Equal(t, "id1@127.0.0.1:7777", satellite.String())
+
+	for _, invalid := range []string{"", "id1", "@127.0.0.1:7777", "id1@127.0.0.1"} {
+		_, err := ParseSatellite(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	satellites, err := ParseSatellites("id1@a:1, ,id2@b:2")
+	assert.NoError(t, err)
+	assert.Equal(t, []Satellite{{"id1", "a:1"}, {"id2", "b:2"}}, satellites)
+}
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+
+	var nilPool *Pool
+	assert.NoError(t, nilPool.VerifyID("id1"))
+
+	// without satellites every satellite that isn't excluded is trusted
+	pool, err := Config{Exclusions: "id2@b:2"}.NewPool()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, pool.VerifyID("id1"))
+	assert.True(t, ErrUntrusted.Has(pool.VerifyID("id2")))
+
+	ca, err := provider.NewTestCA(ctx)
+	assert.NoError(t, err)
+	signer, err := ca.NewIdentity()
+	assert.NoError(t, err)
+
+	var list, signature []byte
+	setList := func(s string, identity *provider.FullIdentity) {
+		list = []byte(s)
+		signature, err = SignList(list, identity)
+		assert.NoError(t, err)
+	}
+	setList("# trusted satellites\nid2@b:2\n\nid3@c:3\n", signer)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/satellites":
+			_, _ = w.Write(list)
+		case "/satellites.sig":
+			_, _ = w.Write(signature)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	// lists have to be signed
+	_, err = Config{ListURL: ts.URL + "/satellites"}.NewPool()
+	assert.Error(t, err)
+
+	pool, err = Config{
+		Satellites: "id1@a:1",
+		ListURL:    ts.URL + "/satellites",
+		ListSigner: signer.ID.String(),
+		Exclusions: "id2",
+	}.NewPool()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, pool.VerifyID("id1"))
+	assert.True(t, ErrUntrusted.Has(pool.VerifyID("id3")))
+
+	assert.NoError(t, pool.Refresh(ctx))
+	assert.NoError(t, pool.VerifyID("id1"))
+	assert.True(t, ErrUntrusted.Has(pool.VerifyID("id2")))
+	assert.NoError(t, pool.VerifyID("id3"))
+	assert.Len(t, pool.Satellites(), 2)
+
+	satellite, ok := pool.Satellite("id3")
+	assert.True(t, ok)
+	assert.Equal(t, "c:3", satellite.Address)
+
+	// a broken list leaves the pool unchanged
+	setList("not a satellite", signer)
+	assert.Error(t, pool.Refresh(ctx))
+	assert.NoError(t, pool.VerifyID("id3"))
+
+	// and so do lists which aren't signed by the list signer
+	other, err := ca.NewIdentity()
+	assert.NoError(t, err)
+	otherCA, err := provider.NewTestCA(ctx)
+	assert.NoError(t, err)
+	forger, err := otherCA.NewIdentity()
+	assert.NoError(t, err)
+
+	setList("id4@d:4\n", forger)
+	assert.Error(t, pool.Refresh(ctx))
+	// any leaf of the signer's ca signs for it
+	setList("id4@d:4\n", other)
+	assert.NoError(t, pool.Refresh(ctx))
+	signature = bytes.Replace(signature, []byte("-----BEGIN SIGNATURE-----"), []byte("-----BEGIN OTHER-----"), 1)
+	assert.Error(t, pool.Refresh(ctx))
+	list = []byte("id5@e:5\n")
+	assert.Error(t, pool.Refresh(ctx))
+	assert.True(t, ErrUntrusted.Has(pool.VerifyID("id5")))
+}
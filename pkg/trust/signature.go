@@ -0,0 +1,80 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package trust
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+
+	"storj.io/storj/pkg/peertls"
+	"storj.io/storj/pkg/provider"
+)
+
+// signatureBlockType is the PEM block type of the signature of a list
+const signatureBlockType = "SIGNATURE"
+
+// SignList signs a list of trusted satellites with the key of identity. The
+// returned signature is PEM encoded, following the certificate chain of
+// the identity, so that it can be served next to the list.
+func SignList(list []byte, identity *provider.FullIdentity) ([]byte, error) {
+	signature, err := peertls.SignMessage(identity.Key, list)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	var buf bytes.Buffer
+	if err := peertls.WriteChain(&buf, append([]*x509.Certificate{identity.Leaf, identity.CA}, identity.RestChain...)...); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if err := pem.Encode(&buf, &pem.Block{Type: signatureBlockType, Bytes: signature}); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// VerifyList checks that signature, as returned by SignList, is a signature
+// of list by the node with the id signer
+func VerifyList(list, signature []byte, signer string) error {
+	var rawChain [][]byte
+	var sig []byte
+	for {
+		var block *pem.Block
+		block, signature = pem.Decode(signature)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case peertls.BlockTypeCertificate:
+			rawChain = append(rawChain, block.Bytes)
+		case signatureBlockType:
+			sig = block.Bytes
+		}
+	}
+	if len(sig) == 0 {
+		return Error.New("list signature is missing")
+	}
+	if len(rawChain) < 2 {
+		return Error.New("list signature has an incomplete certificate chain")
+	}
+
+	chain, err := provider.ParseCertChain(rawChain)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	if err := peertls.VerifyPeerCertChains(nil, [][]*x509.Certificate{chain}); err != nil {
+		return Error.Wrap(err)
+	}
+	identity, err := provider.PeerIdentityFromCerts(chain[0], chain[1], chain[2:])
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	if identity.ID.String() != signer {
+		return Error.New("list is signed by %s instead of %s", identity.ID, signer)
+	}
+	if err := peertls.VerifyMessage(identity.Leaf.PublicKey, list, sig); err != nil {
+		return Error.New("invalid list signature: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package trust
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/zeebo/errs"
+	monkit "gopkg.in/spacemonkeygo/monkit.v2"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the default trust errs class
+	Error = errs.Class("trust error")
+	// ErrUntrusted is used when a satellite isn't trusted
+	ErrUntrusted = errs.Class("untrusted satellite")
+)
+
+// Satellite is a satellite a storage node works with. It's written as
+// id@host:port; as connections to it verify its node id, the id signs the
+// address.
+type Satellite struct {
+	ID      string
+	Address string
+}
+
+// String returns the satellite as id@host:port
+func (s Satellite) String() string {
+	return s.ID + "@" + s.Address
+}
+
+// ParseSatellite parses a satellite written as id@host:port
+func ParseSatellite(s string) (Satellite, error) {
+	s = strings.TrimSpace(s)
+	parts := strings.SplitN(s, "@", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return Satellite{}, Error.New("invalid satellite %q: expected id@host:port", s)
+	}
+	if _, _, err := net.SplitHostPort(parts[1]); err != nil {
+		return Satellite{}, Error.New("invalid satellite %q: %v", s, err)
+	}
+	return Satellite{ID: parts[0], Address: parts[1]}, nil
+}
+
+// ParseSatellites parses a comma separated list of satellites
+func ParseSatellites(s string) (satellites []Satellite, err error) {
+	for _, entry := range splitList(s) {
+		satellite, err := ParseSatellite(entry)
+		if err != nil {
+			return nil, err
+		}
+		satellites = append(satellites, satellite)
+	}
+	return satellites, nil
+}
+
+// ReadSatellites reads a list of satellites, one per line. Empty lines and
+// lines starting with # are skipped.
+func ReadSatellites(r io.Reader) (satellites []Satellite, err error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		satellite, err := ParseSatellite(line)
+		if err != nil {
+			return nil, err
+		}
+		satellites = append(satellites, satellite)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return satellites, nil
+}
+
+// splitList returns the trimmed, non empty entries of a comma separated list
+func splitList(s string) (entries []string) {
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,203 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package uplink
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"storj.io/storj/pkg/storage/meta"
+	"storj.io/storj/pkg/storage/objects"
+	"storj.io/storj/pkg/storj"
+)
+
+// Bucket is a bucket of a project, opened for transferring its objects
+type Bucket struct {
+	name  string
+	store objects.Store
+}
+
+// Object is the information about an object, or a prefix of objects in a
+// listing
+type Object struct {
+	Bucket   string
+	Path     storj.Path
+	IsPrefix bool
+
+	ContentType string
+	Metadata    map[string]string
+	Modified    time.Time
+	Expires     time.Time
+	Size        int64
+}
+
+// objectFromMeta returns the information about the object at path of bucket
+// with the metadata m
+func objectFromMeta(bucket string, path storj.Path, m objects.Meta) Object {
+	return Object{
+		Bucket:      bucket,
+		Path:        path,
+		ContentType: m.ContentType,
+		Metadata:    m.UserDefined,
+		Modified:    m.Modified,
+		Expires:     m.Expiration,
+		Size:        m.Size,
+	}
+}
+
+// UploadOption configures an upload
+type UploadOption func(*objects.SerializableMeta, *time.Time)
+
+// WithContentType sets the content type of the uploaded object. The objects
+// uploaded without one get the content type of their extension, if any.
+func WithContentType(contentType string) UploadOption {
+	return func(m *objects.SerializableMeta, expires *time.Time) { m.ContentType = contentType }
+}
+
+// WithMetadata sets the metadata of the uploaded object
+func WithMetadata(metadata map[string]string) UploadOption {
+	return func(m *objects.SerializableMeta, expires *time.Time) { m.UserDefined = metadata }
+}
+
+// WithExpiration sets the time the uploaded object expires at
+func WithExpiration(expiration time.Time) UploadOption {
+	return func(m *objects.SerializableMeta, expires *time.Time) { *expires = expiration }
+}
+
+// Name returns the name of the bucket
+func (b *Bucket) Name() string { return b.name }
+
+// Upload uploads the object at path, reading its data from data until EOF,
+// replacing the object at path if there's one
+func (b *Bucket) Upload(ctx context.Context, path storj.Path, data io.Reader, opts ...UploadOption) (object Object, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var metadata objects.SerializableMeta
+	var expiration time.Time
+	for _, opt := range opts {
+		opt(&metadata, &expiration)
+	}
+
+	m, err := b.store.Put(ctx, path, data, metadata, expiration)
+	if err != nil {
+		return Object{}, err
+	}
+	return objectFromMeta(b.name, path, m), nil
+}
+
+// Download returns a reader of the data of the object at path, which must be
+// closed
+func (b *Bucket) Download(ctx context.Context, path storj.Path) (io.ReadCloser, error) {
+	return b.DownloadRange(ctx, path, 0, -1)
+}
+
+// DownloadRange returns a reader of length bytes of the data of the object
+// at path, from offset, or of the rest of them if length is -1. The reader
+// must be closed.
+func (b *Bucket) DownloadRange(ctx context.Context, path storj.Path, offset, length int64) (r io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	rr, _, err := b.store.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 {
+		length = rr.Size() - offset
+	}
+	if offset < 0 || length < 0 || offset+length > rr.Size() {
+		return nil, Error.New("invalid range %d-%d of object %s/%s of %d bytes", offset, offset+length, b.name, path, rr.Size())
+	}
+	return rr.Range(ctx, offset, length)
+}
+
+// Stat returns the information about the object at path
+func (b *Bucket) Stat(ctx context.Context, path storj.Path) (object Object, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	m, err := b.store.Meta(ctx, path)
+	if err != nil {
+		return Object{}, err
+	}
+	return objectFromMeta(b.name, path, m), nil
+}
+
+// Delete deletes the object at path
+func (b *Bucket) Delete(ctx context.Context, path storj.Path) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	return b.store.Delete(ctx, path)
+}
+
+// Copy copies the object at src to dst, sharing its data
+func (b *Bucket) Copy(ctx context.Context, src, dst storj.Path) (object Object, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	m, err := b.store.Copy(ctx, src, dst)
+	if err != nil {
+		return Object{}, err
+	}
+	return objectFromMeta(b.name, dst, m), nil
+}
+
+// ListOptions are the options of a listing of the objects of a bucket
+type ListOptions struct {
+	// Prefix is the prefix the listed objects are under
+	Prefix storj.Path
+	// Cursor is the path, relative to Prefix, the objects are listed after
+	Cursor storj.Path
+	// Recursive lists the objects under the prefixes of the objects under
+	// Prefix too, instead of the prefixes
+	Recursive bool
+	// Limit is the maximum number of the objects listed, 0 lists as many as
+	// the satellite allows
+	Limit int
+}
+
+// ObjectList is a page of a listing of the objects of a bucket
+type ObjectList struct {
+	// Items are the objects listed, whose paths are full paths
+	Items []Object
+	// More is whether there are more objects after the last of Items
+	More bool
+}
+
+// NextPage returns the options listing the objects after the ones of list,
+// or nil if there are none
+func (opts ListOptions) NextPage(list ObjectList) *ListOptions {
+	if !list.More || len(list.Items) == 0 {
+		return nil
+	}
+	last := list.Items[len(list.Items)-1].Path
+	opts.Cursor = strings.TrimPrefix(last, listDir(opts.Prefix))
+	return &opts
+}
+
+// listDir returns the prefix the paths listed under prefix are relative to
+func listDir(prefix storj.Path) string {
+	dir := strings.TrimSuffix(prefix, "/")
+	if dir != "" {
+		dir += "/"
+	}
+	return dir
+}
+
+// List lists the objects of the bucket under the prefix of opts
+func (b *Bucket) List(ctx context.Context, opts ListOptions) (list ObjectList, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	items, more, err := b.store.List(ctx, opts.Prefix, opts.Cursor, "", opts.Recursive, opts.Limit, meta.All)
+	if err != nil {
+		return ObjectList{}, err
+	}
+
+	dir := listDir(opts.Prefix)
+	list = ObjectList{Items: make([]Object, 0, len(items)), More: more}
+	for _, item := range items {
+		object := objectFromMeta(b.name, dir+item.Path, item.Meta)
+		object.IsPrefix = item.IsPrefix
+		list.Items = append(list.Items, object)
+	}
+	return list, nil
+}
@@ -0,0 +1,191 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package uplink
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"storj.io/storj/pkg/ranger"
+	"storj.io/storj/pkg/storage/objects"
+	"storj.io/storj/pkg/storj"
+	"storj.io/storj/storage"
+)
+
+// memObjects is an objects.Store keeping its objects in memory
+type memObjects map[storj.Path]memObject
+
+type memObject struct {
+	data []byte
+	meta objects.Meta
+}
+
+func (m memObjects) Meta(ctx context.Context, path storj.Path) (objects.Meta, error) {
+	object, ok := m[path]
+	if !ok {
+		return objects.Meta{}, storage.ErrKeyNotFound.New("%s", path)
+	}
+	return object.meta, nil
+}
+
+func (m memObjects) Get(ctx context.Context, path storj.Path) (ranger.Ranger, objects.Meta, error) {
+	object, ok := m[path]
+	if !ok {
+		return nil, objects.Meta{}, storage.ErrKeyNotFound.New("%s", path)
+	}
+	return ranger.ByteRanger(object.data), object.meta, nil
+}
+
+func (m memObjects) Put(ctx context.Context, path storj.Path, data io.Reader, metadata objects.SerializableMeta, expiration time.Time) (objects.Meta, error) {
+	b, err := ioutil.ReadAll(data)
+	if err != nil {
+		return objects.Meta{}, err
+	}
+	meta := objects.Meta{SerializableMeta: metadata, Modified: time.Now(), Expiration: expiration, Size: int64(len(b))}
+	m[path] = memObject{data: b, meta: meta}
+	return meta, nil
+}
+
+func (m memObjects) Delete(ctx context.Context, path storj.Path) error {
+	if _, ok := m[path]; !ok {
+		return storage.ErrKeyNotFound.New("%s", path)
+	}
+	delete(m, path)
+	return nil
+}
+
+func (m memObjects) Copy(ctx context.Context, src, dst storj.Path) (objects.Meta, error) {
+	object, ok := m[src]
+	if !ok {
+		return objects.Meta{}, storage.ErrKeyNotFound.New("%s", src)
+	}
+	m[dst] = object
+	return object.meta, nil
+}
+
+func (m memObjects) List(ctx context.Context, prefix, startAfter, endBefore storj.Path, recursive bool, limit int, metaFlags uint32) (items []objects.ListItem, more bool, err error) {
+	dir := listDir(prefix)
+	var paths []storj.Path
+	seen := map[storj.Path]bool{}
+	for path := range m {
+		if !strings.HasPrefix(path, dir) {
+			continue
+		}
+		rel := strings.TrimPrefix(path, dir)
+		if i := strings.Index(rel, "/"); !recursive && i >= 0 {
+			rel = rel[:i+1]
+		}
+		if rel > startAfter && !seen[rel] {
+			seen[rel] = true
+			paths = append(paths, rel)
+		}
+	}
+	sort.Strings(paths)
+	if limit > 0 && len(paths) > limit {
+		paths, more = paths[:limit], true
+	}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "/") {
+			items = append(items, objects.ListItem{Path: path, IsPrefix: true})
+			continue
+		}
+		items = append(items, objects.ListItem{Path: path, Meta: m[dir+path].meta})
+	}
+	return items, more, nil
+}
+
+func TestBucket(t *testing.T) {
+	ctx := context.Background()
+	b := &Bucket{name: "bucket", store: memObjects{}}
+	assert.Equal(t, "bucket", b.Name())
+
+	expiration := time.Now().Add(time.Hour)
+	object, err := b.Upload(ctx, "photos/summer.jpg", bytes.NewReader([]byte("summer")),
+		WithContentType("image/jpeg"), WithMetadata(map[string]string{"place": "beach"}), WithExpiration(expiration))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "bucket", object.Bucket)
+	assert.Equal(t, "photos/summer.jpg", object.Path)
+	assert.Equal(t, "image/jpeg", object.ContentType)
+	assert.Equal(t, map[string]string{"place": "beach"}, object.Metadata)
+	assert.Equal(t, expiration, object.Expires)
+	assert.EqualValues(t, 6, object.Size)
+
+	stat, err := b.Stat(ctx, "photos/summer.jpg")
+	if assert.NoError(t, err) {
+		assert.Equal(t, object, stat)
+	}
+
+	for _, tt := range []struct {
+		offset, length int64
+		data           string
+	}{
+		{0, -1, "summer"},
+		{2, -1, "mmer"},
+		{1, 3, "umm"},
+		{6, 0, ""},
+	} {
+		r, err := b.DownloadRange(ctx, "photos/summer.jpg", tt.offset, tt.length)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		data, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.data, string(data))
+		assert.NoError(t, r.Close())
+	}
+	for _, invalid := range [][2]int64{{-1, 2}, {2, 5}, {7, -1}} {
+		_, err := b.DownloadRange(ctx, "photos/summer.jpg", invalid[0], invalid[1])
+		assert.True(t, Error.Has(err), invalid)
+	}
+
+	_, err = b.Download(ctx, "photos/winter.jpg")
+	assert.True(t, storage.ErrKeyNotFound.Has(err))
+
+	copied, err := b.Copy(ctx, "photos/summer.jpg", "photos/2018/summer.jpg")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "photos/2018/summer.jpg", copied.Path)
+		assert.Equal(t, object.Metadata, copied.Metadata)
+	}
+	_, err = b.Upload(ctx, "notes.txt", bytes.NewReader(nil))
+	assert.NoError(t, err)
+
+	list, err := b.List(ctx, ListOptions{Prefix: "photos"})
+	if assert.NoError(t, err) {
+		assert.False(t, list.More)
+		if assert.Len(t, list.Items, 2) {
+			assert.Equal(t, "photos/2018/", list.Items[0].Path)
+			assert.True(t, list.Items[0].IsPrefix)
+			assert.Equal(t, "photos/summer.jpg", list.Items[1].Path)
+			assert.False(t, list.Items[1].IsPrefix)
+		}
+	}
+
+	// the pages of a listing continue after the last object of the one before
+	var paths []storj.Path
+	for opts := &(ListOptions{Recursive: true, Limit: 2}); opts != nil; {
+		list, err := b.List(ctx, *opts)
+		if !assert.NoError(t, err) {
+			break
+		}
+		for _, item := range list.Items {
+			paths = append(paths, item.Path)
+		}
+		opts = opts.NextPage(list)
+	}
+	assert.Equal(t, []storj.Path{"notes.txt", "photos/2018/summer.jpg", "photos/summer.jpg"}, paths)
+
+	assert.NoError(t, b.Delete(ctx, "photos/summer.jpg"))
+	_, err = b.Stat(ctx, "photos/summer.jpg")
+	assert.True(t, storage.ErrKeyNotFound.Has(err))
+}
@@ -0,0 +1,203 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package uplink is the Go library for storing objects on the Storj network,
+// for applications embedding an uplink instead of running the uplink CLI or
+// the S3 gateway:
+//
+//	project, err := uplink.Open(ctx, "satellite.example.com:7777", apiKey,
+//		uplink.WithEncryptionKey(passphrase))
+//	...
+//	bucket, err := project.OpenBucket(ctx, "photos")
+//	...
+//	object, err := bucket.Upload(ctx, "2018/summer.jpg", file)
+//
+// The objects and buckets which don't exist fail with errors of the class
+// storage.ErrKeyNotFound.
+package uplink
+
+import (
+	"context"
+	"flag"
+
+	minio "github.com/minio/minio/cmd"
+	"github.com/zeebo/errs"
+	monkit "gopkg.in/spacemonkeygo/monkit.v2"
+
+	"storj.io/storj/pkg/cfgstruct"
+	"storj.io/storj/pkg/metainfo/kvmetainfo"
+	"storj.io/storj/pkg/miniogw"
+	"storj.io/storj/pkg/node"
+	"storj.io/storj/pkg/provider"
+	"storj.io/storj/pkg/storage/buckets"
+	"storj.io/storj/pkg/storj"
+	"storj.io/storj/storage"
+)
+
+var mon = monkit.Package()
+
+// Error is the error class of the uplink library
+var Error = errs.Class("uplink error")
+
+// identityDifficulty is the difficulty of the identities generated for the
+// projects opened without one, which satellites accept by default
+const identityDifficulty = 12
+
+// Option configures the project opened by Open
+type Option func(*options)
+
+type options struct {
+	config   miniogw.Config
+	identity *provider.FullIdentity
+}
+
+// newOptions returns the options of opts, applied to the defaults of the
+// uplink configuration
+func newOptions(satelliteAddr, apiKey string, opts []Option) *options {
+	o := &options{}
+	cfgstruct.Bind(flag.NewFlagSet("uplink", flag.ContinueOnError), &o.config)
+	o.config.OverlayAddr = satelliteAddr
+	o.config.PointerDBAddr = satelliteAddr
+	o.config.APIKey = apiKey
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithEncryptionKey sets the passphrase the root key the objects are
+// encrypted with is derived from
+func WithEncryptionKey(passphrase string) Option {
+	return func(o *options) {
+		o.config.EncKey = passphrase
+		o.config.EncKeyDerivation = "argon2id"
+	}
+}
+
+// WithAccess opens the project with an access to the paths under a prefix,
+// printed by uplink share, which is used instead of the api key and the
+// encryption passphrase
+func WithAccess(access string) Option {
+	return func(o *options) { o.config.Access = access }
+}
+
+// WithIdentity sets the identity the project is opened with. The projects
+// opened without one generate an identity of their own, which takes a few
+// seconds.
+func WithIdentity(identity *provider.FullIdentity) Option {
+	return func(o *options) { o.identity = identity }
+}
+
+// WithRedundancy sets the Reed-Solomon scheme of the new objects of the
+// buckets without one of their own: min pieces are needed to recover a
+// segment, it's repaired when less than repair are left, and it's uploaded
+// when success of its max pieces are
+func WithRedundancy(min, repair, success, max int) Option {
+	return func(o *options) {
+		o.config.MinThreshold = min
+		o.config.RepairThreshold = repair
+		o.config.SuccessThreshold = success
+		o.config.MaxThreshold = max
+	}
+}
+
+// WithSegmentSize sets the size of the segments of the new objects of the
+// buckets without one of their own
+func WithSegmentSize(size int64) Option {
+	return func(o *options) { o.config.SegmentSize = size }
+}
+
+// WithRateLimits sets the maximum bytes per second uploaded and downloaded
+// by all the transfers of the project together, 0 doesn't limit them
+func WithRateLimits(upload, download int64) Option {
+	return func(o *options) {
+		o.config.MaxUploadRate = upload
+		o.config.MaxDownloadRate = download
+	}
+}
+
+// WithConfig changes the uplink configuration the project is opened with,
+// for the settings without an option of their own
+func WithConfig(change func(config *miniogw.Config)) Option {
+	return func(o *options) { change(&o.config) }
+}
+
+// Project is the buckets of an api key on a satellite
+type Project struct {
+	store   buckets.Store
+	buckets *kvmetainfo.Buckets
+	close   func() error
+}
+
+// Open opens the project of apiKey on the satellite at satelliteAddr. The
+// objects are encrypted with the passphrase set by WithEncryptionKey, or
+// with the access set by WithAccess.
+func Open(ctx context.Context, satelliteAddr, apiKey string, opts ...Option) (p *Project, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	o := newOptions(satelliteAddr, apiKey, opts)
+	if o.config.EncKey == "" && o.config.Access == "" {
+		return nil, Error.New("no encryption passphrase or access")
+	}
+
+	identity := o.identity
+	if identity == nil {
+		identity, err = node.NewFullIdentity(ctx, identityDifficulty, 0)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+	}
+
+	store, close, err := o.config.OpenBucketStore(ctx, identity)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return &Project{store: store, buckets: kvmetainfo.NewBuckets(store), close: close}, nil
+}
+
+// Close closes the connections of the project to the satellite and to the
+// storage nodes. The project and its buckets can't be used afterwards.
+func (p *Project) Close() error {
+	return Error.Wrap(p.close())
+}
+
+// CreateBucket creates the bucket name. Its objects are stored with the
+// redundancy scheme and the segment size of info, if it's set, or with the
+// ones of the project.
+func (p *Project) CreateBucket(ctx context.Context, name string, info *storj.Bucket) (b storj.Bucket, err error) {
+	defer mon.Task()(&ctx)(&err)
+	return p.buckets.CreateBucket(ctx, name, info)
+}
+
+// GetBucket returns the bucket name
+func (p *Project) GetBucket(ctx context.Context, name string) (b storj.Bucket, err error) {
+	defer mon.Task()(&ctx)(&err)
+	return p.buckets.GetBucket(ctx, name)
+}
+
+// DeleteBucket deletes the bucket name. Its objects aren't deleted, so it
+// should be emptied first.
+func (p *Project) DeleteBucket(ctx context.Context, name string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	return p.buckets.DeleteBucket(ctx, name)
+}
+
+// ListBuckets lists the buckets of the project
+func (p *Project) ListBuckets(ctx context.Context, opts storj.BucketListOptions) (list storj.BucketList, err error) {
+	defer mon.Task()(&ctx)(&err)
+	return p.buckets.ListBuckets(ctx, opts)
+}
+
+// OpenBucket opens the bucket name for transferring its objects
+func (p *Project) OpenBucket(ctx context.Context, name string) (b *Bucket, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	objects, err := p.store.GetObjectStore(ctx, name)
+	if _, ok := err.(minio.BucketNotFound); ok {
+		return nil, storage.ErrKeyNotFound.New("%s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Bucket{name: name, store: objects}, nil
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package uplink
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebo/errs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"storj.io/storj/pkg/miniogw"
+	"storj.io/storj/pkg/provider"
+	"storj.io/storj/pkg/storj"
+)
+
+func TestOptions(t *testing.T) {
+	o := newOptions("satellite:7777", "api key", nil)
+	assert.Equal(t, "satellite:7777", o.config.OverlayAddr)
+	assert.Equal(t, "satellite:7777", o.config.PointerDBAddr)
+	assert.Equal(t, "api key", o.config.APIKey)
+	// the options not set are the defaults of the uplink
+	assert.Equal(t, 29, o.config.MinThreshold)
+	assert.EqualValues(t, 64000000, o.config.SegmentSize)
+	assert.Nil(t, o.identity)
+
+	identity := &provider.FullIdentity{}
+	o = newOptions("satellite:7777", "api key", []Option{
+		WithEncryptionKey("passphrase"),
+		WithAccess("access"),
+		WithIdentity(identity),
+		WithRedundancy(2, 3, 4, 5),
+		WithSegmentSize(1024),
+		WithRateLimits(100, 200),
+		WithConfig(func(config *miniogw.Config) { config.ParallelUploads = 1 }),
+	})
+	assert.Equal(t, "passphrase", o.config.EncKey)
+	assert.Equal(t, "argon2id", o.config.EncKeyDerivation)
+	assert.Equal(t, "access", o.config.Access)
+	assert.Equal(t, identity, o.identity)
+	assert.Equal(t, []int{2, 3, 4, 5}, []int{o.config.MinThreshold, o.config.RepairThreshold, o.config.SuccessThreshold, o.config.MaxThreshold})
+	assert.EqualValues(t, 1024, o.config.SegmentSize)
+	assert.EqualValues(t, 100, o.config.MaxUploadRate)
+	assert.EqualValues(t, 200, o.config.MaxDownloadRate)
+	assert.Equal(t, 1, o.config.ParallelUploads)
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := Open(ctx, "127.0.0.1:0", "api key")
+	assert.True(t, Error.Has(err))
+
+	ca, err := provider.NewTestCA(ctx)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	identity, err := ca.NewIdentity()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// the satellite isn't dialed until the project is used
+	goroutines := runtime.NumGoroutine()
+	p, err := Open(ctx, "127.0.0.1:0", "api key", WithEncryptionKey("passphrase"), WithIdentity(identity))
+	if assert.NoError(t, err) {
+		assert.NotNil(t, p)
+
+		// closing the project closes its connections and stops their
+		// goroutines, so it can't be used anymore
+		assert.NoError(t, p.Close())
+		_, err = p.ListBuckets(ctx, storj.BucketListOptions{Direction: storj.Forward})
+		assert.Equal(t, codes.Canceled, status.Code(errs.Unwrap(err)))
+		for i := 0; i < 100 && runtime.NumGoroutine() > goroutines; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.True(t, runtime.NumGoroutine() <= goroutines)
+	}
+
+	_, err = Open(ctx, "127.0.0.1:0", "api key", WithAccess("not base58 0OIl"), WithIdentity(identity))
+	assert.True(t, Error.Has(err))
+}
@@ -5,10 +5,13 @@ package boltdb
 
 import (
 	"bytes"
+	"encoding/binary"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/boltdb/bolt"
+	"go.uber.org/zap"
 
 	"storj.io/storj/pkg/utils"
 	"storj.io/storj/storage"
@@ -19,8 +22,16 @@ type Client struct {
 	db     *bolt.DB
 	Path   string
 	Bucket []byte
+	// TTL is the time after which values put with Put expire, if not 0
+	TTL time.Duration
 
 	referenceCount *int32
+	// mu is held exclusively while the database is replaced by Compact
+	mu sync.RWMutex
+
+	sweeper sync.Once
+	stop    sync.Once
+	done    chan struct{}
 }
 
 const (
@@ -29,6 +40,20 @@ const (
 	defaultTimeout = 1 * time.Second
 )
 
+// sweepInterval is how often expired values are deleted
+var sweepInterval = time.Minute
+
+// the expiration times of the values of a bucket are kept in the nested
+// buckets of the bucket named after it with the expirationsSuffix. One maps
+// keys to their expiration time, the other indexes the keys by their
+// expiration time, so the sweeper finds the expired ones in order.
+var (
+	expirationsSuffix  = []byte("/expirations")
+	expirationsByKey   = []byte("keys")
+	expirationsByTime  = []byte("times")
+	expirationTimeSize = 8
+)
+
 // New instantiates a new BoltDB client given db file path, and a bucket name
 func New(path, bucket string) (*Client, error) {
 	db, err := bolt.Open(path, fileMode, &bolt.Options{Timeout: defaultTimeout})
@@ -51,12 +76,15 @@ func New(path, bucket string) (*Client, error) {
 	refCount := new(int32)
 	*refCount = 1
 
-	return &Client{
+	client := &Client{
 		db:             db,
 		referenceCount: refCount,
 		Path:           path,
 		Bucket:         []byte(bucket),
-	}, nil
+		done:           make(chan struct{}),
+	}
+	client.resumeSweeper()
+	return client, nil
 }
 
 // NewShared instantiates a new BoltDB with multiple buckets
@@ -88,37 +116,99 @@ func NewShared(path string, buckets ...string) ([]*Client, error) {
 
 	clients := []*Client{}
 	for _, bucket := range buckets {
-		clients = append(clients, &Client{
+		client := &Client{
 			db:             db,
 			referenceCount: refCount,
 			Path:           path,
 			Bucket:         []byte(bucket),
-		})
+			done:           make(chan struct{}),
+		}
+		client.resumeSweeper()
+		clients = append(clients, client)
 	}
 
 	return clients, nil
 }
 
 func (client *Client) update(fn func(*bolt.Bucket) error) error {
-	return client.db.Update(func(tx *bolt.Tx) error {
+	return client.updateTx(func(tx *bolt.Tx) error {
 		return fn(tx.Bucket(client.Bucket))
 	})
 }
 
 func (client *Client) view(fn func(*bolt.Bucket) error) error {
-	return client.db.View(func(tx *bolt.Tx) error {
+	return client.viewTx(func(tx *bolt.Tx) error {
 		return fn(tx.Bucket(client.Bucket))
 	})
 }
 
-// Put adds a value to the provided key in boltdb, returning an error on failure.
+func (client *Client) updateTx(fn func(*bolt.Tx) error) error {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+	if client.db == nil {
+		return errNotReopened.New("%s", client.Path)
+	}
+	return client.db.Update(fn)
+}
+
+func (client *Client) viewTx(fn func(*bolt.Tx) error) error {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+	if client.db == nil {
+		return errNotReopened.New("%s", client.Path)
+	}
+	return client.db.View(fn)
+}
+
+// Put adds a value to the provided key in boltdb, which expires after the TTL
+// of the client, returning an error on failure.
 func (client *Client) Put(key storage.Key, value storage.Value) error {
+	return client.PutWithTTL(key, value, client.TTL)
+}
+
+// PutWithTTL adds a value to the provided key in boltdb, which expires after
+// ttl, or never if it's 0, returning an error on failure. Expired values are
+// deleted by a sweeper, so they may be read until it runs the next time.
+func (client *Client) PutWithTTL(key storage.Key, value storage.Value, ttl time.Duration) error {
 	if len(key) == 0 {
 		return Error.New("invalid key")
 	}
-	return client.update(func(bucket *bolt.Bucket) error {
-		return bucket.Put(key, value)
+	err := client.updateTx(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(client.Bucket).Put(key, value); err != nil {
+			return err
+		}
+		return client.setExpiration(tx, key, ttl)
 	})
+	if err == nil && ttl > 0 {
+		client.startSweeper()
+	}
+	return err
+}
+
+// PutAll adds the values of all items to boltdb in a single transaction, which
+// expire after the TTL of the client, returning an error on failure.
+func (client *Client) PutAll(items storage.Items) error {
+	for _, item := range items {
+		if len(item.Key) == 0 {
+			return Error.New("invalid key")
+		}
+	}
+	err := client.updateTx(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(client.Bucket)
+		for _, item := range items {
+			if err := bucket.Put(item.Key, item.Value); err != nil {
+				return err
+			}
+			if err := client.setExpiration(tx, item.Key, client.TTL); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil && client.TTL > 0 && len(items) > 0 {
+		client.startSweeper()
+	}
+	return err
 }
 
 // Get looks up the provided key from boltdb returning either an error or the result.
@@ -137,11 +227,225 @@ func (client *Client) Get(key storage.Key) (storage.Value, error) {
 
 // Delete deletes a key/value pair from boltdb, for a given the key
 func (client *Client) Delete(key storage.Key) error {
-	return client.update(func(bucket *bolt.Bucket) error {
-		return bucket.Delete(key)
+	return client.updateTx(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(client.Bucket).Delete(key); err != nil {
+			return err
+		}
+		return client.setExpiration(tx, key, 0)
+	})
+}
+
+// DeleteAll deletes all keys and their values from boltdb in a single
+// transaction
+func (client *Client) DeleteAll(keys storage.Keys) error {
+	return client.updateTx(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(client.Bucket)
+		for _, key := range keys {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+			if err := client.setExpiration(tx, key, 0); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 }
 
+// CompareAndSwap sets the value of key to new if it's old, in a single
+// transaction, or fails with storage.ErrValueChanged otherwise.
+func (client *Client) CompareAndSwap(key storage.Key, old, new storage.Value) error {
+	if len(key) == 0 {
+		return Error.New("invalid key")
+	}
+	err := client.updateTx(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(client.Bucket)
+		// empty values can't be told apart from missing ones, like in Get
+		current := bucket.Get(key)
+		if (old == nil) != (len(current) == 0) || !bytes.Equal(old, current) {
+			return storage.ErrValueChanged.New("%s", key.String())
+		}
+
+		if new == nil {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+			return client.setExpiration(tx, key, 0)
+		}
+		if err := bucket.Put(key, new); err != nil {
+			return err
+		}
+		return client.setExpiration(tx, key, client.TTL)
+	})
+	if err == nil && new != nil && client.TTL > 0 {
+		client.startSweeper()
+	}
+	return err
+}
+
+// Tx calls fn with the operations of a bolt transaction, which is committed
+// if fn returns nil and rolled back otherwise. Values put in it expire after
+// the TTL of the client.
+func (client *Client) Tx(fn func(storage.Ops) error) error {
+	ops := &txOps{client: client}
+	err := client.updateTx(func(tx *bolt.Tx) error {
+		ops.tx = tx
+		return fn(ops)
+	})
+	if err == nil && ops.put && client.TTL > 0 {
+		client.startSweeper()
+	}
+	return err
+}
+
+// txOps are the storage.Ops of a bolt transaction
+type txOps struct {
+	client *Client
+	tx     *bolt.Tx
+	put    bool
+}
+
+// Get looks up the provided key in the transaction
+func (ops *txOps) Get(key storage.Key) (storage.Value, error) {
+	data := ops.tx.Bucket(ops.client.Bucket).Get(key)
+	if len(data) == 0 {
+		return nil, storage.ErrKeyNotFound.New("%s", key.String())
+	}
+	return storage.CloneValue(storage.Value(data)), nil
+}
+
+// Put adds a value to the provided key in the transaction, which expires
+// after the TTL of the client
+func (ops *txOps) Put(key storage.Key, value storage.Value) error {
+	if len(key) == 0 {
+		return Error.New("invalid key")
+	}
+	if err := ops.tx.Bucket(ops.client.Bucket).Put(key, value); err != nil {
+		return err
+	}
+	ops.put = true
+	return ops.client.setExpiration(ops.tx, key, ops.client.TTL)
+}
+
+// Delete deletes a key/value pair in the transaction
+func (ops *txOps) Delete(key storage.Key) error {
+	if err := ops.tx.Bucket(ops.client.Bucket).Delete(key); err != nil {
+		return err
+	}
+	return ops.client.setExpiration(ops.tx, key, 0)
+}
+
+// setExpiration makes the value of key expire after ttl, or never if it's 0
+func (client *Client) setExpiration(tx *bolt.Tx, key storage.Key, ttl time.Duration) error {
+	expirations := tx.Bucket(client.expirationsBucket())
+	if expirations == nil {
+		if ttl <= 0 {
+			return nil
+		}
+		var err error
+		expirations, err = tx.CreateBucket(client.expirationsBucket())
+		if err != nil {
+			return err
+		}
+		if _, err := expirations.CreateBucket(expirationsByKey); err != nil {
+			return err
+		}
+		if _, err := expirations.CreateBucket(expirationsByTime); err != nil {
+			return err
+		}
+	}
+	byKey, byTime := expirations.Bucket(expirationsByKey), expirations.Bucket(expirationsByTime)
+
+	if expiration := byKey.Get(key); expiration != nil {
+		if err := byTime.Delete(append(storage.CloneKey(expiration), key...)); err != nil {
+			return err
+		}
+		if err := byKey.Delete(key); err != nil {
+			return err
+		}
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	expiration := make([]byte, expirationTimeSize)
+	binary.BigEndian.PutUint64(expiration, uint64(time.Now().Add(ttl).UnixNano()))
+	if err := byKey.Put(key, expiration); err != nil {
+		return err
+	}
+	return byTime.Put(append(expiration, key...), []byte{})
+}
+
+func (client *Client) expirationsBucket() []byte {
+	return append(append([]byte{}, client.Bucket...), expirationsSuffix...)
+}
+
+// sweep deletes the values which expired by now
+func (client *Client) sweep(now time.Time) (deleted int, err error) {
+	limit := make([]byte, expirationTimeSize)
+	binary.BigEndian.PutUint64(limit, uint64(now.UnixNano()))
+
+	err = client.updateTx(func(tx *bolt.Tx) error {
+		expirations := tx.Bucket(client.expirationsBucket())
+		if expirations == nil {
+			return nil
+		}
+		bucket := tx.Bucket(client.Bucket)
+		byKey, byTime := expirations.Bucket(expirationsByKey), expirations.Bucket(expirationsByTime)
+
+		// deleting moves the cursor, so it starts over after every deletion
+		cursor := byTime.Cursor()
+		for entry, _ := cursor.First(); entry != nil && bytes.Compare(entry[:expirationTimeSize], limit) <= 0; entry, _ = cursor.First() {
+			key := storage.CloneKey(entry[expirationTimeSize:])
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+			if err := byKey.Delete(key); err != nil {
+				return err
+			}
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+			deleted++
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// startSweeper starts deleting expired values every sweepInterval, until the
+// client is closed
+func (client *Client) startSweeper() {
+	client.sweeper.Do(func() {
+		go func() {
+			ticker := time.NewTicker(sweepInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-client.done:
+					return
+				case now := <-ticker.C:
+					if _, err := client.sweep(now); err != nil {
+						zap.L().Warn("failed to delete expired values", zap.ByteString("bucket", client.Bucket), zap.Error(err))
+					}
+				}
+			}
+		}()
+	})
+}
+
+// resumeSweeper starts the sweeper if there are values which expire already
+func (client *Client) resumeSweeper() {
+	var expiring bool
+	_ = client.viewTx(func(tx *bolt.Tx) error {
+		expiring = tx.Bucket(client.expirationsBucket()) != nil
+		return nil
+	})
+	if expiring {
+		client.startSweeper()
+	}
+}
+
 // List returns either a list of keys for which boltdb has values or an error.
 func (client *Client) List(first storage.Key, limit int) (storage.Keys, error) {
 	return storage.ListKeys(client, first, limit)
@@ -153,9 +457,20 @@ func (client *Client) ReverseList(first storage.Key, limit int) (storage.Keys, e
 	return storage.ReverseListKeys(client, first, limit)
 }
 
+// Ping checks whether the database is still open
+func (client *Client) Ping() error {
+	return Error.Wrap(client.viewTx(func(tx *bolt.Tx) error { return nil }))
+}
+
 // Close closes a BoltDB client
 func (client *Client) Close() error {
+	client.stop.Do(func() { close(client.done) })
 	if atomic.AddInt32(client.referenceCount, -1) == 0 {
+		client.mu.Lock()
+		defer client.mu.Unlock()
+		if client.db == nil {
+			return nil
+		}
 		return client.db.Close()
 	}
 	return nil
@@ -197,7 +512,7 @@ func (client *Client) Iterate(opts storage.IterateOptions, fn func(storage.Itera
 		lastPrefix := []byte{}
 		wasPrefix := false
 
-		return fn(storage.IteratorFunc(func(item *storage.ListItem) bool {
+		return fn(storage.Limited(storage.IteratorFunc(func(item *storage.ListItem) bool {
 			var key, value []byte
 			if start {
 				key, value = cursor.PositionToFirst(opts.Prefix, opts.First)
@@ -238,7 +553,7 @@ func (client *Client) Iterate(opts storage.IterateOptions, fn func(storage.Itera
 			item.IsPrefix = false
 
 			return true
-		}))
+		}), opts.Limit))
 	})
 }
 
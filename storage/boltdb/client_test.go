@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"storj.io/storj/pkg/utils"
 	"storj.io/storj/storage"
@@ -36,6 +37,206 @@ func TestSuite(t *testing.T) {
 	testsuite.RunTests(t, store)
 }
 
+func TestExpiration(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "storj-bolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tempdir) }()
+
+	dbname := filepath.Join(tempdir, "bolt.db")
+	store, err := New(dbname, "bucket")
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+
+	for key, ttl := range map[string]time.Duration{
+		"expiring": time.Second,
+		"later":    time.Hour,
+		"never":    0,
+		"renewed":  time.Second,
+	} {
+		if err := store.PutWithTTL(storage.Key(key), storage.Value(key), ttl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Put(storage.Key("renewed"), storage.Value("renewed")); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := store.sweep(time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected 1 expired value, deleted %d", deleted)
+	}
+	if _, err := store.Get(storage.Key("expiring")); !storage.ErrKeyNotFound.Has(err) {
+		t.Fatalf("expected expired value to be deleted, got %v", err)
+	}
+	for _, key := range []string{"later", "never", "renewed"} {
+		if _, err := store.Get(storage.Key(key)); err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// expirations survive reopening the store
+	store, err = New(dbname, "bucket")
+	if err != nil {
+		t.Fatalf("failed to reopen db: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	deleted, err = store.sweep(time.Now().Add(2 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected 1 expired value, deleted %d", deleted)
+	}
+	if _, err := store.Get(storage.Key("never")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompactAndBackup(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "storj-bolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tempdir) }()
+
+	dbname := filepath.Join(tempdir, "bolt.db")
+	store, err := New(dbname, "bucket")
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	const N = 1000
+	value := storage.Value(make([]byte, 1024))
+	for i := 0; i < N; i++ {
+		if err := store.Put(storage.Key(fmt.Sprintf("key/%04d", i)), value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i < N; i++ {
+		if err := store.Delete(storage.Key(fmt.Sprintf("key/%04d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.PutWithTTL(storage.Key("expiring"), storage.Value("expiring"), time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := os.Stat(dbname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Compact(); err != nil {
+		t.Fatalf("failed to compact: %v", err)
+	}
+	after, err := os.Stat(dbname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Size() >= before.Size() {
+		t.Fatalf("expected compaction to shrink the db, got %d bytes from %d", after.Size(), before.Size())
+	}
+
+	if _, err := store.Get(storage.Key("key/0000")); err != nil {
+		t.Fatalf("value lost by compaction: %v", err)
+	}
+	deleted, err := store.sweep(time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected expiration to survive compaction, deleted %d", deleted)
+	}
+
+	backupname := filepath.Join(tempdir, "backup.db")
+	backup, err := os.Create(backupname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Backup(backup); err != nil {
+		t.Fatalf("failed to back up: %v", err)
+	}
+	if err := backup.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := New(backupname, "bucket")
+	if err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	defer func() { _ = restored.Close() }()
+	got, err := restored.Get(storage.Key("key/0000"))
+	if err != nil {
+		t.Fatalf("value missing from backup: %v", err)
+	}
+	if len(got) != len(value) {
+		t.Fatalf("expected %d bytes in backup, got %d", len(value), len(got))
+	}
+}
+
+func TestCompactShared(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "storj-bolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tempdir) }()
+
+	stores, err := NewShared(filepath.Join(tempdir, "bolt.db"), "alpha", "beta")
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer func() {
+		for _, store := range stores {
+			_ = store.Close()
+		}
+	}()
+
+	if err := stores[0].Compact(); err == nil {
+		t.Fatal("expected compacting a shared db to fail")
+	}
+}
+
+func TestNotReopened(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "storj-bolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tempdir) }()
+
+	store, err := New(filepath.Join(tempdir, "bolt.db"), "bucket")
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+
+	// like after the database failed to be reopened by Compact
+	if err := store.db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store.db = nil
+
+	if _, err := store.Get(storage.Key("key")); !errNotReopened.Has(err) {
+		t.Fatalf("expected the get to fail, got %v", err)
+	}
+	if err := store.Put(storage.Key("key"), storage.Value("value")); !errNotReopened.Has(err) {
+		t.Fatalf("expected the put to fail, got %v", err)
+	}
+	if err := store.Compact(); !errNotReopened.Has(err) {
+		t.Fatalf("expected the compaction to fail, got %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("failed to close db: %v", err)
+	}
+}
+
 func BenchmarkSuite(b *testing.B) {
 	tempdir, err := ioutil.TempDir("", "storj-bolt")
 	if err != nil {
@@ -139,3 +340,74 @@ func BenchmarkSuiteLong(b *testing.B) {
 	}
 	testsuite.BenchmarkPathOperationsInLargeDb(b, longStore)
 }
+
+func TestQueue(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "storj-bolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tempdir) }()
+
+	dbname := filepath.Join(tempdir, "bolt.db")
+	queue, err := NewQueue(dbname, "queue")
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer func() {
+		if err := queue.Close(); err != nil {
+			t.Fatalf("failed to close db: %v", err)
+		}
+	}()
+
+	testsuite.RunQueueTests(t, queue)
+}
+
+func TestQueueReopen(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "storj-bolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tempdir) }()
+
+	dbname := filepath.Join(tempdir, "bolt.db")
+	queue, err := NewQueue(dbname, "queue")
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	for _, value := range []string{"claimed", "waiting"} {
+		if err := queue.EnqueueWithPriority(storage.Value(value), -1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	claim, err := queue.Claim(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// values and claims survive reopening the queue
+	queue, err = NewQueue(dbname, "queue")
+	if err != nil {
+		t.Fatalf("failed to reopen db: %v", err)
+	}
+	defer func() { _ = queue.Close() }()
+
+	value, err := queue.Dequeue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "waiting" {
+		t.Fatalf("expected the claimed value to be skipped, got %q", value)
+	}
+	if claim.Priority != -1 {
+		t.Fatalf("expected priority -1 to be claimed, got %d", claim.Priority)
+	}
+	if err := queue.Ack(claim); err != nil {
+		t.Fatalf("failed to ack after reopening: %v", err)
+	}
+	if _, err := queue.Dequeue(); !storage.ErrEmptyQueue.Has(err) {
+		t.Fatalf("expected the queue to be empty, got %v", err)
+	}
+}
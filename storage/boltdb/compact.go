@@ -0,0 +1,169 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package boltdb
+
+import (
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/boltdb/bolt"
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/utils"
+)
+
+// errNotReopened is returned by the calls to a client whose database
+// couldn't be reopened after it was compacted
+var errNotReopened = errs.Class("database not reopened after compaction")
+
+// compactBatchSize is how many values are copied per transaction when
+// compacting, so large databases aren't copied in a single transaction
+const compactBatchSize = 10000
+
+// Backup writes a consistent copy of the whole database to w. The copy is
+// made in a read transaction, so the client can be used while it's written.
+func (client *Client) Backup(w io.Writer) error {
+	return client.viewTx(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// Compact rewrites the database file without the free pages bolt keeps once
+// values are deleted or changed, so the file shrinks again. The database is
+// copied to a new file, which then replaces it, so other calls to the client
+// wait until it's done. Databases shared by multiple clients can't be
+// compacted, since they'd keep using the replaced file.
+func (client *Client) Compact() (err error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if client.db == nil {
+		return errNotReopened.New("%s", client.Path)
+	}
+	if atomic.LoadInt32(client.referenceCount) != 1 {
+		return Error.New("can't compact %s while it's shared by other clients", client.Path)
+	}
+
+	// a file left over by a failed compaction would be merged into the copy
+	compactPath := client.Path + ".compact"
+	if err := os.Remove(compactPath); err != nil && !os.IsNotExist(err) {
+		return Error.Wrap(err)
+	}
+	compacted, err := bolt.Open(compactPath, fileMode, &bolt.Options{Timeout: defaultTimeout})
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	err = client.db.View(func(tx *bolt.Tx) error {
+		return copyBuckets(compacted, tx)
+	})
+	err = utils.CombineErrors(err, compacted.Close())
+	if err != nil {
+		return Error.Wrap(utils.CombineErrors(err, os.Remove(compactPath)))
+	}
+
+	if err := client.db.Close(); err != nil {
+		return Error.Wrap(utils.CombineErrors(err, os.Remove(compactPath)))
+	}
+	renameErr := os.Rename(compactPath, client.Path)
+	if renameErr != nil {
+		renameErr = utils.CombineErrors(renameErr, os.Remove(compactPath))
+	}
+	// the old file is reopened if it couldn't be replaced. If the file can't
+	// be reopened, the calls to the client fail rather than use the closed
+	// database.
+	db, err := bolt.Open(client.Path, fileMode, &bolt.Options{Timeout: defaultTimeout})
+	if err != nil {
+		client.db = nil
+		return Error.Wrap(utils.CombineErrors(renameErr, err))
+	}
+	client.db = db
+	return Error.Wrap(renameErr)
+}
+
+// copyBuckets copies all buckets of src to dst, in transactions of at most
+// compactBatchSize values
+func copyBuckets(dst *bolt.DB, src *bolt.Tx) (err error) {
+	c := &compactor{dst: dst}
+	defer func() {
+		if c.tx == nil {
+			return
+		}
+		if err != nil {
+			err = utils.CombineErrors(err, c.tx.Rollback())
+			return
+		}
+		err = c.tx.Commit()
+	}()
+
+	return src.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+		return c.copyBucket([][]byte{name}, bucket)
+	})
+}
+
+// compactor copies values into a new database, starting a new transaction
+// every compactBatchSize values
+type compactor struct {
+	dst   *bolt.DB
+	tx    *bolt.Tx
+	count int
+}
+
+// copyBucket copies the values and nested buckets of src to the bucket at
+// path
+func (c *compactor) copyBucket(path [][]byte, src *bolt.Bucket) error {
+	dst, err := c.bucket(path)
+	if err != nil {
+		return err
+	}
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	return src.ForEach(func(key, value []byte) error {
+		if value == nil {
+			nested := append(append([][]byte{}, path...), key)
+			return c.copyBucket(nested, src.Bucket(key))
+		}
+
+		if c.count >= compactBatchSize {
+			if err := c.tx.Commit(); err != nil {
+				c.tx = nil
+				return err
+			}
+			c.tx, c.count = nil, 0
+		}
+		dst, err := c.bucket(path)
+		if err != nil {
+			return err
+		}
+		c.count++
+		return dst.Put(key, value)
+	})
+}
+
+// bucket returns the bucket at path in the current transaction, creating the
+// bucket, and the transaction, if necessary
+func (c *compactor) bucket(path [][]byte) (*bolt.Bucket, error) {
+	if c.tx == nil {
+		tx, err := c.dst.Begin(true)
+		if err != nil {
+			return nil, err
+		}
+		c.tx = tx
+	}
+
+	bucket, err := c.tx.CreateBucketIfNotExists(path[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range path[1:] {
+		bucket, err = bucket.CreateBucketIfNotExists(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bucket, nil
+}
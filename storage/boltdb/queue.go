@@ -0,0 +1,239 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package boltdb
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/boltdb/bolt"
+
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
+)
+
+// the values of a queue are kept in a nested bucket of the bucket named after
+// it, keyed by their priority and id, so they're in the order they're
+// dequeued in. Another nested bucket maps ids to those keys, to find claimed
+// values again.
+var (
+	queueValues = []byte("values")
+	queueIDs    = []byte("ids")
+)
+
+const (
+	queueKeySize = 16
+	// entries start with the number of claims and when the last claim
+	// times out
+	queueEntryHeaderSize = 12
+)
+
+// Queue is a storage.Queue stored in a bolt database. Claimed values are
+// skipped one by one, so it's meant for queues which are worked on by a few
+// workers at a time.
+type Queue struct {
+	db     *bolt.DB
+	Path   string
+	Bucket []byte
+}
+
+// NewQueue instantiates the queue with the given bucket name, stored in the
+// db file at path
+func NewQueue(path, bucket string) (*Queue, error) {
+	db, err := bolt.Open(path, fileMode, &bolt.Options{Timeout: defaultTimeout})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		queue, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		if _, err := queue.CreateBucketIfNotExists(queueValues); err != nil {
+			return err
+		}
+		_, err = queue.CreateBucketIfNotExists(queueIDs)
+		return err
+	})
+	if err != nil {
+		return nil, utils.CombineErrors(err, db.Close())
+	}
+
+	return &Queue{
+		db:     db,
+		Path:   path,
+		Bucket: []byte(bucket),
+	}, nil
+}
+
+// queueKey returns the key of the value with id and priority. Higher
+// priorities are ordered first, by flipping the sign bit and inverting them.
+func queueKey(id uint64, priority int) []byte {
+	key := make([]byte, queueKeySize)
+	binary.BigEndian.PutUint64(key, ^(uint64(int64(priority)) ^ (1 << 63)))
+	binary.BigEndian.PutUint64(key[8:], id)
+	return key
+}
+
+// queueEntry is a value of a queue
+type queueEntry struct {
+	claims       uint32
+	claimedUntil int64
+	value        []byte
+}
+
+func decodeQueueEntry(data []byte) queueEntry {
+	return queueEntry{
+		claims:       binary.BigEndian.Uint32(data),
+		claimedUntil: int64(binary.BigEndian.Uint64(data[4:])),
+		value:        data[queueEntryHeaderSize:],
+	}
+}
+
+func (entry queueEntry) encode() []byte {
+	data := make([]byte, queueEntryHeaderSize+len(entry.value))
+	binary.BigEndian.PutUint32(data, entry.claims)
+	binary.BigEndian.PutUint64(data[4:], uint64(entry.claimedUntil))
+	copy(data[queueEntryHeaderSize:], entry.value)
+	return data
+}
+
+func (queue *Queue) update(fn func(values, ids *bolt.Bucket) error) error {
+	return queue.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(queue.Bucket)
+		return fn(bucket.Bucket(queueValues), bucket.Bucket(queueIDs))
+	})
+}
+
+// Enqueue adds value to the back of the queue, with priority 0
+func (queue *Queue) Enqueue(value storage.Value) error {
+	return queue.EnqueueWithPriority(value, 0)
+}
+
+// EnqueueWithPriority adds value to the queue, behind the values with the
+// same or a higher priority
+func (queue *Queue) EnqueueWithPriority(value storage.Value, priority int) error {
+	return Error.Wrap(queue.update(func(values, ids *bolt.Bucket) error {
+		id, err := values.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := queueKey(id, priority)
+		if err := values.Put(key, queueEntry{value: value}.encode()); err != nil {
+			return err
+		}
+		return ids.Put(key[8:], key)
+	}))
+}
+
+// front returns the key and entry of the first value which isn't claimed
+func front(values *bolt.Bucket) (key []byte, entry queueEntry, ok bool) {
+	now := time.Now().UnixNano()
+	cursor := values.Cursor()
+	for key, data := cursor.First(); key != nil; key, data = cursor.Next() {
+		entry := decodeQueueEntry(data)
+		if entry.claimedUntil <= now {
+			return append([]byte{}, key...), entry, true
+		}
+	}
+	return nil, queueEntry{}, false
+}
+
+// remove deletes the value with key
+func remove(values, ids *bolt.Bucket, key []byte) error {
+	if err := values.Delete(key); err != nil {
+		return err
+	}
+	return ids.Delete(key[8:])
+}
+
+// Dequeue removes the value at the front of the queue and returns it
+func (queue *Queue) Dequeue() (value storage.Value, err error) {
+	err = queue.update(func(values, ids *bolt.Bucket) error {
+		key, entry, ok := front(values)
+		if !ok {
+			return storage.ErrEmptyQueue.New("%s", string(queue.Bucket))
+		}
+		value = storage.CloneValue(storage.Value(entry.value))
+		return remove(values, ids, key)
+	})
+	if storage.ErrEmptyQueue.Has(err) {
+		return nil, err
+	}
+	return value, Error.Wrap(err)
+}
+
+// Claim hides the value at the front of the queue for timeout and returns it
+func (queue *Queue) Claim(timeout time.Duration) (claim storage.Claim, err error) {
+	err = queue.update(func(values, ids *bolt.Bucket) error {
+		key, entry, ok := front(values)
+		if !ok {
+			return storage.ErrEmptyQueue.New("%s", string(queue.Bucket))
+		}
+		entry.claims++
+		entry.claimedUntil = time.Now().Add(timeout).UnixNano()
+		claim = storage.Claim{
+			ID:       int64(binary.BigEndian.Uint64(key[8:])),
+			Claims:   int(entry.claims),
+			Priority: int(int64(^binary.BigEndian.Uint64(key) ^ (1 << 63))),
+			Value:    storage.CloneValue(storage.Value(entry.value)),
+		}
+		return values.Put(key, entry.encode())
+	})
+	if storage.ErrEmptyQueue.Has(err) {
+		return storage.Claim{}, err
+	}
+	return claim, Error.Wrap(err)
+}
+
+// claimed calls fn with the key and entry of the value claimed by claim
+func (queue *Queue) claimed(claim storage.Claim, fn func(values, ids *bolt.Bucket, key []byte, entry queueEntry) error) error {
+	err := queue.update(func(values, ids *bolt.Bucket) error {
+		id := make([]byte, 8)
+		binary.BigEndian.PutUint64(id, uint64(claim.ID))
+		key := append([]byte{}, ids.Get(id)...)
+		if len(key) == 0 {
+			return storage.ErrClaimExpired.New("")
+		}
+		entry := decodeQueueEntry(values.Get(key))
+		if int(entry.claims) != claim.Claims {
+			return storage.ErrClaimExpired.New("")
+		}
+		return fn(values, ids, key, entry)
+	})
+	if storage.ErrClaimExpired.Has(err) {
+		return err
+	}
+	return Error.Wrap(err)
+}
+
+// Ack removes a claimed value from the queue
+func (queue *Queue) Ack(claim storage.Claim) error {
+	return queue.claimed(claim, func(values, ids *bolt.Bucket, key []byte, entry queueEntry) error {
+		return remove(values, ids, key)
+	})
+}
+
+// Nack releases a claimed value, so it can be claimed again right away
+func (queue *Queue) Nack(claim storage.Claim) error {
+	return queue.claimed(claim, func(values, ids *bolt.Bucket, key []byte, entry queueEntry) error {
+		entry.claimedUntil = 0
+		return values.Put(key, entry.encode())
+	})
+}
+
+// Len returns the number of values in the queue, including the claimed ones
+func (queue *Queue) Len() (n int, err error) {
+	err = queue.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(queue.Bucket).Bucket(queueValues).Stats().KeyN
+		return nil
+	})
+	return n, Error.Wrap(err)
+}
+
+// Close closes the queue
+func (queue *Queue) Close() error {
+	return queue.db.Close()
+}
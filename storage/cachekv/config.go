@@ -0,0 +1,26 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cachekv
+
+import (
+	"time"
+
+	"storj.io/storj/storage"
+)
+
+// Config configures the in-memory cache of a storage.KeyValueStore
+type Config struct {
+	Size         int           `help:"number of recently used values cached in memory, values aren't cached if 0" default:"0"`
+	Expiration   time.Duration `help:"how long values are cached at most, 0 for as long as they're used" default:"0s"`
+	WriteThrough bool          `help:"cache written values instead of evicting them from the cache" default:"true"`
+}
+
+// Wrap returns store with its values cached as configured, or store itself if
+// there's no cache
+func (c Config) Wrap(store storage.KeyValueStore) storage.KeyValueStore {
+	if c.Size <= 0 {
+		return store
+	}
+	return New(store, c.Size, c.Expiration, c.WriteThrough)
+}
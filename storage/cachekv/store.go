@@ -0,0 +1,341 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cachekv
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	monkit "gopkg.in/spacemonkeygo/monkit.v2"
+
+	"storj.io/storj/storage"
+)
+
+var mon = monkit.Package()
+
+// Store is a storage.KeyValueStore keeping the values of the most recently
+// used keys of an underlying store in memory. Values written through the
+// Store are either cached as well, or evicted from the cache, so it's only
+// up to date as long as the underlying store isn't changed by others.
+type Store struct {
+	store        storage.KeyValueStore
+	size         int
+	expiration   time.Duration
+	writeThrough bool
+	now          func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	// version changes with every write, so reads and writes racing with
+	// other writes don't cache stale values
+	version uint64
+	stats   Stats
+}
+
+// entry is a cached value
+type entry struct {
+	key     string
+	value   storage.Value
+	expires time.Time
+}
+
+// Stats counts the lookups of a Store
+type Stats struct {
+	Hits   int64
+	Misses int64
+}
+
+// HitRate returns the fraction of lookups answered from the cache
+func (stats Stats) HitRate() float64 {
+	if stats.Hits+stats.Misses == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(stats.Hits+stats.Misses)
+}
+
+// New returns a Store caching up to size values of store. Values are cached
+// for at most expiration, if it's not 0. Written values are cached if
+// writeThrough is true, and evicted otherwise.
+func New(store storage.KeyValueStore, size int, expiration time.Duration, writeThrough bool) *Store {
+	return &Store{
+		store:        store,
+		size:         size,
+		expiration:   expiration,
+		writeThrough: writeThrough,
+		now:          time.Now,
+		lru:          list.New(),
+		entries:      make(map[string]*list.Element),
+	}
+}
+
+// Stats returns how many lookups were answered from the cache and how many
+// weren't
+func (store *Store) Stats() Stats {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.stats
+}
+
+// lookup returns the cached value of key, and the version to pass to add if
+// it isn't cached
+func (store *Store) lookup(key storage.Key) (value storage.Value, ok bool, version uint64) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if elem, found := store.entries[string(key)]; found {
+		e := elem.Value.(*entry)
+		if e.expires.IsZero() || store.now().Before(e.expires) {
+			store.lru.MoveToFront(elem)
+			store.stats.Hits++
+			mon.Event("cachekv_hit")
+			return storage.CloneValue(e.value), true, 0
+		}
+		store.remove(elem)
+	}
+	store.stats.Misses++
+	mon.Event("cachekv_miss")
+	return nil, false, store.version
+}
+
+// add caches value for key, unless the store was written to since version
+func (store *Store) add(key storage.Key, value storage.Value, version uint64) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.version != version {
+		return
+	}
+	store.set(key, value)
+}
+
+// set caches value for key, evicting the least recently used value if the
+// cache is full. store.mu must be held.
+func (store *Store) set(key storage.Key, value storage.Value) {
+	var expires time.Time
+	if store.expiration > 0 {
+		expires = store.now().Add(store.expiration)
+	}
+	e := &entry{key: string(key), value: storage.CloneValue(value), expires: expires}
+
+	if elem, found := store.entries[e.key]; found {
+		elem.Value = e
+		store.lru.MoveToFront(elem)
+		return
+	}
+	store.entries[e.key] = store.lru.PushFront(e)
+	for store.lru.Len() > store.size {
+		store.remove(store.lru.Back())
+	}
+}
+
+// remove evicts the cached value of elem. store.mu must be held.
+func (store *Store) remove(elem *list.Element) {
+	store.lru.Remove(elem)
+	delete(store.entries, elem.Value.(*entry).key)
+}
+
+// invalidate evicts the cached value of key. store.mu must be held.
+func (store *Store) invalidate(key storage.Key) {
+	if elem, found := store.entries[string(key)]; found {
+		store.remove(elem)
+	}
+}
+
+// begin returns the version to pass to written when a write started
+func (store *Store) begin() uint64 {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.version
+}
+
+// written updates the cache after items were written, with err being the
+// result of the write. Written values are only cached if the write succeeded
+// and there was no other write since version, since the underlying store may
+// have applied the writes in another order.
+func (store *Store) written(items storage.Items, version uint64, err error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	cache := store.writeThrough && err == nil && store.version == version
+	store.version++
+	for _, item := range items {
+		if cache && item.Value != nil {
+			store.set(item.Key, item.Value)
+		} else {
+			store.invalidate(item.Key)
+		}
+	}
+}
+
+// Put adds a value to the store
+func (store *Store) Put(key storage.Key, value storage.Value) error {
+	version := store.begin()
+	err := store.store.Put(key, value)
+	store.written(storage.Items{{Key: key, Value: value}}, version, err)
+	return err
+}
+
+// PutWithTTL adds a value to the store, which expires after ttl. The value is
+// evicted from the cache, since it doesn't know when it expires.
+func (store *Store) PutWithTTL(key storage.Key, value storage.Value, ttl time.Duration) error {
+	version := store.begin()
+	err := store.store.PutWithTTL(key, value, ttl)
+	store.written(storage.Items{{Key: key}}, version, err)
+	return err
+}
+
+// Get gets the value of key, from the cache if it's cached
+func (store *Store) Get(key storage.Key) (storage.Value, error) {
+	value, ok, version := store.lookup(key)
+	if ok {
+		return value, nil
+	}
+	value, err := store.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	store.add(key, value, version)
+	return value, nil
+}
+
+// GetAll gets the values of keys, looking up the ones which aren't cached in
+// the underlying store at once
+func (store *Store) GetAll(keys storage.Keys) (storage.Values, error) {
+	if len(keys) > storage.LookupLimit {
+		return nil, storage.ErrLimitExceeded
+	}
+
+	values := make(storage.Values, len(keys))
+	var missing storage.Keys
+	var indexes []int
+	var version uint64
+	for i, key := range keys {
+		value, ok, v := store.lookup(key)
+		if ok {
+			values[i] = value
+			continue
+		}
+		if missing == nil {
+			version = v
+		}
+		missing = append(missing, key)
+		indexes = append(indexes, i)
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	found, err := store.store.GetAll(missing)
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range found {
+		values[indexes[i]] = value
+		if value != nil {
+			store.add(missing[i], value, version)
+		}
+	}
+	return values, nil
+}
+
+// PutAll adds the values of all items to the store
+func (store *Store) PutAll(items storage.Items) error {
+	version := store.begin()
+	err := store.store.PutAll(items)
+	store.written(items, version, err)
+	return err
+}
+
+// Delete deletes key and its value
+func (store *Store) Delete(key storage.Key) error {
+	version := store.begin()
+	err := store.store.Delete(key)
+	store.written(storage.Items{{Key: key}}, version, err)
+	return err
+}
+
+// DeleteAll deletes all keys and their values
+func (store *Store) DeleteAll(keys storage.Keys) error {
+	items := make(storage.Items, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, storage.ListItem{Key: key})
+	}
+	version := store.begin()
+	err := store.store.DeleteAll(keys)
+	store.written(items, version, err)
+	return err
+}
+
+// CompareAndSwap sets the value of key to new if it's old. The cached value
+// is evicted if the swap fails, since it's likely outdated.
+func (store *Store) CompareAndSwap(key storage.Key, old, new storage.Value) error {
+	version := store.begin()
+	err := store.store.CompareAndSwap(key, old, new)
+	store.written(storage.Items{{Key: key, Value: new}}, version, err)
+	return err
+}
+
+// Tx calls fn with the operations of a transaction of the underlying store.
+// Values are read from the transaction rather than the cache, and the
+// written ones are cached or evicted when it ends, like other writes.
+func (store *Store) Tx(fn func(storage.Ops) error) error {
+	ops := &txOps{}
+	version := store.begin()
+	err := storage.Tx(store.store, func(tx storage.Ops) error {
+		ops.tx = tx
+		return fn(ops)
+	})
+	store.written(ops.written, version, err)
+	return err
+}
+
+// txOps are the storage.Ops of a transaction, recording the written values
+type txOps struct {
+	tx      storage.Ops
+	written storage.Items
+}
+
+func (ops *txOps) Get(key storage.Key) (storage.Value, error) {
+	return ops.tx.Get(key)
+}
+
+func (ops *txOps) Put(key storage.Key, value storage.Value) error {
+	ops.written = append(ops.written, storage.ListItem{Key: storage.CloneKey(key), Value: storage.CloneValue(value)})
+	return ops.tx.Put(key, value)
+}
+
+func (ops *txOps) Delete(key storage.Key) error {
+	ops.written = append(ops.written, storage.ListItem{Key: storage.CloneKey(key)})
+	return ops.tx.Delete(key)
+}
+
+// List lists all keys starting from first and upto limit items
+func (store *Store) List(first storage.Key, limit int) (storage.Keys, error) {
+	return store.store.List(first, limit)
+}
+
+// ReverseList lists all keys in reverse order, starting from first
+func (store *Store) ReverseList(first storage.Key, limit int) (storage.Keys, error) {
+	return store.store.ReverseList(first, limit)
+}
+
+// Iterate iterates over the items of the underlying store based on opts
+func (store *Store) Iterate(opts storage.IterateOptions, fn func(storage.Iterator) error) error {
+	return store.store.Iterate(opts, fn)
+}
+
+// Ping checks whether the underlying store can be reached
+func (store *Store) Ping() error {
+	return store.store.Ping()
+}
+
+// Close empties the cache and closes the underlying store
+func (store *Store) Close() error {
+	store.mu.Lock()
+	store.lru.Init()
+	store.entries = make(map[string]*list.Element)
+	store.version++
+	store.mu.Unlock()
+	return store.store.Close()
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cachekv
+
+import (
+	"testing"
+	"time"
+
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+	"storj.io/storj/storage/testsuite"
+)
+
+func TestSuite(t *testing.T) {
+	t.Run("WriteThrough", func(t *testing.T) { testsuite.RunTests(t, New(teststore.New(), 10, 0, true)) })
+	t.Run("Invalidate", func(t *testing.T) { testsuite.RunTests(t, New(teststore.New(), 10, 0, false)) })
+}
+
+func get(t *testing.T, store storage.KeyValueStore, key string, expected string) {
+	t.Helper()
+	value, err := store.Get(storage.Key(key))
+	if err != nil {
+		t.Fatalf("failed to get %q: %v", key, err)
+	}
+	if string(value) != expected {
+		t.Fatalf("invalid value for %q = %q: got %q", key, expected, value)
+	}
+}
+
+func TestLRU(t *testing.T) {
+	db := teststore.New()
+	store := New(db, 2, 0, false)
+	for _, key := range []string{"a", "b", "c"} {
+		if err := store.Put(storage.Key(key), storage.Value(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get(t, store, "a", "a")
+	get(t, store, "b", "b")
+	get(t, store, "a", "a")
+	// c evicts b, which was used less recently than a
+	get(t, store, "c", "c")
+	get(t, store, "a", "a")
+	if db.CallCount.Get != 3 {
+		t.Fatalf("expected a, b and c to be looked up once, got %d lookups", db.CallCount.Get)
+	}
+	get(t, store, "b", "b")
+	if db.CallCount.Get != 4 {
+		t.Fatalf("expected b to be evicted, got %d lookups", db.CallCount.Get)
+	}
+
+	stats := store.Stats()
+	if stats.Hits != 2 || stats.Misses != 4 || stats.HitRate() != 2.0/6.0 {
+		t.Fatalf("invalid stats %+v", stats)
+	}
+
+	values, err := store.GetAll(storage.Keys{storage.Key("a"), storage.Key("b"), storage.Key("missing")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(values[0]) != "a" || string(values[1]) != "b" || values[2] != nil {
+		t.Fatalf("invalid values %q", values)
+	}
+	if db.CallCount.GetAll != 1 || db.CallCount.Get != 4 {
+		t.Fatalf("expected only a and missing to be looked up, got %d / %d lookups", db.CallCount.GetAll, db.CallCount.Get)
+	}
+}
+
+func TestWrites(t *testing.T) {
+	for _, writeThrough := range []bool{true, false} {
+		db := teststore.New()
+		store := New(db, 10, 0, writeThrough)
+		key := storage.Key("a")
+
+		if err := store.Put(key, storage.Value("1")); err != nil {
+			t.Fatal(err)
+		}
+		get(t, store, "a", "1")
+		if err := store.Put(key, storage.Value("2")); err != nil {
+			t.Fatal(err)
+		}
+		get(t, store, "a", "2")
+		if err := store.CompareAndSwap(key, storage.Value("2"), storage.Value("3")); err != nil {
+			t.Fatal(err)
+		}
+		get(t, store, "a", "3")
+
+		lookups := 3
+		if writeThrough {
+			lookups = 0
+		}
+		if db.CallCount.Get != lookups {
+			t.Fatalf("expected %d lookups with write through %v, got %d", lookups, writeThrough, db.CallCount.Get)
+		}
+
+		// failed swaps evict the value, since it's probably outdated
+		if err := db.Put(key, storage.Value("4")); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.CompareAndSwap(key, storage.Value("3"), storage.Value("5")); !storage.ErrValueChanged.Has(err) {
+			t.Fatalf("expected swap to fail, got %v", err)
+		}
+		get(t, store, "a", "4")
+
+		if err := store.Delete(key); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Get(key); !storage.ErrKeyNotFound.Has(err) {
+			t.Fatalf("expected deleted value to be evicted, got %v", err)
+		}
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	db := teststore.New()
+	store := New(db, 10, time.Minute, true)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	if err := store.Put(storage.Key("a"), storage.Value("1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(storage.Key("a"), storage.Value("2")); err != nil {
+		t.Fatal(err)
+	}
+	get(t, store, "a", "1")
+
+	now = now.Add(time.Minute)
+	get(t, store, "a", "2")
+}
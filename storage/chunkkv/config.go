@@ -0,0 +1,23 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package chunkkv
+
+import (
+	"storj.io/storj/storage"
+)
+
+// Config configures how large the values of a storage.KeyValueStore can be
+// before they're split into chunks
+type Config struct {
+	MaxValueSize int `help:"values larger than this many bytes are split into chunks; values aren't split if 0" default:"0"`
+}
+
+// Wrap returns store splitting its values into chunks as configured, or
+// store itself if values aren't split
+func (c Config) Wrap(store storage.KeyValueStore) (storage.KeyValueStore, error) {
+	if c.MaxValueSize <= 0 {
+		return store, nil
+	}
+	return New(store, c.MaxValueSize)
+}
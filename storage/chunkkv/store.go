@@ -0,0 +1,556 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package chunkkv
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
+)
+
+// Error is the default chunkkv errs class
+var Error = errs.Class("chunkkv error")
+
+// ErrCorrupted is the errs class of chunked values which can't be
+// reassembled, because chunks are missing or changed
+var ErrCorrupted = errs.Class("corrupted chunked value")
+
+var (
+	// chunkPrefix is the prefix of the keys chunks are stored with, which
+	// sorts after printable keys
+	chunkPrefix = storage.Key("\xffchunks/")
+	// manifestMagic starts the values referring to chunks. It starts with a
+	// zero byte, which no encoded protobuf message does.
+	manifestMagic = []byte("\x00chunked\x00")
+)
+
+const (
+	idSize = 16
+	// manifestSize is the size of a manifest, which is the magic followed by
+	// the id of the chunks, the number of chunks, the size of the value and
+	// its sha256 hash
+	manifestSize = 9 + idSize + 4 + 8 + sha256.Size
+)
+
+// Store is a storage.KeyValueStore splitting values which are larger than
+// the values the underlying store can keep into chunks, which are stored
+// under keys of their own. The value itself is replaced by a manifest of the
+// chunks, and reassembled from them when it's read, verifying its hash. The
+// chunks are hidden from listing and iteration.
+//
+// Overwriting or deleting a chunked value deletes its chunks afterwards, so
+// the chunks of values overwritten concurrently can be left behind.
+type Store struct {
+	store        storage.KeyValueStore
+	maxValueSize int
+}
+
+// New returns a Store splitting the values of store into chunks of at most
+// maxValueSize bytes
+func New(store storage.KeyValueStore, maxValueSize int) (*Store, error) {
+	if maxValueSize < manifestSize {
+		return nil, Error.New("max value size %d is smaller than the manifest size %d", maxValueSize, manifestSize)
+	}
+	return &Store{store: store, maxValueSize: maxValueSize}, nil
+}
+
+// manifest describes the chunks of a value
+type manifest struct {
+	id     []byte
+	chunks int
+	size   int
+	hash   []byte
+}
+
+func isManifest(value storage.Value) bool {
+	return len(value) == manifestSize && bytes.HasPrefix(value, manifestMagic)
+}
+
+func decodeManifest(value storage.Value) manifest {
+	data := value[len(manifestMagic):]
+	return manifest{
+		id:     data[:idSize],
+		chunks: int(binary.BigEndian.Uint32(data[idSize:])),
+		size:   int(binary.BigEndian.Uint64(data[idSize+4:])),
+		hash:   data[idSize+12:],
+	}
+}
+
+func (m manifest) encode() storage.Value {
+	value := make(storage.Value, manifestSize)
+	data := value[copy(value, manifestMagic):]
+	copy(data, m.id)
+	binary.BigEndian.PutUint32(data[idSize:], uint32(m.chunks))
+	binary.BigEndian.PutUint64(data[idSize+4:], uint64(m.size))
+	copy(data[idSize+12:], m.hash)
+	return value
+}
+
+// chunkKeys returns the keys of the chunks of the value of key
+func (m manifest) chunkKeys(key storage.Key) storage.Keys {
+	keys := make(storage.Keys, 0, m.chunks)
+	for i := 0; i < m.chunks; i++ {
+		keys = append(keys, storage.Key(fmt.Sprintf("%s%s/%x/%08d", chunkPrefix, key, m.id, i)))
+	}
+	return keys
+}
+
+func isChunkKey(key storage.Key) bool {
+	return bytes.HasPrefix(key, chunkPrefix)
+}
+
+func checkKey(key storage.Key) error {
+	if key.IsZero() {
+		return storage.ErrEmptyKey
+	}
+	if isChunkKey(key) {
+		return Error.New("key %q is reserved for chunks", key)
+	}
+	return nil
+}
+
+// split returns what to store as the value of key, and the chunks of value
+// if it's too large to be stored as it is. Values looking like manifests are
+// chunked too, so they aren't mistaken for them.
+func (store *Store) split(key storage.Key, value storage.Value) (storage.Value, storage.Items, error) {
+	if value == nil || (len(value) <= store.maxValueSize && !bytes.HasPrefix(value, manifestMagic)) {
+		return value, nil, nil
+	}
+
+	hash := sha256.Sum256(value)
+	m := manifest{
+		id:     make([]byte, idSize),
+		chunks: (len(value) + store.maxValueSize - 1) / store.maxValueSize,
+		size:   len(value),
+		hash:   hash[:],
+	}
+	if _, err := rand.Read(m.id); err != nil {
+		return nil, nil, Error.Wrap(err)
+	}
+
+	chunks := make(storage.Items, 0, m.chunks)
+	for _, chunkKey := range m.chunkKeys(key) {
+		n := store.maxValueSize
+		if n > len(value) {
+			n = len(value)
+		}
+		chunks = append(chunks, storage.ListItem{Key: chunkKey, Value: value[:n]})
+		value = value[n:]
+	}
+	return m.encode(), chunks, nil
+}
+
+// join returns the value of key, reassembling it from its chunks, which are
+// looked up with getAll, if stored is a manifest
+func (store *Store) join(key storage.Key, stored storage.Value, getAll func(storage.Keys) (storage.Values, error)) (storage.Value, error) {
+	if !isManifest(stored) {
+		return stored, nil
+	}
+	m := decodeManifest(stored)
+
+	value := make(storage.Value, 0, m.size)
+	keys := m.chunkKeys(key)
+	for len(keys) > 0 {
+		batch := keys
+		if len(batch) > storage.LookupLimit {
+			batch = batch[:storage.LookupLimit]
+		}
+		keys = keys[len(batch):]
+
+		chunks, err := getAll(batch)
+		if err != nil {
+			return nil, err
+		}
+		for i, chunk := range chunks {
+			if chunk == nil {
+				return nil, ErrCorrupted.New("chunk %q of %q is missing", batch[i], key)
+			}
+			value = append(value, chunk...)
+		}
+	}
+
+	if hash := sha256.Sum256(value); len(value) != m.size || !bytes.Equal(hash[:], m.hash) {
+		return nil, ErrCorrupted.New("value of %q doesn't match its hash", key)
+	}
+	return value, nil
+}
+
+// putChunks adds chunks to the store, which expire after ttl unless it's
+// negative, in which case they expire after the default TTL of the store
+func (store *Store) putChunks(chunks storage.Items, ttl time.Duration) error {
+	if ttl >= 0 {
+		for _, chunk := range chunks {
+			if err := store.store.PutWithTTL(chunk.Key, chunk.Value, ttl); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for len(chunks) > 0 {
+		batch := chunks
+		if len(batch) > storage.LookupLimit {
+			batch = batch[:storage.LookupLimit]
+		}
+		chunks = chunks[len(batch):]
+		if err := store.store.PutAll(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteChunks deletes the chunks of the stored values of keys, if they're
+// manifests
+func (store *Store) deleteChunks(keys storage.Keys, stored storage.Values) error {
+	var chunkKeys storage.Keys
+	for i, value := range stored {
+		if isManifest(value) {
+			chunkKeys = append(chunkKeys, decodeManifest(value).chunkKeys(keys[i])...)
+		}
+	}
+	for len(chunkKeys) > 0 {
+		batch := chunkKeys
+		if len(batch) > storage.LookupLimit {
+			batch = batch[:storage.LookupLimit]
+		}
+		chunkKeys = chunkKeys[len(batch):]
+		if err := store.store.DeleteAll(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stored returns the value stored for key, which is nil if there's none
+func (store *Store) stored(key storage.Key) (storage.Value, error) {
+	value, err := store.store.Get(key)
+	if storage.ErrKeyNotFound.Has(err) {
+		return nil, nil
+	}
+	return value, err
+}
+
+// put adds value to the store, splitting it into chunks expiring after ttl
+// if necessary, and deletes the chunks of the value it replaces
+func (store *Store) put(key storage.Key, value storage.Value, ttl time.Duration, put func(storage.Value) error) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	old, err := store.stored(key)
+	if err != nil {
+		return err
+	}
+	stored, chunks, err := store.split(key, value)
+	if err != nil {
+		return err
+	}
+	if err := store.putChunks(chunks, ttl); err != nil {
+		return err
+	}
+	if err := put(stored); err != nil {
+		return utils.CombineErrors(err, store.deleteChunks(storage.Keys{key}, storage.Values{stored}))
+	}
+	return store.deleteChunks(storage.Keys{key}, storage.Values{old})
+}
+
+// Put adds a value to the store, splitting it into chunks if it's too large
+func (store *Store) Put(key storage.Key, value storage.Value) error {
+	return store.put(key, value, -1, func(stored storage.Value) error {
+		return store.store.Put(key, stored)
+	})
+}
+
+// PutWithTTL adds a value to the store, which expires after ttl, splitting it
+// into chunks if it's too large
+func (store *Store) PutWithTTL(key storage.Key, value storage.Value, ttl time.Duration) error {
+	return store.put(key, value, ttl, func(stored storage.Value) error {
+		return store.store.PutWithTTL(key, stored, ttl)
+	})
+}
+
+// Get gets the value of key, reassembling it from its chunks if necessary
+func (store *Store) Get(key storage.Key) (storage.Value, error) {
+	if isChunkKey(key) {
+		return nil, storage.ErrKeyNotFound.New("%s", key.String())
+	}
+	stored, err := store.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return store.join(key, stored, store.store.GetAll)
+}
+
+// GetAll gets the values of keys, reassembling them from their chunks if
+// necessary
+func (store *Store) GetAll(keys storage.Keys) (storage.Values, error) {
+	values, err := store.store.GetAll(keys)
+	if err != nil {
+		return nil, err
+	}
+	for i, stored := range values {
+		if isChunkKey(keys[i]) {
+			values[i] = nil
+			continue
+		}
+		values[i], err = store.join(keys[i], stored, store.store.GetAll)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// PutAll adds the values of all items to the store, splitting them into
+// chunks if they're too large
+func (store *Store) PutAll(items storage.Items) error {
+	keys := make(storage.Keys, 0, len(items))
+	for _, item := range items {
+		if err := checkKey(item.Key); err != nil {
+			return err
+		}
+		keys = append(keys, item.Key)
+	}
+	old, err := store.store.GetAll(keys)
+	if err != nil {
+		return err
+	}
+
+	stored := make(storage.Items, 0, len(items))
+	values := make(storage.Values, 0, len(items))
+	var chunks storage.Items
+	for _, item := range items {
+		value, itemChunks, err := store.split(item.Key, item.Value)
+		if err != nil {
+			return err
+		}
+		stored = append(stored, storage.ListItem{Key: item.Key, Value: value})
+		values = append(values, value)
+		chunks = append(chunks, itemChunks...)
+	}
+	if err := store.putChunks(chunks, -1); err != nil {
+		return err
+	}
+	if err := store.store.PutAll(stored); err != nil {
+		return utils.CombineErrors(err, store.deleteChunks(keys, values))
+	}
+	return store.deleteChunks(keys, old)
+}
+
+// Delete deletes key, its value and its chunks
+func (store *Store) Delete(key storage.Key) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	old, err := store.store.Get(key)
+	if err != nil {
+		return err
+	}
+	if err := store.store.Delete(key); err != nil {
+		return err
+	}
+	return store.deleteChunks(storage.Keys{key}, storage.Values{old})
+}
+
+// DeleteAll deletes all keys, their values and their chunks
+func (store *Store) DeleteAll(keys storage.Keys) error {
+	for _, key := range keys {
+		if err := checkKey(key); err != nil {
+			return err
+		}
+	}
+	old, err := store.store.GetAll(keys)
+	if err != nil {
+		return err
+	}
+	if err := store.store.DeleteAll(keys); err != nil {
+		return err
+	}
+	return store.deleteChunks(keys, old)
+}
+
+// CompareAndSwap sets the value of key to new if it's old. The current value
+// is reassembled and compared to old, and swapped only if what's stored
+// didn't change since.
+func (store *Store) CompareAndSwap(key storage.Key, old, new storage.Value) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	current, err := store.stored(key)
+	if err != nil {
+		return err
+	}
+	if (current == nil) != (old == nil) {
+		return storage.ErrValueChanged.New("%s", key.String())
+	}
+	if old != nil {
+		value, err := store.join(key, current, store.store.GetAll)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(value, old) {
+			return storage.ErrValueChanged.New("%s", key.String())
+		}
+	}
+
+	stored, chunks, err := store.split(key, new)
+	if err != nil {
+		return err
+	}
+	if err := store.putChunks(chunks, -1); err != nil {
+		return err
+	}
+	if err := store.store.CompareAndSwap(key, current, stored); err != nil {
+		return utils.CombineErrors(err, store.deleteChunks(storage.Keys{key}, storage.Values{stored}))
+	}
+	return store.deleteChunks(storage.Keys{key}, storage.Values{current})
+}
+
+// Tx calls fn with the operations of a transaction of the underlying store,
+// splitting and reassembling values like the store, so chunks are written
+// and deleted in the same transaction as their manifests
+func (store *Store) Tx(fn func(storage.Ops) error) error {
+	return storage.Tx(store.store, func(tx storage.Ops) error {
+		return fn(&txOps{store: store, tx: tx})
+	})
+}
+
+// txOps are the storage.Ops of a transaction, splitting its values
+type txOps struct {
+	store *Store
+	tx    storage.Ops
+}
+
+// getAll gets the values of keys in the transaction, which are nil for the
+// keys without values
+func (ops *txOps) getAll(keys storage.Keys) (storage.Values, error) {
+	values := make(storage.Values, 0, len(keys))
+	for _, key := range keys {
+		value, err := ops.stored(key)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// stored returns the value stored for key, which is nil if there's none
+func (ops *txOps) stored(key storage.Key) (storage.Value, error) {
+	value, err := ops.tx.Get(key)
+	if storage.ErrKeyNotFound.Has(err) {
+		return nil, nil
+	}
+	return value, err
+}
+
+// deleteChunks deletes the chunks of the value stored for key, if it's a
+// manifest
+func (ops *txOps) deleteChunks(key storage.Key, stored storage.Value) error {
+	if !isManifest(stored) {
+		return nil
+	}
+	for _, chunkKey := range decodeManifest(stored).chunkKeys(key) {
+		if err := ops.tx.Delete(chunkKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ops *txOps) Get(key storage.Key) (storage.Value, error) {
+	if isChunkKey(key) {
+		return nil, storage.ErrKeyNotFound.New("%s", key.String())
+	}
+	stored, err := ops.tx.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return ops.store.join(key, stored, ops.getAll)
+}
+
+func (ops *txOps) Put(key storage.Key, value storage.Value) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	old, err := ops.stored(key)
+	if err != nil {
+		return err
+	}
+	stored, chunks, err := ops.store.split(key, value)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if err := ops.tx.Put(chunk.Key, chunk.Value); err != nil {
+			return err
+		}
+	}
+	if err := ops.tx.Put(key, stored); err != nil {
+		return err
+	}
+	return ops.deleteChunks(key, old)
+}
+
+func (ops *txOps) Delete(key storage.Key) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	old, err := ops.stored(key)
+	if err != nil {
+		return err
+	}
+	if err := ops.tx.Delete(key); err != nil {
+		return err
+	}
+	return ops.deleteChunks(key, old)
+}
+
+// List lists all keys starting from first and upto limit items
+func (store *Store) List(first storage.Key, limit int) (storage.Keys, error) {
+	return storage.ListKeys(store, first, limit)
+}
+
+// ReverseList lists all keys in reverse order, starting from first
+func (store *Store) ReverseList(first storage.Key, limit int) (storage.Keys, error) {
+	return storage.ReverseListKeys(store, first, limit)
+}
+
+// Iterate iterates over items based on opts, skipping chunks and
+// reassembling chunked values. The iteration stops at the first value which
+// can't be reassembled, and Iterate returns its error.
+func (store *Store) Iterate(opts storage.IterateOptions, fn func(storage.Iterator) error) error {
+	var joinErr error
+	err := store.store.Iterate(opts, func(it storage.Iterator) error {
+		return fn(storage.IteratorFunc(func(item *storage.ListItem) bool {
+			for joinErr == nil && it.Next(item) {
+				if isChunkKey(item.Key) {
+					continue
+				}
+				item.Value, joinErr = store.join(item.Key, item.Value, store.store.GetAll)
+				return joinErr == nil
+			}
+			return false
+		}))
+	})
+	return utils.CombineErrors(joinErr, err)
+}
+
+// Ping checks whether the underlying store can be reached
+func (store *Store) Ping() error {
+	return store.store.Ping()
+}
+
+// Close closes the underlying store
+func (store *Store) Close() error {
+	return store.store.Close()
+}
@@ -0,0 +1,184 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package chunkkv
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+	"storj.io/storj/storage/testsuite"
+)
+
+func newTestStore(t *testing.T, db storage.KeyValueStore, maxValueSize int) *Store {
+	store, err := New(db, maxValueSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestSuite(t *testing.T) {
+	testsuite.RunTests(t, newTestStore(t, teststore.New(), manifestSize))
+}
+
+func TestChunking(t *testing.T) {
+	db := teststore.New()
+	store := newTestStore(t, db, 100)
+
+	key := storage.Key("pointer")
+	large := bytes.Repeat([]byte("0123456789"), 25)
+	if err := store.Put(key, storage.Value(large)); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Items) != 4 {
+		t.Fatalf("expected a manifest and 3 chunks, got %d items", len(db.Items))
+	}
+	for _, item := range db.Items {
+		if len(item.Value) > 100 {
+			t.Fatalf("%q has %d bytes, which is more than the max value size", item.Key, len(item.Value))
+		}
+	}
+
+	value, err := store.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(value, large) {
+		t.Fatalf("expected %q, got %q", large, value)
+	}
+
+	keys, err := store.List(nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || !keys[0].Equal(key) {
+		t.Fatalf("expected chunks to be hidden, got %q", keys)
+	}
+
+	// overwriting the value removes its chunks
+	if err := store.Put(key, storage.Value("small")); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Items) != 1 {
+		t.Fatalf("expected the chunks to be deleted, got %d items", len(db.Items))
+	}
+
+	// as does deleting it
+	if err := store.Put(key, storage.Value(large)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Items) != 0 {
+		t.Fatalf("expected the chunks to be deleted, got %d items", len(db.Items))
+	}
+}
+
+func TestCorruption(t *testing.T) {
+	db := teststore.New()
+	store := newTestStore(t, db, 100)
+
+	key := storage.Key("pointer")
+	if err := store.Put(key, storage.Value(bytes.Repeat([]byte("x"), 250))); err != nil {
+		t.Fatal(err)
+	}
+
+	// the chunks are stored after the manifest
+	chunk := db.Items[1]
+	if err := db.Put(chunk.Key, storage.Value(bytes.Repeat([]byte("y"), 100))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(key); !ErrCorrupted.Has(err) {
+		t.Fatalf("expected a changed chunk to be detected, got %v", err)
+	}
+
+	if err := db.Delete(chunk.Key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(key); !ErrCorrupted.Has(err) {
+		t.Fatalf("expected a missing chunk to be detected, got %v", err)
+	}
+}
+
+func TestManifestLikeValues(t *testing.T) {
+	db := teststore.New()
+	store := newTestStore(t, db, 100)
+
+	// a small value which looks like a manifest
+	key := storage.Key("tricky")
+	value := storage.Value(append(append([]byte{}, manifestMagic...), make([]byte, manifestSize-len(manifestMagic))...))
+	if err := store.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, value) {
+		t.Fatalf("expected %q, got %q", value, got)
+	}
+}
+
+func TestTx(t *testing.T) {
+	db := teststore.New()
+	store := newTestStore(t, db, 100)
+
+	key := storage.Key("pointer")
+	large := storage.Value(bytes.Repeat([]byte("x"), 250))
+	failure := errors.New("failure")
+	err := store.Tx(func(ops storage.Ops) error {
+		if err := ops.Put(key, large); err != nil {
+			return err
+		}
+		value, err := ops.Get(key)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(value, large) {
+			t.Fatalf("expected %q, got %q", large, value)
+		}
+		return failure
+	})
+	if err != failure {
+		t.Fatalf("expected the error of the transaction, got %v", err)
+	}
+	if len(db.Items) != 0 {
+		t.Fatalf("expected the chunks to be rolled back, got %d items", len(db.Items))
+	}
+
+	err = store.Tx(func(ops storage.Ops) error {
+		return ops.Put(key, large)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Items) != 4 {
+		t.Fatalf("expected a manifest and 3 chunks, got %d items", len(db.Items))
+	}
+	err = store.Tx(func(ops storage.Ops) error {
+		return ops.Delete(key)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Items) != 0 {
+		t.Fatalf("expected the chunks to be deleted, got %d items", len(db.Items))
+	}
+}
+
+func TestConfig(t *testing.T) {
+	db := teststore.New()
+	store, err := Config{}.Wrap(db)
+	if err != nil || store != db {
+		t.Fatalf("expected store not to be wrapped without max value size, got %v / %v", store, err)
+	}
+
+	if _, err := (Config{MaxValueSize: 10}).Wrap(db); !Error.Has(err) {
+		t.Fatalf("expected a max value size smaller than a manifest to fail, got %v", err)
+	}
+}
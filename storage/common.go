@@ -6,6 +6,7 @@ package storage
 import (
 	"bytes"
 	"errors"
+	"time"
 
 	"github.com/zeebo/errs"
 )
@@ -16,6 +17,16 @@ const Delimiter = '/'
 //ErrKeyNotFound used When something doesn't exist
 var ErrKeyNotFound = errs.Class("key not found")
 
+// ErrValueChanged is returned by CompareAndSwap when the value was changed
+var ErrValueChanged = errs.Class("value changed")
+
+// ErrEmptyQueue is returned by Dequeue when the queue is empty
+var ErrEmptyQueue = errs.Class("empty queue")
+
+// ErrClaimExpired is returned by Ack and Nack when the claimed value was
+// claimed again, or dequeued, after the claim timed out
+var ErrClaimExpired = errs.Class("claim expired")
+
 // ErrEmptyKey is returned when an empty key is used in Put
 var ErrEmptyKey = errors.New("empty key")
 
@@ -49,24 +60,80 @@ type ListItem struct {
 
 // KeyValueStore is an interface describing key/value stores like redis and boltdb
 type KeyValueStore interface {
-	// Put adds a value to store
+	// Put adds a value to store, which expires after the default TTL of the
+	// store, if it has one
 	Put(Key, Value) error
+	// PutWithTTL adds a value to store, which expires after the given TTL,
+	// or never if it's 0
+	PutWithTTL(Key, Value, time.Duration) error
 	// Get gets a value to store
 	Get(Key) (Value, error)
 	// GetAll gets all values from the store
 	GetAll(Keys) (Values, error)
+	// PutAll adds the values of all items to the store at once, which expire
+	// after the default TTL of the store, if it has one
+	PutAll(Items) error
 	// Delete deletes key and the value
 	Delete(Key) error
+	// DeleteAll deletes all keys and their values at once, skipping the
+	// keys which have none
+	DeleteAll(Keys) error
+	// CompareAndSwap sets the value of key to new if it's old, where a nil
+	// old means key has no value and a nil new deletes it. It fails with
+	// ErrValueChanged if the value isn't old.
+	CompareAndSwap(key Key, old, new Value) error
 	// List lists all keys starting from start and upto limit items
 	List(start Key, limit int) (Keys, error)
 	// ReverseList lists all keys in revers order
 	ReverseList(Key, int) (Keys, error)
 	// Iterate iterates over items based on opts
 	Iterate(opts IterateOptions, fn func(Iterator) error) error
+	// Ping checks whether the store can be reached, returning the error
+	// its operations would fail with if it can't
+	Ping() error
 	// Close closes the store
 	Close() error
 }
 
+// Claim is a value claimed from a Queue
+type Claim struct {
+	// ID identifies the value in its queue
+	ID int64
+	// Claims is how often the value was claimed, including this claim
+	Claims   int
+	Priority int
+	Value    Value
+}
+
+// Queue is an interface describing first in, first out queues of values,
+// which may be shared by multiple processes. Values with a higher priority
+// are dequeued before the ones with a lower priority.
+type Queue interface {
+	// Enqueue adds value to the back of the queue, with priority 0
+	Enqueue(Value) error
+	// EnqueueWithPriority adds value to the queue, behind the values with the
+	// same or a higher priority
+	EnqueueWithPriority(value Value, priority int) error
+	// Dequeue removes the value at the front of the queue and returns it,
+	// or fails with ErrEmptyQueue if there's none. Claimed values are
+	// skipped until their claim times out.
+	Dequeue() (Value, error)
+	// Claim hides the value at the front of the queue from Dequeue and
+	// Claim for timeout and returns it, or fails with ErrEmptyQueue if
+	// there's none. The value stays in the queue until it's acknowledged
+	// with Ack, so it's claimed again if it isn't acknowledged in time.
+	Claim(timeout time.Duration) (Claim, error)
+	// Ack removes a claimed value from the queue
+	Ack(Claim) error
+	// Nack releases a claimed value, so it can be claimed again right away
+	Nack(Claim) error
+	// Len returns the number of values in the queue, including the claimed
+	// ones
+	Len() (int, error)
+	// Close closes the queue
+	Close() error
+}
+
 // IterateOptions contains options for iterator
 type IterateOptions struct {
 	// Prefix ensure
@@ -77,6 +144,8 @@ type IterateOptions struct {
 	Recurse bool
 	// Reverse iterates in reverse order
 	Reverse bool
+	// Limit is the maximum number of items the iterator returns, 0 for no limit
+	Limit int
 }
 
 // Iterator iterates over a sequence of ListItems
@@ -0,0 +1,226 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package deadletter
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
+)
+
+// Error is the default deadletter errs class
+var Error = errs.Class("deadletter error")
+
+// Letter is a value which was moved to the dead-letter store, because it
+// failed to be processed too often
+type Letter struct {
+	// Key is the key of the letter in the dead-letter store
+	Key      storage.Key
+	Value    storage.Value
+	Priority int
+	// Claims is how often the value was claimed before it was moved
+	Claims int
+	// Reason is why the value failed to be processed the last time
+	Reason string
+	Moved  time.Time
+}
+
+// encodedLetter is how a Letter is stored, without its key
+type encodedLetter struct {
+	Value    []byte
+	Priority int
+	Claims   int
+	Reason   string
+	Moved    time.Time
+}
+
+// Queue is a storage.Queue moving values which were claimed more than
+// maxClaims times, without being acknowledged, out of the queue into a
+// dead-letter store. Values failing every time they're processed are that
+// way set aside rather than claimed forever.
+type Queue struct {
+	queue     storage.Queue
+	dead      storage.KeyValueStore
+	maxClaims int
+}
+
+// New returns a Queue moving the values of queue which were claimed more than
+// maxClaims times to dead
+func New(queue storage.Queue, dead storage.KeyValueStore, maxClaims int) *Queue {
+	return &Queue{queue: queue, dead: dead, maxClaims: maxClaims}
+}
+
+// Enqueue adds value to the back of the queue, with priority 0
+func (queue *Queue) Enqueue(value storage.Value) error {
+	return queue.queue.Enqueue(value)
+}
+
+// EnqueueWithPriority adds value to the queue, behind the values with the
+// same or a higher priority
+func (queue *Queue) EnqueueWithPriority(value storage.Value, priority int) error {
+	return queue.queue.EnqueueWithPriority(value, priority)
+}
+
+// Dequeue removes the value at the front of the queue and returns it
+func (queue *Queue) Dequeue() (storage.Value, error) {
+	return queue.queue.Dequeue()
+}
+
+// Claim hides the value at the front of the queue for timeout and returns it.
+// Values which were claimed maxClaims times already are moved to the
+// dead-letter store instead.
+func (queue *Queue) Claim(timeout time.Duration) (storage.Claim, error) {
+	for {
+		claim, err := queue.queue.Claim(timeout)
+		if err != nil {
+			return storage.Claim{}, err
+		}
+		if claim.Claims <= queue.maxClaims {
+			return claim, nil
+		}
+
+		reason := fmt.Sprintf("not acknowledged after %d claims", claim.Claims-1)
+		if err := queue.move(claim, reason); err != nil {
+			return storage.Claim{}, err
+		}
+	}
+}
+
+// Ack removes a claimed value from the queue
+func (queue *Queue) Ack(claim storage.Claim) error {
+	return queue.queue.Ack(claim)
+}
+
+// Nack releases a claimed value, so it can be claimed again right away
+func (queue *Queue) Nack(claim storage.Claim) error {
+	return queue.queue.Nack(claim)
+}
+
+// Len returns the number of values in the queue, including the claimed ones,
+// but not the ones in the dead-letter store
+func (queue *Queue) Len() (int, error) {
+	return queue.queue.Len()
+}
+
+// Fail releases a claimed value which failed to be processed because of
+// reason, like Nack. If it was claimed maxClaims times, it's moved to the
+// dead-letter store right away.
+func (queue *Queue) Fail(claim storage.Claim, reason error) error {
+	if claim.Claims < queue.maxClaims {
+		return queue.queue.Nack(claim)
+	}
+	return queue.move(claim, reason.Error())
+}
+
+// move moves a claimed value to the dead-letter store
+func (queue *Queue) move(claim storage.Claim, reason string) error {
+	key := letterKey(claim)
+	letter := encodedLetter{
+		Value:    claim.Value,
+		Priority: claim.Priority,
+		Claims:   claim.Claims,
+		Reason:   reason,
+		Moved:    time.Now().UTC(),
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(letter); err != nil {
+		return Error.Wrap(err)
+	}
+	if err := queue.dead.Put(key, storage.Value(buf.Bytes())); err != nil {
+		return Error.Wrap(err)
+	}
+
+	// the value is removed from the dead-letter store again, if someone
+	// else claimed it in the meantime
+	if err := queue.queue.Ack(claim); err != nil {
+		return utils.CombineErrors(err, queue.dead.Delete(key))
+	}
+	return nil
+}
+
+// letterKey returns the key of the letter of claim, ordered by when it's
+// moved
+func letterKey(claim storage.Claim) storage.Key {
+	return storage.Key(fmt.Sprintf("%016x%016x", time.Now().UnixNano(), claim.ID))
+}
+
+// Letters lists up to limit letters of the dead-letter store, starting from
+// the one with key first, in the order they were moved
+func (queue *Queue) Letters(first storage.Key, limit int) (letters []Letter, err error) {
+	if limit <= 0 || limit > storage.LookupLimit {
+		limit = storage.LookupLimit
+	}
+
+	err = queue.dead.Iterate(storage.IterateOptions{First: first, Recurse: true, Limit: limit}, func(it storage.Iterator) error {
+		var item storage.ListItem
+		for it.Next(&item) {
+			// letters requeued while they're iterated over
+			if len(item.Value) == 0 {
+				continue
+			}
+			letter, err := decodeLetter(storage.CloneKey(item.Key), item.Value)
+			if err != nil {
+				return err
+			}
+			letters = append(letters, letter)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return letters, nil
+}
+
+// Requeue moves the letter with key from the dead-letter store back to the
+// queue, with its original priority
+func (queue *Queue) Requeue(key storage.Key) error {
+	value, err := queue.dead.Get(key)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	letter, err := decodeLetter(key, value)
+	if err != nil {
+		return err
+	}
+	if err := queue.queue.EnqueueWithPriority(letter.Value, letter.Priority); err != nil {
+		return Error.Wrap(err)
+	}
+	return Error.Wrap(queue.dead.Delete(key))
+}
+
+func decodeLetter(key storage.Key, value storage.Value) (Letter, error) {
+	var letter encodedLetter
+	if err := gob.NewDecoder(bytes.NewReader(value)).Decode(&letter); err != nil {
+		return Letter{}, Error.New("invalid letter %q: %v", key, err)
+	}
+	return Letter{
+		Key:      key,
+		Value:    storage.Value(letter.Value),
+		Priority: letter.Priority,
+		Claims:   letter.Claims,
+		Reason:   letter.Reason,
+		Moved:    letter.Moved,
+	}, nil
+}
+
+// Ping checks whether the storage of the queue can be reached, if it can be
+// checked
+func (queue *Queue) Ping() error {
+	if pinger, ok := queue.queue.(interface{ Ping() error }); ok {
+		return pinger.Ping()
+	}
+	return nil
+}
+
+// Close closes the queue and the dead-letter store
+func (queue *Queue) Close() error {
+	return utils.CombineErrors(queue.queue.Close(), queue.dead.Close())
+}
@@ -0,0 +1,103 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package deadletter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+	"storj.io/storj/storage/testsuite"
+)
+
+func TestSuite(t *testing.T) {
+	queue := New(teststore.NewQueue(), teststore.New(), 3)
+	testsuite.RunQueueTests(t, queue)
+}
+
+func TestFail(t *testing.T) {
+	queue := New(teststore.NewQueue(), teststore.New(), 2)
+	if err := queue.EnqueueWithPriority(storage.Value("poison"), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		claim, err := queue.Claim(time.Hour)
+		if err != nil {
+			t.Fatalf("failed to claim %d: %v", i, err)
+		}
+		if err := queue.Fail(claim, errors.New("invalid segment")); err != nil {
+			t.Fatalf("failed to fail %d: %v", i, err)
+		}
+	}
+	if _, err := queue.Claim(time.Hour); !storage.ErrEmptyQueue.Has(err) {
+		t.Fatalf("expected the value to be moved out of the queue, got %v", err)
+	}
+
+	letters, err := queue.Letters(nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 1 {
+		t.Fatalf("expected 1 letter, got %d", len(letters))
+	}
+	letter := letters[0]
+	if string(letter.Value) != "poison" || letter.Priority != 1 || letter.Claims != 2 || letter.Reason != "invalid segment" {
+		t.Fatalf("unexpected letter %+v", letter)
+	}
+
+	if err := queue.Requeue(letter.Key); err != nil {
+		t.Fatalf("failed to requeue: %v", err)
+	}
+	if letters, err := queue.Letters(nil, 10); err != nil || len(letters) != 0 {
+		t.Fatalf("expected the letter to be removed, got %v / %v", letters, err)
+	}
+	claim, err := queue.Claim(time.Hour)
+	if err != nil {
+		t.Fatalf("failed to claim the requeued value: %v", err)
+	}
+	if string(claim.Value) != "poison" || claim.Priority != 1 || claim.Claims != 1 {
+		t.Fatalf("expected the value to be requeued, got %+v", claim)
+	}
+}
+
+func TestClaimTimeouts(t *testing.T) {
+	queue := New(teststore.NewQueue(), teststore.New(), 2)
+	if err := queue.Enqueue(storage.Value("crashing")); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Enqueue(storage.Value("next")); err != nil {
+		t.Fatal(err)
+	}
+
+	// claims which are never acknowledged, like those of crashing workers
+	for i := 1; i <= 2; i++ {
+		claim, err := queue.Claim(time.Millisecond)
+		if err != nil {
+			t.Fatalf("failed to claim %d: %v", i, err)
+		}
+		if string(claim.Value) != "crashing" {
+			t.Fatalf("expected the timed out value to be claimed again, got %q", claim.Value)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	claim, err := queue.Claim(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(claim.Value) != "next" {
+		t.Fatalf("expected the timed out value to be moved, got %q", claim.Value)
+	}
+
+	letters, err := queue.Letters(nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 1 || string(letters[0].Value) != "crashing" || letters[0].Claims != 3 {
+		t.Fatalf("expected the timed out value to be moved, got %+v", letters)
+	}
+}
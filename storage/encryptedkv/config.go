@@ -0,0 +1,34 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package encryptedkv
+
+import (
+	"github.com/mr-tron/base58/base58"
+
+	"storj.io/storj/pkg/storj"
+	"storj.io/storj/storage"
+)
+
+// Config configures the key values are encrypted with at rest
+type Config struct {
+	Key string `help:"base58 encoded 32 byte key values are encrypted with at rest; values aren't encrypted if empty" default:""`
+}
+
+// Wrap returns store encrypting its values with the configured key, or store
+// itself if there's no key
+func (c Config) Wrap(store storage.KeyValueStore) (storage.KeyValueStore, error) {
+	if c.Key == "" {
+		return store, nil
+	}
+	decoded, err := base58.Decode(c.Key)
+	if err != nil {
+		return nil, Error.New("invalid key: %v", err)
+	}
+	if len(decoded) != storj.KeySize {
+		return nil, Error.New("invalid key: %d bytes instead of %d", len(decoded), storj.KeySize)
+	}
+	var key storj.Key
+	copy(key[:], decoded)
+	return New(store, &key)
+}
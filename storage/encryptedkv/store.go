@@ -0,0 +1,263 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package encryptedkv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/storj/pkg/storj"
+	"storj.io/storj/pkg/utils"
+	"storj.io/storj/storage"
+)
+
+// Error is the default encryptedkv errs class
+var Error = errs.Class("encryptedkv error")
+
+// ErrDecrypt is the errs class of values which can't be decrypted, because
+// they were encrypted with another key or changed
+var ErrDecrypt = errs.Class("decryption failed")
+
+// Store is a storage.KeyValueStore which encrypts values with AES-GCM before
+// writing them to an underlying store, and decrypts them when reading. Keys
+// aren't encrypted, so they're listed and iterated in the same order, but
+// every value is bound to its key, so values can't be moved between keys
+// unnoticed.
+type Store struct {
+	store storage.KeyValueStore
+	aead  cipher.AEAD
+}
+
+// New returns a Store encrypting the values of store with key
+func New(store storage.KeyValueStore, key *storj.Key) (*Store, error) {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return &Store{store: store, aead: aead}, nil
+}
+
+// encrypt returns the random nonce followed by the value encrypted for key
+func (store *Store) encrypt(key storage.Key, value storage.Value) (storage.Value, error) {
+	nonce := make([]byte, store.aead.NonceSize(), store.aead.NonceSize()+len(value)+store.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return store.aead.Seal(nonce, nonce, value, key), nil
+}
+
+// decrypt returns the value encrypted for key. Empty values, like those of
+// prefixes, are returned as they are.
+func (store *Store) decrypt(key storage.Key, encrypted storage.Value) (storage.Value, error) {
+	if len(encrypted) == 0 {
+		return encrypted, nil
+	}
+	if len(encrypted) < store.aead.NonceSize()+store.aead.Overhead() {
+		return nil, ErrDecrypt.New("value of %q is too short", key)
+	}
+	nonce, data := encrypted[:store.aead.NonceSize()], encrypted[store.aead.NonceSize():]
+	value, err := store.aead.Open(nil, nonce, data, key)
+	if err != nil {
+		return nil, ErrDecrypt.New("value of %q: %v", key, err)
+	}
+	return value, nil
+}
+
+// Put encrypts value and adds it to the store
+func (store *Store) Put(key storage.Key, value storage.Value) error {
+	if key.IsZero() {
+		return storage.ErrEmptyKey
+	}
+	encrypted, err := store.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return store.store.Put(key, encrypted)
+}
+
+// PutWithTTL encrypts value and adds it to the store, which expires after ttl
+func (store *Store) PutWithTTL(key storage.Key, value storage.Value, ttl time.Duration) error {
+	if key.IsZero() {
+		return storage.ErrEmptyKey
+	}
+	encrypted, err := store.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return store.store.PutWithTTL(key, encrypted, ttl)
+}
+
+// Get gets and decrypts the value of key
+func (store *Store) Get(key storage.Key) (storage.Value, error) {
+	encrypted, err := store.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return store.decrypt(key, encrypted)
+}
+
+// GetAll gets and decrypts the values of keys
+func (store *Store) GetAll(keys storage.Keys) (storage.Values, error) {
+	values, err := store.store.GetAll(keys)
+	if err != nil {
+		return nil, err
+	}
+	for i, encrypted := range values {
+		if encrypted == nil {
+			continue
+		}
+		values[i], err = store.decrypt(keys[i], encrypted)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// PutAll encrypts the values of all items and adds them to the store
+func (store *Store) PutAll(items storage.Items) error {
+	encrypted := make(storage.Items, 0, len(items))
+	for _, item := range items {
+		if item.Key.IsZero() {
+			return storage.ErrEmptyKey
+		}
+		value, err := store.encrypt(item.Key, item.Value)
+		if err != nil {
+			return err
+		}
+		encrypted = append(encrypted, storage.ListItem{Key: item.Key, Value: value})
+	}
+	return store.store.PutAll(encrypted)
+}
+
+// Delete deletes key and its value
+func (store *Store) Delete(key storage.Key) error {
+	return store.store.Delete(key)
+}
+
+// DeleteAll deletes all keys and their values
+func (store *Store) DeleteAll(keys storage.Keys) error {
+	return store.store.DeleteAll(keys)
+}
+
+// CompareAndSwap sets the value of key to new if it's old. Encrypting the
+// same value twice doesn't give the same result, so the current value is
+// decrypted and compared to old, and swapped only if it didn't change since.
+func (store *Store) CompareAndSwap(key storage.Key, old, new storage.Value) error {
+	if key.IsZero() {
+		return storage.ErrEmptyKey
+	}
+
+	var current storage.Value
+	if old != nil {
+		encrypted, err := store.store.Get(key)
+		if storage.ErrKeyNotFound.Has(err) {
+			return storage.ErrValueChanged.New("%s", key.String())
+		}
+		if err != nil {
+			return err
+		}
+		value, err := store.decrypt(key, encrypted)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(value, old) {
+			return storage.ErrValueChanged.New("%s", key.String())
+		}
+		current = encrypted
+	}
+
+	var encrypted storage.Value
+	if new != nil {
+		var err error
+		encrypted, err = store.encrypt(key, new)
+		if err != nil {
+			return err
+		}
+	}
+	return store.store.CompareAndSwap(key, current, encrypted)
+}
+
+// Tx calls fn with the operations of a transaction of the underlying store,
+// encrypting the values put in it and decrypting the ones read from it
+func (store *Store) Tx(fn func(storage.Ops) error) error {
+	return storage.Tx(store.store, func(tx storage.Ops) error {
+		return fn(&txOps{store: store, tx: tx})
+	})
+}
+
+// txOps are the storage.Ops of a transaction, encrypting its values
+type txOps struct {
+	store *Store
+	tx    storage.Ops
+}
+
+func (ops *txOps) Get(key storage.Key) (storage.Value, error) {
+	encrypted, err := ops.tx.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return ops.store.decrypt(key, encrypted)
+}
+
+func (ops *txOps) Put(key storage.Key, value storage.Value) error {
+	if key.IsZero() {
+		return storage.ErrEmptyKey
+	}
+	encrypted, err := ops.store.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return ops.tx.Put(key, encrypted)
+}
+
+func (ops *txOps) Delete(key storage.Key) error {
+	return ops.tx.Delete(key)
+}
+
+// List lists all keys starting from first and upto limit items
+func (store *Store) List(first storage.Key, limit int) (storage.Keys, error) {
+	return store.store.List(first, limit)
+}
+
+// ReverseList lists all keys in reverse order, starting from first
+func (store *Store) ReverseList(first storage.Key, limit int) (storage.Keys, error) {
+	return store.store.ReverseList(first, limit)
+}
+
+// Iterate iterates over items based on opts, decrypting their values. The
+// iteration stops at the first value which can't be decrypted, and Iterate
+// returns its error.
+func (store *Store) Iterate(opts storage.IterateOptions, fn func(storage.Iterator) error) error {
+	var decryptErr error
+	err := store.store.Iterate(opts, func(it storage.Iterator) error {
+		return fn(storage.IteratorFunc(func(item *storage.ListItem) bool {
+			if decryptErr != nil || !it.Next(item) {
+				return false
+			}
+			item.Value, decryptErr = store.decrypt(item.Key, item.Value)
+			return decryptErr == nil
+		}))
+	})
+	return utils.CombineErrors(decryptErr, err)
+}
+
+// Ping checks whether the underlying store can be reached
+func (store *Store) Ping() error {
+	return store.store.Ping()
+}
+
+// Close closes the underlying store
+func (store *Store) Close() error {
+	return store.store.Close()
+}
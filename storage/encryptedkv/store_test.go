@@ -0,0 +1,91 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package encryptedkv
+
+import (
+	"bytes"
+	"testing"
+
+	"storj.io/storj/pkg/storj"
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+	"storj.io/storj/storage/testsuite"
+)
+
+func newTestStore(t *testing.T, db storage.KeyValueStore, secret string) *Store {
+	var key storj.Key
+	copy(key[:], secret)
+	store, err := New(db, &key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestSuite(t *testing.T) {
+	testsuite.RunTests(t, newTestStore(t, teststore.New(), "secret"))
+}
+
+func TestEncryption(t *testing.T) {
+	db := teststore.New()
+	store := newTestStore(t, db, "secret")
+
+	value := storage.Value("plain value")
+	if err := store.Put(storage.Key("a"), value); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := db.Get(storage.Key("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encrypted, value) {
+		t.Fatalf("value stored in plain text: %q", encrypted)
+	}
+
+	// values can't be read with other keys
+	if _, err := newTestStore(t, db, "other").Get(storage.Key("a")); !ErrDecrypt.Has(err) {
+		t.Fatalf("expected decryption to fail with another key, got %v", err)
+	}
+
+	// nor moved to other keys
+	if err := db.Put(storage.Key("b"), encrypted); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(storage.Key("b")); !ErrDecrypt.Has(err) {
+		t.Fatalf("expected decryption to fail for a moved value, got %v", err)
+	}
+	var iterated storage.Keys
+	err = store.Iterate(storage.IterateOptions{Recurse: true}, func(it storage.Iterator) error {
+		var item storage.ListItem
+		for it.Next(&item) {
+			iterated = append(iterated, storage.CloneKey(item.Key))
+		}
+		return nil
+	})
+	if !ErrDecrypt.Has(err) || len(iterated) != 1 {
+		t.Fatalf("expected iterating to stop at a moved value, got %q / %v", iterated, err)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	db := teststore.New()
+	store, err := Config{}.Wrap(db)
+	if err != nil || store != db {
+		t.Fatalf("expected store not to be wrapped without key, got %v / %v", store, err)
+	}
+
+	if _, err := (Config{Key: "abc"}).Wrap(db); !Error.Has(err) {
+		t.Fatalf("expected short key to fail, got %v", err)
+	}
+	if _, err := (Config{Key: "5Hue5QvvvUgMQd4gk9XYMgM6EcZqtdn3JazShJk7TFs0"}).Wrap(db); !Error.Has(err) {
+		t.Fatalf("expected invalid base58 to fail, got %v", err)
+	}
+	store, err = Config{Key: "5Hue5QvvvUgMQd4gk9XYMgM6EcZqtdn3JazShJk7TFs"}.Wrap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.(*Store); !ok {
+		t.Fatalf("expected store to be encrypted, got %T", store)
+	}
+}
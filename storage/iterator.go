@@ -14,6 +14,21 @@ type IteratorFunc func(item *ListItem) bool
 // Next returns the next item
 func (next IteratorFunc) Next(item *ListItem) bool { return next(item) }
 
+// Limited returns an iterator returning at most limit items of it, or all of
+// them if limit is 0
+func Limited(it Iterator, limit int) Iterator {
+	if limit <= 0 {
+		return it
+	}
+	return IteratorFunc(func(item *ListItem) bool {
+		if limit <= 0 {
+			return false
+		}
+		limit--
+		return it.Next(item)
+	})
+}
+
 // SelectPrefixed keeps only items that have prefix
 // items will be reused and modified
 // TODO: remove this
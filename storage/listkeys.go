@@ -14,9 +14,10 @@ func ListKeys(store KeyValueStore, first Key, limit int) (Keys, error) {
 	err := store.Iterate(IterateOptions{
 		First:   first,
 		Recurse: true,
+		Limit:   limit,
 	}, func(it Iterator) error {
 		var item ListItem
-		for ; limit > 0 && it.Next(&item); limit-- {
+		for it.Next(&item) {
 			if item.Key == nil {
 				panic("nil key")
 			}
@@ -40,9 +41,10 @@ func ReverseListKeys(store KeyValueStore, first Key, limit int) (Keys, error) {
 		First:   first,
 		Recurse: true,
 		Reverse: true,
+		Limit:   limit,
 	}, func(it Iterator) error {
 		var item ListItem
-		for ; limit > 0 && it.Next(&item); limit-- {
+		for it.Next(&item) {
 			if item.Key == nil {
 				panic("nil key")
 			}
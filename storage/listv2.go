@@ -95,6 +95,8 @@ func ListV2(store KeyValueStore, opts ListOptions) (result Items, more bool, err
 		First:   firstFull,
 		Reverse: reverse,
 		Recurse: opts.Recursive,
+		// the first item may be skipped and one more is needed for more
+		Limit: limit + 2,
 	}, iterate)
 
 	if reverse {
@@ -0,0 +1,51 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storage
+
+// Migrate copies all values of from to to, in key order, in batches of up to
+// LookupLimit values. If after isn't nil, only the keys after it are copied.
+// progress, if not nil, is called after every batch with the number of values
+// copied so far and the last key copied, which can be passed as after to
+// resume an interrupted migration. Values are written with PutAll, which
+// gives them the default expiration of to, if it has one, instead of the
+// one they had in from: stores like redis clients should be opened without
+// an expiration, or the copied values expire with it.
+func Migrate(from, to KeyValueStore, after Key, progress func(copied int64, last Key)) error {
+	var copied int64
+	first := after
+	if first != nil {
+		first = NextKey(first)
+	}
+
+	for {
+		items := make(Items, 0, LookupLimit)
+		err := from.Iterate(IterateOptions{
+			First:   first,
+			Recurse: true,
+			Limit:   LookupLimit,
+		}, func(it Iterator) error {
+			var item ListItem
+			for it.Next(&item) {
+				items = append(items, CloneItem(item))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		if err := to.PutAll(items); err != nil {
+			return err
+		}
+		copied += int64(len(items))
+		last := items[len(items)-1].Key
+		if progress != nil {
+			progress(copied, last)
+		}
+		first = NextKey(last)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storage_test
+
+import (
+	"fmt"
+	"testing"
+
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+)
+
+func TestMigrate(t *testing.T) {
+	from, to := teststore.New(), teststore.New()
+	const N = storage.LookupLimit*2 + 10
+	for i := 0; i < N; i++ {
+		key := storage.Key(fmt.Sprintf("path/%05d", i))
+		if err := from.Put(key, storage.Value(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// interrupt the migration by failing the second batch
+	var resume storage.Key
+	err := storage.Migrate(from, to, nil, func(copied int64, last storage.Key) {
+		if copied != storage.LookupLimit {
+			t.Fatalf("expected %d values to be copied, got %d", storage.LookupLimit, copied)
+		}
+		resume = storage.CloneKey(last)
+		to.ForceError++
+	})
+	if err == nil || resume == nil {
+		t.Fatalf("expected the migration to be interrupted, got %v", err)
+	}
+
+	var copied int64
+	err = storage.Migrate(from, to, resume, func(n int64, last storage.Key) { copied = n })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copied != N-storage.LookupLimit {
+		t.Fatalf("expected the rest of the values to be copied, got %d", copied)
+	}
+
+	for i := 0; i < N; i++ {
+		key := storage.Key(fmt.Sprintf("path/%05d", i))
+		value, err := to.Get(key)
+		if err != nil {
+			t.Fatalf("%s wasn't copied: %v", key, err)
+		}
+		if string(value) != string(key) {
+			t.Fatalf("expected %s to be copied, got %q", key, value)
+		}
+	}
+}
@@ -153,7 +153,7 @@ func newAlternateOrderedPostgresIterator(altClient *AlternateClient, opts storag
 
 // Iterate iterates over items based on opts
 func (altClient *AlternateClient) Iterate(opts storage.IterateOptions, fn func(storage.Iterator) error) (err error) {
-	opi, err := newAlternateOrderedPostgresIterator(altClient, opts, defaultBatchSize)
+	opi, err := newAlternateOrderedPostgresIterator(altClient, opts, iterateBatchSize(opts))
 	if err != nil {
 		return err
 	}
@@ -161,5 +161,5 @@ func (altClient *AlternateClient) Iterate(opts storage.IterateOptions, fn func(s
 		err = utils.CombineErrors(err, opi.Close())
 	}()
 
-	return fn(opi)
+	return fn(storage.Limited(opi, opts.Limit))
 }
@@ -6,6 +6,7 @@ package postgreskv
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/zeebo/errs"
@@ -47,6 +48,15 @@ func (client *Client) Put(key storage.Key, value storage.Value) error {
 	return client.PutPath(storage.Key(defaultBucket), key, value)
 }
 
+// PutWithTTL sets the value for the provided key. The pathdata table has no
+// expiration times, so values can only be put without a TTL.
+func (client *Client) PutWithTTL(key storage.Key, value storage.Value, ttl time.Duration) error {
+	if ttl > 0 {
+		return Error.New("expiring values are not supported")
+	}
+	return client.Put(key, value)
+}
+
 // PutPath sets the value for the provided key (in the given bucket).
 func (client *Client) PutPath(bucket, key storage.Key, value storage.Value) error {
 	if key.IsZero() {
@@ -61,6 +71,116 @@ func (client *Client) PutPath(bucket, key storage.Key, value storage.Value) erro
 	return err
 }
 
+// PutAll sets the values of all items at once.
+func (client *Client) PutAll(items storage.Items) error {
+	return client.PutAllPath(storage.Key(defaultBucket), items)
+}
+
+// PutAllPath sets the values of all items (in the given bucket) in a single
+// transaction.
+func (client *Client) PutAllPath(bucket storage.Key, items storage.Items) (err error) {
+	for _, item := range items {
+		if item.Key.IsZero() {
+			return Error.New("invalid key")
+		}
+	}
+	q := `
+		INSERT INTO pathdata (bucket, fullpath, metadata)
+			VALUES ($1::BYTEA, $2::BYTEA, $3::BYTEA)
+			ON CONFLICT (bucket, fullpath) DO UPDATE SET metadata = EXCLUDED.metadata
+	`
+	return client.transaction(q, func(stmt *sql.Stmt) error {
+		for _, item := range items {
+			if _, err := stmt.Exec([]byte(bucket), []byte(item.Key), []byte(item.Value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// transaction runs fn with the prepared statement q in a transaction, which
+// is committed if fn succeeds and rolled back otherwise.
+func (client *Client) transaction(q string, fn func(stmt *sql.Stmt) error) error {
+	return client.withTx(func(tx *sql.Tx) (err error) {
+		stmt, err := tx.Prepare(q)
+		if err != nil {
+			return err
+		}
+		defer func() { err = utils.CombineErrors(err, stmt.Close()) }()
+
+		return fn(stmt)
+	})
+}
+
+// withTx runs fn in a transaction, which is committed if fn succeeds and
+// rolled back otherwise.
+func (client *Client) withTx(fn func(tx *sql.Tx) error) (err error) {
+	tx, err := client.pgConn.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = utils.CombineErrors(err, tx.Rollback())
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return fn(tx)
+}
+
+// Tx calls fn with the operations of a transaction, which is committed if fn
+// returns nil and rolled back otherwise. Values read in it are locked until
+// it ends.
+func (client *Client) Tx(fn func(storage.Ops) error) error {
+	return client.withTx(func(tx *sql.Tx) error {
+		return fn(&txOps{tx: tx, bucket: storage.Key(defaultBucket)})
+	})
+}
+
+// txOps are the storage.Ops of a transaction
+type txOps struct {
+	tx     *sql.Tx
+	bucket storage.Key
+}
+
+// Get looks up the provided key in the transaction and locks it
+func (ops *txOps) Get(key storage.Key) (storage.Value, error) {
+	q := "SELECT metadata FROM pathdata WHERE bucket = $1::BYTEA AND fullpath = $2::BYTEA FOR UPDATE"
+	var val []byte
+	err := ops.tx.QueryRow(q, []byte(ops.bucket), []byte(key)).Scan(&val)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrKeyNotFound.New("%s", key.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// Put sets the value for the provided key in the transaction
+func (ops *txOps) Put(key storage.Key, value storage.Value) error {
+	if key.IsZero() {
+		return Error.New("invalid key")
+	}
+	q := `
+		INSERT INTO pathdata (bucket, fullpath, metadata)
+			VALUES ($1::BYTEA, $2::BYTEA, $3::BYTEA)
+			ON CONFLICT (bucket, fullpath) DO UPDATE SET metadata = EXCLUDED.metadata
+	`
+	_, err := ops.tx.Exec(q, []byte(ops.bucket), []byte(key), []byte(value))
+	return err
+}
+
+// Delete deletes the given key in the transaction
+func (ops *txOps) Delete(key storage.Key) error {
+	q := "DELETE FROM pathdata WHERE bucket = $1::BYTEA AND fullpath = $2::BYTEA"
+	_, err := ops.tx.Exec(q, []byte(ops.bucket), []byte(key))
+	return err
+}
+
 // Get looks up the provided key and returns its value (or an error).
 func (client *Client) Get(key storage.Key) (storage.Value, error) {
 	return client.GetPath(storage.Key(defaultBucket), key)
@@ -103,6 +223,75 @@ func (client *Client) DeletePath(bucket, key storage.Key) error {
 	return nil
 }
 
+// DeleteAll deletes all keys and their associated values at once.
+func (client *Client) DeleteAll(keys storage.Keys) error {
+	return client.DeleteAllPath(storage.Key(defaultBucket), keys)
+}
+
+// DeleteAllPath deletes all keys (in the given bucket) and their associated
+// values at once.
+func (client *Client) DeleteAllPath(bucket storage.Key, keys storage.Keys) error {
+	q := "DELETE FROM pathdata WHERE bucket = $1::BYTEA AND fullpath = ANY($2::BYTEA[])"
+	_, err := client.pgConn.Exec(q, []byte(bucket), pq.ByteaArray(keys.ByteSlices()))
+	return err
+}
+
+// CompareAndSwap sets the value of key to new if it's old, or fails with
+// storage.ErrValueChanged otherwise.
+func (client *Client) CompareAndSwap(key storage.Key, old, new storage.Value) error {
+	return client.CompareAndSwapPath(storage.Key(defaultBucket), key, old, new)
+}
+
+// CompareAndSwapPath sets the value of key (in the given bucket) to new if
+// it's old, or fails with storage.ErrValueChanged otherwise.
+func (client *Client) CompareAndSwapPath(bucket, key storage.Key, old, new storage.Value) error {
+	if key.IsZero() {
+		return Error.New("invalid key")
+	}
+
+	var q string
+	args := []interface{}{[]byte(bucket), []byte(key)}
+	switch {
+	case old == nil && new == nil:
+		q = "SELECT 1 FROM pathdata WHERE bucket = $1::BYTEA AND fullpath = $2::BYTEA"
+		var exists int
+		err := client.pgConn.QueryRow(q, args...).Scan(&exists)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return storage.ErrValueChanged.New("%s", key.String())
+	case old == nil:
+		q = `
+			INSERT INTO pathdata (bucket, fullpath, metadata)
+				VALUES ($1::BYTEA, $2::BYTEA, $3::BYTEA)
+				ON CONFLICT (bucket, fullpath) DO NOTHING
+		`
+		args = append(args, []byte(new))
+	case new == nil:
+		q = "DELETE FROM pathdata WHERE bucket = $1::BYTEA AND fullpath = $2::BYTEA AND metadata = $3::BYTEA"
+		args = append(args, []byte(old))
+	default:
+		q = "UPDATE pathdata SET metadata = $4::BYTEA WHERE bucket = $1::BYTEA AND fullpath = $2::BYTEA AND metadata = $3::BYTEA"
+		args = append(args, []byte(old), []byte(new))
+	}
+
+	result, err := client.pgConn.Exec(q, args...)
+	if err != nil {
+		return err
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return storage.ErrValueChanged.New("%s", key.String())
+	}
+	return nil
+}
+
 // List returns either a list of known keys, in order, or an error.
 func (client *Client) List(first storage.Key, limit int) (storage.Keys, error) {
 	return storage.ListKeys(client, first, limit)
@@ -114,6 +303,11 @@ func (client *Client) ReverseList(first storage.Key, limit int) (storage.Keys, e
 	return storage.ReverseListKeys(client, first, limit)
 }
 
+// Ping checks whether the database can be reached
+func (client *Client) Ping() error {
+	return Error.Wrap(client.pgConn.Ping())
+}
+
 // Close closes the client
 func (client *Client) Close() error {
 	return client.pgConn.Close()
@@ -283,7 +477,7 @@ func newOrderedPostgresIterator(pgClient *Client, opts storage.IterateOptions, b
 
 // Iterate iterates over items based on opts
 func (client *Client) Iterate(opts storage.IterateOptions, fn func(storage.Iterator) error) (err error) {
-	opi, err := newOrderedPostgresIterator(client, opts, defaultBatchSize)
+	opi, err := newOrderedPostgresIterator(client, opts, iterateBatchSize(opts))
 	if err != nil {
 		return err
 	}
@@ -291,5 +485,14 @@ func (client *Client) Iterate(opts storage.IterateOptions, fn func(storage.Itera
 		err = utils.CombineErrors(err, opi.Close())
 	}()
 
-	return fn(opi)
+	return fn(storage.Limited(opi, opts.Limit))
+}
+
+// iterateBatchSize returns how many rows are queried at once when iterating
+// with opts
+func iterateBatchSize(opts storage.IterateOptions) int {
+	if opts.Limit > 0 && opts.Limit < defaultBatchSize {
+		return opts.Limit
+	}
+	return defaultBatchSize
 }
@@ -53,6 +53,24 @@ func TestSuite(t *testing.T) {
 	testsuite.RunTests(t, storelogger.New(zap, store))
 }
 
+func TestQueue(t *testing.T) {
+	if *testPostgres == "" {
+		t.Skipf("postgres flag missing, example:\n-postgres-test-db=%s", defaultPostgresConn)
+	}
+
+	queue, err := NewQueue(*testPostgres, "test")
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer func() {
+		if err := queue.Close(); err != nil {
+			t.Fatalf("failed to close queue: %v", err)
+		}
+	}()
+
+	testsuite.RunQueueTests(t, queue)
+}
+
 func BenchmarkSuite(b *testing.B) {
 	store, cleanup := newTestPostgres(b)
 	defer cleanup()
@@ -0,0 +1,149 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package postgreskv
+
+import (
+	"database/sql"
+	"time"
+
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/postgreskv/schema"
+)
+
+// Queue is a storage.Queue stored in the queues table. Values are dequeued
+// by a single statement, so the queue can be shared by multiple processes.
+type Queue struct {
+	Name   string
+	pgConn *sql.DB
+}
+
+// NewQueue instantiates the queue with the given name, given db URL
+func NewQueue(dbURL, name string) (*Queue, error) {
+	pgConn, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, err
+	}
+	err = schema.PrepareDB(pgConn)
+	if err != nil {
+		return nil, err
+	}
+	return &Queue{
+		Name:   name,
+		pgConn: pgConn,
+	}, nil
+}
+
+// Enqueue adds value to the back of the queue, with priority 0
+func (queue *Queue) Enqueue(value storage.Value) error {
+	return queue.EnqueueWithPriority(value, 0)
+}
+
+// EnqueueWithPriority adds value to the queue, behind the values with the
+// same or a higher priority
+func (queue *Queue) EnqueueWithPriority(value storage.Value, priority int) error {
+	q := "INSERT INTO queues (queue, data, priority) VALUES ($1::BYTEA, $2::BYTEA, $3)"
+	_, err := queue.pgConn.Exec(q, []byte(queue.Name), []byte(value), priority)
+	return err
+}
+
+// Dequeue removes the value at the front of the queue and returns it. Values
+// locked by concurrent dequeues are skipped rather than waited for.
+func (queue *Queue) Dequeue() (storage.Value, error) {
+	q := `
+		DELETE FROM queues
+		 WHERE queue = $1::BYTEA
+		   AND id = (
+			SELECT id
+			  FROM queues
+			 WHERE queue = $1::BYTEA
+			   AND (claimed_until IS NULL OR claimed_until <= now())
+			 ORDER BY priority DESC, id
+			 LIMIT 1
+			   FOR UPDATE SKIP LOCKED
+		   )
+		RETURNING data
+	`
+	var value []byte
+	err := queue.pgConn.QueryRow(q, []byte(queue.Name)).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrEmptyQueue.New("%s", queue.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return storage.Value(value), nil
+}
+
+// Claim hides the value at the front of the queue for timeout and returns it
+func (queue *Queue) Claim(timeout time.Duration) (storage.Claim, error) {
+	q := `
+		UPDATE queues
+		   SET claimed_until = now() + $2::DOUBLE PRECISION * interval '1 second',
+		       claims = claims + 1
+		 WHERE queue = $1::BYTEA
+		   AND id = (
+			SELECT id
+			  FROM queues
+			 WHERE queue = $1::BYTEA
+			   AND (claimed_until IS NULL OR claimed_until <= now())
+			 ORDER BY priority DESC, id
+			 LIMIT 1
+			   FOR UPDATE SKIP LOCKED
+		   )
+		RETURNING id, claims, priority, data
+	`
+	var claim storage.Claim
+	var value []byte
+	err := queue.pgConn.QueryRow(q, []byte(queue.Name), timeout.Seconds()).Scan(&claim.ID, &claim.Claims, &claim.Priority, &value)
+	if err == sql.ErrNoRows {
+		return storage.Claim{}, storage.ErrEmptyQueue.New("%s", queue.Name)
+	}
+	if err != nil {
+		return storage.Claim{}, err
+	}
+	claim.Value = storage.Value(value)
+	return claim, nil
+}
+
+// Ack removes a claimed value from the queue
+func (queue *Queue) Ack(claim storage.Claim) error {
+	q := "DELETE FROM queues WHERE queue = $1::BYTEA AND id = $2 AND claims = $3"
+	result, err := queue.pgConn.Exec(q, []byte(queue.Name), claim.ID, claim.Claims)
+	return claimed(result, err)
+}
+
+// Nack releases a claimed value, so it can be claimed again right away
+func (queue *Queue) Nack(claim storage.Claim) error {
+	q := "UPDATE queues SET claimed_until = NULL WHERE queue = $1::BYTEA AND id = $2 AND claims = $3"
+	result, err := queue.pgConn.Exec(q, []byte(queue.Name), claim.ID, claim.Claims)
+	return claimed(result, err)
+}
+
+// claimed checks the result of a statement updating a claimed value,
+// returning ErrClaimExpired if the value wasn't found
+func claimed(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return storage.ErrClaimExpired.New("")
+	}
+	return nil
+}
+
+// Len returns the number of values in the queue, including the claimed ones
+func (queue *Queue) Len() (n int, err error) {
+	q := "SELECT count(*) FROM queues WHERE queue = $1::BYTEA"
+	err = queue.pgConn.QueryRow(q, []byte(queue.Name)).Scan(&n)
+	return n, err
+}
+
+// Close closes the queue
+func (queue *Queue) Close() error {
+	return queue.pgConn.Close()
+}
@@ -0,0 +1,12 @@
+-- queues holds the values of all queues, in the order they were enqueued.
+-- the primary key doubles as the index values are dequeued with.
+CREATE TABLE queues (
+    queue BYTEA
+        NOT NULL,
+    id BIGSERIAL
+        NOT NULL,
+    data BYTEA
+        NOT NULL,
+
+    PRIMARY KEY (queue, id)
+);
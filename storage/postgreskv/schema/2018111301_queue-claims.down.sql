@@ -0,0 +1,3 @@
+ALTER TABLE queues
+    DROP COLUMN claimed_until,
+    DROP COLUMN claims;
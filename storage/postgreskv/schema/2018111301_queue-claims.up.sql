@@ -0,0 +1,7 @@
+-- claimed_until is when the claim of a value times out, and claims counts
+-- how often it was claimed, which tells claims of the same value apart.
+ALTER TABLE queues
+    ADD COLUMN claimed_until TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN claims INTEGER
+        NOT NULL
+        DEFAULT 0;
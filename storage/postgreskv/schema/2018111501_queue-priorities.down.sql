@@ -0,0 +1,4 @@
+DROP INDEX queues_priority_index;
+
+ALTER TABLE queues
+    DROP COLUMN priority;
@@ -0,0 +1,8 @@
+-- values are dequeued by priority first, so the index values are dequeued
+-- with includes it.
+ALTER TABLE queues
+    ADD COLUMN priority INTEGER
+        NOT NULL
+        DEFAULT 0;
+
+CREATE INDEX queues_priority_index ON queues (queue, priority DESC, id);
@@ -2,6 +2,12 @@
 // sources:
 // 2018092201_initial-tables.down.sql
 // 2018092201_initial-tables.up.sql
+// 2018110501_queues.down.sql
+// 2018110501_queues.up.sql
+// 2018111301_queue-claims.down.sql
+// 2018111301_queue-claims.up.sql
+// 2018111501_queue-priorities.down.sql
+// 2018111501_queue-priorities.up.sql
 package schema
 
 import (
@@ -107,6 +113,126 @@ func _2018092201_initialTablesUpSql() (*asset, error) {
 	return a, nil
 }
 
+var __2018110501_queuesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\x28\x2c\x4d\x2d\x4d\x2d\xb6\xe6\x02\x00\xe7\x67\x97\xa1\x13\x00\x00\x00")
+
+func _2018110501_queuesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__2018110501_queuesDownSql,
+		"2018110501_queues.down.sql",
+	)
+}
+
+func _2018110501_queuesDownSql() (*asset, error) {
+	bytes, err := _2018110501_queuesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "2018110501_queues.down.sql", size: 19, mode: os.FileMode(420), modTime: time.Unix(1541408400, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __2018110501_queuesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x75\x4f\xbb\x0e\xc2\x30\x0c\xdc\xf3\x15\x37\x82\x44\xf9\x01\xa6\x14\x45\xa8\x22\x3c\x54\xca\xd0\x31\xc8\x46\x8d\x08\x2d\xa4\x2d\x85\xbf\x27\x0d\x30\x72\x93\x7d\x77\x3e\xdb\x49\x82\x7b\xcf\x3d\xb7\xa8\x1a\x47\x2d\xba\x8a\xf1\x30\x6e\x24\x9a\x33\x8c\x73\x5f\x79\x06\x5b\x47\xb1\xf1\xc4\x7e\xac\x5e\x18\xd8\x33\xb8\x8e\x06\x9a\x8b\x24\x89\x86\x9b\xb7\x57\xe3\x5f\xb8\x04\x07\x35\xfd\xc9\x85\x28\xf3\x09\xb6\x35\xf1\xf3\x17\x6f\xc2\x30\xf1\x67\x18\x83\xed\xaa\xb9\x58\xe6\x4a\x16\x0a\x85\x4c\xb5\xfa\x9d\x35\x11\x08\x88\x0d\xd2\xb2\x50\x32\xf6\x23\xb6\xbb\x02\xdb\xa3\xd6\xb3\xc8\x58\x42\x9a\xad\x0e\x2a\xcf\xa4\xfe\x63\x21\xd3\x99\xbf\x19\x91\xda\xe7\xd9\x46\xe6\x25\xd6\xaa\xc4\x24\xee\x0c\x7f\xd3\x54\x4c\x17\xe2\x0d\x21\xd7\x70\x2e\x29\x01\x00\x00")
+
+func _2018110501_queuesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__2018110501_queuesUpSql,
+		"2018110501_queues.up.sql",
+	)
+}
+
+func _2018110501_queuesUpSql() (*asset, error) {
+	bytes, err := _2018110501_queuesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "2018110501_queues.up.sql", size: 297, mode: os.FileMode(420), modTime: time.Unix(1541408400, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __2018111301_queueClaimsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x2c\x4d\x2d\x4d\x2d\xe6\x52\x00\x02\x97\x20\xff\x00\x05\x67\x7f\x9f\x50\x5f\x3f\x85\xe4\x9c\xc4\xcc\xdc\xd4\x94\xf8\xd2\xbc\x92\xcc\x1c\x1d\xec\xd2\xc5\xd6\x5c\x00\x68\x3a\xe5\xc6\x4a\x00\x00\x00")
+
+func _2018111301_queueClaimsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__2018111301_queueClaimsDownSql,
+		"2018111301_queue-claims.down.sql",
+	)
+}
+
+func _2018111301_queueClaimsDownSql() (*asset, error) {
+	bytes, err := _2018111301_queueClaimsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "2018111301_queue-claims.down.sql", size: 74, mode: os.FileMode(420), modTime: time.Unix(1542099600, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __2018111301_queueClaimsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6d\x8e\xcd\x0a\xc2\x30\x10\x84\xef\x7d\x8a\x79\x80\x56\xbc\x7b\x8a\x36\x6a\x21\x4d\x45\x53\x04\x2f\x12\x6a\xa4\x81\xd8\xaa\x9b\xd8\xd7\x37\xfe\x1e\xc4\xb9\xed\xce\xcc\xb7\x9b\x65\x68\x9c\xb6\x27\x73\xd8\x87\xce\x5b\x07\x4b\x18\x5a\xd3\xc1\xb7\xe6\xe5\xa0\x3f\x42\xe3\xa6\x5d\x30\xf0\x31\x48\xe8\x83\x4f\xa1\xbb\xc3\xcb\x27\x34\x7d\xac\x52\x92\x65\x68\xfb\x21\xc6\x7d\xac\x5b\x8f\x41\xd3\x87\x9d\x46\xa6\x6d\x5a\x78\xe3\x1c\x7d\x6a\x91\xfb\x38\x42\xfa\x64\xde\x78\x7d\xd6\x57\x3f\x4a\x98\x50\x7c\x0d\xc5\xa6\x82\xe3\x12\x4c\x30\x94\x20\x8a\xe5\x39\x66\x95\xa8\x4b\xf9\xf3\xb2\x2a\x4a\xbe\x51\xac\x5c\x61\x5b\xa8\xe5\x73\xc4\xae\x92\x3c\xfd\x5b\x23\x14\x52\xf1\x05\x5f\x3f\xdd\x87\x64\xa5\x20\x6b\x21\xbe\x8b\x9c\xcf\x59\x2d\x14\xc6\x93\xe4\x0e\xd0\x73\x37\x4e\x20\x01\x00\x00")
+
+func _2018111301_queueClaimsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__2018111301_queueClaimsUpSql,
+		"2018111301_queue-claims.up.sql",
+	)
+}
+
+func _2018111301_queueClaimsUpSql() (*asset, error) {
+	bytes, err := _2018111301_queueClaimsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "2018111301_queue-claims.up.sql", size: 288, mode: os.FileMode(420), modTime: time.Unix(1542099600, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __2018111501_queuePrioritiesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\x28\x2c\x4d\x2d\x4d\x2d\x8e\x2f\x28\xca\xcc\x2f\xca\x2c\xa9\x8c\xcf\xcc\x4b\x49\xad\xb0\xe6\xe2\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x85\xaa\xe1\x52\x00\x02\x17\x90\x4e\x67\x7f\x9f\x50\x5f\x3f\x05\x98\x1e\x6b\x2e\x00\x7f\xb0\xbe\x28\x50\x00\x00\x00")
+
+func _2018111501_queuePrioritiesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__2018111501_queuePrioritiesDownSql,
+		"2018111501_queue-priorities.down.sql",
+	)
+}
+
+func _2018111501_queuePrioritiesDownSql() (*asset, error) {
+	bytes, err := _2018111501_queuePrioritiesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "2018111501_queue-priorities.down.sql", size: 80, mode: os.FileMode(420), modTime: time.Unix(1542272400, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __2018111501_queuePrioritiesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6d\x4e\xbb\x0e\x82\x30\x14\xdd\xfb\x15\x67\xd4\x04\x8c\x3b\x53\xa5\x57\x63\x52\x4b\x82\x25\x71\x33\x68\x6b\x68\x42\x44\xa1\xa8\xfc\xbd\x88\x0f\x16\xcf\x72\x93\x7b\x9e\x61\x88\x5b\x5e\xb6\xb6\x41\x5e\x5b\x18\x7b\x6d\x6d\x6b\x0d\x0e\x1d\x2e\xb5\xab\x6a\xe7\x3b\x9c\x5c\xdd\xf8\x00\x4d\x05\x5f\x58\xb8\xb3\xb1\x8f\x7f\x1e\x16\x86\xb8\x3b\x5f\xf4\x8a\x63\xd9\x9a\x9e\x75\x7e\xc6\xb8\xd4\x94\x42\xf3\x85\x24\x0c\xba\x86\xa1\x07\x17\x02\x71\x22\xb3\x8d\x1a\x7b\xd6\x4a\xd3\x8a\xd2\x81\x7f\x41\x25\x1a\x2a\x93\xf2\xf7\x10\xb4\xe4\x99\xd4\x98\x47\x8c\xc5\x29\x71\x4d\xbd\x47\xd0\xee\x13\xbc\xff\x26\xed\xdf\x1b\x13\xf5\x21\x30\x19\x6e\x30\x56\x09\xda\xc6\x01\x9c\x99\x46\xec\x09\x12\x6b\xa0\xbc\x01\x01\x00\x00")
+
+func _2018111501_queuePrioritiesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__2018111501_queuePrioritiesUpSql,
+		"2018111501_queue-priorities.up.sql",
+	)
+}
+
+func _2018111501_queuePrioritiesUpSql() (*asset, error) {
+	bytes, err := _2018111501_queuePrioritiesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "2018111501_queue-priorities.up.sql", size: 257, mode: os.FileMode(420), modTime: time.Unix(1542272400, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -161,6 +287,12 @@ func AssetNames() []string {
 var _bindata = map[string]func() (*asset, error){
 	"2018092201_initial-tables.down.sql": _2018092201_initialTablesDownSql,
 	"2018092201_initial-tables.up.sql": _2018092201_initialTablesUpSql,
+	"2018110501_queues.down.sql": _2018110501_queuesDownSql,
+	"2018110501_queues.up.sql": _2018110501_queuesUpSql,
+	"2018111301_queue-claims.down.sql": _2018111301_queueClaimsDownSql,
+	"2018111301_queue-claims.up.sql": _2018111301_queueClaimsUpSql,
+	"2018111501_queue-priorities.down.sql": _2018111501_queuePrioritiesDownSql,
+	"2018111501_queue-priorities.up.sql": _2018111501_queuePrioritiesUpSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -205,6 +337,12 @@ type bintree struct {
 var _bintree = &bintree{nil, map[string]*bintree{
 	"2018092201_initial-tables.down.sql": &bintree{_2018092201_initialTablesDownSql, map[string]*bintree{}},
 	"2018092201_initial-tables.up.sql": &bintree{_2018092201_initialTablesUpSql, map[string]*bintree{}},
+	"2018110501_queues.down.sql": &bintree{_2018110501_queuesDownSql, map[string]*bintree{}},
+	"2018110501_queues.up.sql": &bintree{_2018110501_queuesUpSql, map[string]*bintree{}},
+	"2018111301_queue-claims.down.sql": &bintree{_2018111301_queueClaimsDownSql, map[string]*bintree{}},
+	"2018111301_queue-claims.up.sql": &bintree{_2018111301_queueClaimsUpSql, map[string]*bintree{}},
+	"2018111501_queue-priorities.down.sql": &bintree{_2018111501_queuePrioritiesDownSql, map[string]*bintree{}},
+	"2018111501_queue-priorities.up.sql": &bintree{_2018111501_queuePrioritiesUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory
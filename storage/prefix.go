@@ -0,0 +1,174 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storage
+
+import (
+	"bytes"
+	"time"
+)
+
+// prefixedStore is a KeyValueStore keeping its keys under a prefix of another
+// KeyValueStore
+type prefixedStore struct {
+	store  KeyValueStore
+	prefix Key
+}
+
+// WithPrefix returns a KeyValueStore keeping its keys in store, with prefix
+// prepended. Stores with prefixes which aren't prefixes of each other can
+// share the same store without seeing each other's keys, like when "overlay/"
+// and "statdb/" are used. Closing the returned store doesn't close store,
+// since it's usually shared.
+func WithPrefix(store KeyValueStore, prefix Key) KeyValueStore {
+	return &prefixedStore{store: store, prefix: CloneKey(prefix)}
+}
+
+// key returns the key of the underlying store for key
+func (store *prefixedStore) key(key Key) Key {
+	result := make(Key, 0, len(store.prefix)+len(key))
+	return append(append(result, store.prefix...), key...)
+}
+
+// keys returns the keys of the underlying store for keys
+func (store *prefixedStore) keys(keys Keys) Keys {
+	result := make(Keys, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, store.key(key))
+	}
+	return result
+}
+
+// Put adds a value to the store
+func (store *prefixedStore) Put(key Key, value Value) error {
+	if key.IsZero() {
+		return ErrEmptyKey
+	}
+	return store.store.Put(store.key(key), value)
+}
+
+// PutWithTTL adds a value to the store, which expires after ttl
+func (store *prefixedStore) PutWithTTL(key Key, value Value, ttl time.Duration) error {
+	if key.IsZero() {
+		return ErrEmptyKey
+	}
+	return store.store.PutWithTTL(store.key(key), value, ttl)
+}
+
+// Get gets the value of key
+func (store *prefixedStore) Get(key Key) (Value, error) {
+	if key.IsZero() {
+		return nil, ErrEmptyKey
+	}
+	return store.store.Get(store.key(key))
+}
+
+// GetAll gets the values of keys
+func (store *prefixedStore) GetAll(keys Keys) (Values, error) {
+	return store.store.GetAll(store.keys(keys))
+}
+
+// PutAll adds the values of all items to the store
+func (store *prefixedStore) PutAll(items Items) error {
+	prefixed := make(Items, 0, len(items))
+	for _, item := range items {
+		if item.Key.IsZero() {
+			return ErrEmptyKey
+		}
+		prefixed = append(prefixed, ListItem{Key: store.key(item.Key), Value: item.Value})
+	}
+	return store.store.PutAll(prefixed)
+}
+
+// Delete deletes key and its value
+func (store *prefixedStore) Delete(key Key) error {
+	if key.IsZero() {
+		return ErrEmptyKey
+	}
+	return store.store.Delete(store.key(key))
+}
+
+// DeleteAll deletes all keys and their values
+func (store *prefixedStore) DeleteAll(keys Keys) error {
+	return store.store.DeleteAll(store.keys(keys))
+}
+
+// CompareAndSwap sets the value of key to new if it's old
+func (store *prefixedStore) CompareAndSwap(key Key, old, new Value) error {
+	if key.IsZero() {
+		return ErrEmptyKey
+	}
+	return store.store.CompareAndSwap(store.key(key), old, new)
+}
+
+// List lists all keys starting from first and upto limit items
+func (store *prefixedStore) List(first Key, limit int) (Keys, error) {
+	return ListKeys(store, first, limit)
+}
+
+// ReverseList lists all keys in reverse order, starting from first
+func (store *prefixedStore) ReverseList(first Key, limit int) (Keys, error) {
+	return ReverseListKeys(store, first, limit)
+}
+
+// Iterate iterates over the items with the prefix, based on opts, without
+// the prefix
+func (store *prefixedStore) Iterate(opts IterateOptions, fn func(Iterator) error) error {
+	opts.Prefix = store.key(opts.Prefix)
+	if !opts.First.IsZero() {
+		opts.First = store.key(opts.First)
+	}
+	return store.store.Iterate(opts, func(it Iterator) error {
+		return fn(IteratorFunc(func(item *ListItem) bool {
+			if !it.Next(item) {
+				return false
+			}
+			if !bytes.HasPrefix(item.Key, store.prefix) {
+				return false
+			}
+			item.Key = item.Key[len(store.prefix):]
+			return true
+		}))
+	})
+}
+
+// Tx calls fn with the operations of a transaction of the underlying store,
+// with the keys prefixed
+func (store *prefixedStore) Tx(fn func(Ops) error) error {
+	return Tx(store.store, func(tx Ops) error {
+		return fn(&prefixedOps{store: store, tx: tx})
+	})
+}
+
+// prefixedOps are the Ops of a transaction of a prefixedStore
+type prefixedOps struct {
+	store *prefixedStore
+	tx    Ops
+}
+
+func (ops *prefixedOps) Get(key Key) (Value, error) {
+	if key.IsZero() {
+		return nil, ErrEmptyKey
+	}
+	return ops.tx.Get(ops.store.key(key))
+}
+
+func (ops *prefixedOps) Put(key Key, value Value) error {
+	if key.IsZero() {
+		return ErrEmptyKey
+	}
+	return ops.tx.Put(ops.store.key(key), value)
+}
+
+func (ops *prefixedOps) Delete(key Key) error {
+	if key.IsZero() {
+		return ErrEmptyKey
+	}
+	return ops.tx.Delete(ops.store.key(key))
+}
+
+// Ping checks whether the underlying store can be reached
+func (store *prefixedStore) Ping() error { return store.store.Ping() }
+
+// Close doesn't close the underlying store, which is usually shared
+func (store *prefixedStore) Close() error { return nil }
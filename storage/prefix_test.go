@@ -0,0 +1,72 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storage_test
+
+import (
+	"testing"
+
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+	"storj.io/storj/storage/testsuite"
+)
+
+func TestWithPrefix(t *testing.T) {
+	store := teststore.New()
+	if err := store.Put(storage.Key("other"), storage.Value("other")); err != nil {
+		t.Fatal(err)
+	}
+	testsuite.RunTests(t, storage.WithPrefix(store, storage.Key("prefix/")))
+}
+
+func TestWithPrefixIsolation(t *testing.T) {
+	store := teststore.New()
+	alpha := storage.WithPrefix(store, storage.Key("alpha/"))
+	beta := storage.WithPrefix(store, storage.Key("beta/"))
+
+	if err := alpha.Put(storage.Key("key"), storage.Value("alpha")); err != nil {
+		t.Fatal(err)
+	}
+	if err := beta.Put(storage.Key("key"), storage.Value("beta")); err != nil {
+		t.Fatal(err)
+	}
+	if err := beta.Put(storage.Key("only-beta"), storage.Value("beta")); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := alpha.Get(storage.Key("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "alpha" {
+		t.Fatalf("expected the value of alpha, got %q", value)
+	}
+	if _, err := alpha.Get(storage.Key("only-beta")); !storage.ErrKeyNotFound.Has(err) {
+		t.Fatalf("expected the key of beta to be hidden, got %v", err)
+	}
+
+	keys, err := alpha.List(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].String() != "key" {
+		t.Fatalf("expected to list only the keys of alpha, got %v", keys.Strings())
+	}
+	keys, err = beta.ReverseList(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0].String() != "only-beta" || keys[1].String() != "key" {
+		t.Fatalf("expected to list the keys of beta in reverse, got %v", keys.Strings())
+	}
+
+	if _, err := store.Get(storage.Key("beta/only-beta")); err != nil {
+		t.Fatalf("expected the key to be prefixed in the underlying store: %v", err)
+	}
+	if err := alpha.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := beta.Get(storage.Key("key")); err != nil {
+		t.Fatalf("closing one prefix shouldn't affect others: %v", err)
+	}
+}
@@ -4,8 +4,11 @@
 package redis
 
 import (
+	"bytes"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis"
@@ -22,32 +25,85 @@ var (
 
 const defaultNodeExpiration = 61 * time.Minute
 
-// Client is the entrypoint into Redis
+// commands failing because redis can't be reached, like while it restarts
+// or fails over, are retried with exponential backoff. Broken connections
+// are dropped from the pool, so retries reconnect to it.
+const (
+	maxRetries      = 5
+	minRetryBackoff = 50 * time.Millisecond
+	maxRetryBackoff = 2 * time.Second
+)
+
+// Client is the entrypoint into Redis. It talks to a standalone redis, a
+// redis cluster or the master monitored by redis sentinels. Keys of a
+// cluster are spread over its nodes by their hash slot, so the operations on
+// many keys look them up on all nodes and sort the results, keeping the
+// semantics of a single redis.
 type Client struct {
-	db  *redis.Client
+	db  redis.UniversalClient
 	TTL time.Duration
 }
 
 // NewClient returns a configured Client instance, verifying a successful connection to redis
 func NewClient(address, password string, db int) (*Client, error) {
+	return newClient(redis.NewClient(&redis.Options{
+		Addr:            address,
+		Password:        password,
+		DB:              db,
+		MaxRetries:      maxRetries,
+		MinRetryBackoff: minRetryBackoff,
+		MaxRetryBackoff: maxRetryBackoff,
+	}))
+}
+
+// NewClusterClient returns a Client of the redis cluster the nodes at the
+// given addresses are part of, verifying a successful connection to it
+func NewClusterClient(addresses []string, password string) (*Client, error) {
+	return newClient(redis.NewClusterClient(&redis.ClusterOptions{
+		Addrs:           addresses,
+		Password:        password,
+		MaxRetries:      maxRetries,
+		MinRetryBackoff: minRetryBackoff,
+		MaxRetryBackoff: maxRetryBackoff,
+	}))
+}
+
+// NewSentinelClient returns a Client of the redis master with the given name,
+// which is looked up from the sentinels at the given addresses, so the
+// Client follows it when they fail over to another one
+func NewSentinelClient(master string, sentinels []string, password string, db int) (*Client, error) {
+	return newClient(redis.NewFailoverClient(&redis.FailoverOptions{
+		MasterName:      master,
+		SentinelAddrs:   sentinels,
+		Password:        password,
+		DB:              db,
+		MaxRetries:      maxRetries,
+		MinRetryBackoff: minRetryBackoff,
+		MaxRetryBackoff: maxRetryBackoff,
+	}))
+}
+
+func newClient(db redis.UniversalClient) (*Client, error) {
 	client := &Client{
-		db: redis.NewClient(&redis.Options{
-			Addr:     address,
-			Password: password,
-			DB:       db,
-		}),
+		db:  db,
 		TTL: defaultNodeExpiration,
 	}
 
 	// ping here to verify we are able to connect to redis with the initialized client.
 	if err := client.db.Ping().Err(); err != nil {
+		_ = db.Close()
 		return nil, Error.New("ping failed: %v", err)
 	}
 
 	return client, nil
 }
 
-// NewClientFrom returns a configured Client instance from a redis address, verifying a successful connection to redis
+// NewClientFrom returns a configured Client instance from a redis address, verifying a successful connection to redis.
+// The address is redis://host:port?db=0 for a standalone redis,
+// redis://host:port,host:port?mode=cluster for a cluster and
+// redis://host:port,host:port?mode=sentinel&master=name&db=0 for the master
+// monitored by sentinels. The password is given with the password
+// parameter, or as redis://:password@host:port.
 func NewClientFrom(address string) (*Client, error) {
 	redisurl, err := utils.ParseURL(address)
 	if err != nil {
@@ -60,12 +116,36 @@ func NewClientFrom(address string) (*Client, error) {
 
 	q := redisurl.Query()
 
-	db, err := strconv.Atoi(q.Get("db"))
-	if err != nil {
-		return nil, err
+	password := q.Get("password")
+	if password == "" && redisurl.User != nil {
+		password, _ = redisurl.User.Password()
+	}
+
+	db := 0
+	if q.Get("db") != "" {
+		db, err = strconv.Atoi(q.Get("db"))
+		if err != nil {
+			return nil, Error.New("invalid db: %v", err)
+		}
 	}
 
-	return NewClient(redisurl.Host, q.Get("password"), db)
+	addresses := strings.Split(redisurl.Host, ",")
+	switch q.Get("mode") {
+	case "", "standalone":
+		return NewClient(redisurl.Host, password, db)
+	case "cluster":
+		if db != 0 {
+			return nil, Error.New("redis clusters only have db 0")
+		}
+		return NewClusterClient(addresses, password)
+	case "sentinel":
+		if q.Get("master") == "" {
+			return nil, Error.New("no master name for sentinel mode")
+		}
+		return NewSentinelClient(q.Get("master"), addresses, password, db)
+	default:
+		return nil, Error.New("unsupported mode %q", q.Get("mode"))
+	}
 }
 
 // Get looks up the provided key from redis returning either an error or the result.
@@ -80,27 +160,82 @@ func (client *Client) Get(key storage.Key) (storage.Value, error) {
 	return value, nil
 }
 
-// Put adds a value to the provided key in redis, returning an error on failure.
+// Put adds a value to the provided key in redis, which expires after the TTL
+// of the client, returning an error on failure.
 func (client *Client) Put(key storage.Key, value storage.Value) error {
+	return client.PutWithTTL(key, value, client.TTL)
+}
+
+// PutWithTTL adds a value to the provided key in redis, which expires after
+// ttl, or never if it's 0, returning an error on failure.
+func (client *Client) PutWithTTL(key storage.Key, value storage.Value, ttl time.Duration) error {
 	if len(key) == 0 {
 		return Error.New("invalid key")
 	}
-	err := client.db.Set(key.String(), []byte(value), client.TTL).Err()
+	err := client.db.Set(key.String(), []byte(value), ttl).Err()
+	if err != nil {
+		return Error.New("put error: %v", err)
+	}
+	return nil
+}
+
+// PutAll adds the values of all items to redis at once, which expire after
+// the TTL of the client, returning an error on failure.
+func (client *Client) PutAll(items storage.Items) error {
+	if len(items) == 0 {
+		return nil
+	}
+	for _, item := range items {
+		if len(item.Key) == 0 {
+			return Error.New("invalid key")
+		}
+	}
+	err := client.pipelined(func(pipe redis.Pipeliner) error {
+		for _, item := range items {
+			pipe.Set(item.Key.String(), []byte(item.Value), client.TTL)
+		}
+		return nil
+	})
 	if err != nil {
 		return Error.New("put error: %v", err)
 	}
 	return nil
 }
 
-// List returns either a list of keys for which boltdb has values or an error.
+// List returns either a list of keys for which redis has values or an error.
+// The keys are only scanned, without looking up their values.
 func (client *Client) List(first storage.Key, limit int) (storage.Keys, error) {
-	return storage.ListKeys(client, first, limit)
+	all, err := client.allPrefixedKeys(nil, first, nil)
+	if err != nil {
+		return nil, err
+	}
+	return firstKeys(all, limit), nil
 }
 
 // ReverseList returns either a list of keys for which redis has values or an error.
 // Starts from first and iterates backwards
 func (client *Client) ReverseList(first storage.Key, limit int) (storage.Keys, error) {
-	return storage.ReverseListKeys(client, first, limit)
+	all, err := client.allPrefixedKeys(nil, nil, first)
+	if err != nil {
+		return nil, err
+	}
+	return firstKeys(storage.ReverseItems(all), limit), nil
+}
+
+// firstKeys returns the keys of the first limit items, where limit is capped
+// to storage.LookupLimit like in storage.ListKeys
+func firstKeys(items storage.Items, limit int) storage.Keys {
+	if limit <= 0 || limit > storage.LookupLimit {
+		limit = storage.LookupLimit
+	}
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	keys := make(storage.Keys, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+	return keys
 }
 
 // Delete deletes a key/value pair from redis, for a given the key
@@ -112,6 +247,79 @@ func (client *Client) Delete(key storage.Key) error {
 	return nil
 }
 
+// DeleteAll deletes all keys and their values from redis at once
+func (client *Client) DeleteAll(keys storage.Keys) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	err := client.pipelined(func(pipe redis.Pipeliner) error {
+		// a DEL of many keys can't span the hash slots of a cluster
+		for _, key := range keys {
+			pipe.Del(key.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return Error.New("delete error: %v", err)
+	}
+	return nil
+}
+
+// CompareAndSwap sets the value of key in redis to new if it's old, watching
+// the key so a concurrent change fails it with storage.ErrValueChanged too.
+func (client *Client) CompareAndSwap(key storage.Key, old, new storage.Value) error {
+	if len(key) == 0 {
+		return Error.New("invalid key")
+	}
+	k := key.String()
+	err := client.db.Watch(func(tx *redis.Tx) error {
+		current, err := tx.Get(k).Bytes()
+		if err == redis.Nil {
+			current, err = nil, nil
+		}
+		if err != nil {
+			return err
+		}
+		if (old == nil) != (current == nil) || !bytes.Equal(old, current) {
+			return storage.ErrValueChanged.New("%s", key.String())
+		}
+
+		_, err = tx.Pipelined(func(pipe redis.Pipeliner) error {
+			if new == nil {
+				pipe.Del(k)
+			} else {
+				pipe.Set(k, []byte(new), client.TTL)
+			}
+			return nil
+		})
+		return err
+	}, k)
+	if err == redis.TxFailedErr {
+		return storage.ErrValueChanged.New("%s", key.String())
+	}
+	if err != nil && !storage.ErrValueChanged.Has(err) {
+		return Error.New("compare and swap error: %v", err)
+	}
+	return err
+}
+
+// pipelined sends the commands queued by fn to redis at once, in a MULTI
+// transaction unless the client is of a cluster, whose transactions can't
+// span the hash slots of different nodes
+func (client *Client) pipelined(fn func(pipe redis.Pipeliner) error) (err error) {
+	if _, ok := client.db.(*redis.ClusterClient); ok {
+		_, err = client.db.Pipelined(fn)
+	} else {
+		_, err = client.db.TxPipelined(fn)
+	}
+	return err
+}
+
+// Ping checks whether redis can be reached
+func (client *Client) Ping() error {
+	return Error.Wrap(client.db.Ping().Err())
+}
+
 // Close closes a redis client
 func (client *Client) Close() error {
 	return client.db.Close()
@@ -130,6 +338,10 @@ func (client *Client) GetAll(keys storage.Keys) (storage.Values, error) {
 		keyStrings[i] = v.String()
 	}
 
+	if _, ok := client.db.(*redis.ClusterClient); ok {
+		return client.getAllPipelined(keyStrings)
+	}
+
 	results, err := client.db.MGet(keyStrings...).Result()
 	if err != nil {
 		return nil, err
@@ -150,14 +362,45 @@ func (client *Client) GetAll(keys storage.Keys) (storage.Values, error) {
 	return values, nil
 }
 
-// Iterate iterates over items based on opts
+// getAllPipelined looks up the values of keys with a GET per key, as the keys
+// of a cluster may be in the hash slots of different nodes, which a single
+// MGET can't span
+func (client *Client) getAllPipelined(keys []string) (storage.Values, error) {
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := client.db.Pipelined(func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(key)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, Error.Wrap(err)
+	}
+
+	values := make(storage.Values, len(cmds))
+	for i, cmd := range cmds {
+		value, err := cmd.Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		values[i] = storage.Value(value)
+	}
+	return values, nil
+}
+
+// Iterate iterates over items based on opts. SCAN returns keys in no
+// particular order, so the matching keys are sorted before iterating, but
+// their values are only looked up in batches as the iterator advances.
 func (client *Client) Iterate(opts storage.IterateOptions, fn func(it storage.Iterator) error) error {
 	var all storage.Items
 	var err error
 	if !opts.Reverse {
-		all, err = client.allPrefixedItems(opts.Prefix, opts.First, nil)
+		all, err = client.allPrefixedKeys(opts.Prefix, opts.First, nil)
 	} else {
-		all, err = client.allPrefixedItems(opts.Prefix, nil, opts.First)
+		all, err = client.allPrefixedKeys(opts.Prefix, nil, opts.First)
 	}
 	if err != nil {
 		return err
@@ -168,44 +411,147 @@ func (client *Client) Iterate(opts storage.IterateOptions, fn func(it storage.It
 	if opts.Reverse {
 		all = storage.ReverseItems(all)
 	}
-	return fn(&storage.StaticIterator{
-		Items: all,
-	})
+	if opts.Limit > 0 && len(all) > opts.Limit {
+		all = all[:opts.Limit]
+	}
+
+	it := &valueIterator{client: client, items: all}
+	if err := fn(it); err != nil {
+		return err
+	}
+	return it.err
 }
 
-func (client *Client) allPrefixedItems(prefix, first, last storage.Key) (storage.Items, error) {
+// allPrefixedKeys returns the sorted items of the keys with prefix between
+// first and last, without their values
+func (client *Client) allPrefixedKeys(prefix, first, last storage.Key) (storage.Items, error) {
 	var all storage.Items
 	seen := map[string]struct{}{}
 
 	match := string(escapeMatch([]byte(prefix))) + "*"
-	it := client.db.Scan(0, match, 0).Iterator()
-	for it.Next() {
-		key := it.Val()
+	err := client.scan(match, func(key string) {
 		if !first.IsZero() && storage.Key(key).Less(first) {
-			continue
+			return
 		}
 		if !last.IsZero() && last.Less(storage.Key(key)) {
-			continue
+			return
 		}
 
 		if _, ok := seen[key]; ok {
-			continue
+			return
 		}
 		seen[key] = struct{}{}
 
-		value, err := client.db.Get(key).Bytes()
-		if err != nil {
-			return nil, err
-		}
-
 		all = append(all, storage.ListItem{
 			Key:      storage.Key(key),
-			Value:    storage.Value(value),
 			IsPrefix: false,
 		})
+	})
+	if err != nil {
+		return nil, Error.Wrap(err)
 	}
 
 	sort.Sort(all)
 
 	return all, nil
 }
+
+const (
+	// iterateBatchSize is how many values are looked up at once when
+	// iterating, with a single MGET unless the client is of a cluster
+	iterateBatchSize = storage.LookupLimit
+	// scanCount is how many keys SCAN is asked to return per call, so large
+	// databases are scanned in fewer round trips
+	scanCount = 1000
+)
+
+// valueIterator iterates over items, looking up the values of the ones which
+// aren't prefixes in batches. Items without a value, like the ones whose keys
+// were deleted since they were scanned, are returned with an empty value.
+type valueIterator struct {
+	client  *Client
+	items   storage.Items
+	next    int
+	fetched int
+	err     error
+}
+
+// Next returns the next item
+func (it *valueIterator) Next(item *storage.ListItem) bool {
+	for it.err == nil && it.next < len(it.items) {
+		if it.next == it.fetched {
+			it.err = it.fetch()
+			if it.err != nil {
+				return false
+			}
+		}
+
+		next := it.items[it.next]
+		it.items[it.next] = storage.ListItem{}
+		it.next++
+		if !next.IsPrefix && next.Value == nil {
+			next.Value = storage.Value{}
+		}
+		*item = next
+		return true
+	}
+	return false
+}
+
+// fetch looks up the values of the next batch of items
+func (it *valueIterator) fetch() error {
+	end := it.fetched + iterateBatchSize
+	if end > len(it.items) {
+		end = len(it.items)
+	}
+	batch := it.items[it.fetched:end]
+	it.fetched = end
+
+	var keys storage.Keys
+	for _, item := range batch {
+		if !item.IsPrefix {
+			keys = append(keys, item.Key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	values, err := it.client.GetAll(keys)
+	if err != nil {
+		return err
+	}
+	for i := range batch {
+		if batch[i].IsPrefix {
+			continue
+		}
+		batch[i].Value, values = values[0], values[1:]
+	}
+	return nil
+}
+
+// scan calls fn with the keys matching match. The keys of a cluster are
+// scanned on all of its masters.
+func (client *Client) scan(match string, fn func(key string)) error {
+	scan := func(db redis.Cmdable, fn func(key string)) error {
+		it := db.Scan(0, match, scanCount).Iterator()
+		for it.Next() {
+			fn(it.Val())
+		}
+		return it.Err()
+	}
+
+	cluster, ok := client.db.(*redis.ClusterClient)
+	if !ok {
+		return scan(client.db, fn)
+	}
+
+	// the masters are scanned concurrently
+	var mu sync.Mutex
+	return cluster.ForEachMaster(func(master *redis.Client) error {
+		return scan(master, func(key string) {
+			mu.Lock()
+			defer mu.Unlock()
+			fn(key)
+		})
+	})
+}
@@ -5,8 +5,13 @@ package redis
 
 import (
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis"
+
+	"storj.io/storj/storage"
 	"storj.io/storj/storage/redis/redisserver"
+	"storj.io/storj/storage/teststore"
 	"storj.io/storj/storage/testsuite"
 )
 
@@ -25,6 +30,27 @@ func TestSuite(t *testing.T) {
 	testsuite.RunTests(t, client)
 }
 
+func TestQueue(t *testing.T) {
+	// miniredis doesn't run the scripts of the queue atomically
+	addr, cleanup, err := redisserver.Process()
+	if err != nil {
+		t.Skipf("redis-server needed: %v", err)
+	}
+	defer cleanup()
+
+	queue, err := NewQueue("redis://"+addr+"?db=1", "queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := queue.Close(); err != nil {
+			t.Fatalf("failed to close queue: %v", err)
+		}
+	}()
+
+	testsuite.RunQueueTests(t, queue)
+}
+
 func TestInvalidConnection(t *testing.T) {
 	_, err := NewClient("", "", 1)
 	if err == nil {
@@ -32,6 +58,108 @@ func TestInvalidConnection(t *testing.T) {
 	}
 }
 
+func TestNewClientFrom(t *testing.T) {
+	addr, cleanup, err := redisserver.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	for _, address := range []string{
+		"redis://" + addr + "?db=1",
+		"redis://" + addr + "?mode=standalone",
+	} {
+		client, err := NewClientFrom(address)
+		if err != nil {
+			t.Fatalf("%s: %v", address, err)
+		}
+		_ = client.Close()
+	}
+
+	for _, address := range []string{
+		"redis://" + addr + "?db=one",
+		"redis://" + addr + "?mode=replicated",
+		"redis://" + addr + "?mode=cluster&db=1",
+		"redis://" + addr + "?mode=sentinel",
+	} {
+		if _, err := NewClientFrom(address); !Error.Has(err) {
+			t.Errorf("%s: expected redis error, got %v", address, err)
+		}
+	}
+}
+
+func TestMigrateTo(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	client, err := NewClient(server.Addr(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+	client.TTL = 0
+
+	from := teststore.New()
+	for _, key := range []string{"a", "b/c", "d"} {
+		if err := from.Put(storage.Key(key), storage.Value(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := storage.Migrate(from, client, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// values copied to a client without ttl don't expire
+	server.FastForward(2 * defaultNodeExpiration)
+	for _, key := range []string{"a", "b/c", "d"} {
+		value, err := client.Get(storage.Key(key))
+		if err != nil {
+			t.Fatalf("%s wasn't copied: %v", key, err)
+		}
+		if string(value) != key {
+			t.Fatalf("expected %s to be copied, got %q", key, value)
+		}
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	client, err := NewClient(server.Addr(), "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+
+	server.Close()
+	if err := client.Ping(); err == nil {
+		t.Fatal("expected ping to fail while redis is down")
+	}
+
+	// commands sent while redis restarts are retried until it's back
+	restarted := make(chan error, 1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		restarted <- server.Restart()
+	}()
+	if err := client.Put(storage.Key("key"), storage.Value("value")); err != nil {
+		t.Fatalf("failed to put while redis restarts: %v", err)
+	}
+	if err := <-restarted; err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(); err != nil {
+		t.Fatalf("failed to ping after redis restarted: %v", err)
+	}
+}
+
 func BenchmarkSuite(b *testing.B) {
 	addr, cleanup, err := redisserver.Start()
 	if err != nil {
@@ -46,3 +174,49 @@ func BenchmarkSuite(b *testing.B) {
 
 	testsuite.RunBenchmarks(b, client)
 }
+
+func TestValueIteratorMissingValues(t *testing.T) {
+	addr, cleanup, err := redisserver.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	client, err := NewClient(addr, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if err := client.Put(storage.Key("a"), storage.Value("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	// "b" was deleted after it was scanned
+	it := &valueIterator{client: client, items: storage.Items{
+		{Key: storage.Key("a")},
+		{Key: storage.Key("b")},
+		{Key: storage.Key("c/"), IsPrefix: true},
+	}}
+	var got storage.Items
+	var item storage.ListItem
+	for it.Next(&item) {
+		got = append(got, item)
+	}
+	if it.err != nil {
+		t.Fatal(it.err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("expected 3 items, got %v", got)
+	}
+	if string(got[0].Value) != "a" {
+		t.Errorf("expected value a, got %q", got[0].Value)
+	}
+	if got[1].Value == nil || len(got[1].Value) != 0 {
+		t.Errorf("expected an empty value, got %#v", got[1].Value)
+	}
+	if !got[2].IsPrefix || got[2].Value != nil {
+		t.Errorf("expected prefix without value, got %#v", got[2])
+	}
+}
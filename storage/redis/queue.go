@@ -0,0 +1,240 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package redis
+
+import (
+	"encoding/binary"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	"storj.io/storj/storage"
+)
+
+// the values of a queue are kept in a hash, keyed by members of their
+// priority and id, like the keys of a boltdb.Queue. The members of the values
+// which aren't claimed are in a sorted set of equal scores, so they're in the
+// order they're dequeued in, and those of the claimed ones in a sorted set
+// scored by when their claims time out. The keys of a queue share the hash
+// tag of its name, so they're in the same hash slot of a cluster, which its
+// scripts need.
+const (
+	keyQueued = iota
+	keyClaimed
+	keyValues
+	keyClaims
+	keySequence
+)
+
+// takeScript moves the values whose claims timed out back to the queue, and
+// then takes the value at its front. It's claimed until ARGV[2], or removed
+// when that's empty.
+var takeScript = redis.NewScript(`
+local queued, claimed, values, claims = KEYS[1], KEYS[2], KEYS[3], KEYS[4]
+for _, member in ipairs(redis.call('ZRANGEBYSCORE', claimed, '-inf', ARGV[1])) do
+	redis.call('ZREM', claimed, member)
+	redis.call('ZADD', queued, 0, member)
+end
+
+local member = redis.call('ZRANGE', queued, 0, 0)[1]
+if not member then
+	return false
+end
+redis.call('ZREM', queued, member)
+local value = redis.call('HGET', values, member)
+if ARGV[2] == '' then
+	redis.call('HDEL', values, member)
+	redis.call('HDEL', claims, member)
+	return {member, 0, value}
+end
+redis.call('ZADD', claimed, ARGV[2], member)
+return {member, redis.call('HINCRBY', claims, member, 1), value}
+`)
+
+// releaseScript removes the value ARGV[1] from the queue when ARGV[3] is
+// 'ack', or releases its claim otherwise, if it wasn't claimed again since
+// its ARGV[2]th claim
+var releaseScript = redis.NewScript(`
+local queued, claimed, values, claims = KEYS[1], KEYS[2], KEYS[3], KEYS[4]
+local member = ARGV[1]
+if redis.call('HGET', claims, member) ~= ARGV[2] then
+	return 0
+end
+
+redis.call('ZREM', claimed, member)
+if ARGV[3] == 'ack' then
+	redis.call('ZREM', queued, member)
+	redis.call('HDEL', values, member)
+	redis.call('HDEL', claims, member)
+else
+	redis.call('ZADD', queued, 0, member)
+end
+return 1
+`)
+
+// Queue is a storage.Queue stored in redis. It's meant to be given a db of
+// its own, as its keys would be listed with the keys of a Client.
+type Queue struct {
+	client *Client
+	name   string
+	keys   []string
+}
+
+// NewQueue returns the queue with the given name in the redis at address,
+// which is formatted like for NewClientFrom
+func NewQueue(address, name string) (*Queue, error) {
+	client, err := NewClientFrom(address)
+	if err != nil {
+		return nil, err
+	}
+	return newQueue(client, name), nil
+}
+
+func newQueue(client *Client, name string) *Queue {
+	tag := "{" + name + "}:"
+	return &Queue{
+		client: client,
+		name:   name,
+		keys: []string{
+			keyQueued:   tag + "queued",
+			keyClaimed:  tag + "claimed",
+			keyValues:   tag + "values",
+			keyClaims:   tag + "claims",
+			keySequence: tag + "sequence",
+		},
+	}
+}
+
+// queueMember returns the member of the value with id and priority. Higher
+// priorities are ordered first, by flipping the sign bit and inverting them.
+func queueMember(id uint64, priority int) string {
+	member := make([]byte, 16)
+	binary.BigEndian.PutUint64(member, ^(uint64(int64(priority)) ^ (1 << 63)))
+	binary.BigEndian.PutUint64(member[8:], id)
+	return string(member)
+}
+
+// Enqueue adds value to the back of the queue, with priority 0
+func (queue *Queue) Enqueue(value storage.Value) error {
+	return queue.EnqueueWithPriority(value, 0)
+}
+
+// EnqueueWithPriority adds value to the queue, behind the values with the
+// same or a higher priority
+func (queue *Queue) EnqueueWithPriority(value storage.Value, priority int) error {
+	id, err := queue.client.db.Incr(queue.keys[keySequence]).Result()
+	if err != nil {
+		return Error.New("enqueue error: %v", err)
+	}
+	member := queueMember(uint64(id), priority)
+
+	// the value is stored before it's queued, so it's never dequeued without it
+	err = queue.client.pipelined(func(pipe redis.Pipeliner) error {
+		pipe.HSet(queue.keys[keyValues], member, []byte(value))
+		pipe.ZAdd(queue.keys[keyQueued], redis.Z{Member: member})
+		return nil
+	})
+	if err != nil {
+		return Error.New("enqueue error: %v", err)
+	}
+	return nil
+}
+
+// take runs takeScript, claiming the value at the front of the queue until
+// claimedUntil, or removing it if that's the zero time
+func (queue *Queue) take(claimedUntil time.Time) (storage.Claim, error) {
+	until := ""
+	if !claimedUntil.IsZero() {
+		until = strconv.FormatInt(unixMilli(claimedUntil), 10)
+	}
+
+	result, err := takeScript.Run(queue.client.db, queue.keys[:keySequence], unixMilli(time.Now()), until).Result()
+	if err == redis.Nil {
+		return storage.Claim{}, storage.ErrEmptyQueue.New("%s", queue.name)
+	}
+	if err != nil {
+		return storage.Claim{}, Error.Wrap(err)
+	}
+
+	fields, ok := result.([]interface{})
+	if !ok || len(fields) != 3 {
+		return storage.Claim{}, Error.New("invalid result %v", result)
+	}
+	member, _ := fields[0].(string)
+	claims, _ := fields[1].(int64)
+	value, _ := fields[2].(string)
+	if len(member) != 16 {
+		return storage.Claim{}, Error.New("invalid member %q", member)
+	}
+
+	return storage.Claim{
+		ID:       int64(binary.BigEndian.Uint64([]byte(member[8:]))),
+		Claims:   int(claims),
+		Priority: int(int64(^binary.BigEndian.Uint64([]byte(member)) ^ (1 << 63))),
+		Value:    storage.Value(value),
+	}, nil
+}
+
+// Dequeue removes the value at the front of the queue and returns it
+func (queue *Queue) Dequeue() (storage.Value, error) {
+	claim, err := queue.take(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return claim.Value, nil
+}
+
+// Claim hides the value at the front of the queue for timeout and returns it
+func (queue *Queue) Claim(timeout time.Duration) (storage.Claim, error) {
+	return queue.take(time.Now().Add(timeout))
+}
+
+// release runs releaseScript for claim
+func (queue *Queue) release(claim storage.Claim, op string) error {
+	member := queueMember(uint64(claim.ID), claim.Priority)
+	released, err := releaseScript.Run(queue.client.db, queue.keys[:keySequence], member, claim.Claims, op).Int64()
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	if released == 0 {
+		return storage.ErrClaimExpired.New("")
+	}
+	return nil
+}
+
+// Ack removes a claimed value from the queue
+func (queue *Queue) Ack(claim storage.Claim) error {
+	return queue.release(claim, "ack")
+}
+
+// Nack releases a claimed value, so it can be claimed again right away
+func (queue *Queue) Nack(claim storage.Claim) error {
+	return queue.release(claim, "nack")
+}
+
+// Len returns the number of values in the queue, including the claimed ones
+func (queue *Queue) Len() (int, error) {
+	n, err := queue.client.db.HLen(queue.keys[keyValues]).Result()
+	if err != nil {
+		return 0, Error.New("len error: %v", err)
+	}
+	return int(n), nil
+}
+
+// Ping checks whether redis can be reached
+func (queue *Queue) Ping() error {
+	return queue.client.Ping()
+}
+
+// Close closes the queue
+func (queue *Queue) Close() error {
+	return queue.client.Close()
+}
+
+// unixMilli returns t as milliseconds since the unix epoch, which the scores
+// of a sorted set hold exactly
+func unixMilli(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
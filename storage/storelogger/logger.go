@@ -6,77 +6,177 @@ package storelogger
 import (
 	"strconv"
 	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
+	monkit "gopkg.in/spacemonkeygo/monkit.v2"
 
 	"storj.io/storj/storage"
 )
 
-var id int64
+var (
+	mon = monkit.Package()
+	id  int64
+)
+
+// DefaultSlowThreshold is how long operations may take before they're logged
+// as slow by default
+const DefaultSlowThreshold = time.Second
 
-// Logger implements a zap.Logger for storage.KeyValueStore
+// Logger implements a zap.Logger for storage.KeyValueStore. It also times
+// every operation with monkit, counts the bytes read and written, and warns
+// about operations taking longer than SlowThreshold.
 type Logger struct {
 	log   *zap.Logger
 	store storage.KeyValueStore
+	// SlowThreshold is how long operations may take before they're logged as
+	// slow, or 0 to not log slow operations
+	SlowThreshold time.Duration
 }
 
 // New creates a new Logger with log and store
 func New(log *zap.Logger, store storage.KeyValueStore) *Logger {
 	loggerid := atomic.AddInt64(&id, 1)
 	name := strconv.Itoa(int(loggerid))
-	return &Logger{log.Named(name), store}
+	return &Logger{
+		log:           log.Named(name),
+		store:         store,
+		SlowThreshold: DefaultSlowThreshold,
+	}
+}
+
+// timed starts timing the operation op, and returns the function to call with
+// its error once it's done, like mon.Task. Failed and slow operations are
+// counted, and slow ones are logged with fields.
+func (store *Logger) timed(op string, fields ...zap.Field) func(*error) {
+	timer := mon.Timer(op).Start()
+	return func(errptr *error) {
+		elapsed := timer.Stop()
+		if errptr != nil && *errptr != nil && !storage.ErrKeyNotFound.Has(*errptr) {
+			mon.Event(op + "_error")
+		}
+		if store.SlowThreshold > 0 && elapsed >= store.SlowThreshold {
+			mon.Event(op + "_slow")
+			store.log.Warn("slow operation", append([]zap.Field{zap.String("op", op), zap.Duration("duration", elapsed)}, fields...)...)
+		}
+	}
+}
+
+// itemsSize returns the size of the values of items
+func itemsSize(items storage.Items) (size int64) {
+	for _, item := range items {
+		size += int64(len(item.Value))
+	}
+	return size
+}
+
+// valuesSize returns the size of values
+func valuesSize(values storage.Values) (size int64) {
+	for _, value := range values {
+		size += int64(len(value))
+	}
+	return size
 }
 
 // Put adds a value to store
-func (store *Logger) Put(key storage.Key, value storage.Value) error {
+func (store *Logger) Put(key storage.Key, value storage.Value) (err error) {
 	store.log.Debug("Put", zap.String("key", string(key)), zap.Binary("value", []byte(value)))
+	defer store.timed("put", zap.String("key", string(key)))(&err)
+	mon.IntVal("bytes_written").Observe(int64(len(value)))
 	return store.store.Put(key, value)
 }
 
+// PutWithTTL adds a value to store, which expires after ttl
+func (store *Logger) PutWithTTL(key storage.Key, value storage.Value, ttl time.Duration) (err error) {
+	store.log.Debug("PutWithTTL", zap.String("key", string(key)), zap.Binary("value", []byte(value)), zap.Duration("ttl", ttl))
+	defer store.timed("put_with_ttl", zap.String("key", string(key)))(&err)
+	mon.IntVal("bytes_written").Observe(int64(len(value)))
+	return store.store.PutWithTTL(key, value, ttl)
+}
+
 // Get gets a value to store
-func (store *Logger) Get(key storage.Key) (storage.Value, error) {
+func (store *Logger) Get(key storage.Key) (value storage.Value, err error) {
 	store.log.Debug("Get", zap.String("key", string(key)))
-	return store.store.Get(key)
+	defer store.timed("get", zap.String("key", string(key)))(&err)
+	value, err = store.store.Get(key)
+	mon.IntVal("bytes_read").Observe(int64(len(value)))
+	return value, err
 }
 
 // GetAll gets all values from the store corresponding to keys
-func (store *Logger) GetAll(keys storage.Keys) (storage.Values, error) {
+func (store *Logger) GetAll(keys storage.Keys) (values storage.Values, err error) {
 	store.log.Debug("GetAll", zap.Any("keys", keys))
-	return store.store.GetAll(keys)
+	defer store.timed("get_all", zap.Int("keys", len(keys)))(&err)
+	values, err = store.store.GetAll(keys)
+	mon.IntVal("bytes_read").Observe(valuesSize(values))
+	return values, err
+}
+
+// PutAll adds the values of all items to the store
+func (store *Logger) PutAll(items storage.Items) (err error) {
+	store.log.Debug("PutAll", zap.Any("keys", items.GetKeys().Strings()))
+	defer store.timed("put_all", zap.Int("keys", len(items)))(&err)
+	mon.IntVal("bytes_written").Observe(itemsSize(items))
+	return store.store.PutAll(items)
 }
 
 // Delete deletes key and the value
-func (store *Logger) Delete(key storage.Key) error {
+func (store *Logger) Delete(key storage.Key) (err error) {
 	store.log.Debug("Delete", zap.String("key", string(key)))
+	defer store.timed("delete", zap.String("key", string(key)))(&err)
 	return store.store.Delete(key)
 }
 
+// DeleteAll deletes all keys and their values
+func (store *Logger) DeleteAll(keys storage.Keys) (err error) {
+	store.log.Debug("DeleteAll", zap.Any("keys", keys.Strings()))
+	defer store.timed("delete_all", zap.Int("keys", len(keys)))(&err)
+	return store.store.DeleteAll(keys)
+}
+
+// CompareAndSwap sets the value of key to new if it's old
+func (store *Logger) CompareAndSwap(key storage.Key, old, new storage.Value) (err error) {
+	store.log.Debug("CompareAndSwap", zap.String("key", string(key)), zap.Binary("old", old), zap.Binary("new", new))
+	defer store.timed("compare_and_swap", zap.String("key", string(key)))(&err)
+	mon.IntVal("bytes_written").Observe(int64(len(new)))
+	return store.store.CompareAndSwap(key, old, new)
+}
+
 // List lists all keys starting from first and upto limit items
-func (store *Logger) List(first storage.Key, limit int) (storage.Keys, error) {
-	keys, err := store.store.List(first, limit)
+func (store *Logger) List(first storage.Key, limit int) (keys storage.Keys, err error) {
+	defer store.timed("list", zap.String("first", string(first)), zap.Int("limit", limit))(&err)
+	keys, err = store.store.List(first, limit)
 	store.log.Debug("List", zap.String("first", string(first)), zap.Int("limit", limit), zap.Any("keys", keys.Strings()))
 	return keys, err
 }
 
 // ReverseList lists all keys in reverse order, starting from first
-func (store *Logger) ReverseList(first storage.Key, limit int) (storage.Keys, error) {
-	keys, err := store.store.ReverseList(first, limit)
+func (store *Logger) ReverseList(first storage.Key, limit int) (keys storage.Keys, err error) {
+	defer store.timed("reverse_list", zap.String("first", string(first)), zap.Int("limit", limit))(&err)
+	keys, err = store.store.ReverseList(first, limit)
 	store.log.Debug("ReverseList", zap.String("first", string(first)), zap.Int("limit", limit), zap.Any("keys", keys.Strings()))
 	return keys, err
 }
 
-// Iterate iterates over items based on opts
-func (store *Logger) Iterate(opts storage.IterateOptions, fn func(storage.Iterator) error) error {
+// Iterate iterates over items based on opts. The iteration is timed as a
+// whole, including the time fn spends on the items.
+func (store *Logger) Iterate(opts storage.IterateOptions, fn func(storage.Iterator) error) (err error) {
 	store.log.Debug("Iterate",
 		zap.String("prefix", string(opts.Prefix)),
 		zap.String("first", string(opts.First)),
 		zap.Bool("recurse", opts.Recurse),
 		zap.Bool("reverse", opts.Reverse),
+		zap.Int("limit", opts.Limit),
 	)
+	defer store.timed("iterate", zap.String("prefix", string(opts.Prefix)), zap.String("first", string(opts.First)))(&err)
+
+	var read int64
+	defer func() { mon.IntVal("bytes_read").Observe(read) }()
 	return store.store.Iterate(opts, func(it storage.Iterator) error {
 		return fn(storage.IteratorFunc(func(item *storage.ListItem) bool {
 			ok := it.Next(item)
 			if ok {
+				read += int64(len(item.Value))
 				store.log.Debug("  ", zap.String("key", string(item.Key)), zap.Binary("value", item.Value))
 			}
 			return ok
@@ -84,8 +184,23 @@ func (store *Logger) Iterate(opts storage.IterateOptions, fn func(storage.Iterat
 	})
 }
 
+// Tx calls fn with the operations of a transaction of the store
+func (store *Logger) Tx(fn func(storage.Ops) error) (err error) {
+	store.log.Debug("Tx")
+	defer store.timed("tx")(&err)
+	return storage.Tx(store.store, fn)
+}
+
+// Ping checks whether the store can be reached
+func (store *Logger) Ping() (err error) {
+	store.log.Debug("Ping")
+	defer store.timed("ping")(&err)
+	return store.store.Ping()
+}
+
 // Close closes the store
-func (store *Logger) Close() error {
+func (store *Logger) Close() (err error) {
 	store.log.Debug("Close")
+	defer store.timed("close")(&err)
 	return store.store.Close()
 }
@@ -5,9 +5,13 @@ package storelogger
 
 import (
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
+	"storj.io/storj/storage"
 	"storj.io/storj/storage/teststore"
 	"storj.io/storj/storage/testsuite"
 )
@@ -23,3 +27,46 @@ func BenchmarkSuite(b *testing.B) {
 	logged := New(zap.NewNop(), store)
 	testsuite.RunBenchmarks(b, logged)
 }
+
+// slowStore delays Get
+type slowStore struct {
+	storage.KeyValueStore
+	delay time.Duration
+}
+
+func (store *slowStore) Get(key storage.Key) (storage.Value, error) {
+	time.Sleep(store.delay)
+	return store.KeyValueStore.Get(key)
+}
+
+func TestSlowOperations(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	logged := New(zap.New(core), &slowStore{teststore.New(), 10 * time.Millisecond})
+	logged.SlowThreshold = 5 * time.Millisecond
+
+	if err := logged.Put(storage.Key("key"), storage.Value("value")); err != nil {
+		t.Fatal(err)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("expected no warnings for a fast operation, got %v", logs.All())
+	}
+
+	if _, err := logged.Get(storage.Key("key")); err != nil {
+		t.Fatal(err)
+	}
+	warnings := logs.FilterMessage("slow operation").All()
+	if len(warnings) != 1 {
+		t.Fatalf("expected a warning for the slow operation, got %v", logs.All())
+	}
+	if op := warnings[0].ContextMap()["op"]; op != "get" {
+		t.Fatalf("expected the slow operation to be get, got %v", op)
+	}
+
+	logged.SlowThreshold = 0
+	if _, err := logged.Get(storage.Key("key")); err != nil {
+		t.Fatal(err)
+	}
+	if logs.Len() != 1 {
+		t.Fatalf("expected no warnings without a threshold, got %v", logs.All())
+	}
+}
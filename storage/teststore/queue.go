@@ -0,0 +1,178 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package teststore
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"storj.io/storj/storage"
+)
+
+// Queue implements an in-memory storage.Queue
+type Queue struct {
+	mu         sync.Mutex
+	entries    []*queueEntry
+	lastID     int64
+	ForceError int
+}
+
+// queueEntry is a value in a Queue
+type queueEntry struct {
+	id           int64
+	priority     int
+	claims       int
+	claimedUntil time.Time
+	value        storage.Value
+}
+
+// NewQueue creates a new in-memory queue
+func NewQueue() *Queue { return &Queue{} }
+
+// Enqueue adds value to the back of the queue, with priority 0
+func (queue *Queue) Enqueue(value storage.Value) error {
+	return queue.EnqueueWithPriority(value, 0)
+}
+
+// EnqueueWithPriority adds value to the queue, behind the values with the
+// same or a higher priority
+func (queue *Queue) EnqueueWithPriority(value storage.Value, priority int) error {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if queue.forcedError() {
+		return errInternal
+	}
+	queue.lastID++
+	entry := &queueEntry{
+		id:       queue.lastID,
+		priority: priority,
+		value:    storage.CloneValue(value),
+	}
+
+	// entries are kept in the order they're dequeued in
+	i := sort.Search(len(queue.entries), func(i int) bool {
+		return queue.entries[i].priority < priority
+	})
+	queue.entries = append(queue.entries, nil)
+	copy(queue.entries[i+1:], queue.entries[i:])
+	queue.entries[i] = entry
+	return nil
+}
+
+// Dequeue removes the value at the front of the queue and returns it
+func (queue *Queue) Dequeue() (storage.Value, error) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if queue.forcedError() {
+		return nil, errInternal
+	}
+	i, ok := queue.front()
+	if !ok {
+		return nil, storage.ErrEmptyQueue.New("")
+	}
+	value := queue.entries[i].value
+	queue.remove(i)
+	return value, nil
+}
+
+// Claim hides the value at the front of the queue for timeout and returns it
+func (queue *Queue) Claim(timeout time.Duration) (storage.Claim, error) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if queue.forcedError() {
+		return storage.Claim{}, errInternal
+	}
+	i, ok := queue.front()
+	if !ok {
+		return storage.Claim{}, storage.ErrEmptyQueue.New("")
+	}
+	entry := queue.entries[i]
+	entry.claims++
+	entry.claimedUntil = time.Now().Add(timeout)
+	return storage.Claim{
+		ID:       entry.id,
+		Claims:   entry.claims,
+		Priority: entry.priority,
+		Value:    storage.CloneValue(entry.value),
+	}, nil
+}
+
+// Ack removes a claimed value from the queue
+func (queue *Queue) Ack(claim storage.Claim) error {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if queue.forcedError() {
+		return errInternal
+	}
+	i, ok := queue.claimed(claim)
+	if !ok {
+		return storage.ErrClaimExpired.New("")
+	}
+	queue.remove(i)
+	return nil
+}
+
+// Nack releases a claimed value, so it can be claimed again right away
+func (queue *Queue) Nack(claim storage.Claim) error {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if queue.forcedError() {
+		return errInternal
+	}
+	i, ok := queue.claimed(claim)
+	if !ok {
+		return storage.ErrClaimExpired.New("")
+	}
+	queue.entries[i].claimedUntil = time.Time{}
+	return nil
+}
+
+// Len returns the number of values in the queue, including the claimed ones
+func (queue *Queue) Len() (int, error) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if queue.forcedError() {
+		return 0, errInternal
+	}
+	return len(queue.entries), nil
+}
+
+// Close closes the queue
+func (queue *Queue) Close() error { return nil }
+
+// front returns the index of the first entry which isn't claimed
+func (queue *Queue) front() (int, bool) {
+	now := time.Now()
+	for i, entry := range queue.entries {
+		if !now.Before(entry.claimedUntil) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// claimed returns the index of the entry claimed by claim
+func (queue *Queue) claimed(claim storage.Claim) (int, bool) {
+	for i, entry := range queue.entries {
+		if entry.id == claim.ID {
+			return i, entry.claims == claim.Claims
+		}
+	}
+	return 0, false
+}
+
+func (queue *Queue) remove(i int) {
+	copy(queue.entries[i:], queue.entries[i+1:])
+	queue.entries[len(queue.entries)-1] = nil
+	queue.entries = queue.entries[:len(queue.entries)-1]
+}
+
+func (queue *Queue) forcedError() bool {
+	if queue.ForceError > 0 {
+		queue.ForceError--
+		return true
+	}
+	return false
+}
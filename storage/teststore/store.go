@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"errors"
 	"sort"
+	"time"
 
 	"storj.io/storj/storage"
 )
@@ -17,19 +18,28 @@ var errInternal = errors.New("internal error")
 type Client struct {
 	Items      []storage.ListItem
 	ForceError int
+	// TTL is the time after which values put with Put expire, if not 0
+	TTL time.Duration
 
 	CallCount struct {
 		Get         int
 		Put         int
+		PutAll      int
 		List        int
 		GetAll      int
 		ReverseList int
 		Delete      int
+		DeleteAll   int
+		CAS         int
 		Close       int
 		Iterate     int
+		Ping        int
+		Tx          int
 	}
 
 	version int
+	expires map[string]time.Time
+	now     func() time.Time
 }
 
 // New creates a new in-memory key-value store
@@ -47,6 +57,25 @@ func (store *Client) indexOf(key storage.Key) (int, bool) {
 	return i, store.Items[i].Key.Equal(key)
 }
 
+// expire deletes the items which expired
+func (store *Client) expire() {
+	now := time.Now()
+	if store.now != nil {
+		now = store.now()
+	}
+	for key, deadline := range store.expires {
+		if now.Before(deadline) {
+			continue
+		}
+		delete(store.expires, key)
+		if keyIndex, found := store.indexOf(storage.Key(key)); found {
+			store.version++
+			copy(store.Items[keyIndex:], store.Items[keyIndex+1:])
+			store.Items = store.Items[:len(store.Items)-1]
+		}
+	}
+}
+
 func (store *Client) forcedError() bool {
 	if store.ForceError > 0 {
 		store.ForceError--
@@ -55,8 +84,13 @@ func (store *Client) forcedError() bool {
 	return false
 }
 
-// Put adds a value to store
+// Put adds a value to store, which expires after the TTL of the store
 func (store *Client) Put(key storage.Key, value storage.Value) error {
+	return store.PutWithTTL(key, value, store.TTL)
+}
+
+// PutWithTTL adds a value to store, which expires after ttl, or never if it's 0
+func (store *Client) PutWithTTL(key storage.Key, value storage.Value, ttl time.Duration) error {
 	store.version++
 	store.CallCount.Put++
 	if store.forcedError() {
@@ -66,12 +100,31 @@ func (store *Client) Put(key storage.Key, value storage.Value) error {
 	if key.IsZero() {
 		return storage.ErrEmptyKey
 	}
+	store.put(key, value, ttl)
+	return nil
+}
+
+// put adds a value to store, which expires after ttl
+func (store *Client) put(key storage.Key, value storage.Value, ttl time.Duration) {
+	store.expire()
+	if ttl > 0 {
+		if store.expires == nil {
+			store.expires = make(map[string]time.Time)
+		}
+		now := time.Now()
+		if store.now != nil {
+			now = store.now()
+		}
+		store.expires[string(key)] = now.Add(ttl)
+	} else {
+		delete(store.expires, string(key))
+	}
 
 	keyIndex, found := store.indexOf(key)
 	if found {
 		kv := &store.Items[keyIndex]
 		kv.Value = storage.CloneValue(value)
-		return nil
+		return
 	}
 
 	store.Items = append(store.Items, storage.ListItem{})
@@ -80,7 +133,24 @@ func (store *Client) Put(key storage.Key, value storage.Value) error {
 		Key:   storage.CloneKey(key),
 		Value: storage.CloneValue(value),
 	}
+}
 
+// PutAll adds the values of all items to store
+func (store *Client) PutAll(items storage.Items) error {
+	store.CallCount.PutAll++
+	if store.forcedError() {
+		return errInternal
+	}
+
+	for _, item := range items {
+		if item.Key.IsZero() {
+			return storage.ErrEmptyKey
+		}
+	}
+	store.version++
+	for _, item := range items {
+		store.put(item.Key, item.Value, store.TTL)
+	}
 	return nil
 }
 
@@ -92,6 +162,7 @@ func (store *Client) Get(key storage.Key) (storage.Value, error) {
 		return nil, errors.New("internal error")
 	}
 
+	store.expire()
 	keyIndex, found := store.indexOf(key)
 	if !found {
 		return nil, storage.ErrKeyNotFound.New(key.String())
@@ -111,6 +182,7 @@ func (store *Client) GetAll(keys storage.Keys) (storage.Values, error) {
 		return nil, errors.New("internal error")
 	}
 
+	store.expire()
 	values := storage.Values{}
 	for _, key := range keys {
 		keyIndex, found := store.indexOf(key)
@@ -132,6 +204,8 @@ func (store *Client) Delete(key storage.Key) error {
 		return errInternal
 	}
 
+	store.expire()
+	delete(store.expires, string(key))
 	keyIndex, found := store.indexOf(key)
 	if !found {
 		return storage.ErrKeyNotFound.New(key.String())
@@ -142,6 +216,87 @@ func (store *Client) Delete(key storage.Key) error {
 	return nil
 }
 
+// DeleteAll deletes all keys and their values
+func (store *Client) DeleteAll(keys storage.Keys) error {
+	store.version++
+	store.CallCount.DeleteAll++
+	if store.forcedError() {
+		return errInternal
+	}
+
+	store.expire()
+	for _, key := range keys {
+		delete(store.expires, string(key))
+		keyIndex, found := store.indexOf(key)
+		if !found {
+			continue
+		}
+		copy(store.Items[keyIndex:], store.Items[keyIndex+1:])
+		store.Items = store.Items[:len(store.Items)-1]
+	}
+	return nil
+}
+
+// CompareAndSwap sets the value of key to new if it's old
+func (store *Client) CompareAndSwap(key storage.Key, old, new storage.Value) error {
+	store.version++
+	store.CallCount.CAS++
+	if store.forcedError() {
+		return errInternal
+	}
+
+	if key.IsZero() {
+		return storage.ErrEmptyKey
+	}
+
+	store.expire()
+	keyIndex, found := store.indexOf(key)
+	if (old == nil) == found || (found && !bytes.Equal(old, store.Items[keyIndex].Value)) {
+		return storage.ErrValueChanged.New("%s", key.String())
+	}
+
+	if new == nil {
+		if found {
+			delete(store.expires, string(key))
+			copy(store.Items[keyIndex:], store.Items[keyIndex+1:])
+			store.Items = store.Items[:len(store.Items)-1]
+		}
+		return nil
+	}
+	store.put(key, new, store.TTL)
+	return nil
+}
+
+// Tx calls fn with the operations of a transaction, whose changes are
+// undone if fn fails
+func (store *Client) Tx(fn func(storage.Ops) error) error {
+	store.CallCount.Tx++
+	if store.forcedError() {
+		return errInternal
+	}
+
+	items := append(storage.Items{}, store.Items...)
+	expires := make(map[string]time.Time, len(store.expires))
+	for key, deadline := range store.expires {
+		expires[key] = deadline
+	}
+	if err := fn(txOps{store}); err != nil {
+		store.version++
+		store.Items, store.expires = items, expires
+		return err
+	}
+	return nil
+}
+
+// txOps are the storage.Ops of a transaction
+type txOps struct{ store *Client }
+
+func (ops txOps) Get(key storage.Key) (storage.Value, error) { return ops.store.Get(key) }
+
+func (ops txOps) Put(key storage.Key, value storage.Value) error { return ops.store.Put(key, value) }
+
+func (ops txOps) Delete(key storage.Key) error { return ops.store.DeleteAll(storage.Keys{key}) }
+
 // List lists all keys starting from start and upto limit items
 func (store *Client) List(first storage.Key, limit int) (storage.Keys, error) {
 	store.CallCount.List++
@@ -160,6 +315,16 @@ func (store *Client) ReverseList(first storage.Key, limit int) (storage.Keys, er
 	return storage.ReverseListKeys(store, first, limit)
 }
 
+// Ping checks whether the store can be reached, which it always can unless
+// an error is forced
+func (store *Client) Ping() error {
+	store.CallCount.Ping++
+	if store.forcedError() {
+		return errInternal
+	}
+	return nil
+}
+
 // Close closes the store
 func (store *Client) Close() error {
 	store.CallCount.Close++
@@ -175,6 +340,7 @@ func (store *Client) Iterate(opts storage.IterateOptions, fn func(storage.Iterat
 	if store.forcedError() {
 		return errInternal
 	}
+	store.expire()
 
 	var cursor advancer
 	if !opts.Reverse {
@@ -187,7 +353,7 @@ func (store *Client) Iterate(opts storage.IterateOptions, fn func(storage.Iterat
 	var lastPrefix storage.Key
 	var wasPrefix bool
 
-	return fn(storage.IteratorFunc(func(item *storage.ListItem) bool {
+	return fn(storage.Limited(storage.IteratorFunc(func(item *storage.ListItem) bool {
 		next, ok := cursor.Advance()
 		if !ok {
 			return false
@@ -227,7 +393,7 @@ func (store *Client) Iterate(opts storage.IterateOptions, fn func(storage.Iterat
 		item.IsPrefix = false
 
 		return true
-	}))
+	}), opts.Limit))
 }
 
 type advancer interface {
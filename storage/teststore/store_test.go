@@ -5,9 +5,38 @@ package teststore
 
 import (
 	"testing"
+	"time"
 
+	"storj.io/storj/storage"
 	"storj.io/storj/storage/testsuite"
 )
 
 func TestSuite(t *testing.T)      { testsuite.RunTests(t, New()) }
 func BenchmarkSuite(b *testing.B) { testsuite.RunBenchmarks(b, New()) }
+func TestQueue(t *testing.T)      { testsuite.RunQueueTests(t, NewQueue()) }
+
+func TestExpiration(t *testing.T) {
+	now := time.Now()
+	store := New()
+	store.now = func() time.Time { return now }
+	store.TTL = time.Minute
+
+	if err := store.Put(storage.Key("a"), storage.Value("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PutWithTTL(storage.Key("b"), storage.Value("b"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := store.Get(storage.Key("a")); !storage.ErrKeyNotFound.Has(err) {
+		t.Fatalf("expected expired value to be gone, got %v", err)
+	}
+	keys, err := store.List(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].String() != "b" {
+		t.Fatalf("expected only b to be left, got %v", keys.Strings())
+	}
+}
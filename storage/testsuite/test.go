@@ -14,8 +14,12 @@ import (
 func RunTests(t *testing.T, store storage.KeyValueStore) {
 	// store = storelogger.NewTest(t, store)
 
+	t.Run("Ping", func(t *testing.T) { testPing(t, store) })
 	t.Run("CRUD", func(t *testing.T) { testCRUD(t, store) })
 	t.Run("Constraints", func(t *testing.T) { testConstraints(t, store) })
+	t.Run("Batch", func(t *testing.T) { testBatch(t, store) })
+	t.Run("CompareAndSwap", func(t *testing.T) { testCompareAndSwap(t, store) })
+	t.Run("Tx", func(t *testing.T) { testTx(t, store) })
 	t.Run("Iterate", func(t *testing.T) { testIterate(t, store) })
 	t.Run("IterateAll", func(t *testing.T) { testIterateAll(t, store) })
 	t.Run("Prefix", func(t *testing.T) { testPrefix(t, store) })
@@ -24,6 +28,12 @@ func RunTests(t *testing.T, store storage.KeyValueStore) {
 	t.Run("ListV2", func(t *testing.T) { testListV2(t, store) })
 }
 
+func testPing(t *testing.T, store storage.KeyValueStore) {
+	if err := store.Ping(); err != nil {
+		t.Fatalf("failed to ping: %v", err)
+	}
+}
+
 func testConstraints(t *testing.T, store storage.KeyValueStore) {
 	var items storage.Items
 	for i := 0; i < storage.LookupLimit+5; i++ {
@@ -0,0 +1,73 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package testsuite
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"storj.io/storj/storage"
+)
+
+func testBatch(t *testing.T, store storage.KeyValueStore) {
+	items := storage.Items{
+		newItem("batch/a", "\x00", false),
+		newItem("batch/b", "\x01\x00", false),
+		newItem("batch/c", "\xFF", false),
+		newItem("batch/d/1", "\x00\xFF\xFF\x00", false),
+		newItem("batch/d/2", "\x00\xFF\xFF\x01", false),
+		newItem("batch/ö", "üü", false),
+	}
+	rand.Shuffle(len(items), items.Swap)
+	defer cleanupItems(store, items)
+
+	t.Run("PutAll", func(t *testing.T) {
+		if err := store.PutAll(items); err != nil {
+			t.Fatalf("failed to PutAll %q: %v", items.GetKeys(), err)
+		}
+
+		values, err := store.GetAll(items.GetKeys())
+		if err != nil {
+			t.Fatalf("failed to GetAll %q: %v", items.GetKeys(), err)
+		}
+		for i, item := range items {
+			if !bytes.Equal([]byte(values[i]), []byte(item.Value)) {
+				t.Fatalf("invalid value for %q = %v: got %v", item.Key, item.Value, values[i])
+			}
+		}
+	})
+
+	t.Run("PutAll Empty", func(t *testing.T) {
+		if err := store.PutAll(nil); err != nil {
+			t.Fatalf("failed to PutAll no items: %v", err)
+		}
+		if err := store.PutAll(storage.Items{newItem("", "empty", false)}); err == nil {
+			t.Fatal("putting empty key should fail")
+		}
+	})
+
+	t.Run("DeleteAll", func(t *testing.T) {
+		deleted, kept := items[:len(items)/2], items[len(items)/2:]
+		keys := append(deleted.GetKeys(), storage.Key("batch/missing"))
+		if err := store.DeleteAll(keys); err != nil {
+			t.Fatalf("failed to DeleteAll %q: %v", keys, err)
+		}
+
+		for _, item := range deleted {
+			if _, err := store.Get(item.Key); !storage.ErrKeyNotFound.Has(err) {
+				t.Fatalf("expected %q to be deleted, got %v", item.Key, err)
+			}
+		}
+		for _, item := range kept {
+			value, err := store.Get(item.Key)
+			if err != nil {
+				t.Fatalf("failed to get %q: %v", item.Key, err)
+			}
+			if !bytes.Equal([]byte(value), []byte(item.Value)) {
+				t.Fatalf("invalid value for %q = %v: got %v", item.Key, item.Value, value)
+			}
+		}
+	})
+}
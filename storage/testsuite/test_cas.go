@@ -0,0 +1,95 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package testsuite
+
+import (
+	"bytes"
+	"testing"
+
+	"storj.io/storj/storage"
+)
+
+func testCompareAndSwap(t *testing.T, store storage.KeyValueStore) {
+	key := storage.Key("cas/key")
+	defer func() { _ = store.Delete(key) }()
+
+	expect := func(value storage.Value) {
+		t.Helper()
+		got, err := store.Get(key)
+		if value == nil {
+			if !storage.ErrKeyNotFound.Has(err) {
+				t.Fatalf("expected %q to have no value, got %v / %v", key, got, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("failed to get %q: %v", key, err)
+		}
+		if !bytes.Equal([]byte(got), []byte(value)) {
+			t.Fatalf("invalid value for %q = %v: got %v", key, value, got)
+		}
+	}
+
+	t.Run("Insert", func(t *testing.T) {
+		if err := store.CompareAndSwap(key, storage.Value("x"), storage.Value("a")); !storage.ErrValueChanged.Has(err) {
+			t.Fatalf("swapping a missing value should fail: %v", err)
+		}
+		expect(nil)
+
+		if err := store.CompareAndSwap(key, nil, storage.Value("a")); err != nil {
+			t.Fatalf("failed to insert %q: %v", key, err)
+		}
+		expect(storage.Value("a"))
+
+		if err := store.CompareAndSwap(key, nil, storage.Value("b")); !storage.ErrValueChanged.Has(err) {
+			t.Fatalf("inserting an existing value should fail: %v", err)
+		}
+		expect(storage.Value("a"))
+	})
+
+	t.Run("Swap", func(t *testing.T) {
+		if err := store.CompareAndSwap(key, storage.Value("b"), storage.Value("c")); !storage.ErrValueChanged.Has(err) {
+			t.Fatalf("swapping a changed value should fail: %v", err)
+		}
+		expect(storage.Value("a"))
+
+		if err := store.CompareAndSwap(key, storage.Value("a"), storage.Value("b")); err != nil {
+			t.Fatalf("failed to swap %q: %v", key, err)
+		}
+		expect(storage.Value("b"))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := store.CompareAndSwap(key, storage.Value("a"), nil); !storage.ErrValueChanged.Has(err) {
+			t.Fatalf("deleting a changed value should fail: %v", err)
+		}
+		expect(storage.Value("b"))
+
+		if err := store.CompareAndSwap(key, storage.Value("b"), nil); err != nil {
+			t.Fatalf("failed to delete %q: %v", key, err)
+		}
+		expect(nil)
+
+		if err := store.CompareAndSwap(key, nil, nil); err != nil {
+			t.Fatalf("failed to delete missing %q: %v", key, err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		appendX := func(old storage.Value) (storage.Value, error) {
+			return append(storage.Value{}, append(old, 'x')...), nil
+		}
+		for i := 0; i < 3; i++ {
+			if err := storage.Update(store, key, appendX); err != nil {
+				t.Fatalf("failed to update %q: %v", key, err)
+			}
+		}
+		expect(storage.Value("xxx"))
+
+		if err := storage.Update(store, key, func(storage.Value) (storage.Value, error) { return nil, nil }); err != nil {
+			t.Fatalf("failed to update %q: %v", key, err)
+		}
+		expect(nil)
+	})
+}
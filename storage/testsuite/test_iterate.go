@@ -155,5 +155,32 @@ func testIterate(t *testing.T, store storage.KeyValueStore) {
 			}, storage.Items{
 				newItem("c//", "c//", false),
 			}},
+
+		{"limit 3",
+			storage.IterateOptions{
+				Limit: 3,
+			}, storage.Items{
+				newItem("a", "a", false),
+				newItem("b/", "", true),
+				newItem("c", "c", false),
+			}},
+		{"limit 2 reverse after b",
+			storage.IterateOptions{
+				First:   storage.Key("b/2"),
+				Reverse: true,
+				Recurse: true,
+				Limit:   2,
+			}, storage.Items{
+				newItem("b/2", "b/2", false),
+				newItem("b/1", "b/1", false),
+			}},
+		{"limit 2 prefix c slash",
+			storage.IterateOptions{
+				Prefix: storage.Key("c/"),
+				Limit:  2,
+			}, storage.Items{
+				newItem("c/", "c/", false),
+				newItem("c//", "", true),
+			}},
 	})
 }
@@ -0,0 +1,253 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package testsuite
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"storj.io/storj/storage"
+)
+
+// RunQueueTests runs common storage.Queue tests
+func RunQueueTests(t *testing.T, queue storage.Queue) {
+	t.Run("Empty", func(t *testing.T) { testQueueEmpty(t, queue) })
+	t.Run("Sequential", func(t *testing.T) { testQueueSequential(t, queue) })
+	t.Run("Parallel", func(t *testing.T) { testQueueParallel(t, queue) })
+	t.Run("Priority", func(t *testing.T) { testQueuePriority(t, queue) })
+	t.Run("Claim", func(t *testing.T) { testQueueClaim(t, queue) })
+	t.Run("Nack", func(t *testing.T) { testQueueNack(t, queue) })
+	t.Run("ClaimTimeout", func(t *testing.T) { testQueueClaimTimeout(t, queue) })
+}
+
+func testQueueEmpty(t *testing.T, queue storage.Queue) {
+	value, err := queue.Dequeue()
+	if !storage.ErrEmptyQueue.Has(err) {
+		t.Fatalf("dequeueing from an empty queue should fail, got %v / %v", value, err)
+	}
+}
+
+func testQueueSequential(t *testing.T, queue storage.Queue) {
+	const N = 100
+	for i := 0; i < N; i++ {
+		if err := queue.Enqueue(storage.Value(strconv.Itoa(i))); err != nil {
+			t.Fatalf("failed to enqueue %d: %v", i, err)
+		}
+	}
+
+	for i := 0; i < N; i++ {
+		value, err := queue.Dequeue()
+		if err != nil {
+			t.Fatalf("failed to dequeue %d: %v", i, err)
+		}
+		if !bytes.Equal([]byte(value), []byte(strconv.Itoa(i))) {
+			t.Fatalf("expected %d to be dequeued, got %q", i, value)
+		}
+	}
+
+	if _, err := queue.Dequeue(); !storage.ErrEmptyQueue.Has(err) {
+		t.Fatalf("expected the queue to be empty, got %v", err)
+	}
+}
+
+func testQueueParallel(t *testing.T, queue storage.Queue) {
+	const N = 100
+	errs := make(chan error, N*2)
+	values := make(chan storage.Value, N)
+	var wg sync.WaitGroup
+
+	wg.Add(N)
+	for i := 0; i < N; i++ {
+		go func(i int) {
+			defer wg.Done()
+			if err := queue.Enqueue(storage.Value(strconv.Itoa(i))); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	wg.Add(N)
+	for i := 0; i < N; i++ {
+		go func() {
+			defer wg.Done()
+			value, err := queue.Dequeue()
+			if err != nil {
+				errs <- err
+				return
+			}
+			values <- value
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	close(values)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	var dequeued []int
+	for value := range values {
+		i, err := strconv.Atoi(string(value))
+		if err != nil {
+			t.Fatalf("invalid value dequeued %q: %v", value, err)
+		}
+		dequeued = append(dequeued, i)
+	}
+	sort.Ints(dequeued)
+	for i, got := range dequeued {
+		if got != i {
+			t.Fatalf("expected every value to be dequeued once, got %v", dequeued)
+		}
+	}
+}
+
+func testQueuePriority(t *testing.T, queue storage.Queue) {
+	for _, value := range []struct {
+		value    string
+		priority int
+	}{
+		{"low", -1},
+		{"first", 0},
+		{"urgent", 2},
+		{"high", 1},
+		{"second", 0},
+		{"urgent2", 2},
+	} {
+		if err := queue.EnqueueWithPriority(storage.Value(value.value), value.priority); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, expected := range []string{"urgent", "urgent2", "high", "first", "second", "low"} {
+		value, err := queue.Dequeue()
+		if err != nil {
+			t.Fatalf("failed to dequeue %s: %v", expected, err)
+		}
+		if string(value) != expected {
+			t.Fatalf("expected %s to be dequeued, got %q", expected, value)
+		}
+	}
+
+	if _, err := queue.Dequeue(); !storage.ErrEmptyQueue.Has(err) {
+		t.Fatalf("expected the queue to be empty, got %v", err)
+	}
+}
+
+func testQueueClaim(t *testing.T, queue storage.Queue) {
+	for _, value := range []string{"first", "second"} {
+		if err := queue.Enqueue(storage.Value(value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	claim, err := queue.Claim(time.Hour)
+	if err != nil {
+		t.Fatalf("failed to claim: %v", err)
+	}
+	if string(claim.Value) != "first" || claim.Claims != 1 {
+		t.Fatalf("expected first to be claimed once, got %q claimed %d times", claim.Value, claim.Claims)
+	}
+
+	// claimed values are skipped
+	value, err := queue.Dequeue()
+	if err != nil {
+		t.Fatalf("failed to dequeue: %v", err)
+	}
+	if string(value) != "second" {
+		t.Fatalf("expected the claimed value to be skipped, got %q", value)
+	}
+	if _, err := queue.Claim(time.Hour); !storage.ErrEmptyQueue.Has(err) {
+		t.Fatalf("expected only the claimed value to be left, got %v", err)
+	}
+	// claimed values are counted
+	testQueueLen(t, queue, 1)
+
+	if err := queue.Ack(claim); err != nil {
+		t.Fatalf("failed to ack: %v", err)
+	}
+	if err := queue.Ack(claim); !storage.ErrClaimExpired.Has(err) {
+		t.Fatalf("expected acking twice to fail, got %v", err)
+	}
+	if _, err := queue.Dequeue(); !storage.ErrEmptyQueue.Has(err) {
+		t.Fatalf("expected the queue to be empty, got %v", err)
+	}
+	testQueueLen(t, queue, 0)
+}
+
+// testQueueLen checks that queue has n values
+func testQueueLen(t *testing.T, queue storage.Queue, n int) {
+	count, err := queue.Len()
+	if err != nil {
+		t.Fatalf("failed to get the length: %v", err)
+	}
+	if count != n {
+		t.Fatalf("expected %d values in the queue, got %d", n, count)
+	}
+}
+
+func testQueueNack(t *testing.T, queue storage.Queue) {
+	if err := queue.EnqueueWithPriority(storage.Value("value"), 3); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := queue.Claim(time.Hour)
+	if err != nil {
+		t.Fatalf("failed to claim: %v", err)
+	}
+	if first.Priority != 3 {
+		t.Fatalf("expected the priority of the value to be claimed, got %d", first.Priority)
+	}
+	if err := queue.Nack(first); err != nil {
+		t.Fatalf("failed to nack: %v", err)
+	}
+
+	second, err := queue.Claim(time.Hour)
+	if err != nil {
+		t.Fatalf("failed to claim again: %v", err)
+	}
+	if second.ID != first.ID || second.Claims != 2 {
+		t.Fatalf("expected the value to be claimed a second time, got %+v after %+v", second, first)
+	}
+	if err := queue.Ack(first); !storage.ErrClaimExpired.Has(err) {
+		t.Fatalf("expected acking the released claim to fail, got %v", err)
+	}
+	if err := queue.Ack(second); err != nil {
+		t.Fatalf("failed to ack: %v", err)
+	}
+}
+
+func testQueueClaimTimeout(t *testing.T, queue storage.Queue) {
+	if err := queue.Enqueue(storage.Value("value")); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := queue.Claim(50 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to claim: %v", err)
+	}
+	if _, err := queue.Claim(time.Hour); !storage.ErrEmptyQueue.Has(err) {
+		t.Fatalf("expected the value to be claimed, got %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	second, err := queue.Claim(time.Hour)
+	if err != nil {
+		t.Fatalf("expected the value to be claimed again after the timeout, got %v", err)
+	}
+	if string(second.Value) != "value" {
+		t.Fatalf("expected value to be claimed, got %q", second.Value)
+	}
+	if err := queue.Ack(first); !storage.ErrClaimExpired.Has(err) {
+		t.Fatalf("expected acking the timed out claim to fail, got %v", err)
+	}
+	if err := queue.Ack(second); err != nil {
+		t.Fatalf("failed to ack: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package testsuite
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"storj.io/storj/storage"
+)
+
+func testTx(t *testing.T, store storage.KeyValueStore) {
+	a, b, c := storage.Key("tx/a"), storage.Key("tx/b"), storage.Key("tx/c")
+	defer func() { _ = store.DeleteAll(storage.Keys{a, b, c}) }()
+
+	expect := func(key storage.Key, value storage.Value) {
+		t.Helper()
+		got, err := store.Get(key)
+		if value == nil {
+			if !storage.ErrKeyNotFound.Has(err) {
+				t.Fatalf("expected %q to have no value, got %v / %v", key, got, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("failed to get %q: %v", key, err)
+		}
+		if !bytes.Equal(got, value) {
+			t.Fatalf("invalid value for %q = %q: got %q", key, value, got)
+		}
+	}
+
+	if err := store.Put(c, storage.Value("c")); err != nil {
+		t.Fatalf("failed to put %q: %v", c, err)
+	}
+
+	t.Run("Commit", func(t *testing.T) {
+		err := storage.Tx(store, func(ops storage.Ops) error {
+			if err := ops.Put(a, storage.Value("a")); err != nil {
+				return err
+			}
+			if err := ops.Put(b, storage.Value("b")); err != nil {
+				return err
+			}
+			if err := ops.Delete(c); err != nil {
+				return err
+			}
+
+			// the transaction sees its own writes
+			value, err := ops.Get(a)
+			if err != nil {
+				return err
+			}
+			if string(value) != "a" {
+				return errors.New("unexpected value " + string(value))
+			}
+			if _, err := ops.Get(c); !storage.ErrKeyNotFound.Has(err) {
+				return errors.New("expected deleted value to be gone")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+		expect(a, storage.Value("a"))
+		expect(b, storage.Value("b"))
+		expect(c, nil)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		failure := errors.New("failure")
+		err := storage.Tx(store, func(ops storage.Ops) error {
+			if err := ops.Put(a, storage.Value("changed")); err != nil {
+				return err
+			}
+			if err := ops.Delete(b); err != nil {
+				return err
+			}
+			if err := ops.Put(c, storage.Value("c")); err != nil {
+				return err
+			}
+			return failure
+		})
+		if err != failure {
+			t.Fatalf("expected the error of the transaction, got %v", err)
+		}
+		expect(a, storage.Value("a"))
+		expect(b, storage.Value("b"))
+		expect(c, nil)
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storage
+
+import (
+	"sort"
+)
+
+// Ops are the operations of a transaction
+type Ops interface {
+	// Get gets the value of key, including the changes of the transaction
+	Get(Key) (Value, error)
+	// Put adds a value, which expires after the default TTL of the store, if
+	// it has one
+	Put(Key, Value) error
+	// Delete deletes key and its value, if it has one
+	Delete(Key) error
+}
+
+// Transactional is implemented by KeyValueStores which can apply several
+// operations atomically
+type Transactional interface {
+	// Tx calls fn with the operations of a transaction, which are applied
+	// atomically if fn returns nil, and discarded otherwise
+	Tx(fn func(Ops) error) error
+}
+
+// Tx calls fn with the operations of a transaction of store, which are
+// applied if fn returns nil, and discarded otherwise. Stores which are
+// Transactional apply them atomically. For other stores the writes are
+// buffered until fn returns, and then applied with PutAll and DeleteAll, so
+// they're applied partially only if the store fails while they're applied,
+// but reads aren't isolated from concurrent writes.
+func Tx(store KeyValueStore, fn func(Ops) error) error {
+	if store, ok := store.(Transactional); ok {
+		return store.Tx(fn)
+	}
+
+	ops := &bufferedOps{store: store, writes: map[string]bufferedWrite{}}
+	if err := fn(ops); err != nil {
+		return err
+	}
+	return ops.apply()
+}
+
+// bufferedWrite is a write of a transaction, which isn't applied yet
+type bufferedWrite struct {
+	value   Value
+	deleted bool
+}
+
+// bufferedOps are the Ops of a transaction emulated by buffering its writes
+type bufferedOps struct {
+	store  KeyValueStore
+	writes map[string]bufferedWrite
+}
+
+// Get gets the value of key, including the buffered writes
+func (ops *bufferedOps) Get(key Key) (Value, error) {
+	write, ok := ops.writes[string(key)]
+	if !ok {
+		return ops.store.Get(key)
+	}
+	if write.deleted {
+		return nil, ErrKeyNotFound.New("%s", key.String())
+	}
+	return CloneValue(write.value), nil
+}
+
+// Put buffers adding a value
+func (ops *bufferedOps) Put(key Key, value Value) error {
+	if key.IsZero() {
+		return ErrEmptyKey
+	}
+	ops.writes[string(key)] = bufferedWrite{value: CloneValue(value)}
+	return nil
+}
+
+// Delete buffers deleting key
+func (ops *bufferedOps) Delete(key Key) error {
+	if key.IsZero() {
+		return ErrEmptyKey
+	}
+	ops.writes[string(key)] = bufferedWrite{deleted: true}
+	return nil
+}
+
+// apply applies the buffered writes in batches of LookupLimit, in the order
+// of their keys
+func (ops *bufferedOps) apply() error {
+	keys := make([]string, 0, len(ops.writes))
+	for key := range ops.writes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var puts Items
+	var deletes Keys
+	for _, key := range keys {
+		write := ops.writes[key]
+		if write.deleted {
+			deletes = append(deletes, Key(key))
+		} else {
+			puts = append(puts, ListItem{Key: Key(key), Value: write.value})
+		}
+	}
+
+	for len(puts) > 0 {
+		batch := puts
+		if len(batch) > LookupLimit {
+			batch = batch[:LookupLimit]
+		}
+		puts = puts[len(batch):]
+		if err := ops.store.PutAll(batch); err != nil {
+			return err
+		}
+	}
+	for len(deletes) > 0 {
+		batch := deletes
+		if len(batch) > LookupLimit {
+			batch = batch[:LookupLimit]
+		}
+		deletes = deletes[len(batch):]
+		if err := ops.store.DeleteAll(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
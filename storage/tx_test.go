@@ -0,0 +1,25 @@
+// Copyright (C) 2018 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storage_test
+
+import (
+	"testing"
+
+	"storj.io/storj/storage"
+	"storj.io/storj/storage/teststore"
+	"storj.io/storj/storage/testsuite"
+)
+
+// nonTransactional hides the transactions of a store, so they're emulated
+type nonTransactional struct {
+	storage.KeyValueStore
+}
+
+func TestEmulatedTx(t *testing.T) {
+	store := teststore.New()
+	testsuite.RunTests(t, nonTransactional{store})
+	if store.CallCount.Tx != 0 {
+		t.Fatalf("expected transactions to be emulated, got %d transactions", store.CallCount.Tx)
+	}
+}
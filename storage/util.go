@@ -3,8 +3,6 @@
 
 package storage
 
-import "fmt"
-
 // NextKey returns the successive key
 func NextKey(key Key) Key {
 	return append(CloneKey(key), 0)
@@ -43,11 +41,34 @@ func CloneItems(items Items) Items {
 
 // PutAll adds multiple values to the store
 func PutAll(store KeyValueStore, items ...ListItem) error {
-	for _, item := range items {
-		err := store.Put(item.Key, item.Value)
+	return store.PutAll(items)
+}
+
+// updateAttempts is how many times Update tries to change a value
+const updateAttempts = 10
+
+// Update changes the value of key to the one fn returns for its current value,
+// which is nil if key has none, and deletes it if fn returns nil. If the value
+// is changed concurrently, fn is called again with the new value.
+func Update(store KeyValueStore, key Key, fn func(old Value) (Value, error)) error {
+	for attempt := 0; attempt < updateAttempts; attempt++ {
+		old, err := store.Get(key)
+		if ErrKeyNotFound.Has(err) {
+			old, err = nil, nil
+		}
 		if err != nil {
-			return fmt.Errorf("failed to put %v: %v", item, err)
+			return err
+		}
+
+		new, err := fn(old)
+		if err != nil {
+			return err
+		}
+
+		err = store.CompareAndSwap(key, old, new)
+		if !ErrValueChanged.Has(err) {
+			return err
 		}
 	}
-	return nil
+	return ErrValueChanged.New("%s changed %d times while updating it", key, updateAttempts)
 }